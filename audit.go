@@ -0,0 +1,215 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// AuditAction is the kind of mutation recorded by an audit event.
+type AuditAction string
+
+const (
+	// AuditCreate records the creation of a resource.
+	AuditCreate AuditAction = "create"
+	// AuditUpdate records the modification of a resource.
+	AuditUpdate AuditAction = "update"
+	// AuditDelete records the removal of a resource.
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditActionFromMethod maps an HTTP method onto the audit action it represents.
+// Methods that do not mutate state return false.
+func AuditActionFromMethod(method string) (AuditAction, bool) {
+	switch method {
+	case "POST":
+		return AuditCreate, true
+	case "PUT", "PATCH":
+		return AuditUpdate, true
+	case "DELETE":
+		return AuditDelete, true
+	}
+	return "", false
+}
+
+// AuditEvent is a record of a single mutating call made against the API.
+type AuditEvent struct {
+	ID     ID          `json:"id"`
+	Time   time.Time   `json:"time"`
+	Action AuditAction `json:"action"`
+
+	// Method and Path of the request that caused the change.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	ResourceType ResourceType `json:"resourceType,omitempty"`
+	ResourceID   ID           `json:"resourceID,omitempty"`
+	OrgID        ID           `json:"orgID,omitempty"`
+
+	// UserID is the user acting on the resource, and AuthorizationID the
+	// token they used. AuthorizationID is empty for session based requests.
+	UserID          ID     `json:"userID,omitempty"`
+	AuthorizationID ID     `json:"authorizationID,omitempty"`
+	AuthKind        string `json:"authKind,omitempty"`
+	SourceIP        string `json:"sourceIP,omitempty"`
+
+	// Status is the HTTP status code returned to the caller and Error the
+	// error message, if any.
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	Before json.RawMessage    `json:"before,omitempty"`
+	After  json.RawMessage    `json:"after,omitempty"`
+	Diff   []AuditFieldChange `json:"diff,omitempty"`
+}
+
+// Succeeded returns true if the audited call completed without error.
+func (e *AuditEvent) Succeeded() bool {
+	return e.Status >= 200 && e.Status < 300
+}
+
+// AuditFieldChange is a single top level field that differs between the
+// before and after state of an audited resource.
+type AuditFieldChange struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditEventFilter represents a set of filters that restrict the returned audit events.
+type AuditEventFilter struct {
+	OrgID        *ID
+	UserID       *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+	Action       *AuditAction
+	Start        *time.Time
+	Stop         *time.Time
+}
+
+// Match returns true if the event satisfies every filter that is set.
+func (f AuditEventFilter) Match(e *AuditEvent) bool {
+	if f.OrgID != nil && e.OrgID != *f.OrgID {
+		return false
+	}
+	if f.UserID != nil && e.UserID != *f.UserID {
+		return false
+	}
+	if f.ResourceType != nil && e.ResourceType != *f.ResourceType {
+		return false
+	}
+	if f.ResourceID != nil && e.ResourceID != *f.ResourceID {
+		return false
+	}
+	if f.Action != nil && e.Action != *f.Action {
+		return false
+	}
+	if f.Start != nil && e.Time.Before(*f.Start) {
+		return false
+	}
+	if f.Stop != nil && !e.Time.Before(*f.Stop) {
+		return false
+	}
+	return true
+}
+
+// QueryParams returns a map containing url query params.
+func (f AuditEventFilter) QueryParams() map[string][]string {
+	qp := map[string][]string{}
+	if f.OrgID != nil {
+		qp["orgID"] = []string{f.OrgID.String()}
+	}
+	if f.UserID != nil {
+		qp["userID"] = []string{f.UserID.String()}
+	}
+	if f.ResourceType != nil {
+		qp["resourceType"] = []string{string(*f.ResourceType)}
+	}
+	if f.ResourceID != nil {
+		qp["resourceID"] = []string{f.ResourceID.String()}
+	}
+	if f.Action != nil {
+		qp["action"] = []string{string(*f.Action)}
+	}
+	if f.Start != nil {
+		qp["start"] = []string{f.Start.Format(time.RFC3339Nano)}
+	}
+	if f.Stop != nil {
+		qp["stop"] = []string{f.Stop.Format(time.RFC3339Nano)}
+	}
+	return qp
+}
+
+// AuditService records and retrieves audit events.
+type AuditService interface {
+	// CreateAuditEvent records a new audit event and sets e.ID.
+	CreateAuditEvent(ctx context.Context, e *AuditEvent) error
+
+	// FindAuditEvents returns the audit events matching filter ordered by time.
+	FindAuditEvents(ctx context.Context, filter AuditEventFilter, opt ...FindOptions) ([]*AuditEvent, int, error)
+
+	// DeleteAuditEventsBefore removes every audit event recorded before t and
+	// returns the number of events removed.
+	DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error)
+}
+
+// DiffAuditState compares two JSON objects and returns the top level fields
+// that were added, removed or changed. Either side may be empty.
+func DiffAuditState(before, after json.RawMessage) []AuditFieldChange {
+	var b, a map[string]json.RawMessage
+	if len(before) > 0 {
+		if err := json.Unmarshal(before, &b); err != nil {
+			return nil
+		}
+	}
+	if len(after) > 0 {
+		if err := json.Unmarshal(after, &a); err != nil {
+			return nil
+		}
+	}
+
+	fields := make(map[string]struct{}, len(a)+len(b))
+	for k := range b {
+		fields[k] = struct{}{}
+	}
+	for k := range a {
+		fields[k] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var changes []AuditFieldChange
+	for _, k := range keys {
+		bv, av := b[k], a[k]
+		if jsonEqual(bv, av) {
+			continue
+		}
+		changes = append(changes, AuditFieldChange{
+			Field:  k,
+			Before: bv,
+			After:  av,
+		})
+	}
+	return changes
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		return false
+	}
+	ab, _ := json.Marshal(av)
+	bb, _ := json.Marshal(bv)
+	return string(ab) == string(bb)
+}
@@ -0,0 +1,63 @@
+package authorizer
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.AuditService = (*AuditService)(nil)
+
+// AuditService wraps a influxdb.AuditService and authorizes actions
+// against it appropriately.
+type AuditService struct {
+	s influxdb.AuditService
+}
+
+// NewAuditService constructs an instance of an authorizing audit service.
+func NewAuditService(s influxdb.AuditService) *AuditService {
+	return &AuditService{
+		s: s,
+	}
+}
+
+// CreateAuditEvent checks to see if the authorizer on context has write access to all resources.
+func (s *AuditService) CreateAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.CreateAuditEvent(ctx, e)
+}
+
+// FindAuditEvents checks to see if the authorizer on context may read the audit log.
+// The audit log of an organization is visible to those who may modify the organization,
+// while the unscoped audit log requires read access to all resources.
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if filter.OrgID != nil {
+		if err := authorizeWriteOrg(ctx, *filter.OrgID); err != nil {
+			return nil, 0, err
+		}
+	} else if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, 0, err
+	}
+	return s.s.FindAuditEvents(ctx, filter, opt...)
+}
+
+// DeleteAuditEventsBefore checks to see if the authorizer on context has write access to all resources.
+func (s *AuditService) DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return 0, err
+	}
+	return s.s.DeleteAuditEventsBefore(ctx, t)
+}
@@ -0,0 +1,99 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestDBRPMappingService(t *testing.T) {
+	mappings := []*influxdb.DBRPMapping{
+		{Cluster: "c", Database: "db1", RetentionPolicy: "autogen", OrganizationID: 10, BucketID: 1},
+		{Cluster: "c", Database: "db2", RetentionPolicy: "autogen", OrganizationID: 10, BucketID: 2},
+	}
+	svc := mock.NewDBRPMappingService()
+	svc.FindByFn = func(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+		for _, m := range mappings {
+			if m.Database == db {
+				return m, nil
+			}
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound}
+	}
+	svc.FindManyFn = func(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+		return append([]*influxdb.DBRPMapping(nil), mappings...), len(mappings), nil
+	}
+
+	bucketPermission := func(a influxdb.Action, id influxdb.ID) influxdb.Permission {
+		p, err := influxdb.NewPermissionAtID(id, a, influxdb.BucketsResourceType, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *p
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantRead    bool
+		wantWrite   bool
+		wantMany    int
+	}{
+		{
+			name:        "write access to the bucket",
+			permissions: []influxdb.Permission{bucketPermission(influxdb.ReadAction, 1), bucketPermission(influxdb.WriteAction, 1)},
+			wantRead:    true,
+			wantWrite:   true,
+			wantMany:    1,
+		},
+		{
+			name:        "read access to the bucket",
+			permissions: []influxdb.Permission{bucketPermission(influxdb.ReadAction, 1)},
+			wantRead:    true,
+			wantMany:    1,
+		},
+		{
+			name:        "access to another bucket",
+			permissions: []influxdb.Permission{bucketPermission(influxdb.ReadAction, 2), bucketPermission(influxdb.WriteAction, 2)},
+			wantMany:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewDBRPMappingService(svc)
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			check := func(op string, err error, want bool) {
+				t.Helper()
+				if want && err != nil {
+					t.Errorf("%s: unexpected error: %v", op, err)
+				}
+				if !want && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Errorf("%s: expected unauthorized, got: %v", op, err)
+				}
+			}
+
+			_, err := s.FindBy(ctx, "c", "db1", "autogen")
+			check("find", err, tt.wantRead)
+			check("create", s.Create(ctx, mappings[0]), tt.wantWrite)
+			check("delete", s.Delete(ctx, "c", "db1", "autogen"), tt.wantWrite)
+
+			ms, n, err := s.FindMany(ctx, influxdb.DBRPMappingFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n != tt.wantMany || len(ms) != tt.wantMany {
+				t.Errorf("got %d mappings, want %d", n, tt.wantMany)
+			}
+
+			if err := s.Delete(ctx, "c", "missing", "autogen"); err != nil {
+				t.Errorf("deleting a missing mapping: %v", err)
+			}
+		})
+	}
+}
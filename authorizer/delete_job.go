@@ -0,0 +1,92 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.DeleteJobService = (*DeleteJobService)(nil)
+
+// DeleteJobService wraps a influxdb.DeleteJobService and authorizes actions
+// against it appropriately.
+type DeleteJobService struct {
+	s influxdb.DeleteJobService
+}
+
+// NewDeleteJobService constructs an instance of an authorizing delete job service.
+func NewDeleteJobService(s influxdb.DeleteJobService) *DeleteJobService {
+	return &DeleteJobService{
+		s: s,
+	}
+}
+
+// CreateDeleteJob checks to see if the authorizer on context has write access to the job's bucket.
+func (s *DeleteJobService) CreateDeleteJob(ctx context.Context, job *influxdb.DeleteJob, pred influxdb.Predicate) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeWriteBucket(ctx, job.OrgID, job.BucketID); err != nil {
+		return err
+	}
+	return s.s.CreateDeleteJob(ctx, job, pred)
+}
+
+// FindDeleteJobByID checks to see if the authorizer on context has read access to the job's bucket.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	job, err := s.s.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeReadBucket(ctx, job.OrgID, job.BucketID); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// FindDeleteJobs retrieves all delete jobs that match the provided filter and then filters the list down to only the jobs of buckets that are readable.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	js, err := s.s.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := js[:0]
+	for _, job := range js {
+		err := authorizeReadBucket(ctx, job.OrgID, job.BucketID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, err
+		}
+
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// CancelDeleteJob checks to see if the authorizer on context has write access to the job's bucket.
+func (s *DeleteJobService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	job, err := s.s.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeWriteBucket(ctx, job.OrgID, job.BucketID); err != nil {
+		return err
+	}
+	return s.s.CancelDeleteJob(ctx, id)
+}
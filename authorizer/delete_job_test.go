@@ -0,0 +1,100 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestDeleteJobService(t *testing.T) {
+	var (
+		orgID    = influxdb.ID(10)
+		bucketID = influxdb.ID(1)
+	)
+	jobs := func() []*influxdb.DeleteJob {
+		return []*influxdb.DeleteJob{
+			{ID: 100, OrgID: orgID, BucketID: bucketID},
+			{ID: 200, OrgID: orgID, BucketID: 2},
+		}
+	}
+	bucketPermission := func(a influxdb.Action) influxdb.Permission {
+		return influxdb.Permission{
+			Action: a,
+			Resource: influxdb.Resource{
+				Type:  influxdb.BucketsResourceType,
+				OrgID: &orgID,
+				ID:    &bucketID,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantRead    bool
+		wantWrite   bool
+		wantJobs    int
+	}{
+		{
+			name:        "write bucket may create, find and cancel",
+			permissions: []influxdb.Permission{bucketPermission(influxdb.ReadAction), bucketPermission(influxdb.WriteAction)},
+			wantRead:    true,
+			wantWrite:   true,
+			wantJobs:    1,
+		},
+		{
+			name:        "read bucket may only find",
+			permissions: []influxdb.Permission{bucketPermission(influxdb.ReadAction)},
+			wantRead:    true,
+			wantJobs:    1,
+		},
+		{
+			name: "other bucket may do nothing",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: idPtr(3)},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewDeleteJobService()
+			m.FindDeleteJobByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+				return jobs()[0], nil
+			}
+			m.FindDeleteJobsF = func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+				return jobs(), nil
+			}
+			s := authorizer.NewDeleteJobService(m)
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			check := func(op string, err error, want bool) {
+				t.Helper()
+				if want && err != nil {
+					t.Errorf("%s: unexpected error: %v", op, err)
+				}
+				if !want && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Errorf("%s: expected unauthorized, got: %v", op, err)
+				}
+			}
+
+			check("create", s.CreateDeleteJob(ctx, &influxdb.DeleteJob{OrgID: orgID, BucketID: bucketID}, nil), tt.wantWrite)
+			_, err := s.FindDeleteJobByID(ctx, 100)
+			check("find", err, tt.wantRead)
+			check("cancel", s.CancelDeleteJob(ctx, 100), tt.wantWrite)
+
+			found, err := s.FindDeleteJobs(ctx, influxdb.DeleteJobFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found) != tt.wantJobs {
+				t.Errorf("found %d jobs, want %d", len(found), tt.wantJobs)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.OrgLimitService = (*OrgLimitService)(nil)
+
+// OrgLimitService wraps a influxdb.OrgLimitService and authorizes actions
+// against it appropriately.
+type OrgLimitService struct {
+	s influxdb.OrgLimitService
+}
+
+// NewOrgLimitService constructs an instance of an authorizing org limit service.
+func NewOrgLimitService(s influxdb.OrgLimitService) *OrgLimitService {
+	return &OrgLimitService{
+		s: s,
+	}
+}
+
+// FindOrgLimits checks to see if the authorizer on context has read access to the organization.
+func (s *OrgLimitService) FindOrgLimits(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := authorizeReadOrg(ctx, orgID); err != nil {
+		return nil, err
+	}
+	return s.s.FindOrgLimits(ctx, orgID)
+}
+
+// PutOrgLimits checks to see if the authorizer on context has write access to all resources.
+// Members of an organization may not raise its limits.
+func (s *OrgLimitService) PutOrgLimits(ctx context.Context, l *influxdb.OrgLimits) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.PutOrgLimits(ctx, l)
+}
@@ -0,0 +1,69 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationService wraps a influxdb.ReplicationService and authorizes actions
+// against it appropriately.
+type ReplicationService struct {
+	s influxdb.ReplicationService
+}
+
+// NewReplicationService constructs an instance of an authorizing replication service.
+func NewReplicationService(s influxdb.ReplicationService) *ReplicationService {
+	return &ReplicationService{
+		s: s,
+	}
+}
+
+// ReplicationStatus checks to see if the authorizer on context has read access to all resources.
+func (s *ReplicationService) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.ReplicationStatus(ctx)
+}
+
+// FindKVChanges checks to see if the authorizer on context has operator permissions.
+// Changes contain every token and secret, so only operators may follow them.
+func (s *ReplicationService) FindKVChanges(ctx context.Context, filter influxdb.KVChangeFilter) ([]*influxdb.KVChange, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.FindKVChanges(ctx, filter)
+}
+
+// KVSnapshot checks to see if the authorizer on context has operator permissions.
+func (s *ReplicationService) KVSnapshot(ctx context.Context, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.KVSnapshot(ctx, w)
+}
+
+// PromoteReplica checks to see if the authorizer on context has operator permissions.
+func (s *ReplicationService) PromoteReplica(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return s.s.PromoteReplica(ctx)
+}
@@ -0,0 +1,64 @@
+package authorizer_test
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestReplicationService(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantStatus  bool
+		wantChanges bool
+	}{
+		{
+			name:        "operator may follow and promote",
+			permissions: influxdb.OperPermissions(),
+			wantStatus:  true,
+			wantChanges: true,
+		},
+		{
+			name:        "read all may only see status",
+			permissions: influxdb.ReadAllPermissions(),
+			wantStatus:  true,
+		},
+		{
+			name: "org member may do nothing",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.ReadAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewReplicationService(mock.NewReplicationService())
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			check := func(op string, err error, want bool) {
+				t.Helper()
+				if want && err != nil {
+					t.Errorf("%s: unexpected error: %v", op, err)
+				}
+				if !want && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Errorf("%s: expected unauthorized, got: %v", op, err)
+				}
+			}
+
+			_, err := s.ReplicationStatus(ctx)
+			check("status", err, tt.wantStatus)
+			_, err = s.FindKVChanges(ctx, influxdb.KVChangeFilter{})
+			check("changes", err, tt.wantChanges)
+			check("snapshot", s.KVSnapshot(ctx, ioutil.Discard), tt.wantChanges)
+			check("promote", s.PromoteReplica(ctx), tt.wantChanges)
+		})
+	}
+}
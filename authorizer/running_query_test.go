@@ -0,0 +1,95 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestRunningQueryService(t *testing.T) {
+	orgID := influxdb.ID(10)
+	queries := func() []*influxdb.RunningQuery {
+		return []*influxdb.RunningQuery{
+			{ID: 100, OrgID: orgID},
+			{ID: 200, OrgID: 20},
+		}
+	}
+	orgPermission := func(a influxdb.Action) influxdb.Permission {
+		return influxdb.Permission{
+			Action: a,
+			Resource: influxdb.Resource{
+				Type: influxdb.OrgsResourceType,
+				ID:   &orgID,
+			},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantRead    bool
+		wantWrite   bool
+		wantQueries int
+	}{
+		{
+			name:        "write org may find and cancel",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction), orgPermission(influxdb.WriteAction)},
+			wantRead:    true,
+			wantWrite:   true,
+			wantQueries: 1,
+		},
+		{
+			name:        "read org may only find",
+			permissions: []influxdb.Permission{orgPermission(influxdb.ReadAction)},
+			wantRead:    true,
+			wantQueries: 1,
+		},
+		{
+			name: "other org may do nothing",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: idPtr(30)},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mock.NewRunningQueryService()
+			m.FindRunningQueryByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+				return queries()[0], nil
+			}
+			m.FindRunningQueriesF = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+				return queries(), nil
+			}
+			s := authorizer.NewRunningQueryService(m)
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			check := func(op string, err error, want bool) {
+				t.Helper()
+				if want && err != nil {
+					t.Errorf("%s: unexpected error: %v", op, err)
+				}
+				if !want && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Errorf("%s: expected unauthorized, got: %v", op, err)
+				}
+			}
+
+			_, err := s.FindRunningQueryByID(ctx, 100)
+			check("find", err, tt.wantRead)
+			check("cancel", s.CancelRunningQuery(ctx, 100), tt.wantWrite)
+
+			found, err := s.FindRunningQueries(ctx, influxdb.RunningQueryFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(found) != tt.wantQueries {
+				t.Errorf("found %d queries, want %d", len(found), tt.wantQueries)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+)
+
+var _ influxdb.StorageModeService = (*StorageModeService)(nil)
+
+// StorageModeService wraps a influxdb.StorageModeService and authorizes actions
+// against it appropriately.
+type StorageModeService struct {
+	s influxdb.StorageModeService
+}
+
+// NewStorageModeService constructs an instance of an authorizing storage mode service.
+func NewStorageModeService(s influxdb.StorageModeService) *StorageModeService {
+	return &StorageModeService{
+		s: s,
+	}
+}
+
+// StorageMode checks to see if the authorizer on context has read access to all resources.
+func (s *StorageModeService) StorageMode(ctx context.Context) (*influxdb.StorageMode, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.StorageMode(ctx)
+}
+
+// SetStorageMode checks to see if the authorizer on context has operator permissions.
+func (s *StorageModeService) SetStorageMode(ctx context.Context, m influxdb.StorageMode) (*influxdb.StorageMode, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return s.s.SetStorageMode(ctx, m)
+}
@@ -0,0 +1,61 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+)
+
+func TestStorageModeService(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		wantGet     bool
+		wantSet     bool
+	}{
+		{
+			name:        "operator may change mode",
+			permissions: influxdb.OperPermissions(),
+			wantGet:     true,
+			wantSet:     true,
+		},
+		{
+			name:        "read all may only see mode",
+			permissions: influxdb.ReadAllPermissions(),
+			wantGet:     true,
+		},
+		{
+			name: "org member may do nothing",
+			permissions: []influxdb.Permission{{
+				Action:   influxdb.WriteAction,
+				Resource: influxdb.Resource{Type: influxdb.BucketsResourceType},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := authorizer.NewStorageModeService(mock.NewStorageModeService())
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), &Authorizer{tt.permissions})
+
+			check := func(op string, err error, want bool) {
+				t.Helper()
+				if want && err != nil {
+					t.Errorf("%s: unexpected error: %v", op, err)
+				}
+				if !want && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+					t.Errorf("%s: expected unauthorized, got: %v", op, err)
+				}
+			}
+
+			_, err := s.StorageMode(ctx)
+			check("get", err, tt.wantGet)
+			_, err = s.SetStorageMode(ctx, influxdb.StorageMode{ReadOnly: true})
+			check("set", err, tt.wantSet)
+		})
+	}
+}
@@ -41,6 +41,8 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	ColdAfter           time.Duration `json:"coldAfter,omitempty"`         // Age after which data is moved to the cold tier; 0 keeps it local.
+	PartitionDuration   time.Duration `json:"partitionDuration,omitempty"` // Duration of the time partitions TSM files are split into; 0 disables partitioning.
 	CRUDLog
 }
 
@@ -101,9 +103,11 @@ type BucketService interface {
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
-	Name            *string        `json:"name,omitempty"`
-	Description     *string        `json:"description,omitempty"`
-	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	Name              *string        `json:"name,omitempty"`
+	Description       *string        `json:"description,omitempty"`
+	RetentionPeriod   *time.Duration `json:"retentionPeriod,omitempty"`
+	ColdAfter         *time.Duration `json:"coldAfter,omitempty"`
+	PartitionDuration *time.Duration `json:"partitionDuration,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
@@ -0,0 +1,44 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// StorageStats summarizes the data stored for a bucket or a measurement.
+type StorageStats struct {
+	SeriesCardinality int64 `json:"seriesCardinality"`
+	// TSMBytes is the size of the compressed blocks in TSM files.
+	TSMBytes int64 `json:"tsmBytes"`
+	// WALBytes is the size of the values written to the WAL that are not
+	// yet in TSM files.
+	WALBytes int64 `json:"walBytes"`
+	// OldestTime and NewestTime bound the timestamps of the stored data.
+	// Deleted data may still be counted until it is compacted away.
+	OldestTime *time.Time `json:"oldestTime,omitempty"`
+	NewestTime *time.Time `json:"newestTime,omitempty"`
+	// Tombstones is the number of deletes recorded against TSM files that
+	// compactions have not yet applied.
+	Tombstones int64 `json:"tombstones"`
+}
+
+// MeasurementStorageStats summarizes the data stored for a measurement.
+type MeasurementStorageStats struct {
+	Name string `json:"name"`
+	StorageStats
+}
+
+// BucketStats summarizes the data stored for a bucket.
+type BucketStats struct {
+	BucketID ID `json:"bucketID"`
+	OrgID    ID `json:"orgID"`
+	StorageStats
+	Measurements []MeasurementStorageStats `json:"measurements"`
+}
+
+// BucketStatsService returns statistics about the data stored in buckets.
+type BucketStatsService interface {
+	// BucketStats returns the stats of bucketID, with its measurements
+	// sorted by name.
+	BucketStats(ctx context.Context, orgID, bucketID ID) (*BucketStats, error)
+}
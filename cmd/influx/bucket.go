@@ -28,6 +28,8 @@ type cmdBucketBuilder struct {
 	description string
 	org         organization
 	retention   time.Duration
+	coldAfter   time.Duration
+	partition   time.Duration
 }
 
 func newCmdBucketBuilder(svcsFn bucketSVCsFn, opts ...genericCLIOptFn) *cmdBucketBuilder {
@@ -54,6 +56,7 @@ func (b *cmdBucketBuilder) cmd() *cobra.Command {
 		b.cmdCreate(),
 		b.cmdDelete(),
 		b.cmdFind(),
+		b.cmdStats(),
 		b.cmdUpdate(),
 	)
 
@@ -78,6 +81,8 @@ func (b *cmdBucketBuilder) cmdCreate() *cobra.Command {
 
 	cmd.Flags().StringVarP(&b.description, "description", "d", "", "Description of bucket that will be created")
 	cmd.Flags().DurationVarP(&b.retention, "retention", "r", 0, "Duration in nanoseconds data will live in bucket")
+	cmd.Flags().DurationVar(&b.coldAfter, "cold-after", 0, "Age after which data is moved to the cold tier object store")
+	cmd.Flags().DurationVar(&b.partition, "partition-duration", 0, "Duration of the time partitions data is split into so expired data is removed in whole files")
 	b.org.register(cmd, false)
 
 	return cmd
@@ -94,9 +99,11 @@ func (b *cmdBucketBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
 	}
 
 	bkt := &influxdb.Bucket{
-		Name:            b.name,
-		Description:     b.description,
-		RetentionPeriod: b.retention,
+		Name:              b.name,
+		Description:       b.description,
+		RetentionPeriod:   b.retention,
+		ColdAfter:         b.coldAfter,
+		PartitionDuration: b.partition,
 	}
 	bkt.OrgID, err = b.org.getID(orgSVC)
 	if err != nil {
@@ -240,6 +247,65 @@ func (b *cmdBucketBuilder) cmdFindRunEFn(cmd *cobra.Command, args []string) erro
 	return nil
 }
 
+func (b *cmdBucketBuilder) cmdStats() *cobra.Command {
+	cmd := b.newCmd("stats", b.cmdStatsRunEFn)
+	cmd.Short = "Show the disk usage and series cardinality of a bucket by measurement"
+
+	cmd.Flags().StringVarP(&b.id, "id", "i", "", "The bucket ID (required)")
+	cmd.MarkFlagRequired("id")
+	cmd.Flags().BoolVar(&b.headers, "headers", true, "To print the table headers; defaults true")
+
+	return cmd
+}
+
+func (b *cmdBucketBuilder) cmdStatsRunEFn(cmd *cobra.Command, args []string) error {
+	bktSVC, _, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+	statsSVC, ok := bktSVC.(influxdb.BucketStatsService)
+	if !ok {
+		return fmt.Errorf("bucket stats are not supported")
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(b.id); err != nil {
+		return fmt.Errorf("failed to decode bucket id %q: %v", b.id, err)
+	}
+
+	// The server finds the organization of the bucket.
+	stats, err := statsSVC.BucketStats(context.Background(), 0, id)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve stats of bucket with id %q: %v", id, err)
+	}
+
+	w := b.newTabWriter()
+	w.HideHeaders(!b.headers)
+	w.WriteHeaders("Measurement", "Series", "TSMBytes", "WALBytes", "Oldest", "Newest", "Tombstones")
+	write := func(name string, s influxdb.StorageStats) {
+		oldest, newest := "-", "-"
+		if s.OldestTime != nil && s.NewestTime != nil {
+			oldest, newest = s.OldestTime.Format(time.RFC3339Nano), s.NewestTime.Format(time.RFC3339Nano)
+		}
+		w.Write(map[string]interface{}{
+			"Measurement": name,
+			"Series":      s.SeriesCardinality,
+			"TSMBytes":    s.TSMBytes,
+			"WALBytes":    s.WALBytes,
+			"Oldest":      oldest,
+			"Newest":      newest,
+			"Tombstones":  s.Tombstones,
+		})
+	}
+	for _, m := range stats.Measurements {
+		write(m.Name, m.StorageStats)
+	}
+	write("(total)", stats.StorageStats)
+	w.Flush()
+
+	return nil
+}
+
 func (b *cmdBucketBuilder) cmdUpdate() *cobra.Command {
 	cmd := b.newCmd("update", b.cmdUpdateRunEFn)
 	cmd.Short = "Update bucket"
@@ -259,6 +325,8 @@ func (b *cmdBucketBuilder) cmdUpdate() *cobra.Command {
 	cmd.Flags().StringVarP(&b.description, "description", "d", "", "Description of bucket that will be created")
 	cmd.MarkFlagRequired("id")
 	cmd.Flags().DurationVarP(&b.retention, "retention", "r", 0, "New duration data will live in bucket")
+	cmd.Flags().DurationVar(&b.coldAfter, "cold-after", 0, "New age after which data is moved to the cold tier object store")
+	cmd.Flags().DurationVar(&b.partition, "partition-duration", 0, "New duration of the time partitions data is split into")
 
 	return cmd
 }
@@ -284,6 +352,29 @@ func (b *cmdBucketBuilder) cmdUpdateRunEFn(cmd *cobra.Command, args []string) er
 	if b.retention != 0 {
 		update.RetentionPeriod = &b.retention
 	}
+	if b.coldAfter != 0 {
+		update.ColdAfter = &b.coldAfter
+	}
+	if b.partition != 0 {
+		update.PartitionDuration = &b.partition
+	}
+	if update.ColdAfter != nil || update.PartitionDuration != nil {
+		// Retention rules are replaced together, so keep the current
+		// rules unless new ones are given.
+		bkt, err := bktSVC.FindBucketByID(context.Background(), id)
+		if err != nil {
+			return fmt.Errorf("failed to find bucket with id %q: %v", id, err)
+		}
+		if update.RetentionPeriod == nil {
+			update.RetentionPeriod = &bkt.RetentionPeriod
+		}
+		if update.ColdAfter == nil {
+			update.ColdAfter = &bkt.ColdAfter
+		}
+		if update.PartitionDuration == nil {
+			update.PartitionDuration = &bkt.PartitionDuration
+		}
+	}
 
 	bkt, err := bktSVC.UpdateBucket(context.Background(), id, update)
 	if err != nil {
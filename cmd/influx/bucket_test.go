@@ -377,6 +377,38 @@ func TestCmdBucket(t *testing.T) {
 			t.Run(tt.name, fn)
 		}
 	})
+
+	t.Run("stats", func(t *testing.T) {
+		svc := struct {
+			*mock.BucketService
+			*mock.BucketStatsService
+		}{mock.NewBucketService(), mock.NewBucketStatsService()}
+
+		oldest := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+		svc.BucketStatsFn = func(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.BucketStats, error) {
+			if bucketID != 3 {
+				return nil, fmt.Errorf("unexpected id:\n\twant= %s\n\tgot=  %s", influxdb.ID(3), bucketID)
+			}
+			s := influxdb.StorageStats{SeriesCardinality: 7, TSMBytes: 1024, WALBytes: 16, OldestTime: &oldest, NewestTime: &oldest}
+			return &influxdb.BucketStats{
+				BucketID:     bucketID,
+				StorageStats: s,
+				Measurements: []influxdb.MeasurementStorageStats{{Name: "cpu", StorageStats: s}},
+			}, nil
+		}
+
+		var buf bytes.Buffer
+		builder := newCmdBucketBuilder(fakeSVCFn(svc), out(&buf))
+		cmd := builder.cmdStats()
+		cmd.RunE = builder.cmdStatsRunEFn
+		cmd.SetArgs([]string{"--id=" + influxdb.ID(3).String()})
+		require.NoError(t, cmd.Execute())
+
+		output := buf.String()
+		for _, want := range []string{"Measurement", "cpu", "(total)", "1024", "2019-01-01T00:00:00Z"} {
+			assert.Contains(t, output, want)
+		}
+	})
 }
 
 func strPtr(s string) *string {
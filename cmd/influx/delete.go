@@ -3,13 +3,21 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
 	"github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/kit/signals"
 	"github.com/spf13/cobra"
 )
 
-var deleteFlags http.DeleteRequest
+var deleteFlags struct {
+	http.DeleteRequest
+	dryRun bool
+	async  bool
+}
 
 func cmdDelete() *cobra.Command {
 	cmd := &cobra.Command{
@@ -52,7 +60,11 @@ func cmdDelete() *cobra.Command {
 
 	cmd.PersistentFlags().StringVar(&deleteFlags.Start, "start", "", "the start time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
 	cmd.PersistentFlags().StringVar(&deleteFlags.Stop, "stop", "", "the stop time in RFC3339Nano format, exp 2009-01-02T23:00:00Z")
-	cmd.PersistentFlags().StringVarP(&deleteFlags.Predicate, "predicate", "p", "", "sql like predicate string, exp 'tag1=\"v1\" and (tag2=123)'")
+	cmd.PersistentFlags().StringVarP(&deleteFlags.Predicate, "predicate", "p", "", "sql like predicate string, exp 'tag1=\"v1\" and (tag2=123 or tag3=~/^v3/)'")
+	cmd.PersistentFlags().BoolVar(&deleteFlags.dryRun, "dry-run", false, "report the number of series and points that would be deleted, without deleting them")
+	cmd.Flags().BoolVar(&deleteFlags.async, "async", false, "run the delete in the background and print its job")
+
+	cmd.AddCommand(deleteJobCmd())
 
 	return cmd
 }
@@ -77,9 +89,174 @@ func fluxDeleteF(cmd *cobra.Command, args []string) error {
 	}
 
 	ctx := signals.WithStandardSignals(context.Background())
-	if err := s.DeleteBucketRangePredicate(ctx, deleteFlags); err != nil && err != context.Canceled {
+	if deleteFlags.dryRun {
+		stats, err := s.PreviewBucketRangePredicate(ctx, deleteFlags.DeleteRequest)
+		if err == context.Canceled {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to preview delete: %v", err)
+		}
+
+		w := internal.NewTabWriter(os.Stdout)
+		w.WriteHeaders("Series", "Points")
+		w.Write(map[string]interface{}{
+			"Series": stats.Series,
+			"Points": stats.Points,
+		})
+		w.Flush()
+		return nil
+	}
+
+	if deleteFlags.async {
+		job, err := s.CreateDeleteJob(ctx, deleteFlags.DeleteRequest)
+		if err == context.Canceled {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to create delete job: %v", err)
+		}
+		writeDeleteJobs(job)
+		return nil
+	}
+
+	if err := s.DeleteBucketRangePredicate(ctx, deleteFlags.DeleteRequest); err != nil && err != context.Canceled {
 		return fmt.Errorf("failed to delete data: %v", err)
 	}
 
 	return nil
 }
+
+func deleteJobCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "job",
+		Short: "Delete job related commands",
+		Run:   seeHelp,
+	}
+	cmd.AddCommand(
+		deleteJobFindCmd(),
+		deleteJobCancelCmd(),
+	)
+
+	return cmd
+}
+
+var deleteJobFlags struct {
+	id string
+}
+
+func deleteJobFindCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "find",
+		Short: "Find delete jobs and their progress",
+		RunE:  wrapCheckSetup(deleteJobFindF),
+	}
+
+	cmd.Flags().StringVarP(&deleteJobFlags.id, "id", "i", "", "The delete job ID")
+
+	return cmd
+}
+
+func deleteJobFindF(cmd *cobra.Command, args []string) error {
+	s := &http.DeleteService{
+		Addr:               flags.host,
+		Token:              flags.token,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+
+	if deleteJobFlags.id != "" {
+		id, err := influxdb.IDFromString(deleteJobFlags.id)
+		if err != nil {
+			return err
+		}
+		job, err := s.FindDeleteJobByID(context.Background(), *id)
+		if err != nil {
+			return err
+		}
+		writeDeleteJobs(job)
+		return nil
+	}
+
+	var filter influxdb.DeleteJobFilter
+	if deleteFlags.OrgID != "" {
+		id, err := influxdb.IDFromString(deleteFlags.OrgID)
+		if err != nil {
+			return err
+		}
+		filter.OrgID = id
+	}
+	if deleteFlags.BucketID != "" {
+		id, err := influxdb.IDFromString(deleteFlags.BucketID)
+		if err != nil {
+			return err
+		}
+		filter.BucketID = id
+	}
+
+	jobs, err := s.FindDeleteJobs(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	writeDeleteJobs(jobs...)
+	return nil
+}
+
+func deleteJobCancelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel a pending or running delete job",
+		Long: `Cancel a pending or running delete job. A running job can only be
+	canceled before it starts deleting data; after that it runs to completion.`,
+		RunE: wrapCheckSetup(deleteJobCancelF),
+	}
+
+	cmd.Flags().StringVarP(&deleteJobFlags.id, "id", "i", "", "The delete job ID (required)")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func deleteJobCancelF(cmd *cobra.Command, args []string) error {
+	s := &http.DeleteService{
+		Addr:               flags.host,
+		Token:              flags.token,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(deleteJobFlags.id); err != nil {
+		return err
+	}
+	if err := s.CancelDeleteJob(context.Background(), id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Delete job %s canceled.\n", id)
+	return nil
+}
+
+func writeDeleteJobs(jobs ...*influxdb.DeleteJob) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"BucketID",
+		"Status",
+		"Files",
+		"Tombstones",
+		"SeriesDeleted",
+		"CreatedAt",
+		"Error",
+	)
+
+	for _, j := range jobs {
+		w.Write(map[string]interface{}{
+			"ID":            j.ID,
+			"BucketID":      j.BucketID,
+			"Status":        j.Status,
+			"Files":         fmt.Sprintf("%d/%d", j.Progress.FilesScanned+j.Progress.FilesRemoved, j.Progress.FilesTotal),
+			"Tombstones":    j.Progress.TombstonesWritten,
+			"SeriesDeleted": j.Progress.SeriesDeleted,
+			"CreatedAt":     j.CreatedAt.Format(time.RFC3339),
+			"Error":         j.Error,
+		})
+	}
+	w.Flush()
+}
@@ -134,6 +134,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdREPL(),
 		cmdSecret(runEWrapper),
 		cmdSetup(),
+		cmdStorage(),
 		cmdTask(),
 		cmdUser(runEWrapper),
 		cmdWrite(),
@@ -1,17 +1,29 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/repl"
 	_ "github.com/influxdata/flux/stdlib"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/stdlib"
 	"github.com/spf13/cobra"
 )
 
 var queryFlags struct {
-	org organization
+	org    organization
+	params []string
 }
 
 func cmdQuery() *cobra.Command {
@@ -19,11 +31,21 @@ func cmdQuery() *cobra.Command {
 		Use:   "query [query literal or @/path/to/query.flux]",
 		Short: "Execute a Flux query",
 		Long: `Execute a literal Flux query provided as a string,
-or execute a literal Flux query contained in a file by specifying the file prefixed with an @ sign.`,
+or execute a literal Flux query contained in a file by specifying the file prefixed with an @ sign.
+
+Queries read the parameters given with --param from the params record,
+like params.host. Parameter values are JSON, or strings when they are not
+valid JSON.`,
 		Args: cobra.ExactArgs(1),
 		RunE: wrapCheckSetup(fluxQueryF),
 	}
 	queryFlags.org.register(cmd, true)
+	cmd.Flags().StringArrayVarP(&queryFlags.params, "param", "p", nil, "A query parameter as name=value")
+
+	cmd.AddCommand(
+		queryPSCmd(),
+		queryKillCmd(),
+	)
 
 	return cmd
 }
@@ -42,6 +64,11 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load query: %v", err)
 	}
 
+	params, err := parseQueryParams(queryFlags.params)
+	if err != nil {
+		return err
+	}
+
 	orgSvc, err := newOrganizationService()
 	if err != nil {
 		return fmt.Errorf("failed to initialized organization service client: %v", err)
@@ -54,6 +81,10 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 
 	flux.FinalizeBuiltIns()
 
+	if len(params) > 0 {
+		return fluxQueryWithParams(q, params, orgID)
+	}
+
 	r, err := getFluxREPL(flags.host, flags.token, flags.skipVerify, orgID)
 	if err != nil {
 		return fmt.Errorf("failed to get the flux REPL: %v", err)
@@ -65,3 +96,187 @@ func fluxQueryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// parseQueryParams parses query parameters given as name=value.
+func parseQueryParams(args []string) (query.Params, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	raw := make(map[string]interface{}, len(args))
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid query parameter %q: must be name=value", arg)
+		}
+		if json.Valid([]byte(parts[1])) {
+			raw[parts[0]] = json.RawMessage(parts[1])
+		} else {
+			raw[parts[0]] = parts[1]
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var params query.Params
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("invalid query parameters: %v", err)
+	}
+	if _, err := params.File(); err != nil {
+		return nil, fmt.Errorf("invalid query parameters: %v", err)
+	}
+	return params, nil
+}
+
+// fluxQueryWithParams runs a query in the server with the parameters
+// assigned to its params record. Unlike the REPL, the query is not
+// evaluated locally, so the parameters are never part of its text.
+func fluxQueryWithParams(q string, params query.Params, orgID influxdb.ID) error {
+	extern, err := params.File()
+	if err != nil {
+		return err
+	}
+	qs := &http.FluxQueryService{
+		Addr:               flags.host,
+		Token:              flags.token,
+		InsecureSkipVerify: flags.skipVerify,
+	}
+	results, err := qs.Query(context.Background(), &query.Request{
+		OrganizationID: orgID,
+		Compiler: lang.FluxCompiler{
+			Query:  q,
+			Extern: extern,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+	defer results.Release()
+
+	for results.More() {
+		result := results.Next()
+		fmt.Println("Result:", result.Name())
+		if err := result.Tables().Do(func(tbl flux.Table) error {
+			_, err := execute.NewFormatter(tbl, nil).WriteTo(os.Stdout)
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to execute query: %v", err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return fmt.Errorf("failed to execute query: %v", err)
+	}
+	return nil
+}
+
+func queryPSCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "ps",
+		Short: "List the running queries",
+		Long: `List the queries that are running in the server, oldest first.
+Only the queries of the organization are listed when one is given.`,
+		Args: cobra.NoArgs,
+		RunE: wrapCheckSetup(queryPSF),
+	}
+}
+
+func queryPSF(cmd *cobra.Command, args []string) error {
+	s, err := newRunningQueryService()
+	if err != nil {
+		return err
+	}
+
+	var filter influxdb.RunningQueryFilter
+	if queryFlags.org.id != "" || queryFlags.org.name != "" {
+		orgSvc, err := newOrganizationService()
+		if err != nil {
+			return fmt.Errorf("failed to initialized organization service client: %v", err)
+		}
+		orgID, err := queryFlags.org.getID(orgSvc)
+		if err != nil {
+			return err
+		}
+		filter.OrgID = &orgID
+	}
+
+	qs, err := s.FindRunningQueries(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ID",
+		"OrgID",
+		"UserID",
+		"AuthorizationID",
+		"State",
+		"Elapsed",
+		"Memory",
+		"Query",
+	)
+	for _, q := range qs {
+		w.Write(map[string]interface{}{
+			"ID":              q.ID,
+			"OrgID":           q.OrgID,
+			"UserID":          q.UserID,
+			"AuthorizationID": q.AuthorizationID,
+			"State":           q.State,
+			"Elapsed":         q.Elapsed.Round(time.Millisecond),
+			"Memory":          q.MemoryBytes,
+			"Query":           strings.Join(strings.Fields(q.Query), " "),
+		})
+	}
+	w.Flush()
+	return nil
+}
+
+var queryKillFlags struct {
+	id string
+}
+
+func queryKillCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kill",
+		Short: "Kill a running query",
+		Long:  `Kill a running query. The client running the query receives an error.`,
+		Args:  cobra.NoArgs,
+		RunE:  wrapCheckSetup(queryKillF),
+	}
+
+	cmd.Flags().StringVarP(&queryKillFlags.id, "id", "i", "", "The running query ID (required)")
+	cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func queryKillF(cmd *cobra.Command, args []string) error {
+	s, err := newRunningQueryService()
+	if err != nil {
+		return err
+	}
+
+	var id influxdb.ID
+	if err := id.DecodeFromString(queryKillFlags.id); err != nil {
+		return err
+	}
+	if err := s.CancelRunningQuery(context.Background(), id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Query %s killed.\n", id)
+	return nil
+}
+
+func newRunningQueryService() (influxdb.RunningQueryService, error) {
+	if flags.local {
+		return nil, fmt.Errorf("local flag not supported for query command")
+	}
+
+	client, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &http.RunningQueryService{Client: client}, nil
+}
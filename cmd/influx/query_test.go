@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseQueryParams(t *testing.T) {
+	t.Run("typed values", func(t *testing.T) {
+		params, err := parseQueryParams([]string{
+			`host=server01`,
+			`quoted="10"`,
+			`limit=10`,
+			`ratio=0.5`,
+			`ok=true`,
+			`hosts=["a", "b"]`,
+			`expr=a=b`,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, query.Params{
+			"host":   "server01",
+			"quoted": "10",
+			"limit":  int64(10),
+			"ratio":  0.5,
+			"ok":     true,
+			"hosts":  []interface{}{"a", "b"},
+			"expr":   "a=b",
+		}, params)
+	})
+
+	t.Run("no params", func(t *testing.T) {
+		params, err := parseQueryParams(nil)
+		require.NoError(t, err)
+		assert.Nil(t, params)
+	})
+
+	for _, arg := range []string{"host", "=a", "not-an-identifier=a", "n=null"} {
+		t.Run(arg, func(t *testing.T) {
+			_, err := parseQueryParams([]string{arg})
+			require.Error(t, err)
+		})
+	}
+}
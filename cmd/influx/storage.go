@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/cmd/influx/internal"
+	"github.com/influxdata/influxdb/http"
+	"github.com/spf13/cobra"
+)
+
+func cmdStorage() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Storage engine management commands",
+		RunE: wrapCheckSetup(func(cmd *cobra.Command, args []string) error {
+			if flags.local {
+				return fmt.Errorf("local flag not supported for storage command")
+			}
+
+			seeHelp(cmd, args)
+			return nil
+		}),
+	}
+
+	cmd.AddCommand(
+		storageModeCmd(),
+		storageReadOnlyCmd(),
+		storageReadWriteCmd(),
+	)
+
+	return cmd
+}
+
+func storageModeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mode",
+		Short: "Show the mode of the storage engine",
+		RunE: wrapCheckSetup(func(cmd *cobra.Command, args []string) error {
+			s, err := newStorageModeService()
+			if err != nil {
+				return err
+			}
+
+			m, err := s.StorageMode(context.Background())
+			if err != nil {
+				return err
+			}
+			writeStorageMode(m)
+			return nil
+		}),
+	}
+}
+
+var storageReadOnlyFlags struct {
+	pauseCompactions bool
+}
+
+func storageReadOnlyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "read-only",
+		Short: "Put the storage engine in read-only mode",
+		Long: `Rejects writes and deletes while still serving queries, and flushes the WAL to TSM files.
+Use --pause-compactions to also stop compactions, so TSM files are not changed while they are copied.`,
+		RunE: wrapCheckSetup(func(cmd *cobra.Command, args []string) error {
+			return setStorageMode(influxdb.StorageMode{
+				ReadOnly:         true,
+				PauseCompactions: storageReadOnlyFlags.pauseCompactions,
+			})
+		}),
+	}
+
+	cmd.Flags().BoolVar(&storageReadOnlyFlags.pauseCompactions, "pause-compactions", false, "pause compactions while read-only")
+
+	return cmd
+}
+
+func storageReadWriteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "read-write",
+		Short: "Put the storage engine back in read-write mode, resuming compactions",
+		RunE: wrapCheckSetup(func(cmd *cobra.Command, args []string) error {
+			return setStorageMode(influxdb.StorageMode{})
+		}),
+	}
+}
+
+func newStorageModeService() (influxdb.StorageModeService, error) {
+	if flags.local {
+		return nil, fmt.Errorf("local flag not supported for storage command")
+	}
+
+	client, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &http.StorageModeService{Client: client}, nil
+}
+
+func setStorageMode(m influxdb.StorageMode) error {
+	s, err := newStorageModeService()
+	if err != nil {
+		return err
+	}
+
+	mode, err := s.SetStorageMode(context.Background(), m)
+	if err != nil {
+		return err
+	}
+	writeStorageMode(mode)
+	return nil
+}
+
+func writeStorageMode(m *influxdb.StorageMode) {
+	w := internal.NewTabWriter(os.Stdout)
+	w.WriteHeaders(
+		"ReadOnly",
+		"PauseCompactions",
+	)
+	w.Write(map[string]interface{}{
+		"ReadOnly":         m.ReadOnly,
+		"PauseCompactions": m.PauseCompactions,
+	})
+	w.Flush()
+}
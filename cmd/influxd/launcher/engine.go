@@ -9,12 +9,14 @@ import (
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/kit/prom"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/readservice"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxdb/tsdb/value"
 	"github.com/influxdata/influxql"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -26,13 +28,18 @@ var _ Engine = (*storage.Engine)(nil)
 // to facilitate testing.
 type Engine interface {
 	influxdb.DeleteService
+	storage.ProgressDeleter
 	readservice.Viewer
 	storage.PointsWriter
 	storage.BucketDeleter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	influxdb.BucketStatsService
+	influxdb.StorageModeService
+	check.Checker
 
 	SeriesCardinality() int64
+	OrgSeriesCardinality(orgID influxdb.ID) (int64, error)
 
 	WithLogger(log *zap.Logger)
 	Open(context.Context) error
@@ -110,17 +117,43 @@ func (t *TemporaryEngine) WritePoints(ctx context.Context, points []models.Point
 	return t.engine.WritePoints(ctx, points)
 }
 
+// Check reports the health of the storage engine.
+func (t *TemporaryEngine) Check(ctx context.Context) check.Response {
+	return t.engine.Check(ctx)
+}
+
 // SeriesCardinality returns the number of series in the engine.
 func (t *TemporaryEngine) SeriesCardinality() int64 {
 	return t.engine.SeriesCardinality()
 }
 
+// OrgSeriesCardinality returns the number of series stored by orgID.
+func (t *TemporaryEngine) OrgSeriesCardinality(orgID influxdb.ID) (int64, error) {
+	return t.engine.OrgSeriesCardinality(orgID)
+}
+
 // DeleteBucketRangePredicate will delete a bucket from the range and predicate.
 func (t *TemporaryEngine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	return t.engine.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
 
 }
 
+// DeleteBucketRangePredicateWithProgress will delete a bucket from the range and
+// predicate, recording its progress.
+func (t *TemporaryEngine) DeleteBucketRangePredicateWithProgress(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+	return t.engine.DeleteBucketRangePredicateWithProgress(ctx, orgID, bucketID, min, max, pred, progress)
+}
+
+// BucketStats returns the stats of the data stored for a bucket.
+func (t *TemporaryEngine) BucketStats(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.BucketStats, error) {
+	return t.engine.BucketStats(ctx, orgID, bucketID)
+}
+
+// PreviewBucketRangePredicate returns the amount of data a delete would remove.
+func (t *TemporaryEngine) PreviewBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) (*influxdb.DeleteStats, error) {
+	return t.engine.PreviewBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+}
+
 // DeleteBucket deletes a bucket from the time-series data.
 func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID) error {
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
@@ -147,6 +180,21 @@ func (t *TemporaryEngine) CreateSeriesCursor(ctx context.Context, req storage.Se
 	return t.engine.CreateSeriesCursor(ctx, req, cond)
 }
 
+// LastValue calls into the underlying engines LastValue.
+func (t *TemporaryEngine) LastValue(ctx context.Context, name []byte, tags models.Tags, field string, start, end int64) (value.Value, error) {
+	return t.engine.LastValue(ctx, name, tags, field, start, end)
+}
+
+// StorageMode calls into the underlying engines StorageMode.
+func (t *TemporaryEngine) StorageMode(ctx context.Context) (*influxdb.StorageMode, error) {
+	return t.engine.StorageMode(ctx)
+}
+
+// SetStorageMode calls into the underlying engines SetStorageMode.
+func (t *TemporaryEngine) SetStorageMode(ctx context.Context, m influxdb.StorageMode) (*influxdb.StorageMode, error) {
+	return t.engine.SetStorageMode(ctx, m)
+}
+
 // TagKeys calls into the underlying engines TagKeys.
 func (t *TemporaryEngine) TagKeys(ctx context.Context, orgID, bucketID influxdb.ID, start, end int64, predicate influxql.Expr) (cursors.StringIterator, error) {
 	return t.engine.TagKeys(ctx, orgID, bucketID, start, end, predicate)
@@ -3,9 +3,11 @@ package launcher
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"net"
 	nethttp "net/http"
@@ -32,13 +34,18 @@ import (
 	"github.com/influxdata/influxdb/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/limits"
 	influxlogger "github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/nats"
 	"github.com/influxdata/influxdb/pkger"
 	infprom "github.com/influxdata/influxdb/prometheus"
 	"github.com/influxdata/influxdb/query"
+	querycache "github.com/influxdata/influxdb/query/cache"
 	"github.com/influxdata/influxdb/query/control"
+	"github.com/influxdata/influxdb/query/slowlog"
 	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/replication"
+	"github.com/influxdata/influxdb/secret"
 	"github.com/influxdata/influxdb/snowflake"
 	"github.com/influxdata/influxdb/source"
 	"github.com/influxdata/influxdb/storage"
@@ -50,8 +57,9 @@ import (
 	"github.com/influxdata/influxdb/task/backend/middleware"
 	"github.com/influxdata/influxdb/task/backend/scheduler"
 	"github.com/influxdata/influxdb/telemetry"
+	"github.com/influxdata/influxdb/toml"
 	_ "github.com/influxdata/influxdb/tsdb/tsi1" // needed for tsi1
-	_ "github.com/influxdata/influxdb/tsdb/tsm1" // needed for tsm1
+	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/influxdata/influxdb/vault"
 	pzap "github.com/influxdata/influxdb/zap"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -180,6 +188,16 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: "bolt",
 			Desc:    "data store for secrets (bolt or vault)",
 		},
+		{
+			DestP: &l.secretKeyFile,
+			Flag:  "secret-encryption-key-file",
+			Desc:  "path to a file of id:base64-key entries used to encrypt secrets stored in bolt; the first key encrypts, all keys decrypt",
+		},
+		{
+			DestP: &l.secretKeys,
+			Flag:  "secret-encryption-key",
+			Desc:  "comma separated id:base64-key entries used to encrypt secrets stored in bolt, as an alternative to secret-encryption-key-file",
+		},
 		{
 			DestP:   &l.reportingDisabled,
 			Flag:    "reporting-disabled",
@@ -198,6 +216,46 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disables automatically extending session ttl on request",
 		},
+		{
+			DestP:   &l.auditLogEnabled,
+			Flag:    "audit-log",
+			Default: false,
+			Desc:    "record every create, update and delete made through the API in the audit log",
+		},
+		{
+			DestP:   &l.auditLogRetention,
+			Flag:    "audit-log-retention",
+			Default: 30 * 24 * time.Hour,
+			Desc:    "duration audit events are kept for; 0 keeps them forever",
+		},
+		{
+			DestP:   &l.replicationLog,
+			Flag:    "replication-log",
+			Default: false,
+			Desc:    "record metadata changes so that followers can replicate this instance; requires the bolt store",
+		},
+		{
+			DestP:   &l.replicationMaxChanges,
+			Flag:    "replication-max-changes",
+			Default: replication.DefaultMaxChanges,
+			Desc:    "number of metadata changes kept for followers; followers further behind are reinitialized from a snapshot",
+		},
+		{
+			DestP: &l.replicateFrom,
+			Flag:  "replicate-from",
+			Desc:  "URL of a primary influxd to follow; metadata is read-only until promoted through /api/v2/replication/promote",
+		},
+		{
+			DestP: &l.replicateToken,
+			Flag:  "replicate-token",
+			Desc:  "operator token used to follow the primary",
+		},
+		{
+			DestP:   &l.replicateSkipVerify,
+			Flag:    "replicate-skip-verify",
+			Default: false,
+			Desc:    "do not verify the TLS certificate of the primary",
+		},
 		{
 			DestP: &vaultConfig.Address,
 			Flag:  "vault-addr",
@@ -260,6 +318,120 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: "",
 			Desc:    "TLS key for HTTPs",
 		},
+		{
+			DestP: &l.httpTLSClientCA,
+			Flag:  "tls-client-ca",
+			Desc:  "PEM encoded CA certificates used to verify TLS client certificates; requires tls-cert and tls-key",
+		},
+		{
+			DestP: &l.httpTLSClientAuthFile,
+			Flag:  "tls-client-auth-file",
+			Desc:  "file mapping verified client certificate identities (cn:, dns:, email: or uri:) to the authorization IDs they act as, one \"identity id\" pair per line",
+		},
+		{
+			DestP: &l.StorageConfig.ColdTier.URL,
+			Flag:  "cold-tier-url",
+			Desc:  "object store that TSM files are offloaded to once their buckets' cold tier age passes, as file:///path or s3://bucket/prefix?endpoint=...&region=...",
+		},
+		{
+			DestP:   &l.coldTierInterval,
+			Flag:    "cold-tier-interval",
+			Default: storage.DefaultColdTierInterval,
+			Desc:    "interval between checks for TSM files to offload to the cold tier",
+		},
+		{
+			DestP:   &l.coldTierCacheSize,
+			Flag:    "cold-tier-cache-size",
+			Default: storage.DefaultColdTierCacheSize,
+			Desc:    "maximum bytes of blocks read from the cold tier kept in memory",
+		},
+		{
+			DestP:   &l.coldTierTimeout,
+			Flag:    "cold-tier-timeout",
+			Default: storage.DefaultColdTierTimeout,
+			Desc:    "maximum time of each read of a block from the cold tier, and of each delete of a file in it",
+		},
+		{
+			DestP: &l.StorageConfig.ColdTier.AccessKeyID,
+			Flag:  "cold-tier-access-key-id",
+			Desc:  "access key ID for an s3 cold tier; defaults to the AWS_ACCESS_KEY_ID environment variable",
+		},
+		{
+			DestP: &l.StorageConfig.ColdTier.SecretAccessKey,
+			Flag:  "cold-tier-secret-access-key",
+			Desc:  "secret access key for an s3 cold tier; defaults to the AWS_SECRET_ACCESS_KEY environment variable",
+		},
+		{
+			DestP: &l.StorageConfig.TSDB.MaxSeriesPerBucket,
+			Flag:  "max-series-per-bucket",
+			Desc:  "maximum number of series in a bucket; writes creating series beyond it are rejected (0 disables the limit)",
+		},
+		{
+			DestP: &l.StorageConfig.TSDB.MaxSeriesPerMeasurement,
+			Flag:  "max-series-per-measurement",
+			Desc:  "maximum number of series in a measurement of a bucket; writes creating series beyond it are rejected (0 disables the limit)",
+		},
+		{
+			DestP:   &l.StorageConfig.Engine.Compression.String,
+			Flag:    "string-compression",
+			Default: tsm1.DefaultStringCompression,
+			Desc:    "codec of string blocks in TSM files, snappy or zstd; existing blocks are rewritten as they are compacted",
+		},
+		{
+			DestP:   &l.StorageConfig.Engine.Compression.Float,
+			Flag:    "float-compression",
+			Default: tsm1.DefaultFloatCompression,
+			Desc:    "codec of float blocks in TSM files, gorilla or zstd; zstd suits noisy values better",
+		},
+		{
+			DestP:   &l.StorageConfig.Engine.Compression.Level,
+			Flag:    "compression-level",
+			Default: tsm1.DefaultCompressionLevel,
+			Desc:    "zstd compression level of TSM blocks, from 1 (fastest) to 22 (smallest)",
+		},
+		{
+			DestP: &l.StorageConfig.ReadOnly,
+			Flag:  "storage-read-only",
+			Desc:  "start the storage engine in read-only mode, rejecting writes and deletes until changed through /api/v2/storage/mode",
+		},
+		{
+			DestP: &l.lastValueCacheSize,
+			Flag:  "last-value-cache-size",
+			Desc:  "maximum bytes of the latest values of series kept in memory to serve last() queries (0 disables the cache)",
+		},
+		{
+			DestP:   &l.scrubInterval,
+			Flag:    "scrub-interval",
+			Default: tsm1.DefaultScrubInterval,
+			Desc:    "interval between background verifications of all TSM files, which quarantine corrupt files (0 disables scrubbing)",
+		},
+		{
+			DestP:   &l.scrubThroughput,
+			Flag:    "scrub-throughput",
+			Default: tsm1.DefaultScrubThroughput,
+			Desc:    "maximum bytes per second read from TSM files by background verification",
+		},
+		{
+			DestP: &l.slowQueryDuration,
+			Flag:  "slow-query-duration",
+			Desc:  "write queries that run for at least this long to the _monitoring bucket of their organization (0 disables)",
+		},
+		{
+			DestP: &l.slowQueryMemoryBytes,
+			Flag:  "slow-query-memory-bytes",
+			Desc:  "write queries that allocate at least this many bytes to the _monitoring bucket of their organization (0 disables)",
+		},
+		{
+			DestP: &l.queryCacheMaxBytes,
+			Flag:  "query-cache-max-bytes",
+			Desc:  "maximum size of the cache of Flux query results (0 disables the cache)",
+		},
+		{
+			DestP:   &l.queryCacheResolution,
+			Flag:    "query-cache-resolution",
+			Default: querycache.DefaultResolution,
+			Desc:    "interval the time of cached queries is truncated to, which is also the longest time a result stays cached",
+		},
 	}
 
 	cli.BindOptions(cmd, opts)
@@ -279,6 +451,15 @@ type Launcher struct {
 	sessionLength        int // in minutes
 	sessionRenewDisabled bool
 
+	auditLogEnabled   bool
+	auditLogRetention time.Duration
+
+	replicationLog        bool
+	replicationMaxChanges int
+	replicateFrom         string
+	replicateToken        string
+	replicateSkipVerify   bool
+
 	logLevel          string
 	tracingType       string
 	reportingDisabled bool
@@ -287,11 +468,28 @@ type Launcher struct {
 	boltPath        string
 	enginePath      string
 	secretStore     string
+	secretKeyFile   string
+	secretKeys      string
+
+	boltClient       *bolt.Client
+	kvService        *kv.Service
+	replicationStore *replication.Store
+	engine           Engine
+	deleteJobs       *storage.DeleteJobService
+	StorageConfig    storage.Config
+
+	coldTierInterval   time.Duration
+	coldTierCacheSize  int
+	coldTierTimeout    time.Duration
+	lastValueCacheSize int
+	scrubInterval      time.Duration
+	scrubThroughput    int
+
+	slowQueryDuration    time.Duration
+	slowQueryMemoryBytes int
 
-	boltClient    *bolt.Client
-	kvService     *kv.Service
-	engine        Engine
-	StorageConfig storage.Config
+	queryCacheMaxBytes   int
+	queryCacheResolution time.Duration
 
 	queryController *control.Controller
 
@@ -300,6 +498,9 @@ type Launcher struct {
 	httpTLSCert string
 	httpTLSKey  string
 
+	httpTLSClientCA       string
+	httpTLSClientAuthFile string
+
 	natsServer *nats.Server
 	natsPort   int
 
@@ -384,6 +585,11 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 		m.log.Info("Failed closing query service", zap.Error(err))
 	}
 
+	m.log.Info("Stopping", zap.String("service", "delete-jobs"))
+	if err := m.deleteJobs.Close(); err != nil {
+		m.log.Error("Failed to close delete jobs", zap.Error(err))
+	}
+
 	m.log.Info("Stopping", zap.String("service", "storage-engine"))
 	if err := m.engine.Close(); err != nil {
 		m.log.Error("Failed to close engine", zap.Error(err))
@@ -482,12 +688,51 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		SessionLength: time.Duration(m.sessionLength) * time.Minute,
 	}
 
+	keyring, err := secret.LoadKeyring(m.secretKeyFile, m.secretKeys)
+	if err != nil {
+		m.log.Error("Failed loading secret encryption keys", zap.Error(err))
+		return err
+	}
+	if keyring != nil {
+		if m.secretStore != "bolt" {
+			err := fmt.Errorf("secret encryption keys require the bolt secret store, not %q", m.secretStore)
+			m.log.Error("Failed loading secret encryption keys", zap.Error(err))
+			return err
+		}
+		serviceConfig.SecretCipher = keyring
+		m.log.Info("Encrypting secrets", zap.String("active_key", keyring.ActiveKeyID()))
+	}
+
+	if m.replicateFrom != "" {
+		m.replicationLog = true
+	}
+	if m.replicationLog && m.storeType != BoltStore {
+		err := fmt.Errorf("metadata replication requires the %s store", BoltStore)
+		m.log.Error("Failed configuring replication", zap.Error(err))
+		return err
+	}
+
 	flushers := flushers{}
 	switch m.storeType {
 	case BoltStore:
 		store := bolt.NewKVStore(m.log.With(zap.String("service", "kvstore-bolt")), m.boltPath)
 		store.WithDB(m.boltClient.DB())
-		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
+
+		var kvStore kv.Store = store
+		if m.replicationLog {
+			opts := []replication.Option{replication.WithMaxChanges(uint64(m.replicationMaxChanges))}
+			if m.replicateFrom != "" {
+				opts = append(opts, replication.WithFollower())
+			}
+			m.replicationStore = replication.NewStore(m.log.With(zap.String("service", "replication")), store, opts...)
+			if err := m.replicationStore.Initialize(ctx); err != nil {
+				m.log.Error("Failed to initialize replication", zap.Error(err))
+				return err
+			}
+			kvStore = m.replicationStore
+		}
+
+		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), kvStore, serviceConfig)
 		if m.testing {
 			flushers = append(flushers, store)
 		}
@@ -563,13 +808,30 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	if m.coldTierInterval > 0 {
+		m.StorageConfig.ColdTier.Interval = toml.Duration(m.coldTierInterval)
+	}
+	if m.coldTierCacheSize > 0 {
+		m.StorageConfig.ColdTier.CacheSize = toml.Size(m.coldTierCacheSize)
+	}
+	if m.coldTierTimeout > 0 {
+		m.StorageConfig.ColdTier.Timeout = toml.Duration(m.coldTierTimeout)
+	}
+	if m.lastValueCacheSize > 0 {
+		m.StorageConfig.LastValueCacheSize = toml.Size(m.lastValueCacheSize)
+	}
+	m.StorageConfig.Engine.Scrub.Interval = toml.Duration(m.scrubInterval)
+	if m.scrubThroughput > 0 {
+		m.StorageConfig.Engine.Scrub.Throughput = toml.Size(m.scrubThroughput)
+	}
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc), storage.WithColdTier(bucketSvc))
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc), storage.WithColdTier(bucketSvc))
 	}
 	m.engine.WithLogger(m.log)
 	if err := m.engine.Open(ctx); err != nil {
@@ -579,23 +841,48 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	// Organizations without configured limits are unlimited.
+	limitEnforcer := limits.NewEnforcer(m.kvService, limits.WithSeriesCardinality(m.engine))
+
 	var (
-		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
-		backupService platform.BackupService = m.engine
+		deleteService   platform.DeleteService  = m.engine
+		progressDeleter storage.ProgressDeleter = m.engine
+		pointsWriter    storage.PointsWriter    = m.engine
+		backupService   platform.BackupService  = m.engine
 	)
 
+	// Writes and deletes go through the query cache so that they remove
+	// the results they change, including delete jobs and writes by Flux
+	// queries.
+	var queryCache *querycache.Cache
+	if m.queryCacheMaxBytes > 0 {
+		queryCache = querycache.New(querycache.Config{
+			MaxBytes:   int64(m.queryCacheMaxBytes),
+			Resolution: m.queryCacheResolution,
+		})
+		m.reg.MustRegister(queryCache.PrometheusCollectors()...)
+		pointsWriter = &querycache.PointsWriter{PointsWriter: pointsWriter, Cache: queryCache}
+		deleteService = &querycache.DeleteService{DeleteService: deleteService, Cache: queryCache}
+		progressDeleter = &querycache.ProgressDeleter{ProgressDeleter: progressDeleter, Cache: queryCache}
+	}
+
+	m.deleteJobs = storage.NewDeleteJobService(progressDeleter, m.log.With(zap.String("service", "delete-jobs")))
+
 	// TODO(cwolff): Figure out a good default per-query memory limit:
 	//   https://github.com/influxdata/influxdb/issues/13642
 	const (
 		concurrencyQuota         = 10
 		memoryBytesQuotaPerQuery = math.MaxInt64
 		QueueSize                = 10
+
+		// interactiveConcurrencyQuota leaves execution slots to task queries
+		// while dashboards keep the controller busy.
+		interactiveConcurrencyQuota = 8
 	)
 
 	deps, err := influxdb.NewDependencies(
 		reads.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
+		pointsWriter,
 		authorizer.NewBucketService(bucketSvc),
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
@@ -610,8 +897,12 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		ConcurrencyQuota:         concurrencyQuota,
 		MemoryBytesQuotaPerQuery: int64(memoryBytesQuotaPerQuery),
 		QueueSize:                QueueSize,
-		Logger:                   m.log.With(zap.String("service", "storage-reads")),
-		ExecutorDependencies:     []flux.Dependency{deps},
+		Priorities: map[query.Priority]control.PriorityConfig{
+			query.PriorityInteractive: {ConcurrencyQuota: interactiveConcurrencyQuota},
+		},
+		Logger:               m.log.With(zap.String("service", "storage-reads")),
+		ExecutorDependencies: []flux.Dependency{deps},
+		OrgLimiter:           limitEnforcer,
 	})
 	if err != nil {
 		m.log.Error("Failed to create query controller", zap.Error(err))
@@ -620,7 +911,14 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
-	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
+	var storageQueryService query.ProxyQueryService = readservice.NewProxyQueryService(m.queryController)
+	if c := (slowlog.Config{Duration: m.slowQueryDuration, MemoryBytes: int64(m.slowQueryMemoryBytes)}); c.Enabled() {
+		slowLog := m.log.With(zap.String("service", "slow-query-log"))
+		storageQueryService = query.NewLoggingProxyQueryService(slowLog, slowlog.NewLogger(slowLog, c, pointsWriter, bucketSvc), storageQueryService)
+	}
+	if queryCache != nil {
+		storageQueryService = querycache.NewProxyQueryService(m.log.With(zap.String("service", "query-cache")), queryCache, bucketSvc, storageQueryService)
+	}
 	var taskSvc platform.TaskService
 	{
 		// create the task stack
@@ -761,24 +1059,83 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		log.Info("Stopping")
 	}(m.log)
 
+	if m.auditLogEnabled && m.auditLogRetention > 0 {
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			log = log.With(zap.String("service", "audit-retention"))
+			m.enforceAuditRetention(ctx, log)
+			log.Info("Stopping")
+		}(m.log)
+	}
+
+	if m.replicateFrom != "" {
+		client, err := http.NewHTTPClient(m.replicateFrom, m.replicateToken, m.replicateSkipVerify)
+		if err != nil {
+			m.log.Error("Failed to create replication client", zap.Error(err))
+			return err
+		}
+		follower := replication.NewFollower(
+			m.log.With(zap.String("service", "replication-follower")),
+			m.replicationStore,
+			&http.ReplicationService{Client: client},
+		)
+
+		m.wg.Add(1)
+		go func(log *zap.Logger) {
+			defer m.wg.Done()
+			log = log.With(zap.String("service", "replication-follower"), zap.String("primary", m.replicateFrom))
+			log.Info("Following primary")
+			if err := follower.Run(ctx); err != nil && err != context.Canceled {
+				log.Error("Failed to replicate metadata", zap.Error(err))
+			}
+			log.Info("Stopping")
+		}(m.log)
+	}
+
 	m.httpServer = &nethttp.Server{
 		Addr: m.httpBindAddress,
 	}
 
+	if m.httpTLSClientCA != "" && (m.httpTLSCert == "" || m.httpTLSKey == "") {
+		err := errors.New("tls-client-ca requires tls-cert and tls-key")
+		m.log.Error("Failed configuring client certificate authentication", zap.Error(err))
+		return err
+	}
+
+	var clientCerts *http.ClientCertificateMap
+	if m.httpTLSClientAuthFile != "" {
+		if m.httpTLSClientCA == "" {
+			err := errors.New("tls-client-auth-file requires tls-client-ca")
+			m.log.Error("Failed configuring client certificate authentication", zap.Error(err))
+			return err
+		}
+		clientCerts, err = http.LoadClientCertificateMapFile(m.httpTLSClientAuthFile)
+		if err != nil {
+			m.log.Error("Failed loading client certificate authorizations", zap.Error(err))
+			return err
+		}
+	}
+
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           m.assetsPath,
 		HTTPErrorHandler:     kithttp.ErrorHandler(0),
 		Logger:               m.log,
 		SessionRenewDisabled: m.sessionRenewDisabled,
+		ClientCertificates:   clientCerts,
+		AuditLogEnabled:      m.auditLogEnabled,
+		AuditService:         m.kvService,
+		ReplicationService:   replicationService(m.replicationStore),
 		NewBucketService:     source.NewBucketService,
 		NewQueryService:      source.NewQueryService,
 		PointsWriter:         pointsWriter,
 		DeleteService:        deleteService,
+		DeleteJobService:     m.deleteJobs,
 		BackupService:        backupService,
 		KVBackupService:      m.kvService,
 		AuthorizationService: authSvc,
 		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		BucketService:                   storage.NewBucketService(limits.NewBucketService(bucketSvc, limitEnforcer), m.engine),
 		SessionService:                  sessionSvc,
 		UserService:                     userSvc,
 		OrganizationService:             orgSvc,
@@ -786,7 +1143,10 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		LabelService:                    labelSvc,
 		DashboardService:                dashboardSvc,
 		DashboardOperationLogService:    dashboardLogSvc,
+		DBRPMappingService:              m.kvService,
 		BucketOperationLogService:       bucketLogSvc,
+		BucketStatsService:              m.engine,
+		StorageModeService:              m.engine,
 		UserOperationLogService:         userLogSvc,
 		OrganizationOperationLogService: orgLogSvc,
 		SourceService:                   sourceSvc,
@@ -795,7 +1155,8 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		OnboardingService:               onboardingSvc,
 		InfluxQLService:                 storageQueryService,
 		FluxService:                     storageQueryService,
-		TaskService:                     taskSvc,
+		RunningQueryService:             m.queryController,
+		TaskService:                     limits.NewTaskService(taskSvc, limitEnforcer),
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
 		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
@@ -806,6 +1167,9 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		LookupService:                   lookupSvc,
 		DocumentService:                 m.kvService,
 		OrgLookupService:                m.kvService,
+		OrgLimitService:                 m.kvService,
+		OrgWriteLimiter:                 limitEnforcer,
+		UsageService:                    limits.NewUsageService(limitEnforcer, bucketSvc, taskSvc),
 		WriteEventRecorder:              infprom.NewEventRecorder("write"),
 		QueryEventRecorder:              infprom.NewEventRecorder("query"),
 	}
@@ -851,6 +1215,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			m.reg,
 			http.WithLog(httpLogger),
 			http.WithAPIHandler(platformHandler),
+			http.WithHealthHandler(http.NewHealthHandler(m.engine)),
 		)
 
 		if logconf.Level == zap.DebugLevel {
@@ -884,6 +1249,26 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		transport = "https"
 
 		m.httpServer.TLSConfig = &tls.Config{}
+
+		if m.httpTLSClientCA != "" {
+			pem, err := ioutil.ReadFile(m.httpTLSClientCA)
+			if err != nil {
+				m.log.Error("failed to read client CA certificates", zap.Error(err))
+				m.log.Info("Stopping")
+				return err
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				err := fmt.Errorf("no certificates found in %s", m.httpTLSClientCA)
+				m.log.Error("failed to read client CA certificates", zap.Error(err))
+				m.log.Info("Stopping")
+				return err
+			}
+
+			// Clients without a certificate may still use tokens and sessions.
+			m.httpServer.TLSConfig.ClientCAs = pool
+			m.httpServer.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		}
 	}
 
 	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
@@ -910,6 +1295,37 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	return nil
 }
 
+// replicationService returns s as a ReplicationService, or nil if
+// replication is disabled.
+func replicationService(s *replication.Store) platform.ReplicationService {
+	if s == nil {
+		return nil
+	}
+	return s
+}
+
+// enforceAuditRetention periodically removes audit events older than the
+// configured retention until ctx is done.
+func (m *Launcher) enforceAuditRetention(ctx context.Context, log *zap.Logger) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		n, err := m.kvService.DeleteAuditEventsBefore(ctx, time.Now().Add(-m.auditLogRetention))
+		if err != nil {
+			log.Error("Failed to remove expired audit events", zap.Error(err))
+		} else if n > 0 {
+			log.Info("Removed expired audit events", zap.Int("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // isAddressPortAvailable checks whether the address:port is available to listen,
 // by using net.Listen to verify that the port opens successfully, then closes the listener.
 func isAddressPortAvailable(address string, port int) (bool, error) {
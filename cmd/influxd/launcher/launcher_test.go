@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	nethttp "net/http"
 	"testing"
+	"time"
 
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
@@ -139,3 +140,47 @@ func TestLauncher_SetupWithUsers(t *testing.T) {
 		t.Fatalf("unexpected 2 users: %#+v", exp)
 	}
 }
+
+func TestLauncher_Replication(t *testing.T) {
+	primary := launcher.RunTestLauncherOrFail(t, ctx, "--replication-log")
+	primary.SetupOrFail(t)
+	defer primary.ShutdownOrFail(t, ctx)
+
+	follower := launcher.RunTestLauncherOrFail(t, ctx,
+		"--replicate-from", primary.URL(),
+		"--replicate-token", primary.Auth.Token,
+	)
+	defer follower.ShutdownOrFail(t, ctx)
+
+	// Tokens are replicated, so the primary's token works on the follower.
+	follower.Auth = primary.Auth
+	buckets := follower.BucketService(t)
+
+	b := &platform.Bucket{OrgID: primary.Org.ID, Name: "replicated"}
+	if err := primary.BucketService(t).CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, err := buckets.FindBucketByID(ctx, b.ID); err == nil {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("bucket was not replicated: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	err := buckets.CreateBucket(ctx, &platform.Bucket{OrgID: primary.Org.ID, Name: "local"})
+	if platform.ErrorCode(err) != platform.EForbidden {
+		t.Fatalf("expected writes to a follower to be forbidden, got: %v", err)
+	}
+
+	replication := &http.ReplicationService{Client: follower.HTTPClient(t)}
+	if err := replication.PromoteReplica(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := buckets.CreateBucket(ctx, &platform.Bucket{OrgID: primary.Org.ID, Name: "local"}); err != nil {
+		t.Fatalf("unexpected error writing to a promoted follower: %v", err)
+	}
+}
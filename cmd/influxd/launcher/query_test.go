@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/cmd/influxd/launcher"
 	phttp "github.com/influxdata/influxdb/http"
+	"github.com/influxdata/influxdb/kit/prom/promtest"
 	"github.com/influxdata/influxdb/query"
 )
 
@@ -405,3 +406,50 @@ from(bucket: "%s")
 		t.Fatal(err)
 	}
 }
+
+func TestPipeline_Query_SlowQueryLog(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--slow-query-duration", "1ns")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	l.WritePointsOrFail(t, `m,k=v f=1i 946684800000000000`)
+	l.FluxQueryOrFail(t, l.Org, l.Auth.Token, fmt.Sprintf(`from(bucket: "%s") |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z)`, l.Bucket.Name))
+
+	got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, `from(bucket: "_monitoring")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "slow_queries" and r._field == "query")
+	|> keep(columns: ["_value", "status", "authorizationID"])`)
+	if !strings.Contains(got, "range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z)") {
+		t.Fatalf("slow query was not logged, got:\n%s", got)
+	}
+	if !strings.Contains(got, l.Auth.ID.String()) || !strings.Contains(got, "success") {
+		t.Fatalf("slow query is missing its tags, got:\n%s", got)
+	}
+}
+
+func TestPipeline_Query_Cache(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--query-cache-max-bytes", "1048576")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	q := fmt.Sprintf(`from(bucket: "%s") |> range(start: 2000-01-01T00:00:00Z, stop: 2000-01-02T00:00:00Z) |> sum()`, l.Bucket.Name)
+	l.WritePointsOrFail(t, `m,k=v f=1i 946684800000000000`)
+	first := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q)
+	if second := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q); second != first {
+		t.Fatalf("unexpected result of the same query -first/+second:\n%s\n%s", first, second)
+	}
+	mfs, err := l.Registry().Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := promtest.MustFindMetric(t, mfs, "query_cache_requests_total", map[string]string{"result": "hit"})
+	if got := hits.GetCounter().GetValue(); got != 1 {
+		t.Fatalf("expected 1 cache hit, got %v", got)
+	}
+
+	// A write in the range of the query removes its cached result.
+	l.WritePointsOrFail(t, `m,k=v f=2i 946684801000000000`)
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, q); !strings.Contains(got, ",v,3") {
+		t.Fatalf("expected the sum of both points, got:\n%s", got)
+	}
+}
@@ -0,0 +1,131 @@
+// Package secrets implements the influxd secrets commands, which manage the
+// encryption of secrets stored in bolt.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/internal/fs"
+	"github.com/influxdata/influxdb/kit/cli"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/secret"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+// NewCommand returns the influxd secrets command.
+func NewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage the encryption of secrets stored in bolt",
+	}
+	cmd.AddCommand(newGenerateKeyCommand(), newReencryptCommand())
+	return cmd
+}
+
+func newGenerateKeyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate-key <id>",
+		Short: "Generate a secret encryption key",
+		Long: `
+Generates a random key to add to the secret encryption keys. Place the new
+key first to make it the active key, keep the previous keys after it until
+"influxd secrets reencrypt" has been run.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			k, err := secret.GenerateKey(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), k)
+			return nil
+		},
+	}
+}
+
+var reencryptFlags struct {
+	boltPath string
+	keyFile  string
+	keys     string
+}
+
+func newReencryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reencrypt",
+		Short: "Re-encrypt all secrets with the active encryption key",
+		Long: `
+Re-encrypts every secret stored in bolt with the active (first) secret
+encryption key. Secrets stored before encryption was enabled are encrypted.
+Once complete, keys other than the active key may be removed.
+
+NOTES:
+
+* The influxd server should not be running when using this command.
+`,
+		Args: cobra.NoArgs,
+		RunE: reencryptE,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(fmt.Errorf("failed to determine influx directory: %v", err))
+	}
+
+	opts := []cli.Opt{
+		{
+			DestP:   &reencryptFlags.boltPath,
+			Flag:    "bolt-path",
+			Default: filepath.Join(dir, bolt.DefaultFilename),
+			Desc:    "path to boltdb database",
+		},
+		{
+			DestP: &reencryptFlags.keyFile,
+			Flag:  "secret-encryption-key-file",
+			Desc:  "path to a file of id:base64-key entries; the first key encrypts, all keys decrypt",
+		},
+		{
+			DestP: &reencryptFlags.keys,
+			Flag:  "secret-encryption-key",
+			Desc:  "comma separated id:base64-key entries, as an alternative to secret-encryption-key-file",
+		},
+	}
+	cli.BindOptions(cmd, opts)
+
+	return cmd
+}
+
+func reencryptE(cmd *cobra.Command, args []string) error {
+	keyring, err := secret.LoadKeyring(reencryptFlags.keyFile, reencryptFlags.keys)
+	if err != nil {
+		return fmt.Errorf("failed to load secret encryption keys: %v", err)
+	}
+	if keyring == nil {
+		return fmt.Errorf("no secret encryption key given")
+	}
+
+	ctx := context.Background()
+	log := zap.NewNop()
+
+	store := bolt.NewKVStore(log, reencryptFlags.boltPath)
+	if err := store.Open(ctx); err != nil {
+		return fmt.Errorf("failed to open bolt database: %v", err)
+	}
+	defer store.Close()
+
+	svc := kv.NewService(log, store, kv.ServiceConfig{SecretCipher: keyring})
+	if err := svc.Initialize(ctx); err != nil {
+		return err
+	}
+
+	n, err := svc.ReencryptSecrets(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to re-encrypt secrets: %v", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted %d secrets with key %q\n", n, keyring.ActiveKeyID())
+	return nil
+}
@@ -868,6 +868,8 @@ type DashboardQuery struct {
 	EditMode      string        `json:"editMode"` // Either "builder" or "advanced"
 	Name          string        `json:"name"`     // Term or phrase that refers to the query
 	BuilderConfig BuilderConfig `json:"builderConfig"`
+	// Params are the parameters that the query is run with.
+	Params map[string]interface{} `json:"params,omitempty"`
 }
 
 type BuilderConfig struct {
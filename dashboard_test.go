@@ -55,6 +55,62 @@ func TestView_MarshalJSON(t *testing.T) {
 		"timeFormat": ""
   }
 }
+`,
+			},
+		},
+		{
+			name: "query params",
+			args: args{
+				view: platform.View{
+					ViewContents: platform.ViewContents{
+						ID:   platformtesting.MustIDBase16("f01dab1ef005ba11"),
+						Name: "hello",
+					},
+					Properties: platform.XYViewProperties{
+						Type: "xy",
+						Queries: []platform.DashboardQuery{{
+							Text:     "from(bucket: params.bucket)",
+							EditMode: "advanced",
+							Params:   map[string]interface{}{"bucket": "b"},
+						}},
+					},
+				},
+			},
+			wants: wants{
+				json: `
+{
+  "id": "f01dab1ef005ba11",
+  "name": "hello",
+  "properties": {
+    "shape": "chronograf-v2",
+    "queries": [
+      {
+        "text": "from(bucket: params.bucket)",
+        "editMode": "advanced",
+        "name": "",
+        "builderConfig": {
+          "buckets": [],
+          "tags": [],
+          "functions": [],
+          "aggregateWindow": {"period": ""}
+        },
+        "params": {"bucket": "b"}
+      }
+    ],
+    "axes": null,
+    "type": "xy",
+    "colors": null,
+    "legend": {},
+    "geom": "",
+    "note": "",
+    "showNoteWhenEmpty": false,
+    "xColumn": "",
+    "yColumn": "",
+    "shadeBelow": false,
+    "position": "",
+    "timeFormat": ""
+  }
+}
 `,
 			},
 		},
@@ -1,6 +1,10 @@
 package influxdb
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
 
 // Predicate is something that can match on a series key.
 type Predicate interface {
@@ -9,7 +13,131 @@ type Predicate interface {
 	Marshal() ([]byte, error)
 }
 
+// DeleteStats is the amount of data a delete removes.
+type DeleteStats struct {
+	Series int64 `json:"series"`
+	Points int64 `json:"points"`
+}
+
 // DeleteService will delete a bucket from the range and predict.
 type DeleteService interface {
 	DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID ID, min, max int64, pred Predicate) error
+
+	// PreviewBucketRangePredicate returns the amount of data that
+	// DeleteBucketRangePredicate would delete, without deleting it.
+	PreviewBucketRangePredicate(ctx context.Context, orgID, bucketID ID, min, max int64, pred Predicate) (*DeleteStats, error)
+}
+
+// DeleteJobStatus is the state of a DeleteJob.
+type DeleteJobStatus string
+
+// Delete job statuses.
+const (
+	DeleteJobPending   DeleteJobStatus = "pending"
+	DeleteJobRunning   DeleteJobStatus = "running"
+	DeleteJobSucceeded DeleteJobStatus = "succeeded"
+	DeleteJobFailed    DeleteJobStatus = "failed"
+	DeleteJobCanceled  DeleteJobStatus = "canceled"
+)
+
+// Done returns true if a job with status s will not change again.
+func (s DeleteJobStatus) Done() bool {
+	switch s {
+	case DeleteJobSucceeded, DeleteJobFailed, DeleteJobCanceled:
+		return true
+	}
+	return false
+}
+
+// DeleteProgress is how far a delete has got. Deletes tombstone the data in
+// each TSM file and then remove the series left without data from the index;
+// the files themselves are rewritten later by compactions.
+type DeleteProgress struct {
+	FilesTotal        int64 `json:"filesTotal"`
+	FilesScanned      int64 `json:"filesScanned"`
+	TombstonesWritten int64 `json:"tombstonesWritten"`
+	FilesRemoved      int64 `json:"filesRemoved"`
+	SeriesScanned     int64 `json:"seriesScanned"`
+	SeriesDeleted     int64 `json:"seriesDeleted"`
+
+	state int32 // deleteStarted or deleteCanceled once either happens.
+}
+
+const (
+	deleteStarted int32 = iota + 1
+	deleteCanceled
+)
+
+// Start records that the delete is about to modify data, after which it can
+// no longer be canceled. It returns false if the delete was canceled first.
+func (p *DeleteProgress) Start() bool {
+	atomic.CompareAndSwapInt32(&p.state, 0, deleteStarted)
+	return atomic.LoadInt32(&p.state) == deleteStarted
+}
+
+// Cancel records that the delete is canceled. It returns false if the delete
+// has already started modifying data.
+func (p *DeleteProgress) Cancel() bool {
+	atomic.CompareAndSwapInt32(&p.state, 0, deleteCanceled)
+	return atomic.LoadInt32(&p.state) == deleteCanceled
+}
+
+// Started returns true if the delete has started modifying data.
+func (p *DeleteProgress) Started() bool {
+	return atomic.LoadInt32(&p.state) == deleteStarted
+}
+
+// Snapshot returns a copy of p that is safe to read while p is being
+// updated atomically.
+func (p *DeleteProgress) Snapshot() DeleteProgress {
+	return DeleteProgress{
+		FilesTotal:        atomic.LoadInt64(&p.FilesTotal),
+		FilesScanned:      atomic.LoadInt64(&p.FilesScanned),
+		TombstonesWritten: atomic.LoadInt64(&p.TombstonesWritten),
+		FilesRemoved:      atomic.LoadInt64(&p.FilesRemoved),
+		SeriesScanned:     atomic.LoadInt64(&p.SeriesScanned),
+		SeriesDeleted:     atomic.LoadInt64(&p.SeriesDeleted),
+	}
+}
+
+// DeleteJob is a delete running in the background.
+type DeleteJob struct {
+	ID         ID              `json:"id"`
+	OrgID      ID              `json:"orgID"`
+	BucketID   ID              `json:"bucketID"`
+	Start      time.Time       `json:"start"`
+	Stop       time.Time       `json:"stop"`
+	Predicate  string          `json:"predicate,omitempty"`
+	Status     DeleteJobStatus `json:"status"`
+	Progress   DeleteProgress  `json:"progress"`
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	StartedAt  *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// DeleteJobFilter selects delete jobs.
+type DeleteJobFilter struct {
+	OrgID    *ID
+	BucketID *ID
+}
+
+// DeleteJobService runs deletes in the background.
+type DeleteJobService interface {
+	// CreateDeleteJob queues a delete of the data in job.BucketID between
+	// job.Start and job.Stop matching pred, which may be nil. job.Predicate
+	// is the text pred was parsed from. The ID, status and creation time
+	// of job are set.
+	CreateDeleteJob(ctx context.Context, job *DeleteJob, pred Predicate) error
+
+	// FindDeleteJobByID returns a single delete job by ID.
+	FindDeleteJobByID(ctx context.Context, id ID) (*DeleteJob, error)
+
+	// FindDeleteJobs returns the delete jobs matching filter, oldest first.
+	FindDeleteJobs(ctx context.Context, filter DeleteJobFilter) ([]*DeleteJob, error)
+
+	// CancelDeleteJob stops a pending delete job, or a running one that has
+	// not started deleting data yet. A job that has started deleting data
+	// cannot be canceled and runs to completion.
+	CancelDeleteJob(ctx context.Context, id ID) error
 }
@@ -28,6 +28,11 @@ type APIBackend struct {
 	Logger     *zap.Logger
 	influxdb.HTTPErrorHandler
 	SessionRenewDisabled bool
+	// ClientCertificates authenticates requests made with a verified TLS
+	// client certificate.
+	ClientCertificates *ClientCertificateMap
+	// AuditLogEnabled records every mutating request in the AuditService.
+	AuditLogEnabled bool
 	// MaxBatchSizeBytes is the maximum number of bytes which can be written
 	// in a single points batch
 	MaxBatchSizeBytes int64
@@ -51,7 +56,10 @@ type APIBackend struct {
 	QueryEventRecorder metric.EventRecorder
 
 	PointsWriter                    storage.PointsWriter
+	AuditService                    influxdb.AuditService
+	ReplicationService              influxdb.ReplicationService
 	DeleteService                   influxdb.DeleteService
+	DeleteJobService                influxdb.DeleteJobService
 	BackupService                   influxdb.BackupService
 	KVBackupService                 influxdb.KVBackupService
 	AuthorizationService            influxdb.AuthorizationService
@@ -63,7 +71,10 @@ type APIBackend struct {
 	LabelService                    influxdb.LabelService
 	DashboardService                influxdb.DashboardService
 	DashboardOperationLogService    influxdb.DashboardOperationLogService
+	DBRPMappingService              influxdb.DBRPMappingService
 	BucketOperationLogService       influxdb.BucketOperationLogService
+	BucketStatsService              influxdb.BucketStatsService
+	StorageModeService              influxdb.StorageModeService
 	UserOperationLogService         influxdb.UserOperationLogService
 	OrganizationOperationLogService influxdb.OrganizationOperationLogService
 	SourceService                   influxdb.SourceService
@@ -72,6 +83,7 @@ type APIBackend struct {
 	OnboardingService               influxdb.OnboardingService
 	InfluxQLService                 query.ProxyQueryService
 	FluxService                     query.ProxyQueryService
+	RunningQueryService             influxdb.RunningQueryService
 	TaskService                     influxdb.TaskService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
@@ -83,6 +95,9 @@ type APIBackend struct {
 	DocumentService                 influxdb.DocumentService
 	NotificationRuleStore           influxdb.NotificationRuleStore
 	NotificationEndpointService     influxdb.NotificationEndpointService
+	OrgLimitService                 influxdb.OrgLimitService
+	OrgWriteLimiter                 WriteLimiter
+	UsageService                    influxdb.UsageService
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -122,6 +137,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	h.Mount("/api/v2", serveLinksHandler(b.HTTPErrorHandler))
 
+	auditBackend := NewAuditBackend(b.Logger.With(zap.String("handler", "audit")), b)
+	auditBackend.AuditService = authorizer.NewAuditService(b.AuditService)
+	h.Mount(prefixAudit, NewAuditHandler(b.Logger, auditBackend))
+
 	authorizationBackend := NewAuthorizationBackend(b.Logger.With(zap.String("handler", "authorization")), b)
 	authorizationBackend.AuthorizationService = authorizer.NewAuthorizationService(b.AuthorizationService)
 	h.Mount(prefixAuthorization, NewAuthorizationHandler(b.Logger, authorizationBackend))
@@ -141,7 +160,17 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	dashboardBackend.DashboardService = authorizer.NewDashboardService(b.DashboardService)
 	h.Mount(prefixDashboards, NewDashboardHandler(b.Logger, dashboardBackend))
 
+	if b.DBRPMappingService != nil {
+		dbrpMappingBackend := NewDBRPMappingBackend(b.Logger.With(zap.String("handler", "dbrp_mapping")), b)
+		dbrpMappingBackend.DBRPMappingService = authorizer.NewDBRPMappingService(b.DBRPMappingService)
+		dbrpMappingBackend.BucketService = authorizer.NewBucketService(b.BucketService)
+		h.Mount(prefixDBRPMappings, NewDBRPMappingHandler(b.Logger, dbrpMappingBackend))
+	}
+
 	deleteBackend := NewDeleteBackend(b.Logger.With(zap.String("handler", "delete")), b)
+	if b.DeleteJobService != nil {
+		deleteBackend.DeleteJobService = authorizer.NewDeleteJobService(b.DeleteJobService)
+	}
 	h.Mount(prefixDelete, NewDeleteHandler(b.Logger, deleteBackend))
 
 	documentBackend := NewDocumentBackend(b.Logger.With(zap.String("handler", "document")), b)
@@ -150,6 +179,12 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	fluxBackend := NewFluxBackend(b.Logger.With(zap.String("handler", "query")), b)
 	h.Mount(prefixQuery, NewFluxHandler(b.Logger, fluxBackend))
 
+	if b.RunningQueryService != nil {
+		runningQueryBackend := NewRunningQueryBackend(b.Logger.With(zap.String("handler", "running_query")), b)
+		runningQueryBackend.RunningQueryService = authorizer.NewRunningQueryService(b.RunningQueryService)
+		h.Mount(prefixRunningQueries, NewRunningQueryHandler(b.Logger, runningQueryBackend))
+	}
+
 	h.Mount(prefixLabels, NewLabelHandler(b.Logger, authorizer.NewLabelService(b.LabelService), b.HTTPErrorHandler))
 
 	notificationEndpointBackend := NewNotificationEndpointBackend(b.Logger.With(zap.String("handler", "notificationEndpoint")), b)
@@ -164,8 +199,24 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 
 	orgBackend := NewOrgBackend(b.Logger.With(zap.String("handler", "org")), b)
 	orgBackend.OrganizationService = authorizer.NewOrgService(b.OrganizationService)
+	orgBackend.OrgLimitService = authorizer.NewOrgLimitService(b.OrgLimitService)
 	h.Mount(prefixOrganizations, NewOrgHandler(b.Logger, orgBackend))
 
+	prometheusBackend := NewPrometheusBackend(b.Logger.With(zap.String("handler", "prometheus")), b)
+	h.Mount(prefixPrometheus, NewPrometheusHandler(b.Logger, prometheusBackend))
+
+	if b.ReplicationService != nil {
+		replicationBackend := NewReplicationBackend(b.Logger.With(zap.String("handler", "replication")), b)
+		replicationBackend.ReplicationService = authorizer.NewReplicationService(b.ReplicationService)
+		h.Mount(prefixReplication, NewReplicationHandler(b.Logger, replicationBackend))
+	}
+
+	if b.StorageModeService != nil {
+		storageModeBackend := NewStorageModeBackend(b.Logger.With(zap.String("handler", "storage_mode")), b)
+		storageModeBackend.StorageModeService = authorizer.NewStorageModeService(b.StorageModeService)
+		h.Mount(prefixStorageMode, NewStorageModeHandler(b.Logger, storageModeBackend))
+	}
+
 	scraperBackend := NewScraperBackend(b.Logger.With(zap.String("handler", "scraper")), b)
 	scraperBackend.ScraperStorageService = authorizer.NewScraperTargetStoreService(b.ScraperTargetStoreService,
 		b.UserResourceMappingService,
@@ -197,6 +248,10 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	h.Mount(prefixTelegrafPlugins, NewTelegrafHandler(b.Logger, telegrafBackend))
 	h.Mount(prefixTelegraf, NewTelegrafHandler(b.Logger, telegrafBackend))
 
+	usageHandler := NewUsageHandler(b.Logger.With(zap.String("handler", "usage")), b.HTTPErrorHandler)
+	usageHandler.UsageService = authorizer.NewUsageService(b.UsageService)
+	h.Mount(prefixUsage, usageHandler)
+
 	userBackend := NewUserBackend(b.Logger.With(zap.String("handler", "user")), b)
 	userBackend.UserService = authorizer.NewUserService(b.UserService)
 	userBackend.PasswordsService = authorizer.NewPasswordService(b.PasswordsService)
@@ -218,8 +273,23 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 		WithParserMaxBytes(b.WriteParserMaxBytes),
 		WithParserMaxLines(b.WriteParserMaxLines),
 		WithParserMaxValues(b.WriteParserMaxValues),
+		WithWriteLimiter(b.OrgWriteLimiter),
 	))
 
+	if b.DBRPMappingService != nil {
+		legacyBackend := NewLegacyBackend(b.Logger.With(zap.String("handler", "legacy")), b)
+		legacyHandler := NewLegacyHandler(b.Logger, legacyBackend,
+			WithMaxBatchSizeBytes(b.MaxBatchSizeBytes),
+			WithParserMaxBytes(b.WriteParserMaxBytes),
+			WithParserMaxLines(b.WriteParserMaxLines),
+			WithParserMaxValues(b.WriteParserMaxValues),
+			WithWriteLimiter(b.OrgWriteLimiter),
+		)
+		h.Mount(prefixLegacyQuery, legacyHandler)
+		h.Mount(prefixLegacyWrite, legacyHandler)
+		h.Mount(prefixLegacyPing, legacyHandler)
+	}
+
 	for _, o := range opts {
 		o(h)
 	}
@@ -229,10 +299,12 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 var apiLinks = map[string]interface{}{
 	// when adding new links, please take care to keep this list alphabetical
 	// as this makes it easier to verify values against the swagger document.
+	"audit":          "/api/v2/audit",
 	"authorizations": "/api/v2/authorizations",
 	"backup":         "/api/v2/backup",
 	"buckets":        "/api/v2/buckets",
 	"dashboards":     "/api/v2/dashboards",
+	"dbrps":          "/api/v2/dbrps",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
 	},
@@ -242,18 +314,21 @@ var apiLinks = map[string]interface{}{
 	"notificationRules":     "/api/v2/notificationRules",
 	"notificationEndpoints": "/api/v2/notificationEndpoints",
 	"orgs":                  "/api/v2/orgs",
+	"queries":               "/api/v2/queries",
 	"query": map[string]string{
 		"self":        "/api/v2/query",
 		"ast":         "/api/v2/query/ast",
 		"analyze":     "/api/v2/query/analyze",
 		"suggestions": "/api/v2/query/suggestions",
 	},
-	"setup":    "/api/v2/setup",
-	"signin":   "/api/v2/signin",
-	"signout":  "/api/v2/signout",
-	"sources":  "/api/v2/sources",
-	"scrapers": "/api/v2/scrapers",
-	"swagger":  "/api/v2/swagger.json",
+	"replication": "/api/v2/replication",
+	"setup":       "/api/v2/setup",
+	"signin":      "/api/v2/signin",
+	"signout":     "/api/v2/signout",
+	"sources":     "/api/v2/sources",
+	"scrapers":    "/api/v2/scrapers",
+	"storageMode": "/api/v2/storage/mode",
+	"swagger":     "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
@@ -263,6 +338,7 @@ var apiLinks = map[string]interface{}{
 	"checks":    "/api/v2/checks",
 	"telegrafs": "/api/v2/telegrafs",
 	"plugins":   "/api/v2/telegraf/plugins",
+	"usage":     "/api/v2/usage",
 	"users":     "/api/v2/users",
 	"write":     "/api/v2/write",
 	"delete":    "/api/v2/delete",
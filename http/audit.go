@@ -0,0 +1,530 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixAudit     = "/api/v2/audit"
+	auditExportPath = "/api/v2/audit/export"
+
+	// auditMaxBodyBytes is the largest resource representation that is kept
+	// as the before or after state of an audit event.
+	auditMaxBodyBytes = 64 * 1024
+)
+
+// auditResourceTypes maps the first path segment beneath /api/v2 onto the
+// resource type being changed.
+var auditResourceTypes = map[string]influxdb.ResourceType{
+	"authorizations":        influxdb.AuthorizationsResourceType,
+	"buckets":               influxdb.BucketsResourceType,
+	"checks":                influxdb.ChecksResourceType,
+	"dashboards":            influxdb.DashboardsResourceType,
+	"delete":                influxdb.BucketsResourceType,
+	"documents":             influxdb.DocumentsResourceType,
+	"labels":                influxdb.LabelsResourceType,
+	"me":                    influxdb.UsersResourceType,
+	"notificationEndpoints": influxdb.NotificationEndpointResourceType,
+	"notificationRules":     influxdb.NotificationRuleResourceType,
+	"orgs":                  influxdb.OrgsResourceType,
+	"scrapers":              influxdb.ScraperResourceType,
+	"sources":               influxdb.SourcesResourceType,
+	"tasks":                 influxdb.TasksResourceType,
+	"telegrafs":             influxdb.TelegrafsResourceType,
+	"users":                 influxdb.UsersResourceType,
+	"variables":             influxdb.VariablesResourceType,
+}
+
+// auditIgnoredPrefixes are endpoints that accept mutating methods but do
+// not change any resource, or are too high volume to be audited.
+var auditIgnoredPrefixes = []string{
+	prefixAudit,
+	prefixQuery,
+	prefixSignIn,
+	prefixSignOut,
+	prefixWrite,
+}
+
+// auditScrubbedFields are removed from resource representations before they
+// are stored in the audit log.
+var auditScrubbedFields = []string{"links", "token", "password"}
+
+// AuditMW records every mutating request made against the API with svc.
+// It must be installed beneath the authentication handler so that the actor
+// is available on the request context.
+func AuditMW(log *zap.Logger, svc influxdb.AuditService) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			action, ok := influxdb.AuditActionFromMethod(r.Method)
+			if !ok || !isAuditedPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			e := &influxdb.AuditEvent{
+				Action:   action,
+				Method:   r.Method,
+				Path:     r.URL.Path,
+				SourceIP: remoteIP(r),
+			}
+			resourcePath := decodeAuditResource(r, e)
+
+			if a, err := pcontext.GetAuthorizer(ctx); err == nil {
+				e.UserID = a.GetUserID()
+				e.AuthKind = a.Kind()
+				if a.Kind() == influxdb.AuthorizationKind {
+					e.AuthorizationID = a.Identifier()
+				}
+				if e.ResourceType == influxdb.UsersResourceType && !e.ResourceID.Valid() && strings.HasPrefix(r.URL.Path, prefixMe) {
+					e.ResourceID = e.UserID
+				}
+			}
+
+			// Endpoints whose request bodies are kept out of the debug log
+			// also have their state kept out of the audit log.
+			_, sensitive := mapURLPath(r.URL.Path)
+			captureState := !sensitive && resourcePath != ""
+
+			if captureState {
+				e.Before = auditFetchState(next, r, resourcePath)
+			}
+
+			rec := &auditResponseWriter{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			e.Status = rec.Code()
+			if !e.Succeeded() {
+				e.Error = auditErrorMessage(rec.body.Bytes())
+			}
+
+			if e.Succeeded() && !sensitive {
+				switch {
+				case resourcePath == "" && action == influxdb.AuditCreate:
+					// A resource was created on a collection endpoint; its
+					// representation is the response body.
+					e.After = scrubAuditState(rec.body.Bytes())
+					var created struct {
+						ID influxdb.ID `json:"id"`
+					}
+					if err := json.Unmarshal(e.After, &created); err == nil && created.ID.Valid() {
+						e.ResourceID = created.ID
+					}
+				case captureState && !(action == influxdb.AuditDelete && resourcePath == r.URL.Path):
+					e.After = auditFetchState(next, r, resourcePath)
+				}
+			}
+
+			if !e.OrgID.Valid() {
+				e.OrgID = auditOrgID(e.After, e.Before)
+			}
+			e.Diff = influxdb.DiffAuditState(e.Before, e.After)
+
+			// The audit event is recorded even if the caller has gone away.
+			if err := svc.CreateAuditEvent(context.Background(), e); err != nil {
+				log.Error("Failed to record audit event",
+					zap.String("method", e.Method),
+					zap.String("path", e.Path),
+					zap.Error(err),
+				)
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+func isAuditedPath(p string) bool {
+	if !strings.HasPrefix(p, "/api/v2/") {
+		return false
+	}
+	for _, prefix := range auditIgnoredPrefixes {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeAuditResource fills in the resource identifiers of e from the request
+// and returns the path of the individual resource being changed, if any.
+func decodeAuditResource(r *http.Request, e *influxdb.AuditEvent) string {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v2"), "/"), "/")
+	rt, ok := auditResourceTypes[parts[0]]
+	if !ok {
+		return ""
+	}
+	e.ResourceType = rt
+
+	qp := r.URL.Query()
+	if id, err := influxdb.IDFromString(qp.Get("orgID")); err == nil {
+		e.OrgID = *id
+	}
+
+	switch parts[0] {
+	case "delete":
+		if id, err := influxdb.IDFromString(qp.Get("bucketID")); err == nil {
+			e.ResourceID = *id
+		}
+		return ""
+	case "documents":
+		// documents are addressed as /documents/:namespace/:id
+		if len(parts) > 2 {
+			if id, err := influxdb.IDFromString(parts[2]); err == nil {
+				e.ResourceID = *id
+				return "/api/v2/" + strings.Join(parts[:3], "/")
+			}
+		}
+		return ""
+	case "me":
+		return prefixMe
+	}
+
+	if len(parts) < 2 {
+		return ""
+	}
+	id, err := influxdb.IDFromString(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	if parts[0] == "orgs" {
+		e.OrgID = *id
+		if len(parts) > 2 && parts[2] == "secrets" {
+			e.ResourceType = influxdb.SecretsResourceType
+			return ""
+		}
+	}
+	e.ResourceID = *id
+	return "/api/v2/" + parts[0] + "/" + parts[1]
+}
+
+// auditFetchState retrieves the current representation of the resource found
+// at resourcePath by issuing a GET against next on behalf of the caller.
+func auditFetchState(next http.Handler, r *http.Request, resourcePath string) json.RawMessage {
+	req := r.Clone(r.Context())
+	req.Method = http.MethodGet
+	req.Body = http.NoBody
+	req.ContentLength = 0
+	req.URL.Path = resourcePath
+	req.URL.RawPath = ""
+	req.URL.RawQuery = ""
+	req.RequestURI = resourcePath
+
+	rec := &auditResponseWriter{header: http.Header{}}
+	next.ServeHTTP(rec, req)
+	if rec.Code() != http.StatusOK {
+		return nil
+	}
+	return scrubAuditState(rec.body.Bytes())
+}
+
+func scrubAuditState(b []byte) json.RawMessage {
+	if len(b) == 0 || len(b) >= auditMaxBodyBytes {
+		return nil
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+	for _, f := range auditScrubbedFields {
+		delete(m, f)
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func auditErrorMessage(b []byte) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(b, &e); err != nil {
+		return ""
+	}
+	return e.Message
+}
+
+func auditOrgID(states ...json.RawMessage) influxdb.ID {
+	for _, s := range states {
+		if len(s) == 0 {
+			continue
+		}
+		var v struct {
+			OrgID          influxdb.ID `json:"orgID"`
+			OrganizationID influxdb.ID `json:"organizationID"`
+		}
+		// Unmarshalling fails on fields that are not IDs; either field is
+		// enough so errors are ignored.
+		_ = json.Unmarshal(s, &v)
+		if v.OrgID.Valid() {
+			return v.OrgID
+		}
+		if v.OrganizationID.Valid() {
+			return v.OrganizationID
+		}
+	}
+	return 0
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditResponseWriter captures the status code and up to auditMaxBodyBytes of
+// the response body. When ResponseWriter is nil the response is only captured.
+type auditResponseWriter struct {
+	http.ResponseWriter
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *auditResponseWriter) Header() http.Header {
+	if w.ResponseWriter == nil {
+		return w.header
+	}
+	return w.ResponseWriter.Header()
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if rem := auditMaxBodyBytes - w.body.Len(); rem > 0 {
+		if len(b) < rem {
+			rem = len(b)
+		}
+		w.body.Write(b[:rem])
+	}
+	if w.ResponseWriter == nil {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *auditResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	if w.ResponseWriter != nil {
+		w.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (w *auditResponseWriter) Code() int {
+	if w.statusCode == 0 {
+		return http.StatusOK
+	}
+	return w.statusCode
+}
+
+// AuditBackend is all services and associated parameters required to construct
+// the AuditHandler.
+type AuditBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	AuditService influxdb.AuditService
+}
+
+// NewAuditBackend returns a new instance of AuditBackend.
+func NewAuditBackend(log *zap.Logger, b *APIBackend) *AuditBackend {
+	return &AuditBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		AuditService: b.AuditService,
+	}
+}
+
+// AuditHandler serves the audit log.
+type AuditHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	AuditService influxdb.AuditService
+}
+
+// NewAuditHandler returns a new instance of AuditHandler.
+func NewAuditHandler(log *zap.Logger, b *AuditBackend) *AuditHandler {
+	h := &AuditHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		AuditService: b.AuditService,
+	}
+
+	h.HandlerFunc("GET", prefixAudit, h.handleGetAuditEvents)
+	h.HandlerFunc("GET", auditExportPath, h.handleExportAuditEvents)
+	return h
+}
+
+type auditEventsResponse struct {
+	Links  *influxdb.PagingLinks  `json:"links"`
+	Events []*influxdb.AuditEvent `json:"events"`
+}
+
+// handleGetAuditEvents is the HTTP handler for the GET /api/v2/audit route.
+func (h *AuditHandler) handleGetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "AuditHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	filter, err := decodeAuditEventFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	opts, err := decodeFindOptions(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	events, _, err := h.AuditService.FindAuditEvents(ctx, filter, *opts)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Audit events retrieved", zap.Int("count", len(events)))
+
+	res := auditEventsResponse{
+		Links:  newPagingLinks(prefixAudit, *opts, filter, len(events)),
+		Events: events,
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, res); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleExportAuditEvents is the HTTP handler for the GET /api/v2/audit/export route.
+// Matching events are streamed as JSON lines, oldest first.
+func (h *AuditHandler) handleExportAuditEvents(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "AuditHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	filter, err := decodeAuditEventFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	events, _, err := h.AuditService.FindAuditEvents(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			logEncodingError(h.log, r, err)
+			return
+		}
+	}
+}
+
+func decodeAuditEventFilter(r *http.Request) (influxdb.AuditEventFilter, error) {
+	var f influxdb.AuditEventFilter
+	qp := r.URL.Query()
+
+	decodeID := func(name string) (*influxdb.ID, error) {
+		v := qp.Get(name)
+		if v == "" {
+			return nil, nil
+		}
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  name + " is invalid",
+				Err:  err,
+			}
+		}
+		return id, nil
+	}
+
+	decodeTime := func(name string) (*time.Time, error) {
+		v := qp.Get(name)
+		if v == "" {
+			return nil, nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  name + " must be an RFC3339 timestamp",
+				Err:  err,
+			}
+		}
+		return &t, nil
+	}
+
+	var err error
+	if f.OrgID, err = decodeID("orgID"); err != nil {
+		return f, err
+	}
+	if f.UserID, err = decodeID("userID"); err != nil {
+		return f, err
+	}
+	if f.ResourceID, err = decodeID("resourceID"); err != nil {
+		return f, err
+	}
+	if f.Start, err = decodeTime("start"); err != nil {
+		return f, err
+	}
+	if f.Stop, err = decodeTime("stop"); err != nil {
+		return f, err
+	}
+
+	if v := qp.Get("resourceType"); v != "" {
+		rt := influxdb.ResourceType(v)
+		if err := rt.Valid(); err != nil {
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "resourceType is invalid",
+				Err:  err,
+			}
+		}
+		f.ResourceType = &rt
+	}
+
+	if v := qp.Get("action"); v != "" {
+		a := influxdb.AuditAction(v)
+		switch a {
+		case influxdb.AuditCreate, influxdb.AuditUpdate, influxdb.AuditDelete:
+		default:
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "action must be one of create, update or delete",
+			}
+		}
+		f.Action = &a
+	}
+
+	return f, nil
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAuditMW(t *testing.T) {
+	const (
+		bucketID = "020f755c3c082000"
+		orgID    = "020f755c3c082001"
+	)
+
+	// fake bucket API holding a single mutable bucket.
+	state := map[string]interface{}{
+		"id":              bucketID,
+		"orgID":           orgID,
+		"name":            "b1",
+		"retentionPeriod": 0,
+		"links":           map[string]string{"self": "/api/v2/buckets/" + bucketID},
+	}
+	exists := false
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == prefixBuckets:
+			exists = true
+			_ = encodeResponse(r.Context(), w, http.StatusCreated, state)
+		case r.URL.Path != prefixBuckets+"/"+bucketID:
+			w.WriteHeader(http.StatusNotFound)
+		case !exists:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code":"not found","message":"bucket not found"}`)
+		case r.Method == "GET":
+			_ = encodeResponse(r.Context(), w, http.StatusOK, state)
+		case r.Method == "PATCH":
+			var upd map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&upd)
+			for k, v := range upd {
+				state[k] = v
+			}
+			_ = encodeResponse(r.Context(), w, http.StatusOK, state)
+		case r.Method == "DELETE":
+			exists = false
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	var events []*influxdb.AuditEvent
+	svc := mock.NewAuditService()
+	svc.CreateAuditEventFn = func(ctx context.Context, e *influxdb.AuditEvent) error {
+		events = append(events, e)
+		return nil
+	}
+
+	auth := &influxdb.Authorization{
+		ID:     influxdb.ID(3),
+		UserID: influxdb.ID(4),
+		Token:  "secret",
+	}
+	h := AuditMW(zaptest.NewLogger(t), svc)(api)
+
+	do := func(method, path, body string) int {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.RemoteAddr = "10.0.0.1:5555"
+		r = r.WithContext(pcontext.SetAuthorizer(r.Context(), auth))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	do("GET", prefixBuckets+"/"+bucketID, "")
+	do("POST", prefixBuckets, `{"name":"b1"}`)
+	do("PATCH", prefixBuckets+"/"+bucketID, `{"name":"b2"}`)
+	do("DELETE", prefixBuckets+"/"+bucketID, "")
+	do("DELETE", prefixBuckets+"/"+bucketID, "")
+	do("POST", prefixWrite, "m f=1")
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 audit events, got %d", len(events))
+	}
+
+	for i, e := range events {
+		if e.ResourceType != influxdb.BucketsResourceType || e.ResourceID.String() != bucketID {
+			t.Errorf("unexpected resource for %s %s: %s %s", e.Method, e.Path, e.ResourceType, e.ResourceID)
+		}
+		// the org of a resource can only be found while it exists.
+		if i < 3 && e.OrgID.String() != orgID {
+			t.Errorf("unexpected org for %s %s: %s", e.Method, e.Path, e.OrgID)
+		}
+		if e.UserID != auth.UserID || e.AuthorizationID != auth.ID || e.SourceIP != "10.0.0.1" {
+			t.Errorf("unexpected actor for %s %s: user %s token %s ip %s", e.Method, e.Path, e.UserID, e.AuthorizationID, e.SourceIP)
+		}
+	}
+
+	create, update, del, failed := events[0], events[1], events[2], events[3]
+	if create.Action != influxdb.AuditCreate || create.Before != nil || create.After == nil {
+		t.Errorf("unexpected create event: %+v", create)
+	}
+	if strings.Contains(string(create.After), "links") {
+		t.Errorf("expected links to be scrubbed from audit state: %s", create.After)
+	}
+	if update.Action != influxdb.AuditUpdate || len(update.Diff) != 1 || update.Diff[0].Field != "name" ||
+		string(update.Diff[0].Before) != `"b1"` || string(update.Diff[0].After) != `"b2"` {
+		t.Errorf("unexpected update event: %+v", update)
+	}
+	if del.Action != influxdb.AuditDelete || del.Status != http.StatusNoContent || del.Before == nil || del.After != nil {
+		t.Errorf("unexpected delete event: %+v", del)
+	}
+	if failed.Succeeded() || failed.Error != "bucket not found" {
+		t.Errorf("unexpected failed delete event: %+v", failed)
+	}
+}
+
+func TestAuditHandler_Export(t *testing.T) {
+	svc := mock.NewAuditService()
+	svc.FindAuditEventsFn = func(ctx context.Context, f influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+		if f.Action == nil || *f.Action != influxdb.AuditDelete {
+			t.Errorf("expected delete action filter")
+		}
+		return []*influxdb.AuditEvent{
+			{ID: 1, Action: influxdb.AuditDelete, Method: "DELETE", Status: 204},
+			{ID: 2, Action: influxdb.AuditDelete, Method: "DELETE", Status: 204},
+		}, 2, nil
+	}
+
+	h := NewAuditHandler(zaptest.NewLogger(t), &AuditBackend{
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		log:              zaptest.NewLogger(t),
+		AuditService:     svc,
+	})
+
+	r := httptest.NewRequest("GET", auditExportPath+"?action=delete", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, _ := ioutil.ReadAll(res.Body)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", res.StatusCode, body)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("unexpected content type %q", ct)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), body)
+	}
+	for _, l := range lines {
+		var e influxdb.AuditEvent
+		if err := json.Unmarshal([]byte(l), &e); err != nil {
+			t.Errorf("invalid json line %q: %v", l, err)
+		}
+	}
+
+	r = httptest.NewRequest("GET", prefixAudit+"?action=rename", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid action to be rejected, got %d", w.Code)
+	}
+}
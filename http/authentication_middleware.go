@@ -25,6 +25,11 @@ type AuthenticationHandler struct {
 	TokenParser          *jsonweb.TokenParser
 	SessionRenewDisabled bool
 
+	// ClientCertificates maps verified TLS client certificates to
+	// authorizations. Requests with a token or session are authenticated
+	// by those instead.
+	ClientCertificates *ClientCertificateMap
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -50,16 +55,21 @@ func (h *AuthenticationHandler) RegisterNoAuthRoute(method, path string) {
 }
 
 const (
-	tokenAuthScheme   = "token"
-	sessionAuthScheme = "session"
+	tokenAuthScheme       = "token"
+	sessionAuthScheme     = "session"
+	certificateAuthScheme = "certificate"
 )
 
-// ProbeAuthScheme probes the http request for the requests for token or cookie session.
+// ProbeAuthScheme probes the http request for the requests for token, cookie session
+// or verified TLS client certificate.
 func ProbeAuthScheme(r *http.Request) (string, error) {
 	_, tokenErr := GetToken(r)
 	_, sessErr := decodeCookieSession(r.Context(), r)
 
 	if tokenErr != nil && sessErr != nil {
+		if verifiedClientCertificate(r) != nil {
+			return certificateAuthScheme, nil
+		}
 		return "", fmt.Errorf("token required")
 	}
 
@@ -95,6 +105,8 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		auth, err = h.extractAuthorization(ctx, r)
 	case sessionAuthScheme:
 		auth, err = h.extractSession(ctx, r)
+	case certificateAuthScheme:
+		auth, err = h.extractCertificateAuthorization(ctx, r)
 	default:
 		// TODO: this error will be nil if it gets here, this should be remedied with some
 		//  sentinel error I'm thinking
@@ -155,6 +167,29 @@ func (h *AuthenticationHandler) extractAuthorization(ctx context.Context, r *htt
 	return h.AuthorizationService.FindAuthorizationByToken(ctx, t)
 }
 
+func (h *AuthenticationHandler) extractCertificateAuthorization(ctx context.Context, r *http.Request) (*platform.Authorization, error) {
+	cert := verifiedClientCertificate(r)
+	if cert == nil {
+		return nil, errors.New("client certificate required")
+	}
+
+	id, ok := h.ClientCertificates.Lookup(cert)
+	if !ok {
+		return nil, fmt.Errorf("no authorization for client certificate %q", cert.Subject.String())
+	}
+
+	a, err := h.AuthorizationService.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !a.IsActive() {
+		return nil, fmt.Errorf("authorization %s for client certificate %q is inactive", id, cert.Subject.String())
+	}
+
+	return a, nil
+}
+
 func (h *AuthenticationHandler) extractSession(ctx context.Context, r *http.Request) (*platform.Session, error) {
 	k, err := decodeCookieSession(ctx, r)
 	if err != nil {
@@ -2,6 +2,9 @@ package http_test
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,6 +14,7 @@ import (
 
 	influxdb "github.com/influxdata/influxdb"
 	platform "github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
 	platformhttp "github.com/influxdata/influxdb/http"
 	"github.com/influxdata/influxdb/jsonweb"
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
@@ -249,6 +253,76 @@ func TestAuthenticationHandler(t *testing.T) {
 	}
 }
 
+func TestAuthenticationHandler_ClientCertificate(t *testing.T) {
+	authID := platform.ID(10)
+	certs, err := platformhttp.NewClientCertificateMap(map[string]platform.ID{
+		"cn:telegraf-01": authID,
+		"cn:telegraf-02": platform.ID(11),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authSvc := &mock.AuthorizationService{
+		FindAuthorizationByIDFn: func(ctx context.Context, id platform.ID) (*platform.Authorization, error) {
+			status := platform.Active
+			if id != authID {
+				status = platform.Inactive
+			}
+			return &platform.Authorization{ID: id, Status: status}, nil
+		},
+		FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+			return nil, fmt.Errorf("authorization not found")
+		},
+	}
+
+	tests := []struct {
+		name  string
+		cn    string
+		token string
+		code  int
+	}{
+		{name: "mapped certificate", cn: "telegraf-01", code: http.StatusOK},
+		{name: "unmapped certificate", cn: "telegraf-03", code: http.StatusUnauthorized},
+		{name: "inactive authorization", cn: "telegraf-02", code: http.StatusUnauthorized},
+		{name: "token takes precedence", cn: "telegraf-01", token: "abc123", code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got platform.Authorizer
+			h := platformhttp.NewAuthenticationHandler(zaptest.NewLogger(t), kithttp.ErrorHandler(0))
+			h.AuthorizationService = authSvc
+			h.SessionService = mock.NewSessionService()
+			h.ClientCertificates = certs
+			h.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = icontext.GetAuthorizer(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "https://any.url", nil)
+			r.TLS = &tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{{
+					{Subject: pkix.Name{CommonName: tt.cn}},
+				}},
+			}
+			if tt.token != "" {
+				platformhttp.SetToken(tt.token, r)
+			}
+
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("expected status code to be %d got %d", tt.code, w.Code)
+			}
+			if tt.code == http.StatusOK && got.Identifier() != authID {
+				t.Fatalf("expected authorization %s got %s", authID, got.Identifier())
+			}
+		})
+	}
+}
+
 func TestProbeAuthScheme(t *testing.T) {
 	type args struct {
 		token   string
@@ -24,6 +24,7 @@ type BucketBackend struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	BucketStatsService         influxdb.BucketStatsService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -38,6 +39,7 @@ func NewBucketBackend(log *zap.Logger, b *APIBackend) *BucketBackend {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		BucketStatsService:         b.BucketStatsService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -53,6 +55,7 @@ type BucketHandler struct {
 
 	BucketService              influxdb.BucketService
 	BucketOperationLogService  influxdb.BucketOperationLogService
+	BucketStatsService         influxdb.BucketStatsService
 	UserResourceMappingService influxdb.UserResourceMappingService
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
@@ -63,6 +66,7 @@ const (
 	prefixBuckets          = "/api/v2/buckets"
 	bucketsIDPath          = "/api/v2/buckets/:id"
 	bucketsIDLogPath       = "/api/v2/buckets/:id/logs"
+	bucketsIDStatsPath     = "/api/v2/buckets/:id/stats"
 	bucketsIDMembersPath   = "/api/v2/buckets/:id/members"
 	bucketsIDMembersIDPath = "/api/v2/buckets/:id/members/:userID"
 	bucketsIDOwnersPath    = "/api/v2/buckets/:id/owners"
@@ -80,6 +84,7 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 
 		BucketService:              b.BucketService,
 		BucketOperationLogService:  b.BucketOperationLogService,
+		BucketStatsService:         b.BucketStatsService,
 		UserResourceMappingService: b.UserResourceMappingService,
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
@@ -92,6 +97,9 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 	h.HandlerFunc("GET", bucketsIDLogPath, h.handleGetBucketLog)
 	h.HandlerFunc("PATCH", bucketsIDPath, h.handlePatchBucket)
 	h.HandlerFunc("DELETE", bucketsIDPath, h.handleDeleteBucket)
+	if h.BucketStatsService != nil {
+		h.HandlerFunc("GET", bucketsIDStatsPath, h.handleGetBucketStats)
+	}
 
 	memberBackend := MemberBackend{
 		HTTPErrorHandler:           b.HTTPErrorHandler,
@@ -146,6 +154,11 @@ type bucket struct {
 type retentionRule struct {
 	Type         string `json:"type"`
 	EverySeconds int64  `json:"everySeconds"`
+
+	// PartitionSeconds is the duration of the time partitions the data of
+	// the bucket is split into, so that expired data is removed in whole
+	// files. It is only valid on expire rules.
+	PartitionSeconds int64 `json:"partitionSeconds,omitempty"`
 }
 
 func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
@@ -160,22 +173,105 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// Retention rule types. Rules without a type expire data.
+const (
+	retentionRuleExpire = "expire"
+	retentionRuleCold   = "cold"
+)
+
+// retentionPeriods returns the periods after which data is expired and moved
+// to the cold tier, and the duration of the partitions expired data is
+// removed in. Only the first rule of each type is used; a zero period means
+// the rule is absent.
+func retentionPeriods(rules []retentionRule) (expire, cold, partition time.Duration, err error) {
+	var seenExpire, seenCold bool
+	for i := range rules {
+		rr := &rules[i]
+		switch rr.Type {
+		case "", retentionRuleExpire:
+			if seenExpire {
+				continue
+			}
+			seenExpire = true
+			if expire, err = rr.RetentionPeriod(); err != nil {
+				return 0, 0, 0, err
+			}
+			partition = time.Duration(rr.PartitionSeconds) * time.Second
+			if partition < 0 {
+				return 0, 0, 0, &influxdb.Error{
+					Code: influxdb.EUnprocessableEntity,
+					Msg:  "partition seconds must not be negative",
+				}
+			}
+			if partition > expire {
+				return 0, 0, 0, &influxdb.Error{
+					Code: influxdb.EUnprocessableEntity,
+					Msg:  "partition seconds must not be greater than expiration seconds",
+				}
+			}
+		case retentionRuleCold:
+			if seenCold {
+				continue
+			}
+			seenCold = true
+			cold = time.Duration(rr.EverySeconds) * time.Second
+			if cold < time.Second {
+				return 0, 0, 0, &influxdb.Error{
+					Code: influxdb.EUnprocessableEntity,
+					Msg:  "cold tier seconds must be greater than or equal to one second",
+				}
+			}
+			if rr.PartitionSeconds != 0 {
+				return 0, 0, 0, &influxdb.Error{
+					Code: influxdb.EUnprocessableEntity,
+					Msg:  "partition seconds are only valid on expire rules",
+				}
+			}
+		default:
+			return 0, 0, 0, &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Msg:  fmt.Sprintf("unknown retention rule type %q", rr.Type),
+			}
+		}
+	}
+
+	if cold > 0 && expire > 0 && cold >= expire {
+		return 0, 0, 0, &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "cold tier seconds must be less than expiration seconds",
+		}
+	}
+	return expire, cold, partition, nil
+}
+
+// newRetentionRules returns the retention rules for the given periods.
+func newRetentionRules(expire, cold, partition time.Duration) []retentionRule {
+	rules := []retentionRule{}
+	if rp := int64(expire.Round(time.Second) / time.Second); rp > 0 {
+		rules = append(rules, retentionRule{
+			Type:             retentionRuleExpire,
+			EverySeconds:     rp,
+			PartitionSeconds: int64(partition.Round(time.Second) / time.Second),
+		})
+	}
+	if cp := int64(cold.Round(time.Second) / time.Second); cp > 0 {
+		rules = append(rules, retentionRule{
+			Type:         retentionRuleCold,
+			EverySeconds: cp,
+		})
+	}
+	return rules
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
 	}
 
-	var d time.Duration // zero value implies infinite retention policy
-
-	// Only support a single retention period for the moment
-	if len(b.RetentionRules) > 0 {
-		d = time.Duration(b.RetentionRules[0].EverySeconds) * time.Second
-		if d < time.Second {
-			return nil, &influxdb.Error{
-				Code: influxdb.EUnprocessableEntity,
-				Msg:  "expiration seconds must be greater than or equal to one second",
-			}
-		}
+	// zero values imply infinite retention, no cold tier and no partitions
+	d, cold, partition, err := retentionPeriods(b.RetentionRules)
+	if err != nil {
+		return nil, err
 	}
 
 	return &influxdb.Bucket{
@@ -186,6 +282,8 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		ColdAfter:           cold,
+		PartitionDuration:   partition,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -195,15 +293,6 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		return nil
 	}
 
-	rules := []retentionRule{}
-	rp := int64(pb.RetentionPeriod.Round(time.Second) / time.Second)
-	if rp > 0 {
-		rules = append(rules, retentionRule{
-			Type:         "expire",
-			EverySeconds: rp,
-		})
-	}
-
 	return &bucket{
 		ID:                  pb.ID,
 		OrgID:               pb.OrgID,
@@ -211,7 +300,7 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Name:                pb.Name,
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
-		RetentionRules:      rules,
+		RetentionRules:      newRetentionRules(pb.RetentionPeriod, pb.ColdAfter, pb.PartitionDuration),
 		CRUDLog:             pb.CRUDLog,
 	}
 }
@@ -224,13 +313,8 @@ type bucketUpdate struct {
 }
 
 func (b *bucketUpdate) OK() error {
-	if len(b.RetentionRules) > 0 {
-		_, err := b.RetentionRules[0].RetentionPeriod()
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	_, _, _, err := retentionPeriods(b.RetentionRules)
+	return err
 }
 
 func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
@@ -238,17 +322,20 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		return nil
 	}
 
-	// For now, only use a single retention rule.
-	var d time.Duration
-	if len(b.RetentionRules) > 0 {
-		d, _ = b.RetentionRules[0].RetentionPeriod()
-	}
+	d, cold, partition, _ := retentionPeriods(b.RetentionRules)
 
-	return &influxdb.BucketUpdate{
+	upd := &influxdb.BucketUpdate{
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
 	}
+	// The rules replace the bucket's rules, so a missing cold rule removes
+	// the bucket's cold tier and missing partition seconds its partitions.
+	if len(b.RetentionRules) > 0 {
+		upd.ColdAfter = &cold
+		upd.PartitionDuration = &partition
+	}
+	return upd
 }
 
 func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
@@ -264,10 +351,17 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 
 	if pb.RetentionPeriod != nil {
 		d := int64((*pb.RetentionPeriod).Round(time.Second) / time.Second)
-		up.RetentionRules = append(up.RetentionRules, retentionRule{
-			Type:         "expire",
+		rule := retentionRule{
+			Type:         retentionRuleExpire,
 			EverySeconds: d,
-		})
+		}
+		if pb.PartitionDuration != nil {
+			rule.PartitionSeconds = int64((*pb.PartitionDuration).Round(time.Second) / time.Second)
+		}
+		up.RetentionRules = append(up.RetentionRules, rule)
+	}
+	if pb.ColdAfter != nil && *pb.ColdAfter > 0 {
+		up.RetentionRules = append(up.RetentionRules, newRetentionRules(0, *pb.ColdAfter, 0)...)
 	}
 	return up
 }
@@ -351,13 +445,10 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
-	// Only support a single retention period for the moment
-	if len(b.RetentionRules) > 0 {
-		if _, err := b.RetentionRules[0].RetentionPeriod(); err != nil {
-			return &influxdb.Error{
-				Code: influxdb.EUnprocessableEntity,
-				Msg:  err.Error(),
-			}
+	if _, _, _, err := retentionPeriods(b.RetentionRules); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  err.Error(),
 		}
 	}
 
@@ -373,11 +464,7 @@ func (b *postBucketRequest) OK() error {
 }
 
 func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
-	// Only support a single retention period for the moment
-	var dur time.Duration
-	if len(b.RetentionRules) > 0 {
-		dur, _ = b.RetentionRules[0].RetentionPeriod()
-	}
+	dur, cold, partition, _ := retentionPeriods(b.RetentionRules)
 
 	return &influxdb.Bucket{
 		OrgID:               b.OrgID,
@@ -386,6 +473,8 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		ColdAfter:           cold,
+		PartitionDuration:   partition,
 	}
 }
 
@@ -458,6 +547,49 @@ func newBucketLogResponse(id influxdb.ID, es []*influxdb.OperationLogEntry) *ope
 	}
 }
 
+// handleGetBucketStats is the HTTP handler for the GET /api/v2/buckets/:id/stats route.
+func (h *BucketHandler) handleGetBucketStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	// Finding the bucket checks that it may be read.
+	b, err := h.BucketService.FindBucketByID(ctx, id)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	stats, err := h.BucketStatsService.BucketStats(ctx, b.OrgID, b.ID)
+	if err != nil {
+		h.api.Err(w, err)
+		return
+	}
+
+	h.api.Respond(w, http.StatusOK, newBucketStatsResponse(stats))
+}
+
+type bucketStatsResponse struct {
+	Links map[string]string `json:"links"`
+	*influxdb.BucketStats
+}
+
+func newBucketStatsResponse(stats *influxdb.BucketStats) *bucketStatsResponse {
+	if stats.Measurements == nil {
+		stats.Measurements = []influxdb.MeasurementStorageStats{}
+	}
+	return &bucketStatsResponse{
+		Links: map[string]string{
+			"self":   fmt.Sprintf("/api/v2/buckets/%s/stats", stats.BucketID),
+			"bucket": fmt.Sprintf("/api/v2/buckets/%s", stats.BucketID),
+		},
+		BucketStats: stats,
+	}
+}
+
 // handleDeleteBucket is the HTTP handler for the DELETE /api/v2/buckets/:id route.
 func (h *BucketHandler) handleDeleteBucket(w http.ResponseWriter, r *http.Request) {
 	id, err := decodeIDFromCtx(r.Context(), "id")
@@ -780,6 +912,23 @@ func (s *BucketService) DeleteBucket(ctx context.Context, id influxdb.ID) error
 		Do(ctx)
 }
 
+// BucketStats returns the stats of the data stored for bucketID. The server
+// finds the organization of the bucket, so orgID is not sent.
+func (s *BucketService) BucketStats(ctx context.Context, orgID, bucketID influxdb.ID) (*influxdb.BucketStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var stats influxdb.BucketStats
+	err := s.Client.
+		Get(bucketIDPath(bucketID), "stats").
+		DecodeJSON(&stats).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // validBucketName reports any errors with bucket names
 func validBucketName(bucket *influxdb.Bucket) error {
 	// names starting with an underscore are reserved for system buckets
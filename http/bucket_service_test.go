@@ -1225,3 +1225,138 @@ func mustNewHTTPClient(t *testing.T, addr, token string) *httpc.Client {
 	}
 	return httpClient
 }
+
+func TestRetentionPeriods(t *testing.T) {
+	tests := []struct {
+		name      string
+		rules     []retentionRule
+		expire    time.Duration
+		cold      time.Duration
+		partition time.Duration
+		wantErr   bool
+	}{
+		{
+			name: "no rules",
+		},
+		{
+			name:   "expire and cold",
+			rules:  []retentionRule{{Type: "expire", EverySeconds: 3600}, {Type: "cold", EverySeconds: 60}},
+			expire: time.Hour,
+			cold:   time.Minute,
+		},
+		{
+			name:  "cold without expiry",
+			rules: []retentionRule{{Type: "cold", EverySeconds: 60}},
+			cold:  time.Minute,
+		},
+		{
+			name:    "cold after expiry",
+			rules:   []retentionRule{{Type: "expire", EverySeconds: 60}, {Type: "cold", EverySeconds: 60}},
+			wantErr: true,
+		},
+		{
+			name:    "cold less than a second",
+			rules:   []retentionRule{{Type: "cold", EverySeconds: 0}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			rules:   []retentionRule{{Type: "archive", EverySeconds: 60}},
+			wantErr: true,
+		},
+		{
+			name:      "partitioned expiry",
+			rules:     []retentionRule{{Type: "expire", EverySeconds: 86400, PartitionSeconds: 3600}},
+			expire:    24 * time.Hour,
+			partition: time.Hour,
+		},
+		{
+			name:    "partition longer than expiry",
+			rules:   []retentionRule{{Type: "expire", EverySeconds: 3600, PartitionSeconds: 7200}},
+			wantErr: true,
+		},
+		{
+			name:    "partitioned cold rule",
+			rules:   []retentionRule{{Type: "cold", EverySeconds: 60, PartitionSeconds: 60}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expire, cold, partition, err := retentionPeriods(tt.rules)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("retentionPeriods() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if influxdb.ErrorCode(err) != influxdb.EUnprocessableEntity {
+					t.Fatalf("retentionPeriods() error code = %q, want %q", influxdb.ErrorCode(err), influxdb.EUnprocessableEntity)
+				}
+				return
+			}
+			if expire != tt.expire || cold != tt.cold || partition != tt.partition {
+				t.Fatalf("retentionPeriods() = %v, %v, %v, want %v, %v, %v", expire, cold, partition, tt.expire, tt.cold, tt.partition)
+			}
+
+			// Rules round trip through the bucket model.
+			b := newBucket(&influxdb.Bucket{RetentionPeriod: expire, ColdAfter: cold, PartitionDuration: partition})
+			pb, err := b.toInfluxDB()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if pb.RetentionPeriod != expire || pb.ColdAfter != cold || pb.PartitionDuration != partition {
+				t.Fatalf("round trip = %v, %v, %v, want %v, %v, %v", pb.RetentionPeriod, pb.ColdAfter, pb.PartitionDuration, expire, cold, partition)
+			}
+		})
+	}
+}
+
+func TestBucketService_BucketStats(t *testing.T) {
+	orgID, bucketID := platformtesting.MustIDBase16("020f755c3c082000"), platformtesting.MustIDBase16("020f755c3c082001")
+	oldest := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	bucketBackend := NewMockBucketBackend(t)
+	bucketBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+	bucketBackend.BucketService = &mock.BucketService{
+		FindBucketByIDFn: func(ctx context.Context, id platform.ID) (*platform.Bucket, error) {
+			if id != bucketID {
+				return nil, &platform.Error{Code: platform.ENotFound, Msg: "bucket not found"}
+			}
+			return &platform.Bucket{ID: id, OrgID: orgID, Name: "b"}, nil
+		},
+	}
+	bucketBackend.BucketStatsService = &mock.BucketStatsService{
+		BucketStatsFn: func(ctx context.Context, o, b platform.ID) (*platform.BucketStats, error) {
+			if o != orgID || b != bucketID {
+				t.Errorf("BucketStats(%s, %s), want (%s, %s)", o, b, orgID, bucketID)
+			}
+			return &platform.BucketStats{
+				OrgID:        o,
+				BucketID:     b,
+				StorageStats: platform.StorageStats{SeriesCardinality: 3, TSMBytes: 100, OldestTime: &oldest},
+				Measurements: []platform.MeasurementStorageStats{
+					{Name: "cpu", StorageStats: platform.StorageStats{SeriesCardinality: 3, TSMBytes: 100, OldestTime: &oldest}},
+				},
+			}, nil
+		},
+	}
+	server := httptest.NewServer(NewBucketHandler(zaptest.NewLogger(t), bucketBackend))
+	defer server.Close()
+	client := BucketService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	stats, err := client.BucketStats(context.Background(), 0, bucketID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.BucketID != bucketID || stats.SeriesCardinality != 3 || stats.TSMBytes != 100 || !stats.OldestTime.Equal(oldest) {
+		t.Fatalf("got stats %+v", stats)
+	}
+	if len(stats.Measurements) != 1 || stats.Measurements[0].Name != "cpu" || stats.Measurements[0].SeriesCardinality != 3 {
+		t.Fatalf("got measurements %+v", stats.Measurements)
+	}
+
+	// The stats of buckets that cannot be found are not returned.
+	if _, err := client.BucketStats(context.Background(), 0, orgID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Fatalf("got error %v, want not found", err)
+	}
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+// Prefixes of the certificate identities understood by a ClientCertificateMap.
+const (
+	certIdentityCommonName = "cn:"
+	certIdentityDNS        = "dns:"
+	certIdentityEmail      = "email:"
+	certIdentityURI        = "uri:"
+)
+
+// ClientCertificateMap maps the identities of verified TLS client
+// certificates to the authorizations the clients act as.
+//
+// An identity is the subject common name or one of the subject alternative
+// names of a certificate, written as "cn:<name>", "dns:<name>",
+// "email:<address>" or "uri:<uri>".
+type ClientCertificateMap struct {
+	authorizations map[string]platform.ID
+}
+
+// NewClientCertificateMap returns a map of identities to authorization IDs.
+func NewClientCertificateMap(m map[string]platform.ID) (*ClientCertificateMap, error) {
+	cm := &ClientCertificateMap{authorizations: make(map[string]platform.ID, len(m))}
+	for identity, id := range m {
+		if !validCertIdentity(identity) {
+			return nil, fmt.Errorf("invalid certificate identity %q: expected a cn:, dns:, email: or uri: prefix", identity)
+		}
+		if !id.Valid() {
+			return nil, fmt.Errorf("invalid authorization ID for certificate identity %q", identity)
+		}
+		cm.authorizations[identity] = id
+	}
+	return cm, nil
+}
+
+// ParseClientCertificateMap parses lines of the form
+// "<identity> <authorization ID>". Blank lines and lines starting with '#'
+// are ignored.
+func ParseClientCertificateMap(r io.Reader) (*ClientCertificateMap, error) {
+	m := make(map[string]platform.ID)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an identity and an authorization ID", n)
+		}
+
+		id, err := platform.IDFromString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		if _, ok := m[fields[0]]; ok {
+			return nil, fmt.Errorf("line %d: duplicate certificate identity %q", n, fields[0])
+		}
+		m[fields[0]] = *id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewClientCertificateMap(m)
+}
+
+// LoadClientCertificateMapFile parses the ClientCertificateMap in the file at path.
+func LoadClientCertificateMapFile(path string) (*ClientCertificateMap, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseClientCertificateMap(bytes.NewReader(b))
+}
+
+// Lookup returns the authorization ID mapped to the first identity of cert
+// found in the map. Subject alternative names are checked before the
+// common name.
+func (m *ClientCertificateMap) Lookup(cert *x509.Certificate) (platform.ID, bool) {
+	if m == nil || cert == nil {
+		return 0, false
+	}
+	for _, identity := range certificateIdentities(cert) {
+		if id, ok := m.authorizations[identity]; ok {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func certificateIdentities(cert *x509.Certificate) []string {
+	var ids []string
+	for _, u := range cert.URIs {
+		ids = append(ids, certIdentityURI+u.String())
+	}
+	for _, name := range cert.DNSNames {
+		ids = append(ids, certIdentityDNS+name)
+	}
+	for _, addr := range cert.EmailAddresses {
+		ids = append(ids, certIdentityEmail+addr)
+	}
+	if cn := cert.Subject.CommonName; cn != "" {
+		ids = append(ids, certIdentityCommonName+cn)
+	}
+	return ids
+}
+
+func validCertIdentity(identity string) bool {
+	for _, prefix := range []string{certIdentityCommonName, certIdentityDNS, certIdentityEmail, certIdentityURI} {
+		if strings.HasPrefix(identity, prefix) && len(identity) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifiedClientCertificate returns the client certificate of a request
+// made over TLS if the server verified it against its client CAs.
+func verifiedClientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return r.TLS.VerifiedChains[0][0]
+}
@@ -0,0 +1,79 @@
+package http_test
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"strings"
+	"testing"
+
+	platform "github.com/influxdata/influxdb"
+	platformhttp "github.com/influxdata/influxdb/http"
+)
+
+func TestParseClientCertificateMap(t *testing.T) {
+	m, err := platformhttp.ParseClientCertificateMap(strings.NewReader(`
+# identity                              authorization
+cn:telegraf-01                          000000000000000a
+dns:telegraf-01.example.com             000000000000000b
+uri:spiffe://example.com/telegraf/02    000000000000000c
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spiffe, _ := url.Parse("spiffe://example.com/telegraf/02")
+	tests := []struct {
+		name string
+		cert *x509.Certificate
+		want platform.ID
+		ok   bool
+	}{
+		{
+			name: "common name",
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "telegraf-01"}},
+			want: 10,
+			ok:   true,
+		},
+		{
+			name: "subject alternative names before common name",
+			cert: &x509.Certificate{
+				Subject:  pkix.Name{CommonName: "telegraf-01"},
+				DNSNames: []string{"telegraf-01.example.com"},
+			},
+			want: 11,
+			ok:   true,
+		},
+		{
+			name: "uri",
+			cert: &x509.Certificate{URIs: []*url.URL{spiffe}},
+			want: 12,
+			ok:   true,
+		},
+		{
+			name: "unknown",
+			cert: &x509.Certificate{Subject: pkix.Name{CommonName: "telegraf-03"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.Lookup(tt.cert)
+			if ok != tt.ok || got != tt.want {
+				t.Fatalf("unexpected lookup result: got %s %v, want %s %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseClientCertificateMap_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"cn:telegraf-01",
+		"telegraf-01 000000000000000a",
+		"cn:telegraf-01 not-an-id",
+		"cn:telegraf-01 000000000000000a\ncn:telegraf-01 000000000000000b",
+	} {
+		if _, err := platformhttp.ParseClientCertificateMap(strings.NewReader(in)); err == nil {
+			t.Errorf("expected an error parsing %q", in)
+		}
+	}
+}
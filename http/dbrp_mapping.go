@@ -0,0 +1,271 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const prefixDBRPMappings = "/api/v2/dbrps"
+
+// DBRPMappingBackend is all services and associated parameters required to
+// construct the DBRPMappingHandler.
+type DBRPMappingBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	DBRPMappingService influxdb.DBRPMappingService
+	BucketService      influxdb.BucketService
+}
+
+// NewDBRPMappingBackend returns a new instance of DBRPMappingBackend.
+func NewDBRPMappingBackend(log *zap.Logger, b *APIBackend) *DBRPMappingBackend {
+	return &DBRPMappingBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		DBRPMappingService: b.DBRPMappingService,
+		BucketService:      b.BucketService,
+	}
+}
+
+// DBRPMappingHandler manages the mappings of InfluxDB 1.x databases and
+// retention policies to buckets.
+type DBRPMappingHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	DBRPMappingService influxdb.DBRPMappingService
+	BucketService      influxdb.BucketService
+}
+
+// NewDBRPMappingHandler returns a new instance of DBRPMappingHandler.
+func NewDBRPMappingHandler(log *zap.Logger, b *DBRPMappingBackend) *DBRPMappingHandler {
+	h := &DBRPMappingHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		DBRPMappingService: b.DBRPMappingService,
+		BucketService:      b.BucketService,
+	}
+
+	h.HandlerFunc("GET", prefixDBRPMappings, h.handleGetDBRPMappings)
+	h.HandlerFunc("POST", prefixDBRPMappings, h.handlePostDBRPMapping)
+	h.HandlerFunc("DELETE", prefixDBRPMappings, h.handleDeleteDBRPMapping)
+	return h
+}
+
+type dbrpMappingsResponse struct {
+	DBRPMappings []*influxdb.DBRPMapping `json:"dbrps"`
+}
+
+// handleGetDBRPMappings is the HTTP handler for the GET /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handleGetDBRPMappings(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DBRPMappingHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	filter, err := decodeDBRPMappingFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	ms, _, err := h.DBRPMappingService.FindMany(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, dbrpMappingsResponse{DBRPMappings: ms}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePostDBRPMapping is the HTTP handler for the POST /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handlePostDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DBRPMappingHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var m influxdb.DBRPMapping
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}, w)
+		return
+	}
+	if m.Cluster == "" {
+		m.Cluster = influxdb.DefaultDBRPCluster
+	}
+
+	// The mapping always belongs to the organization of its bucket.
+	b, err := h.BucketService.FindBucketByID(ctx, m.BucketID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if m.OrganizationID.Valid() && m.OrganizationID != b.OrgID {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "bucket does not belong to the organization of the dbrp mapping",
+		}, w)
+		return
+	}
+	m.OrganizationID = b.OrgID
+
+	if err := h.DBRPMappingService.Create(ctx, &m); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("DBRP mapping created", zap.String("database", m.Database), zap.String("retentionPolicy", m.RetentionPolicy))
+
+	if err := encodeResponse(ctx, w, http.StatusCreated, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handleDeleteDBRPMapping is the HTTP handler for the DELETE /api/v2/dbrps route.
+func (h *DBRPMappingHandler) handleDeleteDBRPMapping(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DBRPMappingHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	qp := r.URL.Query()
+	cluster, db, rp := qp.Get("cluster"), qp.Get("db"), qp.Get("rp")
+	if cluster == "" {
+		cluster = influxdb.DefaultDBRPCluster
+	}
+	if db == "" || rp == "" {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "db and rp are required",
+		}, w)
+		return
+	}
+
+	if err := h.DBRPMappingService.Delete(ctx, cluster, db, rp); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeDBRPMappingFilter(r *http.Request) (influxdb.DBRPMappingFilter, error) {
+	var filter influxdb.DBRPMappingFilter
+	qp := r.URL.Query()
+	if v := qp.Get("cluster"); v != "" {
+		filter.Cluster = &v
+	}
+	if v := qp.Get("db"); v != "" {
+		filter.Database = &v
+	}
+	if v := qp.Get("rp"); v != "" {
+		filter.RetentionPolicy = &v
+	}
+	if v := qp.Get("default"); v != "" {
+		d, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "default must be true or false",
+				Err:  err,
+			}
+		}
+		filter.Default = &d
+	}
+	return filter, nil
+}
+
+// DBRPMappingService is the client implementation of influxdb.DBRPMappingService.
+type DBRPMappingService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.DBRPMappingService = (*DBRPMappingService)(nil)
+
+// FindBy returns the dbrp mapping for cluster, db and rp.
+func (s *DBRPMappingService) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	return s.Find(ctx, influxdb.DBRPMappingFilter{
+		Cluster:         &cluster,
+		Database:        &db,
+		RetentionPolicy: &rp,
+	})
+}
+
+// Find returns the first dbrp mapping that matches filter.
+func (s *DBRPMappingService) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	ms, n, err := s.FindMany(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "dbrp mapping not found",
+		}
+	}
+	return ms[0], nil
+}
+
+// FindMany returns the dbrp mappings that match filter and the total count
+// of matching dbrp mappings.
+func (s *DBRPMappingService) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	var params [][2]string
+	if filter.Cluster != nil {
+		params = append(params, [2]string{"cluster", *filter.Cluster})
+	}
+	if filter.Database != nil {
+		params = append(params, [2]string{"db", *filter.Database})
+	}
+	if filter.RetentionPolicy != nil {
+		params = append(params, [2]string{"rp", *filter.RetentionPolicy})
+	}
+	if filter.Default != nil {
+		params = append(params, [2]string{"default", strconv.FormatBool(*filter.Default)})
+	}
+
+	var resp dbrpMappingsResponse
+	err := s.Client.
+		Get(prefixDBRPMappings).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.DBRPMappings, len(resp.DBRPMappings), nil
+}
+
+// Create creates a new dbrp mapping.
+func (s *DBRPMappingService) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	return s.Client.
+		PostJSON(m, prefixDBRPMappings).
+		DecodeJSON(m).
+		Do(ctx)
+}
+
+// Delete removes a dbrp mapping.
+func (s *DBRPMappingService) Delete(ctx context.Context, cluster, db, rp string) error {
+	return s.Client.
+		Delete(prefixDBRPMappings).
+		QueryParams(
+			[2]string{"cluster", cluster},
+			[2]string{"db", db},
+			[2]string{"rp", rp},
+		).
+		Do(ctx)
+}
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	influxtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDBRPMappingService(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxtesting.MustIDBase16("043e0780ee2b1000")
+	bucketID := influxtesting.MustIDBase16("04504b356e23b000")
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+		if id != bucketID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+		}
+		return &influxdb.Bucket{ID: bucketID, OrgID: orgID}, nil
+	}
+
+	backend := &DBRPMappingBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		log:                zaptest.NewLogger(t),
+		DBRPMappingService: newInMemKVSVC(t),
+		BucketService:      buckets,
+	}
+	server := httptest.NewServer(NewDBRPMappingHandler(zaptest.NewLogger(t), backend))
+	defer server.Close()
+
+	client := &DBRPMappingService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	m := &influxdb.DBRPMapping{
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  orgID,
+		BucketID:        bucketID,
+	}
+	if err := client.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Cluster != influxdb.DefaultDBRPCluster {
+		t.Fatalf("expected the default cluster, got: %+v", m)
+	}
+
+	other := &influxdb.DBRPMapping{
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		OrganizationID:  influxtesting.MustIDBase16("043e0780ee2b2000"),
+		BucketID:        bucketID,
+	}
+	if err := client.Create(ctx, other); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected %q for a bucket of another organization, got: %v", influxdb.EInvalid, err)
+	}
+
+	defaultRP := true
+	found, err := client.Find(ctx, influxdb.DBRPMappingFilter{
+		Database: &m.Database,
+		Default:  &defaultRP,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found.Equal(m) {
+		t.Fatalf("unexpected mapping: %+v", found)
+	}
+
+	if err := client.Delete(ctx, m.Cluster, m.Database, m.RetentionPolicy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.FindBy(ctx, m.Cluster, m.Database, m.RetentionPolicy); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected %q after delete, got: %v", influxdb.ENotFound, err)
+	}
+}
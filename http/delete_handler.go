@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	http "net/http"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/httprouter"
@@ -23,6 +25,7 @@ type DeleteBackend struct {
 	influxdb.HTTPErrorHandler
 
 	DeleteService       influxdb.DeleteService
+	DeleteJobService    influxdb.DeleteJobService
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
 }
@@ -34,6 +37,7 @@ func NewDeleteBackend(log *zap.Logger, b *APIBackend) *DeleteBackend {
 
 		HTTPErrorHandler:    b.HTTPErrorHandler,
 		DeleteService:       b.DeleteService,
+		DeleteJobService:    b.DeleteJobService,
 		BucketService:       b.BucketService,
 		OrganizationService: b.OrganizationService,
 	}
@@ -47,12 +51,15 @@ type DeleteHandler struct {
 	log *zap.Logger
 
 	DeleteService       influxdb.DeleteService
+	DeleteJobService    influxdb.DeleteJobService
 	BucketService       influxdb.BucketService
 	OrganizationService influxdb.OrganizationService
 }
 
 const (
-	prefixDelete = "/api/v2/delete"
+	prefixDelete     = "/api/v2/delete"
+	prefixDeleteJobs = "/api/v2/delete/jobs"
+	deleteJobsIDPath = "/api/v2/delete/jobs/:id"
 )
 
 // NewDeleteHandler creates a new handler at /api/v2/delete to recieve delete requests.
@@ -64,10 +71,17 @@ func NewDeleteHandler(log *zap.Logger, b *DeleteBackend) *DeleteHandler {
 
 		BucketService:       b.BucketService,
 		DeleteService:       b.DeleteService,
+		DeleteJobService:    b.DeleteJobService,
 		OrganizationService: b.OrganizationService,
 	}
 
 	h.HandlerFunc("POST", prefixDelete, h.handleDelete)
+	if h.DeleteJobService != nil {
+		h.HandlerFunc("POST", prefixDeleteJobs, h.handlePostDeleteJob)
+		h.HandlerFunc("GET", prefixDeleteJobs, h.handleGetDeleteJobs)
+		h.HandlerFunc("GET", deleteJobsIDPath, h.handleGetDeleteJob)
+		h.HandlerFunc("DELETE", deleteJobsIDPath, h.handleCancelDeleteJob)
+	}
 	return h
 }
 
@@ -114,6 +128,24 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if dr.DryRun {
+		stats, err := h.DeleteService.PreviewBucketRangePredicate(ctx,
+			dr.Org.ID,
+			dr.Bucket.ID,
+			dr.Start,
+			dr.Stop,
+			dr.Predicate,
+		)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if err := encodeResponse(ctx, w, http.StatusOK, stats); err != nil {
+			logEncodingError(h.log, r, err)
+		}
+		return
+	}
+
 	// send delete points request to storage
 	err = h.DeleteService.DeleteBucketRangePredicate(ctx,
 		dr.Org.ID,
@@ -134,6 +166,198 @@ func (h *DeleteHandler) handleDelete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type deleteJobResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.DeleteJob
+}
+
+func newDeleteJobResponse(job *influxdb.DeleteJob) *deleteJobResponse {
+	return &deleteJobResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", prefixDeleteJobs, job.ID),
+		},
+		DeleteJob: *job,
+	}
+}
+
+type deleteJobsResponse struct {
+	Links map[string]string    `json:"links"`
+	Jobs  []*deleteJobResponse `json:"jobs"`
+}
+
+func newDeleteJobsResponse(jobs []*influxdb.DeleteJob) *deleteJobsResponse {
+	res := &deleteJobsResponse{
+		Links: map[string]string{
+			"self": prefixDeleteJobs,
+		},
+		Jobs: make([]*deleteJobResponse, 0, len(jobs)),
+	}
+	for _, job := range jobs {
+		res.Jobs = append(res.Jobs, newDeleteJobResponse(job))
+	}
+	return res
+}
+
+// handlePostDeleteJob is the HTTP handler for the POST /api/v2/delete/jobs route.
+func (h *DeleteHandler) handlePostDeleteJob(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	dr, err := decodeDeleteRequest(
+		ctx, r,
+		h.OrganizationService,
+		h.BucketService,
+	)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if dr.DryRun {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "delete jobs cannot be dry runs; preview the delete with POST /api/v2/delete?dryRun=true",
+		}, w)
+		return
+	}
+
+	job := &influxdb.DeleteJob{
+		OrgID:     dr.Org.ID,
+		BucketID:  dr.Bucket.ID,
+		Start:     time.Unix(0, dr.Start).UTC(),
+		Stop:      time.Unix(0, dr.Stop).UTC(),
+		Predicate: dr.Expr,
+	}
+	if err := h.DeleteJobService.CreateDeleteJob(ctx, job, dr.Predicate); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Delete job created", zap.String("jobID", job.ID.String()))
+
+	if err := encodeResponse(ctx, w, http.StatusAccepted, newDeleteJobResponse(job)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleGetDeleteJobs is the HTTP handler for the GET /api/v2/delete/jobs route.
+func (h *DeleteHandler) handleGetDeleteJobs(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	filter, err := decodeDeleteJobFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	jobs, err := h.DeleteJobService.FindDeleteJobs(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newDeleteJobsResponse(jobs)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleGetDeleteJob is the HTTP handler for the GET /api/v2/delete/jobs/:id route.
+func (h *DeleteHandler) handleGetDeleteJob(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	id, err := decodeDeleteJobID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	job, err := h.DeleteJobService.FindDeleteJobByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newDeleteJobResponse(job)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleCancelDeleteJob is the HTTP handler for the DELETE /api/v2/delete/jobs/:id route.
+func (h *DeleteHandler) handleCancelDeleteJob(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "DeleteHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	id, err := decodeDeleteJobID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.DeleteJobService.CancelDeleteJob(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Delete job canceled", zap.String("jobID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeDeleteJobID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i influxdb.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid delete job id format",
+			Err:  err,
+		}
+	}
+	return i, nil
+}
+
+func decodeDeleteJobFilter(r *http.Request) (influxdb.DeleteJobFilter, error) {
+	var filter influxdb.DeleteJobFilter
+
+	q := r.URL.Query()
+	if v := q.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "orgID is invalid",
+				Err:  err,
+			}
+		}
+		filter.OrgID = id
+	}
+	if v := q.Get("bucketID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "bucketID is invalid",
+				Err:  err,
+			}
+		}
+		filter.BucketID = id
+	}
+	return filter, nil
+}
+
 func decodeDeleteRequest(ctx context.Context, r *http.Request, orgSvc influxdb.OrganizationService, bucketSvc influxdb.BucketService) (*deleteRequest, error) {
 	dr := new(deleteRequest)
 	err := json.NewDecoder(r.Body).Decode(dr)
@@ -151,6 +375,16 @@ func decodeDeleteRequest(ctx context.Context, r *http.Request, orgSvc influxdb.O
 	if dr.Bucket, err = queryBucket(ctx, r, bucketSvc); err != nil {
 		return nil, err
 	}
+
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		if dr.DryRun, err = strconv.ParseBool(v); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "dryRun must be true or false",
+				Err:  err,
+			}
+		}
+	}
 	return dr, nil
 }
 
@@ -160,6 +394,10 @@ type deleteRequest struct {
 	Start     int64
 	Stop      int64
 	Predicate influxdb.Predicate
+	DryRun    bool
+
+	// Expr is the text the predicate was parsed from.
+	Expr string
 }
 
 type deleteRequestDecode struct {
@@ -208,6 +446,7 @@ func (dr *deleteRequest) UnmarshalJSON(b []byte) error {
 		}
 	}
 	dr.Stop = stop.UnixNano()
+	dr.Expr = drd.Predicate
 	node, err := predicate.Parse(drd.Predicate)
 	if err != nil {
 		return err
@@ -225,17 +464,142 @@ type DeleteService struct {
 
 // DeleteBucketRangePredicate send delete request over http to delete points.
 func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr DeleteRequest) error {
-	u, err := NewURL(s.Addr, prefixDelete)
+	resp, err := s.do(ctx, prefixDelete, dr, false)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+// PreviewBucketRangePredicate send delete request over http to count the
+// series and points it would delete, without deleting them.
+func (s *DeleteService) PreviewBucketRangePredicate(ctx context.Context, dr DeleteRequest) (*influxdb.DeleteStats, error) {
+	resp, err := s.do(ctx, prefixDelete, dr, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var stats influxdb.DeleteStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CreateDeleteJob sends a delete request over http to be run in the background.
+func (s *DeleteService) CreateDeleteJob(ctx context.Context, dr DeleteRequest) (*influxdb.DeleteJob, error) {
+	resp, err := s.do(ctx, prefixDeleteJobs, dr, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return nil, err
+	}
+
+	var job influxdb.DeleteJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (s *DeleteService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	var job influxdb.DeleteJob
+	if err := s.get(ctx, prefixDeleteJobs+"/"+id.String(), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindDeleteJobs returns the delete jobs matching filter, oldest first.
+func (s *DeleteService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	params := url.Values{}
+	if filter.OrgID != nil {
+		params.Set("orgID", filter.OrgID.String())
+	}
+	if filter.BucketID != nil {
+		params.Set("bucketID", filter.BucketID.String())
+	}
+
+	var res struct {
+		Jobs []*influxdb.DeleteJob `json:"jobs"`
+	}
+	if err := s.get(ctx, prefixDeleteJobs, params, &res); err != nil {
+		return nil, err
+	}
+	return res.Jobs, nil
+}
+
+// CancelDeleteJob stops a pending delete job, or a running one that has not
+// started deleting data yet.
+func (s *DeleteService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	u, err := NewURL(s.Addr, prefixDeleteJobs+"/"+id.String())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("DELETE", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return CheckError(resp)
+}
+
+func (s *DeleteService) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	u, err := NewURL(s.Addr, path)
+	if err != nil {
+		return err
+	}
+	u.RawQuery = params.Encode()
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	SetToken(s.Token, req)
+
+	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
+	resp, err := hc.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := CheckError(resp); err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (s *DeleteService) do(ctx context.Context, path string, dr DeleteRequest, dryRun bool) (*http.Response, error) {
+	u, err := NewURL(s.Addr, path)
+	if err != nil {
+		return nil, err
+	}
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(dr); err != nil {
-		return err
+		return nil, err
 	}
 	req, err := http.NewRequest("POST", u.String(), buf)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
@@ -253,15 +617,11 @@ func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, dr Delet
 	} else if dr.Bucket != "" {
 		params.Set("bucket", dr.Bucket)
 	}
+	if dryRun {
+		params.Set("dryRun", "true")
+	}
 	req.URL.RawQuery = params.Encode()
 
 	hc := NewClient(u.Scheme, s.InsecureSkipVerify)
-
-	resp, err := hc.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	return CheckError(resp)
+	return hc.Do(req.WithContext(ctx))
 }
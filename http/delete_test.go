@@ -6,7 +6,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	pcontext "github.com/influxdata/influxdb/context"
@@ -231,7 +233,7 @@ func TestDelete(t *testing.T) {
 			},
 		},
 		{
-			name: "unsupported delete",
+			name: "or delete",
 			args: args{
 				queryParams: map[string][]string{
 					"org":    []string{"org1"},
@@ -277,10 +279,70 @@ func TestDelete(t *testing.T) {
 				},
 			},
 			wants: wants{
-				statusCode: http.StatusBadRequest,
+				statusCode: http.StatusNoContent,
+				body:       ``,
+			},
+		},
+		{
+			name: "dry run delete",
+			args: args{
+				queryParams: map[string][]string{
+					"org":    []string{"org1"},
+					"bucket": []string{"buck1"},
+					"dryRun": []string{"true"},
+				},
+				body: []byte(`{
+					"start":"2009-01-01T23:00:00Z",
+					"stop":"2019-11-10T01:00:00Z",
+					"predicate": "_measurement=cpu and host=~/^web-[0-9]+$/"
+				}`),
+				authorizer: &influxdb.Authorization{
+					UserID: user1ID,
+					Status: influxdb.Active,
+					Permissions: []influxdb.Permission{
+						{
+							Action: influxdb.WriteAction,
+							Resource: influxdb.Resource{
+								Type:  influxdb.BucketsResourceType,
+								ID:    influxtesting.IDPtr(influxdb.ID(2)),
+								OrgID: influxtesting.IDPtr(influxdb.ID(1)),
+							},
+						},
+					},
+				},
+			},
+			fields: fields{
+				DeleteService: &mock.DeleteService{
+					DeleteBucketRangePredicateF: func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+						return &influxdb.Error{Code: influxdb.EInternal, Msg: "unexpected delete"}
+					},
+					PreviewBucketRangePredicateF: func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) (*influxdb.DeleteStats, error) {
+						return &influxdb.DeleteStats{Series: 3, Points: 120}, nil
+					},
+				},
+				BucketService: &mock.BucketService{
+					FindBucketFn: func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:   influxdb.ID(2),
+							Name: "bucket1",
+						}, nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+						return &influxdb.Organization{
+							ID:   influxdb.ID(1),
+							Name: "org1",
+						}, nil
+					},
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
 				body: `{
-					"code": "invalid",
-					"message": "invalid request; error parsing request json: the logical operator OR is not supported yet at position 25"
+					"series": 3,
+					"points": 120
 				  }`,
 			},
 		},
@@ -380,3 +442,145 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteJobs(t *testing.T) {
+	created := time.Date(2019, 11, 10, 1, 0, 0, 0, time.UTC)
+	job := func() *influxdb.DeleteJob {
+		return &influxdb.DeleteJob{
+			ID:        influxdb.ID(3),
+			OrgID:     influxdb.ID(1),
+			BucketID:  influxdb.ID(2),
+			Start:     time.Date(2009, 1, 1, 23, 0, 0, 0, time.UTC),
+			Stop:      created,
+			Predicate: `tag1="v1"`,
+			Status:    influxdb.DeleteJobRunning,
+			Progress:  influxdb.DeleteProgress{FilesTotal: 4, FilesScanned: 1},
+			CreatedAt: created,
+		}
+	}
+	jobJSON := `{
+		"links": {"self": "/api/v2/delete/jobs/0000000000000003"},
+		"id": "0000000000000003",
+		"orgID": "0000000000000001",
+		"bucketID": "0000000000000002",
+		"start": "2009-01-01T23:00:00Z",
+		"stop": "2019-11-10T01:00:00Z",
+		"predicate": "tag1=\"v1\"",
+		"status": "running",
+		"progress": {"filesTotal": 4, "filesScanned": 1, "tombstonesWritten": 0, "filesRemoved": 0, "seriesScanned": 0, "seriesDeleted": 0},
+		"createdAt": "2019-11-10T01:00:00Z"
+	}`
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		svc        *mock.DeleteJobService
+		statusCode int
+		wantBody   string
+	}{
+		{
+			name:   "create job",
+			method: "POST",
+			path:   "/api/v2/delete/jobs?orgID=0000000000000001&bucketID=0000000000000002",
+			body:   `{"start":"2009-01-01T23:00:00Z","stop":"2019-11-10T01:00:00Z","predicate":"tag1=\"v1\""}`,
+			svc: &mock.DeleteJobService{
+				CreateDeleteJobF: func(ctx context.Context, j *influxdb.DeleteJob, pred influxdb.Predicate) error {
+					if pred == nil {
+						return &influxdb.Error{Code: influxdb.EInternal, Msg: "missing predicate"}
+					}
+					*j = *job()
+					return nil
+				},
+			},
+			statusCode: http.StatusAccepted,
+			wantBody:   jobJSON,
+		},
+		{
+			name:   "get job",
+			method: "GET",
+			path:   "/api/v2/delete/jobs/0000000000000003",
+			svc: &mock.DeleteJobService{
+				FindDeleteJobByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+					return job(), nil
+				},
+			},
+			statusCode: http.StatusOK,
+			wantBody:   jobJSON,
+		},
+		{
+			name:   "list jobs",
+			method: "GET",
+			path:   "/api/v2/delete/jobs?bucketID=0000000000000002",
+			svc: &mock.DeleteJobService{
+				FindDeleteJobsF: func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+					if filter.BucketID == nil || *filter.BucketID != influxdb.ID(2) {
+						return nil, &influxdb.Error{Code: influxdb.EInternal, Msg: "unexpected filter"}
+					}
+					return []*influxdb.DeleteJob{job()}, nil
+				},
+			},
+			statusCode: http.StatusOK,
+			wantBody:   `{"links": {"self": "/api/v2/delete/jobs"}, "jobs": [` + jobJSON + `]}`,
+		},
+		{
+			name:   "cancel job",
+			method: "DELETE",
+			path:   "/api/v2/delete/jobs/0000000000000003",
+			svc: &mock.DeleteJobService{
+				CancelDeleteJobF: func(ctx context.Context, id influxdb.ID) error {
+					return nil
+				},
+			},
+			statusCode: http.StatusNoContent,
+		},
+		{
+			name:   "cancel finished job",
+			method: "DELETE",
+			path:   "/api/v2/delete/jobs/0000000000000003",
+			svc: &mock.DeleteJobService{
+				CancelDeleteJobF: func(ctx context.Context, id influxdb.ID) error {
+					return &influxdb.Error{Code: influxdb.EConflict, Msg: "delete job has already finished"}
+				},
+			},
+			statusCode: http.StatusUnprocessableEntity,
+			wantBody:   `{"code": "conflict", "message": "delete job has already finished"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleteBackend := NewMockDeleteBackend(t)
+			deleteBackend.HTTPErrorHandler = kithttp.ErrorHandler(0)
+			deleteBackend.DeleteJobService = tt.svc
+			deleteBackend.OrganizationService = &mock.OrganizationService{
+				FindOrganizationF: func(ctx context.Context, f influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+					return &influxdb.Organization{ID: influxdb.ID(1), Name: "org1"}, nil
+				},
+			}
+			deleteBackend.BucketService = &mock.BucketService{
+				FindBucketFn: func(ctx context.Context, f influxdb.BucketFilter) (*influxdb.Bucket, error) {
+					return &influxdb.Bucket{ID: influxdb.ID(2), Name: "bucket1"}, nil
+				},
+			}
+			h := NewDeleteHandler(zaptest.NewLogger(t), deleteBackend)
+
+			r := httptest.NewRequest(tt.method, "http://any.tld"+tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("%q. status = %v, want %v: %s", tt.name, res.StatusCode, tt.statusCode, body)
+			}
+			if tt.wantBody != "" {
+				if eq, diff, err := jsonEqual(string(body), tt.wantBody); err != nil {
+					t.Errorf("%q. error unmarshaling json %v", tt.name, err)
+				} else if !eq {
+					t.Errorf("%q. body = ***%s***", tt.name, diff)
+				}
+			}
+		})
+	}
+}
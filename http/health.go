@@ -1,8 +1,12 @@
 package http
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+
+	"github.com/influxdata/influxdb/kit/check"
 )
 
 // HealthHandler returns the status of the process.
@@ -12,3 +16,43 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintln(w, msg)
 }
+
+// NewHealthHandler returns a handler reporting the status of the process along
+// with the result of each of checks. The process fails with a 503 status if
+// any of the checks fail.
+func NewHealthHandler(checks ...check.Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := struct {
+			Name    string          `json:"name"`
+			Message string          `json:"message"`
+			Status  check.Status    `json:"status"`
+			Checks  check.Responses `json:"checks"`
+		}{
+			Name:    "influxdb",
+			Message: "ready for queries and writes",
+			Status:  check.StatusPass,
+			Checks:  make(check.Responses, 0, len(checks)),
+		}
+
+		for _, c := range checks {
+			res := c.Check(r.Context())
+			if res.Status != check.StatusPass {
+				resp.Status = res.Status
+				resp.Message = "one or more checks failed"
+			}
+			resp.Checks = append(resp.Checks, res)
+		}
+		sort.Sort(resp.Checks)
+
+		status := http.StatusOK
+		if resp.Status == check.StatusFail {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			fmt.Fprintf(w, "Error encoding health status: %v\n", err)
+		}
+	})
+}
@@ -1,10 +1,14 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/influxdata/influxdb/kit/check"
 )
 
 func TestHealthHandler(t *testing.T) {
@@ -53,3 +57,54 @@ func TestHealthHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestNewHealthHandler(t *testing.T) {
+	pass := check.NamedFunc("storage", func(context.Context) check.Response {
+		return check.Pass()
+	})
+	fail := check.NamedFunc("storage", func(context.Context) check.Response {
+		return check.Error(errors.New("engine is closed"))
+	})
+
+	tests := []struct {
+		name       string
+		checks     []check.Checker
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "no checks",
+			statusCode: http.StatusOK,
+			body:       `{"name":"influxdb", "message":"ready for queries and writes", "status":"pass", "checks":[]}`,
+		},
+		{
+			name:       "passing check",
+			checks:     []check.Checker{pass},
+			statusCode: http.StatusOK,
+			body:       `{"name":"influxdb", "message":"ready for queries and writes", "status":"pass", "checks":[{"name":"storage","status":"pass"}]}`,
+		},
+		{
+			name:       "failing check",
+			checks:     []check.Checker{fail},
+			statusCode: http.StatusServiceUnavailable,
+			body:       `{"name":"influxdb", "message":"one or more checks failed", "status":"fail", "checks":[{"name":"storage","status":"fail","message":"engine is closed"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			NewHealthHandler(tt.checks...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			res := w.Result()
+			body, _ := ioutil.ReadAll(res.Body)
+
+			if res.StatusCode != tt.statusCode {
+				t.Errorf("got status %v, want %v", res.StatusCode, tt.statusCode)
+			}
+			if eq, diff, err := jsonEqual(string(body), tt.body); err != nil {
+				t.Errorf("error unmarshaling json %v", err)
+			} else if !eq {
+				t.Errorf("NewHealthHandler() = ***%s***", diff)
+			}
+		})
+	}
+}
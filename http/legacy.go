@@ -0,0 +1,576 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/http/metric"
+	"github.com/influxdata/influxdb/jsonweb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/influxql"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixLegacyQuery = "/query"
+	prefixLegacyWrite = "/write"
+	prefixLegacyPing  = "/ping"
+
+	// defaultLegacyChunkSize is the number of values per series in each
+	// response of a chunked query without a chunk_size.
+	defaultLegacyChunkSize = 10000
+)
+
+// isLegacyPath reports whether path is served by the InfluxDB 1.x
+// compatible LegacyHandler.
+func isLegacyPath(path string) bool {
+	switch path {
+	case prefixLegacyQuery, prefixLegacyWrite, prefixLegacyPing:
+		return true
+	default:
+		return false
+	}
+}
+
+// LegacyBackend is all services and associated parameters required to
+// construct the LegacyHandler.
+type LegacyBackend struct {
+	log *zap.Logger
+
+	QueryEventRecorder metric.EventRecorder
+
+	DBRPMappingService  influxdb.DBRPMappingService
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+	ProxyQueryService   query.ProxyQueryService
+	WriteBackend        *WriteBackend
+}
+
+// NewLegacyBackend returns a new instance of LegacyBackend.
+func NewLegacyBackend(log *zap.Logger, b *APIBackend) *LegacyBackend {
+	writeBackend := NewWriteBackend(log.With(zap.String("handler", "write")), b)
+	writeBackend.HTTPErrorHandler = legacyErrorHandler{}
+
+	return &LegacyBackend{
+		log: log,
+
+		QueryEventRecorder: b.QueryEventRecorder,
+
+		DBRPMappingService:  b.DBRPMappingService,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		ProxyQueryService: routingQueryService{
+			InfluxQLService: b.InfluxQLService,
+			DefaultService:  b.FluxService,
+		},
+		WriteBackend: writeBackend,
+	}
+}
+
+// LegacyHandler serves the InfluxDB 1.x compatible /query, /write and /ping
+// endpoints. Databases and retention policies are resolved to buckets
+// through the dbrp mappings of the organization of the request.
+type LegacyHandler struct {
+	*httprouter.Router
+	log *zap.Logger
+
+	EventRecorder metric.EventRecorder
+
+	DBRPMappingService  influxdb.DBRPMappingService
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+	ProxyQueryService   query.ProxyQueryService
+
+	writeHandler *WriteHandler
+}
+
+// NewLegacyHandler returns a new instance of LegacyHandler. The write options
+// configure the handler of the /write endpoint.
+func NewLegacyHandler(log *zap.Logger, b *LegacyBackend, opts ...WriteHandlerOption) *LegacyHandler {
+	h := &LegacyHandler{
+		Router: NewRouter(legacyErrorHandler{}),
+		log:    log,
+
+		EventRecorder: b.QueryEventRecorder,
+
+		DBRPMappingService:  b.DBRPMappingService,
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		ProxyQueryService:   b.ProxyQueryService,
+
+		writeHandler: NewWriteHandler(log, b.WriteBackend, opts...),
+	}
+
+	h.HandlerFunc("GET", prefixLegacyQuery, h.handleQuery)
+	h.HandlerFunc("POST", prefixLegacyQuery, h.handleQuery)
+	h.HandlerFunc("POST", prefixLegacyWrite, h.handleWrite)
+	h.HandlerFunc("GET", prefixLegacyPing, h.handlePing)
+	h.HandlerFunc("HEAD", prefixLegacyPing, h.handlePing)
+	return h
+}
+
+// ServeHTTP sets the version headers of InfluxDB 1.x on every response.
+func (h *LegacyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Influxdb-Build", "OSS")
+	w.Header().Set("X-Influxdb-Version", influxdb.GetBuildInfo().Version)
+	h.Router.ServeHTTP(w, r)
+}
+
+// handlePing is the HTTP handler for the GET /ping route.
+func (h *LegacyHandler) handlePing(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleQuery is the HTTP handler for the GET and POST /query routes.
+func (h *LegacyHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "LegacyHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var orgID influxdb.ID
+	sw := kithttp.NewStatusResponseWriter(w)
+	w = sw
+	defer func() {
+		h.EventRecorder.Record(ctx, metric.Event{
+			OrgID:         orgID,
+			Endpoint:      r.URL.Path,
+			ResponseBytes: sw.ResponseBytes(),
+			Status:        sw.Code(),
+		})
+	}()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	req, err := decodeLegacyQueryRequest(r)
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	orgID, err = requestOrganizationID(ctx, r, h.OrganizationService, a)
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	token, err := queryAuthorization(a, orgID)
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, token)
+
+	compiler := influxql.NewCompiler(&legacyDBRPResolver{
+		DBRPMappingService: h.DBRPMappingService,
+		BucketService:      h.BucketService,
+		Authorizer:         token,
+		OrganizationID:     orgID,
+		Action:             influxdb.ReadAction,
+	})
+	compiler.Cluster = influxdb.DefaultDBRPCluster
+	compiler.DB = req.DB
+	compiler.RP = req.RP
+	compiler.Query = req.Query
+
+	pr := &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  token,
+			OrganizationID: orgID,
+			Compiler:       compiler,
+			Source:         r.Header.Get("User-Agent"),
+		},
+		Dialect: req.Dialect,
+	}
+	req.Dialect.SetHeaders(w)
+
+	cw := iocounter.Writer{Writer: w}
+	if _, err := h.ProxyQueryService.Query(ctx, &cw, pr); err != nil {
+		if cw.Count() == 0 {
+			// Only record the error headers IFF nothing has been written to w.
+			legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+			return
+		}
+		_ = tracing.LogError(span, err)
+		h.log.Info("Error writing response to client",
+			zap.String("handler", "legacy"),
+			zap.Error(err),
+		)
+	}
+}
+
+// handleWrite is the HTTP handler for the POST /write route. The request is
+// served by the v2 write handler once its database and retention policy are
+// resolved to a bucket.
+func (h *LegacyHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "LegacyHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	qp := r.URL.Query()
+	db := qp.Get("db")
+	if db == "" {
+		legacyErrorHandler{}.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "database is required",
+		}, w)
+		return
+	}
+
+	precision, err := legacyPrecision(qp.Get("precision"))
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	orgID, err := requestOrganizationID(ctx, r, h.OrganizationService, a)
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	resolver := &legacyDBRPResolver{
+		DBRPMappingService: h.DBRPMappingService,
+		BucketService:      h.BucketService,
+		Authorizer:         a,
+		OrganizationID:     orgID,
+		Action:             influxdb.WriteAction,
+	}
+	m, err := resolver.FindBy(ctx, influxdb.DefaultDBRPCluster, db, qp.Get("rp"))
+	if err != nil {
+		legacyErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	params := url.Values{}
+	params.Set(OrgID, m.OrganizationID.String())
+	params.Set("bucket", m.BucketID.String())
+	params.Set("precision", precision)
+	r.URL.RawQuery = params.Encode()
+	h.writeHandler.handleWrite(w, r)
+}
+
+// requestOrganizationID returns the organization of a request. It is given
+// by the org or orgID parameters, or else by the token of the request.
+func requestOrganizationID(ctx context.Context, r *http.Request, svc influxdb.OrganizationService, a influxdb.Authorizer) (influxdb.ID, error) {
+	qp := r.URL.Query()
+	if qp.Get(Org) != "" || qp.Get(OrgID) != "" {
+		o, err := queryOrganization(ctx, r, svc)
+		if err != nil {
+			return 0, err
+		}
+		return o.ID, nil
+	}
+
+	if auth, ok := a.(*influxdb.Authorization); ok {
+		return auth.OrgID, nil
+	}
+	return 0, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "org or orgID is required when not authorized with a token",
+	}
+}
+
+// queryAuthorization returns the authorization that queries of the
+// organization run with for the authorizer of a request.
+func queryAuthorization(a influxdb.Authorizer, orgID influxdb.ID) (*influxdb.Authorization, error) {
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		return a, nil
+	case *influxdb.Session:
+		return a.EphemeralAuth(orgID), nil
+	case *jsonweb.Token:
+		return a.EphemeralAuth(orgID), nil
+	default:
+		return nil, influxdb.ErrAuthorizerNotSupported
+	}
+}
+
+type legacyQueryRequest struct {
+	Query   string
+	DB      string
+	RP      string
+	Dialect *influxql.Dialect
+}
+
+func decodeLegacyQueryRequest(r *http.Request) (*legacyQueryRequest, error) {
+	q := strings.TrimSpace(r.FormValue("q"))
+	if q == "" {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  `missing required parameter "q"`,
+		}
+	}
+
+	d := &influxql.Dialect{}
+	switch r.FormValue("epoch") {
+	case "":
+		d.TimeFormat = influxql.RFC3339Nano
+	case "h":
+		d.TimeFormat = influxql.Hour
+	case "m":
+		d.TimeFormat = influxql.Minute
+	case "s":
+		d.TimeFormat = influxql.Second
+	case "ms":
+		d.TimeFormat = influxql.Millisecond
+	case "u", "µ", "us":
+		d.TimeFormat = influxql.Microsecond
+	case "n", "ns":
+		d.TimeFormat = influxql.Nanosecond
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid epoch; valid epochs are h, m, s, ms, u, and ns",
+		}
+	}
+
+	if chunked, _ := strconv.ParseBool(r.FormValue("chunked")); chunked {
+		d.ChunkSize = defaultLegacyChunkSize
+		if v := r.FormValue("chunk_size"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "chunk_size must be a positive integer",
+				}
+			}
+			d.ChunkSize = n
+		}
+	}
+
+	switch accept := r.Header.Get("Accept"); {
+	case strings.Contains(accept, "application/csv"), strings.Contains(accept, "text/csv"):
+		d.Encoding = influxql.CSV
+	default:
+		if pretty, _ := strconv.ParseBool(r.FormValue("pretty")); pretty {
+			d.Encoding = influxql.JSONPretty
+		}
+	}
+
+	return &legacyQueryRequest{
+		Query:   q,
+		DB:      r.FormValue("db"),
+		RP:      r.FormValue("rp"),
+		Dialect: d,
+	}, nil
+}
+
+// legacyPrecision returns the write precision for the 1.x precision p.
+func legacyPrecision(p string) (string, error) {
+	switch p {
+	case "", "n", "ns":
+		return "ns", nil
+	case "u", "µ", "us":
+		return "us", nil
+	case "ms":
+		return "ms", nil
+	case "s":
+		return "s", nil
+	default:
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid precision; valid precision units are n, u, ms, and s",
+		}
+	}
+}
+
+// legacyDBRPResolver resolves the databases and retention policies of 1.x
+// requests to the buckets of a single organization. A database without a
+// dbrp mapping resolves to the bucket named "db/rp", where the retention
+// policy defaults to "autogen". The authorizer must be allowed the action on
+// the bucket.
+type legacyDBRPResolver struct {
+	DBRPMappingService influxdb.DBRPMappingService
+	BucketService      influxdb.BucketService
+	Authorizer         influxdb.Authorizer
+	OrganizationID     influxdb.ID
+	Action             influxdb.Action
+}
+
+var _ influxdb.DBRPMappingService = (*legacyDBRPResolver)(nil)
+
+// FindBy returns the mapping of db and rp. An empty rp resolves to the
+// default retention policy of db.
+func (r *legacyDBRPResolver) FindBy(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	m, err := r.findMapping(ctx, cluster, db, rp)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		if m, err = r.findBucket(ctx, cluster, db, rp); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := influxdb.NewPermissionAtID(m.BucketID, r.Action, influxdb.BucketsResourceType, m.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if !r.Authorizer.Allowed(*p) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("insufficient permissions to %s database %q", r.Action, db),
+		}
+	}
+	return m, nil
+}
+
+func (r *legacyDBRPResolver) findMapping(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	filter := influxdb.DBRPMappingFilter{
+		Cluster:  &cluster,
+		Database: &db,
+	}
+	if rp != "" {
+		filter.RetentionPolicy = &rp
+	} else {
+		defaultRP := true
+		filter.Default = &defaultRP
+	}
+
+	ms, _, err := r.DBRPMappingService.FindMany(ctx, filter)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return nil, err
+	}
+	for _, m := range ms {
+		if m.OrganizationID == r.OrganizationID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *legacyDBRPResolver) findBucket(ctx context.Context, cluster, db, rp string) (*influxdb.DBRPMapping, error) {
+	name := db + "/autogen"
+	if rp != "" {
+		name = db + "/" + rp
+	}
+
+	b, err := r.BucketService.FindBucket(ctx, influxdb.BucketFilter{
+		OrganizationID: &r.OrganizationID,
+		Name:           &name,
+	})
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil, &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("database not found: %s", db),
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &influxdb.DBRPMapping{
+		Cluster:         cluster,
+		Database:        db,
+		RetentionPolicy: rp,
+		Default:         rp == "",
+		OrganizationID:  b.OrgID,
+		BucketID:        b.ID,
+	}, nil
+}
+
+// Find returns the mapping of the database and retention policy of filter.
+func (r *legacyDBRPResolver) Find(ctx context.Context, filter influxdb.DBRPMappingFilter) (*influxdb.DBRPMapping, error) {
+	var cluster, db, rp string
+	if filter.Cluster != nil {
+		cluster = *filter.Cluster
+	}
+	if filter.Database != nil {
+		db = *filter.Database
+	}
+	if filter.RetentionPolicy != nil {
+		rp = *filter.RetentionPolicy
+	}
+	return r.FindBy(ctx, cluster, db, rp)
+}
+
+// FindMany returns the mapping of the database and retention policy of filter.
+func (r *legacyDBRPResolver) FindMany(ctx context.Context, filter influxdb.DBRPMappingFilter, opt ...influxdb.FindOptions) ([]*influxdb.DBRPMapping, int, error) {
+	m, err := r.Find(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return []*influxdb.DBRPMapping{m}, 1, nil
+}
+
+// Create is not supported by the resolver.
+func (r *legacyDBRPResolver) Create(ctx context.Context, m *influxdb.DBRPMapping) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "dbrp mappings cannot be created while resolving a request",
+	}
+}
+
+// Delete is not supported by the resolver.
+func (r *legacyDBRPResolver) Delete(ctx context.Context, cluster, db, rp string) error {
+	return &influxdb.Error{
+		Code: influxdb.EMethodNotAllowed,
+		Msg:  "dbrp mappings cannot be deleted while resolving a request",
+	}
+}
+
+// legacyErrorHandler writes errors in the format of InfluxDB 1.x.
+type legacyErrorHandler struct{}
+
+// HandleHTTPError writes err as {"error": "..."} with the status code of
+// its platform error code.
+func (legacyErrorHandler) HandleHTTPError(ctx context.Context, err error, w http.ResponseWriter) {
+	if err == nil {
+		return
+	}
+
+	msg := "An internal error has occurred"
+	if _, ok := err.(*influxdb.Error); ok {
+		msg = err.Error()
+	}
+
+	kithttp.SetRetryAfter(w, err)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Influxdb-Error", msg)
+	w.WriteHeader(kithttp.ErrorStatusCode(err))
+	b, _ := json.Marshal(struct {
+		Err string `json:"error"`
+	}{Err: msg})
+	_, _ = w.Write(b)
+}
+
+// legacyAuthMW authorizes 1.x and Prometheus API requests that carry their
+// token as the password of the u and p parameters or of basic authentication.
+func legacyAuthMW(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if isLegacyPath(r.URL.Path) || isPrometheusPath(r.URL.Path) {
+			var token string
+			if _, p, ok := r.BasicAuth(); ok {
+				token = p
+			} else if r.Header.Get("Authorization") == "" {
+				token = r.URL.Query().Get("p")
+			}
+			if token != "" {
+				r.Header.Set("Authorization", "Token "+token)
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
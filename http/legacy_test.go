@@ -0,0 +1,330 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http/metric"
+	httpmock "github.com/influxdata/influxdb/http/mock"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	influxtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	legacyTestOrg    = "043e0780ee2b1000"
+	legacyTestBucket = "04504b356e23b000"
+)
+
+func bucketPermission(action influxdb.Action, org, bucket string) *influxdb.Authorization {
+	oid := influxtesting.MustIDBase16(org)
+	bid := influxtesting.MustIDBase16(bucket)
+	return &influxdb.Authorization{
+		OrgID:  oid,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{
+			{
+				Action: action,
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: &oid,
+					ID:    &bid,
+				},
+			},
+		},
+	}
+}
+
+// newTestLegacyHandler returns a legacy handler with the dbrp mapping
+// telegraf/autogen of the test bucket, which also has the name "db/autogen".
+func newTestLegacyHandler(t *testing.T, auth influxdb.Authorizer, pw *mock.PointsWriter) http.Handler {
+	t.Helper()
+
+	orgID := influxtesting.MustIDBase16(legacyTestOrg)
+	bucketID := influxtesting.MustIDBase16(legacyTestBucket)
+
+	dbrps := newInMemKVSVC(t)
+	if err := dbrps.Create(context.Background(), &influxdb.DBRPMapping{
+		Cluster:         influxdb.DefaultDBRPCluster,
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  orgID,
+		BucketID:        bucketID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		if filter.ID != nil && *filter.ID == bucketID || filter.Name != nil && *filter.Name == "db/autogen" {
+			return &influxdb.Bucket{ID: bucketID, OrgID: orgID}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return &influxdb.Organization{ID: orgID}, nil
+	}
+
+	queries := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			if _, err := req.Request.Compiler.Compile(ctx); err != nil {
+				return flux.Statistics{}, err
+			}
+			results := flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm: "0",
+				Tbls: []*executetest.Table{{
+					KeyCols: []string{"_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1527152400000000000), "cpu", float64(2)},
+					},
+				}},
+			}})
+			_, err := req.Dialect.Encoder().Encode(w, results)
+			return flux.Statistics{}, err
+		},
+	}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		DBRPMappingService:  dbrps,
+		BucketService:       buckets,
+		OrganizationService: orgs,
+		InfluxQLService:     queries,
+		FluxService:         queries,
+		PointsWriter:        pw,
+		WriteEventRecorder:  &metric.NopEventRecorder{},
+		QueryEventRecorder:  &metric.NopEventRecorder{},
+	}
+	h := NewLegacyHandler(zaptest.NewLogger(t), NewLegacyBackend(zaptest.NewLogger(t), b))
+	return httpmock.NewAuthMiddlewareHandler(h, auth)
+}
+
+func TestLegacyHandler_Query(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   influxdb.Authorizer
+		params url.Values
+		accept string
+		code   int
+		body   string
+	}{
+		{
+			name:   "default retention policy of a mapped database",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}, "q": {"SELECT value FROM cpu"}, "epoch": {"s"}},
+			code:   http.StatusOK,
+			body:   `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[[1527152400,2]]}]}]}` + "\n",
+		},
+		{
+			name:   "unmapped database falls back to bucket db/rp",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"db"}, "q": {"SELECT value FROM cpu"}},
+			code:   http.StatusOK,
+			body:   `{"results":[{"statement_id":0,"series":[{"name":"cpu","columns":["time","value"],"values":[["2018-05-24T09:00:00Z",2]]}]}]}` + "\n",
+		},
+		{
+			name:   "csv",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}, "rp": {"autogen"}, "q": {"SELECT value FROM cpu"}},
+			accept: "application/csv",
+			code:   http.StatusOK,
+			body:   "name,tags,time,value\ncpu,,1527152400000000000,2\n",
+		},
+		{
+			name:   "unknown database",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"nodb"}, "q": {"SELECT value FROM cpu"}},
+			code:   http.StatusNotFound,
+			body:   `{"error":"database not found: nodb"}`,
+		},
+		{
+			name:   "insufficient permissions",
+			auth:   bucketPermission(influxdb.WriteAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}, "q": {"SELECT value FROM cpu"}},
+			code:   http.StatusForbidden,
+			body:   `{"error":"insufficient permissions to read database \"telegraf\""}`,
+		},
+		{
+			name:   "missing query",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}},
+			code:   http.StatusBadRequest,
+			body:   `{"error":"missing required parameter \"q\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestLegacyHandler(t, tt.auth, &mock.PointsWriter{})
+
+			r := httptest.NewRequest("GET", "http://localhost:9999/query?"+tt.params.Encode(), nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("unexpected status code: got %d want %d", got, want)
+			}
+			if got, want := w.Body.String(), tt.body; got != want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestLegacyHandler_Write(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   influxdb.Authorizer
+		params url.Values
+		code   int
+		body   string
+		points int
+	}{
+		{
+			name:   "mapped database and retention policy",
+			auth:   bucketPermission(influxdb.WriteAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}, "rp": {"autogen"}, "precision": {"u"}},
+			code:   http.StatusNoContent,
+			points: 1,
+		},
+		{
+			name:   "unsupported precision",
+			auth:   bucketPermission(influxdb.WriteAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}, "precision": {"h"}},
+			code:   http.StatusBadRequest,
+			body:   `{"error":"invalid precision; valid precision units are n, u, ms, and s"}`,
+		},
+		{
+			name:   "missing database",
+			auth:   bucketPermission(influxdb.WriteAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{},
+			code:   http.StatusBadRequest,
+			body:   `{"error":"database is required"}`,
+		},
+		{
+			name:   "insufficient permissions",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			params: url.Values{"db": {"telegraf"}},
+			code:   http.StatusForbidden,
+			body:   `{"error":"insufficient permissions to write database \"telegraf\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &mock.PointsWriter{}
+			h := newTestLegacyHandler(t, tt.auth, pw)
+
+			r := httptest.NewRequest("POST", "http://localhost:9999/write?"+tt.params.Encode(), strings.NewReader("cpu value=1 1527152400000000"))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("unexpected status code: got %d want %d", got, want)
+			}
+			if got, want := w.Body.String(), tt.body; got != want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+			}
+			if got, want := len(pw.Points), tt.points; got != want {
+				t.Errorf("unexpected number of points written: got %d want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestLegacyHandler_Ping(t *testing.T) {
+	h := newTestLegacyHandler(t, nil, &mock.PointsWriter{})
+
+	r := httptest.NewRequest("GET", "http://localhost:9999/ping", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code: %d", w.Code)
+	}
+	if w.Header().Get("X-Influxdb-Build") != "OSS" {
+		t.Fatalf("missing version headers: %v", w.Header())
+	}
+}
+
+func TestLegacyAuthMW(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		header http.Header
+		user   []string
+		want   string
+	}{
+		{
+			name:   "password parameter",
+			target: "/query?u=user&p=mytoken",
+			want:   "Token mytoken",
+		},
+		{
+			name:   "basic authentication",
+			target: "/write",
+			user:   []string{"user", "mytoken"},
+			want:   "Token mytoken",
+		},
+		{
+			name:   "token authentication",
+			target: "/query?p=other",
+			header: http.Header{"Authorization": {"Token mytoken"}},
+			want:   "Token mytoken",
+		},
+		{
+			name:   "prometheus paths",
+			target: "/api/v1/query",
+			user:   []string{"user", "mytoken"},
+			want:   "Token mytoken",
+		},
+		{
+			name:   "only legacy paths",
+			target: "/api/v2/query?p=mytoken",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := legacyAuthMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+			}))
+
+			r := httptest.NewRequest("GET", "http://localhost:9999"+tt.target, nil)
+			for k, v := range tt.header {
+				r.Header[k] = v
+			}
+			if tt.user != nil {
+				r.SetBasicAuth(tt.user[0], tt.user[1])
+			}
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Fatalf("unexpected authorization: got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -27,6 +27,7 @@ type OrgBackend struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	OrgLimitService                 influxdb.OrgLimitService
 }
 
 // NewOrgBackend is a datasource used by the org handler.
@@ -41,6 +42,7 @@ func NewOrgBackend(log *zap.Logger, b *APIBackend) *OrgBackend {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		OrgLimitService:                 b.OrgLimitService,
 	}
 }
 
@@ -56,6 +58,7 @@ type OrgHandler struct {
 	SecretService                   influxdb.SecretService
 	LabelService                    influxdb.LabelService
 	UserService                     influxdb.UserService
+	OrgLimitService                 influxdb.OrgLimitService
 }
 
 const (
@@ -71,6 +74,7 @@ const (
 	organizationsIDSecretsDeletePath = "/api/v2/orgs/:id/secrets/delete"
 	organizationsIDLabelsPath        = "/api/v2/orgs/:id/labels"
 	organizationsIDLabelsIDPath      = "/api/v2/orgs/:id/labels/:lid"
+	organizationsIDLimitsPath        = "/api/v2/orgs/:id/limits"
 )
 
 func checkOrganizationExists(orgHandler *OrgHandler) kithttp.Middleware {
@@ -107,6 +111,7 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 		SecretService:                   b.SecretService,
 		LabelService:                    b.LabelService,
 		UserService:                     b.UserService,
+		OrgLimitService:                 b.OrgLimitService,
 	}
 
 	h.HandlerFunc("POST", prefixOrganizations, h.handlePostOrg)
@@ -145,6 +150,9 @@ func NewOrgHandler(log *zap.Logger, b *OrgBackend) *OrgHandler {
 	// TODO(desa): need a way to specify which secrets to delete. this should work for now
 	h.HandlerFunc("POST", organizationsIDSecretsDeletePath, h.handleDeleteSecrets)
 
+	h.HandlerFunc("GET", organizationsIDLimitsPath, h.handleGetLimits)
+	h.HandlerFunc("PUT", organizationsIDLimitsPath, h.handlePutLimits)
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -198,6 +206,7 @@ func newOrgResponse(o influxdb.Organization) orgResponse {
 			"owners":     fmt.Sprintf("/api/v2/orgs/%s/owners", o.ID),
 			"secrets":    fmt.Sprintf("/api/v2/orgs/%s/secrets", o.ID),
 			"labels":     fmt.Sprintf("/api/v2/orgs/%s/labels", o.ID),
+			"limits":     fmt.Sprintf("/api/v2/orgs/%s/limits", o.ID),
 			"buckets":    fmt.Sprintf("/api/v2/buckets?org=%s", o.Name),
 			"tasks":      fmt.Sprintf("/api/v2/tasks?org=%s", o.Name),
 			"dashboards": fmt.Sprintf("/api/v2/dashboards?org=%s", o.Name),
@@ -393,6 +402,61 @@ func (h *OrgHandler) handleDeleteSecrets(w http.ResponseWriter, r *http.Request)
 	h.API.Respond(w, http.StatusNoContent, nil)
 }
 
+type orgLimitsResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.OrgLimits
+}
+
+func newOrgLimitsResponse(l influxdb.OrgLimits) *orgLimitsResponse {
+	return &orgLimitsResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/orgs/%s/limits", l.OrgID),
+			"org":  fmt.Sprintf("/api/v2/orgs/%s", l.OrgID),
+		},
+		OrgLimits: l,
+	}
+}
+
+// handleGetLimits is the HTTP handler for the GET /api/v2/orgs/:id/limits route.
+func (h *OrgHandler) handleGetLimits(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	l, err := h.OrgLimitService.FindOrgLimits(r.Context(), orgID)
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newOrgLimitsResponse(*l))
+}
+
+// handlePutLimits is the HTTP handler for the PUT /api/v2/orgs/:id/limits route.
+func (h *OrgHandler) handlePutLimits(w http.ResponseWriter, r *http.Request) {
+	orgID, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	var l influxdb.OrgLimits
+	if err := h.API.DecodeJSON(r.Body, &l); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+	l.OrgID = orgID
+
+	if err := h.OrgLimitService.PutOrgLimits(r.Context(), &l); err != nil {
+		h.API.Err(w, err)
+		return
+	}
+
+	h.API.Respond(w, http.StatusOK, newOrgLimitsResponse(l))
+}
+
 // hanldeGetOrganizationLog retrieves a organization log by the organizations ID.
 func (h *OrgHandler) handleGetOrgLog(w http.ResponseWriter, r *http.Request) {
 	orgID, err := decodeIDFromCtx(r.Context(), "id")
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"go.uber.org/zap"
 )
 
 // PlatformHandler is a collection of all the service handlers.
@@ -18,9 +19,13 @@ type PlatformHandler struct {
 func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler {
 	h := NewAuthenticationHandler(b.Logger, b.HTTPErrorHandler)
 	h.Handler = NewAPIHandler(b, opts...)
+	if b.AuditLogEnabled {
+		h.Handler = AuditMW(b.Logger.With(zap.String("service", "audit")), b.AuditService)(h.Handler)
+	}
 	h.AuthorizationService = b.AuthorizationService
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
+	h.ClientCertificates = b.ClientCertificates
 	h.UserService = b.UserService
 
 	h.RegisterNoAuthRoute("GET", "/api/v2")
@@ -29,11 +34,13 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
+	h.RegisterNoAuthRoute("GET", prefixLegacyPing)
+	h.RegisterNoAuthRoute("HEAD", prefixLegacyPing)
 
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
 
-	wrappedHandler := kithttp.SetCORS(h)
+	wrappedHandler := kithttp.SetCORS(legacyAuthMW(h))
 	wrappedHandler = kithttp.SkipOptions(wrappedHandler)
 
 	return &PlatformHandler{
@@ -51,8 +58,10 @@ func (h *PlatformHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Serve the chronograf assets for any basepath that does not start with addressable parts
-	// of the platform API.
-	if !strings.HasPrefix(r.URL.Path, "/v1") &&
+	// of the platform API, the InfluxDB 1.x compatible API or the Prometheus API.
+	if !isLegacyPath(r.URL.Path) &&
+		!isPrometheusPath(r.URL.Path) &&
+		!strings.HasPrefix(r.URL.Path, "/v1") &&
 		!strings.HasPrefix(r.URL.Path, "/api/v2") &&
 		!strings.HasPrefix(r.URL.Path, "/chronograf/") {
 		h.AssetHandler.ServeHTTP(w, r)
@@ -0,0 +1,557 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	pcontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/http/metric"
+	"github.com/influxdata/influxdb/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/promql"
+	"github.com/prometheus/common/model"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixPrometheus            = "/api/v1"
+	prefixPrometheusQuery       = prefixPrometheus + "/query"
+	prefixPrometheusQueryRange  = prefixPrometheus + "/query_range"
+	prefixPrometheusSeries      = prefixPrometheus + "/series"
+	prefixPrometheusLabels      = prefixPrometheus + "/labels"
+	prefixPrometheusLabelValues = prefixPrometheus + "/label/:name/values"
+
+	// prometheusMaxPoints is the maximum number of samples of a series in
+	// the result of a range query.
+	prometheusMaxPoints = 11000
+
+	// prometheusSeriesRange is the time range of the series, labels and
+	// label values without a start.
+	prometheusSeriesRange = time.Hour
+)
+
+// isPrometheusPath reports whether path is served by the PrometheusHandler.
+func isPrometheusPath(path string) bool {
+	return strings.HasPrefix(path, prefixPrometheus+"/")
+}
+
+// PrometheusBackend is all services and associated parameters required to
+// construct the PrometheusHandler.
+type PrometheusBackend struct {
+	log *zap.Logger
+
+	QueryEventRecorder metric.EventRecorder
+
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+	ProxyQueryService   query.ProxyQueryService
+}
+
+// NewPrometheusBackend returns a new instance of PrometheusBackend.
+func NewPrometheusBackend(log *zap.Logger, b *APIBackend) *PrometheusBackend {
+	return &PrometheusBackend{
+		log: log,
+
+		QueryEventRecorder: b.QueryEventRecorder,
+
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		ProxyQueryService:   b.FluxService,
+	}
+}
+
+// PrometheusHandler serves the query endpoints of the Prometheus HTTP API,
+// so that Prometheus clients can query the metrics of a bucket with PromQL.
+//
+// The bucket is given by the bucketID parameter, or by the bucket parameter
+// within the organization of the org or orgID parameters or of the token.
+// Without a bucket, the token must only be allowed to read a single bucket.
+type PrometheusHandler struct {
+	*httprouter.Router
+	log *zap.Logger
+
+	EventRecorder metric.EventRecorder
+
+	BucketService       influxdb.BucketService
+	OrganizationService influxdb.OrganizationService
+	ProxyQueryService   query.ProxyQueryService
+}
+
+// NewPrometheusHandler returns a new instance of PrometheusHandler.
+func NewPrometheusHandler(log *zap.Logger, b *PrometheusBackend) *PrometheusHandler {
+	h := &PrometheusHandler{
+		Router: NewRouter(prometheusErrorHandler{}),
+		log:    log,
+
+		EventRecorder: b.QueryEventRecorder,
+
+		BucketService:       b.BucketService,
+		OrganizationService: b.OrganizationService,
+		ProxyQueryService:   b.ProxyQueryService,
+	}
+
+	h.HandlerFunc("GET", prefixPrometheusQuery, h.handleQuery)
+	h.HandlerFunc("POST", prefixPrometheusQuery, h.handleQuery)
+	h.HandlerFunc("GET", prefixPrometheusQueryRange, h.handleQueryRange)
+	h.HandlerFunc("POST", prefixPrometheusQueryRange, h.handleQueryRange)
+	h.HandlerFunc("GET", prefixPrometheusSeries, h.handleSeries)
+	h.HandlerFunc("POST", prefixPrometheusSeries, h.handleSeries)
+	h.HandlerFunc("GET", prefixPrometheusLabels, h.handleLabels)
+	h.HandlerFunc("POST", prefixPrometheusLabels, h.handleLabels)
+	h.HandlerFunc("GET", prefixPrometheusLabelValues, h.handleLabelValues)
+	return h
+}
+
+// handleQuery is the HTTP handler for the GET and POST /api/v1/query routes.
+func (h *PrometheusHandler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	h.query(w, r, decodePrometheusInstantQuery)
+}
+
+// handleQueryRange is the HTTP handler for the GET and POST
+// /api/v1/query_range routes.
+func (h *PrometheusHandler) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	h.query(w, r, decodePrometheusRangeQuery)
+}
+
+// handleSeries is the HTTP handler for the GET and POST /api/v1/series routes.
+func (h *PrometheusHandler) handleSeries(w http.ResponseWriter, r *http.Request) {
+	h.query(w, r, func(r *http.Request) (*promql.Compiler, *promql.Dialect, error) {
+		return decodePrometheusSeriesQuery(r, &promql.Dialect{Format: promql.Series})
+	})
+}
+
+// handleLabels is the HTTP handler for the GET and POST /api/v1/labels routes.
+func (h *PrometheusHandler) handleLabels(w http.ResponseWriter, r *http.Request) {
+	h.query(w, r, func(r *http.Request) (*promql.Compiler, *promql.Dialect, error) {
+		return decodePrometheusSeriesQuery(r, &promql.Dialect{Format: promql.Labels})
+	})
+}
+
+// handleLabelValues is the HTTP handler for the GET
+// /api/v1/label/:name/values route.
+func (h *PrometheusHandler) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	h.query(w, r, func(r *http.Request) (*promql.Compiler, *promql.Dialect, error) {
+		return decodePrometheusSeriesQuery(r, &promql.Dialect{
+			Format: promql.LabelValues,
+			Label:  httprouter.ParamsFromContext(r.Context()).ByName("name"),
+		})
+	})
+}
+
+// query runs the query that decode returns for the request against the
+// bucket of the request.
+func (h *PrometheusHandler) query(w http.ResponseWriter, r *http.Request, decode func(*http.Request) (*promql.Compiler, *promql.Dialect, error)) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "PrometheusHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var orgID influxdb.ID
+	sw := kithttp.NewStatusResponseWriter(w)
+	w = sw
+	defer func() {
+		h.EventRecorder.Record(ctx, metric.Event{
+			OrgID:         orgID,
+			Endpoint:      r.URL.Path,
+			ResponseBytes: sw.ResponseBytes(),
+			Status:        sw.Code(),
+		})
+	}()
+
+	a, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		prometheusErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	compiler, dialect, err := decode(r)
+	if err != nil {
+		prometheusErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.findBucket(ctx, r, a)
+	if err != nil {
+		prometheusErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+	orgID = b.OrgID
+	compiler.BucketID = b.ID.String()
+
+	token, err := queryAuthorization(a, orgID)
+	if err != nil {
+		prometheusErrorHandler{}.HandleHTTPError(ctx, err, w)
+		return
+	}
+	ctx = pcontext.SetAuthorizer(ctx, token)
+
+	pr := &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  token,
+			OrganizationID: orgID,
+			Compiler:       compiler,
+			Source:         r.Header.Get("User-Agent"),
+		},
+		Dialect: dialect,
+	}
+	dialect.SetHeaders(w)
+
+	cw := iocounter.Writer{Writer: w}
+	if _, err := h.ProxyQueryService.Query(ctx, &cw, pr); err != nil {
+		if cw.Count() == 0 {
+			// Only record the error headers IFF nothing has been written to w.
+			if _, ok := err.(*influxdb.Error); !ok {
+				err = &influxdb.Error{
+					Code: influxdb.EUnprocessableEntity,
+					Err:  err,
+				}
+			}
+			prometheusErrorHandler{}.HandleHTTPError(ctx, err, w)
+			return
+		}
+		_ = tracing.LogError(span, err)
+		h.log.Info("Error writing response to client",
+			zap.String("handler", "prometheus"),
+			zap.Error(err),
+		)
+	}
+}
+
+// findBucket returns the bucket of a request, which the authorizer must be
+// allowed to read.
+func (h *PrometheusHandler) findBucket(ctx context.Context, r *http.Request, a influxdb.Authorizer) (*influxdb.Bucket, error) {
+	qp := r.URL.Query()
+	var filter influxdb.BucketFilter
+	switch {
+	case qp.Get(BucketID) != "":
+		id, err := influxdb.IDFromString(qp.Get(BucketID))
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "invalid bucketID",
+				Err:  err,
+			}
+		}
+		filter.ID = id
+	case qp.Get(Bucket) != "":
+		orgID, err := requestOrganizationID(ctx, r, h.OrganizationService, a)
+		if err != nil {
+			return nil, err
+		}
+		name := qp.Get(Bucket)
+		filter.Name = &name
+		filter.OrganizationID = &orgID
+	default:
+		id := readableBucket(a)
+		if id == nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "bucket or bucketID is required when the token can read more than one bucket",
+			}
+		}
+		filter.ID = id
+	}
+
+	b, err := h.BucketService.FindBucket(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := influxdb.NewPermissionAtID(b.ID, influxdb.ReadAction, influxdb.BucketsResourceType, b.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Allowed(*p) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EForbidden,
+			Msg:  fmt.Sprintf("insufficient permissions to read bucket %q", b.Name),
+		}
+	}
+	return b, nil
+}
+
+// readableBucket returns the only bucket that the authorizer is allowed to
+// read, or nil if it is not a token that reads a single bucket.
+func readableBucket(a influxdb.Authorizer) *influxdb.ID {
+	auth, ok := a.(*influxdb.Authorization)
+	if !ok {
+		return nil
+	}
+
+	var id *influxdb.ID
+	for _, p := range auth.Permissions {
+		if p.Action != influxdb.ReadAction || p.Resource.Type != influxdb.BucketsResourceType {
+			continue
+		}
+		if p.Resource.ID == nil || id != nil && *id != *p.Resource.ID {
+			return nil
+		}
+		id = p.Resource.ID
+	}
+	return id
+}
+
+func decodePrometheusInstantQuery(r *http.Request) (*promql.Compiler, *promql.Dialect, error) {
+	now := time.Now()
+	q, err := decodePrometheusExpr(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	ts, err := decodePrometheusTime(r, "time", now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c := &promql.Compiler{
+		Query: q,
+		End:   ts,
+		Now:   &now,
+	}
+	if err := validatePrometheusQuery(c); err != nil {
+		return nil, nil, err
+	}
+
+	// An instant query for a range vector returns all of its samples.
+	d := &promql.Dialect{Format: promql.Vector}
+	if s, ok := mustParsePromQL(q).(*promql.Selector); ok && s.Range > 0 {
+		d.Format = promql.Matrix
+	}
+	return c, d, nil
+}
+
+func decodePrometheusRangeQuery(r *http.Request) (*promql.Compiler, *promql.Dialect, error) {
+	now := time.Now()
+	q, err := decodePrometheusExpr(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, name := range []string{"start", "end", "step"} {
+		if r.FormValue(name) == "" {
+			return nil, nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("missing required parameter %q", name),
+			}
+		}
+	}
+	start, err := decodePrometheusTime(r, "start", now)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := decodePrometheusTime(r, "end", now)
+	if err != nil {
+		return nil, nil, err
+	}
+	step, err := decodePrometheusDuration(r, "step")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case end.Before(start):
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "end timestamp must not be before start time",
+		}
+	case step <= 0:
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "zero or negative query resolution step widths are not accepted, try a positive integer",
+		}
+	case end.Sub(start)/step > prometheusMaxPoints:
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("exceeded maximum resolution of %d points per timeseries, try decreasing the query resolution (?step=XX)", prometheusMaxPoints),
+		}
+	}
+
+	c := &promql.Compiler{
+		Query: q,
+		Start: start,
+		End:   end,
+		Step:  step,
+		Now:   &now,
+	}
+	if err := validatePrometheusQuery(c); err != nil {
+		return nil, nil, err
+	}
+	return c, &promql.Dialect{Format: promql.Matrix}, nil
+}
+
+// decodePrometheusSeriesQuery decodes the series selectors and time range
+// of the series, labels and label values endpoints. The selectors are only
+// required for the series.
+func decodePrometheusSeriesQuery(r *http.Request, d *promql.Dialect) (*promql.Compiler, *promql.Dialect, error) {
+	now := time.Now()
+	if err := r.ParseForm(); err != nil {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+	match := r.Form["match[]"]
+	if len(match) == 0 && d.Format == promql.Series {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "no match[] parameter provided",
+		}
+	}
+
+	end, err := decodePrometheusTime(r, "end", now)
+	if err != nil {
+		return nil, nil, err
+	}
+	start, err := decodePrometheusTime(r, "start", end.Add(-prometheusSeriesRange))
+	if err != nil {
+		return nil, nil, err
+	}
+	if end.Before(start) {
+		return nil, nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "end timestamp must not be before start time",
+		}
+	}
+
+	c := &promql.Compiler{
+		Match: match,
+		Start: start,
+		End:   end,
+		Now:   &now,
+	}
+	if err := validatePrometheusQuery(c); err != nil {
+		return nil, nil, err
+	}
+	return c, d, nil
+}
+
+func decodePrometheusExpr(r *http.Request) (string, error) {
+	q := strings.TrimSpace(r.FormValue("query"))
+	if q == "" {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  `missing required parameter "query"`,
+		}
+	}
+	return q, nil
+}
+
+// validatePrometheusQuery transpiles the query of the compiler so that
+// invalid queries are rejected before they are run.
+func validatePrometheusQuery(c *promql.Compiler) error {
+	config := promql.Config{
+		Start: c.Start,
+		End:   c.End,
+		Step:  c.Step,
+	}
+	var err error
+	if c.Query != "" {
+		_, err = promql.Transpile(c.Query, config)
+	} else {
+		_, err = promql.TranspileSeries(c.Match, config)
+	}
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  err.Error(),
+		}
+	}
+	return nil
+}
+
+// mustParsePromQL parses a query that has already been validated.
+func mustParsePromQL(q string) interface{} {
+	parsed, err := promql.ParsePromQL(q)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// decodePrometheusTime decodes the parameter as a unix timestamp in seconds
+// or as an RFC3339 time. A missing parameter returns the default.
+func decodePrometheusTime(r *http.Request, name string, def time.Time) (time.Time, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return def, nil
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		s, ms := math.Modf(f)
+		return time.Unix(int64(s), int64(math.Round(ms*1000))*int64(time.Millisecond)).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid parameter %q: cannot parse %q to a valid timestamp", name, v),
+	}
+}
+
+// decodePrometheusDuration decodes the parameter as a number of seconds or
+// as a Prometheus duration.
+func decodePrometheusDuration(r *http.Request, name string) (time.Duration, error) {
+	v := r.FormValue(name)
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	if d, err := model.ParseDuration(v); err == nil {
+		return time.Duration(d), nil
+	}
+	return 0, &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("invalid parameter %q: cannot parse %q to a valid duration", name, v),
+	}
+}
+
+// prometheusErrorHandler writes errors in the format of the Prometheus HTTP
+// API.
+type prometheusErrorHandler struct{}
+
+// HandleHTTPError writes err as {"status": "error", "errorType": "...",
+// "error": "..."} with the status code of its platform error code.
+func (prometheusErrorHandler) HandleHTTPError(ctx context.Context, err error, w http.ResponseWriter) {
+	if err == nil {
+		return
+	}
+
+	msg := "An internal error has occurred"
+	if _, ok := err.(*influxdb.Error); ok {
+		msg = err.Error()
+	}
+
+	var errorType string
+	switch influxdb.ErrorCode(err) {
+	case influxdb.EInternal:
+		errorType = "internal"
+	case influxdb.ENotFound:
+		errorType = "not_found"
+	case influxdb.EUnprocessableEntity:
+		errorType = "execution"
+	case influxdb.EUnavailable, influxdb.ETooManyRequests:
+		errorType = "unavailable"
+	default:
+		errorType = "bad_data"
+	}
+
+	kithttp.SetRetryAfter(w, err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(kithttp.ErrorStatusCode(err))
+	b, _ := json.Marshal(struct {
+		Status    string `json:"status"`
+		ErrorType string `json:"errorType"`
+		Err       string `json:"error"`
+	}{
+		Status:    "error",
+		ErrorType: errorType,
+		Err:       msg,
+	})
+	_, _ = w.Write(b)
+}
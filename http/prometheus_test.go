@@ -0,0 +1,216 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/http/metric"
+	httpmock "github.com/influxdata/influxdb/http/mock"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
+	querymock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/query/promql"
+	influxtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+// newTestPrometheusHandler returns a Prometheus handler for the legacy test
+// bucket, which also has the name "metrics". Every query returns the series
+// up{job="a"} with a single sample.
+func newTestPrometheusHandler(t *testing.T, auth influxdb.Authorizer) http.Handler {
+	t.Helper()
+
+	orgID := influxtesting.MustIDBase16(legacyTestOrg)
+	bucketID := influxtesting.MustIDBase16(legacyTestBucket)
+
+	buckets := mock.NewBucketService()
+	buckets.FindBucketFn = func(ctx context.Context, filter influxdb.BucketFilter) (*influxdb.Bucket, error) {
+		if filter.ID != nil && *filter.ID == bucketID || filter.Name != nil && *filter.Name == "metrics" {
+			return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: "metrics"}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+	orgs := mock.NewOrganizationService()
+	orgs.FindOrganizationF = func(ctx context.Context, filter influxdb.OrganizationFilter) (*influxdb.Organization, error) {
+		return &influxdb.Organization{ID: orgID}, nil
+	}
+
+	queries := &querymock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			if got, want := req.Request.Compiler.(*promql.Compiler).BucketID, legacyTestBucket; got != want {
+				t.Errorf("unexpected bucket: got %s want %s", got, want)
+			}
+			if _, err := req.Request.Compiler.Compile(ctx); err != nil {
+				return flux.Statistics{}, err
+			}
+			results := flux.NewSliceResultIterator([]flux.Result{&executetest.Result{
+				Nm: "_result",
+				Tbls: []*executetest.Table{{
+					KeyCols: []string{"_field", "_measurement", "job"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "job", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1527152400000000000), 1.5, "counter", "up", "a"},
+					},
+				}},
+			}})
+			_, err := req.Dialect.Encoder().Encode(w, results)
+			return flux.Statistics{}, err
+		},
+	}
+
+	b := &APIBackend{
+		HTTPErrorHandler:    DefaultErrorHandler,
+		Logger:              zaptest.NewLogger(t),
+		BucketService:       buckets,
+		OrganizationService: orgs,
+		FluxService:         queries,
+		QueryEventRecorder:  &metric.NopEventRecorder{},
+	}
+	h := NewPrometheusHandler(zaptest.NewLogger(t), NewPrometheusBackend(zaptest.NewLogger(t), b))
+	return httpmock.NewAuthMiddlewareHandler(h, auth)
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		auth   influxdb.Authorizer
+		path   string
+		params url.Values
+		code   int
+		body   string
+	}{
+		{
+			name:   "instant query",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query",
+			params: url.Values{"query": {`up{job="a"}`}, "time": {"1527152400"}},
+			code:   http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"a"},"value":[1527152400,"1.5"]}]}}` + "\n",
+		},
+		{
+			name:   "instant query for a range vector",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query",
+			params: url.Values{"query": {`up[5m]`}, "time": {"2018-05-24T09:00:00Z"}},
+			code:   http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1527152400,"1.5"]]}]}}` + "\n",
+		},
+		{
+			name:   "range query of a bucket by name",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query_range",
+			params: url.Values{"bucket": {"metrics"}, "query": {`sum(up)`}, "start": {"1527152000"}, "end": {"1527152400"}, "step": {"15s"}},
+			code:   http.StatusOK,
+			body:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1527152400,"1.5"]]}]}}` + "\n",
+		},
+		{
+			name:   "series",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/series",
+			params: url.Values{"match[]": {`up`}},
+			code:   http.StatusOK,
+			body:   `{"status":"success","data":[{"__name__":"up","job":"a"}]}` + "\n",
+		},
+		{
+			name: "labels",
+			auth: bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path: "/api/v1/labels",
+			code: http.StatusOK,
+			body: `{"status":"success","data":["__name__","job"]}` + "\n",
+		},
+		{
+			name: "label values",
+			auth: bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path: "/api/v1/label/__name__/values",
+			code: http.StatusOK,
+			body: `{"status":"success","data":["up"]}` + "\n",
+		},
+		{
+			name:   "missing query",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query",
+			params: url.Values{},
+			code:   http.StatusBadRequest,
+			body:   `{"status":"error","errorType":"bad_data","error":"missing required parameter \"query\""}`,
+		},
+		{
+			name:   "missing series selector",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/series",
+			params: url.Values{},
+			code:   http.StatusBadRequest,
+			body:   `{"status":"error","errorType":"bad_data","error":"no match[] parameter provided"}`,
+		},
+		{
+			name:   "negative step",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up`}, "start": {"1527152000"}, "end": {"1527152400"}, "step": {"-1"}},
+			code:   http.StatusBadRequest,
+			body:   `{"status":"error","errorType":"bad_data","error":"zero or negative query resolution step widths are not accepted, try a positive integer"}`,
+		},
+		{
+			name:   "too many points",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query_range",
+			params: url.Values{"query": {`up`}, "start": {"0"}, "end": {"1527152400"}, "step": {"1m"}},
+			code:   http.StatusBadRequest,
+			body:   `{"status":"error","errorType":"bad_data","error":"exceeded maximum resolution of 11000 points per timeseries, try decreasing the query resolution (?step=XX)"}`,
+		},
+		{
+			name:   "unknown bucket",
+			auth:   bucketPermission(influxdb.ReadAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query",
+			params: url.Values{"bucket": {"other"}, "query": {`up`}},
+			code:   http.StatusNotFound,
+			body:   `{"status":"error","errorType":"not_found","error":"bucket not found"}`,
+		},
+		{
+			name:   "insufficient permissions",
+			auth:   bucketPermission(influxdb.WriteAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query",
+			params: url.Values{"bucketID": {legacyTestBucket}, "query": {`up`}},
+			code:   http.StatusForbidden,
+			body:   `{"status":"error","errorType":"bad_data","error":"insufficient permissions to read bucket \"metrics\""}`,
+		},
+		{
+			name:   "token without a single bucket",
+			auth:   bucketPermission(influxdb.WriteAction, legacyTestOrg, legacyTestBucket),
+			path:   "/api/v1/query",
+			params: url.Values{"query": {`up`}},
+			code:   http.StatusBadRequest,
+			body:   `{"status":"error","errorType":"bad_data","error":"bucket or bucketID is required when the token can read more than one bucket"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestPrometheusHandler(t, tt.auth)
+
+			r := httptest.NewRequest("GET", "http://localhost:9999"+tt.path+"?"+tt.params.Encode(), nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if got, want := w.Code, tt.code; got != want {
+				t.Errorf("unexpected status code: got %d want %d", got, want)
+			}
+			if got, want := w.Body.String(), tt.body; got != want {
+				t.Errorf("unexpected body:\ngot  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
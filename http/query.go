@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 	"unicode/utf8"
 
@@ -21,9 +22,12 @@ import (
 	"github.com/influxdata/flux/parser"
 	"github.com/influxdata/flux/repl"
 	"github.com/influxdata/influxdb"
-	"github.com/influxdata/influxdb/jsonweb"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/arrow"
+	"github.com/influxdata/influxdb/query/explain"
 	transpiler "github.com/influxdata/influxdb/query/influxql"
+	fluxjson "github.com/influxdata/influxdb/query/json"
+	"github.com/influxdata/influxdb/query/lineprotocol"
 	"github.com/influxdata/influxql"
 )
 
@@ -38,6 +42,11 @@ type QueryRequest struct {
 	AST     *ast.Package `json:"ast,omitempty"`
 	Dialect QueryDialect `json:"dialect"`
 
+	// Params are the parameters of a Flux or InfluxQL query. Flux
+	// queries read them from the params record, and InfluxQL queries bind
+	// them to parameters like $host.
+	Params query.Params `json:"params,omitempty"`
+
 	// InfluxQL fields
 	Bucket string `json:"bucket,omitempty"`
 
@@ -63,11 +72,60 @@ type QueryRequest struct {
 
 // QueryDialect is the formatting options for the query response.
 type QueryDialect struct {
+	// Type is the format of the response: csv, json, arrow or lineprotocol.
+	// It defaults to the format of the Accept header of the request and
+	// to csv. The other options apply to csv, except Orient.
+	Type           string   `json:"type,omitempty"`
 	Header         *bool    `json:"header"`
 	Delimiter      string   `json:"delimiter"`
 	CommentPrefix  string   `json:"commentPrefix"`
 	DateTimeFormat string   `json:"dateTimeFormat"`
 	Annotations    []string `json:"annotations"`
+	// Orient is the layout of json responses: records or tables.
+	Orient string `json:"orient,omitempty"`
+}
+
+// dialectMediaTypes are the media types of the dialect types. The first
+// media type of a dialect type is the one of its responses.
+var dialectMediaTypes = []struct {
+	mediaType, dialectType string
+}{
+	{"text/csv", csv.DialectType},
+	{"application/csv", csv.DialectType},
+	{"application/json", fluxjson.DialectType},
+	{"application/vnd.apache.arrow.stream", arrow.DialectType},
+	{lineprotocol.ContentType, lineprotocol.DialectType},
+}
+
+// dialectTypeFromAccept returns the dialect type of the first media type
+// of an Accept header that has one, or an empty string.
+func dialectTypeFromAccept(accept string) string {
+	for _, v := range strings.Split(accept, ",") {
+		mt, _, err := mime.ParseMediaType(v)
+		if err != nil {
+			continue
+		}
+		for _, m := range dialectMediaTypes {
+			if m.mediaType == mt {
+				return m.dialectType
+			}
+		}
+	}
+	return ""
+}
+
+// dialectMediaType returns the media type of the responses of a dialect
+// type, which defaults to csv.
+func dialectMediaType(dialectType string) string {
+	if dialectType == "" {
+		dialectType = csv.DialectType
+	}
+	for _, m := range dialectMediaTypes {
+		if m.dialectType == dialectType {
+			return m.mediaType
+		}
+	}
+	return ""
 }
 
 // WithDefaults adds default values to the request.
@@ -85,6 +143,9 @@ func (r QueryRequest) WithDefaults() QueryRequest {
 		header := true
 		r.Dialect.Header = &header
 	}
+	if r.Dialect.Type == fluxjson.DialectType && r.Dialect.Orient == "" {
+		r.Dialect.Orient = string(fluxjson.OrientRecords)
+	}
 	return r
 }
 
@@ -103,6 +164,21 @@ func (r QueryRequest) Validate() error {
 		}
 	}
 
+	if r.Spec != nil && len(r.Params) > 0 {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "request body cannot specify both a spec and params",
+		}
+	}
+
+	if _, err := r.Params.File(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid params",
+			Err:  err,
+		}
+	}
+
 	if r.Type != "flux" && r.Type != "influxql" {
 		return fmt.Errorf(`unknown query type: %s`, r.Type)
 	}
@@ -111,6 +187,16 @@ func (r QueryRequest) Validate() error {
 		return fmt.Errorf("bucket parameter is required for influxql queries")
 	}
 
+	switch r.Dialect.Type {
+	case "", csv.DialectType, arrow.DialectType, lineprotocol.DialectType:
+	case fluxjson.DialectType:
+		if o := fluxjson.Orient(r.Dialect.Orient); o != "" && !o.Valid() {
+			return fmt.Errorf(`unknown dialect orient: %s`, r.Dialect.Orient)
+		}
+	default:
+		return fmt.Errorf(`unknown dialect type: %s`, r.Dialect.Type)
+	}
+
 	if len(r.Dialect.CommentPrefix) > 1 {
 		return fmt.Errorf("invalid dialect comment prefix: must be length 0 or 1")
 	}
@@ -144,6 +230,8 @@ func (r QueryRequest) Validate() error {
 // QueryAnalysis is a structured response of errors.
 type QueryAnalysis struct {
 	Errors []queryParseError `json:"errors"`
+	// Plan is the plan of the query, when it was explained or profiled.
+	Plan *explain.Explanation `json:"plan,omitempty"`
 }
 
 type queryParseError struct {
@@ -252,6 +340,10 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	if err := r.Validate(); err != nil {
 		return nil, err
 	}
+	extern, err := r.extern()
+	if err != nil {
+		return nil, err
+	}
 	// Query is preferred over AST
 	var compiler flux.Compiler
 	if r.Query != "" {
@@ -262,13 +354,14 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 				Now:    &n,
 				Query:  r.Query,
 				Bucket: r.Bucket,
+				Params: r.Params,
 			}
 		case "flux":
 			fallthrough
 		default:
 			compiler = lang.FluxCompiler{
 				Now:    now(),
-				Extern: r.Extern,
+				Extern: extern,
 				Query:  r.Query,
 			}
 		}
@@ -277,8 +370,8 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 			AST: r.AST,
 			Now: now(),
 		}
-		if r.Extern != nil {
-			c.PrependFile(r.Extern)
+		if extern != nil {
+			c.PrependFile(extern)
 		}
 		compiler = c
 	} else if r.Spec != nil {
@@ -287,37 +380,31 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 		}
 	}
 
-	delimiter, _ := utf8.DecodeRuneInString(r.Dialect.Delimiter)
-
-	noHeader := false
-	if r.Dialect.Header != nil {
-		noHeader = !*r.Dialect.Header
-	}
-
 	var dialect flux.Dialect
-	if r.PreferNoContent {
+	switch {
+	case r.PreferNoContent:
 		dialect = &query.NoContentDialect{}
-	} else {
-		if r.Type == "influxql" {
-			// Use default transpiler dialect
-			dialect = &transpiler.Dialect{}
-		} else {
-			// TODO(nathanielc): Use commentPrefix and dateTimeFormat
-			// once they are supported.
-			encConfig := csv.ResultEncoderConfig{
-				NoHeader:    noHeader,
-				Delimiter:   delimiter,
-				Annotations: r.Dialect.Annotations,
-			}
-			if r.PreferNoContentWithError {
-				dialect = &query.NoContentWithErrorDialect{
-					ResultEncoderConfig: encConfig,
-				}
-			} else {
-				dialect = &csv.Dialect{
-					ResultEncoderConfig: encConfig,
-				}
-			}
+	case r.Type == "influxql":
+		// Use default transpiler dialect
+		dialect = &transpiler.Dialect{}
+	case r.PreferNoContentWithError:
+		// Runtime errors are always encoded in CSV.
+		dialect = &query.NoContentWithErrorDialect{
+			ResultEncoderConfig: csvConfig(r.Dialect),
+		}
+	case r.Dialect.Type == fluxjson.DialectType:
+		dialect = &fluxjson.Dialect{
+			ResultEncoderConfig: fluxjson.ResultEncoderConfig{
+				Orient: fluxjson.Orient(r.Dialect.Orient),
+			},
+		}
+	case r.Dialect.Type == arrow.DialectType:
+		dialect = &arrow.Dialect{}
+	case r.Dialect.Type == lineprotocol.DialectType:
+		dialect = &lineprotocol.Dialect{}
+	default:
+		dialect = &csv.Dialect{
+			ResultEncoderConfig: csvConfig(r.Dialect),
 		}
 	}
 
@@ -330,6 +417,42 @@ func (r QueryRequest) proxyRequest(now func() time.Time) (*query.ProxyRequest, e
 	}, nil
 }
 
+// extern returns the external declarations of a Flux query, followed by
+// the assignment of its params.
+func (r QueryRequest) extern() (*ast.File, error) {
+	if len(r.Params) == 0 {
+		return r.Extern, nil
+	}
+	params, err := r.Params.File()
+	if err != nil {
+		return nil, err
+	}
+	if r.Extern == nil {
+		return params, nil
+	}
+	extern := r.Extern.Copy().(*ast.File)
+	extern.Body = append(extern.Body, params.Body...)
+	return extern, nil
+}
+
+// csvConfig returns the configuration of the CSV encoder of a dialect.
+func csvConfig(d QueryDialect) csv.ResultEncoderConfig {
+	delimiter, _ := utf8.DecodeRuneInString(d.Delimiter)
+
+	noHeader := false
+	if d.Header != nil {
+		noHeader = !*d.Header
+	}
+
+	// TODO(nathanielc): Use commentPrefix and dateTimeFormat
+	// once they are supported.
+	return csv.ResultEncoderConfig{
+		NoHeader:    noHeader,
+		Delimiter:   delimiter,
+		Annotations: d.Annotations,
+	}
+}
+
 // QueryRequestFromProxyRequest converts a query.ProxyRequest into a QueryRequest.
 // The ProxyRequest must contain supported compilers and dialects otherwise an error occurs.
 func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error) {
@@ -356,6 +479,13 @@ func QueryRequestFromProxyRequest(req *query.ProxyRequest) (*QueryRequest, error
 		qr.Dialect.CommentPrefix = "#"
 		qr.Dialect.DateTimeFormat = "RFC3339"
 		qr.Dialect.Annotations = d.ResultEncoderConfig.Annotations
+	case *fluxjson.Dialect:
+		qr.Dialect.Type = fluxjson.DialectType
+		qr.Dialect.Orient = string(d.Orient)
+	case *arrow.Dialect:
+		qr.Dialect.Type = arrow.DialectType
+	case *lineprotocol.Dialect:
+		qr.Dialect.Type = lineprotocol.DialectType
 	case *query.NoContentDialect:
 		qr.PreferNoContent = true
 	case *query.NoContentWithErrorDialect:
@@ -393,6 +523,10 @@ func decodeQueryRequest(ctx context.Context, r *http.Request, svc influxdb.Organ
 		}
 	}
 
+	if req.Dialect.Type == "" {
+		req.Dialect.Type = dialectTypeFromAccept(r.Header.Get("Accept"))
+	}
+
 	switch hv := r.Header.Get(query.PreferHeaderKey); hv {
 	case query.PreferNoContentHeaderValue:
 		req.PreferNoContent = true
@@ -430,19 +564,9 @@ func decodeProxyQueryRequest(ctx context.Context, r *http.Request, auth influxdb
 	if err != nil {
 		return nil, n, err
 	}
-
-	var token *influxdb.Authorization
-	switch a := auth.(type) {
-	case *influxdb.Authorization:
-		token = a
-	case *influxdb.Session:
-		token = a.EphemeralAuth(req.Org.ID)
-	case *jsonweb.Token:
-		token = a.EphemeralAuth(req.Org.ID)
-	default:
-		return pr, n, influxdb.ErrAuthorizerNotSupported
+	pr.Request.Authorization, err = queryAuthorization(auth, req.Org.ID)
+	if err != nil {
+		return pr, n, err
 	}
-
-	pr.Request.Authorization = token
 	return pr, n, nil
 }
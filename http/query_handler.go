@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"sort"
@@ -28,7 +29,11 @@ import (
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/arrow"
+	"github.com/influxdata/influxdb/query/explain"
 	"github.com/influxdata/influxdb/query/influxql"
+	fluxjson "github.com/influxdata/influxdb/query/json"
+	"github.com/influxdata/influxdb/query/lineprotocol"
 	"github.com/pkg/errors"
 	prom "github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -242,13 +247,28 @@ func (h *FluxHandler) postFluxAST(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// postQueryAnalyze parses a query and returns any query errors.
+// postQueryAnalyze parses a query and returns any query errors. In the
+// explain and profile modes, a query without errors is also planned, or
+// run, and its plan is returned.
 func (h *FluxHandler) postQueryAnalyze(w http.ResponseWriter, r *http.Request) {
 	span, r := tracing.ExtractFromHTTPRequest(r, "FluxHandler")
 	defer span.Finish()
 
 	ctx := r.Context()
 
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case "", "validate":
+	case "explain", "profile":
+		h.explainQuery(w, r, mode == "profile")
+		return
+	default:
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("unknown analyze mode %q", mode),
+		}, w)
+		return
+	}
+
 	var req QueryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.HandleHTTPError(ctx, &influxdb.Error{
@@ -270,6 +290,82 @@ func (h *FluxHandler) postQueryAnalyze(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// explainQuery analyzes a query and, if it has no errors, returns its plan.
+// Profiled queries run, without returning their results, and their plan
+// has how long each operation took.
+func (h *FluxHandler) explainQuery(w http.ResponseWriter, r *http.Request, profile bool) {
+	const op = "http/explainQuery"
+	ctx := r.Context()
+
+	auth, err := pcontext.GetAuthorizer(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "authorization is invalid or missing in the query request",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	req, _, err := decodeQueryRequest(ctx, r, h.OrganizationService)
+	if err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request body",
+			Op:   op,
+			Err:  err,
+		}, w)
+		return
+	}
+
+	a, err := req.Analyze()
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if len(a.Errors) == 0 {
+		pr, err := req.ProxyRequest()
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		if pr.Request.Authorization, err = queryAuthorization(auth, req.Org.ID); err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		pr.Request.Source = r.Header.Get("User-Agent")
+		pr.Request.Compiler = explain.Compiler{
+			Compiler: pr.Request.Compiler,
+			Profile:  profile,
+		}
+		// The results of profiled queries are read, but not returned.
+		pr.Dialect = &query.NoContentDialect{}
+
+		ctx := pcontext.SetAuthorizer(ctx, pr.Request.Authorization)
+		stats, err := h.ProxyQueryService.Query(ctx, ioutil.Discard, pr)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		e, ok := explain.FromStatistics(stats)
+		if !ok {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "the query service did not return the plan of the query",
+				Op:   op,
+			}, w)
+			return
+		}
+		a.Plan = e
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, a); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
 // fluxParams contain flux funciton parameters as defined by the semantic graph
 type fluxParams map[string]string
 
@@ -432,6 +528,10 @@ type FluxQueryService struct {
 	Token              string
 	Name               string
 	InsecureSkipVerify bool
+
+	// Dialect is the format results are transferred in. It defaults to
+	// annotated CSV. JSON results must be in the tables orientation.
+	Dialect flux.Dialect
 }
 
 // Query runs a flux query against a influx server and decodes the result
@@ -449,7 +549,13 @@ func (s *FluxQueryService) Query(ctx context.Context, r *query.Request) (flux.Re
 
 	preq := &query.ProxyRequest{
 		Request: *r,
-		Dialect: csv.DefaultDialect(),
+		Dialect: s.Dialect,
+	}
+	if preq.Dialect == nil {
+		preq.Dialect = csv.DefaultDialect()
+	}
+	if d, ok := preq.Dialect.(*fluxjson.Dialect); ok && d.Orient != fluxjson.OrientTables {
+		return nil, tracing.LogError(span, fluxjson.ErrRecordsNotDecodable)
 	}
 	qreq, err := QueryRequestFromProxyRequest(preq)
 	if err != nil {
@@ -468,7 +574,7 @@ func (s *FluxQueryService) Query(ctx context.Context, r *query.Request) (flux.Re
 	SetToken(s.Token, hreq)
 
 	hreq.Header.Set("Content-Type", "application/json")
-	hreq.Header.Set("Accept", "text/csv")
+	hreq.Header.Set("Accept", dialectMediaType(qreq.Dialect.Type))
 	if r.Source != "" {
 		hreq.Header.Add("User-Agent", r.Source)
 	} else if s.Name != "" {
@@ -492,7 +598,7 @@ func (s *FluxQueryService) Query(ctx context.Context, r *query.Request) (flux.Re
 		return nil, tracing.LogError(span, err)
 	}
 
-	decoder := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	decoder := resultDecoder(resp.Header.Get("Content-Type"), qreq.Dialect.Type)
 	itr, err := decoder.Decode(resp.Body)
 	if err != nil {
 		return nil, tracing.LogError(span, err)
@@ -501,6 +607,27 @@ func (s *FluxQueryService) Query(ctx context.Context, r *query.Request) (flux.Re
 	return itr, nil
 }
 
+// resultDecoder returns the decoder of the results of a response with the
+// given content type. Responses without a known content type are decoded
+// as the requested dialect type.
+func resultDecoder(contentType, dialectType string) flux.MultiResultDecoder {
+	if mt, _, err := mime.ParseMediaType(contentType); err == nil {
+		if t := dialectTypeFromAccept(mt); t != "" {
+			dialectType = t
+		}
+	}
+	switch dialectType {
+	case fluxjson.DialectType:
+		return fluxjson.NewMultiResultDecoder()
+	case arrow.DialectType:
+		return arrow.NewMultiResultDecoder()
+	case lineprotocol.DialectType:
+		return lineprotocol.NewMultiResultDecoder()
+	default:
+		return csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	}
+}
+
 func (s FluxQueryService) Check(ctx context.Context) check.Response {
 	return QueryHealthCheck(s.Addr, s.InsecureSkipVerify)
 }
@@ -18,6 +18,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb"
 	platform "github.com/influxdata/influxdb"
@@ -28,6 +30,10 @@ import (
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	influxmock "github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/arrow"
+	"github.com/influxdata/influxdb/query/explain"
+	fluxjson "github.com/influxdata/influxdb/query/json"
+	"github.com/influxdata/influxdb/query/lineprotocol"
 	"github.com/influxdata/influxdb/query/mock"
 	"go.uber.org/zap/zaptest"
 )
@@ -232,6 +238,84 @@ func TestFluxQueryService_Query(t *testing.T) {
 	}
 }
 
+func TestFluxQueryService_Query_dialects(t *testing.T) {
+	newResults := func() flux.ResultIterator {
+		r := executetest.NewResult([]*executetest.Table{{
+			KeyCols: []string{"_measurement", "_field"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "_time", Type: flux.TTime},
+			},
+			Data: [][]interface{}{
+				{"cpu", "usage", 1.5, execute.Time(1)},
+				{"cpu", "usage", 2.5, execute.Time(2)},
+			},
+		}})
+		r.Nm = "_result"
+		return flux.NewSliceResultIterator([]flux.Result{r})
+	}
+	tests := []struct {
+		name    string
+		dialect flux.Dialect
+		accept  string
+		wantErr bool
+	}{
+		{
+			name:    "json",
+			dialect: &fluxjson.Dialect{ResultEncoderConfig: fluxjson.ResultEncoderConfig{Orient: fluxjson.OrientTables}},
+			accept:  "application/json",
+		},
+		{
+			name:    "json records",
+			dialect: fluxjson.DefaultDialect(),
+			wantErr: true,
+		},
+		{
+			name:    "arrow",
+			dialect: &arrow.Dialect{},
+			accept:  "application/vnd.apache.arrow.stream",
+		},
+		{
+			name:    "line protocol",
+			dialect: &lineprotocol.Dialect{},
+			accept:  "text/vnd.influx.line-protocol",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept"); got != tt.accept {
+					t.Errorf("unexpected Accept header %q, want %q", got, tt.accept)
+				}
+				tt.dialect.(HTTPDialect).SetHeaders(w)
+				if _, err := tt.dialect.Encoder().Encode(w, newResults()); err != nil {
+					t.Errorf("unexpected encode error: %v", err)
+				}
+			}))
+			defer ts.Close()
+
+			s := &FluxQueryService{
+				Addr:    ts.URL,
+				Dialect: tt.dialect,
+			}
+			res, err := s.Query(context.Background(), &query.Request{
+				Compiler: lang.FluxCompiler{Query: "from()"},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FluxQueryService.Query() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if err := executetest.EqualResultIterators(newResults(), res); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
 func TestFluxHandler_postFluxAST(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -449,6 +533,103 @@ func TestFluxHandler_PostQuery_Errors(t *testing.T) {
 	})
 }
 
+func TestFluxHandler_postQueryAnalyze_explain(t *testing.T) {
+	orgSVC := newInMemKVSVC(t)
+	org := influxdb.Organization{Name: t.Name()}
+	if err := orgSVC.CreateOrganization(context.Background(), &org); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &explain.Explanation{
+		Logical:    []explain.Node{{ID: "influxDBFrom0", Kind: "influxDBFrom", Predecessors: []string{}}},
+		Physical:   []explain.Node{{ID: "ReadRange0", Kind: "ReadRangePhysKind", Predecessors: []string{}, Operations: []string{"influxDBFrom0"}, Storage: true}},
+		PushedDown: []string{"influxDBFrom0"},
+	}
+	var got *query.ProxyRequest
+	b := &FluxBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		log:                 zaptest.NewLogger(t),
+		QueryEventRecorder:  noopEventRecorder{},
+		OrganizationService: orgSVC,
+		ProxyQueryService: &mock.ProxyQueryService{
+			QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+				got = req
+				return flux.Statistics{
+					Metadata: flux.Metadata{explain.MetadataKey: []interface{}{plan}},
+				}, nil
+			},
+		},
+	}
+	h := NewFluxHandler(zaptest.NewLogger(t), b)
+
+	analyze := func(t *testing.T, mode, q string) *httptest.ResponseRecorder {
+		t.Helper()
+		got = nil
+		body := fmt.Sprintf(`{"type": "flux", "query": %q}`, q)
+		req := httptest.NewRequest("POST", "/api/v2/query/analyze?orgID="+org.ID.String()+"&mode="+mode, strings.NewReader(body))
+		req = req.WithContext(icontext.SetAuthorizer(req.Context(), &influxdb.Authorization{OrgID: org.ID}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, mode := range []string{"explain", "profile"} {
+		t.Run(mode, func(t *testing.T) {
+			w := analyze(t, mode, `from(bucket: "b") |> range(start: -1h)`)
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+			}
+			var a QueryAnalysis
+			if err := json.Unmarshal(w.Body.Bytes(), &a); err != nil {
+				t.Fatal(err)
+			}
+			if len(a.Errors) != 0 || !cmp.Equal(plan, a.Plan) {
+				t.Errorf("unexpected analysis -want/+got plan:\n%s\nerrors: %v", cmp.Diff(plan, a.Plan), a.Errors)
+			}
+
+			c, ok := got.Request.Compiler.(explain.Compiler)
+			if !ok {
+				t.Fatalf("expected an explain compiler, got %T", got.Request.Compiler)
+			}
+			if fc, ok := c.Compiler.(lang.FluxCompiler); !ok || fc.Query != `from(bucket: "b") |> range(start: -1h)` {
+				t.Errorf("unexpected compiler of the query %#v", c.Compiler)
+			}
+			if c.Profile != (mode == "profile") {
+				t.Errorf("unexpected profile %v", c.Profile)
+			}
+			if _, ok := got.Dialect.(*query.NoContentDialect); !ok {
+				t.Errorf("expected no content dialect, got %T", got.Dialect)
+			}
+			if got.Request.OrganizationID != org.ID || got.Request.Authorization == nil {
+				t.Errorf("unexpected organization %v or authorization %v", got.Request.OrganizationID, got.Request.Authorization)
+			}
+		})
+	}
+
+	t.Run("parse errors", func(t *testing.T) {
+		w := analyze(t, "explain", `from(bucket: "b") |> range(start: -1h`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+		}
+		var a QueryAnalysis
+		if err := json.Unmarshal(w.Body.Bytes(), &a); err != nil {
+			t.Fatal(err)
+		}
+		if len(a.Errors) == 0 || a.Plan != nil {
+			t.Errorf("expected errors without a plan, got %+v", a)
+		}
+		if got != nil {
+			t.Error("expected the query not to be planned")
+		}
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		if w := analyze(t, "fast", `from(bucket: "b")`); w.Code != http.StatusBadRequest {
+			t.Errorf("expected bad request status, got %d", w.Code)
+		}
+	})
+}
+
 func TestFluxService_Query_gzip(t *testing.T) {
 	// orgService is just to mock out orgs by returning
 	// the same org every time.
@@ -20,7 +20,11 @@ import (
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/mock"
 	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/arrow"
 	_ "github.com/influxdata/influxdb/query/builtin"
+	transpiler "github.com/influxdata/influxdb/query/influxql"
+	fluxjson "github.com/influxdata/influxdb/query/json"
+	"github.com/influxdata/influxdb/query/lineprotocol"
 )
 
 var cmpOptions = cmp.Options{
@@ -28,6 +32,7 @@ var cmpOptions = cmp.Options{
 	cmpopts.IgnoreUnexported(query.ProxyRequest{}),
 	cmpopts.IgnoreUnexported(query.Request{}),
 	cmpopts.IgnoreUnexported(flux.Spec{}),
+	cmpopts.IgnoreUnexported(transpiler.Compiler{}),
 	cmpopts.EquateEmpty(),
 }
 
@@ -174,6 +179,44 @@ func TestQueryRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown dialect type",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:      "xml",
+					Delimiter: ",",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown json orient",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:      "json",
+					Delimiter: ",",
+					Orient:    "columns",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid json query",
+			fields: fields{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:           "json",
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Orient:         "tables",
+				},
+			},
+		},
 		{
 			name: "valid query",
 			fields: fields{
@@ -212,6 +255,8 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 		Query   string
 		Type    string
 		Dialect QueryDialect
+		Params  query.Params
+		Bucket  string
 		org     *platform.Organization
 	}
 	tests := []struct {
@@ -361,6 +406,177 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "json dialect",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:           "json",
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Orient:         "tables",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &fluxjson.Dialect{
+					ResultEncoderConfig: fluxjson.ResultEncoderConfig{
+						Orient: fluxjson.OrientTables,
+					},
+				},
+			},
+		},
+		{
+			name: "arrow dialect",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:           "arrow",
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &arrow.Dialect{},
+			},
+		},
+		{
+			name: "line protocol dialect",
+			fields: fields{
+				Query: "howdy",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:           "lineprotocol",
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now:   time.Unix(1, 1),
+						Query: `howdy`,
+					},
+				},
+				Dialect: &lineprotocol.Dialect{},
+			},
+		},
+		{
+			name: "valid query with extern and params",
+			fields: fields{
+				Extern: &ast.File{
+					Body: []ast.Statement{
+						&ast.OptionStatement{
+							Assignment: &ast.VariableAssignment{
+								ID:   &ast.Identifier{Name: "x"},
+								Init: &ast.IntegerLiteral{Value: 0},
+							},
+						},
+					},
+				},
+				Query:  "howdy",
+				Type:   "flux",
+				Params: query.Params{"host": `a"`, "n": int64(1)},
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: lang.FluxCompiler{
+						Now: time.Unix(1, 1),
+						Extern: &ast.File{
+							Body: []ast.Statement{
+								&ast.OptionStatement{
+									Assignment: &ast.VariableAssignment{
+										ID:   &ast.Identifier{Name: "x"},
+										Init: &ast.IntegerLiteral{Value: 0},
+									},
+								},
+								&ast.VariableAssignment{
+									ID: &ast.Identifier{Name: "params"},
+									Init: &ast.ObjectExpression{
+										Properties: []*ast.Property{
+											{Key: &ast.Identifier{Name: "host"}, Value: &ast.StringLiteral{Value: `a"`}},
+											{Key: &ast.Identifier{Name: "n"}, Value: &ast.IntegerLiteral{Value: 1}},
+										},
+									},
+								},
+							},
+						},
+						Query: `howdy`,
+					},
+				},
+				Dialect: &csv.Dialect{
+					ResultEncoderConfig: csv.ResultEncoderConfig{
+						NoHeader:  false,
+						Delimiter: ',',
+					},
+				},
+			},
+		},
+		{
+			name: "valid influxql query with params",
+			fields: fields{
+				Query:  "SELECT value FROM cpu WHERE host = $host",
+				Type:   "influxql",
+				Bucket: "b",
+				Params: query.Params{"host": "a"},
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org: &platform.Organization{},
+			},
+			now: func() time.Time { return time.Unix(1, 1) },
+			want: &query.ProxyRequest{
+				Request: query.Request{
+					Compiler: &transpiler.Compiler{
+						Now:    func() *time.Time { n := time.Unix(1, 1); return &n }(),
+						Query:  "SELECT value FROM cpu WHERE host = $host",
+						Bucket: "b",
+						Params: query.Params{"host": "a"},
+					},
+				},
+				Dialect: &transpiler.Dialect{},
+			},
+		},
+		{
+			name: "params that are not identifiers",
+			fields: fields{
+				Query:  "howdy",
+				Type:   "flux",
+				Params: query.Params{"not-an-identifier": "a"},
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+				},
+				org: &platform.Organization{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -371,6 +587,8 @@ func TestQueryRequest_proxyRequest(t *testing.T) {
 				Query:   tt.fields.Query,
 				Type:    tt.fields.Type,
 				Dialect: tt.fields.Dialect,
+				Params:  tt.fields.Params,
+				Bucket:  tt.fields.Bucket,
 				Org:     tt.fields.org,
 			}
 			got, err := r.proxyRequest(tt.now)
@@ -451,6 +669,93 @@ func Test_decodeQueryRequest(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid query request with params",
+			args: args{
+				r: httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "from()", "params": {"host": "a", "n": 1, "f": 1.0}}`)),
+				svc: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{
+							ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+						}, nil
+					},
+				},
+			},
+			want: &QueryRequest{
+				Query:  "from()",
+				Type:   "flux",
+				Params: query.Params{"host": "a", "n": int64(1), "f": 1.0},
+				Dialect: QueryDialect{
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Header:         func(x bool) *bool { return &x }(true),
+				},
+				Org: &platform.Organization{
+					ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+				},
+			},
+		},
+		{
+			name: "valid query request with dialect type from accept",
+			args: args{
+				r: func() *http.Request {
+					r := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "from()"}`))
+					r.Header.Set("Accept", "application/xml, application/vnd.apache.arrow.stream, text/csv")
+					return r
+				}(),
+				svc: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{
+							ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+						}, nil
+					},
+				},
+			},
+			want: &QueryRequest{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:           "arrow",
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Header:         func(x bool) *bool { return &x }(true),
+				},
+				Org: &platform.Organization{
+					ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+				},
+			},
+		},
+		{
+			name: "dialect type overrides accept",
+			args: args{
+				r: func() *http.Request {
+					r := httptest.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "from()", "dialect": {"type": "json"}}`))
+					r.Header.Set("Accept", "text/csv")
+					return r
+				}(),
+				svc: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, filter platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{
+							ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+						}, nil
+					},
+				},
+			},
+			want: &QueryRequest{
+				Query: "from()",
+				Type:  "flux",
+				Dialect: QueryDialect{
+					Type:           "json",
+					Delimiter:      ",",
+					DateTimeFormat: "RFC3339",
+					Header:         func(x bool) *bool { return &x }(true),
+					Orient:         "records",
+				},
+				Org: &platform.Organization{
+					ID: func() platform.ID { s, _ := platform.IDFromString("deadbeefdeadbeef"); return *s }(),
+				},
+			},
+		},
 		{
 			name: "error decoding json",
 			args: args{
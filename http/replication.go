@@ -0,0 +1,259 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixReplication       = "/api/v2/replication"
+	replicationChangesPath  = prefixReplication + "/changes"
+	replicationSnapshotPath = prefixReplication + "/snapshot"
+	replicationPromotePath  = prefixReplication + "/promote"
+
+	// maxReplicationWait caps how long a request for changes may wait.
+	maxReplicationWait = 5 * time.Minute
+)
+
+// ReplicationBackend is all services and associated parameters required to
+// construct the ReplicationHandler.
+type ReplicationBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ReplicationService influxdb.ReplicationService
+}
+
+// NewReplicationBackend returns a new instance of ReplicationBackend.
+func NewReplicationBackend(log *zap.Logger, b *APIBackend) *ReplicationBackend {
+	return &ReplicationBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ReplicationService: b.ReplicationService,
+	}
+}
+
+// ReplicationHandler serves the metadata change feed to followers.
+type ReplicationHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	ReplicationService influxdb.ReplicationService
+}
+
+// NewReplicationHandler returns a new instance of ReplicationHandler.
+func NewReplicationHandler(log *zap.Logger, b *ReplicationBackend) *ReplicationHandler {
+	h := &ReplicationHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ReplicationService: b.ReplicationService,
+	}
+
+	h.HandlerFunc("GET", prefixReplication, h.handleGetStatus)
+	h.HandlerFunc("GET", replicationChangesPath, h.handleGetChanges)
+	h.HandlerFunc("GET", replicationSnapshotPath, h.handleGetSnapshot)
+	h.HandlerFunc("POST", replicationPromotePath, h.handlePromote)
+	return h
+}
+
+// handleGetStatus is the HTTP handler for the GET /api/v2/replication route.
+func (h *ReplicationHandler) handleGetStatus(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ReplicationHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	st, err := h.ReplicationService.ReplicationStatus(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, st); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+type replicationChangesResponse struct {
+	Changes []*influxdb.KVChange `json:"changes"`
+}
+
+// handleGetChanges is the HTTP handler for the GET /api/v2/replication/changes route.
+func (h *ReplicationHandler) handleGetChanges(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ReplicationHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	filter, err := decodeKVChangeFilter(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	changes, err := h.ReplicationService.FindKVChanges(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Debug("Replication changes retrieved", zap.Int("count", len(changes)))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, replicationChangesResponse{Changes: changes}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func decodeKVChangeFilter(r *http.Request) (influxdb.KVChangeFilter, error) {
+	var f influxdb.KVChangeFilter
+	qp := r.URL.Query()
+
+	if v := qp.Get("after"); v != "" {
+		after, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "after must be a sequence number",
+				Err:  err,
+			}
+		}
+		f.After = after
+	}
+
+	if v := qp.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "limit must be a non-negative integer",
+			}
+		}
+		f.Limit = limit
+	}
+
+	if v := qp.Get("wait"); v != "" {
+		wait, err := time.ParseDuration(v)
+		if err != nil || wait < 0 {
+			return f, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "wait must be a non-negative duration",
+			}
+		}
+		if wait > maxReplicationWait {
+			wait = maxReplicationWait
+		}
+		f.Wait = wait
+	}
+
+	return f, nil
+}
+
+// handleGetSnapshot is the HTTP handler for the GET /api/v2/replication/snapshot route.
+func (h *ReplicationHandler) handleGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ReplicationHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := h.ReplicationService.KVSnapshot(ctx, w); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+}
+
+// handlePromote is the HTTP handler for the POST /api/v2/replication/promote route.
+func (h *ReplicationHandler) handlePromote(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "ReplicationHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	if err := h.ReplicationService.PromoteReplica(ctx); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Info("Metadata replica promoted")
+
+	st, err := h.ReplicationService.ReplicationStatus(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, st); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// ReplicationService is the client implementation of influxdb.ReplicationService.
+type ReplicationService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationStatus returns the replication state of the remote instance.
+func (s *ReplicationService) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	var st influxdb.ReplicationStatus
+	err := s.Client.
+		Get(prefixReplication).
+		DecodeJSON(&st).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// FindKVChanges returns the changes committed on the remote instance after filter.After.
+func (s *ReplicationService) FindKVChanges(ctx context.Context, filter influxdb.KVChangeFilter) ([]*influxdb.KVChange, error) {
+	params := [][2]string{{"after", strconv.FormatUint(filter.After, 10)}}
+	if filter.Limit > 0 {
+		params = append(params, [2]string{"limit", strconv.Itoa(filter.Limit)})
+	}
+	if filter.Wait > 0 {
+		params = append(params, [2]string{"wait", filter.Wait.String()})
+	}
+
+	var resp replicationChangesResponse
+	err := s.Client.
+		Get(replicationChangesPath).
+		QueryParams(params...).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Changes, nil
+}
+
+// KVSnapshot writes a snapshot of the remote metadata store to w.
+func (s *ReplicationService) KVSnapshot(ctx context.Context, w io.Writer) error {
+	return s.Client.
+		Get(replicationSnapshotPath).
+		Accept("application/octet-stream").
+		Decode(func(resp *http.Response) error {
+			_, err := io.Copy(w, resp.Body)
+			return err
+		}).
+		Do(ctx)
+}
+
+// PromoteReplica promotes the remote instance to primary.
+func (s *ReplicationService) PromoteReplica(ctx context.Context) error {
+	return s.Client.
+		Post(nil, replicationPromotePath).
+		Do(ctx)
+}
@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/inmem"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/replication"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestReplicationService(t *testing.T) {
+	ctx := context.Background()
+
+	store := replication.NewStore(zaptest.NewLogger(t), inmem.NewKVStore(), replication.WithMaxChanges(2))
+	if err := store.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &ReplicationBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		log:                zaptest.NewLogger(t),
+		ReplicationService: store,
+	}
+	server := httptest.NewServer(NewReplicationHandler(zaptest.NewLogger(t), backend))
+	defer server.Close()
+
+	client := &ReplicationService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	for _, k := range []string{"k1", "k2", "k3"} {
+		err := store.Update(ctx, func(tx kv.Tx) error {
+			b, err := tx.Bucket([]byte("b"))
+			if err != nil {
+				return err
+			}
+			return b.Put([]byte(k), []byte("v"))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := client.FindKVChanges(ctx, influxdb.KVChangeFilter{After: 1, Limit: 1, Wait: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Seq != 2 || string(changes[0].Ops[0].Key) != "k2" {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+
+	if _, err := client.FindKVChanges(ctx, influxdb.KVChangeFilter{}); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected %q for truncated changes, got: %v", influxdb.EConflict, err)
+	}
+
+	st, err := client.ReplicationStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Role != influxdb.ReplicationRolePrimary || st.Seq != 3 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+
+	if err := client.PromoteReplica(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeKVChangeFilter(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    influxdb.KVChangeFilter
+		wantErr bool
+	}{
+		{query: "", want: influxdb.KVChangeFilter{}},
+		{query: "after=5&limit=10&wait=30s", want: influxdb.KVChangeFilter{After: 5, Limit: 10, Wait: 30 * time.Second}},
+		{query: "wait=1h", want: influxdb.KVChangeFilter{Wait: maxReplicationWait}},
+		{query: "after=-1", wantErr: true},
+		{query: "limit=x", wantErr: true},
+		{query: "wait=-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			r := httptest.NewRequest("GET", replicationChangesPath+"?"+tt.query, nil)
+			got, err := decodeKVChangeFilter(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
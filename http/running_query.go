@@ -0,0 +1,236 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixRunningQueries = "/api/v2/queries"
+	runningQueriesIDPath = "/api/v2/queries/:id"
+)
+
+// RunningQueryBackend is all services and associated parameters required to
+// construct the RunningQueryHandler.
+type RunningQueryBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	RunningQueryService influxdb.RunningQueryService
+}
+
+// NewRunningQueryBackend returns a new instance of RunningQueryBackend.
+func NewRunningQueryBackend(log *zap.Logger, b *APIBackend) *RunningQueryBackend {
+	return &RunningQueryBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		RunningQueryService: b.RunningQueryService,
+	}
+}
+
+// RunningQueryHandler lists and kills the queries running in the server.
+type RunningQueryHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	RunningQueryService influxdb.RunningQueryService
+}
+
+// NewRunningQueryHandler returns a new instance of RunningQueryHandler.
+func NewRunningQueryHandler(log *zap.Logger, b *RunningQueryBackend) *RunningQueryHandler {
+	h := &RunningQueryHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		RunningQueryService: b.RunningQueryService,
+	}
+
+	h.HandlerFunc("GET", prefixRunningQueries, h.handleGetRunningQueries)
+	h.HandlerFunc("GET", runningQueriesIDPath, h.handleGetRunningQuery)
+	h.HandlerFunc("DELETE", runningQueriesIDPath, h.handleCancelRunningQuery)
+	return h
+}
+
+type runningQueryResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.RunningQuery
+}
+
+func newRunningQueryResponse(q *influxdb.RunningQuery) *runningQueryResponse {
+	return &runningQueryResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("%s/%s", prefixRunningQueries, q.ID),
+		},
+		RunningQuery: *q,
+	}
+}
+
+type runningQueriesResponse struct {
+	Links   map[string]string       `json:"links"`
+	Queries []*runningQueryResponse `json:"queries"`
+}
+
+func newRunningQueriesResponse(qs []*influxdb.RunningQuery) *runningQueriesResponse {
+	res := &runningQueriesResponse{
+		Links: map[string]string{
+			"self": prefixRunningQueries,
+		},
+		Queries: make([]*runningQueryResponse, 0, len(qs)),
+	}
+	for _, q := range qs {
+		res.Queries = append(res.Queries, newRunningQueryResponse(q))
+	}
+	return res
+}
+
+// handleGetRunningQueries is the HTTP handler for the GET /api/v2/queries route.
+func (h *RunningQueryHandler) handleGetRunningQueries(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var filter influxdb.RunningQueryFilter
+	if v := r.URL.Query().Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "orgID is invalid",
+				Err:  err,
+			}, w)
+			return
+		}
+		filter.OrgID = id
+	}
+
+	qs, err := h.RunningQueryService.FindRunningQueries(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunningQueriesResponse(qs)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleGetRunningQuery is the HTTP handler for the GET /api/v2/queries/:id route.
+func (h *RunningQueryHandler) handleGetRunningQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	id, err := decodeRunningQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	q, err := h.RunningQueryService.FindRunningQueryByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunningQueryResponse(q)); err != nil {
+		logEncodingError(h.log, r, err)
+	}
+}
+
+// handleCancelRunningQuery is the HTTP handler for the DELETE /api/v2/queries/:id route.
+func (h *RunningQueryHandler) handleCancelRunningQuery(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "RunningQueryHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	id, err := decodeRunningQueryID(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.RunningQueryService.CancelRunningQuery(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Info("Query killed", zap.String("queryID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeRunningQueryID(ctx context.Context) (influxdb.ID, error) {
+	params := httprouter.ParamsFromContext(ctx)
+	id := params.ByName("id")
+	if id == "" {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "url missing id",
+		}
+	}
+
+	var i influxdb.ID
+	if err := i.DecodeFromString(id); err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid query id format",
+			Err:  err,
+		}
+	}
+	return i, nil
+}
+
+// RunningQueryService is the client implementation of influxdb.RunningQueryService.
+type RunningQueryService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.RunningQueryService = (*RunningQueryService)(nil)
+
+// FindRunningQueryByID returns a single query running in the remote server.
+func (s *RunningQueryService) FindRunningQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+	var resp runningQueryResponse
+	err := s.Client.
+		Get(prefixRunningQueries, id.String()).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.RunningQuery, nil
+}
+
+// FindRunningQueries returns the queries running in the remote server.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	req := s.Client.Get(prefixRunningQueries)
+	if filter.OrgID != nil {
+		req = req.QueryParams([2]string{"orgID", filter.OrgID.String()})
+	}
+
+	var resp runningQueriesResponse
+	if err := req.DecodeJSON(&resp).Do(ctx); err != nil {
+		return nil, err
+	}
+
+	qs := make([]*influxdb.RunningQuery, 0, len(resp.Queries))
+	for _, q := range resp.Queries {
+		qs = append(qs, &q.RunningQuery)
+	}
+	return qs, nil
+}
+
+// CancelRunningQuery kills a query running in the remote server.
+func (s *RunningQueryService) CancelRunningQuery(ctx context.Context, id influxdb.ID) error {
+	return s.Client.
+		Delete(prefixRunningQueries, id.String()).
+		Do(ctx)
+}
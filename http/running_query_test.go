@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunningQueryService(t *testing.T) {
+	ctx := context.Background()
+
+	orgID := influxdb.ID(10)
+	running := &influxdb.RunningQuery{
+		ID:           1,
+		OrgID:        orgID,
+		State:        "executing",
+		CompilerType: "flux",
+		Query:        `from(bucket: "telegraf") |> range(start: -1h)`,
+		CreatedAt:    time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC),
+		Elapsed:      influxdb.Duration{Duration: 90 * time.Second},
+		MemoryBytes:  1024,
+	}
+	var canceled influxdb.ID
+	svc := mock.NewRunningQueryService()
+	svc.FindRunningQueryByIDF = func(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+		if id != running.ID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "query not found"}
+		}
+		return running, nil
+	}
+	svc.FindRunningQueriesF = func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+		if filter.OrgID != nil && *filter.OrgID != orgID {
+			return nil, nil
+		}
+		return []*influxdb.RunningQuery{running}, nil
+	}
+	svc.CancelRunningQueryF = func(ctx context.Context, id influxdb.ID) error {
+		canceled = id
+		return nil
+	}
+
+	backend := &RunningQueryBackend{
+		HTTPErrorHandler:    kithttp.ErrorHandler(0),
+		log:                 zaptest.NewLogger(t),
+		RunningQueryService: svc,
+	}
+	server := httptest.NewServer(NewRunningQueryHandler(zaptest.NewLogger(t), backend))
+	defer server.Close()
+
+	client := &RunningQueryService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	qs, err := client.FindRunningQueries(ctx, influxdb.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 1 || *qs[0] != *running {
+		t.Fatalf("unexpected running queries: %+v", qs)
+	}
+
+	otherOrgID := influxdb.ID(20)
+	if qs, err := client.FindRunningQueries(ctx, influxdb.RunningQueryFilter{OrgID: &otherOrgID}); err != nil {
+		t.Fatal(err)
+	} else if len(qs) != 0 {
+		t.Fatalf("unexpected running queries of another organization: %+v", qs)
+	}
+
+	q, err := client.FindRunningQueryByID(ctx, running.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *q != *running {
+		t.Fatalf("unexpected running query: %+v", q)
+	}
+
+	if err := client.CancelRunningQuery(ctx, running.ID); err != nil {
+		t.Fatal(err)
+	}
+	if canceled != running.ID {
+		t.Fatalf("unexpected query canceled: %s", canceled)
+	}
+
+	if _, err := client.FindRunningQueryByID(ctx, 2); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected %q, got: %v", influxdb.ENotFound, err)
+	}
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const prefixStorageMode = "/api/v2/storage/mode"
+
+// StorageModeBackend is all services and associated parameters required to
+// construct the StorageModeHandler.
+type StorageModeBackend struct {
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	StorageModeService influxdb.StorageModeService
+}
+
+// NewStorageModeBackend returns a new instance of StorageModeBackend.
+func NewStorageModeBackend(log *zap.Logger, b *APIBackend) *StorageModeBackend {
+	return &StorageModeBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		StorageModeService: b.StorageModeService,
+	}
+}
+
+// StorageModeHandler reads and changes the mode of the storage engine.
+type StorageModeHandler struct {
+	*httprouter.Router
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	StorageModeService influxdb.StorageModeService
+}
+
+// NewStorageModeHandler returns a new instance of StorageModeHandler.
+func NewStorageModeHandler(log *zap.Logger, b *StorageModeBackend) *StorageModeHandler {
+	h := &StorageModeHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		StorageModeService: b.StorageModeService,
+	}
+
+	h.HandlerFunc("GET", prefixStorageMode, h.handleGetStorageMode)
+	h.HandlerFunc("PUT", prefixStorageMode, h.handlePutStorageMode)
+	return h
+}
+
+// handleGetStorageMode is the HTTP handler for the GET /api/v2/storage/mode route.
+func (h *StorageModeHandler) handleGetStorageMode(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageModeHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	m, err := h.StorageModeService.StorageMode(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := encodeResponse(ctx, w, http.StatusOK, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// handlePutStorageMode is the HTTP handler for the PUT /api/v2/storage/mode route.
+func (h *StorageModeHandler) handlePutStorageMode(w http.ResponseWriter, r *http.Request) {
+	span, r := tracing.ExtractFromHTTPRequest(r, "StorageModeHandler")
+	defer span.Finish()
+
+	ctx := r.Context()
+	var req influxdb.StorageMode
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid json structure",
+			Err:  err,
+		}, w)
+		return
+	}
+
+	m, err := h.StorageModeService.SetStorageMode(ctx, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	h.log.Info("Storage mode changed", zap.Bool("readOnly", m.ReadOnly), zap.Bool("pauseCompactions", m.PauseCompactions))
+
+	if err := encodeResponse(ctx, w, http.StatusOK, m); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+// StorageModeService is the client implementation of influxdb.StorageModeService.
+type StorageModeService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.StorageModeService = (*StorageModeService)(nil)
+
+// StorageMode returns the mode of the remote storage engine.
+func (s *StorageModeService) StorageMode(ctx context.Context) (*influxdb.StorageMode, error) {
+	var m influxdb.StorageMode
+	err := s.Client.
+		Get(prefixStorageMode).
+		DecodeJSON(&m).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetStorageMode changes the mode of the remote storage engine.
+func (s *StorageModeService) SetStorageMode(ctx context.Context, m influxdb.StorageMode) (*influxdb.StorageMode, error) {
+	var resp influxdb.StorageMode
+	err := s.Client.
+		PutJSON(m, prefixStorageMode).
+		DecodeJSON(&resp).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
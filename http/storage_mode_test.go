@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	kithttp "github.com/influxdata/influxdb/kit/transport/http"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStorageModeService(t *testing.T) {
+	ctx := context.Background()
+
+	var mode influxdb.StorageMode
+	svc := &mock.StorageModeService{
+		StorageModeFn: func(ctx context.Context) (*influxdb.StorageMode, error) {
+			m := mode
+			return &m, nil
+		},
+		SetStorageModeFn: func(ctx context.Context, m influxdb.StorageMode) (*influxdb.StorageMode, error) {
+			mode = m
+			return &m, nil
+		},
+	}
+
+	backend := &StorageModeBackend{
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		log:                zaptest.NewLogger(t),
+		StorageModeService: svc,
+	}
+	server := httptest.NewServer(NewStorageModeHandler(zaptest.NewLogger(t), backend))
+	defer server.Close()
+
+	client := &StorageModeService{Client: mustNewHTTPClient(t, server.URL, "")}
+
+	want := influxdb.StorageMode{ReadOnly: true, PauseCompactions: true}
+	m, err := client.SetStorageMode(ctx, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *m != want || mode != want {
+		t.Fatalf("unexpected mode set: got %+v, engine %+v", m, mode)
+	}
+
+	m, err = client.StorageMode(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *m != want {
+		t.Fatalf("unexpected mode: %+v", m)
+	}
+
+	svc.SetStorageModeFn = func(ctx context.Context, m influxdb.StorageMode) (*influxdb.StorageMode, error) {
+		return nil, &influxdb.Error{Code: influxdb.EUnavailable, Msg: "engine is closed"}
+	}
+	if _, err := client.SetStorageMode(ctx, influxdb.StorageMode{}); influxdb.ErrorCode(err) != influxdb.EUnavailable {
+		t.Fatalf("expected %q, got: %v", influxdb.EUnavailable, err)
+	}
+}
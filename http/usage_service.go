@@ -11,6 +11,8 @@ import (
 	"go.uber.org/zap"
 )
 
+const prefixUsage = "/api/v2/usage"
+
 // UsageHandler represents an HTTP API handler for usages.
 type UsageHandler struct {
 	*httprouter.Router
@@ -27,7 +29,7 @@ func NewUsageHandler(log *zap.Logger, he platform.HTTPErrorHandler) *UsageHandle
 		log:    log,
 	}
 
-	h.HandlerFunc("GET", "/api/v2/usage", h.handleGetUsage)
+	h.HandlerFunc("GET", prefixUsage, h.handleGetUsage)
 	return h
 }
 
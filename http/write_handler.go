@@ -40,6 +40,13 @@ type WriteBackend struct {
 	OrganizationService influxdb.OrganizationService
 }
 
+// WriteLimiter decides whether an organization may write a batch of points.
+type WriteLimiter interface {
+	// AllowWrite returns an error if writing n bytes containing points points
+	// would exceed the limits of orgID.
+	AllowWrite(ctx context.Context, orgID influxdb.ID, n, points int) error
+}
+
 // NewWriteBackend returns a new instance of WriteBackend.
 func NewWriteBackend(log *zap.Logger, b *APIBackend) *WriteBackend {
 	return &WriteBackend{
@@ -63,6 +70,7 @@ type WriteHandler struct {
 	OrganizationService influxdb.OrganizationService
 
 	PointsWriter storage.PointsWriter
+	WriteLimiter WriteLimiter
 
 	EventRecorder metric.EventRecorder
 
@@ -108,6 +116,14 @@ func WithParserMaxValues(n int) WriteHandlerOption {
 	}
 }
 
+// WithWriteLimiter rejects writes that the limiter does not allow.
+// When l is nil, writes are not limited.
+func WithWriteLimiter(l WriteLimiter) WriteHandlerOption {
+	return func(w *WriteHandler) {
+		w.WriteLimiter = l
+	}
+}
+
 // Prefix provides the route prefix.
 func (*WriteHandler) Prefix() string {
 	return prefixWrite
@@ -301,7 +317,29 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.WriteLimiter != nil {
+		if err := h.WriteLimiter.AllowWrite(ctx, org.ID, requestBytes, len(points)); err != nil {
+			log.Info("Write rejected by organization limits", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+	}
+
 	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
+		// Points rejected by the engine, such as those that would create
+		// series beyond a series limit, are the writer's error to fix.
+		var pwe tsdb.PartialWriteError
+		if errors.As(err, &pwe) {
+			log.Info("Points rejected by storage engine", zap.Error(err))
+			handleError(err, influxdb.EUnprocessableEntity, "")
+			return
+		}
+		// The engine rejects writes while it is read-only.
+		if influxdb.ErrorCode(err) == influxdb.EUnavailable {
+			log.Info("Points rejected by unavailable storage engine", zap.Error(err))
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
 		log.Error("Error writing points", zap.Error(err))
 		handleError(err, influxdb.EInternal, "unexpected error writing points to database")
 		return
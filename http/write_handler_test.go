@@ -10,6 +10,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/http/metric"
@@ -17,6 +18,7 @@ import (
 	kithttp "github.com/influxdata/influxdb/kit/transport/http"
 	"github.com/influxdata/influxdb/mock"
 	influxtesting "github.com/influxdata/influxdb/testing"
+	"github.com/influxdata/influxdb/tsdb"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -142,6 +144,42 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"internal error","message":"unexpected error writing points to database: error"}`,
 			},
 		},
+		{
+			name: "points rejected by the engine are unprocessable",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1",
+				auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:      testOrg("043e0780ee2b1000"),
+				bucket:   testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeErr: tsdb.PartialWriteError{Reason: "max-series-per-bucket limit exceeded", Dropped: 1},
+			},
+			wants: wants{
+				code: 422,
+				body: `{"code":"unprocessable entity","message":"partial write: max-series-per-bucket limit exceeded dropped=1"}`,
+			},
+		},
+		{
+			name: "read-only storage engine is unavailable",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1",
+				auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:      testOrg("043e0780ee2b1000"),
+				bucket:   testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				writeErr: influxdb.ErrStorageReadOnly,
+			},
+			wants: wants{
+				code: 503,
+				body: `{"code":"unavailable","message":"storage engine is read-only"}`,
+			},
+		},
 		{
 			name: "empty request body returns 400 error",
 			request: request{
@@ -275,6 +313,26 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"request too large","message":"unable to read data: points batch is too large"}`,
 			},
 		},
+		{
+			name: "org write limit rejected",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1",
+				auth:   bucketWritePermission("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+				opts: []WriteHandlerOption{WithWriteLimiter(writeLimiterFn(func(ctx context.Context, orgID influxdb.ID, n, points int) error {
+					return influxdb.ErrOrgLimitExceeded("test", "organization write rate limit exceeded", time.Second)
+				}))},
+			},
+			wants: wants{
+				code: 429,
+				body: `{"code":"too many requests","message":"organization write rate limit exceeded: retry after 1s"}`,
+			},
+		},
 		{
 			name: "bytes limit rejected",
 			request: request{
@@ -413,3 +471,9 @@ func testBucket(org, bucket string) *influxdb.Bucket {
 		OrgID: oid,
 	}
 }
+
+type writeLimiterFn func(ctx context.Context, orgID influxdb.ID, n, points int) error
+
+func (fn writeLimiterFn) AllowWrite(ctx context.Context, orgID influxdb.ID, n, points int) error {
+	return fn(ctx, orgID, n, points)
+}
@@ -189,6 +189,7 @@ func (a *API) Err(w http.ResponseWriter, err error) {
 	}
 
 	a.logErr("api error encountered", zap.Error(err))
+	SetRetryAfter(w, err)
 
 	v, status, err := a.errFn(err)
 	if err != nil {
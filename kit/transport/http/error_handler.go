@@ -3,7 +3,9 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/influxdata/influxdb"
 )
@@ -21,11 +23,9 @@ func (h ErrorHandler) HandleHTTPError(ctx context.Context, err error, w http.Res
 	}
 
 	code := influxdb.ErrorCode(err)
-	httpCode, ok := statusCodePlatformError[code]
-	if !ok {
-		httpCode = http.StatusBadRequest
-	}
+	httpCode := ErrorStatusCode(err)
 	w.Header().Set(PlatformErrorCodeHeader, code)
+	SetRetryAfter(w, err)
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(httpCode)
 	var e struct {
@@ -41,3 +41,22 @@ func (h ErrorHandler) HandleHTTPError(ctx context.Context, err error, w http.Res
 	b, _ := json.Marshal(e)
 	_, _ = w.Write(b)
 }
+
+// ErrorStatusCode returns the HTTP status code for the platform error code
+// of err. Unknown error codes are bad requests.
+func ErrorStatusCode(err error) int {
+	if code, ok := statusCodePlatformError[influxdb.ErrorCode(err)]; ok {
+		return code
+	}
+	return http.StatusBadRequest
+}
+
+// SetRetryAfter sets the Retry-After header, in whole seconds, when err
+// carries the duration after which the request may be retried.
+func SetRetryAfter(w http.ResponseWriter, err error) {
+	d := influxdb.ErrorRetryAfter(err)
+	if d <= 0 {
+		return
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.Seconds()))))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/http"
@@ -55,3 +56,19 @@ func TestEncodeErrorWithError(t *testing.T) {
 		t.Errorf("unexpected message -want/+got:\n\t- %q\n\t+ %q", want, got)
 	}
 }
+
+func TestEncodeErrorWithRetryAfter(t *testing.T) {
+	ctx := context.TODO()
+	err := influxdb.ErrOrgLimitExceeded("test", "organization write rate limit exceeded", 1500*time.Millisecond)
+
+	w := httptest.NewRecorder()
+
+	kithttp.ErrorHandler(0).HandleHTTPError(ctx, err, w)
+
+	if w.Code != 429 {
+		t.Errorf("expected status code 429, got: %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("expected Retry-After: 2, got: %q", got)
+	}
+}
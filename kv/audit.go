@@ -0,0 +1,210 @@
+package kv
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	auditBucket = []byte("auditv1")
+)
+
+var _ influxdb.AuditService = (*Service)(nil)
+
+func (s *Service) initializeAudit(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(auditBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// encodeAuditEventKey builds a key that sorts audit events by the time they
+// were recorded. The event ID is appended to keep keys unique.
+func encodeAuditEventKey(e *influxdb.AuditEvent) ([]byte, error) {
+	id, err := e.ID.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 8, 8+len(id))
+	// This needs to be big-endian so that the iteration order is preserved when scanning keys
+	binary.BigEndian.PutUint64(key, uint64(e.Time.UTC().UnixNano()))
+	return append(key, id...), nil
+}
+
+func decodeAuditEventKeyTime(key []byte) time.Time {
+	if len(key) < 8 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[:8]))).UTC()
+}
+
+// CreateAuditEvent records an audit event.
+func (s *Service) CreateAuditEvent(ctx context.Context, e *influxdb.AuditEvent) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.createAuditEvent(ctx, tx, e)
+	})
+}
+
+func (s *Service) createAuditEvent(ctx context.Context, tx Tx, e *influxdb.AuditEvent) error {
+	e.ID = s.IDGenerator.ID()
+	if e.Time.IsZero() {
+		e.Time = s.Now()
+	}
+	e.Time = e.Time.UTC()
+
+	key, err := encodeAuditEventKey(e)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	v, err := json.Marshal(e)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	b, err := tx.Bucket(auditBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(key, v); err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// FindAuditEvents returns the audit events matching filter. Events are
+// returned oldest first unless opts.Descending is set.
+func (s *Service) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	var opts influxdb.FindOptions
+	if len(opt) > 0 {
+		opts = opt[0]
+	}
+
+	events := []*influxdb.AuditEvent{}
+	err := s.kv.View(ctx, func(tx Tx) error {
+		var err error
+		events, err = s.findAuditEvents(ctx, tx, filter, opts)
+		return err
+	})
+	if err != nil {
+		return nil, 0, &influxdb.Error{
+			Err: err,
+		}
+	}
+
+	return events, len(events), nil
+}
+
+func (s *Service) findAuditEvents(ctx context.Context, tx Tx, filter influxdb.AuditEventFilter, opts influxdb.FindOptions) ([]*influxdb.AuditEvent, error) {
+	b, err := tx.Bucket(auditBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+
+	first, next := cur.First, cur.Next
+	if opts.Descending {
+		first, next = cur.Last, cur.Prev
+	}
+
+	events := []*influxdb.AuditEvent{}
+	seen := 0
+	for k, v := first(); k != nil; k, v = next() {
+		// Skip decoding entries which fall outside of the requested time range.
+		ts := decodeAuditEventKeyTime(k)
+		if filter.Start != nil && ts.Before(*filter.Start) {
+			if opts.Descending {
+				break
+			}
+			continue
+		}
+		if filter.Stop != nil && !ts.Before(*filter.Stop) {
+			if opts.Descending {
+				continue
+			}
+			break
+		}
+
+		e := &influxdb.AuditEvent{}
+		if err := json.Unmarshal(v, e); err != nil {
+			return nil, err
+		}
+
+		if !filter.Match(e) {
+			continue
+		}
+
+		seen++
+		if seen <= opts.Offset {
+			continue
+		}
+
+		events = append(events, e)
+		if opts.Limit > 0 && len(events) >= opts.Limit {
+			break
+		}
+	}
+
+	return events, nil
+}
+
+// DeleteAuditEventsBefore removes all audit events recorded before t.
+func (s *Service) DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error) {
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		var err error
+		n, err = s.deleteAuditEventsBefore(ctx, tx, t)
+		return err
+	})
+	if err != nil {
+		return 0, &influxdb.Error{
+			Err: err,
+		}
+	}
+	return n, nil
+}
+
+func (s *Service) deleteAuditEventsBefore(ctx context.Context, tx Tx, t time.Time) (int, error) {
+	b, err := tx.Bucket(auditBucket)
+	if err != nil {
+		return 0, err
+	}
+
+	cur, err := b.Cursor()
+	if err != nil {
+		return 0, err
+	}
+
+	// Collect keys first; deleting while iterating is not safe on every store.
+	var keys [][]byte
+	for k, _ := cur.First(); k != nil; k, _ = cur.Next() {
+		if !decodeAuditEventKeyTime(k).Before(t) {
+			break
+		}
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), nil
+}
@@ -0,0 +1,147 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestInmemAuditService(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+	testAuditService(t, s)
+}
+
+func TestBoltAuditService(t *testing.T) {
+	s, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+	testAuditService(t, s)
+}
+
+func testAuditService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	var (
+		orgA     = influxdb.ID(10)
+		orgB     = influxdb.ID(11)
+		t0       = time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+		ids      = []influxdb.ID{1, 2, 3, 4}
+		recorded = []*influxdb.AuditEvent{
+			{Time: t0, Action: influxdb.AuditCreate, OrgID: orgA, ResourceType: influxdb.BucketsResourceType, ResourceID: 100},
+			{Time: t0.Add(time.Minute), Action: influxdb.AuditUpdate, OrgID: orgA, ResourceType: influxdb.BucketsResourceType, ResourceID: 100},
+			{Time: t0.Add(2 * time.Minute), Action: influxdb.AuditCreate, OrgID: orgB, ResourceType: influxdb.DashboardsResourceType, ResourceID: 200},
+			{Time: t0.Add(3 * time.Minute), Action: influxdb.AuditDelete, OrgID: orgA, ResourceType: influxdb.BucketsResourceType, ResourceID: 100},
+		}
+	)
+
+	for i, e := range recorded {
+		id := ids[i]
+		svc.IDGenerator = mock.IDGenerator{IDFn: func() influxdb.ID { return id }}
+		if err := svc.CreateAuditEvent(ctx, e); err != nil {
+			t.Fatalf("failed to create audit event: %v", err)
+		}
+		if e.ID != ids[i] {
+			t.Fatalf("unexpected audit event id: got %s, want %s", e.ID, ids[i])
+		}
+	}
+
+	eventIDs := func(es []*influxdb.AuditEvent) []influxdb.ID {
+		out := make([]influxdb.ID, 0, len(es))
+		for _, e := range es {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+
+	bucket := influxdb.BucketsResourceType
+	del := influxdb.AuditDelete
+	start := t0.Add(time.Minute)
+	stop := t0.Add(3 * time.Minute)
+
+	tests := []struct {
+		name   string
+		filter influxdb.AuditEventFilter
+		opts   influxdb.FindOptions
+		want   []influxdb.ID
+	}{
+		{
+			name: "all events oldest first",
+			want: []influxdb.ID{1, 2, 3, 4},
+		},
+		{
+			name: "descending with limit and offset",
+			opts: influxdb.FindOptions{Descending: true, Limit: 2, Offset: 1},
+			want: []influxdb.ID{3, 2},
+		},
+		{
+			name:   "by org",
+			filter: influxdb.AuditEventFilter{OrgID: &orgA},
+			want:   []influxdb.ID{1, 2, 4},
+		},
+		{
+			name:   "by resource type and action",
+			filter: influxdb.AuditEventFilter{ResourceType: &bucket, Action: &del},
+			want:   []influxdb.ID{4},
+		},
+		{
+			name:   "by time range",
+			filter: influxdb.AuditEventFilter{Start: &start, Stop: &stop},
+			want:   []influxdb.ID{2, 3},
+		},
+		{
+			name:   "by time range descending",
+			filter: influxdb.AuditEventFilter{Start: &start, Stop: &stop},
+			opts:   influxdb.FindOptions{Descending: true},
+			want:   []influxdb.ID{3, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, n, err := svc.FindAuditEvents(ctx, tt.filter, tt.opts)
+			if err != nil {
+				t.Fatalf("failed to find audit events: %v", err)
+			}
+			if n != len(tt.want) {
+				t.Fatalf("unexpected number of events: got %d, want %d", n, len(tt.want))
+			}
+			got := eventIDs(events)
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("unexpected events: got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	n, err := svc.DeleteAuditEventsBefore(ctx, t0.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("failed to delete audit events: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("unexpected number of deleted events: got %d, want 2", n)
+	}
+
+	events, _, err := svc.FindAuditEvents(ctx, influxdb.AuditEventFilter{})
+	if err != nil {
+		t.Fatalf("failed to find audit events: %v", err)
+	}
+	if got := eventIDs(events); len(got) != 2 || got[0] != 3 || got[1] != 4 {
+		t.Fatalf("unexpected events after retention: got %v", got)
+	}
+}
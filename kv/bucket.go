@@ -675,6 +675,14 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.ColdAfter != nil {
+		b.ColdAfter = *upd.ColdAfter
+	}
+
+	if upd.PartitionDuration != nil {
+		b.PartitionDuration = *upd.PartitionDuration
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
@@ -10,6 +10,7 @@ import (
 
 	influxdb "github.com/influxdata/influxdb"
 	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/query"
 )
 
 var (
@@ -529,6 +530,10 @@ func (s *Service) deleteDashboardCellView(ctx context.Context, tx Tx, dashboardI
 }
 
 func (s *Service) putDashboardCellView(ctx context.Context, tx Tx, dashboardID, cellID influxdb.ID, view *influxdb.View) error {
+	if err := validViewParams(view); err != nil {
+		return err
+	}
+
 	k, err := encodeDashboardCellViewID(dashboardID, cellID)
 	if err != nil {
 		return influxdb.NewError(influxdb.WithErrorErr(err))
@@ -551,6 +556,45 @@ func (s *Service) putDashboardCellView(ctx context.Context, tx Tx, dashboardID,
 	return nil
 }
 
+// validViewParams returns an error if the params of a query of the view
+// cannot be passed to the query when the cell runs it.
+func validViewParams(view *influxdb.View) error {
+	for _, q := range viewQueries(view.Properties) {
+		if err := query.Params(q.Params).Validate(); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  err.Error(),
+			}
+		}
+	}
+	return nil
+}
+
+// viewQueries returns the queries of the view properties, if any.
+func viewQueries(p influxdb.ViewProperties) []influxdb.DashboardQuery {
+	switch p := p.(type) {
+	case influxdb.LinePlusSingleStatProperties:
+		return p.Queries
+	case influxdb.XYViewProperties:
+		return p.Queries
+	case influxdb.CheckViewProperties:
+		return p.Queries
+	case influxdb.SingleStatViewProperties:
+		return p.Queries
+	case influxdb.HistogramViewProperties:
+		return p.Queries
+	case influxdb.HeatmapViewProperties:
+		return p.Queries
+	case influxdb.ScatterViewProperties:
+		return p.Queries
+	case influxdb.GaugeViewProperties:
+		return p.Queries
+	case influxdb.TableViewProperties:
+		return p.Queries
+	}
+	return nil
+}
+
 func encodeDashboardCellViewID(dashID, cellID influxdb.ID) ([]byte, error) {
 	did, err := dashID.Encode()
 	if err != nil {
@@ -0,0 +1,78 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	influxdbtesting "github.com/influxdata/influxdb/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltDBRPMappingService(t *testing.T) {
+	t.Run("CreateDBRPMapping", func(t *testing.T) { influxdbtesting.CreateDBRPMapping(initBoltDBRPMappingService, t) })
+	t.Run("FindDBRPMappingByKey", func(t *testing.T) { influxdbtesting.FindDBRPMappingByKey(initBoltDBRPMappingService, t) })
+	t.Run("FindDBRPMappings", func(t *testing.T) { influxdbtesting.FindDBRPMappings(initBoltDBRPMappingService, t) })
+	t.Run("FindDBRPMapping", func(t *testing.T) { influxdbtesting.FindDBRPMapping(initBoltDBRPMappingService, t) })
+	t.Run("DeleteDBRPMapping", func(t *testing.T) { influxdbtesting.DeleteDBRPMapping(initBoltDBRPMappingService, t) })
+}
+
+func initBoltDBRPMappingService(f influxdbtesting.DBRPMappingFields, t *testing.T) (influxdb.DBRPMappingService, func()) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing dbrp mapping service: %v", err)
+	}
+	if err := f.Populate(ctx, svc); err != nil {
+		t.Fatal(err)
+	}
+	return svc, func() {
+		if err := influxdbtesting.CleanupDBRPMappings(ctx, svc); err != nil {
+			t.Logf("failed to remove dbrp mappings: %v", err)
+		}
+		closeBolt()
+	}
+}
+
+func TestDBRPMappingService_CreateSecondDefault(t *testing.T) {
+	svc, done := initBoltDBRPMappingService(influxdbtesting.DBRPMappingFields{}, t)
+	defer done()
+
+	ctx := context.Background()
+	m := &influxdb.DBRPMapping{
+		Cluster:         "cluster",
+		Database:        "telegraf",
+		RetentionPolicy: "autogen",
+		Default:         true,
+		OrganizationID:  influxdbtesting.MustIDBase16("aaaaaaaaaaaaaaaa"),
+		BucketID:        influxdbtesting.MustIDBase16("bbbbbbbbbbbbbbbb"),
+	}
+	if err := svc.Create(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+
+	other := *m
+	other.RetentionPolicy = "weekly"
+	if err := svc.Create(ctx, &other); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected conflict creating a second default mapping, got: %v", err)
+	}
+
+	other.Default = false
+	if err := svc.Create(ctx, &other); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := svc.Find(ctx, influxdb.DBRPMappingFilter{Cluster: &m.Cluster, Database: &m.Database, Default: &m.Default})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(m) {
+		t.Fatalf("got default mapping %+v, want %+v", got, m)
+	}
+}
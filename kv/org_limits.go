@@ -0,0 +1,142 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/influxdata/influxdb"
+)
+
+var (
+	orgLimitsBucket = []byte("orglimitsv1")
+)
+
+var _ influxdb.OrgLimitService = (*Service)(nil)
+
+func (s *Service) initializeOrgLimits(ctx context.Context, tx Tx) error {
+	if _, err := tx.Bucket(orgLimitsBucket); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindOrgLimits returns the limits of the organization orgID. Organizations
+// without stored limits are unlimited.
+func (s *Service) FindOrgLimits(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	var l *influxdb.OrgLimits
+	err := s.kv.View(ctx, func(tx Tx) error {
+		lim, err := s.findOrgLimits(ctx, tx, orgID)
+		if err != nil {
+			return err
+		}
+		l = lim
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *Service) findOrgLimits(ctx context.Context, tx Tx, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+	key, err := orgID.Encode()
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgLimitsBucket)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return &influxdb.OrgLimits{OrgID: orgID}, nil
+	}
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	l := &influxdb.OrgLimits{}
+	if err := json.Unmarshal(v, l); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return l, nil
+}
+
+// PutOrgLimits replaces the limits of an existing organization.
+func (s *Service) PutOrgLimits(ctx context.Context, l *influxdb.OrgLimits) error {
+	if err := l.Valid(); err != nil {
+		return err
+	}
+
+	return s.kv.Update(ctx, func(tx Tx) error {
+		if _, err := s.findOrganizationByID(ctx, tx, l.OrgID); err != nil {
+			return err
+		}
+		return s.putOrgLimits(ctx, tx, l)
+	})
+}
+
+func (s *Service) putOrgLimits(ctx context.Context, tx Tx, l *influxdb.OrgLimits) error {
+	key, err := l.OrgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	v, err := json.Marshal(l)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgLimitsBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Put(key, v); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+func (s *Service) deleteOrgLimits(ctx context.Context, tx Tx, orgID influxdb.ID) error {
+	key, err := orgID.Encode()
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  err,
+		}
+	}
+
+	b, err := tx.Bucket(orgLimitsBucket)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Delete(key); err != nil && !IsNotFound(err) {
+		return &influxdb.Error{
+			Code: influxdb.EInternal,
+			Err:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package kv_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBoltOrgLimitService(t *testing.T) {
+	s, closeStore, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+	testOrgLimitService(t, s)
+}
+
+func TestInmemOrgLimitService(t *testing.T) {
+	s, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeStore()
+	testOrgLimitService(t, s)
+}
+
+func testOrgLimitService(t *testing.T, s kv.Store) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), s)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+
+	l, err := svc.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatalf("failed to find org limits: %v", err)
+	}
+	if want := (influxdb.OrgLimits{OrgID: org.ID}); *l != want {
+		t.Fatalf("expected unlimited org, got: %+v", l)
+	}
+
+	want := influxdb.OrgLimits{
+		OrgID:                org.ID,
+		WriteBytesPerSecond:  1 << 20,
+		PointsPerSecond:      1000,
+		MaxSeries:            10000,
+		MaxBuckets:           5,
+		MaxTasks:             10,
+		MaxConcurrentQueries: 2,
+	}
+	if err := svc.PutOrgLimits(ctx, &want); err != nil {
+		t.Fatalf("failed to put org limits: %v", err)
+	}
+
+	l, err = svc.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatalf("failed to find org limits: %v", err)
+	}
+	if !reflect.DeepEqual(*l, want) {
+		t.Fatalf("unexpected org limits: got %+v, want %+v", l, want)
+	}
+
+	if err := svc.PutOrgLimits(ctx, &influxdb.OrgLimits{OrgID: org.ID, MaxTasks: -1}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error for negative limit, got: %v", err)
+	}
+
+	if err := svc.PutOrgLimits(ctx, &influxdb.OrgLimits{OrgID: org.ID + 1}); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error for unknown org, got: %v", err)
+	}
+
+	if err := svc.DeleteOrganization(ctx, org.ID); err != nil {
+		t.Fatalf("failed to delete organization: %v", err)
+	}
+	l, err = svc.FindOrgLimits(ctx, org.ID)
+	if err != nil {
+		t.Fatalf("failed to find org limits: %v", err)
+	}
+	if want := (influxdb.OrgLimits{OrgID: org.ID}); *l != want {
+		t.Fatalf("expected limits to be removed with the org, got: %+v", l)
+	}
+}
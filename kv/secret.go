@@ -1,6 +1,7 @@
 package kv
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"errors"
@@ -10,8 +11,20 @@ import (
 
 var (
 	secretBucket = []byte("secretsv1")
+
+	// encryptedSecretPrefix marks secret values encrypted by a SecretCipher.
+	// It cannot occur in base64 encoded values.
+	encryptedSecretPrefix = []byte("\x00enc:")
 )
 
+// SecretCipher encrypts and decrypts secret values. The additional data is
+// authenticated along with the value, binding a ciphertext to the key it
+// is stored under.
+type SecretCipher interface {
+	Encrypt(plaintext, additionalData []byte) ([]byte, error)
+	Decrypt(ciphertext, additionalData []byte) ([]byte, error)
+}
+
 var _ influxdb.SecretService = (*Service)(nil)
 
 func (s *Service) initializeSecrets(ctx context.Context, tx Tx) error {
@@ -64,7 +77,7 @@ func (s *Service) loadSecret(ctx context.Context, tx Tx, orgID influxdb.ID, k st
 		return "", err
 	}
 
-	v, err := decodeSecretValue(val)
+	v, err := s.decryptSecretValue(key, val)
 	if err != nil {
 		return "", err
 	}
@@ -141,7 +154,10 @@ func (s *Service) putSecret(ctx context.Context, tx Tx, orgID influxdb.ID, k, v
 		return err
 	}
 
-	val := encodeSecretValue(v)
+	val, err := s.encryptSecretValue(key, v)
+	if err != nil {
+		return err
+	}
 
 	b, err := tx.Bucket(secretBucket)
 	if err != nil {
@@ -200,6 +216,117 @@ func encodeSecretValue(v string) []byte {
 	return val
 }
 
+// encryptSecretValue encrypts v with the configured cipher, falling back
+// to base64 when no cipher is configured.
+func (s *Service) encryptSecretValue(key []byte, v string) ([]byte, error) {
+	if s.Config.SecretCipher == nil {
+		return encodeSecretValue(v), nil
+	}
+
+	ct, err := s.Config.SecretCipher.Encrypt([]byte(v), key)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to encrypt secret",
+			Err:  err,
+		}
+	}
+
+	val := make([]byte, 0, len(encryptedSecretPrefix)+len(ct))
+	val = append(val, encryptedSecretPrefix...)
+	return append(val, ct...), nil
+}
+
+// decryptSecretValue decodes a value written by encryptSecretValue. Values
+// stored before encryption was enabled are still readable.
+func (s *Service) decryptSecretValue(key, val []byte) (string, error) {
+	if !bytes.HasPrefix(val, encryptedSecretPrefix) {
+		return decodeSecretValue(val)
+	}
+
+	if s.Config.SecretCipher == nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "secret is encrypted but no secret encryption key is configured",
+		}
+	}
+
+	v, err := s.Config.SecretCipher.Decrypt(val[len(encryptedSecretPrefix):], key)
+	if err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to decrypt secret",
+			Err:  err,
+		}
+	}
+
+	return string(v), nil
+}
+
+// ReencryptSecrets rewrites every stored secret with the configured cipher,
+// so that all secrets are encrypted with its active key. It returns the
+// number of secrets rewritten. Old keys may be removed from the cipher once
+// it has completed.
+func (s *Service) ReencryptSecrets(ctx context.Context) (int, error) {
+	if s.Config.SecretCipher == nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "no secret encryption key is configured",
+		}
+	}
+
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(secretBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+
+		type secret struct {
+			key []byte
+			val string
+		}
+
+		var secrets []secret
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			val, err := s.decryptSecretValue(k, v)
+			if err != nil {
+				return err
+			}
+			secrets = append(secrets, secret{key: append([]byte(nil), k...), val: val})
+		}
+		if err := cur.Err(); err != nil {
+			return err
+		}
+		if err := cur.Close(); err != nil {
+			return err
+		}
+
+		for _, sec := range secrets {
+			val, err := s.encryptSecretValue(sec.key, sec.val)
+			if err != nil {
+				return err
+			}
+			if err := b.Put(sec.key, val); err != nil {
+				return err
+			}
+		}
+
+		n = len(secrets)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
 // PutSecrets puts all provided secrets and overwrites any previous values.
 func (s *Service) PutSecrets(ctx context.Context, orgID influxdb.ID, m map[string]string) error {
 	return s.kv.Update(ctx, func(tx Tx) error {
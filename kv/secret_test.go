@@ -1,11 +1,15 @@
 package kv_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/secret"
 	influxdbtesting "github.com/influxdata/influxdb/testing"
 	"go.uber.org/zap/zaptest"
 )
@@ -44,3 +48,157 @@ func initSecretService(s kv.Store, f influxdbtesting.SecretServiceFields, t *tes
 
 	return svc, func() {}
 }
+
+func TestBoltSecretService_Encrypted(t *testing.T) {
+	influxdbtesting.SecretService(initBoltEncryptedSecretService, t)
+}
+
+func initBoltEncryptedSecretService(f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+
+	svc, closeSvc := initEncryptedSecretService(s, newTestKeyring(t, "k1"), f, t)
+	return svc, func() {
+		closeSvc()
+		closeBolt()
+	}
+}
+
+func initEncryptedSecretService(s kv.Store, kr *secret.Keyring, f influxdbtesting.SecretServiceFields, t *testing.T) (*kv.Service, func()) {
+	svc := kv.NewService(zaptest.NewLogger(t), s, kv.ServiceConfig{SecretCipher: kr})
+	ctx := context.Background()
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing secret service: %v", err)
+	}
+
+	for _, s := range f.Secrets {
+		for k, v := range s.Env {
+			if err := svc.PutSecret(ctx, s.OrganizationID, k, v); err != nil {
+				t.Fatalf("failed to populate secrets")
+			}
+		}
+	}
+
+	return svc, func() {}
+}
+
+// newTestKeyring returns a keyring with a random key for each id. The
+// first id is the active key.
+func newTestKeyring(t *testing.T, ids ...string) *secret.Keyring {
+	t.Helper()
+
+	var entries []string
+	for _, id := range ids {
+		k, err := secret.GenerateKey(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, k)
+	}
+
+	kr, err := secret.ParseKeyring(strings.NewReader(strings.Join(entries, ",")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kr
+}
+
+func TestService_ReencryptSecrets(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+
+	s, closeBolt, err := NewTestBoltStore(t)
+	if err != nil {
+		t.Fatalf("failed to create new kv store: %v", err)
+	}
+	defer closeBolt()
+
+	// Secrets written before encryption was enabled.
+	initSecretService(s, influxdbtesting.SecretServiceFields{
+		Secrets: []influxdbtesting.Secret{
+			{OrganizationID: orgID, Env: map[string]string{"slack": "xoxb-token"}},
+		},
+	}, t)
+
+	k1Entry, err := secret.GenerateKey("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2Entry, err := secret.GenerateKey("k2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parse := func(entries ...string) *secret.Keyring {
+		kr, err := secret.ParseKeyring(strings.NewReader(strings.Join(entries, ",")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return kr
+	}
+	k1, rotated, k2 := parse(k1Entry), parse(k2Entry, k1Entry), parse(k2Entry)
+
+	svc, _ := initEncryptedSecretService(s, k1, influxdbtesting.SecretServiceFields{
+		Secrets: []influxdbtesting.Secret{
+			{OrganizationID: orgID, Env: map[string]string{"pagerduty": "routing-key"}},
+		},
+	}, t)
+
+	// Legacy values remain readable and are encrypted by re-encryption.
+	if v, err := svc.LoadSecret(ctx, orgID, "slack"); err != nil || v != "xoxb-token" {
+		t.Fatalf("unexpected legacy secret %q: %v", v, err)
+	}
+	if n, err := svc.ReencryptSecrets(ctx); err != nil || n != 2 {
+		t.Fatalf("unexpected result re-encrypting %d secrets: %v", n, err)
+	}
+	assertSecretsEncrypted(t, s, "xoxb-token", "routing-key")
+
+	// Rotating to k2 keeps k1 secrets readable until they are re-encrypted.
+	svc, _ = initEncryptedSecretService(s, rotated, influxdbtesting.SecretServiceFields{}, t)
+	if n, err := svc.ReencryptSecrets(ctx); err != nil || n != 2 {
+		t.Fatalf("unexpected result re-encrypting %d secrets: %v", n, err)
+	}
+
+	// Once re-encrypted, k1 may be dropped from the keyring.
+	svc, _ = initEncryptedSecretService(s, k2, influxdbtesting.SecretServiceFields{}, t)
+	for k, want := range map[string]string{"slack": "xoxb-token", "pagerduty": "routing-key"} {
+		if v, err := svc.LoadSecret(ctx, orgID, k); err != nil || v != want {
+			t.Fatalf("unexpected secret %q for %s: %v", v, k, err)
+		}
+	}
+
+	// Without a key the encrypted secrets can't be read.
+	plain := kv.NewService(zaptest.NewLogger(t), s)
+	if _, err := plain.LoadSecret(ctx, orgID, "slack"); influxdb.ErrorCode(err) != influxdb.EInternal {
+		t.Fatalf("expected an internal error without an encryption key, got: %v", err)
+	}
+}
+
+func assertSecretsEncrypted(t *testing.T, s kv.Store, plaintexts ...string) {
+	t.Helper()
+
+	err := s.View(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("secretsv1"))
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			for _, p := range plaintexts {
+				if bytes.Contains(v, []byte(p)) || bytes.Contains(v, []byte(base64.StdEncoding.EncodeToString([]byte(p)))) {
+					t.Errorf("secret %q is stored unencrypted", k)
+				}
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
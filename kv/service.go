@@ -92,6 +92,10 @@ type ServiceConfig struct {
 	SessionLength time.Duration
 	Clock         clock.Clock
 
+	// SecretCipher encrypts secret values before they are stored. When
+	// nil, secrets are stored base64 encoded.
+	SecretCipher SecretCipher
+
 	indexer indexer
 }
 
@@ -102,10 +106,22 @@ func (s *Service) Initialize(ctx context.Context) error {
 			return err
 		}
 
+		if err := s.initializeAudit(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := s.initializeOrgLimits(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeDocuments(ctx, tx); err != nil {
 			return err
 		}
 
+		if err := s.initializeDBRPMappings(ctx, tx); err != nil {
+			return err
+		}
+
 		if err := s.initializeBuckets(ctx, tx); err != nil {
 			return err
 		}
@@ -0,0 +1,96 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// OrgLimits are the resource limits enforced for a single organization.
+// A zero value for any limit means the resource is unlimited.
+type OrgLimits struct {
+	OrgID ID `json:"orgID"`
+
+	// WriteBytesPerSecond caps the rate of line protocol bytes written.
+	WriteBytesPerSecond int64 `json:"writeBytesPerSecond"`
+	// PointsPerSecond caps the rate of points written.
+	PointsPerSecond int64 `json:"pointsPerSecond"`
+	// MaxSeries caps the series cardinality across all of the org's buckets.
+	MaxSeries int64 `json:"maxSeries"`
+	// MaxBuckets caps the number of user buckets.
+	MaxBuckets int `json:"maxBuckets"`
+	// MaxTasks caps the number of tasks.
+	MaxTasks int `json:"maxTasks"`
+	// MaxConcurrentQueries caps the number of queries executing at once.
+	MaxConcurrentQueries int `json:"maxConcurrentQueries"`
+}
+
+// Valid returns an error if any of the limits are negative.
+func (l OrgLimits) Valid() error {
+	if l.WriteBytesPerSecond < 0 ||
+		l.PointsPerSecond < 0 ||
+		l.MaxSeries < 0 ||
+		l.MaxBuckets < 0 ||
+		l.MaxTasks < 0 ||
+		l.MaxConcurrentQueries < 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "org limits must not be negative",
+		}
+	}
+	return nil
+}
+
+// OrgLimitService stores the limits of organizations.
+type OrgLimitService interface {
+	// FindOrgLimits returns the limits of an organization. Organizations
+	// without configured limits return an unlimited OrgLimits.
+	FindOrgLimits(ctx context.Context, orgID ID) (*OrgLimits, error)
+
+	// PutOrgLimits replaces the limits of an organization.
+	PutOrgLimits(ctx context.Context, l *OrgLimits) error
+}
+
+// RetryAfterError is carried by errors whose request may succeed if
+// repeated after the given duration.
+type RetryAfterError struct {
+	After time.Duration
+}
+
+// Error implements the error interface.
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("retry after %s", e.After)
+}
+
+// ErrorRetryAfter returns the duration after which the request that caused
+// err may be retried, or zero if the error does not carry one.
+func ErrorRetryAfter(err error) time.Duration {
+	switch e := err.(type) {
+	case *RetryAfterError:
+		if e == nil {
+			return 0
+		}
+		return e.After
+	case *Error:
+		if e == nil {
+			return 0
+		}
+		return ErrorRetryAfter(e.Err)
+	}
+	return 0
+}
+
+// ErrOrgLimitExceeded is returned when a request would exceed one of the
+// organization's limits. A positive retryAfter tells the client when the
+// request may succeed.
+func ErrOrgLimitExceeded(op, msg string, retryAfter time.Duration) *Error {
+	err := &Error{
+		Code: ETooManyRequests,
+		Op:   op,
+		Msg:  msg,
+	}
+	if retryAfter > 0 {
+		err.Err = &RetryAfterError{After: retryAfter}
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.BucketService = (*BucketService)(nil)
+
+// BucketService wraps an influxdb.BucketService and refuses to create
+// buckets beyond the MaxBuckets limit of their organization.
+type BucketService struct {
+	influxdb.BucketService
+	enforcer *Enforcer
+
+	// mu serializes creations so that concurrent requests cannot both
+	// observe a count below the limit.
+	mu sync.Mutex
+}
+
+// NewBucketService returns a BucketService enforcing the limits of e.
+func NewBucketService(s influxdb.BucketService, e *Enforcer) *BucketService {
+	return &BucketService{
+		BucketService: s,
+		enforcer:      e,
+	}
+}
+
+// CreateBucket creates b if its organization is below its bucket limit.
+// System buckets are not counted against the limit.
+func (s *BucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) error {
+	if b.Type == influxdb.BucketTypeSystem {
+		return s.BucketService.CreateBucket(ctx, b)
+	}
+
+	l, err := s.enforcer.Limits(ctx, b.OrgID)
+	if err != nil {
+		return err
+	}
+	if l.MaxBuckets <= 0 {
+		return s.BucketService.CreateBucket(ctx, b)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := countBuckets(ctx, s.BucketService, b.OrgID)
+	if err != nil {
+		return err
+	}
+	if n >= l.MaxBuckets {
+		return influxdb.ErrOrgLimitExceeded("limits/CreateBucket", fmt.Sprintf("organization has reached its limit of %d buckets", l.MaxBuckets), 0)
+	}
+	return s.BucketService.CreateBucket(ctx, b)
+}
+
+// countBuckets returns the number of user buckets owned by orgID.
+func countBuckets(ctx context.Context, s influxdb.BucketService, orgID influxdb.ID) (int, error) {
+	bs, _, err := s.FindBuckets(ctx, influxdb.BucketFilter{OrganizationID: &orgID})
+	if err != nil {
+		return 0, err
+	}
+
+	var n int
+	for _, b := range bs {
+		if b.Type == influxdb.BucketTypeUser {
+			n++
+		}
+	}
+	return n, nil
+}
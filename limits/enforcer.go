@@ -0,0 +1,292 @@
+// Package limits enforces the per-organization limits stored in an
+// influxdb.OrgLimitService.
+package limits
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultRefreshInterval is how long limits and series cardinality
+	// are cached before being read again.
+	DefaultRefreshInterval = 10 * time.Second
+
+	// queryRetryAfter is the delay suggested to clients whose query was
+	// rejected because the org is running too many queries.
+	queryRetryAfter = time.Second
+)
+
+// SeriesCardinality reports the number of series stored by an organization.
+type SeriesCardinality interface {
+	OrgSeriesCardinality(orgID influxdb.ID) (int64, error)
+}
+
+// Enforcer checks requests against the limits of their organization.
+// It is safe for concurrent use.
+type Enforcer struct {
+	limits influxdb.OrgLimitService
+	series SeriesCardinality
+
+	refresh time.Duration
+	now     func() time.Time
+
+	mu   sync.Mutex
+	orgs map[influxdb.ID]*orgState
+}
+
+// orgState is the consumption of a single organization.
+type orgState struct {
+	limits   influxdb.OrgLimits
+	loadedAt time.Time
+
+	writeBytes *rate.Limiter
+	points     *rate.Limiter
+
+	series   int64
+	seriesAt time.Time
+
+	queries      int
+	bytesWritten int64
+	valuesCount  int64
+}
+
+// EnforcerOption configures an Enforcer.
+type EnforcerOption func(*Enforcer)
+
+// WithSeriesCardinality enables the MaxSeries limit using sc to count the
+// series of an organization.
+func WithSeriesCardinality(sc SeriesCardinality) EnforcerOption {
+	return func(e *Enforcer) {
+		e.series = sc
+	}
+}
+
+// WithRefreshInterval sets how long limits and series cardinality are cached.
+func WithRefreshInterval(d time.Duration) EnforcerOption {
+	return func(e *Enforcer) {
+		e.refresh = d
+	}
+}
+
+// NewEnforcer returns an Enforcer applying the limits found in s.
+func NewEnforcer(s influxdb.OrgLimitService, opts ...EnforcerOption) *Enforcer {
+	e := &Enforcer{
+		limits:  s,
+		refresh: DefaultRefreshInterval,
+		now:     time.Now,
+		orgs:    make(map[influxdb.ID]*orgState),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Limits returns the current limits of orgID.
+func (e *Enforcer) Limits(ctx context.Context, orgID influxdb.ID) (influxdb.OrgLimits, error) {
+	st, err := e.state(ctx, orgID)
+	if err != nil {
+		return influxdb.OrgLimits{}, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return st.limits, nil
+}
+
+// state returns the state of orgID, reloading its limits once they are
+// older than the refresh interval.
+func (e *Enforcer) state(ctx context.Context, orgID influxdb.ID) (*orgState, error) {
+	now := e.now()
+
+	e.mu.Lock()
+	st, ok := e.orgs[orgID]
+	if ok && now.Sub(st.loadedAt) < e.refresh {
+		e.mu.Unlock()
+		return st, nil
+	}
+	e.mu.Unlock()
+
+	l, err := e.limits.FindOrgLimits(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok = e.orgs[orgID]
+	if !ok {
+		st = &orgState{}
+		e.orgs[orgID] = st
+	}
+	if st.writeBytes == nil || l.WriteBytesPerSecond != st.limits.WriteBytesPerSecond {
+		st.writeBytes = newLimiter(l.WriteBytesPerSecond)
+	}
+	if st.points == nil || l.PointsPerSecond != st.limits.PointsPerSecond {
+		st.points = newLimiter(l.PointsPerSecond)
+	}
+	st.limits = *l
+	st.loadedAt = now
+	return st, nil
+}
+
+// newLimiter returns a limiter allowing perSecond events every second, with
+// a burst of one second's worth. Zero means no limit.
+func newLimiter(perSecond int64) *rate.Limiter {
+	if perSecond <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(perSecond), int(perSecond))
+}
+
+// AllowWrite checks a write of n bytes containing points points against
+// the write limits of orgID and, if allowed, records it.
+func (e *Enforcer) AllowWrite(ctx context.Context, orgID influxdb.ID, n, points int) error {
+	const op = "limits/AllowWrite"
+
+	st, err := e.state(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	if err := e.checkSeries(st, orgID); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	br := st.writeBytes.ReserveN(now, n)
+	if !br.OK() {
+		return &influxdb.Error{
+			Code: influxdb.ETooLarge,
+			Op:   op,
+			Msg:  fmt.Sprintf("write of %d bytes exceeds the organization's limit of %d bytes per second", n, st.limits.WriteBytesPerSecond),
+		}
+	}
+	pr := st.points.ReserveN(now, points)
+	if !pr.OK() {
+		br.CancelAt(now)
+		return &influxdb.Error{
+			Code: influxdb.ETooLarge,
+			Op:   op,
+			Msg:  fmt.Sprintf("write of %d points exceeds the organization's limit of %d points per second", points, st.limits.PointsPerSecond),
+		}
+	}
+
+	if d := maxDuration(br.DelayFrom(now), pr.DelayFrom(now)); d > 0 {
+		br.CancelAt(now)
+		pr.CancelAt(now)
+		return influxdb.ErrOrgLimitExceeded(op, "organization write rate limit exceeded", d)
+	}
+
+	st.bytesWritten += int64(n)
+	st.valuesCount += int64(points)
+	return nil
+}
+
+// checkSeries rejects writes once the organization has reached its
+// series cardinality limit. The cardinality is sampled at most once per
+// refresh interval, so the limit may be exceeded by the series created
+// in between.
+func (e *Enforcer) checkSeries(st *orgState, orgID influxdb.ID) error {
+	if e.series == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	max := st.limits.MaxSeries
+	stale := e.now().Sub(st.seriesAt) >= e.refresh
+	e.mu.Unlock()
+
+	if max <= 0 {
+		return nil
+	}
+
+	if stale {
+		n, err := e.series.OrgSeriesCardinality(orgID)
+		if err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "unable to determine series cardinality",
+				Err:  err,
+			}
+		}
+
+		e.mu.Lock()
+		st.series = n
+		st.seriesAt = e.now()
+		e.mu.Unlock()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if st.series >= max {
+		return influxdb.ErrOrgLimitExceeded("limits/AllowWrite", fmt.Sprintf("organization has reached its limit of %d series", max), e.refresh)
+	}
+	return nil
+}
+
+// AcquireQuery reserves one of the concurrent query slots of orgID. The
+// returned function releases the slot and must be called once the query
+// has finished.
+func (e *Enforcer) AcquireQuery(ctx context.Context, orgID influxdb.ID) (func(), error) {
+	st, err := e.state(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if max := st.limits.MaxConcurrentQueries; max > 0 && st.queries >= max {
+		return nil, influxdb.ErrOrgLimitExceeded("limits/AcquireQuery", fmt.Sprintf("organization has reached its limit of %d concurrent queries", max), queryRetryAfter)
+	}
+	st.queries++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			st.queries--
+			e.mu.Unlock()
+		})
+	}, nil
+}
+
+// consumption is a snapshot of the usage tracked by the enforcer.
+type consumption struct {
+	queries      int
+	bytesWritten int64
+	valuesCount  int64
+}
+
+func (e *Enforcer) consumption(orgID influxdb.ID) consumption {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	st, ok := e.orgs[orgID]
+	if !ok {
+		return consumption{}
+	}
+	return consumption{
+		queries:      st.queries,
+		bytesWritten: st.bytesWritten,
+		valuesCount:  st.valuesCount,
+	}
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,209 @@
+package limits
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+)
+
+type seriesCardinalityFn func(orgID influxdb.ID) (int64, error)
+
+func (fn seriesCardinalityFn) OrgSeriesCardinality(orgID influxdb.ID) (int64, error) {
+	return fn(orgID)
+}
+
+func newTestEnforcer(l influxdb.OrgLimits, opts ...EnforcerOption) (*Enforcer, *time.Time) {
+	svc := mock.NewOrgLimitService()
+	svc.FindOrgLimitsFn = func(ctx context.Context, orgID influxdb.ID) (*influxdb.OrgLimits, error) {
+		if orgID != l.OrgID {
+			return &influxdb.OrgLimits{OrgID: orgID}, nil
+		}
+		lim := l
+		return &lim, nil
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := NewEnforcer(svc, opts...)
+	e.now = func() time.Time { return now }
+	return e, &now
+}
+
+func TestEnforcer_AllowWrite(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	e, now := newTestEnforcer(influxdb.OrgLimits{
+		OrgID:               orgID,
+		WriteBytesPerSecond: 100,
+		PointsPerSecond:     10,
+	})
+
+	if err := e.AllowWrite(ctx, orgID, 60, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := e.AllowWrite(ctx, orgID, 60, 5)
+	if code := influxdb.ErrorCode(err); code != influxdb.ETooManyRequests {
+		t.Fatalf("expected %q, got: %v", influxdb.ETooManyRequests, err)
+	}
+	if d := influxdb.ErrorRetryAfter(err); d <= 0 || d > time.Second {
+		t.Fatalf("unexpected retry after: %s", d)
+	}
+
+	// A rejected write must not consume the points allowance.
+	if err := e.AllowWrite(ctx, orgID, 10, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err = e.AllowWrite(ctx, orgID, 200, 1)
+	if code := influxdb.ErrorCode(err); code != influxdb.ETooLarge {
+		t.Fatalf("expected %q for a write larger than the burst, got: %v", influxdb.ETooLarge, err)
+	}
+
+	*now = now.Add(time.Second)
+	if err := e.AllowWrite(ctx, orgID, 100, 10); err != nil {
+		t.Fatalf("unexpected error after the limit replenished: %v", err)
+	}
+
+	if err := e.AllowWrite(ctx, influxdb.ID(2), 1<<20, 1<<20); err != nil {
+		t.Fatalf("unexpected error for an unlimited org: %v", err)
+	}
+
+	c := e.consumption(orgID)
+	if c.bytesWritten != 170 || c.valuesCount != 20 {
+		t.Fatalf("unexpected consumption: %+v", c)
+	}
+}
+
+func TestEnforcer_MaxSeries(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+
+	var series int64 = 9
+	e, now := newTestEnforcer(influxdb.OrgLimits{
+		OrgID:     orgID,
+		MaxSeries: 10,
+	}, WithSeriesCardinality(seriesCardinalityFn(func(id influxdb.ID) (int64, error) {
+		return series, nil
+	})))
+
+	if err := e.AllowWrite(ctx, orgID, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The cardinality is cached until the refresh interval has passed.
+	series = 10
+	if err := e.AllowWrite(ctx, orgID, 1, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	*now = now.Add(DefaultRefreshInterval)
+	err := e.AllowWrite(ctx, orgID, 1, 1)
+	if code := influxdb.ErrorCode(err); code != influxdb.ETooManyRequests {
+		t.Fatalf("expected %q, got: %v", influxdb.ETooManyRequests, err)
+	}
+	if d := influxdb.ErrorRetryAfter(err); d != DefaultRefreshInterval {
+		t.Fatalf("unexpected retry after: %s", d)
+	}
+}
+
+func TestEnforcer_AcquireQuery(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	e, _ := newTestEnforcer(influxdb.OrgLimits{
+		OrgID:                orgID,
+		MaxConcurrentQueries: 2,
+	})
+
+	release1, err := e.AcquireQuery(ctx, orgID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release2, err := e.AcquireQuery(ctx, orgID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := e.AcquireQuery(ctx, orgID); influxdb.ErrorCode(err) != influxdb.ETooManyRequests {
+		t.Fatalf("expected %q, got: %v", influxdb.ETooManyRequests, err)
+	}
+
+	// Releasing twice must only free one slot.
+	release1()
+	release1()
+	if got := e.consumption(orgID).queries; got != 1 {
+		t.Fatalf("unexpected number of queries: got %d, want 1", got)
+	}
+
+	if _, err := e.AcquireQuery(ctx, orgID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release2()
+}
+
+func TestBucketService_CreateBucket(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	e, _ := newTestEnforcer(influxdb.OrgLimits{
+		OrgID:      orgID,
+		MaxBuckets: 1,
+	})
+
+	var buckets []*influxdb.Bucket
+	inner := mock.NewBucketService()
+	inner.FindBucketsFn = func(ctx context.Context, filter influxdb.BucketFilter, opts ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return buckets, len(buckets), nil
+	}
+	inner.CreateBucketFn = func(ctx context.Context, b *influxdb.Bucket) error {
+		buckets = append(buckets, b)
+		return nil
+	}
+	svc := NewBucketService(inner, e)
+
+	if err := svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: orgID, Name: "_tasks", Type: influxdb.BucketTypeSystem}); err != nil {
+		t.Fatalf("unexpected error creating a system bucket: %v", err)
+	}
+	if err := svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: orgID, Name: "a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := svc.CreateBucket(ctx, &influxdb.Bucket{OrgID: orgID, Name: "b"})
+	if code := influxdb.ErrorCode(err); code != influxdb.ETooManyRequests {
+		t.Fatalf("expected %q, got: %v", influxdb.ETooManyRequests, err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("unexpected number of buckets: got %d, want 2", len(buckets))
+	}
+}
+
+func TestTaskService_CreateTask(t *testing.T) {
+	ctx := context.Background()
+	orgID := influxdb.ID(1)
+	e, _ := newTestEnforcer(influxdb.OrgLimits{
+		OrgID:    orgID,
+		MaxTasks: 1,
+	})
+
+	var tasks []*influxdb.Task
+	inner := mock.NewTaskService()
+	inner.FindTasksFn = func(ctx context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+		return tasks, len(tasks), nil
+	}
+	inner.CreateTaskFn = func(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+		task := &influxdb.Task{ID: influxdb.ID(len(tasks) + 1), OrganizationID: tc.OrganizationID}
+		tasks = append(tasks, task)
+		return task, nil
+	}
+	svc := NewTaskService(inner, e)
+
+	if _, err := svc.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: orgID}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := svc.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: orgID})
+	if code := influxdb.ErrorCode(err); code != influxdb.ETooManyRequests {
+		t.Fatalf("expected %q, got: %v", influxdb.ETooManyRequests, err)
+	}
+}
@@ -0,0 +1,80 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.TaskService = (*TaskService)(nil)
+
+// TaskService wraps an influxdb.TaskService and refuses to create tasks
+// beyond the MaxTasks limit of their organization.
+type TaskService struct {
+	influxdb.TaskService
+	enforcer *Enforcer
+
+	// mu serializes creations so that concurrent requests cannot both
+	// observe a count below the limit.
+	mu sync.Mutex
+}
+
+// NewTaskService returns a TaskService enforcing the limits of e.
+func NewTaskService(s influxdb.TaskService, e *Enforcer) *TaskService {
+	return &TaskService{
+		TaskService: s,
+		enforcer:    e,
+	}
+}
+
+// CreateTask creates a task if its organization is below its task limit.
+// The organization must be given by ID for the limit to apply.
+func (s *TaskService) CreateTask(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+	if !tc.OrganizationID.Valid() {
+		return s.TaskService.CreateTask(ctx, tc)
+	}
+
+	l, err := s.enforcer.Limits(ctx, tc.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if l.MaxTasks <= 0 {
+		return s.TaskService.CreateTask(ctx, tc)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n, err := countTasks(ctx, s.TaskService, tc.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= l.MaxTasks {
+		return nil, influxdb.ErrOrgLimitExceeded("limits/CreateTask", fmt.Sprintf("organization has reached its limit of %d tasks", l.MaxTasks), 0)
+	}
+	return s.TaskService.CreateTask(ctx, tc)
+}
+
+// countTasks returns the number of tasks owned by orgID.
+func countTasks(ctx context.Context, s influxdb.TaskService, orgID influxdb.ID) (int, error) {
+	filter := influxdb.TaskFilter{
+		OrganizationID: &orgID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+
+	var n int
+	for {
+		ts, _, err := s.FindTasks(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		n += len(ts)
+		if len(ts) < filter.Limit {
+			return n, nil
+		}
+		last := ts[len(ts)-1].ID
+		filter.After = &last
+	}
+}
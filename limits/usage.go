@@ -0,0 +1,91 @@
+package limits
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.UsageService = (*UsageService)(nil)
+
+// UsageService reports the consumption of the resources limited by an
+// Enforcer.
+type UsageService struct {
+	enforcer *Enforcer
+
+	BucketService influxdb.BucketService
+	TaskService   influxdb.TaskService
+}
+
+// NewUsageService returns a UsageService reporting the consumption seen by e.
+func NewUsageService(e *Enforcer, bs influxdb.BucketService, ts influxdb.TaskService) *UsageService {
+	return &UsageService{
+		enforcer:      e,
+		BucketService: bs,
+		TaskService:   ts,
+	}
+}
+
+// GetUsage returns the current consumption of an organization. Write
+// counters cover the writes accepted since the process started, the
+// remaining metrics are the current values; filter.Range is ignored.
+func (s *UsageService) GetUsage(ctx context.Context, filter influxdb.UsageFilter) (map[influxdb.UsageMetric]*influxdb.Usage, error) {
+	if filter.OrgID == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "orgID is required to report usage",
+		}
+	}
+	if filter.BucketID != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "usage is reported per organization; bucketID is not supported",
+		}
+	}
+	orgID := *filter.OrgID
+
+	c := s.enforcer.consumption(orgID)
+	usage := map[influxdb.UsageMetric]float64{
+		influxdb.UsageWriteRequestBytes: float64(c.bytesWritten),
+		influxdb.UsageValues:            float64(c.valuesCount),
+		influxdb.UsageConcurrentQueries: float64(c.queries),
+	}
+
+	if s.enforcer.series != nil {
+		n, err := s.enforcer.series.OrgSeriesCardinality(orgID)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "unable to determine series cardinality",
+				Err:  err,
+			}
+		}
+		usage[influxdb.UsageSeries] = float64(n)
+	}
+
+	if s.BucketService != nil {
+		n, err := countBuckets(ctx, s.BucketService, orgID)
+		if err != nil {
+			return nil, err
+		}
+		usage[influxdb.UsageBuckets] = float64(n)
+	}
+
+	if s.TaskService != nil {
+		n, err := countTasks(ctx, s.TaskService, orgID)
+		if err != nil {
+			return nil, err
+		}
+		usage[influxdb.UsageTasks] = float64(n)
+	}
+
+	m := make(map[influxdb.UsageMetric]*influxdb.Usage, len(usage))
+	for k, v := range usage {
+		m[k] = &influxdb.Usage{
+			OrganizationID: &orgID,
+			Type:           k,
+			Value:          v,
+		}
+	}
+	return m, nil
+}
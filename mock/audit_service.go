@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.AuditService = (*AuditService)(nil)
+
+// AuditService is a mock implementation of platform.AuditService.
+type AuditService struct {
+	CreateAuditEventFn        func(ctx context.Context, e *platform.AuditEvent) error
+	FindAuditEventsFn         func(ctx context.Context, filter platform.AuditEventFilter, opt ...platform.FindOptions) ([]*platform.AuditEvent, int, error)
+	DeleteAuditEventsBeforeFn func(ctx context.Context, t time.Time) (int, error)
+}
+
+// NewAuditService returns a mock AuditService where its methods will return
+// zero values.
+func NewAuditService() *AuditService {
+	return &AuditService{
+		CreateAuditEventFn: func(ctx context.Context, e *platform.AuditEvent) error {
+			return nil
+		},
+		FindAuditEventsFn: func(ctx context.Context, filter platform.AuditEventFilter, opt ...platform.FindOptions) ([]*platform.AuditEvent, int, error) {
+			return nil, 0, nil
+		},
+		DeleteAuditEventsBeforeFn: func(ctx context.Context, t time.Time) (int, error) {
+			return 0, fmt.Errorf("not implemented")
+		},
+	}
+}
+
+// CreateAuditEvent records an audit event.
+func (s *AuditService) CreateAuditEvent(ctx context.Context, e *platform.AuditEvent) error {
+	return s.CreateAuditEventFn(ctx, e)
+}
+
+// FindAuditEvents returns the audit events matching filter.
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter platform.AuditEventFilter, opt ...platform.FindOptions) ([]*platform.AuditEvent, int, error) {
+	return s.FindAuditEventsFn(ctx, filter, opt...)
+}
+
+// DeleteAuditEventsBefore removes audit events recorded before t.
+func (s *AuditService) DeleteAuditEventsBefore(ctx context.Context, t time.Time) (int, error) {
+	return s.DeleteAuditEventsBeforeFn(ctx, t)
+}
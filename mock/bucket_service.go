@@ -104,3 +104,24 @@ func (s *BucketService) DeleteBucket(ctx context.Context, id platform.ID) error
 	defer s.DeleteBucketCalls.IncrFn()()
 	return s.DeleteBucketFn(ctx, id)
 }
+
+var _ platform.BucketStatsService = &BucketStatsService{}
+
+// BucketStatsService is a mock implementation of platform.BucketStatsService.
+type BucketStatsService struct {
+	BucketStatsFn func(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error)
+}
+
+// NewBucketStatsService returns a mock BucketStatsService returning empty stats.
+func NewBucketStatsService() *BucketStatsService {
+	return &BucketStatsService{
+		BucketStatsFn: func(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error) {
+			return &platform.BucketStats{OrgID: orgID, BucketID: bucketID}, nil
+		},
+	}
+}
+
+// BucketStats calls BucketStatsFn.
+func (s *BucketStatsService) BucketStats(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error) {
+	return s.BucketStatsFn(ctx, orgID, bucketID)
+}
@@ -10,7 +10,8 @@ var _ influxdb.DeleteService = &DeleteService{}
 
 // DeleteService is a mock delete server.
 type DeleteService struct {
-	DeleteBucketRangePredicateF func(tx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error
+	DeleteBucketRangePredicateF  func(tx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error
+	PreviewBucketRangePredicateF func(tx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) (*influxdb.DeleteStats, error)
 }
 
 // NewDeleteService returns a mock DeleteService where its methods will return
@@ -20,6 +21,9 @@ func NewDeleteService() DeleteService {
 		DeleteBucketRangePredicateF: func(tx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 			return nil
 		},
+		PreviewBucketRangePredicateF: func(tx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) (*influxdb.DeleteStats, error) {
+			return &influxdb.DeleteStats{}, nil
+		},
 	}
 }
 
@@ -27,3 +31,57 @@ func NewDeleteService() DeleteService {
 func (s DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
 	return s.DeleteBucketRangePredicateF(ctx, orgID, bucketID, min, max, pred)
 }
+
+//PreviewBucketRangePredicate calls PreviewBucketRangePredicateF.
+func (s DeleteService) PreviewBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) (*influxdb.DeleteStats, error) {
+	return s.PreviewBucketRangePredicateF(ctx, orgID, bucketID, min, max, pred)
+}
+
+var _ influxdb.DeleteJobService = &DeleteJobService{}
+
+// DeleteJobService is a mock delete job service.
+type DeleteJobService struct {
+	CreateDeleteJobF   func(ctx context.Context, job *influxdb.DeleteJob, pred influxdb.Predicate) error
+	FindDeleteJobByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error)
+	FindDeleteJobsF    func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error)
+	CancelDeleteJobF   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewDeleteJobService returns a mock DeleteJobService where its methods will
+// return zero values.
+func NewDeleteJobService() *DeleteJobService {
+	return &DeleteJobService{
+		CreateDeleteJobF: func(ctx context.Context, job *influxdb.DeleteJob, pred influxdb.Predicate) error {
+			return nil
+		},
+		FindDeleteJobByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+			return nil, nil
+		},
+		FindDeleteJobsF: func(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+			return nil, nil
+		},
+		CancelDeleteJobF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// CreateDeleteJob calls CreateDeleteJobF.
+func (s *DeleteJobService) CreateDeleteJob(ctx context.Context, job *influxdb.DeleteJob, pred influxdb.Predicate) error {
+	return s.CreateDeleteJobF(ctx, job, pred)
+}
+
+// FindDeleteJobByID calls FindDeleteJobByIDF.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	return s.FindDeleteJobByIDF(ctx, id)
+}
+
+// FindDeleteJobs calls FindDeleteJobsF.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	return s.FindDeleteJobsF(ctx, filter)
+}
+
+// CancelDeleteJob calls CancelDeleteJobF.
+func (s *DeleteJobService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	return s.CancelDeleteJobF(ctx, id)
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.OrgLimitService = (*OrgLimitService)(nil)
+
+// OrgLimitService is a mock implementation of platform.OrgLimitService.
+type OrgLimitService struct {
+	FindOrgLimitsFn func(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error)
+	PutOrgLimitsFn  func(ctx context.Context, l *platform.OrgLimits) error
+}
+
+// NewOrgLimitService returns a mock OrgLimitService where its methods
+// report every organization as unlimited.
+func NewOrgLimitService() *OrgLimitService {
+	return &OrgLimitService{
+		FindOrgLimitsFn: func(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error) {
+			return &platform.OrgLimits{OrgID: orgID}, nil
+		},
+		PutOrgLimitsFn: func(ctx context.Context, l *platform.OrgLimits) error {
+			return nil
+		},
+	}
+}
+
+// FindOrgLimits returns the limits of an organization.
+func (s *OrgLimitService) FindOrgLimits(ctx context.Context, orgID platform.ID) (*platform.OrgLimits, error) {
+	return s.FindOrgLimitsFn(ctx, orgID)
+}
+
+// PutOrgLimits replaces the limits of an organization.
+func (s *OrgLimitService) PutOrgLimits(ctx context.Context, l *platform.OrgLimits) error {
+	return s.PutOrgLimitsFn(ctx, l)
+}
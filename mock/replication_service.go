@@ -0,0 +1,57 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationService is a mock implementation of platform.ReplicationService.
+type ReplicationService struct {
+	ReplicationStatusFn func(ctx context.Context) (*platform.ReplicationStatus, error)
+	FindKVChangesFn     func(ctx context.Context, filter platform.KVChangeFilter) ([]*platform.KVChange, error)
+	KVSnapshotFn        func(ctx context.Context, w io.Writer) error
+	PromoteReplicaFn    func(ctx context.Context) error
+}
+
+// NewReplicationService returns a mock ReplicationService where its methods
+// will return zero values.
+func NewReplicationService() *ReplicationService {
+	return &ReplicationService{
+		ReplicationStatusFn: func(ctx context.Context) (*platform.ReplicationStatus, error) {
+			return &platform.ReplicationStatus{Role: platform.ReplicationRolePrimary}, nil
+		},
+		FindKVChangesFn: func(ctx context.Context, filter platform.KVChangeFilter) ([]*platform.KVChange, error) {
+			return nil, nil
+		},
+		KVSnapshotFn: func(ctx context.Context, w io.Writer) error {
+			return nil
+		},
+		PromoteReplicaFn: func(ctx context.Context) error {
+			return nil
+		},
+	}
+}
+
+// ReplicationStatus returns the replication state of the instance.
+func (s *ReplicationService) ReplicationStatus(ctx context.Context) (*platform.ReplicationStatus, error) {
+	return s.ReplicationStatusFn(ctx)
+}
+
+// FindKVChanges returns the changes committed after filter.After.
+func (s *ReplicationService) FindKVChanges(ctx context.Context, filter platform.KVChangeFilter) ([]*platform.KVChange, error) {
+	return s.FindKVChangesFn(ctx, filter)
+}
+
+// KVSnapshot writes a copy of the metadata store to w.
+func (s *ReplicationService) KVSnapshot(ctx context.Context, w io.Writer) error {
+	return s.KVSnapshotFn(ctx, w)
+}
+
+// PromoteReplica promotes the instance to primary.
+func (s *ReplicationService) PromoteReplica(ctx context.Context) error {
+	return s.PromoteReplicaFn(ctx)
+}
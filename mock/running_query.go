@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+)
+
+var _ influxdb.RunningQueryService = &RunningQueryService{}
+
+// RunningQueryService is a mock running query service.
+type RunningQueryService struct {
+	FindRunningQueryByIDF func(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error)
+	FindRunningQueriesF   func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error)
+	CancelRunningQueryF   func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewRunningQueryService returns a mock RunningQueryService where its
+// methods will return zero values.
+func NewRunningQueryService() *RunningQueryService {
+	return &RunningQueryService{
+		FindRunningQueryByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+			return nil, nil
+		},
+		FindRunningQueriesF: func(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+			return nil, nil
+		},
+		CancelRunningQueryF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindRunningQueryByID calls FindRunningQueryByIDF.
+func (s *RunningQueryService) FindRunningQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+	return s.FindRunningQueryByIDF(ctx, id)
+}
+
+// FindRunningQueries calls FindRunningQueriesF.
+func (s *RunningQueryService) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	return s.FindRunningQueriesF(ctx, filter)
+}
+
+// CancelRunningQuery calls CancelRunningQueryF.
+func (s *RunningQueryService) CancelRunningQuery(ctx context.Context, id influxdb.ID) error {
+	return s.CancelRunningQueryF(ctx, id)
+}
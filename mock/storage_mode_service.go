@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"context"
+
+	platform "github.com/influxdata/influxdb"
+)
+
+var _ platform.StorageModeService = (*StorageModeService)(nil)
+
+// StorageModeService is a mock implementation of platform.StorageModeService.
+type StorageModeService struct {
+	StorageModeFn    func(ctx context.Context) (*platform.StorageMode, error)
+	SetStorageModeFn func(ctx context.Context, m platform.StorageMode) (*platform.StorageMode, error)
+}
+
+// NewStorageModeService returns a mock StorageModeService where its methods
+// will return zero values.
+func NewStorageModeService() *StorageModeService {
+	return &StorageModeService{
+		StorageModeFn: func(ctx context.Context) (*platform.StorageMode, error) {
+			return &platform.StorageMode{}, nil
+		},
+		SetStorageModeFn: func(ctx context.Context, m platform.StorageMode) (*platform.StorageMode, error) {
+			return &m, nil
+		},
+	}
+}
+
+// StorageMode returns the mode of the storage engine.
+func (s *StorageModeService) StorageMode(ctx context.Context) (*platform.StorageMode, error) {
+	return s.StorageModeFn(ctx)
+}
+
+// SetStorageMode changes the mode of the storage engine.
+func (s *StorageModeService) SetStorageMode(ctx context.Context, m platform.StorageMode) (*platform.StorageMode, error) {
+	return s.SetStorageModeFn(ctx, m)
+}
@@ -0,0 +1,82 @@
+package objstore
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/pkg/fs"
+)
+
+// Dir is a Store keeping objects as files in a local directory, such as a
+// mounted network file system.
+type Dir struct {
+	path string
+}
+
+// NewDir returns a Store keeping objects in the directory path.
+func NewDir(path string) *Dir {
+	return &Dir{path: path}
+}
+
+func (d *Dir) filename(key string) string {
+	return filepath.Join(d.path, filepath.FromSlash(key))
+}
+
+// Put writes the object to a temporary file and renames it into place.
+func (d *Dir) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	name := d.filename(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if n, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	} else if n != size {
+		f.Close()
+		return io.ErrUnexpectedEOF
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return fs.RenameFileWithReplacement(f.Name(), name)
+}
+
+// ReadAt reads part of an object.
+func (d *Dir) ReadAt(ctx context.Context, key string, p []byte, off int64) error {
+	f, err := os.Open(d.filename(key))
+	if os.IsNotExist(err) {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.ReadAt(p, off); err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes an object.
+func (d *Dir) Delete(ctx context.Context, key string) error {
+	if err := os.Remove(d.filename(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// Package objstore stores immutable objects in a local directory or in an
+// S3-compatible object store.
+package objstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Store is a store of immutable objects.
+type Store interface {
+	// Put stores the size bytes read from r as the object key, replacing
+	// any object already stored as key.
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+
+	// ReadAt reads len(p) bytes of the object key starting at offset off.
+	// It returns io.ErrUnexpectedEOF if the object ends first.
+	ReadAt(ctx context.Context, key string, p []byte, off int64) error
+
+	// Delete removes the object key. Removing a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Credentials authenticate requests to an S3-compatible store.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// EnvCredentials returns the credentials in the standard AWS environment
+// variables.
+func EnvCredentials() Credentials {
+	return Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Open returns the store at rawurl, which is either a directory,
+// file:///path/to/dir, or s3://bucket/prefix. The query parameters of an s3
+// url set the endpoint of S3-compatible stores such as minio and the
+// region, e.g. s3://bucket/prefix?endpoint=http://localhost:9000&region=us-east-1.
+func Open(rawurl string, creds Credentials) (Store, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object store url: %v", err)
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		return NewDir(u.Path), nil
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("object store url %q has no bucket", rawurl)
+		}
+		q := u.Query()
+		return NewS3(S3Config{
+			Bucket:      u.Host,
+			Prefix:      strings.TrimPrefix(u.Path, "/"),
+			Endpoint:    q.Get("endpoint"),
+			Region:      q.Get("region"),
+			Credentials: creds,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported object store scheme %q", u.Scheme)
+	}
+}
@@ -0,0 +1,137 @@
+package objstore_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/influxdata/influxdb/pkg/objstore"
+)
+
+// fakeS3 is an in-memory S3 bucket supporting PUT, ranged GET and DELETE.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		http.Error(w, "<Error><Code>AccessDenied</Code><Message>unsigned</Message></Error>", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		b, _ := ioutil.ReadAll(r.Body)
+		f.objects[r.URL.Path] = b
+	case http.MethodGet:
+		b, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var start, end int
+		rng := strings.TrimPrefix(r.Header.Get("Range"), "bytes=")
+		if i := strings.Index(rng, "-"); i > 0 {
+			start, _ = strconv.Atoi(rng[:i])
+			end, _ = strconv.Atoi(rng[i+1:])
+		}
+		if start >= len(b) {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		if end >= len(b) {
+			end = len(b) - 1
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(b[start : end+1])
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func testStore(t *testing.T, s objstore.Store) {
+	ctx := context.Background()
+	data := []byte("0123456789")
+
+	if err := s.Put(ctx, "a/b.tsm", bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	p := make([]byte, 4)
+	if err := s.ReadAt(ctx, "a/b.tsm", p, 3); err != nil {
+		t.Fatal(err)
+	} else if string(p) != "3456" {
+		t.Fatalf("ReadAt = %q, want %q", p, "3456")
+	}
+	if err := s.ReadAt(ctx, "a/b.tsm", p, 8); err != io.ErrUnexpectedEOF {
+		t.Fatalf("ReadAt past end: got %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+
+	if err := s.Delete(ctx, "a/b.tsm"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReadAt(ctx, "a/b.tsm", p, 0); err != objstore.ErrNotFound {
+		t.Fatalf("ReadAt deleted object: got %v, want %v", err, objstore.ErrNotFound)
+	}
+	if err := s.Delete(ctx, "a/b.tsm"); err != nil {
+		t.Fatalf("Delete missing object: %v", err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "objstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s, err := objstore.Open("file://"+dir, objstore.Credentials{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	ts := httptest.NewServer(fake)
+	defer ts.Close()
+
+	s, err := objstore.Open("s3://bucket/prefix?endpoint="+ts.URL, objstore.Credentials{
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+
+	if err := s.Put(context.Background(), "x", strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.objects["/bucket/prefix/x"]; !ok {
+		t.Fatalf("object not stored at path-style url: %v", fake.objects)
+	}
+
+	unsigned, err := objstore.Open("s3://bucket?endpoint="+ts.URL, objstore.Credentials{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = unsigned.Delete(context.Background(), "x")
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Fatalf("unsigned request: got %v, want access denied", err)
+	}
+}
@@ -0,0 +1,311 @@
+package objstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultS3Region is the region used when none is configured.
+	DefaultS3Region = "us-east-1"
+
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+)
+
+// S3Config configures an S3 store.
+type S3Config struct {
+	Bucket string
+	Prefix string // prepended to every key
+
+	// Endpoint is the base url of an S3-compatible store such as minio.
+	// Requests to an endpoint use path-style addressing. When empty, the
+	// AWS endpoint of Region is used with virtual-hosted-style addressing.
+	Endpoint string
+	Region   string
+
+	Credentials Credentials
+	Client      *http.Client
+}
+
+// defaultS3Client is used when S3Config has no client. Unlike
+// http.DefaultClient it gives up on a store that does not accept a connection
+// or answer a request in time. Requests have no overall time limit, as
+// uploads may be large; their contexts bound them instead.
+var defaultS3Client = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+		ExpectContinueTimeout: time.Second,
+	},
+}
+
+// S3 is a Store backed by an S3-compatible object store. Requests are signed
+// with AWS Signature Version 4.
+type S3 struct {
+	bucket    string
+	prefix    string
+	base      *url.URL
+	pathStyle bool
+	region    string
+	creds     Credentials
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3 returns a Store backed by the S3 bucket in c.
+func NewS3(c S3Config) (*S3, error) {
+	s := &S3{
+		bucket: c.Bucket,
+		prefix: strings.Trim(c.Prefix, "/"),
+		region: c.Region,
+		creds:  c.Credentials,
+		client: c.Client,
+		now:    time.Now,
+	}
+	if s.region == "" {
+		s.region = DefaultS3Region
+	}
+	if s.client == nil {
+		s.client = defaultS3Client
+	}
+
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", c.Bucket, s.region)
+	} else {
+		s.pathStyle = true
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpoint)
+	}
+	s.base = u
+	return s, nil
+}
+
+// url returns the url of the object key.
+func (s *S3) url(key string) *url.URL {
+	p := key
+	if s.prefix != "" {
+		p = s.prefix + "/" + key
+	}
+	if s.pathStyle {
+		p = s.bucket + "/" + p
+	}
+	u := *s.base
+	u.Path = path.Join("/", u.Path, p)
+	return &u
+}
+
+// Put uploads an object with a single PUT request.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64) error {
+	req, err := http.NewRequest(http.MethodPut, s.url(key).String(), ioutil.NopCloser(r))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+
+	resp, err := s.do(ctx, req, unsignedPayload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(resp, key)
+	}
+	return nil
+}
+
+// ReadAt reads part of an object with a ranged GET request.
+func (s *S3) ReadAt(ctx context.Context, key string, p []byte, off int64) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.url(key).String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	resp, err := s.do(ctx, req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The store ignored the range and returned the whole object.
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, off); err != nil {
+			return io.ErrUnexpectedEOF
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		return io.ErrUnexpectedEOF
+	default:
+		return s.responseError(resp, key)
+	}
+
+	if _, err := io.ReadFull(resp.Body, p); err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete removes an object.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequest(http.MethodDelete, s.url(key).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, req, emptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s.responseError(resp, key)
+	}
+}
+
+func (s *S3) do(ctx context.Context, req *http.Request, payloadHash string) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	s.sign(req, payloadHash, s.now().UTC())
+	return s.client.Do(req)
+}
+
+// responseError returns the error described by an S3 error response.
+func (s *S3) responseError(resp *http.Response, key string) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := xml.Unmarshal(b, &body); err != nil || body.Code == "" {
+		return fmt.Errorf("object %q: unexpected status %s", key, resp.Status)
+	}
+	return fmt.Errorf("object %q: %s: %s", key, body.Code, body.Message)
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req. Requests
+// are unsigned when no access key is configured.
+func (s *S3) sign(req *http.Request, payloadHash string, t time.Time) {
+	amzDate := t.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.SessionToken)
+	}
+	if s.creds.AccessKeyID == "" {
+		return
+	}
+
+	// Sign the host and every x-amz-* and range header.
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		k = strings.ToLower(k)
+		if strings.HasPrefix(k, "x-amz-") || k == "range" || k == "content-type" {
+			headers[k] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := amzDate[:8]
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.creds.SecretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), q[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape percent-encodes s as required by Signature Version 4.
+func escape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -13,6 +13,7 @@ type LogicalOperator int
 // LogicalOperators
 var (
 	LogicalAnd LogicalOperator = 1
+	LogicalOr  LogicalOperator = 2
 )
 
 // Value returns the node logical type.
@@ -20,6 +21,8 @@ func (op LogicalOperator) Value() (datatypes.Node_Logical, error) {
 	switch op {
 	case LogicalAnd:
 		return datatypes.LogicalAnd, nil
+	case LogicalOr:
+		return datatypes.LogicalOr, nil
 	default:
 		return 0, &influxdb.Error{
 			Code: influxdb.EInvalid,
@@ -2,7 +2,9 @@ package predicate
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxql"
@@ -20,12 +22,21 @@ type buffer [3]struct {
 // to the predicate node
 type parser struct {
 	sc        *influxql.Scanner
-	i         int // buffer index
-	n         int // buffer size
+	src       []rune // the statement, to scan regexes from
+	offset    int    // position of the scanner in src
+	i         int    // buffer index
+	n         int    // buffer size
 	openParen int
 	buf       buffer
 }
 
+func newParser(sts string) *parser {
+	return &parser{
+		sc:  influxql.NewScanner(strings.NewReader(sts)),
+		src: []rune(sts),
+	}
+}
+
 // scan returns the next token from the underlying scanner.
 // If a token has been unscanned then read that instead.
 func (p *parser) scan() (tok influxql.Token, pos influxql.Pos, lit string) {
@@ -39,6 +50,7 @@ func (p *parser) scan() (tok influxql.Token, pos influxql.Pos, lit string) {
 	p.i = (p.i + 1) % len(p.buf)
 	buf := &p.buf[p.i]
 	buf.tok, buf.pos, buf.lit = p.sc.Scan()
+	buf.pos.Char += p.offset
 
 	return p.curr()
 }
@@ -63,93 +75,113 @@ func (p *parser) scanIgnoreWhitespace() (tok influxql.Token, pos influxql.Pos, l
 }
 
 // Parse the predicate statement.
+//
+// AND binds tighter than OR, and parentheses may be nested to any depth.
 func Parse(sts string) (n Node, err error) {
 	if sts == "" {
 		return nil, nil
 	}
-	p := new(parser)
-	p.sc = influxql.NewScanner(strings.NewReader(sts))
-	return p.parseLogicalNode()
+	p := newParser(sts)
+	n, err = p.parseLogicalNode()
+	if err != nil {
+		return nil, err
+	}
+	if tok, pos, _ := p.scanIgnoreWhitespace(); tok == influxql.RPAREN {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("extra ) seen"),
+		}
+	} else if tok != influxql.EOF {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
+		}
+	}
+	return n, nil
 }
 
+// parseLogicalNode parses expressions joined by OR.
 func (p *parser) parseLogicalNode() (Node, error) {
-	n := new(LogicalNode)
-	for {
-		tok, pos, _ := p.scanIgnoreWhitespace()
-		switch tok {
-		case influxql.NUMBER, influxql.INTEGER, influxql.NAME, influxql.IDENT:
-			p.unscan()
-			tr, err := p.parseTagRuleNode()
-			if err != nil {
-				return *n, err
-			}
-			if n.Children[0] == nil {
-				n.Children[0] = tr
-			} else {
-				n.Children[1] = tr
-			}
-		case influxql.AND:
-			n.Operator = LogicalAnd
-			if n.Children[1] == nil {
-				continue
-			}
-			var n1 Node
-			var err error
-			if tokNext := p.peekTok(); tokNext == influxql.LPAREN {
-				n1, err = p.parseLogicalNode()
-			} else {
-				n1, err = p.parseTagRuleNode()
-			}
-			if err != nil {
-				return *n, err
-			}
-			n = &LogicalNode{
-				Children: [2]Node{*n, n1},
-				Operator: LogicalAnd,
-			}
-		case influxql.OR:
-			return *n, &influxdb.Error{
+	n, err := p.parseAndNode()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekTok() == influxql.OR {
+		p.scanIgnoreWhitespace()
+		n1, err := p.parseAndNode()
+		if err != nil {
+			return nil, err
+		}
+		n = LogicalNode{
+			Children: [2]Node{n, n1},
+			Operator: LogicalOr,
+		}
+	}
+	return n, nil
+}
+
+// parseAndNode parses expressions joined by AND.
+func (p *parser) parseAndNode() (Node, error) {
+	n, err := p.parsePrimaryNode()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekTok() == influxql.AND {
+		p.scanIgnoreWhitespace()
+		n1, err := p.parsePrimaryNode()
+		if err != nil {
+			return nil, err
+		}
+		n = LogicalNode{
+			Children: [2]Node{n, n1},
+			Operator: LogicalAnd,
+		}
+	}
+	return n, nil
+}
+
+// parsePrimaryNode parses a tag rule or a parenthesized expression.
+func (p *parser) parsePrimaryNode() (Node, error) {
+	tok, pos, _ := p.scanIgnoreWhitespace()
+	switch tok {
+	case influxql.NUMBER, influxql.INTEGER, influxql.NAME, influxql.IDENT:
+		p.unscan()
+		return p.parseTagRuleNode()
+	case influxql.LPAREN:
+		p.openParen++
+		n, err := p.parseLogicalNode()
+		if err != nil {
+			return nil, err
+		}
+		if tok, pos, _ := p.scanIgnoreWhitespace(); tok == influxql.EOF {
+			return nil, &influxdb.Error{
 				Code: influxdb.EInvalid,
-				Msg:  fmt.Sprintf("the logical operator OR is not supported yet at position %d", pos.Char),
+				Msg:  fmt.Sprintf("extra ( seen"),
 			}
-		case influxql.LPAREN:
-			p.openParen++
-			currParen := p.openParen
-			n1, err := p.parseLogicalNode()
-			if err != nil {
-				return *n, err
-			}
-			if p.openParen != currParen-1 {
-				return *n, &influxdb.Error{
-					Code: influxdb.EInvalid,
-					Msg:  fmt.Sprintf("extra ( seen"),
-				}
-			}
-			if n.Children[0] == nil {
-				n.Children[0] = n1
-			} else {
-				n.Children[1] = n1
-			}
-		case influxql.RPAREN:
-			p.openParen--
-			fallthrough
-		case influxql.EOF:
-			if p.openParen < 0 {
-				return *n, &influxdb.Error{
-					Code: influxdb.EInvalid,
-					Msg:  fmt.Sprintf("extra ) seen"),
-				}
-			}
-			if n.Children[1] == nil {
-				return n.Children[0], nil
-			}
-			return *n, nil
-		default:
-			return *n, &influxdb.Error{
+		} else if tok != influxql.RPAREN {
+			return nil, &influxdb.Error{
 				Code: influxdb.EInvalid,
 				Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
 			}
 		}
+		p.openParen--
+		return n, nil
+	case influxql.EOF:
+		if p.openParen > 0 {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  fmt.Sprintf("extra ( seen"),
+			}
+		}
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "incomplete logical expression",
+		}
+	default:
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad logical expression, at position %d", pos.Char),
+		}
 	}
 }
 
@@ -178,18 +210,25 @@ func (p *parser) parseTagRuleNode() (TagRuleNode, error) {
 		n.Operator = influxdb.NotEqual
 		goto scanRegularTagValue
 	case influxql.EQREGEX:
-		fallthrough
+		n.Operator = influxdb.RegexEqual
+		goto scanRegexTagValue
 	case influxql.NEQREGEX:
-		return *n, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Msg:  fmt.Sprintf("operator: %q at position: %d is not supported yet", tok.String(), pos.Char),
-		}
+		n.Operator = influxdb.NotRegexEqual
+		goto scanRegexTagValue
 	default:
 		return *n, &influxdb.Error{
 			Code: influxdb.EInvalid,
 			Msg:  fmt.Sprintf("invalid operator %q at position: %d", tok.String(), pos.Char),
 		}
 	}
+	// scan the regex, which starts right after the operator
+scanRegexTagValue:
+	{
+		re, err := p.scanRegex(pos.Char + len(tok.String()))
+		n.Value = re
+		return *n, err
+	}
+
 	// scan the value
 scanRegularTagValue:
 	tok, pos, lit = p.scanIgnoreWhitespace()
@@ -220,6 +259,38 @@ scanRegularTagValue:
 	}
 }
 
+// scanRegex scans the /regex/ literal starting at position start, and
+// continues scanning after it. The influxql scanner cannot scan a regex once
+// the whitespace before it has been scanned, so it is scanned from the
+// statement instead.
+func (p *parser) scanRegex(start int) (string, error) {
+	for start < len(p.src) && (p.src[start] == ' ' || p.src[start] == '\t' || p.src[start] == '\n') {
+		start++
+	}
+	r := strings.NewReader(string(p.src[start:]))
+	b, err := influxql.ScanDelimited(r, '/', '/', map[rune]rune{'/': '/'}, true)
+	if err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad regex, at position %d", start),
+			Err:  err,
+		}
+	}
+	if _, err := regexp.Compile(string(b)); err != nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("bad regex, at position %d", start),
+			Err:  err,
+		}
+	}
+
+	rest := string(p.src[start:])[int(r.Size())-r.Len():]
+	p.offset = len(p.src) - utf8.RuneCountInString(rest)
+	p.sc = influxql.NewScanner(strings.NewReader(rest))
+	p.n = 0
+	return string(b), nil
+}
+
 // peekRune returns the next rune that would be read by the scanner.
 func (p *parser) peekTok() influxql.Token {
 	tok, _, _ := p.scanIgnoreWhitespace()
@@ -72,6 +72,94 @@ func TestDataTypeConversion(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "regex tag rule",
+			node: &TagRuleNode{
+				Operator: influxdb.RegexEqual,
+				Tag: influxdb.Tag{
+					Key:   "_field",
+					Value: "^usage",
+				},
+			},
+			dataType: &datatypes.Node{
+				NodeType: datatypes.NodeTypeComparisonExpression,
+				Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonRegex},
+				Children: []*datatypes.Node{
+					{
+						NodeType: datatypes.NodeTypeTagRef,
+						Value:    &datatypes.Node_TagRefValue{TagRefValue: models.FieldKeyTagKey},
+					},
+					{
+						NodeType: datatypes.NodeTypeLiteral,
+						Value: &datatypes.Node_RegexValue{
+							RegexValue: "^usage",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "or logical",
+			node: &LogicalNode{
+				Operator: LogicalOr,
+				Children: [2]Node{
+					&TagRuleNode{
+						Operator: influxdb.Equal,
+						Tag: influxdb.Tag{
+							Key:   "k1",
+							Value: "v1",
+						},
+					},
+					&TagRuleNode{
+						Operator: influxdb.NotRegexEqual,
+						Tag: influxdb.Tag{
+							Key:   "k2",
+							Value: "v2",
+						},
+					},
+				},
+			},
+			dataType: &datatypes.Node{
+				NodeType: datatypes.NodeTypeLogicalExpression,
+				Value: &datatypes.Node_Logical_{
+					Logical: datatypes.LogicalOr,
+				},
+				Children: []*datatypes.Node{
+					{
+						NodeType: datatypes.NodeTypeComparisonExpression,
+						Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+						Children: []*datatypes.Node{
+							{
+								NodeType: datatypes.NodeTypeTagRef,
+								Value:    &datatypes.Node_TagRefValue{TagRefValue: "k1"},
+							},
+							{
+								NodeType: datatypes.NodeTypeLiteral,
+								Value: &datatypes.Node_StringValue{
+									StringValue: "v1",
+								},
+							},
+						},
+					},
+					{
+						NodeType: datatypes.NodeTypeComparisonExpression,
+						Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonNotRegex},
+						Children: []*datatypes.Node{
+							{
+								NodeType: datatypes.NodeTypeTagRef,
+								Value:    &datatypes.Node_TagRefValue{TagRefValue: "k2"},
+							},
+							{
+								NodeType: datatypes.NodeTypeLiteral,
+								Value: &datatypes.Node_RegexValue{
+									RegexValue: "v2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "measurement equal tag rule",
 			node: &TagRuleNode{
@@ -0,0 +1,213 @@
+package arrow
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	fluxmemory "github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/query"
+)
+
+// MultiResultDecoder decodes results encoded as Arrow IPC streams.
+type MultiResultDecoder struct{}
+
+// NewMultiResultDecoder returns a decoder of results.
+func NewMultiResultDecoder() *MultiResultDecoder {
+	return &MultiResultDecoder{}
+}
+
+// Decode reads all results from r and closes it. An error encoded in the
+// results is reported by the Err method of the iterator.
+func (d *MultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	defer r.Close()
+
+	var (
+		br      = bufio.NewReader(r)
+		alloc   = &fluxmemory.Allocator{}
+		results []flux.Result
+		// tables are the tables of the last result.
+		tables []flux.Table
+		name   string
+	)
+	endResult := func() {
+		if tables != nil {
+			results = append(results, query.NewBufferedResult(name, tables))
+		}
+		tables = nil
+	}
+	for {
+		if _, err := br.Peek(1); err == io.EOF {
+			break
+		}
+		sr, err := ipc.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		md := sr.Schema().Metadata()
+		if i := md.FindKey(ErrorKey); i >= 0 {
+			sr.Release()
+			endResult()
+			return query.NewBufferedResultIterator(results, errors.New(md.Values()[i])), nil
+		}
+		result := ""
+		if i := md.FindKey(ResultKey); i >= 0 {
+			result = md.Values()[i]
+		}
+		// The index of the first table of a result is 0.
+		first := true
+		if i := md.FindKey(TableKey); i >= 0 {
+			first = md.Values()[i] == "0"
+		}
+		if tables == nil || first || result != name {
+			endResult()
+			name = result
+		}
+
+		tbl, err := decodeTable(sr, alloc)
+		sr.Release()
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+	endResult()
+	return query.NewBufferedResultIterator(results, nil), nil
+}
+
+// decodeTable reads the records of a stream into a table.
+func decodeTable(sr *ipc.Reader, alloc *fluxmemory.Allocator) (flux.Table, error) {
+	schema := sr.Schema()
+	cols := make([]flux.ColMeta, len(schema.Fields()))
+	for j, f := range schema.Fields() {
+		typ, err := columnType(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %v", f.Name, err)
+		}
+		cols[j] = flux.ColMeta{Label: f.Name, Type: typ}
+	}
+
+	var b *execute.ColListTableBuilder
+	for sr.Next() {
+		rec := sr.Record()
+		if b == nil {
+			// The group key is the first row of the key columns.
+			var (
+				keyCols   []flux.ColMeta
+				keyValues []values.Value
+			)
+			for j, f := range schema.Fields() {
+				if i := f.Metadata.FindKey(GroupKey); i < 0 || f.Metadata.Values()[i] != "true" {
+					continue
+				}
+				keyCols = append(keyCols, cols[j])
+				keyValues = append(keyValues, valueAt(rec.Column(j), 0, cols[j].Type))
+			}
+			b = execute.NewColListTableBuilder(execute.NewGroupKey(keyCols, keyValues), alloc)
+			for _, c := range cols {
+				if _, err := b.AddCol(c); err != nil {
+					return nil, err
+				}
+			}
+		}
+		for j := range cols {
+			if err := appendColumn(b, j, rec.Column(j)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := sr.Err(); err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, errors.New("stream of a table has no records")
+	}
+	return b.Table()
+}
+
+func columnType(dt arrow.DataType) (flux.ColType, error) {
+	switch dt.ID() {
+	case arrow.BOOL:
+		return flux.TBool, nil
+	case arrow.INT64:
+		return flux.TInt, nil
+	case arrow.UINT64:
+		return flux.TUInt, nil
+	case arrow.FLOAT64:
+		return flux.TFloat, nil
+	case arrow.STRING:
+		return flux.TString, nil
+	case arrow.TIMESTAMP:
+		if t := dt.(*arrow.TimestampType); t.Unit == arrow.Nanosecond {
+			return flux.TTime, nil
+		}
+	}
+	return flux.TInvalid, fmt.Errorf("unsupported data type %v", dt)
+}
+
+// appendColumn appends the values of arr to column j of b. Strings and
+// times are read with the types the builder expects.
+func appendColumn(b *execute.ColListTableBuilder, j int, arr array.Interface) error {
+	switch a := arr.(type) {
+	case *array.Boolean:
+		// AppendBools of the builder misplaces nulls.
+		for i := 0; i < a.Len(); i++ {
+			var err error
+			if a.IsNull(i) {
+				err = b.AppendNil(j)
+			} else {
+				err = b.AppendBool(j, a.Value(i))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case *array.Int64:
+		return b.AppendInts(j, a)
+	case *array.Uint64:
+		return b.AppendUInts(j, a)
+	case *array.Float64:
+		return b.AppendFloats(j, a)
+	case *array.String:
+		vs := withType(a, arrow.BinaryTypes.Binary)
+		defer vs.Release()
+		return b.AppendStrings(j, vs.(*array.Binary))
+	case *array.Timestamp:
+		vs := withType(a, arrow.PrimitiveTypes.Int64)
+		defer vs.Release()
+		return b.AppendTimes(j, vs.(*array.Int64))
+	default:
+		return fmt.Errorf("unsupported array %T", arr)
+	}
+}
+
+// valueAt returns the value at row i of arr.
+func valueAt(arr array.Interface, i int, typ flux.ColType) values.Value {
+	if arr.IsNull(i) {
+		return values.NewNull(flux.SemanticType(typ))
+	}
+	switch a := arr.(type) {
+	case *array.Boolean:
+		return values.NewBool(a.Value(i))
+	case *array.Int64:
+		return values.NewInt(a.Value(i))
+	case *array.Uint64:
+		return values.NewUInt(a.Value(i))
+	case *array.Float64:
+		return values.NewFloat(a.Value(i))
+	case *array.String:
+		return values.NewString(a.Value(i))
+	case *array.Timestamp:
+		return values.NewTime(values.Time(a.Value(i)))
+	default:
+		return values.NewNull(flux.SemanticType(typ))
+	}
+}
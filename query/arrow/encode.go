@@ -0,0 +1,220 @@
+// Package arrow encodes query results in the Apache Arrow IPC streaming
+// format, which data frame libraries such as pandas load without parsing.
+//
+// Every table of the results is a stream of its own, with a schema and the
+// record batches of the table, and the streams follow each other. The
+// metadata of a schema holds the name of the result and the index of the
+// table within it, and the metadata of a field marks the group key columns.
+// Strings are UTF-8 and times are UTC timestamps in nanoseconds.
+package arrow
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+)
+
+const DialectType = "arrow"
+
+// Metadata keys of the schemas and fields.
+const (
+	// ResultKey is the name of the result of a table.
+	ResultKey = "flux.result"
+	// TableKey is the index of a table within its result.
+	TableKey = "flux.table"
+	// ErrorKey is the error that ended the results. It is set on the
+	// schema of a last stream with no fields.
+	ErrorKey = "flux.error"
+	// GroupKey is set to "true" on the fields of the group key columns.
+	GroupKey = "flux.group"
+)
+
+// AddDialectMappings adds the mapping for the Arrow dialect.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	return mappings.Add(DialectType, func() flux.Dialect {
+		return new(Dialect)
+	})
+}
+
+// Dialect is the Apache Arrow IPC streaming dialect of query results.
+type Dialect struct{}
+
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	return NewMultiResultEncoder()
+}
+
+func (d *Dialect) DialectType() flux.DialectType {
+	return DialectType
+}
+
+func (d *Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/vnd.apache.arrow.stream")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// MultiResultEncoder encodes results as Arrow IPC streams, one per table.
+// Tables without rows are not encoded.
+//
+// An error met before anything is written is returned as is. Later errors
+// are encoded in a last stream with ErrorKey set on its schema.
+type MultiResultEncoder struct {
+	mem memory.Allocator
+}
+
+// NewMultiResultEncoder returns an encoder of Arrow IPC streams.
+func NewMultiResultEncoder() *MultiResultEncoder {
+	return &MultiResultEncoder{mem: memory.DefaultAllocator}
+}
+
+type flusher interface {
+	Flush()
+}
+
+func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	wc := &iocounter.Writer{Writer: w}
+	for results.More() {
+		res := results.Next()
+		table := 0
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			if tbl.Empty() {
+				tbl.Done()
+				return nil
+			}
+			if err := e.encodeTable(wc, res.Name(), table, tbl); err != nil {
+				return err
+			}
+			table++
+			if f, ok := w.(flusher); ok {
+				f.Flush()
+			}
+			return nil
+		}); err != nil {
+			return e.fail(wc, err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return e.fail(wc, err)
+	}
+	return wc.Count(), nil
+}
+
+// encodeTable writes tbl as a stream. The stream is ended even if reading
+// the table fails, so that the error can follow it.
+func (e *MultiResultEncoder) encodeTable(w io.Writer, result string, table int, tbl flux.Table) error {
+	schema := newSchema(result, table, tbl.Key(), tbl.Cols())
+
+	var sw *ipc.Writer
+	err := tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() == 0 {
+			return nil
+		}
+		if sw == nil {
+			sw = ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(e.mem))
+		}
+		rec := newRecord(schema, cr)
+		defer rec.Release()
+		return sw.Write(rec)
+	})
+	if sw != nil {
+		if cerr := sw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// fail returns err if nothing was written, and encodes it otherwise.
+func (e *MultiResultEncoder) fail(wc *iocounter.Writer, err error) (int64, error) {
+	if wc.Count() == 0 {
+		return 0, err
+	}
+	md := arrow.NewMetadata([]string{ErrorKey}, []string{err.Error()})
+	sw := ipc.NewWriter(wc, ipc.WithSchema(arrow.NewSchema(nil, &md)), ipc.WithAllocator(e.mem))
+	if cerr := sw.Close(); cerr != nil {
+		return wc.Count(), cerr
+	}
+	return wc.Count(), err
+}
+
+func newSchema(result string, table int, key flux.GroupKey, cols []flux.ColMeta) *arrow.Schema {
+	fields := make([]arrow.Field, len(cols))
+	for j, c := range cols {
+		fields[j] = arrow.Field{
+			Name:     c.Label,
+			Type:     dataType(c.Type),
+			Nullable: true,
+		}
+		if key.HasCol(c.Label) {
+			fields[j].Metadata = arrow.NewMetadata([]string{GroupKey}, []string{"true"})
+		}
+	}
+	md := arrow.NewMetadata(
+		[]string{ResultKey, TableKey},
+		[]string{result, strconv.Itoa(table)},
+	)
+	return arrow.NewSchema(fields, &md)
+}
+
+func dataType(t flux.ColType) arrow.DataType {
+	switch t {
+	case flux.TBool:
+		return arrow.FixedWidthTypes.Boolean
+	case flux.TInt:
+		return arrow.PrimitiveTypes.Int64
+	case flux.TUInt:
+		return arrow.PrimitiveTypes.Uint64
+	case flux.TFloat:
+		return arrow.PrimitiveTypes.Float64
+	case flux.TString:
+		return arrow.BinaryTypes.String
+	case flux.TTime:
+		return arrow.FixedWidthTypes.Timestamp_ns
+	default:
+		return arrow.Null
+	}
+}
+
+// newRecord returns the columns of cr as a record of schema. Strings and
+// times share the buffers of cr with the types of the schema.
+func newRecord(schema *arrow.Schema, cr flux.ColReader) array.Record {
+	cols := make([]array.Interface, len(cr.Cols()))
+	for j, c := range cr.Cols() {
+		var arr array.Interface
+		switch c.Type {
+		case flux.TBool:
+			arr = cr.Bools(j)
+		case flux.TInt:
+			arr = cr.Ints(j)
+		case flux.TUInt:
+			arr = cr.UInts(j)
+		case flux.TFloat:
+			arr = cr.Floats(j)
+		case flux.TString:
+			arr = cr.Strings(j)
+		case flux.TTime:
+			arr = cr.Times(j)
+		default:
+			arr = array.NewNull(cr.Len())
+			defer arr.Release()
+		}
+		cols[j] = withType(arr, schema.Field(j).Type)
+		defer cols[j].Release()
+	}
+	return array.NewRecord(schema, cols, int64(cr.Len()))
+}
+
+// withType returns the data of arr as an array of type dt, which has the
+// same layout. Flux reads strings as binary arrays whatever their type.
+func withType(arr array.Interface, dt arrow.DataType) array.Interface {
+	data := arr.Data()
+	return array.MakeFromData(array.NewData(dt, data.Len(), data.Buffers(), nil, data.NullN(), data.Offset()))
+}
@@ -0,0 +1,127 @@
+package arrow_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	apachearrow "github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/arrow"
+)
+
+// newResults returns two results, the second of which fails if err is set.
+// Tables without rows are left out since they are not encoded.
+func newResults(err error) []flux.Result {
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+		{Label: "host", Type: flux.TString},
+		{Label: "n", Type: flux.TInt},
+		{Label: "u", Type: flux.TUInt},
+		{Label: "ok", Type: flux.TBool},
+	}
+	first := executetest.NewResult([]*executetest.Table{
+		{
+			KeyCols: []string{"host"},
+			ColMeta: cols,
+			Data: [][]interface{}{
+				{execute.Time(0), 1.5, "a", int64(-1), uint64(1), true},
+				{execute.Time(1500000000), nil, "a", int64(2), nil, false},
+			},
+		},
+		{
+			KeyCols: []string{"host"},
+			ColMeta: cols,
+			Data: [][]interface{}{
+				{execute.Time(0), 2.0, "b", int64(3), uint64(3), nil},
+			},
+		},
+	})
+	first.Nm = "first"
+	second := executetest.NewResult([]*executetest.Table{{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TString}},
+		Data:    [][]interface{}{{"x"}},
+		Err:     err,
+	}})
+	second.Nm = "second"
+	return []flux.Result{first, second}
+}
+
+func encode(t *testing.T, results []flux.Result) ([]byte, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	_, err := arrow.NewMultiResultEncoder().Encode(&buf, flux.NewSliceResultIterator(results))
+	return buf.Bytes(), err
+}
+
+func TestMultiResultEncoder(t *testing.T) {
+	data, err := encode(t, newResults(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first stream is the first table.
+	r, err := ipc.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	schema := r.Schema()
+	if got, want := schema.Metadata().String(), apachearrow.NewMetadata(
+		[]string{arrow.ResultKey, arrow.TableKey},
+		[]string{"first", "0"},
+	).String(); got != want {
+		t.Errorf("unexpected schema metadata %s, want %s", got, want)
+	}
+	for _, want := range []apachearrow.Field{
+		{Name: "_time", Type: apachearrow.FixedWidthTypes.Timestamp_ns, Nullable: true},
+		{Name: "host", Type: apachearrow.BinaryTypes.String, Nullable: true, Metadata: apachearrow.NewMetadata([]string{arrow.GroupKey}, []string{"true"})},
+	} {
+		if f, ok := schema.FieldByName(want.Name); !ok || !f.Equal(want) {
+			t.Errorf("unexpected field %v, want %v", f, want)
+		}
+	}
+	if !r.Next() || r.Record().NumRows() != 2 {
+		t.Fatal("expected a record of 2 rows")
+	}
+
+	itr, err := arrow.NewMultiResultDecoder().Decode(ioutil.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := executetest.EqualResultIterators(flux.NewSliceResultIterator(newResults(nil)), itr); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMultiResultEncoder_Errors(t *testing.T) {
+	expErr := errors.New("expected error")
+
+	// Errors before any output are returned.
+	data, err := encode(t, newResults(expErr)[1:])
+	if err != expErr {
+		t.Errorf("expected error %v, got %v", expErr, err)
+	}
+	if len(data) != 0 {
+		t.Errorf("expected no output, got %d bytes", len(data))
+	}
+
+	data, err = encode(t, newResults(expErr))
+	if err != expErr {
+		t.Errorf("expected error %v, got %v", expErr, err)
+	}
+	itr, err := arrow.NewMultiResultDecoder().Decode(ioutil.NopCloser(bytes.NewReader(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := query.NewBufferedResultIterator(newResults(nil)[:1], expErr)
+	if err := executetest.EqualResultIterators(want, itr); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+)
+
+// safeImports are the packages a cached query may import. The others
+// write data, read from outside the storage engine or read buckets
+// without a call to from in the query.
+var safeImports = map[string]bool{
+	"date":    true,
+	"math":    true,
+	"regexp":  true,
+	"strings": true,
+}
+
+// bucketFuncs are the functions reading or writing buckets.
+var bucketFuncs = map[string]bool{
+	"buckets": true,
+	"from":    true,
+	"to":      true,
+}
+
+// analysis describes the data a query reads.
+type analysis struct {
+	// text is the formatted query and its extern.
+	text string
+
+	// buckets are the names of the buckets read by the query and
+	// bucketIDs their IDs.
+	buckets   []string
+	bucketIDs []string
+
+	// start and stop bound the time range read by the query. A zero
+	// time is unbounded.
+	start, stop time.Time
+}
+
+// analyze returns what the query of c reads when it runs at now. It
+// returns false if the results of the query should not be cached.
+func analyze(c lang.FluxCompiler, now time.Time) (*analysis, bool) {
+	pkg, err := flux.Parse(c.Query)
+	if err != nil {
+		return nil, false
+	}
+
+	a := &analysis{text: ast.Format(pkg)}
+	env := make(map[string]ast.Expression)
+	files := pkg.Files
+	if c.Extern != nil {
+		a.text = ast.Format(c.Extern) + "\n" + a.text
+		files = append([]*ast.File{c.Extern}, files...)
+	}
+	for _, f := range files {
+		for _, imp := range f.Imports {
+			if !safeImports[imp.Path.Value] {
+				return nil, false
+			}
+		}
+		for _, stmt := range f.Body {
+			addAssignment(env, stmt)
+		}
+	}
+
+	ok := true
+	ranged, unbounded := false, false
+	// callees are the identifiers called directly. Any other reference
+	// to from, to or buckets, such as an alias or an argument, may call
+	// them in a way the analysis cannot see.
+	callees := make(map[*ast.Identifier]bool)
+	visit := ast.CreateVisitor(func(n ast.Node) {
+		if !ok {
+			return
+		}
+		if id, isIdent := n.(*ast.Identifier); isIdent {
+			if bucketFuncs[id.Name] && !callees[id] {
+				ok = false
+			}
+			return
+		}
+		call, isCall := n.(*ast.CallExpression)
+		if !isCall {
+			return
+		}
+		callee, isIdent := call.Callee.(*ast.Identifier)
+		if !isIdent {
+			return
+		}
+		callees[callee] = true
+		args := arguments(call)
+		switch callee.Name {
+		case "to", "buckets":
+			// to writes data and the bucket list depends on the
+			// authorization.
+			ok = false
+		case "from":
+			for k := range args {
+				if k != "bucket" && k != "bucketID" {
+					// Queries of another organization or host.
+					ok = false
+					return
+				}
+			}
+			if b, isString := resolve(env, args["bucket"]).(*ast.StringLiteral); isString {
+				a.buckets = append(a.buckets, b.Value)
+			} else if id, isString := resolve(env, args["bucketID"]).(*ast.StringLiteral); isString {
+				a.bucketIDs = append(a.bucketIDs, id.Value)
+			} else {
+				ok = false
+			}
+		case "range":
+			start, stop := evalTime(env, args["start"], now), now
+			if e, found := args["stop"]; found {
+				stop = evalTime(env, e, now)
+			}
+			if start.IsZero() || stop.IsZero() {
+				unbounded = true
+				return
+			}
+			if !ranged || start.Before(a.start) {
+				a.start = start
+			}
+			if !ranged || stop.After(a.stop) {
+				a.stop = stop
+			}
+			ranged = true
+		}
+	})
+	for _, f := range files {
+		ast.Walk(visit, f)
+	}
+	if !ok || len(a.buckets)+len(a.bucketIDs) == 0 {
+		// Without buckets the cached result could not be authorized.
+		return nil, false
+	}
+	if !ranged || unbounded {
+		a.start, a.stop = time.Time{}, time.Time{}
+	}
+	return a, true
+}
+
+// addAssignment records the value of variables and options in env.
+func addAssignment(env map[string]ast.Expression, stmt ast.Statement) {
+	if o, ok := stmt.(*ast.OptionStatement); ok {
+		stmt = o.Assignment
+	}
+	switch s := stmt.(type) {
+	case *ast.VariableAssignment:
+		env[s.ID.Name] = s.Init
+	case *ast.MemberAssignment:
+		if obj, ok := s.Member.Object.(*ast.Identifier); ok {
+			env[obj.Name+"."+s.Member.Property.Key()] = s.Init
+		}
+	}
+}
+
+// arguments returns the named arguments of a call.
+func arguments(call *ast.CallExpression) map[string]ast.Expression {
+	args := make(map[string]ast.Expression)
+	for _, arg := range call.Arguments {
+		obj, ok := arg.(*ast.ObjectExpression)
+		if !ok {
+			continue
+		}
+		for _, p := range obj.Properties {
+			args[p.Key.Key()] = p.Value
+		}
+	}
+	return args
+}
+
+// resolve replaces identifiers and members of objects with the
+// expressions assigned to them, if known.
+func resolve(env map[string]ast.Expression, e ast.Expression) ast.Expression {
+	// Bound the lookups so that cyclic assignments terminate.
+	for i := 0; i < 8; i++ {
+		switch n := e.(type) {
+		case *ast.Identifier:
+			v, ok := env[n.Name]
+			if !ok {
+				return e
+			}
+			e = v
+		case *ast.MemberExpression:
+			obj, ok := n.Object.(*ast.Identifier)
+			if !ok {
+				return e
+			}
+			if v, ok := env[obj.Name+"."+n.Property.Key()]; ok {
+				e = v
+				continue
+			}
+			o, ok := resolve(env, obj).(*ast.ObjectExpression)
+			if !ok {
+				return e
+			}
+			var found bool
+			for _, p := range o.Properties {
+				if p.Key.Key() == n.Property.Key() {
+					e, found = p.Value, true
+					break
+				}
+			}
+			if !found {
+				return e
+			}
+		default:
+			return e
+		}
+	}
+	return e
+}
+
+// evalTime returns the time of a range bound or the zero time if it
+// cannot be known before the query runs.
+func evalTime(env map[string]ast.Expression, e ast.Expression, now time.Time) time.Time {
+	switch n := resolve(env, e).(type) {
+	case *ast.DateTimeLiteral:
+		return n.Value
+	case *ast.IntegerLiteral:
+		return time.Unix(n.Value, 0)
+	case *ast.DurationLiteral:
+		if d, ok := duration(n); ok {
+			return now.Add(d)
+		}
+	case *ast.UnaryExpression:
+		if lit, ok := n.Argument.(*ast.DurationLiteral); ok && n.Operator == ast.SubtractionOperator {
+			if d, ok := duration(lit); ok {
+				return now.Add(-d)
+			}
+		}
+	case *ast.CallExpression:
+		if id, ok := n.Callee.(*ast.Identifier); ok && id.Name == "now" && len(n.Arguments) == 0 {
+			return now
+		}
+	}
+	return time.Time{}
+}
+
+// duration converts a duration literal with fixed length units.
+func duration(lit *ast.DurationLiteral) (time.Duration, bool) {
+	var d time.Duration
+	for _, v := range lit.Values {
+		var unit time.Duration
+		switch v.Unit {
+		case ast.NanosecondUnit:
+			unit = time.Nanosecond
+		case ast.MicrosecondUnit:
+			unit = time.Microsecond
+		case ast.MillisecondUnit:
+			unit = time.Millisecond
+		case ast.SecondUnit:
+			unit = time.Second
+		case ast.MinuteUnit:
+			unit = time.Minute
+		case ast.HourUnit:
+			unit = time.Hour
+		case ast.DayUnit:
+			unit = 24 * time.Hour
+		case ast.WeekUnit:
+			unit = 7 * 24 * time.Hour
+		default:
+			// Months and years vary in length.
+			return 0, false
+		}
+		d += time.Duration(v.Magnitude) * unit
+	}
+	return d, true
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+)
+
+func TestAnalyze(t *testing.T) {
+	now := time.Date(2019, 12, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		query  string
+		extern string
+		want   bool
+	}{
+		{
+			name:  "from",
+			query: `from(bucket: "b") |> range(start: -1h)`,
+			want:  true,
+		},
+		{
+			name:  "alias of from",
+			query: `f = from f(bucket: "secret") |> range(start: -1h)`,
+		},
+		{
+			name:  "alias of to",
+			query: `w = to from(bucket: "b") |> range(start: -1h) |> w(bucket: "c")`,
+		},
+		{
+			name:  "function argument",
+			query: `read = (fn) => fn(bucket: "secret") read(fn: from) |> range(start: -1h)`,
+		},
+		{
+			name:  "record value",
+			query: `r = {f: buckets} r.f()`,
+		},
+		{
+			name:   "alias in the extern",
+			extern: `f = from`,
+			query:  `f(bucket: "secret") |> range(start: -1h)`,
+		},
+		{
+			name:  "no bucket",
+			query: `import "strings" strings.toUpper(v: "a")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := lang.FluxCompiler{Query: tt.query}
+			if tt.extern != "" {
+				c.Extern = parser.ParseSource(tt.extern).Files[0]
+			}
+			if _, ok := analyze(c, now); ok != tt.want {
+				t.Errorf("got %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
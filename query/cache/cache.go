@@ -0,0 +1,280 @@
+// Package cache caches the results of read-only Flux queries, so that a
+// dashboard shown on many screens executes each of its queries once per
+// refresh interval.
+//
+// The time of a query, which relative time ranges are resolved against,
+// is truncated to the resolution of the cache, so the same query sent
+// within the resolution shares a result. Results are removed when points
+// are written to or deleted from the buckets and time range they read.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultResolution is the default interval the time of cached queries
+// is truncated to.
+const DefaultResolution = 10 * time.Second
+
+// Config configures a Cache.
+type Config struct {
+	// MaxBytes is the maximum size of the cached results.
+	MaxBytes int64
+
+	// MaxEntryBytes is the maximum size of a single result. Larger
+	// results are not cached. It defaults to a tenth of MaxBytes.
+	MaxEntryBytes int64
+
+	// Resolution is the interval now() is truncated to and the time
+	// results stay cached. It defaults to DefaultResolution.
+	Resolution time.Duration
+}
+
+// entry is a cached result and what it was read from.
+type entry struct {
+	key     string
+	orgID   influxdb.ID
+	buckets []influxdb.ID
+	// start and stop bound the time range of the query. A zero time
+	// is unbounded.
+	start, stop time.Time
+	expires     time.Time
+	data        []byte
+
+	// stale is set on a pending entry when a write lands in its range
+	// while the query runs.
+	stale bool
+}
+
+// overlaps reports whether a write to a bucket between min and max may
+// change the result of e.
+func (e *entry) overlaps(orgID, bucketID influxdb.ID, min, max int64) bool {
+	if e.orgID != orgID {
+		return false
+	}
+	var found bool
+	for _, id := range e.buckets {
+		if id == bucketID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if !e.start.IsZero() && max < e.start.UnixNano() {
+		return false
+	}
+	return e.stop.IsZero() || min < e.stop.UnixNano()
+}
+
+// Cache holds query results in memory, evicting the least recently used
+// results when it is full.
+type Cache struct {
+	config Config
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	size    int64
+	// pending are the entries of the queries running now.
+	pending map[*entry]struct{}
+
+	metrics *cacheMetrics
+	now     func() time.Time
+}
+
+// New returns a Cache.
+func New(config Config) *Cache {
+	if config.MaxEntryBytes == 0 {
+		config.MaxEntryBytes = config.MaxBytes / 10
+	}
+	if config.Resolution <= 0 {
+		config.Resolution = DefaultResolution
+	}
+	return &Cache{
+		config:  config,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+		pending: make(map[*entry]struct{}),
+		metrics: newCacheMetrics(),
+		now:     time.Now,
+	}
+}
+
+// get returns the entry stored under key, if it has not expired.
+func (c *Cache) get(key string) *entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return e
+}
+
+// begin registers the entry of a query about to run, so that writes
+// landing while it runs are not missed.
+func (c *Cache) begin(e *entry) {
+	c.mu.Lock()
+	c.pending[e] = struct{}{}
+	c.mu.Unlock()
+}
+
+// abort forgets a pending entry whose result is not cached.
+func (c *Cache) abort(e *entry) {
+	c.mu.Lock()
+	delete(c.pending, e)
+	c.mu.Unlock()
+}
+
+// commit stores a pending entry with its result unless a write landed in
+// its range while the query ran.
+func (c *Cache) commit(e *entry, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, e)
+	size := int64(len(data))
+	if e.stale || size > c.config.MaxEntryBytes || size > c.config.MaxBytes {
+		return
+	}
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el)
+	}
+
+	e.data = data
+	e.expires = c.now().Add(c.config.Resolution)
+	c.entries[e.key] = c.lru.PushFront(e)
+	c.size += size
+	for c.size > c.config.MaxBytes {
+		c.remove(c.lru.Back())
+		c.metrics.evictions.Inc()
+	}
+	c.metrics.size.Set(float64(c.size))
+	c.metrics.entries.Set(float64(len(c.entries)))
+}
+
+// Invalidate removes the results that points written to or deleted from
+// a bucket between min and max, in nanoseconds, may change.
+func (c *Cache) Invalidate(orgID, bucketID influxdb.ID, min, max int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := range c.pending {
+		if e.overlaps(orgID, bucketID, min, max) {
+			e.stale = true
+		}
+	}
+
+	now := c.now()
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		e := el.Value.(*entry)
+		if e.overlaps(orgID, bucketID, min, max) {
+			c.remove(el)
+			c.metrics.invalidations.Inc()
+		} else if !now.Before(e.expires) {
+			c.remove(el)
+		}
+		el = next
+	}
+	c.metrics.size.Set(float64(c.size))
+	c.metrics.entries.Set(float64(len(c.entries)))
+}
+
+// remove deletes a stored entry. The caller holds mu.
+func (c *Cache) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry)
+	delete(c.entries, e.key)
+	c.size -= int64(len(e.data))
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (c *Cache) PrometheusCollectors() []prometheus.Collector {
+	return c.metrics.PrometheusCollectors()
+}
+
+type cacheMetrics struct {
+	requests      *prometheus.CounterVec
+	evictions     prometheus.Counter
+	invalidations prometheus.Counter
+	size          prometheus.Gauge
+	entries       prometheus.Gauge
+}
+
+type requestsLabel string
+
+const (
+	labelHit  = requestsLabel("hit")
+	labelMiss = requestsLabel("miss")
+	// labelSkip counts the queries that cannot be cached.
+	labelSkip = requestsLabel("skip")
+)
+
+func newCacheMetrics() *cacheMetrics {
+	const (
+		namespace = "query"
+		subsystem = "cache"
+	)
+
+	return &cacheMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "requests_total",
+			Help:      "Count of the query requests by cache result",
+		}, []string{"result"}),
+
+		evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "evictions_total",
+			Help:      "Count of the results evicted to make room for others",
+		}),
+
+		invalidations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "invalidations_total",
+			Help:      "Count of the results removed because of writes or deletes",
+		}),
+
+		size: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "size_bytes",
+			Help:      "Size of the cached results",
+		}),
+
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "entries",
+			Help:      "Number of cached results",
+		}),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (cm *cacheMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		cm.requests,
+		cm.evictions,
+		cm.invalidations,
+		cm.size,
+		cm.entries,
+	}
+}
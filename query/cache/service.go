@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/kit/check"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/query"
+	"go.uber.org/zap"
+)
+
+// ProxyQueryService serves the results of Flux queries from a Cache and
+// forwards the other queries.
+type ProxyQueryService struct {
+	cache         *Cache
+	bucketService influxdb.BucketService
+	proxy         query.ProxyQueryService
+
+	log *zap.Logger
+}
+
+var _ query.ProxyQueryService = (*ProxyQueryService)(nil)
+
+// NewProxyQueryService returns a ProxyQueryService that caches the
+// results of proxy in c. The bucketService finds the buckets read by the
+// queries.
+func NewProxyQueryService(log *zap.Logger, c *Cache, bucketService influxdb.BucketService, proxy query.ProxyQueryService) *ProxyQueryService {
+	return &ProxyQueryService{
+		cache:         c,
+		bucketService: bucketService,
+		proxy:         proxy,
+		log:           log,
+	}
+}
+
+// Query writes the cached result of the request if there is one, and
+// executes and caches it otherwise. Cached results have no statistics.
+func (s *ProxyQueryService) Query(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	c, ok := req.Request.Compiler.(lang.FluxCompiler)
+	if !ok || !req.Request.OrganizationID.Valid() {
+		return s.proxy.Query(ctx, w, req)
+	}
+	// Truncate the time of the query so that the queries sent within the
+	// resolution share their result.
+	if c.Now.IsZero() {
+		c.Now = s.cache.now()
+	}
+	c.Now = c.Now.Truncate(s.cache.config.Resolution)
+	a, ok := analyze(c, c.Now)
+	if !ok {
+		s.cache.metrics.requests.WithLabelValues(string(labelSkip)).Inc()
+		return s.proxy.Query(ctx, w, req)
+	}
+	key, err := cacheKey(req, c, a)
+	if err != nil {
+		s.cache.metrics.requests.WithLabelValues(string(labelSkip)).Inc()
+		return s.proxy.Query(ctx, w, req)
+	}
+	auth := authorizer(ctx, req)
+
+	if e := s.cache.get(key); e != nil && allowed(auth, e) {
+		s.cache.metrics.requests.WithLabelValues(string(labelHit)).Inc()
+		span.LogKV("cache", "hit")
+		_, err := w.Write(e.data)
+		return flux.Statistics{}, err
+	}
+	s.cache.metrics.requests.WithLabelValues(string(labelMiss)).Inc()
+
+	// Run the query at the truncated time, so that its result is the
+	// one of every query sharing the key.
+	creq := *req
+	creq.Request.Compiler = c
+
+	e, err := s.newEntry(ctx, req.Request.OrganizationID, key, a)
+	if err != nil || !allowed(auth, e) {
+		// Let the query report the missing bucket or permission.
+		return s.proxy.Query(ctx, w, &creq)
+	}
+
+	s.cache.begin(e)
+	buf := &limitedBuffer{max: s.cache.config.MaxEntryBytes}
+	stats, err := s.proxy.Query(ctx, io.MultiWriter(w, buf), &creq)
+	if err != nil || buf.overflow {
+		s.cache.abort(e)
+		return stats, err
+	}
+	s.cache.commit(e, buf.data)
+	return stats, nil
+}
+
+// newEntry returns an entry for the data read by a query.
+func (s *ProxyQueryService) newEntry(ctx context.Context, orgID influxdb.ID, key string, a *analysis) (*entry, error) {
+	e := &entry{
+		key:   key,
+		orgID: orgID,
+		start: a.start,
+		stop:  a.stop,
+	}
+	for _, name := range a.buckets {
+		b, err := s.bucketService.FindBucketByName(ctx, orgID, name)
+		if err != nil {
+			return nil, err
+		}
+		e.buckets = append(e.buckets, b.ID)
+	}
+	for _, v := range a.bucketIDs {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		e.buckets = append(e.buckets, *id)
+	}
+	return e, nil
+}
+
+func (s *ProxyQueryService) Check(ctx context.Context) check.Response {
+	return s.proxy.Check(ctx)
+}
+
+// cacheKey identifies the results of a query.
+func cacheKey(req *query.ProxyRequest, c lang.FluxCompiler, a *analysis) (string, error) {
+	dialect, err := json.Marshal(req.Dialect)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(req.Request.OrganizationID.String()))
+	h.Write([]byte{0})
+	h.Write([]byte(strconv.FormatInt(c.Now.UnixNano(), 10)))
+	h.Write([]byte{0})
+	if req.Dialect != nil {
+		h.Write([]byte(req.Dialect.DialectType()))
+	}
+	h.Write(dialect)
+	h.Write([]byte{0})
+	h.Write([]byte(a.text))
+	return string(h.Sum(nil)), nil
+}
+
+// authorizer returns the authorizer of the request.
+func authorizer(ctx context.Context, req *query.ProxyRequest) influxdb.Authorizer {
+	if a, err := icontext.GetAuthorizer(ctx); err == nil {
+		return a
+	}
+	if req.Request.Authorization != nil {
+		return req.Request.Authorization
+	}
+	return nil
+}
+
+// allowed reports whether a may read the buckets of e. An entry without
+// buckets is never allowed, so that it is never stored nor served.
+func allowed(a influxdb.Authorizer, e *entry) bool {
+	if a == nil || len(e.buckets) == 0 {
+		return false
+	}
+	for _, id := range e.buckets {
+		p, err := influxdb.NewPermissionAtID(id, influxdb.ReadAction, influxdb.BucketsResourceType, e.orgID)
+		if err != nil || !a.Allowed(*p) {
+			return false
+		}
+	}
+	return true
+}
+
+// limitedBuffer keeps what is written to it until it exceeds max bytes.
+type limitedBuffer struct {
+	max      int64
+	data     []byte
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.overflow {
+		return len(p), nil
+	}
+	if int64(len(b.data)+len(p)) > b.max {
+		b.overflow = true
+		b.data = nil
+		return len(p), nil
+	}
+	b.data = append(b.data, p...)
+	return len(p), nil
+}
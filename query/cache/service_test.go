@@ -0,0 +1,308 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/influxdb"
+	icontext "github.com/influxdata/influxdb/context"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	qmock "github.com/influxdata/influxdb/query/mock"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID      = influxdb.ID(0xa)
+	otherOrgID = influxdb.ID(0xb)
+	bucketID   = influxdb.ID(0xb0c4)
+)
+
+var now = time.Date(2020, 2, 20, 12, 0, 7, 0, time.UTC)
+
+type testService struct {
+	*ProxyQueryService
+	executed int
+	writer   *PointsWriter
+	deleter  *ProgressDeleter
+}
+
+type progressDeleterFunc func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error
+
+func (f progressDeleterFunc) DeleteBucketRangePredicateWithProgress(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+	return f(ctx, orgID, bucketID, min, max, pred, progress)
+}
+
+func newTestService(t *testing.T, config Config) *testService {
+	t.Helper()
+	ts := &testService{}
+	c := New(config)
+	c.now = func() time.Time { return now }
+
+	bs := mock.NewBucketService()
+	bs.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		if name != "b" {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+		}
+		return &influxdb.Bucket{ID: bucketID, OrgID: id, Name: name}, nil
+	}
+	proxy := &qmock.ProxyQueryService{
+		QueryF: func(ctx context.Context, w io.Writer, req *query.ProxyRequest) (flux.Statistics, error) {
+			ts.executed++
+			c := req.Request.Compiler.(lang.FluxCompiler)
+			if c.Query == "fail" {
+				return flux.Statistics{}, errors.New("expected error")
+			}
+			// The result depends on the time of the query.
+			_, err := io.WriteString(w, c.Now.Format(time.RFC3339Nano))
+			return flux.Statistics{TotalDuration: time.Second}, err
+		},
+	}
+	ts.ProxyQueryService = NewProxyQueryService(zaptest.NewLogger(t), c, bs, proxy)
+	ts.writer = &PointsWriter{PointsWriter: &mock.PointsWriter{}, Cache: c}
+	ts.deleter = &ProgressDeleter{
+		ProgressDeleter: progressDeleterFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+			return nil
+		}),
+		Cache: c,
+	}
+	return ts
+}
+
+func readAuthorization(orgID influxdb.ID) *influxdb.Authorization {
+	return &influxdb.Authorization{
+		OrgID:  orgID,
+		Status: influxdb.Active,
+		Permissions: []influxdb.Permission{{
+			Action:   influxdb.ReadAction,
+			Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &orgID},
+		}},
+	}
+}
+
+func (ts *testService) query(t *testing.T, orgID influxdb.ID, auth *influxdb.Authorization, c lang.FluxCompiler) string {
+	t.Helper()
+	req := &query.ProxyRequest{
+		Request: query.Request{
+			Authorization:  auth,
+			OrganizationID: orgID,
+			Compiler:       c,
+		},
+		Dialect: &csv.Dialect{},
+	}
+	var buf bytes.Buffer
+	ctx := icontext.SetAuthorizer(context.Background(), auth)
+	if _, err := ts.Query(ctx, &buf, req); err != nil {
+		return "error: " + err.Error()
+	}
+	return buf.String()
+}
+
+func (ts *testService) write(t *testing.T, orgID influxdb.ID, at time.Time) {
+	t.Helper()
+	p, err := models.NewPoint("m", nil, models.Fields{"f": 1.0}, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	points, err := tsdb.ExplodePoints(orgID, bucketID, models.Points{p})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.writer.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (ts *testService) delete(t *testing.T, orgID influxdb.ID, start, stop time.Time) {
+	t.Helper()
+	if err := ts.deleter.DeleteBucketRangePredicateWithProgress(context.Background(), orgID, bucketID,
+		start.UnixNano(), stop.UnixNano(), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProxyQueryService_Query(t *testing.T) {
+	const q = `from(bucket: "b") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "m")`
+	auth := readAuthorization(orgID)
+	flux := func(q string) lang.FluxCompiler { return lang.FluxCompiler{Query: q} }
+
+	tests := []struct {
+		name string
+		// run sends the queries, possibly writing in between.
+		run          func(t *testing.T, ts *testService)
+		wantExecuted int
+	}{
+		{
+			name: "hit with other formatting",
+			run: func(t *testing.T, ts *testService) {
+				first := ts.query(t, orgID, auth, flux(q))
+				second := ts.query(t, orgID, auth, flux("from(bucket:\"b\")\n\t|> range(start:-1h)\n\t|> filter(fn:(r) => r._measurement == \"m\")"))
+				if first != second {
+					t.Errorf("cached result %q differs from %q", second, first)
+				}
+				if want := now.Truncate(DefaultResolution).Format(time.RFC3339Nano); first != want {
+					t.Errorf("query ran at %s, want the truncated time %s", first, want)
+				}
+			},
+			wantExecuted: 1,
+		},
+		{
+			name: "time of the request",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, lang.FluxCompiler{Query: q, Now: now})
+				ts.query(t, orgID, auth, lang.FluxCompiler{Query: q, Now: now.Add(time.Second)})
+			},
+			wantExecuted: 1,
+		},
+		{
+			name: "other organization",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(q))
+				ts.query(t, otherOrgID, readAuthorization(otherOrgID), flux(q))
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "no permission on the bucket",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(q))
+				ts.query(t, orgID, &influxdb.Authorization{OrgID: orgID, Status: influxdb.Active}, flux(q))
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "write in range",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(q))
+				ts.write(t, orgID, now.Add(-10*time.Minute))
+				ts.query(t, orgID, auth, flux(q))
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "write out of range",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(q))
+				ts.write(t, orgID, now.Add(-2*time.Hour))
+				// Later than the truncated time of the query.
+				ts.write(t, orgID, now)
+				ts.write(t, otherOrgID, now.Add(-10*time.Minute))
+				ts.query(t, orgID, auth, flux(q))
+			},
+			wantExecuted: 1,
+		},
+		{
+			name: "delete job",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(q))
+				ts.delete(t, orgID, now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+				ts.query(t, orgID, auth, flux(q))
+				ts.delete(t, orgID, now.Add(-20*time.Minute), now.Add(-10*time.Minute))
+				ts.query(t, orgID, auth, flux(q))
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "dashboard variables",
+			run: func(t *testing.T, ts *testService) {
+				extern := parser.ParseSource(`option v = {timeRangeStart: -5m, timeRangeStop: now()}`).Files[0]
+				c := lang.FluxCompiler{
+					Query:  `from(bucket: "b") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)`,
+					Extern: extern,
+				}
+				ts.query(t, orgID, auth, c)
+				ts.write(t, orgID, now.Add(-10*time.Minute))
+				ts.query(t, orgID, auth, c)
+				ts.write(t, orgID, now.Add(-time.Minute))
+				ts.query(t, orgID, auth, c)
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "next resolution interval",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(q))
+				now = now.Add(DefaultResolution)
+				defer func() { now = now.Add(-DefaultResolution) }()
+				ts.query(t, orgID, auth, flux(q))
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "query of another organization",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux(`from(bucket: "b", org: "other") |> range(start: -1h)`))
+				ts.query(t, orgID, auth, flux(`from(bucket: "b", org: "other") |> range(start: -1h)`))
+			},
+			wantExecuted: 2,
+		},
+		{
+			name: "side effects",
+			run: func(t *testing.T, ts *testService) {
+				for i := 0; i < 2; i++ {
+					ts.query(t, orgID, auth, flux(`from(bucket: "b") |> range(start: -1h) |> to(bucket: "c")`))
+					ts.query(t, orgID, auth, flux(`import "http" from(bucket: "b") |> range(start: -1h)`))
+				}
+			},
+			wantExecuted: 4,
+		},
+		{
+			name: "errors",
+			run: func(t *testing.T, ts *testService) {
+				ts.query(t, orgID, auth, flux("fail"))
+				ts.query(t, orgID, auth, flux("fail"))
+			},
+			wantExecuted: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, Config{MaxBytes: 1 << 20})
+			tt.run(t, ts)
+			if ts.executed != tt.wantExecuted {
+				t.Errorf("executed %d queries, want %d", ts.executed, tt.wantExecuted)
+			}
+		})
+	}
+}
+
+func TestProxyQueryService_Query_Size(t *testing.T) {
+	// Each result is 20 bytes long and the cache fits two of them.
+	ts := newTestService(t, Config{MaxBytes: 45, MaxEntryBytes: 20})
+	auth := readAuthorization(orgID)
+	queries := []string{
+		`from(bucket: "b") |> range(start: -1h)`,
+		`from(bucket: "b") |> range(start: -2h)`,
+		`from(bucket: "b") |> range(start: -3h)`,
+	}
+	for _, q := range queries {
+		ts.query(t, orgID, auth, lang.FluxCompiler{Query: q})
+	}
+	ts.executed = 0
+
+	// The least recently used result was evicted.
+	for i := len(queries) - 1; i >= 0; i-- {
+		ts.query(t, orgID, auth, lang.FluxCompiler{Query: queries[i]})
+	}
+	if ts.executed != 1 {
+		t.Errorf("executed %d queries, want 1", ts.executed)
+	}
+
+	ts = newTestService(t, Config{MaxBytes: 1 << 20, MaxEntryBytes: 10})
+	for i := 0; i < 2; i++ {
+		ts.query(t, orgID, auth, lang.FluxCompiler{Query: queries[0]})
+	}
+	if ts.executed != 2 {
+		t.Errorf("executed %d queries, want 2 when the result is too large", ts.executed)
+	}
+}
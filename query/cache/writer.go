@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// PointsWriter writes points and invalidates the cached results they
+// change.
+type PointsWriter struct {
+	storage.PointsWriter
+	Cache *Cache
+}
+
+// WritePoints writes points, which must have been exploded with
+// tsdb.ExplodePoints, and invalidates the cached results of their
+// buckets and time range.
+func (w *PointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	err := w.PointsWriter.WritePoints(ctx, points)
+
+	// Invalidate even on errors, some points may have been written.
+	type timeRange struct{ min, max int64 }
+	ranges := make(map[[16]byte]*timeRange)
+	for _, p := range points {
+		var name [16]byte
+		if copy(name[:], p.Name()) != len(name) {
+			continue
+		}
+		t := p.UnixNano()
+		r, ok := ranges[name]
+		if !ok {
+			ranges[name] = &timeRange{min: t, max: t}
+			continue
+		}
+		if t < r.min {
+			r.min = t
+		}
+		if t > r.max {
+			r.max = t
+		}
+	}
+	for name, r := range ranges {
+		orgID, bucketID := tsdb.DecodeName(name)
+		w.Cache.Invalidate(orgID, bucketID, r.min, r.max)
+	}
+	return err
+}
+
+// DeleteService deletes data and invalidates the cached results it
+// changes.
+type DeleteService struct {
+	influxdb.DeleteService
+	Cache *Cache
+}
+
+// DeleteBucketRangePredicate deletes data and invalidates the cached
+// results of the bucket and time range.
+func (s *DeleteService) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate) error {
+	err := s.DeleteService.DeleteBucketRangePredicate(ctx, orgID, bucketID, min, max, pred)
+	s.Cache.Invalidate(orgID, bucketID, min, max)
+	return err
+}
+
+// ProgressDeleter deletes data in the background, recording its progress,
+// and invalidates the cached results it changes.
+type ProgressDeleter struct {
+	storage.ProgressDeleter
+	Cache *Cache
+}
+
+// DeleteBucketRangePredicateWithProgress deletes data and invalidates the
+// cached results of the bucket and time range.
+func (d *ProgressDeleter) DeleteBucketRangePredicateWithProgress(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+	err := d.ProgressDeleter.DeleteBucketRangePredicateWithProgress(ctx, orgID, bucketID, min, max, pred, progress)
+	d.Cache.Invalidate(orgID, bucketID, min, max)
+	return err
+}
@@ -44,13 +44,16 @@ import (
 // orgLabel is the metric label to use in the controller
 const orgLabel = "org"
 
+// priorityLabel is the metric label of the priority class of a query.
+const priorityLabel = "priority"
+
 // Controller provides a central location to manage all incoming queries.
 // The controller is responsible for compiling, queueing, and executing queries.
 type Controller struct {
 	lastID     uint64
 	queriesMu  sync.RWMutex
 	queries    map[QueryID]*Query
-	queryQueue chan *Query
+	queryQueue *queryQueue
 	wg         sync.WaitGroup
 	shutdown   bool
 	done       chan struct{}
@@ -64,6 +67,14 @@ type Controller struct {
 	log *zap.Logger
 
 	dependencies []flux.Dependency
+	orgLimiter   OrgQueryLimiter
+}
+
+// OrgQueryLimiter limits the number of queries an organization may run at once.
+type OrgQueryLimiter interface {
+	// AcquireQuery reserves a query slot for orgID and returns the
+	// function that releases it.
+	AcquireQuery(ctx context.Context, orgID influxdb.ID) (func(), error)
 }
 
 type Config struct {
@@ -92,13 +103,29 @@ type Config struct {
 	// QueueSize is the number of queries that are allowed to be awaiting execution before new queries are
 	// rejected.
 	QueueSize int
-	Logger    *zap.Logger
+
+	// Priorities overrides the ConcurrencyQuota, QueueSize and
+	// MemoryBytesQuotaPerQuery for the queries of a priority class.
+	// Each class has its own queue, so a full queue of interactive
+	// queries does not reject task queries.
+	Priorities map[query.Priority]PriorityConfig
+
+	// OrgWeights is the number of queries an organization may start in a
+	// turn when several organizations are waiting in a queue. It
+	// defaults to 1 for every organization.
+	OrgWeights map[influxdb.ID]int
+
+	Logger *zap.Logger
 	// MetricLabelKeys is a list of labels to add to the metrics produced by the controller.
 	// The value for a given key will be read off the context.
 	// The context value must be a string or an implementation of the Stringer interface.
 	MetricLabelKeys []string
 
 	ExecutorDependencies []flux.Dependency
+
+	// OrgLimiter, if set, is consulted before a query is created and may
+	// reject queries from organizations that are running too many.
+	OrgLimiter OrgQueryLimiter
 }
 
 // complete will fill in the defaults, validate the configuration, and
@@ -136,6 +163,19 @@ func (c *Config) validate(isComplete bool) error {
 	if c.QueueSize <= 0 {
 		return errors.New("QueueSize must be positive")
 	}
+	for p, pc := range c.Priorities {
+		if !p.Valid() {
+			return fmt.Errorf("unknown query priority %q", p)
+		}
+		if err := pc.validate(c); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("invalid %s priority", p))
+		}
+	}
+	for orgID, w := range c.OrgWeights {
+		if w < 0 {
+			return fmt.Errorf("weight of organization %s must be positive", orgID)
+		}
+	}
 	return nil
 }
 
@@ -151,7 +191,7 @@ func New(config Config) (*Controller, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid controller config")
 	}
-	c.MetricLabelKeys = append(c.MetricLabelKeys, orgLabel) //lint:ignore SA1029 this is a temporary ignore until we have time to create an appropriate type
+	c.MetricLabelKeys = append(c.MetricLabelKeys, orgLabel, priorityLabel) //lint:ignore SA1029 this is a temporary ignore until we have time to create an appropriate type
 	logger := c.Logger
 	if logger == nil {
 		logger = zap.NewNop()
@@ -165,7 +205,6 @@ func New(config Config) (*Controller, error) {
 
 	mm := &memoryManager{
 		initialBytesQuotaPerQuery: c.InitialMemoryBytesQuotaPerQuery,
-		memoryBytesQuotaPerQuery:  c.MemoryBytesQuotaPerQuery,
 	}
 	if c.MaxMemoryBytes > 0 {
 		mm.unusedMemoryBytes = c.MaxMemoryBytes - (int64(c.ConcurrencyQuota) * c.InitialMemoryBytesQuotaPerQuery)
@@ -174,7 +213,7 @@ func New(config Config) (*Controller, error) {
 	}
 	ctrl := &Controller{
 		queries:      make(map[QueryID]*Query),
-		queryQueue:   make(chan *Query, c.QueueSize),
+		queryQueue:   newQueryQueue(&c),
 		done:         make(chan struct{}),
 		abort:        make(chan struct{}),
 		memory:       mm,
@@ -182,6 +221,7 @@ func New(config Config) (*Controller, error) {
 		metrics:      newControllerMetrics(c.MetricLabelKeys),
 		labelKeys:    c.MetricLabelKeys,
 		dependencies: c.ExecutorDependencies,
+		orgLimiter:   c.OrgLimiter,
 	}
 	ctrl.wg.Add(c.ConcurrencyQuota)
 	for i := 0; i < c.ConcurrencyQuota; i++ {
@@ -201,7 +241,8 @@ func (c *Controller) Query(ctx context.Context, req *query.Request) (flux.Query,
 	// Set the request on the context so platform specific Flux operations can retrieve it later.
 	ctx = query.ContextWithRequest(ctx, req)
 	// Set the org label value for controller metrics
-	ctx = context.WithValue(ctx, orgLabel, req.OrganizationID.String()) //lint:ignore SA1029 this is a temporary ignore until we have time to create an appropriate type
+	ctx = context.WithValue(ctx, orgLabel, req.OrganizationID.String())  //lint:ignore SA1029 this is a temporary ignore until we have time to create an appropriate type
+	ctx = context.WithValue(ctx, priorityLabel, string(priorityOf(req))) //lint:ignore SA1029 this is a temporary ignore until we have time to create an appropriate type
 	// The controller injects the dependencies for each incoming request.
 	for _, dep := range c.dependencies {
 		ctx = dep.Inject(ctx)
@@ -245,6 +286,11 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	}
 	c.queriesMu.RUnlock()
 
+	release, err := c.acquireOrgQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	id := c.nextID()
 	labelValues := make([]string, len(c.labelKeys))
 	compileLabelValues := make([]string, len(c.labelKeys)+1)
@@ -262,6 +308,7 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 	}
 	compileLabelValues[len(compileLabelValues)-1] = string(ct)
 
+	req := query.RequestFromContext(ctx)
 	cctx, cancel := context.WithCancel(ctx)
 	parentSpan, parentCtx := StartSpanFromContext(
 		cctx,
@@ -280,6 +327,10 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 		parentSpan:         parentSpan,
 		cancel:             cancel,
 		doneCh:             make(chan struct{}),
+		release:            release,
+		request:            req,
+		createdAt:          time.Now(),
+		priority:           priorityOf(req),
 	}
 
 	// Lock the queries mutex for the rest of this method.
@@ -294,12 +345,28 @@ func (c *Controller) createQuery(ctx context.Context, ct flux.CompilerType) (*Qu
 			Msg:  "query controller shutdown",
 		}
 		q.setErr(err)
+		release()
 		return nil, err
 	}
 	c.queries[id] = q
 	return q, nil
 }
 
+// acquireOrgQuery reserves a query slot for the organization of the request
+// on ctx when queries are limited per organization.
+func (c *Controller) acquireOrgQuery(ctx context.Context) (func(), error) {
+	noop := func() {}
+	if c.orgLimiter == nil {
+		return noop, nil
+	}
+
+	req := query.RequestFromContext(ctx)
+	if req == nil || !req.OrganizationID.Valid() {
+		return noop, nil
+	}
+	return c.orgLimiter.AcquireQuery(ctx, req.OrganizationID)
+}
+
 func (c *Controller) nextID() QueryID {
 	nextID := atomic.AddUint64(&c.lastID, 1)
 	return QueryID(nextID)
@@ -362,25 +429,21 @@ func (c *Controller) enqueueQuery(q *Query) error {
 		}
 	}
 
-	select {
-	case c.queryQueue <- q:
-	default:
-		return &flux.Error{
-			Code: codes.ResourceExhausted,
-			Msg:  "queue length exceeded",
-		}
-	}
-
-	return nil
+	return c.queryQueue.push(q)
 }
 
 func (c *Controller) processQueryQueue() {
 	for {
+		if q := c.queryQueue.pop(); q != nil {
+			c.executeQuery(q)
+			c.queryQueue.release(q)
+			continue
+		}
+
 		select {
 		case <-c.done:
 			return
-		case q := <-c.queryQueue:
-			c.executeQuery(q)
+		case <-c.queryQueue.ready:
 		}
 	}
 }
@@ -418,7 +481,10 @@ func (c *Controller) executeQuery(q *Query) {
 		return
 	}
 
+	// The allocator is read concurrently by the running query service.
+	q.stateMu.Lock()
 	q.c.createAllocator(q)
+	q.stateMu.Unlock()
 	exec, err := q.program.Start(ctx, q.alloc)
 	if err != nil {
 		q.setErr(err)
@@ -443,6 +509,10 @@ func (c *Controller) finish(q *Query) {
 		close(c.done)
 	}
 	c.queriesMu.Unlock()
+
+	if q.release != nil {
+		q.release()
+	}
 }
 
 // Queries reports the active queries.
@@ -534,6 +604,18 @@ type Query struct {
 
 	memoryManager *queryMemoryManager
 	alloc         *memory.Allocator
+
+	// release frees the query slot held with the organization limiter.
+	release func()
+
+	// request is the request the query was created for, if any.
+	request   *query.Request
+	createdAt time.Time
+	priority  query.Priority
+
+	// killed is set when the query is canceled through the controller
+	// rather than by its client, so that the client gets an error.
+	killed bool
 }
 
 // ID reports an ephemeral unique ID for the query.
@@ -541,6 +623,14 @@ func (q *Query) ID() QueryID {
 	return q.id
 }
 
+// orgID returns the organization that runs the query, if known.
+func (q *Query) orgID() influxdb.ID {
+	if q.request == nil {
+		return 0
+	}
+	return q.request.OrganizationID
+}
+
 // Cancel will stop the query execution.
 func (q *Query) Cancel() {
 	// Call the cancel function to signal that execution should
@@ -595,6 +685,11 @@ func (q *Query) Done() {
 				// this so maybe their interface should change?
 				q.err = q.exec.Err()
 			}
+			q.stateMu.Lock()
+			if q.err == nil && q.killed {
+				q.err = errQueryKilled
+			}
+			q.stateMu.Unlock()
 			// Merge the metadata from the program into the controller stats.
 			stats := q.exec.Statistics()
 			q.stats.Metadata = stats.Metadata
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/arrow"
 	"github.com/influxdata/flux/codes"
@@ -20,6 +21,7 @@ import (
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/stdlib/universe"
+	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/query"
 	_ "github.com/influxdata/influxdb/query/builtin"
 	"github.com/influxdata/influxdb/query/control"
@@ -77,8 +79,9 @@ func validateRequestTotals(t testing.TB, reg *prometheus.Registry, success, comp
 			metrics,
 			"query_control_requests_total",
 			map[string]string{
-				"result": name,
-				"org":    "",
+				"result":   name,
+				"org":      "",
+				"priority": "interactive",
 			},
 		)
 		var got int
@@ -727,6 +730,67 @@ func TestController_ConcurrencyQuota(t *testing.T) {
 	}
 }
 
+// orgLimiter allows a single query per organization at a time.
+type orgLimiter struct {
+	mu     sync.Mutex
+	active map[platform.ID]bool
+}
+
+func (l *orgLimiter) AcquireQuery(ctx context.Context, orgID platform.ID) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[orgID] {
+		return nil, &platform.Error{Code: platform.ETooManyRequests, Msg: "too many queries"}
+	}
+	l.active[orgID] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.active, orgID)
+	}, nil
+}
+
+func TestController_OrgLimiter(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 2
+	config.QueueSize = 2
+	config.OrgLimiter = &orgLimiter{active: make(map[platform.ID]bool)}
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	request := func(orgID platform.ID) *query.Request {
+		req := makeRequest(mockCompiler)
+		req.OrganizationID = orgID
+		return req
+	}
+
+	q, err := ctrl.Query(context.Background(), request(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err := ctrl.Query(context.Background(), request(1)); platform.ErrorCode(err) != platform.ETooManyRequests {
+		t.Fatalf("expected too many requests error, got: %v", err)
+	}
+
+	other, err := ctrl.Query(context.Background(), request(2))
+	if err != nil {
+		t.Fatalf("unexpected error for another organization: %s", err)
+	}
+	consumeResults(t, other)
+
+	consumeResults(t, q)
+
+	q, err = ctrl.Query(context.Background(), request(1))
+	if err != nil {
+		t.Fatalf("unexpected error after the first query finished: %s", err)
+	}
+	consumeResults(t, q)
+}
+
 func TestController_QueueSize(t *testing.T) {
 	const (
 		concurrencyQuota = 2
@@ -797,6 +861,295 @@ func TestController_QueueSize(t *testing.T) {
 	}
 }
 
+// blockingCompiler returns a compiler whose programs signal executing
+// and then block until done is closed.
+func blockingCompiler(executing chan<- *query.Request, done <-chan struct{}) flux.Compiler {
+	return &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					executing <- query.RequestFromContext(ctx)
+					<-done
+				},
+			}, nil
+		},
+	}
+}
+
+// runInBackground consumes the results of q until it finishes.
+func runInBackground(q flux.Query) {
+	go func() {
+		for range q.Results() {
+			// discard the results
+		}
+		q.Done()
+	}()
+}
+
+func TestController_PriorityQueueSize(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 1
+	config.QueueSize = 1
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	done := make(chan struct{})
+	defer close(done)
+	executing := make(chan *query.Request, 3)
+	compiler := blockingCompiler(executing, done)
+
+	// Occupy the only slot and fill the queue of interactive queries.
+	q, err := ctrl.Query(context.Background(), makeRequest(compiler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runInBackground(q)
+	<-executing
+	q, err = ctrl.Query(context.Background(), makeRequest(compiler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runInBackground(q)
+
+	if _, err := ctrl.Query(context.Background(), makeRequest(compiler)); err == nil {
+		t.Fatal("expected an error about queue length exceeded")
+	}
+
+	// Task queries have their own queue.
+	req := makeRequest(compiler)
+	req.Priority = query.PriorityTask
+	q, err = ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error queueing a task query: %s", err)
+	}
+	runInBackground(q)
+}
+
+func TestController_PriorityConcurrencyQuota(t *testing.T) {
+	config := config
+	config.ConcurrencyQuota = 2
+	config.QueueSize = 10
+	config.Priorities = map[query.Priority]control.PriorityConfig{
+		query.PriorityInteractive: {ConcurrencyQuota: 1},
+	}
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	done := make(chan struct{})
+	defer close(done)
+	executing := make(chan *query.Request, 3)
+	compiler := blockingCompiler(executing, done)
+
+	for i := 0; i < 2; i++ {
+		q, err := ctrl.Query(context.Background(), makeRequest(compiler))
+		if err != nil {
+			t.Fatal(err)
+		}
+		runInBackground(q)
+	}
+	<-executing
+
+	// The second interactive query waits, but a task query may use the
+	// slot left free for the other classes.
+	req := makeRequest(compiler)
+	req.Priority = query.PriorityTask
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runInBackground(q)
+
+	select {
+	case got := <-executing:
+		if got.Priority != query.PriorityTask {
+			t.Fatalf("expected the task query to execute, got a %q query", got.Priority)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("task query did not execute")
+	}
+}
+
+func TestController_PriorityMemoryQuota(t *testing.T) {
+	config := config
+	config.InitialMemoryBytesQuotaPerQuery = 64
+	config.Priorities = map[query.Priority]control.PriorityConfig{
+		query.PriorityBackground: {MemoryBytesQuotaPerQuery: 128},
+	}
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					defer func() {
+						if err, ok := recover().(error); ok && err != nil {
+							q.SetErr(err)
+						}
+					}()
+
+					mem := arrow.NewAllocator(alloc)
+					b := mem.Allocate(512)
+					mem.Free(b)
+				},
+			}, nil
+		},
+	}
+
+	for _, tt := range []struct {
+		priority query.Priority
+		wantErr  bool
+	}{
+		{priority: query.PriorityInteractive},
+		{priority: query.PriorityBackground, wantErr: true},
+	} {
+		req := makeRequest(compiler)
+		req.Priority = tt.priority
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range q.Results() {
+			// discard the results
+		}
+		q.Done()
+
+		if got := q.Err() != nil; got != tt.wantErr {
+			t.Errorf("unexpected error for a %s query: %v", tt.priority, q.Err())
+		}
+	}
+}
+
+func TestController_PriorityConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		priorities map[query.Priority]control.PriorityConfig
+	}{
+		{
+			name: "unknown priority",
+			priorities: map[query.Priority]control.PriorityConfig{
+				"urgent": {},
+			},
+		},
+		{
+			name: "concurrency above the controller quota",
+			priorities: map[query.Priority]control.PriorityConfig{
+				query.PriorityTask: {ConcurrencyQuota: config.ConcurrencyQuota + 1},
+			},
+		},
+		{
+			name: "memory below the initial quota",
+			priorities: map[query.Priority]control.PriorityConfig{
+				query.PriorityTask: {MemoryBytesQuotaPerQuery: config.MemoryBytesQuotaPerQuery / 2},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			config.Priorities = tt.priorities
+			if _, err := control.New(config); err == nil {
+				t.Fatal("expected an invalid config error")
+			}
+		})
+	}
+}
+
+func TestController_OrgFairness(t *testing.T) {
+	orgA, orgB := platform.ID(1), platform.ID(2)
+	for _, tt := range []struct {
+		name    string
+		weights map[platform.ID]int
+		want    []platform.ID
+	}{
+		{
+			name: "round robin",
+			want: []platform.ID{orgA, orgB, orgA, orgA},
+		},
+		{
+			name:    "weighted",
+			weights: map[platform.ID]int{orgA: 2},
+			want:    []platform.ID{orgA, orgA, orgB, orgA},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := config
+			config.ConcurrencyQuota = 1
+			config.QueueSize = 10
+			config.OrgWeights = tt.weights
+			ctrl, err := control.New(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer shutdown(t, ctrl)
+
+			block := make(chan struct{})
+			executing := make(chan *query.Request, 1)
+			q, err := ctrl.Query(context.Background(), makeRequest(blockingCompiler(executing, block)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			runInBackground(q)
+			<-executing
+
+			// Queue a burst of queries from one organization before a
+			// query from another one.
+			var (
+				mu  sync.Mutex
+				got []platform.ID
+			)
+			compiler := &mock.Compiler{
+				CompileFn: func(ctx context.Context) (flux.Program, error) {
+					return &mock.Program{
+						ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+							mu.Lock()
+							got = append(got, query.RequestFromContext(ctx).OrganizationID)
+							mu.Unlock()
+						},
+					}, nil
+				},
+			}
+			var queries []flux.Query
+			for _, orgID := range []platform.ID{orgA, orgA, orgA, orgB} {
+				req := makeRequest(compiler)
+				req.OrganizationID = orgID
+				q, err := ctrl.Query(context.Background(), req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				queries = append(queries, q)
+			}
+
+			// The controller waits for each query to be done before it
+			// starts the next one, so consume them concurrently.
+			close(block)
+			var wg sync.WaitGroup
+			for _, q := range queries {
+				wg.Add(1)
+				go func(q flux.Query) {
+					defer wg.Done()
+					consumeResults(t, q)
+				}(q)
+			}
+			wg.Wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !cmp.Equal(tt.want, got) {
+				t.Fatalf("unexpected execution order -want/+got:\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
 // Test that rapidly starting and canceling the query and then calling done will correctly
 // cancel the query and not result in a race condition.
 func TestController_CancelDone(t *testing.T) {
@@ -1254,6 +1607,71 @@ func TestController_ReserveMemoryWithoutExceedingMax(t *testing.T) {
 	}
 }
 
+func TestController_RunningQueries(t *testing.T) {
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	executing := make(chan struct{})
+	compiler := &mock.Compiler{
+		CompileFn: func(ctx context.Context) (flux.Program, error) {
+			return &mock.Program{
+				ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+					close(executing)
+					<-ctx.Done()
+				},
+			}, nil
+		},
+	}
+
+	orgID, otherOrgID := platform.ID(1), platform.ID(2)
+	req := makeRequest(compiler)
+	req.OrganizationID = orgID
+	req.Authorization = &platform.Authorization{ID: 3, UserID: 4}
+	q, err := ctrl.Query(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-executing
+
+	rqs, err := ctrl.FindRunningQueries(context.Background(), platform.RunningQueryFilter{OrgID: &orgID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rqs) != 1 {
+		t.Fatalf("unexpected number of running queries: got %d want 1", len(rqs))
+	}
+	rq := rqs[0]
+	if rq.OrgID != orgID || rq.AuthorizationID != 3 || rq.UserID != 4 {
+		t.Errorf("unexpected running query: %+v", rq)
+	}
+	if got, want := rq.State, "executing"; got != want {
+		t.Errorf("unexpected state: got %s want %s", got, want)
+	}
+
+	if rqs, err := ctrl.FindRunningQueries(context.Background(), platform.RunningQueryFilter{OrgID: &otherOrgID}); err != nil {
+		t.Fatal(err)
+	} else if len(rqs) != 0 {
+		t.Errorf("unexpected running queries of another organization: %v", rqs)
+	}
+
+	if err := ctrl.CancelRunningQuery(context.Background(), rq.ID); err != nil {
+		t.Fatal(err)
+	}
+	for range q.Results() {
+	}
+	q.Done()
+	if q.Err() == nil {
+		t.Error("expected an error from the canceled query")
+	}
+
+	if _, err := ctrl.FindRunningQueryByID(context.Background(), rq.ID); platform.ErrorCode(err) != platform.ENotFound {
+		t.Errorf("unexpected error finding a finished query: %v", err)
+	}
+}
+
 func consumeResults(tb testing.TB, q flux.Query) {
 	tb.Helper()
 	for res := range q.Results() {
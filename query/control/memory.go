@@ -14,10 +14,6 @@ type memoryManager struct {
 	// memory pool.
 	initialBytesQuotaPerQuery int64
 
-	// memoryBytesQuotaPerQuery is the maximum amount of memory
-	// that may be allocated to each query.
-	memoryBytesQuotaPerQuery int64
-
 	// unusedMemoryBytes is the amount of memory that may be used
 	// when a query requests more memory. This value is only used
 	// when unlimited is set to false.
@@ -34,6 +30,7 @@ func (c *Controller) createAllocator(q *Query) {
 	q.memoryManager = &queryMemoryManager{
 		m:     c.memory,
 		limit: c.memory.initialBytesQuotaPerQuery,
+		quota: c.queryQueue.memoryBytesQuotaPerQuery(q.priority),
 	}
 	q.alloc = &memory.Allocator{
 		// Use an anonymous function to ensure the value is copied.
@@ -47,6 +44,9 @@ type queryMemoryManager struct {
 	m     *memoryManager
 	limit int64
 	given int64
+	// quota is the maximum amount of memory that may be allocated
+	// to the query.
+	quota int64
 }
 
 // RequestMemory will determine if the query can be given more memory
@@ -60,8 +60,8 @@ type queryMemoryManager struct {
 // too much about the specific message or structure.
 func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 	// It can be determined statically if we are going to violate
-	// the memoryBytesQuotaPerQuery.
-	if q.limit+want > q.m.memoryBytesQuotaPerQuery {
+	// the quota.
+	if q.limit+want > q.quota {
 		return 0, errors.New("query hit hard limit")
 	}
 
@@ -106,12 +106,12 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 func (q *queryMemoryManager) giveMemory(want, unused int64) int64 {
 	// If we can safely double the limit, then just do that.
 	if q.limit > want && q.limit < unused {
-		if q.limit*2 <= q.m.memoryBytesQuotaPerQuery {
+		if q.limit*2 <= q.quota {
 			return q.limit
 		}
 		// Doubling the limit sends us over the quota.
 		// Determine what would be our maximum amount.
-		max := q.m.memoryBytesQuotaPerQuery - q.limit
+		max := q.quota - q.limit
 		if max > want {
 			return max
 		}
@@ -0,0 +1,230 @@
+package control
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+// PriorityConfig limits the queries of a priority class. Zero values
+// fall back to the settings of the controller.
+type PriorityConfig struct {
+	// ConcurrencyQuota is the number of queries of the class that are
+	// allowed to execute concurrently. It cannot exceed the
+	// ConcurrencyQuota of the controller.
+	ConcurrencyQuota int
+
+	// QueueSize is the number of queries of the class that are allowed
+	// to be awaiting execution before new queries of the class are
+	// rejected.
+	QueueSize int
+
+	// MemoryBytesQuotaPerQuery is the maximum number of bytes a query of
+	// the class is allowed to use at any given time.
+	MemoryBytesQuotaPerQuery int64
+}
+
+func (c PriorityConfig) validate(config *Config) error {
+	if c.ConcurrencyQuota < 0 || c.ConcurrencyQuota > config.ConcurrencyQuota {
+		return fmt.Errorf("ConcurrencyQuota must be between 0 and %d", config.ConcurrencyQuota)
+	}
+	if c.QueueSize < 0 {
+		return fmt.Errorf("QueueSize must be positive")
+	}
+	if c.MemoryBytesQuotaPerQuery < 0 {
+		return fmt.Errorf("MemoryBytesQuotaPerQuery must be positive")
+	}
+	if c.MemoryBytesQuotaPerQuery > 0 && c.MemoryBytesQuotaPerQuery < config.InitialMemoryBytesQuotaPerQuery {
+		return fmt.Errorf("MemoryBytesQuotaPerQuery must be greater than or equal to the InitialMemoryBytesQuotaPerQuery: %d < %d", c.MemoryBytesQuotaPerQuery, config.InitialMemoryBytesQuotaPerQuery)
+	}
+	return nil
+}
+
+// complete fills in the defaults of the class from the controller config.
+func (c PriorityConfig) complete(config *Config) PriorityConfig {
+	if c.ConcurrencyQuota == 0 {
+		c.ConcurrencyQuota = config.ConcurrencyQuota
+	}
+	if c.QueueSize == 0 {
+		c.QueueSize = config.QueueSize
+	}
+	if c.MemoryBytesQuotaPerQuery == 0 {
+		c.MemoryBytesQuotaPerQuery = config.MemoryBytesQuotaPerQuery
+	}
+	return c
+}
+
+// priorityOf returns the class a request is scheduled in.
+func priorityOf(req *query.Request) query.Priority {
+	if req == nil || !req.Priority.Valid() {
+		return query.PriorityInteractive
+	}
+	return req.Priority
+}
+
+// queryQueue holds the compiled queries waiting for execution.
+//
+// Queries are taken from the most urgent priority class that has both
+// waiting queries and concurrency left. Within a class, organizations are
+// served in weighted round robin so that a burst of queries from one
+// organization does not delay the queries of the others.
+type queryQueue struct {
+	mu      sync.Mutex
+	classes []*classQueue
+	byName  map[query.Priority]*classQueue
+	weight  func(orgID influxdb.ID) int
+
+	// ready is signaled whenever a query may have become runnable.
+	ready chan struct{}
+}
+
+func newQueryQueue(config *Config) *queryQueue {
+	qq := &queryQueue{
+		byName: make(map[query.Priority]*classQueue, len(query.Priorities)),
+		weight: func(orgID influxdb.ID) int {
+			if w := config.OrgWeights[orgID]; w > 0 {
+				return w
+			}
+			return 1
+		},
+		ready: make(chan struct{}, 1),
+	}
+	for _, p := range query.Priorities {
+		cq := &classQueue{
+			priority: p,
+			config:   config.Priorities[p].complete(config),
+			orgs:     make(map[influxdb.ID]*orgQueue),
+		}
+		qq.classes = append(qq.classes, cq)
+		qq.byName[p] = cq
+	}
+	return qq
+}
+
+// push adds q to the queue of its class or fails if that queue is full.
+func (qq *queryQueue) push(q *Query) error {
+	qq.mu.Lock()
+	cq := qq.byName[q.priority]
+	if cq.len >= cq.config.QueueSize {
+		qq.mu.Unlock()
+		return &flux.Error{
+			Code: codes.ResourceExhausted,
+			Msg:  fmt.Sprintf("queue length exceeded for %s queries", q.priority),
+		}
+	}
+	cq.push(q, q.orgID())
+	qq.mu.Unlock()
+
+	qq.signal()
+	return nil
+}
+
+// pop removes the next query to execute or returns nil if no query can
+// execute now. The query holds a concurrency slot of its class until
+// release is called.
+func (qq *queryQueue) pop() *Query {
+	qq.mu.Lock()
+	defer qq.mu.Unlock()
+
+	for i, cq := range qq.classes {
+		if cq.len == 0 || cq.running >= cq.config.ConcurrencyQuota {
+			continue
+		}
+		q := cq.pop(qq.weight)
+		cq.running++
+
+		// Wake another worker if more queries can run.
+		for _, cq := range qq.classes[i:] {
+			if cq.len > 0 && cq.running < cq.config.ConcurrencyQuota {
+				qq.signal()
+				break
+			}
+		}
+		return q
+	}
+	return nil
+}
+
+// release frees the concurrency slot of an executed query.
+func (qq *queryQueue) release(q *Query) {
+	qq.mu.Lock()
+	qq.byName[q.priority].running--
+	qq.mu.Unlock()
+
+	qq.signal()
+}
+
+func (qq *queryQueue) signal() {
+	select {
+	case qq.ready <- struct{}{}:
+	default:
+	}
+}
+
+// memoryBytesQuotaPerQuery returns the memory quota of the queries of p.
+func (qq *queryQueue) memoryBytesQuotaPerQuery(p query.Priority) int64 {
+	return qq.byName[p].config.MemoryBytesQuotaPerQuery
+}
+
+// classQueue holds the waiting queries of a priority class.
+type classQueue struct {
+	priority query.Priority
+	config   PriorityConfig
+
+	// len is the number of waiting queries and running the number of
+	// executing ones.
+	len     int
+	running int
+
+	orgs map[influxdb.ID]*orgQueue
+	// active lists the organizations with waiting queries in the order
+	// they are served, and next is the index of the one served next.
+	active []*orgQueue
+	next   int
+}
+
+type orgQueue struct {
+	id      influxdb.ID
+	queries []*Query
+	// served is the number of queries taken in the current turn.
+	served int
+}
+
+func (cq *classQueue) push(q *Query, orgID influxdb.ID) {
+	oq, ok := cq.orgs[orgID]
+	if !ok {
+		oq = &orgQueue{id: orgID}
+		cq.orgs[orgID] = oq
+		cq.active = append(cq.active, oq)
+	}
+	oq.queries = append(oq.queries, q)
+	cq.len++
+}
+
+// pop takes a query from the organization whose turn it is. An
+// organization keeps its turn for as many queries as its weight.
+func (cq *classQueue) pop(weight func(influxdb.ID) int) *Query {
+	oq := cq.active[cq.next]
+	q := oq.queries[0]
+	oq.queries[0] = nil
+	oq.queries = oq.queries[1:]
+	oq.served++
+	cq.len--
+
+	switch {
+	case len(oq.queries) == 0:
+		delete(cq.orgs, oq.id)
+		cq.active = append(cq.active[:cq.next], cq.active[cq.next+1:]...)
+		if cq.next >= len(cq.active) {
+			cq.next = 0
+		}
+	case oq.served >= weight(oq.id):
+		oq.served = 0
+		cq.next = (cq.next + 1) % len(cq.active)
+	}
+	return q
+}
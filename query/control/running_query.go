@@ -0,0 +1,101 @@
+package control
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
+)
+
+var _ influxdb.RunningQueryService = (*Controller)(nil)
+
+// ErrRunningQueryNotFound is returned when a query is not running.
+var ErrRunningQueryNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "query not found",
+}
+
+// errQueryKilled is the error of a query canceled with CancelRunningQuery.
+var errQueryKilled = &flux.Error{
+	Code: codes.Canceled,
+	Msg:  "query was killed",
+}
+
+// FindRunningQueryByID returns the query with the ID if it has not finished.
+func (c *Controller) FindRunningQueryByID(ctx context.Context, id influxdb.ID) (*influxdb.RunningQuery, error) {
+	q := c.findQuery(id)
+	if q == nil {
+		return nil, ErrRunningQueryNotFound
+	}
+	return q.runningQuery(time.Now()), nil
+}
+
+// FindRunningQueries returns the queries that have not finished, oldest
+// first.
+func (c *Controller) FindRunningQueries(ctx context.Context, filter influxdb.RunningQueryFilter) ([]*influxdb.RunningQuery, error) {
+	now := time.Now()
+	rqs := make([]*influxdb.RunningQuery, 0)
+	for _, q := range c.Queries() {
+		rq := q.runningQuery(now)
+		if filter.OrgID != nil && rq.OrgID != *filter.OrgID {
+			continue
+		}
+		rqs = append(rqs, rq)
+	}
+	sort.Slice(rqs, func(i, j int) bool {
+		return rqs[i].ID < rqs[j].ID
+	})
+	return rqs, nil
+}
+
+// CancelRunningQuery cancels the query with the ID.
+func (c *Controller) CancelRunningQuery(ctx context.Context, id influxdb.ID) error {
+	q := c.findQuery(id)
+	if q == nil {
+		return ErrRunningQueryNotFound
+	}
+	q.stateMu.Lock()
+	q.killed = true
+	q.stateMu.Unlock()
+	q.Cancel()
+	return nil
+}
+
+func (c *Controller) findQuery(id influxdb.ID) *Query {
+	c.queriesMu.RLock()
+	defer c.queriesMu.RUnlock()
+	return c.queries[QueryID(id)]
+}
+
+// runningQuery describes the query as it is at now.
+func (q *Query) runningQuery(now time.Time) *influxdb.RunningQuery {
+	rq := &influxdb.RunningQuery{
+		ID:        influxdb.ID(q.id),
+		State:     q.State().String(),
+		CreatedAt: q.createdAt,
+		Elapsed:   influxdb.Duration{Duration: now.Sub(q.createdAt)},
+	}
+	if req := q.request; req != nil {
+		rq.OrgID = req.OrganizationID
+		rq.Source = req.Source
+		if req.Authorization != nil {
+			rq.AuthorizationID = req.Authorization.ID
+			rq.UserID = req.Authorization.UserID
+		}
+		if req.Compiler != nil {
+			rq.CompilerType = string(req.Compiler.CompilerType())
+			rq.Query = query.QueryText(req.Compiler)
+		}
+	}
+
+	q.stateMu.RLock()
+	if q.alloc != nil {
+		rq.MemoryBytes = q.alloc.Allocated()
+	}
+	q.stateMu.RUnlock()
+	return rq
+}
@@ -0,0 +1,402 @@
+// Package explain reports how Flux queries are planned and, optionally,
+// how long each operation of their plan takes to run.
+//
+// A query is explained by running it with a Compiler, which wraps the
+// compiler of the query. The plan of the query, and its profile if it was
+// requested, are reported in the metadata of the statistics of the query.
+package explain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
+	"go.uber.org/zap"
+)
+
+// CompilerType is the type of the explain compiler.
+const CompilerType = "explain"
+
+// MetadataKey is the key of the Explanation in the metadata of the
+// statistics of an explained query.
+const MetadataKey = "influxdb/explain"
+
+// storageKinds are the kinds of the operations that read from storage.
+var storageKinds = map[plan.ProcedureKind]bool{
+	influxdb.ReadRangePhysKind:     true,
+	influxdb.ReadGroupPhysKind:     true,
+	influxdb.ReadLastPhysKind:      true,
+	influxdb.ReadTagKeysPhysKind:   true,
+	influxdb.ReadTagValuesPhysKind: true,
+}
+
+// Explanation is the plan of a query.
+type Explanation struct {
+	// Logical is the plan of the query before the physical planner
+	// rules, including the pushdowns into storage, are applied.
+	Logical []Node `json:"logical"`
+	// Physical is the plan that is executed.
+	Physical []Node `json:"physical"`
+	// PushedDown are the IDs of the logical operations that storage
+	// performs.
+	PushedDown []string `json:"pushedDown"`
+	// Profile is how the operations of the physical plan ran. It is only
+	// set when the query was profiled.
+	Profile []OperatorProfile `json:"profile,omitempty"`
+}
+
+// Node is an operation of a plan.
+type Node struct {
+	ID           string   `json:"id"`
+	Kind         string   `json:"kind"`
+	Predecessors []string `json:"predecessors"`
+	// Details describes the operation, for the operations that support it.
+	Details string `json:"details,omitempty"`
+	// Operations are the IDs of the logical operations that a physical
+	// operation performs.
+	Operations []string `json:"operations,omitempty"`
+	// Storage is set on the physical operations that storage performs.
+	Storage bool `json:"storage,omitempty"`
+}
+
+// FromStatistics returns the Explanation in the metadata of stats.
+func FromStatistics(stats flux.Statistics) (*Explanation, bool) {
+	for _, v := range stats.Metadata[MetadataKey] {
+		if e, ok := v.(*Explanation); ok {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+// Compiler explains the Flux program of another compiler. Without
+// Profile, the program is planned but does not run, and has no results.
+//
+// Options of the planner package of Flux are not applied to the plan.
+type Compiler struct {
+	Compiler flux.Compiler `json:"compiler"`
+	Profile  bool          `json:"profile"`
+}
+
+func (c Compiler) Compile(ctx context.Context) (flux.Program, error) {
+	prog, err := c.Compiler.Compile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := prog.(*lang.AstProgram)
+	if !ok {
+		return nil, fmt.Errorf("cannot explain queries of compiler type %s", c.Compiler.CompilerType())
+	}
+
+	// The extern of a Flux compiler is an option of its program that
+	// is only applied when the program starts.
+	pkg := p.Ast
+	if fc, ok := c.Compiler.(lang.FluxCompiler); ok && fc.Extern != nil {
+		pkg = pkg.Copy().(*ast.Package)
+		pkg.Files = append([]*ast.File{fc.Extern}, pkg.Files...)
+	}
+	return &program{
+		pkg:     pkg,
+		now:     p.Now,
+		profile: c.Profile,
+	}, nil
+}
+
+func (c Compiler) CompilerType() flux.CompilerType {
+	return CompilerType
+}
+
+// program plans a Flux AST when it starts, and runs the plan if it is
+// profiled.
+type program struct {
+	pkg     *ast.Package
+	now     time.Time
+	profile bool
+	logger  *zap.Logger
+}
+
+func (p *program) SetLogger(logger *zap.Logger) {
+	p.logger = logger
+}
+
+func (p *program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Query, error) {
+	start := time.Now()
+	spec, err := p.spec(ctx, alloc)
+	if err != nil {
+		return nil, err
+	}
+
+	lp := plan.NewLogicalPlanner()
+	ps, err := lp.CreateInitialPlan(spec)
+	if err != nil {
+		return nil, err
+	}
+	if ps, err = lp.Plan(ps); err != nil {
+		return nil, err
+	}
+	// The physical planner rewrites the nodes of the logical plan.
+	logical := nodes(ps)
+	if ps, err = plan.NewPhysicalPlanner().Plan(ps); err != nil {
+		return nil, err
+	}
+	e := &Explanation{
+		Logical:  logical,
+		Physical: physicalNodes(ps, logical),
+	}
+	for _, n := range e.Physical {
+		if n.Storage {
+			e.PushedDown = append(e.PushedDown, n.Operations...)
+		}
+	}
+	sort.Strings(e.PushedDown)
+
+	if !p.profile {
+		return newExplainedQuery(e), nil
+	}
+	prof := insertProfilers(ps, start)
+	q, err := (&lang.Program{Logger: p.logger, PlanSpec: ps}).Start(ctx, alloc)
+	if err != nil {
+		return nil, err
+	}
+	return &profiledQuery{Query: q, e: e, prof: prof}, nil
+}
+
+// spec evaluates the AST of the program into the spec of the query.
+func (p *program) spec(ctx context.Context, alloc *memory.Allocator) (*flux.Spec, error) {
+	// Functions like tableFind run queries while the AST is evaluated.
+	ctx = lang.ExecutionDependencies{
+		Allocator: alloc,
+		Logger:    p.logger,
+	}.Inject(ctx)
+
+	now := p.now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	sideEffects, scope, err := flux.EvalAST(ctx, p.pkg, flux.SetNowOption(now))
+	if err != nil {
+		return nil, err
+	}
+	if nowOpt, ok := scope.Lookup(flux.NowOption); ok {
+		v, err := nowOpt.Function().Call(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		now = v.Time().Time()
+	}
+
+	spec := &flux.Spec{Now: now}
+	ids := &ider{ids: make(map[*flux.TableObject]flux.OperationID)}
+	visited := make(map[*flux.TableObject]bool)
+	for _, se := range sideEffects {
+		if t, ok := se.Value.(*flux.TableObject); ok && !visited[t] {
+			addOperations(spec, t, ids, visited)
+		}
+	}
+	if len(spec.Operations) == 0 {
+		return nil, fmt.Errorf("this Flux script returns no streaming data")
+	}
+	return spec, nil
+}
+
+// ider names operations after their kind and the order in which they are
+// added to a spec, like Flux programs do.
+type ider struct {
+	ids map[*flux.TableObject]flux.OperationID
+}
+
+func (i *ider) ID(t *flux.TableObject) flux.OperationID {
+	id, ok := i.ids[t]
+	if !ok {
+		id = flux.OperationID(fmt.Sprintf("%s%d", t.Kind, len(i.ids)))
+		i.ids[t] = id
+	}
+	return id
+}
+
+// addOperations adds the operation of t to spec, after those of its
+// parents.
+func addOperations(spec *flux.Spec, t *flux.TableObject, ids *ider, visited map[*flux.TableObject]bool) {
+	visited[t] = true
+	t.Parents.Range(func(i int, v values.Value) {
+		if p := v.(*flux.TableObject); !visited[p] {
+			addOperations(spec, p, ids, visited)
+		}
+	})
+	id := ids.ID(t)
+	t.Parents.Range(func(i int, v values.Value) {
+		spec.Edges = append(spec.Edges, flux.Edge{
+			Parent: ids.ID(v.(*flux.TableObject)),
+			Child:  id,
+		})
+	})
+	spec.Operations = append(spec.Operations, t.Operation(ids))
+}
+
+// walk calls f with the nodes of ps, each after its predecessors. Roots
+// are walked in the order of their IDs.
+func walk(ps *plan.Spec, f func(plan.Node)) {
+	roots := make([]plan.Node, 0, len(ps.Roots))
+	for r := range ps.Roots {
+		roots = append(roots, r)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].ID() < roots[j].ID()
+	})
+
+	visited := make(map[plan.Node]bool)
+	var visit func(n plan.Node)
+	visit = func(n plan.Node) {
+		if visited[n] {
+			return
+		}
+		visited[n] = true
+		for _, pred := range n.Predecessors() {
+			visit(pred)
+		}
+		f(n)
+	}
+	for _, r := range roots {
+		visit(r)
+	}
+}
+
+func nodes(ps *plan.Spec) []Node {
+	var nodes []Node
+	walk(ps, func(n plan.Node) {
+		node := Node{
+			ID:           string(n.ID()),
+			Kind:         string(n.Kind()),
+			Predecessors: make([]string, len(n.Predecessors())),
+		}
+		for i, pred := range n.Predecessors() {
+			node.Predecessors[i] = string(pred.ID())
+		}
+		if d, ok := n.ProcedureSpec().(plan.Detailer); ok {
+			node.Details = d.PlanDetails()
+		}
+		nodes = append(nodes, node)
+	})
+	return nodes
+}
+
+// physicalNodes returns the nodes of the physical plan ps, with the
+// operations of the logical plan that they perform.
+func physicalNodes(ps *plan.Spec, logical []Node) []Node {
+	byID := make(map[string]Node, len(logical))
+	for _, n := range logical {
+		byID[n.ID] = n
+	}
+	physical := make(map[string]bool)
+	walk(ps, func(n plan.Node) {
+		physical[string(n.ID())] = true
+	})
+
+	// rewritten returns id and the predecessors of id, recursively,
+	// that the physical planner rewrote into other operations.
+	var rewritten func(id string) []string
+	rewritten = func(id string) []string {
+		if physical[id] {
+			return nil
+		}
+		var ids []string
+		for _, pred := range byID[id].Predecessors {
+			ids = append(ids, rewritten(pred)...)
+		}
+		return append(ids, id)
+	}
+
+	nodes := nodes(ps)
+	i := 0
+	walk(ps, func(n plan.Node) {
+		node := &nodes[i]
+		i++
+		node.Storage = storageKinds[n.Kind()]
+		if _, ok := byID[node.ID]; ok {
+			node.Operations = []string{node.ID}
+			return
+		}
+		// The planner keeps the order of the predecessors of a node,
+		// so the rewritten operations of a node are found from the
+		// matching predecessor of its successors in the logical plan.
+		for _, succ := range n.Successors() {
+			for i, pred := range succ.Predecessors() {
+				if pred == n && i < len(byID[string(succ.ID())].Predecessors) {
+					node.Operations = append(node.Operations, rewritten(byID[string(succ.ID())].Predecessors[i])...)
+				}
+			}
+		}
+		if len(n.Successors()) == 0 {
+			for _, l := range logical {
+				if !physical[l.ID] && !hasSuccessor(logical, l.ID) {
+					node.Operations = append(node.Operations, rewritten(l.ID)...)
+				}
+			}
+		}
+	})
+	return nodes
+}
+
+// hasSuccessor reports whether a node of nodes has the predecessor id.
+func hasSuccessor(nodes []Node, id string) bool {
+	for _, n := range nodes {
+		for _, pred := range n.Predecessors {
+			if pred == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// explainedQuery is a query that has no results, and the explanation of
+// its plan in its statistics.
+type explainedQuery struct {
+	results chan flux.Result
+	e       *Explanation
+}
+
+func newExplainedQuery(e *Explanation) *explainedQuery {
+	results := make(chan flux.Result)
+	close(results)
+	return &explainedQuery{results: results, e: e}
+}
+
+func (q *explainedQuery) Results() <-chan flux.Result { return q.results }
+func (q *explainedQuery) Done()                       {}
+func (q *explainedQuery) Cancel()                     {}
+func (q *explainedQuery) Err() error                  { return nil }
+
+func (q *explainedQuery) Statistics() flux.Statistics {
+	return flux.Statistics{
+		Metadata: flux.Metadata{MetadataKey: []interface{}{q.e}},
+	}
+}
+
+// profiledQuery adds the explanation of its plan and its profile to the
+// statistics of a query.
+type profiledQuery struct {
+	flux.Query
+	e    *Explanation
+	prof *profile
+}
+
+func (q *profiledQuery) Statistics() flux.Statistics {
+	stats := q.Query.Statistics()
+	e := *q.e
+	e.Profile = q.prof.operators()
+
+	md := make(flux.Metadata, len(stats.Metadata)+1)
+	md.AddAll(stats.Metadata)
+	md.Add(MetadataKey, &e)
+	stats.Metadata = md
+	return stats
+}
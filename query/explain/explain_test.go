@@ -0,0 +1,127 @@
+package explain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	_ "github.com/influxdata/influxdb/query/builtin"
+	"github.com/influxdata/influxdb/query/explain"
+)
+
+func run(t *testing.T, c explain.Compiler) (*explain.Explanation, int) {
+	t.Helper()
+	prog, err := c.Compile(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := prog.Start(context.Background(), &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tables int
+	for res := range q.Results() {
+		if err := res.Tables().Do(func(tbl flux.Table) error {
+			tables++
+			return tbl.Do(func(flux.ColReader) error { return nil })
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		t.Fatal(err)
+	}
+	e, ok := explain.FromStatistics(q.Statistics())
+	if !ok {
+		t.Fatal("expected an explanation in the statistics of the query")
+	}
+	return e, tables
+}
+
+func TestCompiler_Explain(t *testing.T) {
+	e, tables := run(t, explain.Compiler{
+		Compiler: lang.FluxCompiler{
+			Query: `from(bucket: "b") |> range(start: -1h) |> filter(fn: (r) => r._measurement == "m") |> sum()`,
+			Now:   time.Unix(3600, 0),
+		},
+	})
+	if tables != 0 {
+		t.Errorf("expected no results, got %d tables", tables)
+	}
+	want := &explain.Explanation{
+		Logical: []explain.Node{
+			{ID: "influxDBFrom0", Kind: "influxDBFrom", Predecessors: []string{}},
+			{ID: "range1", Kind: "range", Predecessors: []string{"influxDBFrom0"}},
+			{ID: "filter2", Kind: "filter", Predecessors: []string{"range1"}, Details: `r._measurement == "m"`},
+			{ID: "sum3", Kind: "sum", Predecessors: []string{"filter2"}},
+			{ID: "generated_yield", Kind: "generatedYield", Predecessors: []string{"sum3"}},
+		},
+		Physical: []explain.Node{
+			{
+				ID:           "merged_ReadRange_filter2",
+				Kind:         "ReadRangePhysKind",
+				Predecessors: []string{},
+				Operations:   []string{"influxDBFrom0", "range1", "filter2"},
+				Storage:      true,
+			},
+			{ID: "sum3", Kind: "sum", Predecessors: []string{"merged_ReadRange_filter2"}, Operations: []string{"sum3"}},
+			{ID: "generated_yield", Kind: "generatedYield", Predecessors: []string{"sum3"}, Operations: []string{"generated_yield"}},
+		},
+		PushedDown: []string{"filter2", "influxDBFrom0", "range1"},
+	}
+	if !cmp.Equal(want, e) {
+		t.Errorf("unexpected explanation -want/+got:\n%s", cmp.Diff(want, e))
+	}
+}
+
+func TestCompiler_Profile(t *testing.T) {
+	e, tables := run(t, explain.Compiler{
+		Compiler: lang.FluxCompiler{
+			Query: `import "csv"
+
+data = "
+#datatype,string,long,string,double
+#group,false,false,true,false
+#default,_result,,,
+,result,table,host,_value
+,,0,a,1.0
+,,0,a,2.0
+,,1,b,3.0
+"
+
+csv.from(csv: data) |> filter(fn: (r) => r._value > 1.0) |> sum()`,
+		},
+		Profile: true,
+	})
+	if tables != 2 {
+		t.Errorf("expected 2 tables, got %d", tables)
+	}
+
+	type count struct {
+		ID           string
+		Tables, Rows int64
+	}
+	var got []count
+	for _, op := range e.Profile {
+		if op.Error != "" || op.Finished < op.FirstTable || op.Duration > op.Finished {
+			t.Errorf("unexpected profile of %s: %+v", op.ID, op)
+		}
+		got = append(got, count{ID: op.ID, Tables: op.Tables, Rows: op.Rows})
+	}
+	want := []count{
+		{ID: "fromCSV0", Tables: 2, Rows: 3},
+		{ID: "filter1", Tables: 2, Rows: 2},
+		{ID: "sum2", Tables: 2, Rows: 2},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected profile -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	if len(e.PushedDown) != 0 {
+		t.Errorf("expected no operations pushed down, got %v", e.PushedDown)
+	}
+}
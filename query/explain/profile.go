@@ -0,0 +1,257 @@
+package explain
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+)
+
+// ProfilerKind is the kind of the operations that profile the operation
+// preceding them.
+const ProfilerKind = "influxdb/profiler"
+
+func init() {
+	execute.RegisterTransformation(ProfilerKind, createProfilerTransformation)
+}
+
+// OperatorProfile is how an operation of a physical plan ran. Times are
+// measured from the start of the query.
+type OperatorProfile struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Tables and Rows are the number of tables and rows that the
+	// operation produced.
+	Tables int64 `json:"tables"`
+	Rows   int64 `json:"rows"`
+	// FirstTable is when the operation produced its first table, and
+	// Finished is when it produced its last.
+	FirstTable time.Duration `json:"firstTable"`
+	Finished   time.Duration `json:"finished"`
+	// Duration is the time from when the predecessors of the operation
+	// finished, or the query started for a source, until it finished.
+	Duration time.Duration `json:"duration"`
+	// ReadDuration is the time spent reading the rows of the tables of the
+	// operation, apart from the time their readers took to process them.
+	// Sources read from storage while their tables are read.
+	ReadDuration time.Duration `json:"readDuration"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// profile gathers the profiles of the operations of a plan.
+type profile struct {
+	start time.Time
+
+	mu sync.Mutex
+	// ops are the profiles in the order of the physical plan, and preds
+	// are the indexes of the predecessors of each operation.
+	ops   []OperatorProfile
+	preds [][]int
+}
+
+func (p *profile) update(i int, f func(op *OperatorProfile)) {
+	p.mu.Lock()
+	f(&p.ops[i])
+	p.mu.Unlock()
+}
+
+// operators returns a copy of the profiles of the operations.
+func (p *profile) operators() []OperatorProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ops := make([]OperatorProfile, len(p.ops))
+	copy(ops, p.ops)
+	for i := range ops {
+		var start time.Duration
+		for _, j := range p.preds[i] {
+			if ops[j].Finished > start {
+				start = ops[j].Finished
+			}
+		}
+		if ops[i].Finished > start {
+			ops[i].Duration = ops[i].Finished - start
+		}
+	}
+	return ops
+}
+
+// insertProfilers inserts an operation after each operation of ps that
+// has successors, which profiles it. Yields and the operations without
+// successors, which write data, are not profiled.
+func insertProfilers(ps *plan.Spec, start time.Time) *profile {
+	p := &profile{start: start}
+	index := make(map[plan.Node]int)
+	walk(ps, func(n plan.Node) {
+		if _, ok := n.ProcedureSpec().(plan.YieldProcedureSpec); ok || len(n.Successors()) == 0 {
+			return
+		}
+		i := len(p.ops)
+		index[n] = i
+		p.ops = append(p.ops, OperatorProfile{
+			ID:   string(n.ID()),
+			Kind: string(n.Kind()),
+		})
+		var preds []int
+		for _, pred := range n.Predecessors() {
+			if j, ok := index[pred]; ok {
+				preds = append(preds, j)
+			}
+		}
+		p.preds = append(p.preds, preds)
+	})
+
+	for n, i := range index {
+		profiler := plan.CreatePhysicalNode(plan.NodeID("profile_"+string(n.ID())), &ProfilerProcedureSpec{
+			profile: p,
+			index:   i,
+		})
+		succs := make([]plan.Node, len(n.Successors()))
+		copy(succs, n.Successors())
+		for _, succ := range succs {
+			preds := succ.Predecessors()
+			for j := range preds {
+				if preds[j] == n {
+					preds[j] = profiler
+				}
+			}
+		}
+		n.ClearSuccessors()
+		n.AddSuccessors(profiler)
+		profiler.AddPredecessors(n)
+		profiler.AddSuccessors(succs...)
+	}
+	return p
+}
+
+// ProfilerProcedureSpec is the spec of the operations that profile the
+// operation preceding them.
+type ProfilerProcedureSpec struct {
+	profile *profile
+	index   int
+}
+
+func (s *ProfilerProcedureSpec) Kind() plan.ProcedureKind {
+	return ProfilerKind
+}
+
+func (s *ProfilerProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func (s *ProfilerProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	return plan.Cost{}, inStats[0]
+}
+
+func createProfilerTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s := spec.(*ProfilerProcedureSpec)
+	d := &profilerDataset{id: id}
+	t := &profilerTransformation{
+		d:       d,
+		profile: s.profile,
+		index:   s.index,
+	}
+	return t, d, nil
+}
+
+// profilerTransformation passes the tables of the operation preceding it
+// on to its dataset, and profiles them.
+type profilerTransformation struct {
+	d       *profilerDataset
+	profile *profile
+	index   int
+}
+
+func (t *profilerTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	since := time.Since(t.profile.start)
+	t.profile.update(t.index, func(op *OperatorProfile) {
+		if op.Tables == 0 {
+			op.FirstTable = since
+		}
+		op.Tables++
+	})
+	return t.d.ts.Process(t.d.id, &profiledTable{Table: tbl, t: t})
+}
+
+func (t *profilerTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *profilerTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *profilerTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *profilerTransformation) Finish(id execute.DatasetID, err error) {
+	since := time.Since(t.profile.start)
+	t.profile.update(t.index, func(op *OperatorProfile) {
+		op.Finished = since
+		if err != nil {
+			op.Error = err.Error()
+		}
+	})
+	t.d.Finish(err)
+}
+
+// profilerDataset passes everything on to the transformations that read
+// from it.
+type profilerDataset struct {
+	id execute.DatasetID
+	ts execute.TransformationSet
+}
+
+func (d *profilerDataset) AddTransformation(t execute.Transformation) {
+	d.ts = append(d.ts, t)
+}
+
+func (d *profilerDataset) RetractTable(key flux.GroupKey) error {
+	return d.ts.RetractTable(d.id, key)
+}
+
+func (d *profilerDataset) UpdateProcessingTime(t execute.Time) error {
+	return d.ts.UpdateProcessingTime(d.id, t)
+}
+
+func (d *profilerDataset) UpdateWatermark(mark execute.Time) error {
+	return d.ts.UpdateWatermark(d.id, mark)
+}
+
+func (d *profilerDataset) Finish(err error) {
+	d.ts.Finish(d.id, err)
+}
+
+func (d *profilerDataset) SetTriggerSpec(plan.TriggerSpec) {}
+
+// profiledTable counts the rows of a table and times the reading of them.
+type profiledTable struct {
+	flux.Table
+	t *profilerTransformation
+}
+
+func (tbl *profiledTable) Do(f func(flux.ColReader) error) error {
+	var (
+		start   = time.Now()
+		rows    int64
+		readers time.Duration
+	)
+	err := tbl.Table.Do(func(cr flux.ColReader) error {
+		rows += int64(cr.Len())
+		start := time.Now()
+		defer func() {
+			readers += time.Since(start)
+		}()
+		return f(cr)
+	})
+	read := time.Since(start) - readers
+	tbl.t.profile.update(tbl.t.index, func(op *OperatorProfile) {
+		op.Rows += rows
+		op.ReadDuration += read
+	})
+	return err
+}
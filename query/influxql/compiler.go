@@ -8,6 +8,7 @@ import (
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/plan"
 	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/query"
 )
 
 const CompilerType = "influxql"
@@ -27,6 +28,8 @@ type Compiler struct {
 	Bucket  string     `json:"bucket,omitempty"`
 	Query   string     `json:"query"`
 	Now     *time.Time `json:"now,omitempty"`
+	// Params are bound to the parameters of the query, like $host.
+	Params query.Params `json:"params,omitempty"`
 
 	logicalPlannerOptions []plan.LogicalOption
 
@@ -57,6 +60,7 @@ func (c *Compiler) Compile(ctx context.Context) (flux.Program, error) {
 			DefaultDatabase:        c.DB,
 			DefaultRetentionPolicy: c.RP,
 			Now:                    now,
+			Params:                 c.Params,
 		},
 	)
 	astPkg, err := transpiler.Transpile(ctx, c.Query)
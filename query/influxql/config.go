@@ -2,6 +2,8 @@ package influxql
 
 import (
 	"time"
+
+	"github.com/influxdata/influxdb/query"
 )
 
 // Config modifies the behavior of the Transpiler.
@@ -16,4 +18,6 @@ type Config struct {
 	// FallbackToDBRP if true will use the naming convention of `db/rp`
 	// for a bucket name when an mapping is not found
 	FallbackToDBRP bool
+	// Params are bound to the parameters of the query, like $host.
+	Params query.Params
 }
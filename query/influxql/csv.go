@@ -0,0 +1,141 @@
+package influxql
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/iocounter"
+)
+
+// CSVMultiResultEncoder encodes results in the InfluxQL CSV format. Every
+// line starts with the name and tags of its series, followed by the values.
+// A header line is written whenever the columns change.
+type CSVMultiResultEncoder struct {
+	// TimeFormat is the format of the time values. Times are written as
+	// nanoseconds since the unix epoch unless an epoch unit is given.
+	TimeFormat TimeFormat
+}
+
+// Encode writes a collection of results to the influxdb 1.X CSV format.
+func (e *CSVMultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	wc := &iocounter.Writer{Writer: w}
+	cw := csv.NewWriter(wc)
+
+	tf := e.TimeFormat
+	if tf == RFC3339Nano {
+		tf = Nanosecond
+	}
+
+	var columns []string
+	writeHeader := func(cols []string) error {
+		if equalColumns(columns, cols) {
+			return nil
+		}
+		if columns != nil {
+			// Separate blocks with different columns with an empty line.
+			cw.Flush()
+			if _, err := io.WriteString(wc, "\n"); err != nil {
+				return err
+			}
+		}
+		columns = cols
+		return cw.Write(cols)
+	}
+
+	writeError := func(err error) error {
+		if err := writeHeader([]string{"error"}); err != nil {
+			return err
+		}
+		return cw.Write([]string{err.Error()})
+	}
+
+	for results.More() {
+		result, err := encodeResult(results.Next(), tf)
+		if err != nil {
+			results.Release()
+			if err := writeError(err); err != nil {
+				return wc.Count(), err
+			}
+			break
+		}
+
+		for _, row := range result.Series {
+			if err := writeHeader(append([]string{"name", "tags"}, row.Columns...)); err != nil {
+				return wc.Count(), err
+			}
+
+			tags := formatTags(row.Tags)
+			for _, values := range row.Values {
+				record := make([]string, 0, len(values)+2)
+				record = append(record, row.Name, tags)
+				for _, v := range values {
+					record = append(record, formatCSVValue(v))
+				}
+				if err := cw.Write(record); err != nil {
+					return wc.Count(), err
+				}
+			}
+		}
+	}
+
+	if err := results.Err(); err != nil {
+		if err := writeError(err); err != nil {
+			return wc.Count(), err
+		}
+	}
+
+	cw.Flush()
+	return wc.Count(), cw.Error()
+}
+
+// formatTags returns tags as a comma-separated list of key=value pairs
+// sorted by key.
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + tags[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+func formatCSVValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func equalColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
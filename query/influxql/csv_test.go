@@ -0,0 +1,112 @@
+package influxql_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query/influxql"
+)
+
+func TestCSVMultiResultEncoder_Encode(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		enc  *influxql.CSVMultiResultEncoder
+		in   flux.ResultIterator
+		out  string
+	}{
+		{
+			name: "Default",
+			enc:  &influxql.CSVMultiResultEncoder{},
+			in: flux.NewSliceResultIterator(
+				[]flux.Result{
+					&executetest.Result{
+						Nm: "0",
+						Tbls: []*executetest.Table{{
+							KeyCols: []string{"_measurement", "host", "region"},
+							ColMeta: []flux.ColMeta{
+								{Label: "_time", Type: flux.TTime},
+								{Label: "_measurement", Type: flux.TString},
+								{Label: "host", Type: flux.TString},
+								{Label: "region", Type: flux.TString},
+								{Label: "value", Type: flux.TFloat},
+							},
+							Data: [][]interface{}{
+								{ts("2018-05-24T09:00:00Z"), "m0", "server01", "west", float64(2.5)},
+								{ts("2018-05-24T09:00:10Z"), "m0", "server01", "west", nil},
+							},
+						}},
+					},
+					&executetest.Result{
+						Nm: "1",
+						Tbls: []*executetest.Table{{
+							KeyCols: []string{},
+							ColMeta: []flux.ColMeta{
+								{Label: "name", Type: flux.TString},
+							},
+							Data: [][]interface{}{
+								{"telegraf"},
+							},
+						}},
+					},
+				},
+			),
+			out: `name,tags,time,value
+m0,"host=server01,region=west",1527152400000000000,2.5
+m0,"host=server01,region=west",1527152410000000000,
+
+name,tags,name
+,,telegraf
+`,
+		},
+		{
+			name: "Epoch",
+			enc:  &influxql.CSVMultiResultEncoder{TimeFormat: influxql.Second},
+			in: flux.NewSliceResultIterator(
+				[]flux.Result{&executetest.Result{
+					Nm: "0",
+					Tbls: []*executetest.Table{{
+						KeyCols: []string{"_measurement"},
+						ColMeta: []flux.ColMeta{
+							{Label: "_time", Type: flux.TTime},
+							{Label: "_measurement", Type: flux.TString},
+							{Label: "value", Type: flux.TInt},
+						},
+						Data: [][]interface{}{
+							{ts("2018-05-24T09:00:00Z"), "m0", int64(2)},
+						},
+					}},
+				}},
+			),
+			out: `name,tags,time,value
+m0,,1527152400,2
+`,
+		},
+		{
+			name: "Error",
+			enc:  &influxql.CSVMultiResultEncoder{},
+			in: &resultErrorIterator{
+				Error: "expected",
+			},
+			out: `error
+expected
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.enc.Encode(&buf, tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got, exp := buf.String(), tt.out; got != exp {
+				t.Fatalf("unexpected output:\nexp=%s\ngot=%s", exp, got)
+			}
+			if got, exp := n, int64(len(tt.out)); got != exp {
+				t.Errorf("unexpected encoding count: got=%d exp=%d", got, exp)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package influxql
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/influxql"
+	pkgerrors "github.com/pkg/errors"
 )
 
 // cursor is holds known information about the current stream. It maps the influxql ast information
@@ -33,66 +36,24 @@ type varRefCursor struct {
 // createVarRefCursor creates a new cursor from a variable reference using the sources
 // in the transpilerState.
 func createVarRefCursor(t *transpilerState, ref *influxql.VarRef) (cursor, error) {
-	if len(t.stmt.Sources) != 1 {
-		// TODO(jsternberg): Support multiple sources.
-		return nil, errors.New("unimplemented: only one source is allowed")
-	}
-
-	// Only support a direct measurement. Subqueries are not supported yet.
-	mm, ok := t.stmt.Sources[0].(*influxql.Measurement)
-	if !ok {
-		return nil, errors.New("unimplemented: source must be a measurement")
-	}
-
-	// Create the from spec and add it to the list of operations.
-	from, err := t.from(mm)
-	if err != nil {
-		return nil, err
-	}
-
-	valuer := influxql.NowValuer{Now: t.config.Now}
-	_, tr, err := influxql.ConditionExpr(t.stmt.Condition, &valuer)
+	src, err := t.source()
 	if err != nil {
 		return nil, err
 	}
 
-	// If the maximum is not set and we have a windowing function, then
-	// the end time will be set to now.
-	if tr.Max.IsZero() {
-		if window, err := t.stmt.GroupByInterval(); err == nil && window > 0 {
-			tr.Max = t.config.Now
-		}
+	var mm *influxql.Measurement
+	switch src := src.(type) {
+	case *influxql.Measurement:
+		mm = src
+	case *influxql.SubQuery:
+		return createSubQueryCursor(t, src, ref)
+	default:
+		return nil, fmt.Errorf("unimplemented: source type %T", src)
 	}
 
-	range_ := &ast.PipeExpression{
-		Argument: from,
-		Call: &ast.CallExpression{
-			Callee: &ast.Identifier{
-				Name: "range",
-			},
-			Arguments: []ast.Expression{
-				&ast.ObjectExpression{
-					Properties: []*ast.Property{
-						{
-							Key: &ast.Identifier{
-								Name: "start",
-							},
-							Value: &ast.DateTimeLiteral{
-								Value: tr.MinTime().UTC(),
-							},
-						},
-						{
-							Key: &ast.Identifier{
-								Name: "stop",
-							},
-							Value: &ast.DateTimeLiteral{
-								Value: tr.MaxTime().UTC(),
-							},
-						},
-					},
-				},
-			},
-		},
+	range_, err := t.readRange(mm)
+	if err != nil {
+		return nil, err
 	}
 
 	expr := &ast.PipeExpression{
@@ -150,6 +111,180 @@ func createVarRefCursor(t *transpilerState, ref *influxql.VarRef) (cursor, error
 	}, nil
 }
 
+// source returns the source of the statement.
+func (t *transpilerState) source() (influxql.Source, error) {
+	if len(t.stmt.Sources) != 1 {
+		// TODO(jsternberg): Support multiple sources.
+		return nil, errors.New("unimplemented: only one source is allowed")
+	}
+	return t.stmt.Sources[0], nil
+}
+
+// readRange reads the measurement from its bucket within the time range
+// of the condition.
+func (t *transpilerState) readRange(mm *influxql.Measurement) (ast.Expression, error) {
+	// Create the from spec and add it to the list of operations.
+	from, err := t.from(mm)
+	if err != nil {
+		return nil, err
+	}
+
+	tr, err := t.timeRange()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ast.PipeExpression{
+		Argument: from,
+		Call: &ast.CallExpression{
+			Callee: &ast.Identifier{
+				Name: "range",
+			},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{
+						{
+							Key: &ast.Identifier{
+								Name: "start",
+							},
+							Value: &ast.DateTimeLiteral{
+								Value: tr.MinTime().UTC(),
+							},
+						},
+						{
+							Key: &ast.Identifier{
+								Name: "stop",
+							},
+							Value: &ast.DateTimeLiteral{
+								Value: tr.MaxTime().UTC(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// timeRange returns the time range of the condition.
+func (t *transpilerState) timeRange() (influxql.TimeRange, error) {
+	valuer := influxql.NowValuer{Now: t.config.Now}
+	_, tr, err := influxql.ConditionExpr(t.stmt.Condition, &valuer)
+	if err != nil {
+		return influxql.TimeRange{}, err
+	}
+
+	// If the maximum is not set and we have a windowing function, then
+	// the end time will be set to now.
+	if tr.Max.IsZero() {
+		if window, err := t.stmt.GroupByInterval(); err == nil && window > 0 {
+			tr.Max = t.config.Now
+		}
+	}
+	return tr, nil
+}
+
+// createSubQueryCursor creates a new cursor from a variable reference that reads
+// the column with the same name from the results of the subquery.
+func createSubQueryCursor(t *transpilerState, sq *influxql.SubQuery, ref *influxql.VarRef) (cursor, error) {
+	expr, err := t.subquery(sq)
+	if err != nil {
+		return nil, err
+	}
+
+	// The columns of the subquery are only available before they are mapped
+	// to the value column so the condition is evaluated here instead of
+	// when the cursors of the group are joined.
+	valuer := influxql.NowValuer{Now: t.config.Now}
+	cond, _, err := influxql.ConditionExpr(t.stmt.Condition, &valuer)
+	if err != nil {
+		return nil, err
+	} else if cond != nil {
+		fn, err := t.mapField(cond, &columnsCursor{})
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "unable to evaluate condition")
+		}
+		expr = &ast.PipeExpression{
+			Argument: expr,
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "filter",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{{
+							Key: &ast.Identifier{Name: "fn"},
+							Value: &ast.FunctionExpression{
+								Params: []*ast.Property{{
+									Key: &ast.Identifier{Name: "r"},
+								}},
+								Body: fn,
+							},
+						}},
+					},
+				},
+			},
+		}
+	}
+
+	// Map the column to the value column while keeping the tags
+	// the statement is grouped by.
+	properties := []*ast.Property{
+		{
+			Key:   &ast.Identifier{Name: execute.DefaultTimeColLabel},
+			Value: column(execute.DefaultTimeColLabel),
+		},
+		{
+			Key:   &ast.Identifier{Name: execute.DefaultValueColLabel},
+			Value: column(ref.Val),
+		},
+	}
+	for _, d := range t.stmt.Dimensions {
+		if dim, ok := d.Expr.(*influxql.VarRef); ok {
+			properties = append(properties, &ast.Property{
+				Key:   &ast.Identifier{Name: dim.Val},
+				Value: column(dim.Val),
+			})
+		}
+	}
+	return &varRefCursor{
+		expr: &ast.PipeExpression{
+			Argument: expr,
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "map",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key: &ast.Identifier{
+									Name: "fn",
+								},
+								Value: &ast.FunctionExpression{
+									Params: []*ast.Property{{
+										Key: &ast.Identifier{Name: "r"},
+									}},
+									Body: &ast.ObjectExpression{
+										Properties: properties,
+									},
+								},
+							},
+							{
+								Key: &ast.Identifier{
+									Name: "mergeKey",
+								},
+								Value: &ast.BooleanLiteral{Value: true},
+							},
+						},
+					},
+				},
+			},
+		},
+		ref: ref,
+	}, nil
+}
+
 func (c *varRefCursor) Expr() ast.Expression {
 	return c.expr
 }
@@ -179,3 +314,83 @@ type pipeCursor struct {
 }
 
 func (c *pipeCursor) Expr() ast.Expression { return c.expr }
+
+// subquery transpiles the subquery within the time range of the outer statement
+// and returns the identifier the results are assigned to.
+func (t *transpilerState) subquery(sq *influxql.SubQuery) (ast.Expression, error) {
+	if ident, ok := t.subqueries[sq]; ok {
+		return ident, nil
+	}
+
+	if len(sq.Statement.SortFields) > 0 && sq.Statement.TimeAscending() != t.stmt.TimeAscending() {
+		return nil, errors.New("subqueries must be ordered in the same direction as the query itself")
+	}
+
+	tr, err := t.timeRange()
+	if err != nil {
+		return nil, err
+	}
+
+	// Restrict the subquery to the time range of the outer statement so
+	// both read the same window of data.
+	stmt := sq.Statement.Clone()
+	if !tr.Min.IsZero() {
+		stmt.Condition = conjunction(stmt.Condition, &influxql.BinaryExpr{
+			Op:  influxql.GTE,
+			LHS: &influxql.VarRef{Val: "time"},
+			RHS: &influxql.TimeLiteral{Val: tr.Min},
+		})
+	}
+	if !tr.Max.IsZero() {
+		stmt.Condition = conjunction(stmt.Condition, &influxql.BinaryExpr{
+			Op:  influxql.LTE,
+			LHS: &influxql.VarRef{Val: "time"},
+			RHS: &influxql.TimeLiteral{Val: tr.Max},
+		})
+	}
+
+	outer := t.stmt
+	cur, err := t.transpileSelect(context.TODO(), stmt)
+	t.stmt = outer
+	if err != nil {
+		return nil, err
+	}
+
+	ident := t.assignment(cur.Expr())
+	t.subqueries[sq] = ident
+	return ident, nil
+}
+
+// conjunction combines the condition with another expression using AND.
+func conjunction(cond, expr influxql.Expr) influxql.Expr {
+	if cond == nil {
+		return expr
+	}
+	return &influxql.BinaryExpr{Op: influxql.AND, LHS: cond, RHS: expr}
+}
+
+// columnsCursor is a pseudo-cursor for records that hold every variable in the
+// column with the same name, such as the rows of a pivot table or the results
+// of a subquery. The values of a wildcard are read from the value column.
+type columnsCursor struct {
+	expr ast.Expression
+}
+
+func (c *columnsCursor) Expr() ast.Expression {
+	return c.expr
+}
+
+func (c *columnsCursor) Keys() []influxql.Expr {
+	return nil
+}
+
+func (c *columnsCursor) Value(expr influxql.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *influxql.VarRef:
+		return expr.Val, true
+	case *influxql.Wildcard, *influxql.RegexLiteral:
+		return execute.DefaultValueColLabel, true
+	default:
+		return "", false
+	}
+}
@@ -77,7 +77,7 @@ var skipTests = map[string]string{
 	"regex_tag_3":              "Transpiler: Returns results in wrong sort order for regex filter on tags https://github.com/influxdata/influxdb/issues/10739",
 	"explicit_type_0":          "Transpiler should remove _start column https://github.com/influxdata/influxdb/issues/10742",
 	"explicit_type_1":          "Transpiler should remove _start column https://github.com/influxdata/influxdb/issues/10742",
-	"fills_0":                  "need fill/Interpolate function https://github.com/influxdata/flux/issues/436",
+	"fills_0":                  "transpiler does not create the last window of an inclusive time range https://github.com/influxdata/influxdb/issues/10744",
 	"random_math_0":            "transpiler does not implement joining fields within a cursor https://github.com/influxdata/influxdb/issues/10743",
 	"selector_0":               "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
 	"selector_1":               "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
@@ -94,21 +94,20 @@ var skipTests = map[string]string{
 	"series_agg_7":             "Transpiler should remove _start column  https://github.com/influxdata/influxdb/issues/10742",
 	"series_agg_8":             "Transpiler should remove _start column  https://github.com/influxdata/influxdb/issues/10742",
 	"series_agg_9":             "Transpiler should remove _start column  https://github.com/influxdata/influxdb/issues/10742",
-	"Subquery_0":               "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"Subquery_1":               "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"Subquery_2":               "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"Subquery_3":               "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"Subquery_4":               "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"Subquery_5":               "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"NestedSubquery_0":         "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"NestedSubquery_1":         "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"NestedSubquery_2":         "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"NestedSubquery_3":         "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"SimulatedHTTP_0":          "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"SimulatedHTTP_1":          "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"SimulatedHTTP_2":          "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"SimulatedHTTP_3":          "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
-	"SimulatedHTTP_4":          "Implement subqueries in the transpiler https://github.com/influxdata/influxdb/issues/10660",
+	"Subquery_0":               "Transpiler: aggregates without a time range use the minimum time instead of the epoch",
+	"Subquery_1":               "Transpiler: aggregates without a time range use the minimum time instead of the epoch",
+	"Subquery_2":               "transpiler does not implement joining fields within a cursor https://github.com/influxdata/influxdb/issues/10743",
+	"Subquery_3":               "Transpiler: aggregates without a time range use the minimum time instead of the epoch",
+	"Subquery_4":               "transpiler does not implement joining fields within a cursor https://github.com/influxdata/influxdb/issues/10743",
+	"NestedSubquery_0":         "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
+	"NestedSubquery_1":         "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
+	"NestedSubquery_2":         "Transpiler: LIMIT is not implemented",
+	"NestedSubquery_3":         "Transpiler: LIMIT is not implemented",
+	"SimulatedHTTP_0":          "Transpiler: multiple sources are not implemented",
+	"SimulatedHTTP_1":          "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
+	"SimulatedHTTP_2":          "Transpiler: Implement spread https://github.com/influxdata/influxdb/issues/10734",
+	"SimulatedHTTP_3":          "Transpiler: multiple sources are not implemented",
+	"SimulatedHTTP_4":          "Transpiler: multiple sources are not implemented",
 	"SelectorMath_0":           "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
 	"SelectorMath_1":           "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
 	"SelectorMath_2":           "Transpiler: unimplemented functions: top and bottom https://github.com/influxdata/influxdb/issues/10738",
@@ -34,18 +34,38 @@ func parseFunction(expr *influxql.Call) (*function, error) {
 			}, nil
 		case *influxql.Call:
 			if ref.Name == "distinct" {
-				return nil, errors.New("unimplemented: count(distinct)")
+				// Validate the distinct call and count the distinct values of its field.
+				fn, err := parseFunction(ref)
+				if err != nil {
+					return nil, err
+				}
+				return &function{
+					Ref:  fn.Ref,
+					call: expr,
+				}, nil
 			}
 			return nil, fmt.Errorf("expected field argument in %s()", expr.Name)
-		case *influxql.Distinct:
-			return nil, errors.New("unimplemented: count(distinct)")
-		case *influxql.Wildcard:
-			return nil, errors.New("unimplemented: wildcard function")
-		case *influxql.RegexLiteral:
-			return nil, errors.New("unimplemented: wildcard regex function")
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			// The wildcard is expanded to every matching field when the cursor is created.
+			return &function{call: expr}, nil
 		default:
 			return nil, fmt.Errorf("expected field argument in %s()", expr.Name)
 		}
+	case "distinct":
+		if len(expr.Args) == 0 {
+			return nil, errors.New("distinct function requires at least one argument")
+		} else if len(expr.Args) != 1 {
+			return nil, errors.New("distinct function can only have one argument")
+		}
+
+		ref, ok := expr.Args[0].(*influxql.VarRef)
+		if !ok {
+			return nil, fmt.Errorf("expected field argument in %s()", expr.Name)
+		}
+		return &function{
+			Ref:  ref,
+			call: expr,
+		}, nil
 	case "min", "max", "sum", "first", "last", "mean", "median":
 		if exp, got := 1, len(expr.Args); exp != got {
 			return nil, fmt.Errorf("invalid number of arguments for %s, expected %d, got %d", expr.Name, exp, got)
@@ -57,10 +77,8 @@ func parseFunction(expr *influxql.Call) (*function, error) {
 				Ref:  ref,
 				call: expr,
 			}, nil
-		case *influxql.Wildcard:
-			return nil, errors.New("unimplemented: wildcard function")
-		case *influxql.RegexLiteral:
-			return nil, errors.New("unimplemented: wildcard regex function")
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			return &function{call: expr}, nil
 		default:
 			return nil, fmt.Errorf("expected field argument in %s()", expr.Name)
 		}
@@ -74,10 +92,7 @@ func parseFunction(expr *influxql.Call) (*function, error) {
 		switch ref := expr.Args[0].(type) {
 		case *influxql.VarRef:
 			functionRef = ref
-		case *influxql.Wildcard:
-			return nil, errors.New("unimplemented: wildcard function")
-		case *influxql.RegexLiteral:
-			return nil, errors.New("unimplemented: wildcard regex function")
+		case *influxql.Wildcard, *influxql.RegexLiteral:
 		default:
 			return nil, fmt.Errorf("expected field argument in %s()", expr.Name)
 		}
@@ -107,13 +122,27 @@ func createFunctionCursor(t *transpilerState, call *influxql.Call, in cursor, no
 		parent: in,
 	}
 	switch call.Name {
-	case "count", "min", "max", "sum", "first", "last", "mean":
-		value, ok := in.Value(call.Args[0])
+	case "count", "min", "max", "sum", "first", "last", "mean", "distinct":
+		arg, expr := call.Args[0], in.Expr()
+		if distinct, ok := arg.(*influxql.Call); ok && distinct.Name == "distinct" {
+			// Count the distinct values by removing the duplicates first.
+			arg = distinct.Args[0]
+			expr = &ast.PipeExpression{
+				Argument: expr,
+				Call: &ast.CallExpression{
+					Callee: &ast.Identifier{
+						Name: "distinct",
+					},
+				},
+			}
+		}
+
+		value, ok := in.Value(arg)
 		if !ok {
-			return nil, fmt.Errorf("undefined variable: %s", call.Args[0])
+			return nil, fmt.Errorf("undefined variable: %s", arg)
 		}
 		cur.expr = &ast.PipeExpression{
-			Argument: in.Expr(),
+			Argument: expr,
 			Call: &ast.CallExpression{
 				Callee: &ast.Identifier{
 					Name: call.Name,
@@ -121,7 +150,7 @@ func createFunctionCursor(t *transpilerState, call *influxql.Call, in cursor, no
 			},
 		}
 		cur.value = value
-		cur.exclude = map[influxql.Expr]struct{}{call.Args[0]: {}}
+		cur.exclude = map[influxql.Expr]struct{}{arg: {}}
 	case "median":
 		value, ok := in.Value(call.Args[0])
 		if !ok {
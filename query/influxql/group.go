@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/influxql"
 	"github.com/pkg/errors"
 )
@@ -38,9 +39,6 @@ func (v *groupVisitor) Visit(n influxql.Node) influxql.Visitor {
 		}
 		v.calls = append(v.calls, fn)
 		return nil
-	case *influxql.Distinct:
-		v.err = errors.New("unimplemented: distinct expression")
-		return nil
 	case *influxql.VarRef:
 		if expr.Val == "time" {
 			return nil
@@ -65,6 +63,14 @@ func identifyGroups(stmt *influxql.SelectStatement) ([]*groupInfo, error) {
 		return nil, v.err
 	}
 
+	// The distinct function returns multiple values for the same time so it cannot be
+	// combined with anything else.
+	for _, fn := range v.calls {
+		if fn.call.Name == "distinct" && (len(v.calls) > 1 || len(v.refs) > 0) {
+			return nil, errors.New("aggregate function distinct() cannot be combined with other functions or fields")
+		}
+	}
+
 	// Attempt to take the calls and variables and put them into groups.
 	if len(v.refs) > 0 {
 		// If any of the calls are not selectors, we have an error message.
@@ -110,12 +116,24 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 	// TODO(jsternberg): Determine which of these cursors are from fields and which are tags.
 	var cursors []cursor
 	if gr.call != nil {
-		ref, ok := gr.call.Args[0].(*influxql.VarRef)
-		if !ok {
+		arg := gr.call.Args[0]
+		if call, ok := arg.(*influxql.Call); ok && call.Name == "distinct" {
+			arg = call.Args[0]
+		}
+
+		var (
+			cur cursor
+			err error
+		)
+		switch arg := arg.(type) {
+		case *influxql.VarRef:
+			cur, err = createVarRefCursor(t, arg)
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			cur, err = createWildcardCursor(t, arg)
+		default:
 			// TODO(jsternberg): This should be validated and figured out somewhere else.
 			return nil, fmt.Errorf("first argument to %q must be a variable", gr.call.Name)
 		}
-		cur, err := createVarRefCursor(t, ref)
 		if err != nil {
 			return nil, err
 		}
@@ -137,7 +155,9 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 		cond influxql.Expr
 	)
 	valuer := influxql.NowValuer{Now: t.config.Now}
-	if t.stmt.Condition != nil {
+	if _, ok := t.stmt.Sources[0].(*influxql.SubQuery); ok {
+		// The condition has already been evaluated by the subquery cursor.
+	} else if t.stmt.Condition != nil {
 		var err error
 		if cond, _, err = influxql.ConditionExpr(t.stmt.Condition, &valuer); err != nil {
 			return nil, err
@@ -268,6 +288,13 @@ func (gr *groupInfo) createCursor(t *transpilerState) (cursor, error) {
 				},
 				cursor: cur,
 			}
+
+			// Fill the windows that did not have any values.
+			if c, err := gr.fill(t, cur); err != nil {
+				return nil, err
+			} else {
+				cur = c
+			}
 		}
 	} else {
 		// If we do not have a function, but we have a field option,
@@ -295,6 +322,14 @@ func (gr *groupInfo) group(t *transpilerState, in cursor) (cursor, error) {
 		&ast.StringLiteral{Value: "_measurement"},
 		&ast.StringLiteral{Value: "_start"},
 	}
+	if gr.call != nil {
+		// A wildcard reads every matching field into the same cursor so the function
+		// must be evaluated for each field separately.
+		switch gr.call.Args[0].(type) {
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			tags = append(tags, &ast.StringLiteral{Value: "_field"})
+		}
+	}
 	if len(t.stmt.Dimensions) > 0 {
 		// Maintain a set of the dimensions we have encountered.
 		// This is so we don't duplicate groupings, but we still maintain the
@@ -432,6 +467,17 @@ func (gr *groupInfo) group(t *transpilerState, in cursor) (cursor, error) {
 				},
 			})
 		}
+		if t.stmt.Fill != influxql.NoFill {
+			// Create the empty windows so the function returns a value that can be filled.
+			args = append(args, &ast.Property{
+				Key: &ast.Identifier{
+					Name: "createEmpty",
+				},
+				Value: &ast.BooleanLiteral{
+					Value: true,
+				},
+			})
+		}
 		in = &pipeCursor{
 			expr: &ast.PipeExpression{
 				Argument: in.Expr(),
@@ -452,6 +498,131 @@ func (gr *groupInfo) group(t *transpilerState, in cursor) (cursor, error) {
 	return in, nil
 }
 
+// fill replaces the missing values of the function with the fill option of the statement.
+func (gr *groupInfo) fill(t *transpilerState, in cursor) (cursor, error) {
+	var args []*ast.Property
+	switch t.stmt.Fill {
+	case influxql.NullFill, influxql.NoFill:
+		// The empty windows already have null values or were never created.
+		return in, nil
+	case influxql.NumberFill:
+		// The count function always returns an integer. All of the other functions
+		// are assumed to return a float.
+		var value ast.Expression
+		switch v := t.stmt.FillValue.(type) {
+		case int64:
+			if gr.call.Name == "count" {
+				value = &ast.IntegerLiteral{Value: v}
+			} else {
+				value = &ast.FloatLiteral{Value: float64(v)}
+			}
+		case float64:
+			if gr.call.Name == "count" {
+				value = &ast.IntegerLiteral{Value: int64(v)}
+			} else {
+				value = &ast.FloatLiteral{Value: v}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported fill value: %v", t.stmt.FillValue)
+		}
+
+		// The count of an empty window is zero instead of null so
+		// the zero counts are replaced instead.
+		if gr.call.Name == "count" {
+			return gr.fillCount(in, value)
+		}
+		args = append(args, &ast.Property{
+			Key:   &ast.Identifier{Name: "value"},
+			Value: value,
+		})
+	case influxql.PreviousFill:
+		args = append(args, &ast.Property{
+			Key:   &ast.Identifier{Name: "usePrevious"},
+			Value: &ast.BooleanLiteral{Value: true},
+		})
+	case influxql.LinearFill:
+		// Flux has no function that interpolates between the windows.
+		return nil, errors.New("fill(linear) is not supported, use fill(previous) or fill(<number>) instead")
+	default:
+		return nil, fmt.Errorf("unsupported fill option: %d", t.stmt.Fill)
+	}
+
+	if value, ok := in.Value(gr.call); ok && value != execute.DefaultValueColLabel {
+		args = append([]*ast.Property{{
+			Key:   &ast.Identifier{Name: "column"},
+			Value: &ast.StringLiteral{Value: value},
+		}}, args...)
+	}
+	return &pipeCursor{
+		expr: &ast.PipeExpression{
+			Argument: in.Expr(),
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "fill",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: args,
+					},
+				},
+			},
+		},
+		cursor: in,
+	}, nil
+}
+
+// fillCount replaces the counts of the empty windows with the value.
+func (gr *groupInfo) fillCount(in cursor, value ast.Expression) (cursor, error) {
+	name, ok := in.Value(gr.call)
+	if !ok {
+		return nil, fmt.Errorf("undefined variable: %s", gr.call)
+	}
+	return &pipeCursor{
+		expr: &ast.PipeExpression{
+			Argument: in.Expr(),
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "map",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key: &ast.Identifier{Name: "fn"},
+								Value: &ast.FunctionExpression{
+									Params: []*ast.Property{{
+										Key: &ast.Identifier{Name: "r"},
+									}},
+									Body: &ast.ObjectExpression{
+										With: &ast.Identifier{Name: "r"},
+										Properties: []*ast.Property{{
+											Key: &ast.Identifier{Name: name},
+											Value: &ast.ConditionalExpression{
+												Test: &ast.BinaryExpression{
+													Operator: ast.EqualOperator,
+													Left:     column(name),
+													Right:    &ast.IntegerLiteral{Value: 0},
+												},
+												Consequent: value,
+												Alternate:  column(name),
+											},
+										}},
+									},
+								},
+							},
+							{
+								Key:   &ast.Identifier{Name: "mergeKey"},
+								Value: &ast.BooleanLiteral{Value: true},
+							},
+						},
+					},
+				},
+			},
+		},
+		cursor: in,
+	}, nil
+}
+
 // tagsCursor is a pseudo-cursor that can be used to access tags within the cursor.
 type tagsCursor struct {
 	cursor
@@ -108,16 +108,7 @@ func (t *transpilerState) mapFields(in cursor) (cursor, error) {
 
 func (t *transpilerState) mapField(expr influxql.Expr, in cursor) (ast.Expression, error) {
 	if sym, ok := in.Value(expr); ok {
-		var property ast.PropertyKey
-		if strings.HasPrefix(sym, "_") {
-			property = &ast.Identifier{Name: sym}
-		} else {
-			property = &ast.StringLiteral{Value: sym}
-		}
-		return &ast.MemberExpression{
-			Object:   &ast.Identifier{Name: "r"},
-			Property: property,
-		}, nil
+		return column(sym), nil
 	}
 
 	switch expr := expr.(type) {
@@ -227,3 +218,17 @@ func (evalBuilder) eval(op ast.OperatorKind) func(left, right ast.Expression) as
 		}
 	}
 }
+
+// column returns the expression that accesses the named column of the record r.
+func column(name string) *ast.MemberExpression {
+	var property ast.PropertyKey
+	if strings.HasPrefix(name, "_") {
+		property = &ast.Identifier{Name: name}
+	} else {
+		property = &ast.StringLiteral{Value: name}
+	}
+	return &ast.MemberExpression{
+		Object:   &ast.Identifier{Name: "r"},
+		Property: property,
+	}
+}
@@ -13,7 +13,16 @@ import (
 )
 
 // MultiResultEncoder encodes results as InfluxQL JSON format.
-type MultiResultEncoder struct{}
+type MultiResultEncoder struct {
+	// TimeFormat is the format of the time values; defaults to RFC3339Nano.
+	TimeFormat TimeFormat
+	// ChunkSize is the maximum number of values of a series in each response.
+	// When it is greater than zero, every statement is written as one or more
+	// separate responses instead of a single response for all of them.
+	ChunkSize int
+	// Pretty indents the encoded JSON.
+	Pretty bool
+}
 
 // Encode writes a collection of results to the influxdb 1.X http response format.
 // Expectations/Assumptions:
@@ -28,163 +37,256 @@ type MultiResultEncoder struct{}
 //      TODO(jsternberg): This function currently requires the first column to be a time field, but this isn't
 //      a strict requirement and will be lifted when we begin to work on transpiling meta queries.
 func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
-	resp := Response{}
 	wc := &iocounter.Writer{Writer: w}
+	enc := json.NewEncoder(wc)
+	if e.Pretty {
+		enc.SetIndent("", "    ")
+	}
+
+	if e.ChunkSize > 0 {
+		err := e.encodeChunked(enc, results)
+		return wc.Count(), err
+	}
 
+	resp := Response{}
 	for results.More() {
-		res := results.Next()
-		name := res.Name()
-		id, err := strconv.Atoi(name)
+		result, err := encodeResult(results.Next(), e.TimeFormat)
 		if err != nil {
-			resp.error(fmt.Errorf("unable to parse statement id from result name: %s", err))
+			resp.error(err)
 			results.Release()
 			break
 		}
+		resp.Results = append(resp.Results, result)
+	}
 
-		tables := res.Tables()
+	if err := results.Err(); err != nil && resp.Err == "" {
+		resp.error(err)
+	}
 
-		result := Result{StatementID: id}
-		if err := tables.Do(func(tbl flux.Table) error {
-			var row Row
+	err := enc.Encode(resp)
+	return wc.Count(), err
+}
 
-			for j, c := range tbl.Key().Cols() {
-				if c.Type != flux.TString {
-					// Skip any columns that aren't strings. They are extra ones that
-					// flux includes by default like the start and end times that we do not
-					// care about.
-					continue
+// encodeChunked writes each statement as a series of responses holding at
+// most ChunkSize values of a single series. Every response but the last one
+// of a statement is marked as partial, as is every row but the last one of
+// a series.
+func (e *MultiResultEncoder) encodeChunked(enc *json.Encoder, results flux.ResultIterator) error {
+	for results.More() {
+		result, err := encodeResult(results.Next(), e.TimeFormat)
+		if err != nil {
+			results.Release()
+			resp := Response{}
+			resp.error(err)
+			return enc.Encode(resp)
+		}
+
+		if len(result.Series) == 0 {
+			if err := enc.Encode(Response{Results: []Result{result}}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for i, row := range result.Series {
+			values := row.Values
+			for {
+				n := len(values)
+				if n > e.ChunkSize {
+					n = e.ChunkSize
 				}
-				v := tbl.Key().Value(j).Str()
-				if c.Label == "_measurement" {
-					row.Name = v
-				} else if c.Label == "_field" {
-					// If the field key was not removed by a previous operation, we explicitly
-					// ignore it here when encoding the result back.
-				} else {
-					if row.Tags == nil {
-						row.Tags = make(map[string]string)
-					}
-					row.Tags[c.Label] = v
+				chunk := *row
+				chunk.Values, values = values[:n], values[n:]
+				chunk.Partial = len(values) > 0
+
+				resp := Response{Results: []Result{{
+					StatementID: result.StatementID,
+					Series:      []*Row{&chunk},
+					Partial:     chunk.Partial || i < len(result.Series)-1,
+				}}}
+				if err := enc.Encode(resp); err != nil {
+					return err
+				}
+				if len(values) == 0 {
+					break
 				}
 			}
+		}
+	}
+
+	if err := results.Err(); err != nil {
+		resp := Response{}
+		resp.error(err)
+		return enc.Encode(resp)
+	}
+	return nil
+}
+
+// encodeResult converts a flux result into the result of a statement,
+// formatting the time values with tf.
+func encodeResult(res flux.Result, tf TimeFormat) (Result, error) {
+	name := res.Name()
+	id, err := strconv.Atoi(name)
+	if err != nil {
+		return Result{}, fmt.Errorf("unable to parse statement id from result name: %s", err)
+	}
 
-			// TODO: resultColMap should be constructed from query metadata once it is provided.
-			// for now we know that an influxql query ALWAYS has time first, so we put this placeholder
-			// here to catch this most obvious requirement.  Column orderings should be explicitly determined
-			// from the ordering given in the original flux.
-			resultColMap := map[string]int{}
-			j := 1
-			for _, c := range tbl.Cols() {
-				if c.Label == execute.DefaultTimeColLabel {
-					resultColMap[c.Label] = 0
-				} else if !tbl.Key().HasCol(c.Label) {
-					resultColMap[c.Label] = j
-					j++
+	tables := res.Tables()
+
+	result := Result{StatementID: id}
+	if err := tables.Do(func(tbl flux.Table) error {
+		var row Row
+
+		for j, c := range tbl.Key().Cols() {
+			if c.Type != flux.TString {
+				// Skip any columns that aren't strings. They are extra ones that
+				// flux includes by default like the start and end times that we do not
+				// care about.
+				continue
+			}
+			v := tbl.Key().Value(j).Str()
+			if c.Label == "_measurement" {
+				row.Name = v
+			} else if c.Label == "_field" {
+				// If the field key was not removed by a previous operation, we explicitly
+				// ignore it here when encoding the result back.
+			} else {
+				if row.Tags == nil {
+					row.Tags = make(map[string]string)
 				}
+				row.Tags[c.Label] = v
 			}
+		}
 
-			if _, ok := resultColMap[execute.DefaultTimeColLabel]; !ok {
-				for k, v := range resultColMap {
-					resultColMap[k] = v - 1
-				}
+		// TODO: resultColMap should be constructed from query metadata once it is provided.
+		// for now we know that an influxql query ALWAYS has time first, so we put this placeholder
+		// here to catch this most obvious requirement.  Column orderings should be explicitly determined
+		// from the ordering given in the original flux.
+		resultColMap := map[string]int{}
+		j := 1
+		for _, c := range tbl.Cols() {
+			if c.Label == execute.DefaultTimeColLabel {
+				resultColMap[c.Label] = 0
+			} else if !tbl.Key().HasCol(c.Label) {
+				resultColMap[c.Label] = j
+				j++
 			}
+		}
 
-			row.Columns = make([]string, len(resultColMap))
+		if _, ok := resultColMap[execute.DefaultTimeColLabel]; !ok {
 			for k, v := range resultColMap {
-				if k == execute.DefaultTimeColLabel {
-					k = "time"
-				}
-				row.Columns[v] = k
+				resultColMap[k] = v - 1
 			}
+		}
 
-			if err := tbl.Do(func(cr flux.ColReader) error {
-				// Preallocate the number of rows for the response to make this section
-				// of code easier to read. Find a time column which should exist
-				// in the output.
-				values := make([][]interface{}, cr.Len())
-				for j := range values {
-					values[j] = make([]interface{}, len(row.Columns))
-				}
+		row.Columns = make([]string, len(resultColMap))
+		for k, v := range resultColMap {
+			if k == execute.DefaultTimeColLabel {
+				k = "time"
+			}
+			row.Columns[v] = k
+		}
 
-				j := 0
-				for idx, c := range tbl.Cols() {
-					if cr.Key().HasCol(c.Label) {
-						continue
-					}
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			// Preallocate the number of rows for the response to make this section
+			// of code easier to read. Find a time column which should exist
+			// in the output.
+			values := make([][]interface{}, cr.Len())
+			for j := range values {
+				values[j] = make([]interface{}, len(row.Columns))
+			}
 
-					j = resultColMap[c.Label]
-					// Fill in the values for each column.
-					switch c.Type {
-					case flux.TFloat:
-						vs := cr.Floats(idx)
-						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
-								values[i][j] = vs.Value(i)
-							}
+			j := 0
+			for idx, c := range tbl.Cols() {
+				if cr.Key().HasCol(c.Label) {
+					continue
+				}
+
+				j = resultColMap[c.Label]
+				// Fill in the values for each column.
+				switch c.Type {
+				case flux.TFloat:
+					vs := cr.Floats(idx)
+					for i := 0; i < vs.Len(); i++ {
+						if vs.IsValid(i) {
+							values[i][j] = vs.Value(i)
 						}
-					case flux.TInt:
-						vs := cr.Ints(idx)
-						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
-								values[i][j] = vs.Value(i)
-							}
+					}
+				case flux.TInt:
+					vs := cr.Ints(idx)
+					for i := 0; i < vs.Len(); i++ {
+						if vs.IsValid(i) {
+							values[i][j] = vs.Value(i)
 						}
-					case flux.TString:
-						vs := cr.Strings(idx)
-						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
-								values[i][j] = vs.ValueString(i)
-							}
+					}
+				case flux.TString:
+					vs := cr.Strings(idx)
+					for i := 0; i < vs.Len(); i++ {
+						if vs.IsValid(i) {
+							values[i][j] = vs.ValueString(i)
 						}
-					case flux.TUInt:
-						vs := cr.UInts(idx)
-						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
-								values[i][j] = vs.Value(i)
-							}
+					}
+				case flux.TUInt:
+					vs := cr.UInts(idx)
+					for i := 0; i < vs.Len(); i++ {
+						if vs.IsValid(i) {
+							values[i][j] = vs.Value(i)
 						}
-					case flux.TBool:
-						vs := cr.Bools(idx)
-						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
-								values[i][j] = vs.Value(i)
-							}
+					}
+				case flux.TBool:
+					vs := cr.Bools(idx)
+					for i := 0; i < vs.Len(); i++ {
+						if vs.IsValid(i) {
+							values[i][j] = vs.Value(i)
 						}
-					case flux.TTime:
-						vs := cr.Times(idx)
-						for i := 0; i < vs.Len(); i++ {
-							if vs.IsValid(i) {
-								values[i][j] = execute.Time(vs.Value(i)).Time().Format(time.RFC3339Nano)
-							}
+					}
+				case flux.TTime:
+					vs := cr.Times(idx)
+					for i := 0; i < vs.Len(); i++ {
+						if vs.IsValid(i) {
+							values[i][j] = formatTime(execute.Time(vs.Value(i)), tf)
 						}
-					default:
-						return fmt.Errorf("unsupported column type: %s", c.Type)
 					}
-
+				default:
+					return fmt.Errorf("unsupported column type: %s", c.Type)
 				}
-				row.Values = append(row.Values, values...)
-				return nil
-			}); err != nil {
-				return err
-			}
 
-			result.Series = append(result.Series, &row)
+			}
+			row.Values = append(row.Values, values...)
 			return nil
 		}); err != nil {
-			resp.error(err)
-			results.Release()
-			break
+			return err
 		}
-		resp.Results = append(resp.Results, result)
-	}
 
-	if err := results.Err(); err != nil && resp.Err == "" {
-		resp.error(err)
+		result.Series = append(result.Series, &row)
+		return nil
+	}); err != nil {
+		return Result{}, err
 	}
+	return result, nil
+}
 
-	err := json.NewEncoder(wc).Encode(resp)
-	return wc.Count(), err
+// formatTime returns t in the format tf. Epoch formats are truncated to
+// integers in their unit.
+func formatTime(t execute.Time, tf TimeFormat) interface{} {
+	switch tf {
+	case Hour:
+		return int64(t) / int64(time.Hour)
+	case Minute:
+		return int64(t) / int64(time.Minute)
+	case Second:
+		return int64(t) / int64(time.Second)
+	case Millisecond:
+		return int64(t) / int64(time.Millisecond)
+	case Microsecond:
+		return int64(t) / int64(time.Microsecond)
+	case Nanosecond:
+		return int64(t)
+	default:
+		return t.Time().Format(time.RFC3339Nano)
+	}
 }
+
 func NewMultiResultEncoder() *MultiResultEncoder {
 	return new(MultiResultEncoder)
 }
@@ -0,0 +1,35 @@
+package spectests
+
+func init() {
+	RegisterFixture(
+		NewFixture(
+			`SELECT DISTINCT value FROM db0..cpu`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: 1677-09-21T00:12:43.145224194Z, stop: 2262-04-11T23:47:16.854775806Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r._field == "value")
+	|> group(columns: ["_measurement", "_start"], mode: "by")
+	|> distinct()
+	|> duplicate(column: "_start", as: "_time")
+	|> map(fn: (r) => ({_time: r._time, distinct: r._value}), mergeKey: true)
+	|> yield(name: "0")
+`,
+		),
+		NewFixture(
+			`SELECT count(distinct(value)) FROM db0..cpu`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: 1677-09-21T00:12:43.145224194Z, stop: 2262-04-11T23:47:16.854775806Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r._field == "value")
+	|> group(columns: ["_measurement", "_start"], mode: "by")
+	|> distinct()
+	|> count()
+	|> duplicate(column: "_start", as: "_time")
+	|> map(fn: (r) => ({_time: r._time, count: r._value}), mergeKey: true)
+	|> yield(name: "0")
+`,
+		),
+	)
+}
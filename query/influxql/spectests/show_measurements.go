@@ -0,0 +1,37 @@
+package spectests
+
+func init() {
+	RegisterFixture(
+		NewFixture(
+			`SHOW MEASUREMENTS ON "db0"`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: -1h)
+	|> keep(columns: ["_measurement"])
+	|> group()
+	|> distinct(column: "_measurement")
+	|> rename(columns: {_value: "name"})
+	|> set(key: "_measurement", value: "measurements")
+	|> group(columns: ["_measurement"], mode: "by")
+	|> yield(name: "0")
+`,
+		),
+		NewFixture(
+			`SHOW MEASUREMENTS ON "db0" WITH MEASUREMENT =~ /cp.*/ WHERE host = 'server01'`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement =~ /cp.*/ and r["host"] == "server01")
+	|> keep(columns: ["_measurement"])
+	|> group()
+	|> distinct(column: "_measurement")
+	|> rename(columns: {_value: "name"})
+	|> set(key: "_measurement", value: "measurements")
+	|> group(columns: ["_measurement"], mode: "by")
+	|> yield(name: "0")
+`,
+		),
+	)
+}
@@ -0,0 +1,21 @@
+package spectests
+
+func init() {
+	RegisterFixture(
+		NewFixture(
+			`SHOW SERIES ON "db0" FROM "cpu" WHERE region = 'west'`,
+			`package main
+
+import influxql "internal/influxql"
+
+from(bucketID: "")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "cpu" and r["region"] == "west")
+	|> influxql.seriesKeys()
+	|> distinct()
+	|> rename(columns: {_value: "key"})
+	|> yield(name: "0")
+`,
+		),
+	)
+}
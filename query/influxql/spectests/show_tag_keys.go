@@ -0,0 +1,22 @@
+package spectests
+
+func init() {
+	RegisterFixture(
+		NewFixture(
+			`SHOW TAG KEYS ON "db0" FROM "cpu"`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: -1h)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> keys()
+	|> keep(columns: ["_measurement", "_value"])
+	|> group(columns: ["_measurement"], mode: "by")
+	|> distinct()
+	|> filter(fn: (r) => r._value != "_start" and r._value != "_stop" and r._value != "_measurement" and r._value != "_field")
+	|> rename(columns: {_value: "tagKey"})
+	|> yield(name: "0")
+`,
+		),
+	)
+}
@@ -0,0 +1,26 @@
+package spectests
+
+func init() {
+	RegisterFixture(
+		NewFixture(
+			`SELECT max(mean) FROM (SELECT mean(value) FROM db0..cpu GROUP BY host) WHERE time >= now() - 10m`,
+			`package main
+
+t0 = from(bucketID: "")
+	|> range(start: 2010-09-15T08:50:00Z, stop: 2262-04-11T23:47:16.854775806Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r._field == "value")
+	|> group(columns: ["_measurement", "_start", "host"], mode: "by")
+	|> mean()
+	|> duplicate(column: "_start", as: "_time")
+	|> map(fn: (r) => ({_time: r._time, mean: r._value}), mergeKey: true)
+
+t0
+	|> map(fn: (r) => ({_time: r._time, _value: r["mean"]}), mergeKey: true)
+	|> group(columns: ["_measurement", "_start"], mode: "by")
+	|> max()
+	|> map(fn: (r) => ({_time: r._time, max: r._value}), mergeKey: true)
+	|> yield(name: "0")
+`,
+		),
+	)
+}
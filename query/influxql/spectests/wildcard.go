@@ -0,0 +1,77 @@
+package spectests
+
+func init() {
+	RegisterFixture(
+		NewFixture(
+			`SELECT * FROM db0..cpu`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: 1677-09-21T00:12:43.145224194Z, stop: 2262-04-11T23:47:16.854775806Z)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> group(columns: ["_measurement", "_start"], mode: "by")
+	|> drop(columns: ["_stop"])
+	|> yield(name: "0")
+`,
+		),
+		NewFixture(
+			`SELECT /^us/ FROM db0..cpu WHERE host = 'server01' GROUP BY host`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: 1677-09-21T00:12:43.145224194Z, stop: 2262-04-11T23:47:16.854775806Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r._field =~ /^us/)
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> filter(fn: (r) => r["host"] == "server01")
+	|> group(columns: ["_measurement", "_start", "host"], mode: "by")
+	|> keep(fn: (column) => column == "_time" or column == "_measurement" or column == "_start" or column == "host" or column =~ /^us/)
+	|> yield(name: "0")
+`,
+		),
+		NewFixture(
+			`SELECT max(*) FROM db0..cpu`,
+			`package main
+
+from(bucketID: "")
+	|> range(start: 1677-09-21T00:12:43.145224194Z, stop: 2262-04-11T23:47:16.854775806Z)
+	|> filter(fn: (r) => r._measurement == "cpu")
+	|> group(columns: ["_measurement", "_start", "_field"], mode: "by")
+	|> max()
+	|> drop(columns: ["_time"])
+	|> duplicate(column: "_start", as: "_time")
+	|> map(fn: (r) => ({_time: r._time, _field: "max_" + r._field, _value: r._value}), mergeKey: true)
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> yield(name: "0")
+`,
+		),
+		NewFixture(
+			`SELECT count(/^us/), mean(/^us/) FROM db0..cpu WHERE time >= now() - 10m GROUP BY time(5m)`,
+			`package main
+
+t0 = from(bucketID: "")
+	|> range(start: 2010-09-15T08:50:00Z, stop: 2010-09-15T09:00:00Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r._field =~ /^us/)
+	|> group(columns: ["_measurement", "_start", "_field"], mode: "by")
+	|> window(every: 5m, createEmpty: true)
+	|> count()
+	|> duplicate(column: "_start", as: "_time")
+	|> window(every: inf)
+	|> map(fn: (r) => ({_time: r._time, _field: "count_" + r._field, _value: r._value}), mergeKey: true)
+t1 = from(bucketID: "")
+	|> range(start: 2010-09-15T08:50:00Z, stop: 2010-09-15T09:00:00Z)
+	|> filter(fn: (r) => r._measurement == "cpu" and r._field =~ /^us/)
+	|> group(columns: ["_measurement", "_start", "_field"], mode: "by")
+	|> window(every: 5m, createEmpty: true)
+	|> mean()
+	|> duplicate(column: "_start", as: "_time")
+	|> window(every: inf)
+	|> map(fn: (r) => ({_time: r._time, _field: "mean_" + r._field, _value: r._value}), mergeKey: true)
+
+union(tables: [t0, t1])
+	|> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+	|> yield(name: "0")
+`,
+		),
+	)
+}
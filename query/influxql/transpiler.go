@@ -3,9 +3,11 @@ package influxql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/flux/ast"
@@ -32,7 +34,9 @@ func NewTranspilerWithConfig(dbrpMappingSvc influxdb.DBRPMappingService, cfg Con
 
 func (t *Transpiler) Transpile(ctx context.Context, txt string) (*ast.Package, error) {
 	// Parse the text of the query.
-	q, err := influxql.ParseQuery(txt)
+	p := influxql.NewParser(strings.NewReader(txt))
+	p.SetParams(t.Config.Params)
+	q, err := p.ParseQuery()
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +60,7 @@ type transpilerState struct {
 	config         Config
 	file           *ast.File
 	assignments    map[string]ast.Expression
+	subqueries     map[*influxql.SubQuery]*ast.Identifier
 	dbrpMappingSvc influxdb.DBRPMappingService
 }
 
@@ -69,6 +74,7 @@ func newTranspilerState(dbrpMappingSvc influxdb.DBRPMappingService, config *Conf
 			},
 		},
 		assignments:    make(map[string]ast.Expression),
+		subqueries:     make(map[*influxql.SubQuery]*ast.Identifier),
 		dbrpMappingSvc: dbrpMappingSvc,
 	}
 	if config != nil {
@@ -117,8 +123,16 @@ func (t *transpilerState) transpile(ctx context.Context, s influxql.Statement) (
 			return nil, err
 		}
 		return cur.Expr(), nil
+	case *influxql.ShowMeasurementsStatement:
+		return t.transpileShowMeasurements(ctx, stmt)
+	case *influxql.ShowTagKeysStatement:
+		return t.transpileShowTagKeys(ctx, stmt)
 	case *influxql.ShowTagValuesStatement:
 		return t.transpileShowTagValues(ctx, stmt)
+	case *influxql.ShowFieldKeysStatement:
+		return t.transpileShowFieldKeys(ctx, stmt)
+	case *influxql.ShowSeriesStatement:
+		return t.transpileShowSeries(ctx, stmt)
 	case *influxql.ShowDatabasesStatement:
 		return t.transpileShowDatabases(ctx, stmt)
 	case *influxql.ShowRetentionPoliciesStatement:
@@ -129,113 +143,11 @@ func (t *transpilerState) transpile(ctx context.Context, s influxql.Statement) (
 }
 
 func (t *transpilerState) transpileShowTagValues(ctx context.Context, stmt *influxql.ShowTagValuesStatement) (ast.Expression, error) {
-	// While the ShowTagValuesStatement contains a sources section and those sources are measurements, they do
-	// not actually contain the database and we do not factor in retention policies. So we are always going to use
-	// the default retention policy when evaluating which bucket we are querying and we do not have to consult
-	// the sources in the statement.
-	if stmt.Database == "" {
-		if t.config.DefaultDatabase == "" {
-			return nil, errDatabaseNameRequired
-		}
-		stmt.Database = t.config.DefaultDatabase
-	}
-
-	expr, err := t.from(&influxql.Measurement{Database: stmt.Database})
+	expr, err := t.readMeta(stmt.Database, stmt.Sources, stmt.Condition)
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO(jsternberg): Read the range from the condition expression. 1.x doesn't actually do this so it isn't
-	// urgent to implement this functionality so we can use the default range.
-	expr = &ast.PipeExpression{
-		Argument: expr,
-		Call: &ast.CallExpression{
-			Callee: &ast.Identifier{
-				Name: "range",
-			},
-			Arguments: []ast.Expression{
-				&ast.ObjectExpression{
-					Properties: []*ast.Property{
-						{
-							Key: &ast.Identifier{
-								Name: "start",
-							},
-							Value: &ast.DurationLiteral{
-								Values: []ast.Duration{{
-									Magnitude: -1,
-									Unit:      "h",
-								}},
-							},
-						},
-					},
-				},
-			},
-		},
-	}
-
-	// If we have a list of sources, look through it and add each of the measurement names.
-	measurementNames := make([]string, 0, len(stmt.Sources))
-	for _, source := range stmt.Sources {
-		mm := source.(*influxql.Measurement)
-		measurementNames = append(measurementNames, mm.Name)
-	}
-
-	if len(measurementNames) > 0 {
-		var filterExpr ast.Expression = &ast.BinaryExpression{
-			Operator: ast.EqualOperator,
-			Left: &ast.MemberExpression{
-				Object:   &ast.Identifier{Name: "r"},
-				Property: &ast.Identifier{Name: "_measurement"},
-			},
-			Right: &ast.StringLiteral{
-				Value: measurementNames[len(measurementNames)-1],
-			},
-		}
-		for i := len(measurementNames) - 2; i >= 0; i-- {
-			filterExpr = &ast.LogicalExpression{
-				Operator: ast.OrOperator,
-				Left: &ast.BinaryExpression{
-					Operator: ast.EqualOperator,
-					Left: &ast.MemberExpression{
-						Object:   &ast.Identifier{Name: "r"},
-						Property: &ast.Identifier{Name: "_measurement"},
-					},
-					Right: &ast.StringLiteral{
-						Value: measurementNames[i],
-					},
-				},
-				Right: filterExpr,
-			}
-		}
-		expr = &ast.PipeExpression{
-			Argument: expr,
-			Call: &ast.CallExpression{
-				Callee: &ast.Identifier{
-					Name: "filter",
-				},
-				Arguments: []ast.Expression{
-					&ast.ObjectExpression{
-						Properties: []*ast.Property{
-							{
-								Key: &ast.Identifier{Name: "fn"},
-								Value: &ast.FunctionExpression{
-									Params: []*ast.Property{
-										{
-											Key: &ast.Identifier{Name: "r"},
-										},
-									},
-									Body: filterExpr,
-								},
-							},
-						},
-					},
-				},
-			},
-		}
-	}
-
-	// TODO(jsternberg): Add the condition filter for the where clause.
-
 	// Create the key values op spec from the
 	var keyColumns []ast.Expression
 	switch expr := stmt.TagKeyExpr.(type) {
@@ -390,6 +302,277 @@ func (t *transpilerState) transpileShowTagValues(ctx context.Context, stmt *infl
 	}, nil
 }
 
+// readMeta reads the series of the database that are used to answer a meta query.
+// The series are filtered by the measurements in the sources and the condition.
+func (t *transpilerState) readMeta(database string, sources influxql.Sources, cond influxql.Expr) (ast.Expression, error) {
+	// While the meta queries contain a sources section and those sources are measurements, they do
+	// not actually contain the database and we do not factor in retention policies. So we are always going to use
+	// the default retention policy when evaluating which bucket we are querying and we do not have to consult
+	// the sources in the statement.
+	if database == "" {
+		if t.config.DefaultDatabase == "" {
+			return nil, errDatabaseNameRequired
+		}
+		database = t.config.DefaultDatabase
+	}
+
+	expr, err := t.from(&influxql.Measurement{Database: database})
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO(jsternberg): Read the range from the condition expression. 1.x doesn't actually do this so it isn't
+	// urgent to implement this functionality so we can use the default range.
+	expr = &ast.PipeExpression{
+		Argument: expr,
+		Call: &ast.CallExpression{
+			Callee: &ast.Identifier{
+				Name: "range",
+			},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{
+						{
+							Key: &ast.Identifier{
+								Name: "start",
+							},
+							Value: &ast.DurationLiteral{
+								Values: []ast.Duration{{
+									Magnitude: -1,
+									Unit:      "h",
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// If we have a list of sources, look through it and match each of the measurements
+	// by their name or regex.
+	var filterExpr ast.Expression
+	for i := len(sources) - 1; i >= 0; i-- {
+		mm := sources[i].(*influxql.Measurement)
+		var match ast.Expression
+		if mm.Regex != nil {
+			match = &ast.BinaryExpression{
+				Operator: ast.RegexpMatchOperator,
+				Left:     column("_measurement"),
+				Right:    &ast.RegexpLiteral{Value: mm.Regex.Val},
+			}
+		} else {
+			match = &ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     column("_measurement"),
+				Right:    &ast.StringLiteral{Value: mm.Name},
+			}
+		}
+		if filterExpr != nil {
+			match = &ast.LogicalExpression{
+				Operator: ast.OrOperator,
+				Left:     match,
+				Right:    filterExpr,
+			}
+		}
+		filterExpr = match
+	}
+
+	// The condition of a meta query can only reference tags.
+	valuer := influxql.NowValuer{Now: t.config.Now}
+	if cond, _, err := influxql.ConditionExpr(cond, &valuer); err != nil {
+		return nil, err
+	} else if cond != nil {
+		fn, err := t.mapField(cond, &columnsCursor{})
+		if err != nil {
+			return nil, err
+		}
+		if filterExpr != nil {
+			fn = &ast.LogicalExpression{
+				Operator: ast.AndOperator,
+				Left:     filterExpr,
+				Right:    fn,
+			}
+		}
+		filterExpr = fn
+	}
+
+	if filterExpr != nil {
+		expr = &ast.PipeExpression{
+			Argument: expr,
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "filter",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key: &ast.Identifier{Name: "fn"},
+								Value: &ast.FunctionExpression{
+									Params: []*ast.Property{
+										{
+											Key: &ast.Identifier{Name: "r"},
+										},
+									},
+									Body: filterExpr,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	return expr, nil
+}
+
+func (t *transpilerState) transpileShowMeasurements(ctx context.Context, stmt *influxql.ShowMeasurementsStatement) (ast.Expression, error) {
+	var sources influxql.Sources
+	if stmt.Source != nil {
+		sources = influxql.Sources{stmt.Source}
+	}
+	expr, err := t.readMeta(stmt.Database, sources, stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the distinct measurement names and return them within a series named measurements.
+	expr = pipe(expr, "keep", property("columns", stringArray("_measurement")))
+	expr = pipe(expr, "group")
+	expr = pipe(expr, "distinct", property("column", &ast.StringLiteral{Value: "_measurement"}))
+	expr = pipe(expr, "rename", property("columns", renames("_value", "name")))
+	expr = pipe(expr, "set",
+		property("key", &ast.StringLiteral{Value: "_measurement"}),
+		property("value", &ast.StringLiteral{Value: "measurements"}),
+	)
+	return pipe(expr, "group",
+		property("columns", stringArray("_measurement")),
+		property("mode", &ast.StringLiteral{Value: "by"}),
+	), nil
+}
+
+func (t *transpilerState) transpileShowTagKeys(ctx context.Context, stmt *influxql.ShowTagKeysStatement) (ast.Expression, error) {
+	expr, err := t.readMeta(stmt.Database, stmt.Sources, stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	// List the columns of the group key of each series and find the distinct
+	// ones for each measurement. The columns that are not tags are removed.
+	expr = pipe(expr, "keys")
+	expr = pipe(expr, "keep", property("columns", stringArray("_measurement", "_value")))
+	expr = pipe(expr, "group",
+		property("columns", stringArray("_measurement")),
+		property("mode", &ast.StringLiteral{Value: "by"}),
+	)
+	expr = pipe(expr, "distinct")
+
+	var body ast.Expression
+	for _, label := range []string{"_start", "_stop", "_measurement", "_field"} {
+		var ne ast.Expression = &ast.BinaryExpression{
+			Operator: ast.NotEqualOperator,
+			Left:     column("_value"),
+			Right:    &ast.StringLiteral{Value: label},
+		}
+		if body != nil {
+			ne = &ast.LogicalExpression{
+				Operator: ast.AndOperator,
+				Left:     body,
+				Right:    ne,
+			}
+		}
+		body = ne
+	}
+	expr = pipe(expr, "filter", property("fn", &ast.FunctionExpression{
+		Params: []*ast.Property{{
+			Key: &ast.Identifier{Name: "r"},
+		}},
+		Body: body,
+	}))
+	return pipe(expr, "rename", property("columns", renames("_value", "tagKey"))), nil
+}
+
+func (t *transpilerState) transpileShowFieldKeys(ctx context.Context, stmt *influxql.ShowFieldKeysStatement) (ast.Expression, error) {
+	expr, err := t.readMeta(stmt.Database, stmt.Sources, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the distinct fields of each measurement.
+	// TODO(jsternberg): The field type cannot be determined from the group key so it is not included.
+	expr = pipe(expr, "keep", property("columns", stringArray("_measurement", "_field")))
+	expr = pipe(expr, "group",
+		property("columns", stringArray("_measurement")),
+		property("mode", &ast.StringLiteral{Value: "by"}),
+	)
+	expr = pipe(expr, "distinct", property("column", &ast.StringLiteral{Value: "_field"}))
+	return pipe(expr, "rename", property("columns", renames("_value", "fieldKey"))), nil
+}
+
+func (t *transpilerState) transpileShowSeries(ctx context.Context, stmt *influxql.ShowSeriesStatement) (ast.Expression, error) {
+	expr, err := t.readMeta(stmt.Database, stmt.Sources, stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	// Format the series key of each table and find the distinct series keys.
+	internal := t.requireImport("internal/influxql")
+	expr = &ast.PipeExpression{
+		Argument: expr,
+		Call: &ast.CallExpression{
+			Callee: &ast.MemberExpression{
+				Object:   internal,
+				Property: &ast.Identifier{Name: "seriesKeys"},
+			},
+		},
+	}
+	expr = pipe(expr, "distinct")
+	return pipe(expr, "rename", property("columns", renames("_value", "key"))), nil
+}
+
+// pipe passes the expression to the named function with the properties as its arguments.
+func pipe(expr ast.Expression, name string, properties ...*ast.Property) ast.Expression {
+	call := &ast.CallExpression{
+		Callee: &ast.Identifier{Name: name},
+	}
+	if len(properties) > 0 {
+		call.Arguments = []ast.Expression{
+			&ast.ObjectExpression{Properties: properties},
+		}
+	}
+	return &ast.PipeExpression{
+		Argument: expr,
+		Call:     call,
+	}
+}
+
+// property creates a property with the name and value.
+func property(name string, value ast.Expression) *ast.Property {
+	return &ast.Property{
+		Key:   &ast.Identifier{Name: name},
+		Value: value,
+	}
+}
+
+// stringArray creates an array of string literals.
+func stringArray(values ...string) *ast.ArrayExpression {
+	elements := make([]ast.Expression, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, &ast.StringLiteral{Value: v})
+	}
+	return &ast.ArrayExpression{Elements: elements}
+}
+
+// renames creates the object for renaming the column from to the column to.
+func renames(from, to string) *ast.ObjectExpression {
+	return &ast.ObjectExpression{
+		Properties: []*ast.Property{
+			property(from, &ast.StringLiteral{Value: to}),
+		},
+	}
+}
+
 func (t *transpilerState) transpileShowDatabases(ctx context.Context, stmt *influxql.ShowDatabasesStatement) (ast.Expression, error) {
 	v1 := t.requireImport("influxdata/influxdb/v1")
 	return &ast.PipeExpression{
@@ -618,6 +801,25 @@ func (t *transpilerState) transpileSelect(ctx context.Context, stmt *influxql.Se
 	t.stmt = stmt.Clone()
 	t.stmt.OmitTime = true
 
+	// Rewrite the distinct expressions as calls so they are treated as any other function.
+	for _, f := range t.stmt.Fields {
+		f.Expr = influxql.RewriteExpr(f.Expr, func(expr influxql.Expr) influxql.Expr {
+			if d, ok := expr.(*influxql.Distinct); ok {
+				return d.NewCall()
+			}
+			return expr
+		})
+	}
+
+	if hasWildcard(t.stmt.Fields) {
+		if t.stmt.Target != nil {
+			// The fieldFn of to() names every field that is written, but the
+			// fields matching a wildcard are only known when the query runs.
+			return nil, errors.New("wildcards cannot be used with an INTO clause, select the fields by name instead")
+		}
+		return t.transpileWildcard()
+	}
+
 	groups, err := identifyGroups(t.stmt)
 	if err != nil {
 		return nil, err
@@ -646,109 +848,183 @@ func (t *transpilerState) transpileSelect(ctx context.Context, stmt *influxql.Se
 	if err != nil {
 		return nil, err
 	}
+
+	// Write the results to the target if there is one.
+	if t.stmt.Target != nil {
+		return t.into(cur)
+	}
 	return cur, nil
 }
 
+// into writes the fields of the results to the target measurement.
+func (t *transpilerState) into(in cursor) (cursor, error) {
+	bucket, err := t.bucket(t.stmt.Target.Measurement)
+	if err != nil {
+		return nil, err
+	}
+
+	expr := in.Expr()
+	if name := t.stmt.Target.Measurement.Name; name != "" {
+		expr = &ast.PipeExpression{
+			Argument: expr,
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "set",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key:   &ast.Identifier{Name: "key"},
+								Value: &ast.StringLiteral{Value: "_measurement"},
+							},
+							{
+								Key:   &ast.Identifier{Name: "value"},
+								Value: &ast.StringLiteral{Value: name},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// Every column that was selected is written as a field. The string columns
+	// of the group key are written as the tags.
+	columns := t.stmt.ColumnNames()
+	fields := make([]*ast.Property, 0, len(t.stmt.Fields))
+	for i, f := range t.stmt.Fields {
+		if ref, ok := f.Expr.(*influxql.VarRef); ok && ref.Val == "time" {
+			continue
+		}
+		fields = append(fields, &ast.Property{
+			Key:   &ast.Identifier{Name: columns[i]},
+			Value: column(columns[i]),
+		})
+	}
+	return &mapCursor{
+		expr: &ast.PipeExpression{
+			Argument: expr,
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "to",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{
+							bucket,
+							{
+								Key: &ast.Identifier{Name: "fieldFn"},
+								Value: &ast.FunctionExpression{
+									Params: []*ast.Property{{
+										Key: &ast.Identifier{Name: "r"},
+									}},
+									Body: &ast.ObjectExpression{
+										Properties: fields,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
 func (t *transpilerState) mapType(ref *influxql.VarRef) influxql.DataType {
 	// TODO(jsternberg): Actually evaluate the type against the schema.
 	return influxql.Tag
 }
 
 func (t *transpilerState) from(m *influxql.Measurement) (ast.Expression, error) {
-	var args []ast.Expression
+	bucket, err := t.bucket(m)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.CallExpression{
+		Callee: &ast.Identifier{
+			Name: "from",
+		},
+		Arguments: []ast.Expression{
+			&ast.ObjectExpression{
+				Properties: []*ast.Property{bucket},
+			},
+		},
+	}, nil
+}
+
+// bucket returns the property that identifies the bucket of the measurement
+// for the from and to functions.
+func (t *transpilerState) bucket(m *influxql.Measurement) (*ast.Property, error) {
 	// Use the bucket inteasd of dbrp mapping if it exists.
 	if t.config.Bucket != "" {
-		args = []ast.Expression{
-			&ast.ObjectExpression{
-				Properties: []*ast.Property{
-					{
-						Key: &ast.Identifier{
-							Name: "bucket",
-						},
-						Value: &ast.StringLiteral{
-							Value: t.config.Bucket,
-						},
-					},
-				},
+		return &ast.Property{
+			Key: &ast.Identifier{
+				Name: "bucket",
 			},
+			Value: &ast.StringLiteral{
+				Value: t.config.Bucket,
+			},
+		}, nil
+	}
+
+	if t.dbrpMappingSvc == nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "unable to transpile: db and rp mappings need to be created by some way",
 		}
-	} else {
-		if t.dbrpMappingSvc == nil {
+	}
+	db, rp := m.Database, m.RetentionPolicy
+	if db == "" {
+		if t.config.DefaultDatabase == "" {
 			return nil, &influxdb.Error{
-				Code: influxdb.EInternal,
-				Msg:  "unable to transpile: db and rp mappings need to be created by some way",
-			}
-		}
-		db, rp := m.Database, m.RetentionPolicy
-		if db == "" {
-			if t.config.DefaultDatabase == "" {
-				return nil, &influxdb.Error{
-					Code: influxdb.EInvalid,
-					Msg:  "unable to transpile: database is required",
-				}
+				Code: influxdb.EInvalid,
+				Msg:  "unable to transpile: database is required",
 			}
-			db = t.config.DefaultDatabase
 		}
-		if rp == "" {
-			if t.config.DefaultRetentionPolicy != "" {
-				rp = t.config.DefaultRetentionPolicy
-			}
+		db = t.config.DefaultDatabase
+	}
+	if rp == "" {
+		if t.config.DefaultRetentionPolicy != "" {
+			rp = t.config.DefaultRetentionPolicy
 		}
+	}
 
-		var filter influxdb.DBRPMappingFilter
-		filter.Cluster = &t.config.Cluster
-		if db != "" {
-			filter.Database = &db
-		}
-		if rp != "" {
-			filter.RetentionPolicy = &rp
-		}
-		defaultRP := rp == ""
-		filter.Default = &defaultRP
-		mapping, err := t.dbrpMappingSvc.Find(context.TODO(), filter)
-		if err != nil {
-			if !t.config.FallbackToDBRP {
-				return nil, err
-			}
-			// use `db/rp` naming convention
-			args = []ast.Expression{
-				&ast.ObjectExpression{
-					Properties: []*ast.Property{
-						{
-							Key: &ast.Identifier{
-								Name: "bucket",
-							},
-							Value: &ast.StringLiteral{
-								Value: fmt.Sprintf("%s/%s", db, rp),
-							},
-						},
-					},
-				},
-			}
-		} else {
-			// use mapping bucket id
-			args = []ast.Expression{
-				&ast.ObjectExpression{
-					Properties: []*ast.Property{
-						{
-							Key: &ast.Identifier{
-								Name: "bucketID",
-							},
-							Value: &ast.StringLiteral{
-								Value: mapping.BucketID.String(),
-							},
-						},
-					},
-				},
-			}
+	var filter influxdb.DBRPMappingFilter
+	filter.Cluster = &t.config.Cluster
+	if db != "" {
+		filter.Database = &db
+	}
+	if rp != "" {
+		filter.RetentionPolicy = &rp
+	}
+	defaultRP := rp == ""
+	filter.Default = &defaultRP
+	mapping, err := t.dbrpMappingSvc.Find(context.TODO(), filter)
+	if err != nil {
+		if !t.config.FallbackToDBRP {
+			return nil, err
 		}
+		// use `db/rp` naming convention
+		return &ast.Property{
+			Key: &ast.Identifier{
+				Name: "bucket",
+			},
+			Value: &ast.StringLiteral{
+				Value: fmt.Sprintf("%s/%s", db, rp),
+			},
+		}, nil
 	}
 
-	return &ast.CallExpression{
-		Callee: &ast.Identifier{
-			Name: "from",
+	// use mapping bucket id
+	return &ast.Property{
+		Key: &ast.Identifier{
+			Name: "bucketID",
+		},
+		Value: &ast.StringLiteral{
+			Value: mapping.BucketID.String(),
 		},
-		Arguments: args,
 	}, nil
 }
 
@@ -5,8 +5,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/influxdata/flux/ast"
 	platform "github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/query/influxql"
 	"github.com/influxdata/influxdb/query/influxql/spectests"
 	platformtesting "github.com/influxdata/influxdb/testing"
@@ -244,6 +246,10 @@ func TestTranspiler_Compile(t *testing.T) {
 		{s: `SELECT field1 FROM foo group by time(1s)`, err: `using GROUP BY requires at least one aggregate function`},
 		{s: `SELECT field1 FROM foo fill(none)`, err: `fill(none) must be used with a function`},
 		{s: `SELECT field1 FROM foo fill(linear)`, err: `fill(linear) must be used with a function`},
+		{s: `SELECT mean(value) FROM cpu WHERE time >= now() - 10m GROUP BY time(5m) fill(linear)`, err: `fill(linear) is not supported, use fill(previous) or fill(<number>) instead`},
+		{s: `SELECT mean(*) FROM cpu WHERE time >= now() - 10m GROUP BY time(5m) fill(linear)`, err: `fill(linear) is not supported, use fill(previous) or fill(<number>) instead`},
+		{s: `SELECT * INTO cpu_copy FROM cpu`, err: `wildcards cannot be used with an INTO clause, select the fields by name instead`},
+		{s: `SELECT mean(/usage/) INTO cpu_5m FROM cpu WHERE time >= now() - 10m GROUP BY time(5m)`, err: `wildcards cannot be used with an INTO clause, select the fields by name instead`},
 		{s: `SELECT count(value), value FROM foo`, err: `mixing aggregate and non-aggregate queries is not supported`},
 		{s: `SELECT count(value) FROM foo group by time`, err: `time() is a function and expects at least one argument`},
 		{s: `SELECT count(value) FROM foo group by 'time'`, err: `only time and tag dimensions allowed`},
@@ -406,3 +412,35 @@ func TestTranspiler_Compile(t *testing.T) {
 		})
 	}
 }
+
+func TestTranspiler_Params(t *testing.T) {
+	transpile := func(t *testing.T, s string, params query.Params) string {
+		t.Helper()
+		transpiler := influxql.NewTranspilerWithConfig(
+			dbrpMappingSvc,
+			influxql.Config{
+				DefaultDatabase: "db0",
+				Params:          params,
+			},
+		)
+		pkg, err := transpiler.Transpile(context.Background(), s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ast.Format(pkg)
+	}
+
+	got := transpile(t, `SELECT mean(value) FROM cpu WHERE host = $host AND value > $min`, query.Params{
+		"host": `server01' OR host = 'server02`,
+		"min":  int64(10),
+	})
+	want := transpile(t, `SELECT mean(value) FROM cpu WHERE host = 'server01\' OR host = \'server02' AND value > 10`, nil)
+	if got != want {
+		t.Errorf("unexpected transpiled query:\n%s\nwant:\n%s", got, want)
+	}
+
+	transpiler := influxql.NewTranspilerWithConfig(dbrpMappingSvc, influxql.Config{DefaultDatabase: "db0"})
+	if _, err := transpiler.Transpile(context.Background(), `SELECT value FROM cpu WHERE host = $host`); err == nil || !strings.Contains(err.Error(), "missing parameter: host") {
+		t.Errorf("expected a missing parameter error, got %v", err)
+	}
+}
@@ -0,0 +1,473 @@
+package influxql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/influxql"
+	pkgerrors "github.com/pkg/errors"
+)
+
+// wildcardCursor holds every field matching a wildcard or regex within the value column.
+type wildcardCursor struct {
+	expr ast.Expression
+	args []influxql.Expr
+}
+
+// createWildcardCursor creates a new cursor that reads every field of the measurement
+// that matches any of the wildcards or regexes.
+func createWildcardCursor(t *transpilerState, args ...influxql.Expr) (cursor, error) {
+	src, err := t.source()
+	if err != nil {
+		return nil, err
+	}
+
+	mm, ok := src.(*influxql.Measurement)
+	if !ok {
+		return nil, errors.New("unimplemented: wildcards must select from a measurement")
+	}
+
+	range_, err := t.readRange(mm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter the fields by the regexes. A wildcard matches every field so
+	// no filter is needed if one is present.
+	var fields ast.Expression
+	for _, arg := range args {
+		re, ok := arg.(*influxql.RegexLiteral)
+		if !ok {
+			fields = nil
+			break
+		}
+		var expr ast.Expression = &ast.BinaryExpression{
+			Operator: ast.RegexpMatchOperator,
+			Left:     column("_field"),
+			Right:    &ast.RegexpLiteral{Value: re.Val},
+		}
+		if fields != nil {
+			expr = &ast.LogicalExpression{
+				Operator: ast.OrOperator,
+				Left:     fields,
+				Right:    expr,
+			}
+		}
+		fields = expr
+	}
+
+	var body ast.Expression = &ast.BinaryExpression{
+		Operator: ast.EqualOperator,
+		Left:     column("_measurement"),
+		Right:    &ast.StringLiteral{Value: mm.Name},
+	}
+	if fields != nil {
+		body = &ast.LogicalExpression{
+			Operator: ast.AndOperator,
+			Left:     body,
+			Right:    fields,
+		}
+	}
+	return &wildcardCursor{
+		expr: &ast.PipeExpression{
+			Argument: range_,
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "filter",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{{
+							Key: &ast.Identifier{Name: "fn"},
+							Value: &ast.FunctionExpression{
+								Params: []*ast.Property{{
+									Key: &ast.Identifier{Name: "r"},
+								}},
+								Body: body,
+							},
+						}},
+					},
+				},
+			},
+		},
+		args: args,
+	}, nil
+}
+
+func (c *wildcardCursor) Expr() ast.Expression {
+	return c.expr
+}
+
+func (c *wildcardCursor) Keys() []influxql.Expr {
+	return c.args
+}
+
+func (c *wildcardCursor) Value(expr influxql.Expr) (string, bool) {
+	for _, arg := range c.args {
+		if expr == arg {
+			return execute.DefaultValueColLabel, true
+		}
+	}
+	return "", false
+}
+
+// hasWildcard returns true if any of the fields contains a wildcard or a regex.
+func hasWildcard(fields influxql.Fields) bool {
+	var found bool
+	influxql.WalkFunc(fields, func(n influxql.Node) {
+		switch n.(type) {
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			found = true
+		}
+	})
+	return found
+}
+
+// transpileWildcard transpiles a select statement with fields that contain wildcards.
+// The fields must either all be raw wildcards or all be functions of wildcards.
+func (t *transpilerState) transpileWildcard() (cursor, error) {
+	var (
+		calls []*influxql.Call
+		raw   []influxql.Expr
+	)
+	for _, f := range t.stmt.Fields {
+		switch expr := f.Expr.(type) {
+		case *influxql.Call:
+			if _, err := parseFunction(expr); err != nil {
+				return nil, err
+			}
+			calls = append(calls, expr)
+		case *influxql.VarRef:
+			if expr.Val == "time" {
+				// The time is always selected.
+				continue
+			}
+			raw = append(raw, expr)
+		case *influxql.Wildcard, *influxql.RegexLiteral:
+			raw = append(raw, expr)
+		default:
+			return nil, fmt.Errorf("unimplemented: wildcards within an expression: %s", f.Expr)
+		}
+	}
+
+	if len(calls) > 0 && len(raw) > 0 {
+		// A function of a wildcard is expanded to multiple functions so it
+		// can only be combined with fields if it is a single selector.
+		for _, call := range calls {
+			if !influxql.IsSelector(call) {
+				return nil, errors.New("mixing aggregate and non-aggregate queries is not supported")
+			}
+			switch call.Args[0].(type) {
+			case *influxql.Wildcard, *influxql.RegexLiteral:
+				return nil, errors.New("mixing aggregate and non-aggregate queries is not supported")
+			}
+		}
+		if len(calls) > 1 {
+			return nil, errors.New("mixing multiple selector functions with tags or fields is not supported")
+		}
+		return nil, errors.New("unimplemented: selector function with wildcard fields")
+	} else if len(calls) > 0 {
+		for _, call := range calls {
+			switch call.Args[0].(type) {
+			case *influxql.Wildcard, *influxql.RegexLiteral:
+			default:
+				return nil, errors.New("unimplemented: mixing wildcard and field functions")
+			}
+		}
+		return t.wildcardFunctions(calls)
+	}
+
+	for _, expr := range raw {
+		if _, ok := expr.(*influxql.VarRef); ok {
+			return nil, errors.New("unimplemented: mixing wildcards with fields")
+		}
+	}
+	return t.wildcardFields(raw)
+}
+
+// wildcardFields reads every field matching the wildcards as a column.
+func (t *transpilerState) wildcardFields(args []influxql.Expr) (cursor, error) {
+	if interval, err := t.stmt.GroupByInterval(); err != nil {
+		return nil, err
+	} else if interval > 0 {
+		return nil, errors.New("using GROUP BY requires at least one aggregate function")
+	}
+	switch t.stmt.Fill {
+	case influxql.NoFill:
+		return nil, errors.New("fill(none) must be used with a function")
+	case influxql.LinearFill:
+		return nil, errors.New("fill(linear) must be used with a function")
+	}
+
+	cur, err := createWildcardCursor(t, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pivot the fields into columns so each row holds all of the fields
+	// of a series with the same time.
+	cur = &pipeCursor{
+		expr:   pivot(cur.Expr()),
+		cursor: &columnsCursor{},
+	}
+
+	valuer := influxql.NowValuer{Now: t.config.Now}
+	cond, _, err := influxql.ConditionExpr(t.stmt.Condition, &valuer)
+	if err != nil {
+		return nil, err
+	} else if cond != nil {
+		fn, err := t.mapField(cond, cur)
+		if err != nil {
+			return nil, pkgerrors.Wrap(err, "unable to evaluate condition")
+		}
+		cur = &pipeCursor{
+			expr: &ast.PipeExpression{
+				Argument: cur.Expr(),
+				Call: &ast.CallExpression{
+					Callee: &ast.Identifier{
+						Name: "filter",
+					},
+					Arguments: []ast.Expression{
+						&ast.ObjectExpression{
+							Properties: []*ast.Property{{
+								Key: &ast.Identifier{Name: "fn"},
+								Value: &ast.FunctionExpression{
+									Params: []*ast.Property{{
+										Key: &ast.Identifier{Name: "r"},
+									}},
+									Body: fn,
+								},
+							}},
+						},
+					},
+				},
+			},
+			cursor: cur,
+		}
+	}
+
+	if cur, err = (&groupInfo{}).group(t, cur); err != nil {
+		return nil, err
+	}
+
+	// Remove the columns that were not selected. Every column is selected by a wildcard
+	// except for the stop time that is added by flux.
+	var regexes []*influxql.RegexLiteral
+	for _, arg := range args {
+		re, ok := arg.(*influxql.RegexLiteral)
+		if !ok {
+			regexes = nil
+			break
+		}
+		regexes = append(regexes, re)
+	}
+
+	var call *ast.CallExpression
+	if len(regexes) == 0 {
+		call = &ast.CallExpression{
+			Callee: &ast.Identifier{
+				Name: "drop",
+			},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{{
+						Key: &ast.Identifier{Name: "columns"},
+						Value: &ast.ArrayExpression{
+							Elements: []ast.Expression{
+								&ast.StringLiteral{Value: execute.DefaultStopColLabel},
+							},
+						},
+					}},
+				},
+			},
+		}
+	} else {
+		// Keep the columns used by the group and the columns that match a regex.
+		names := []string{execute.DefaultTimeColLabel, "_measurement", execute.DefaultStartColLabel}
+		for _, d := range t.stmt.Dimensions {
+			if ref, ok := d.Expr.(*influxql.VarRef); ok {
+				names = append(names, ref.Val)
+			}
+		}
+
+		var body ast.Expression
+		or := func(expr ast.Expression) {
+			if body != nil {
+				expr = &ast.LogicalExpression{
+					Operator: ast.OrOperator,
+					Left:     body,
+					Right:    expr,
+				}
+			}
+			body = expr
+		}
+		for _, name := range names {
+			or(&ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     &ast.Identifier{Name: "column"},
+				Right:    &ast.StringLiteral{Value: name},
+			})
+		}
+		for _, re := range regexes {
+			or(&ast.BinaryExpression{
+				Operator: ast.RegexpMatchOperator,
+				Left:     &ast.Identifier{Name: "column"},
+				Right:    &ast.RegexpLiteral{Value: re.Val},
+			})
+		}
+		call = &ast.CallExpression{
+			Callee: &ast.Identifier{
+				Name: "keep",
+			},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{{
+						Key: &ast.Identifier{Name: "fn"},
+						Value: &ast.FunctionExpression{
+							Params: []*ast.Property{{
+								Key: &ast.Identifier{Name: "column"},
+							}},
+							Body: body,
+						},
+					}},
+				},
+			},
+		}
+	}
+	return &mapCursor{
+		expr: &ast.PipeExpression{
+			Argument: cur.Expr(),
+			Call:     call,
+		},
+	}, nil
+}
+
+// wildcardFunctions evaluates each function for every field matching its wildcard
+// and stores the result of each function in a column named after the function and the field.
+func (t *transpilerState) wildcardFunctions(calls []*influxql.Call) (cursor, error) {
+	tables := make([]ast.Expression, 0, len(calls))
+	for _, call := range calls {
+		// The functions are never treated as a selector because they are evaluated
+		// for multiple fields and the time of each field would be different.
+		cur, err := (&groupInfo{call: call}).createCursor(t)
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := cur.Value(call)
+		if !ok {
+			return nil, fmt.Errorf("undefined variable: %s", call)
+		}
+		expr := &ast.PipeExpression{
+			Argument: cur.Expr(),
+			Call: &ast.CallExpression{
+				Callee: &ast.Identifier{
+					Name: "map",
+				},
+				Arguments: []ast.Expression{
+					&ast.ObjectExpression{
+						Properties: []*ast.Property{
+							{
+								Key: &ast.Identifier{Name: "fn"},
+								Value: &ast.FunctionExpression{
+									Params: []*ast.Property{{
+										Key: &ast.Identifier{Name: "r"},
+									}},
+									Body: &ast.ObjectExpression{
+										Properties: []*ast.Property{
+											{
+												Key:   &ast.Identifier{Name: execute.DefaultTimeColLabel},
+												Value: column(execute.DefaultTimeColLabel),
+											},
+											{
+												Key: &ast.Identifier{Name: "_field"},
+												Value: &ast.BinaryExpression{
+													Operator: ast.AdditionOperator,
+													Left:     &ast.StringLiteral{Value: call.Name + "_"},
+													Right:    column("_field"),
+												},
+											},
+											{
+												Key:   &ast.Identifier{Name: execute.DefaultValueColLabel},
+												Value: column(value),
+											},
+										},
+									},
+								},
+							},
+							{
+								Key:   &ast.Identifier{Name: "mergeKey"},
+								Value: &ast.BooleanLiteral{Value: true},
+							},
+						},
+					},
+				},
+			},
+		}
+		tables = append(tables, expr)
+	}
+
+	// Combine the results of every function so they can be pivoted together.
+	expr := tables[0]
+	if len(tables) > 1 {
+		for i, table := range tables {
+			tables[i] = t.assignment(table)
+		}
+		expr = &ast.CallExpression{
+			Callee: &ast.Identifier{
+				Name: "union",
+			},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{{
+						Key: &ast.Identifier{Name: "tables"},
+						Value: &ast.ArrayExpression{
+							Elements: tables,
+						},
+					}},
+				},
+			},
+		}
+	}
+	return &mapCursor{expr: pivot(expr)}, nil
+}
+
+// pivot transforms the values of every field into a column named after the field.
+func pivot(expr ast.Expression) ast.Expression {
+	return &ast.PipeExpression{
+		Argument: expr,
+		Call: &ast.CallExpression{
+			Callee: &ast.Identifier{
+				Name: "pivot",
+			},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{
+						{
+							Key: &ast.Identifier{Name: "rowKey"},
+							Value: &ast.ArrayExpression{
+								Elements: []ast.Expression{
+									&ast.StringLiteral{Value: execute.DefaultTimeColLabel},
+								},
+							},
+						},
+						{
+							Key: &ast.Identifier{Name: "columnKey"},
+							Value: &ast.ArrayExpression{
+								Elements: []ast.Expression{
+									&ast.StringLiteral{Value: "_field"},
+								},
+							},
+						},
+						{
+							Key:   &ast.Identifier{Name: "valueColumn"},
+							Value: &ast.StringLiteral{Value: execute.DefaultValueColLabel},
+						},
+					},
+				},
+			},
+		},
+	}
+}
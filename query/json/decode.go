@@ -0,0 +1,220 @@
+package json
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/query"
+)
+
+// ErrRecordsNotDecodable is returned when decoding results in the records
+// orientation, which does not keep the column types and group keys.
+var ErrRecordsNotDecodable = errors.New("results in the records orientation cannot be decoded into tables; use the tables orientation")
+
+type response struct {
+	Results []struct {
+		Name   string `json:"name"`
+		Tables []struct {
+			Columns []struct {
+				Label    string `json:"label"`
+				DataType string `json:"dataType"`
+				Group    bool   `json:"group"`
+			} `json:"columns"`
+			Data [][]interface{} `json:"data"`
+		} `json:"tables"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// MultiResultDecoder decodes results encoded in the tables orientation.
+type MultiResultDecoder struct{}
+
+// NewMultiResultDecoder returns a decoder of results.
+func NewMultiResultDecoder() *MultiResultDecoder {
+	return &MultiResultDecoder{}
+}
+
+// Decode reads all results from r and closes it. An error encoded in the
+// results is reported by the Err method of the iterator.
+func (d *MultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	defer r.Close()
+
+	br := bufio.NewReader(r)
+	if c, err := peekNonSpace(br); err != nil {
+		return nil, err
+	} else if c == '[' {
+		return nil, ErrRecordsNotDecodable
+	}
+
+	var resp response
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	alloc := &memory.Allocator{}
+	results := make([]flux.Result, 0, len(resp.Results))
+	for _, res := range resp.Results {
+		tables := make([]flux.Table, 0, len(res.Tables))
+		for _, t := range res.Tables {
+			cols := make([]flux.ColMeta, len(t.Columns))
+			for j, c := range t.Columns {
+				typ, err := columnType(c.DataType)
+				if err != nil {
+					return nil, err
+				}
+				cols[j] = flux.ColMeta{Label: c.Label, Type: typ}
+			}
+
+			rows := make([][]values.Value, len(t.Data))
+			for i, data := range t.Data {
+				if len(data) != len(cols) {
+					return nil, fmt.Errorf("row %d of a table has %d values, expected %d", i, len(data), len(cols))
+				}
+				rows[i] = make([]values.Value, len(cols))
+				for j, v := range data {
+					value, err := decodeValue(v, cols[j].Type)
+					if err != nil {
+						return nil, fmt.Errorf("column %q: %v", cols[j].Label, err)
+					}
+					rows[i][j] = value
+				}
+			}
+
+			var (
+				keyCols   []flux.ColMeta
+				keyValues []values.Value
+			)
+			for j, c := range t.Columns {
+				if !c.Group {
+					continue
+				}
+				keyCols = append(keyCols, cols[j])
+				if len(rows) > 0 {
+					keyValues = append(keyValues, rows[0][j])
+				} else {
+					keyValues = append(keyValues, values.NewNull(flux.SemanticType(cols[j].Type)))
+				}
+			}
+
+			b := execute.NewColListTableBuilder(execute.NewGroupKey(keyCols, keyValues), alloc)
+			for _, c := range cols {
+				if _, err := b.AddCol(c); err != nil {
+					return nil, err
+				}
+			}
+			for _, row := range rows {
+				for j, v := range row {
+					if err := b.AppendValue(j, v); err != nil {
+						return nil, err
+					}
+				}
+			}
+			tbl, err := b.Table()
+			if err != nil {
+				return nil, err
+			}
+			tables = append(tables, tbl)
+		}
+		results = append(results, query.NewBufferedResult(res.Name, tables))
+	}
+
+	var err error
+	if resp.Error != "" {
+		err = errors.New(resp.Error)
+	}
+	return query.NewBufferedResultIterator(results, err), nil
+}
+
+// peekNonSpace returns the first byte of r that is not white space.
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return c, r.UnreadByte()
+	}
+}
+
+func columnType(datatype string) (flux.ColType, error) {
+	switch datatype {
+	case boolDatatype:
+		return flux.TBool, nil
+	case intDatatype:
+		return flux.TInt, nil
+	case uintDatatype:
+		return flux.TUInt, nil
+	case floatDatatype:
+		return flux.TFloat, nil
+	case stringDatatype:
+		return flux.TString, nil
+	case timeDatatype:
+		return flux.TTime, nil
+	default:
+		return flux.TInvalid, fmt.Errorf("unknown data type %q", datatype)
+	}
+}
+
+func decodeValue(v interface{}, typ flux.ColType) (values.Value, error) {
+	if v == nil {
+		return values.NewNull(flux.SemanticType(typ)), nil
+	}
+	switch typ {
+	case flux.TBool:
+		if b, ok := v.(bool); ok {
+			return values.NewBool(b), nil
+		}
+	case flux.TInt:
+		if n, ok := v.(json.Number); ok {
+			i, err := n.Int64()
+			if err != nil {
+				return nil, err
+			}
+			return values.NewInt(i), nil
+		}
+	case flux.TUInt:
+		if n, ok := v.(json.Number); ok {
+			u, err := strconv.ParseUint(n.String(), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewUInt(u), nil
+		}
+	case flux.TFloat:
+		if n, ok := v.(json.Number); ok {
+			f, err := n.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return values.NewFloat(f), nil
+		}
+	case flux.TString:
+		if s, ok := v.(string); ok {
+			return values.NewString(s), nil
+		}
+	case flux.TTime:
+		if s, ok := v.(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewTime(values.ConvertTime(t)), nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected value %v for type %v", v, typ)
+}
@@ -0,0 +1,387 @@
+// Package json encodes query results as JSON, either as a list of records
+// that data frame libraries load directly, or as tables that keep the
+// column types and group keys of the results.
+package json
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const DialectType = "json"
+
+// Orient is the layout of the encoded results.
+type Orient string
+
+const (
+	// OrientRecords encodes every row as an object keyed by column label,
+	// with the result name and table index under "result" and "table".
+	OrientRecords Orient = "records"
+	// OrientTables encodes the columns and rows of every table, grouped
+	// by result.
+	OrientTables Orient = "tables"
+)
+
+// Valid reports whether o is a known orientation.
+func (o Orient) Valid() bool {
+	return o == OrientRecords || o == OrientTables
+}
+
+// Data types of the columns in the tables orientation. They are the
+// datatype annotations of annotated CSV.
+const (
+	boolDatatype   = "boolean"
+	intDatatype    = "long"
+	uintDatatype   = "unsignedLong"
+	floatDatatype  = "double"
+	stringDatatype = "string"
+	timeDatatype   = "dateTime:RFC3339"
+)
+
+// AddDialectMappings adds the mapping for the JSON dialect.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	return mappings.Add(DialectType, func() flux.Dialect {
+		return DefaultDialect()
+	})
+}
+
+// ResultEncoderConfig configures the encoding of results.
+type ResultEncoderConfig struct {
+	// Orient is the layout of the results; it defaults to OrientRecords.
+	Orient Orient
+}
+
+// Dialect is the JSON dialect of query results.
+type Dialect struct {
+	ResultEncoderConfig
+}
+
+// DefaultDialect returns a dialect that encodes records.
+func DefaultDialect() *Dialect {
+	return &Dialect{
+		ResultEncoderConfig: ResultEncoderConfig{Orient: OrientRecords},
+	}
+}
+
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	return NewMultiResultEncoder(d.ResultEncoderConfig)
+}
+
+func (d *Dialect) DialectType() flux.DialectType {
+	return DialectType
+}
+
+func (d *Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// MultiResultEncoder encodes results as a single JSON document. Tables
+// without rows are not encoded.
+//
+// An error met before anything is written is returned as is. Later errors
+// end the document: the tables orientation sets its "error" key and the
+// records orientation appends an object with only an "error" key.
+type MultiResultEncoder struct {
+	c ResultEncoderConfig
+}
+
+// NewMultiResultEncoder returns an encoder configured with c.
+func NewMultiResultEncoder(c ResultEncoderConfig) *MultiResultEncoder {
+	if c.Orient == "" {
+		c.Orient = OrientRecords
+	}
+	return &MultiResultEncoder{c: c}
+}
+
+func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	wc := &iocounter.Writer{Writer: w}
+	enc := &encoder{
+		w:      bufio.NewWriter(wc),
+		flush:  w,
+		orient: e.c.Orient,
+	}
+	for results.More() {
+		if err := enc.encodeResult(results.Next()); err != nil {
+			return enc.fail(wc, err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return enc.fail(wc, err)
+	}
+	enc.end("")
+	return wc.Count(), enc.w.Flush()
+}
+
+type flusher interface {
+	Flush()
+}
+
+// encoder writes the JSON document as the results are read, keeping
+// track of the objects and arrays it has opened.
+type encoder struct {
+	w      *bufio.Writer
+	flush  io.Writer
+	orient Orient
+
+	// started is set once the document has been opened.
+	started bool
+	// inResult and inTable are set while a result or a table is open in
+	// the tables orientation.
+	inResult, inTable bool
+	// results, tables and rows count the elements written to the open
+	// array of each level, and records the records written.
+	results, tables, rows, records int
+	// table is the index of the table within its result.
+	table int
+}
+
+func (e *encoder) encodeResult(res flux.Result) error {
+	e.table, e.tables = 0, 0
+	err := res.Tables().Do(func(tbl flux.Table) error {
+		if tbl.Empty() {
+			tbl.Done()
+			return nil
+		}
+		if err := e.encodeTable(res.Name(), tbl); err != nil {
+			return err
+		}
+		e.table++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if e.inResult {
+		e.w.WriteString("]}")
+		e.inResult = false
+	}
+	return nil
+}
+
+func (e *encoder) encodeTable(result string, tbl flux.Table) error {
+	e.rows = 0
+	cols := tbl.Cols()
+	err := tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() == 0 {
+			return nil
+		}
+		if !e.inTable {
+			e.begin(result, tbl.Key(), cols)
+		}
+		for i := 0; i < cr.Len(); i++ {
+			e.writeRow(result, cr, i)
+		}
+		return nil
+	})
+	if e.inTable && e.orient == OrientTables {
+		e.w.WriteString("]}")
+	}
+	e.inTable = false
+	if err != nil {
+		return err
+	}
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+	if f, ok := e.flush.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// start opens the document unless it is open.
+func (e *encoder) start() {
+	if e.started {
+		return
+	}
+	e.started = true
+	if e.orient == OrientTables {
+		e.w.WriteString(`{"results":[`)
+	} else {
+		e.w.WriteString("[")
+	}
+}
+
+// begin opens the document, the result and the table as needed before the
+// first row of a table.
+func (e *encoder) begin(result string, key flux.GroupKey, cols []flux.ColMeta) {
+	e.start()
+	e.inTable = true
+	if e.orient != OrientTables {
+		return
+	}
+
+	if !e.inResult {
+		if e.results > 0 {
+			e.w.WriteString(",")
+		}
+		e.results++
+		e.w.WriteString(`{"name":`)
+		writeString(e.w, result)
+		e.w.WriteString(`,"tables":[`)
+		e.inResult = true
+	}
+	if e.tables > 0 {
+		e.w.WriteString(",")
+	}
+	e.tables++
+	e.w.WriteString(`{"columns":[`)
+	for j, c := range cols {
+		if j > 0 {
+			e.w.WriteString(",")
+		}
+		e.w.WriteString(`{"label":`)
+		writeString(e.w, c.Label)
+		e.w.WriteString(`,"dataType":`)
+		writeString(e.w, datatype(c.Type))
+		e.w.WriteString(`,"group":`)
+		e.w.WriteString(strconv.FormatBool(key.HasCol(c.Label)))
+		e.w.WriteString("}")
+	}
+	e.w.WriteString(`],"data":[`)
+}
+
+func (e *encoder) writeRow(result string, cr flux.ColReader, i int) {
+	if e.orient == OrientTables {
+		if e.rows > 0 {
+			e.w.WriteString(",")
+		}
+		e.w.WriteString("[")
+		for j := range cr.Cols() {
+			if j > 0 {
+				e.w.WriteString(",")
+			}
+			writeValue(e.w, execute.ValueForRow(cr, i, j))
+		}
+		e.w.WriteString("]")
+		e.rows++
+		return
+	}
+
+	if e.records > 0 {
+		e.w.WriteString(",")
+	}
+	e.w.WriteString("\n")
+	e.w.WriteString(`{"result":`)
+	writeString(e.w, result)
+	e.w.WriteString(`,"table":`)
+	e.w.WriteString(strconv.Itoa(e.table))
+	for j, c := range cr.Cols() {
+		e.w.WriteString(",")
+		writeString(e.w, c.Label)
+		e.w.WriteString(":")
+		writeValue(e.w, execute.ValueForRow(cr, i, j))
+	}
+	e.w.WriteString("}")
+	e.records++
+}
+
+// end closes the document, with err as its error unless it is empty.
+func (e *encoder) end(err string) {
+	e.start()
+	if e.orient == OrientTables {
+		if e.inResult {
+			e.w.WriteString("]}")
+			e.inResult = false
+		}
+		e.w.WriteString("]")
+		if err != "" {
+			e.w.WriteString(`,"error":`)
+			writeString(e.w, err)
+		}
+		e.w.WriteString("}\n")
+		return
+	}
+	if err != "" {
+		if e.records > 0 {
+			e.w.WriteString(",")
+		}
+		e.w.WriteString("\n")
+		e.w.WriteString(`{"error":`)
+		writeString(e.w, err)
+		e.w.WriteString("}")
+	}
+	e.w.WriteString("\n]\n")
+}
+
+// fail returns err if nothing was written, and ends the document with it
+// otherwise.
+func (e *encoder) fail(wc *iocounter.Writer, err error) (int64, error) {
+	if !e.started {
+		return wc.Count(), err
+	}
+	e.end(err.Error())
+	if ferr := e.w.Flush(); ferr != nil {
+		return wc.Count(), ferr
+	}
+	return wc.Count(), err
+}
+
+func datatype(t flux.ColType) string {
+	switch t {
+	case flux.TBool:
+		return boolDatatype
+	case flux.TInt:
+		return intDatatype
+	case flux.TUInt:
+		return uintDatatype
+	case flux.TFloat:
+		return floatDatatype
+	case flux.TString:
+		return stringDatatype
+	case flux.TTime:
+		return timeDatatype
+	default:
+		return t.String()
+	}
+}
+
+func writeString(w *bufio.Writer, s string) {
+	b, _ := json.Marshal(s)
+	w.Write(b)
+}
+
+// writeValue writes v as JSON. Times are RFC3339 strings, and nulls and
+// floats JSON cannot represent, such as NaN, are null.
+func writeValue(w *bufio.Writer, v values.Value) {
+	if v.IsNull() {
+		w.WriteString("null")
+		return
+	}
+	switch v.Type().Nature() {
+	case semantic.Bool:
+		w.WriteString(strconv.FormatBool(v.Bool()))
+	case semantic.Int:
+		w.WriteString(strconv.FormatInt(v.Int(), 10))
+	case semantic.UInt:
+		w.WriteString(strconv.FormatUint(v.UInt(), 10))
+	case semantic.Float:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			w.WriteString("null")
+			return
+		}
+		w.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+	case semantic.String:
+		writeString(w, v.Str())
+	case semantic.Time:
+		writeString(w, v.Time().Time().Format(time.RFC3339Nano))
+	default:
+		writeString(w, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,160 @@
+package json_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/json"
+)
+
+// newResults returns two results, the second of which fails if err is set.
+func newResults(err error) []flux.Result {
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+		{Label: "host", Type: flux.TString},
+		{Label: "n", Type: flux.TInt},
+		{Label: "u", Type: flux.TUInt},
+		{Label: "ok", Type: flux.TBool},
+	}
+	first := executetest.NewResult([]*executetest.Table{
+		{
+			KeyCols: []string{"host"},
+			ColMeta: cols,
+			Data: [][]interface{}{
+				{execute.Time(0), 1.5, "a", int64(-1), uint64(1), true},
+				{execute.Time(1500000000), nil, "a", int64(2), nil, false},
+			},
+		},
+		{
+			KeyCols:   []string{"host"},
+			KeyValues: []interface{}{"c"},
+			ColMeta:   cols,
+		},
+		{
+			KeyCols: []string{"host"},
+			ColMeta: cols,
+			Data: [][]interface{}{
+				{execute.Time(0), 2.0, "b", int64(3), uint64(3), nil},
+			},
+		},
+	})
+	first.Nm = "first"
+	second := executetest.NewResult([]*executetest.Table{{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TString}},
+		Data:    [][]interface{}{{"x\"y"}},
+		Err:     err,
+	}})
+	second.Nm = "second"
+	return []flux.Result{first, second}
+}
+
+// withoutEmptyTables removes the tables without rows, which are not
+// encoded.
+func withoutEmptyTables(results []flux.Result) []flux.Result {
+	for _, r := range results {
+		r := r.(*executetest.Result)
+		tables := r.Tbls[:0]
+		for _, tbl := range r.Tbls {
+			if !tbl.Empty() {
+				tables = append(tables, tbl)
+			}
+		}
+		r.Tbls = tables
+	}
+	return results
+}
+
+func encode(t *testing.T, orient json.Orient, results []flux.Result) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := json.NewMultiResultEncoder(json.ResultEncoderConfig{Orient: orient})
+	_, err := enc.Encode(&buf, flux.NewSliceResultIterator(results))
+	return buf.String(), err
+}
+
+func TestMultiResultEncoder_Records(t *testing.T) {
+	got, err := encode(t, json.OrientRecords, newResults(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[
+{"result":"first","table":0,"_time":"1970-01-01T00:00:00Z","_value":1.5,"host":"a","n":-1,"u":1,"ok":true},
+{"result":"first","table":0,"_time":"1970-01-01T00:00:01.5Z","_value":null,"host":"a","n":2,"u":null,"ok":false},
+{"result":"first","table":1,"_time":"1970-01-01T00:00:00Z","_value":2,"host":"b","n":3,"u":3,"ok":null},
+{"result":"second","table":0,"_value":"x\"y"}
+]
+`
+	if got != want {
+		t.Errorf("unexpected records:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMultiResultEncoder_Tables(t *testing.T) {
+	got, err := encode(t, json.OrientTables, newResults(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	itr, err := json.NewMultiResultDecoder().Decode(ioutil.NopCloser(bytes.NewBufferString(got)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := withoutEmptyTables(newResults(nil))
+	if err := executetest.EqualResultIterators(flux.NewSliceResultIterator(want), itr); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMultiResultEncoder_Errors(t *testing.T) {
+	expErr := errors.New("expected error")
+
+	// Errors before any output are returned.
+	for _, orient := range []json.Orient{json.OrientRecords, json.OrientTables} {
+		results := newResults(expErr)[1:]
+		got, err := encode(t, orient, results)
+		if err != expErr {
+			t.Errorf("%s: expected error %v, got %v", orient, expErr, err)
+		}
+		if got != "" {
+			t.Errorf("%s: expected no output, got %q", orient, got)
+		}
+	}
+
+	got, err := encode(t, json.OrientRecords, newResults(expErr))
+	if err != expErr {
+		t.Errorf("expected error %v, got %v", expErr, err)
+	}
+	if want := "},\n{\"error\":\"expected error\"}\n]\n"; !bytes.HasSuffix([]byte(got), []byte(want)) {
+		t.Errorf("records do not end with the error:\n%s", got)
+	}
+
+	got, err = encode(t, json.OrientTables, newResults(expErr))
+	if err != expErr {
+		t.Errorf("expected error %v, got %v", expErr, err)
+	}
+	itr, err := json.NewMultiResultDecoder().Decode(ioutil.NopCloser(bytes.NewBufferString(got)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := withoutEmptyTables(newResults(nil)[:1])
+	if err := executetest.EqualResultIterators(query.NewBufferedResultIterator(want, expErr), itr); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMultiResultDecoder_Records(t *testing.T) {
+	got, err := encode(t, json.OrientRecords, newResults(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := json.NewMultiResultDecoder().Decode(ioutil.NopCloser(bytes.NewBufferString(got))); err != json.ErrRecordsNotDecodable {
+		t.Errorf("expected error %v, got %v", json.ErrRecordsNotDecodable, err)
+	}
+}
@@ -0,0 +1,162 @@
+package lineprotocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+)
+
+// resultName is the name of the result line protocol is decoded into.
+const resultName = "_result"
+
+// MultiResultDecoder decodes points into a single result, with a table
+// for every field of every series. The tables have the columns
+// _measurement, the tags, _field, _value and _time, and all columns but
+// _value and _time are in the group key.
+type MultiResultDecoder struct{}
+
+// NewMultiResultDecoder returns a decoder of line protocol.
+func NewMultiResultDecoder() *MultiResultDecoder {
+	return &MultiResultDecoder{}
+}
+
+// series is the table of a field of a series.
+type series struct {
+	measurement, field string
+	tags               models.Tags
+	typ                flux.ColType
+	values             []values.Value
+	times              []int64
+}
+
+// Decode reads all points from r and closes it. An error encoded in the
+// points is reported by the Err method of the iterator.
+func (d *MultiResultDecoder) Decode(r io.ReadCloser) (flux.ResultIterator, error) {
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var resultErr error
+	if i := bytes.LastIndex(data, []byte(errorPrefix)); i >= 0 && (i == 0 || data[i-1] == '\n') {
+		resultErr = errors.New(string(bytes.TrimSpace(data[i+len(errorPrefix):])))
+		data = data[:i]
+	}
+
+	// The parser moves the measurement and the field of points to tags
+	// when it is given the name of their bucket. Any name works here.
+	points, err := models.ParsePoints(data, []byte("_"))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		bySeries = make(map[string]*series)
+		order    []*series
+	)
+	for _, p := range points {
+		fields, err := p.Fields()
+		if err != nil {
+			return nil, err
+		}
+		var (
+			tags        models.Tags
+			measurement string
+			field       string
+		)
+		for _, t := range p.Tags() {
+			switch string(t.Key) {
+			case models.MeasurementTagKey:
+				measurement = string(t.Value)
+			case models.FieldKeyTagKey:
+				field = string(t.Value)
+			default:
+				tags = append(tags, t)
+			}
+		}
+		v, ok := fields[field]
+		if !ok {
+			continue
+		}
+		value := values.New(v)
+		typ := flux.ColumnType(value.Type())
+
+		key := string(p.Key())
+		s, ok := bySeries[key]
+		if !ok {
+			s = &series{
+				measurement: measurement,
+				field:       field,
+				tags:        tags,
+				typ:         typ,
+			}
+			bySeries[key] = s
+			order = append(order, s)
+		}
+		if typ != s.typ {
+			return nil, fmt.Errorf("field %q of measurement %q has values of type %v and %v", field, measurement, s.typ, typ)
+		}
+		s.values = append(s.values, value)
+		s.times = append(s.times, p.UnixNano())
+	}
+
+	alloc := &memory.Allocator{}
+	tables := make([]flux.Table, 0, len(order))
+	for _, s := range order {
+		tbl, err := s.table(alloc)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+	results := []flux.Result{query.NewBufferedResult(resultName, tables)}
+	return query.NewBufferedResultIterator(results, resultErr), nil
+}
+
+func (s *series) table(alloc *memory.Allocator) (flux.Table, error) {
+	sort.Sort(s.tags)
+	keyCols := []flux.ColMeta{{Label: measurementLabel, Type: flux.TString}}
+	keyValues := []values.Value{values.NewString(s.measurement)}
+	for _, t := range s.tags {
+		keyCols = append(keyCols, flux.ColMeta{Label: string(t.Key), Type: flux.TString})
+		keyValues = append(keyValues, values.NewString(string(t.Value)))
+	}
+	keyCols = append(keyCols, flux.ColMeta{Label: fieldLabel, Type: flux.TString})
+	keyValues = append(keyValues, values.NewString(s.field))
+
+	b := execute.NewColListTableBuilder(execute.NewGroupKey(keyCols, keyValues), alloc)
+	if err := execute.AddTableKeyCols(b.Key(), b); err != nil {
+		return nil, err
+	}
+	valueIdx, err := b.AddCol(flux.ColMeta{Label: valueLabel, Type: s.typ})
+	if err != nil {
+		return nil, err
+	}
+	timeIdx, err := b.AddCol(flux.ColMeta{Label: timeLabel, Type: flux.TTime})
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range s.values {
+		if err := execute.AppendKeyValues(b.Key(), b); err != nil {
+			return nil, err
+		}
+		if err := b.AppendValue(valueIdx, v); err != nil {
+			return nil, err
+		}
+		if err := b.AppendTime(timeIdx, values.Time(s.times[i])); err != nil {
+			return nil, err
+		}
+	}
+	return b.Table()
+}
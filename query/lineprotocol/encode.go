@@ -0,0 +1,261 @@
+// Package lineprotocol encodes query results as line protocol, so that
+// they can be written back to a bucket or to another database.
+//
+// Every row of a table is a point. The measurement and the time of the
+// point are the _measurement and _time columns, and its tags are the
+// other string columns of the group key, except _start, _stop and _field.
+// If the table has _field and _value columns, the point has a single field
+// named by _field, otherwise every other column is a field. Nulls are left
+// out and rows without fields are skipped.
+package lineprotocol
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/iocounter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+)
+
+const DialectType = "lineprotocol"
+
+// ContentType is the media type of line protocol.
+const ContentType = "text/vnd.influx.line-protocol"
+
+// errorPrefix starts the comment line that holds the error that ended the
+// results.
+const errorPrefix = "# error: "
+
+const (
+	measurementLabel = "_measurement"
+	timeLabel        = "_time"
+	fieldLabel       = "_field"
+	valueLabel       = "_value"
+	startLabel       = "_start"
+	stopLabel        = "_stop"
+)
+
+// AddDialectMappings adds the mapping for the line protocol dialect.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	return mappings.Add(DialectType, func() flux.Dialect {
+		return new(Dialect)
+	})
+}
+
+// Dialect is the line protocol dialect of query results.
+type Dialect struct{}
+
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	return NewMultiResultEncoder()
+}
+
+func (d *Dialect) DialectType() flux.DialectType {
+	return DialectType
+}
+
+func (d *Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType+"; charset=utf-8")
+	w.Header().Set("Transfer-Encoding", "chunked")
+}
+
+// MultiResultEncoder encodes the rows of results as points. The names of
+// the results are not encoded.
+//
+// An error met before anything is written is returned as is. Later errors
+// are written as a last comment line starting with "# error: ".
+type MultiResultEncoder struct{}
+
+// NewMultiResultEncoder returns an encoder of line protocol.
+func NewMultiResultEncoder() *MultiResultEncoder {
+	return &MultiResultEncoder{}
+}
+
+type flusher interface {
+	Flush()
+}
+
+func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	wc := &iocounter.Writer{Writer: w}
+	bw := bufio.NewWriter(wc)
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			if err := encodeTable(bw, tbl); err != nil {
+				return err
+			}
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			if f, ok := w.(flusher); ok {
+				f.Flush()
+			}
+			return nil
+		}); err != nil {
+			return fail(wc, bw, err)
+		}
+	}
+	if err := results.Err(); err != nil {
+		return fail(wc, bw, err)
+	}
+	return wc.Count(), bw.Flush()
+}
+
+// fail returns err if nothing was written, and writes it otherwise.
+func fail(wc *iocounter.Writer, bw *bufio.Writer, err error) (int64, error) {
+	if wc.Count() == 0 && bw.Buffered() == 0 {
+		return 0, err
+	}
+	bw.WriteString(errorPrefix)
+	// A newline would end the comment.
+	bw.WriteString(strings.Replace(err.Error(), "\n", " ", -1))
+	bw.WriteString("\n")
+	if ferr := bw.Flush(); ferr != nil {
+		return wc.Count(), ferr
+	}
+	return wc.Count(), err
+}
+
+// columns are the indexes of the columns that make up a point.
+type columns struct {
+	measurement, time int
+	tags              []int
+	// field and value are the _field and _value columns, or -1 if each
+	// column in fields is a field.
+	field, value int
+	fields       []int
+}
+
+func newColumns(key flux.GroupKey, cols []flux.ColMeta) (*columns, error) {
+	c := &columns{
+		measurement: execute.ColIdx(measurementLabel, cols),
+		time:        execute.ColIdx(timeLabel, cols),
+		field:       execute.ColIdx(fieldLabel, cols),
+		value:       execute.ColIdx(valueLabel, cols),
+	}
+	if c.measurement < 0 || cols[c.measurement].Type != flux.TString {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "line protocol requires a _measurement column of type string",
+		}
+	}
+	if c.time < 0 || cols[c.time].Type != flux.TTime {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "line protocol requires a _time column of type time",
+		}
+	}
+	if c.field < 0 || c.value < 0 || cols[c.field].Type != flux.TString {
+		c.field, c.value = -1, -1
+	}
+
+	for j, col := range cols {
+		switch col.Label {
+		case measurementLabel, timeLabel, startLabel, stopLabel:
+			continue
+		}
+		if j == c.field || j == c.value {
+			continue
+		}
+		if col.Type == flux.TString && key.HasCol(col.Label) {
+			c.tags = append(c.tags, j)
+		} else if c.field < 0 {
+			c.fields = append(c.fields, j)
+		}
+	}
+	return c, nil
+}
+
+func encodeTable(w *bufio.Writer, tbl flux.Table) error {
+	if tbl.Empty() {
+		tbl.Done()
+		return nil
+	}
+	cols, err := newColumns(tbl.Key(), tbl.Cols())
+	if err != nil {
+		tbl.Done()
+		return err
+	}
+
+	var buf []byte
+	return tbl.Do(func(cr flux.ColReader) error {
+		labels := cr.Cols()
+		for i := 0; i < cr.Len(); i++ {
+			m := execute.ValueForRow(cr, i, cols.measurement)
+			t := execute.ValueForRow(cr, i, cols.time)
+			if m.IsNull() || t.IsNull() {
+				continue
+			}
+
+			tags := make(map[string]string, len(cols.tags))
+			for _, j := range cols.tags {
+				if v := execute.ValueForRow(cr, i, j); !v.IsNull() {
+					tags[labels[j].Label] = v.Str()
+				}
+			}
+
+			fields := make(models.Fields)
+			if cols.field >= 0 {
+				f := execute.ValueForRow(cr, i, cols.field)
+				if !f.IsNull() {
+					addField(fields, f.Str(), execute.ValueForRow(cr, i, cols.value))
+				}
+			} else {
+				for _, j := range cols.fields {
+					addField(fields, labels[j].Label, execute.ValueForRow(cr, i, j))
+				}
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			p, err := models.NewPoint(m.Str(), models.NewTags(tags), fields, t.Time().Time())
+			if err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  "row cannot be encoded as line protocol",
+					Err:  err,
+				}
+			}
+			buf = p.AppendString(buf[:0])
+			buf = append(buf, '\n')
+			if _, err := w.Write(buf); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// addField adds v to fields unless it is null or a float line protocol
+// cannot represent, such as NaN. Times are RFC3339 strings.
+func addField(fields models.Fields, name string, v values.Value) {
+	if v.IsNull() {
+		return
+	}
+	switch v.Type().Nature() {
+	case semantic.Bool:
+		fields[name] = v.Bool()
+	case semantic.Int:
+		fields[name] = v.Int()
+	case semantic.UInt:
+		fields[name] = v.UInt()
+	case semantic.Float:
+		if f := v.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			fields[name] = f
+		}
+	case semantic.String:
+		fields[name] = v.Str()
+	case semantic.Time:
+		fields[name] = v.Time().Time().Format(time.RFC3339Nano)
+	}
+}
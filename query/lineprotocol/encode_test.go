@@ -0,0 +1,178 @@
+package lineprotocol_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/lineprotocol"
+)
+
+func encode(t *testing.T, results ...flux.Result) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	_, err := lineprotocol.NewMultiResultEncoder().Encode(&buf, flux.NewSliceResultIterator(results))
+	return buf.String(), err
+}
+
+func TestMultiResultEncoder(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   *executetest.Table
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "field and value",
+			table: &executetest.Table{
+				KeyCols: []string{"_start", "_stop", "_measurement", "_field", "host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_start", Type: flux.TTime},
+					{Label: "_stop", Type: flux.TTime},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_measurement", Type: flux.TString},
+					{Label: "_field", Type: flux.TString},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "host", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), execute.Time(10), execute.Time(1), "cpu", "usage", 1.5, "a b"},
+					{execute.Time(0), execute.Time(10), execute.Time(2), "cpu", "usage", nil, "a b"},
+					{execute.Time(0), execute.Time(10), execute.Time(3), "cpu", "usage", 2.0, "a b"},
+				},
+			},
+			want: "cpu,host=a\\ b usage=1.5 1\ncpu,host=a\\ b usage=2 3\n",
+		},
+		{
+			name: "pivoted",
+			table: &executetest.Table{
+				KeyCols: []string{"_measurement", "host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_measurement", Type: flux.TString},
+					{Label: "host", Type: flux.TString},
+					{Label: "n", Type: flux.TInt},
+					{Label: "u", Type: flux.TUInt},
+					{Label: "ok", Type: flux.TBool},
+					{Label: "msg", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "m", "a", int64(1), uint64(2), true, "hi"},
+					{execute.Time(2), "m", nil, nil, nil, nil, nil},
+					{execute.Time(3), "m", nil, int64(-3), nil, nil, nil},
+				},
+			},
+			want: "m,host=a msg=\"hi\",n=1i,ok=true,u=2u 1\nm n=-3i 3\n",
+		},
+		{
+			name: "no measurement",
+			table: &executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := encode(t, executetest.NewResult([]*executetest.Table{tt.table}))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected line protocol:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiResultEncoder_Errors(t *testing.T) {
+	expErr := errors.New("expected\nerror")
+	table := func(err error) *executetest.Table {
+		return &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{{execute.Time(1), "m", 1.0}},
+			Err:  err,
+		}
+	}
+
+	got, err := encode(t, executetest.NewResult([]*executetest.Table{table(expErr)}))
+	if err != expErr || got != "" {
+		t.Errorf("expected error %v without output, got %v and %q", expErr, err, got)
+	}
+
+	got, err = encode(t, executetest.NewResult([]*executetest.Table{table(nil), table(expErr)}))
+	if err != expErr {
+		t.Errorf("expected error %v, got %v", expErr, err)
+	}
+	if want := "m _value=1 1\n# error: expected error\n"; got != want {
+		t.Errorf("unexpected line protocol:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestMultiResultDecoder(t *testing.T) {
+	data := `cpu,host=a usage=1.5,idle=2i 1
+mem free=3u 1
+cpu,host=a usage=2.5 2
+# error: expected error
+`
+	itr, err := lineprotocol.NewMultiResultDecoder().Decode(ioutil.NopCloser(bytes.NewBufferString(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cols := func(typ flux.ColType, tags ...string) []flux.ColMeta {
+		cols := []flux.ColMeta{{Label: "_measurement", Type: flux.TString}}
+		for _, tag := range tags {
+			cols = append(cols, flux.ColMeta{Label: tag, Type: flux.TString})
+		}
+		return append(cols,
+			flux.ColMeta{Label: "_field", Type: flux.TString},
+			flux.ColMeta{Label: "_value", Type: typ},
+			flux.ColMeta{Label: "_time", Type: flux.TTime},
+		)
+	}
+	want := executetest.NewResult([]*executetest.Table{
+		{
+			KeyCols: []string{"_measurement", "host", "_field"},
+			ColMeta: cols(flux.TFloat, "host"),
+			Data: [][]interface{}{
+				{"cpu", "a", "usage", 1.5, execute.Time(1)},
+				{"cpu", "a", "usage", 2.5, execute.Time(2)},
+			},
+		},
+		{
+			KeyCols: []string{"_measurement", "host", "_field"},
+			ColMeta: cols(flux.TInt, "host"),
+			Data: [][]interface{}{
+				{"cpu", "a", "idle", int64(2), execute.Time(1)},
+			},
+		},
+		{
+			KeyCols: []string{"_measurement", "_field"},
+			ColMeta: cols(flux.TUInt),
+			Data: [][]interface{}{
+				{"mem", "free", uint64(3), execute.Time(1)},
+			},
+		},
+	})
+	want.Nm = "_result"
+	wantItr := query.NewBufferedResultIterator([]flux.Result{want}, errors.New("expected error"))
+	if err := executetest.EqualResultIterators(wantItr, itr); err != nil {
+		t.Error(err)
+	}
+}
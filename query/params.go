@@ -0,0 +1,160 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// ParamsIdentifier is the identifier of the record of the parameters of a
+// Flux query.
+const ParamsIdentifier = "params"
+
+// Params are the typed parameters of a query. Values are strings, booleans,
+// integers, floats, arrays of values and records of values. In JSON,
+// numbers without a fraction or an exponent are integers.
+//
+// Flux queries read them from the params record, like params.host, and
+// InfluxQL queries bind them to parameters like $host.
+type Params map[string]interface{}
+
+func (p *Params) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw map[string]interface{}
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	params := make(Params, len(raw))
+	for k, v := range raw {
+		v, err := fromJSON(v)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %v", k, err)
+		}
+		params[k] = v
+	}
+	*p = params
+	return nil
+}
+
+// fromJSON converts the numbers of a value decoded from JSON into integers
+// and floats.
+func fromJSON(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, nil
+		}
+		return v.Float64()
+	case []interface{}:
+		for i := range v {
+			e, err := fromJSON(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = e
+		}
+	case map[string]interface{}:
+		for k := range v {
+			e, err := fromJSON(v[k])
+			if err != nil {
+				return nil, err
+			}
+			v[k] = e
+		}
+	}
+	return v, nil
+}
+
+var identifierRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// File returns a Flux file that assigns the parameters to the params
+// record. The values are literals of the file, so they are never parsed
+// as Flux.
+func (p Params) File() (*ast.File, error) {
+	record, err := record(p)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.File{
+		Body: []ast.Statement{
+			&ast.VariableAssignment{
+				ID:   &ast.Identifier{Name: ParamsIdentifier},
+				Init: record,
+			},
+		},
+	}, nil
+}
+
+// Validate returns an error if the name of a parameter is not an
+// identifier or a value is not supported, following the rules of File.
+func (p Params) Validate() error {
+	_, err := p.File()
+	return err
+}
+
+func record(m map[string]interface{}) (*ast.ObjectExpression, error) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	obj := &ast.ObjectExpression{
+		Properties: make([]*ast.Property, len(keys)),
+	}
+	for i, k := range keys {
+		if !identifierRE.MatchString(k) {
+			return nil, fmt.Errorf("parameter name %q is not an identifier", k)
+		}
+		v, err := literal(m[k])
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %v", k, err)
+		}
+		obj.Properties[i] = &ast.Property{
+			Key:   &ast.Identifier{Name: k},
+			Value: v,
+		}
+	}
+	return obj, nil
+}
+
+// literal returns the Flux expression of a parameter value.
+func literal(v interface{}) (ast.Expression, error) {
+	switch v := v.(type) {
+	case string:
+		return &ast.StringLiteral{Value: v}, nil
+	case bool:
+		return &ast.BooleanLiteral{Value: v}, nil
+	case int:
+		return &ast.IntegerLiteral{Value: int64(v)}, nil
+	case int64:
+		return &ast.IntegerLiteral{Value: v}, nil
+	case float64:
+		return &ast.FloatLiteral{Value: v}, nil
+	case []interface{}:
+		arr := &ast.ArrayExpression{
+			Elements: make([]ast.Expression, len(v)),
+		}
+		for i, e := range v {
+			l, err := literal(e)
+			if err != nil {
+				return nil, err
+			}
+			arr.Elements[i] = l
+		}
+		return arr, nil
+	case map[string]interface{}:
+		return record(v)
+	case Params:
+		return record(v)
+	case nil:
+		return nil, fmt.Errorf("null values are not supported")
+	default:
+		return nil, fmt.Errorf("values of type %T are not supported", v)
+	}
+}
@@ -0,0 +1,90 @@
+package query_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/values"
+	"github.com/influxdata/influxdb/query"
+	_ "github.com/influxdata/influxdb/query/builtin"
+)
+
+func TestParams_UnmarshalJSON(t *testing.T) {
+	var got query.Params
+	data := `{"s": "a", "b": true, "i": -1, "f": 1.5, "e": 1e3, "a": [1, 2.5], "r": {"x": 2}}`
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := query.Params{
+		"s": "a",
+		"b": true,
+		"i": int64(-1),
+		"f": 1.5,
+		"e": 1000.0,
+		"a": []interface{}{int64(1), 2.5},
+		"r": map[string]interface{}{"x": int64(2)},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected params -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestParams_File(t *testing.T) {
+	params := query.Params{
+		"host":  `a" or r.host != "`,
+		"limit": int64(10),
+		"ratio": 0.5,
+		"ok":    false,
+		"hosts": []interface{}{"a", "b"},
+		"range": map[string]interface{}{"start": "-1h"},
+	}
+	f, err := params.File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `params = {
+	host: "a\" or r.host != \"",
+	hosts: ["a", "b"],
+	limit: 10,
+	ok: false,
+	range: {start: "-1h"},
+	ratio: 0.5,
+}`
+	if got := ast.Format(f); got != want {
+		t.Errorf("unexpected file:\n%s\nwant:\n%s", got, want)
+	}
+
+	pkg := parser.ParseSource(`host = params.host
+limit = params.limit + 1`)
+	pkg.Files = append([]*ast.File{f}, pkg.Files...)
+	_, scope, err := flux.EvalAST(context.Background(), pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]values.Value{
+		"host":  values.NewString(`a" or r.host != "`),
+		"limit": values.NewInt(11),
+	} {
+		got, ok := scope.Lookup(name)
+		if !ok || !got.Equal(want) {
+			t.Errorf("unexpected value of %s: %v", name, got)
+		}
+	}
+}
+
+func TestParams_File_Errors(t *testing.T) {
+	for _, params := range []query.Params{
+		{"not-an-identifier": "a"},
+		{"null": nil},
+		{"record": map[string]interface{}{"x": struct{}{}}},
+	} {
+		if _, err := params.File(); err == nil {
+			t.Errorf("expected an error for %v", params)
+		}
+	}
+}
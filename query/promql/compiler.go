@@ -0,0 +1,70 @@
+package promql
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+)
+
+const CompilerType = "promql"
+
+// AddCompilerMappings adds the promql specific compiler mappings.
+func AddCompilerMappings(mappings flux.CompilerMappings) error {
+	return mappings.Add(CompilerType, func() flux.Compiler {
+		return new(Compiler)
+	})
+}
+
+// Compiler is the transpiler to convert PromQL to a Flux specification.
+// The query is evaluated at End if there is no Step. If there is no Query,
+// the compiler returns the series that match any of the Match selectors.
+type Compiler struct {
+	BucketID      string        `json:"bucketID"`
+	Query         string        `json:"query,omitempty"`
+	Match         []string      `json:"match,omitempty"`
+	Start         time.Time     `json:"start,omitempty"`
+	End           time.Time     `json:"end"`
+	Step          time.Duration `json:"step,omitempty"`
+	LookbackDelta time.Duration `json:"lookbackDelta,omitempty"`
+	Now           *time.Time    `json:"now,omitempty"`
+}
+
+var _ flux.Compiler = &Compiler{}
+
+// Compile transpiles the query into a Program.
+func (c *Compiler) Compile(ctx context.Context) (flux.Program, error) {
+	var now time.Time
+	if c.Now != nil {
+		now = *c.Now
+	} else {
+		now = time.Now()
+	}
+
+	config := Config{
+		BucketID:      c.BucketID,
+		Start:         c.Start,
+		End:           c.End,
+		Step:          c.Step,
+		LookbackDelta: c.LookbackDelta,
+	}
+	var (
+		astPkg *ast.Package
+		err    error
+	)
+	if c.Query != "" {
+		astPkg, err = Transpile(c.Query, config)
+	} else {
+		astPkg, err = TranspileSeries(c.Match, config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lang.CompileAST(astPkg, now), nil
+}
+
+func (c *Compiler) CompilerType() flux.CompilerType {
+	return CompilerType
+}
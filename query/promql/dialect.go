@@ -0,0 +1,55 @@
+package promql
+
+import (
+	"net/http"
+
+	"github.com/influxdata/flux"
+)
+
+const DialectType = "promql"
+
+// AddDialectMappings adds the promql specific dialect mappings.
+func AddDialectMappings(mappings flux.DialectMappings) error {
+	return mappings.Add(DialectType, func() flux.Dialect {
+		return new(Dialect)
+	})
+}
+
+// Dialect describes the output format of the Prometheus HTTP API.
+type Dialect struct {
+	Format ResultFormat // Format is the shape of the data in the response.
+	Label  string       // Label is the label whose values are returned by the LabelValues format.
+}
+
+func (d *Dialect) SetHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+}
+
+func (d *Dialect) Encoder() flux.MultiResultEncoder {
+	return &MultiResultEncoder{
+		Format: d.Format,
+		Label:  d.Label,
+	}
+}
+
+func (d *Dialect) DialectType() flux.DialectType {
+	return DialectType
+}
+
+// ResultFormat specifies the data of the Prometheus HTTP API response.
+type ResultFormat int
+
+const (
+	// Vector returns a single sample for each series and is the result of
+	// an instant query.
+	Vector ResultFormat = iota
+	// Matrix returns all of the samples of each series and is the result of
+	// a range query or of an instant query for a range vector.
+	Matrix
+	// Series returns the labels of each series.
+	Series
+	// Labels returns the sorted names of the labels of all series.
+	Labels
+	// LabelValues returns the sorted values of a label of all series.
+	LabelValues
+)
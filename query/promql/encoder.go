@@ -0,0 +1,292 @@
+package promql
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/iocounter"
+)
+
+// MultiResultEncoder encodes results in the JSON format of the Prometheus
+// HTTP API.
+//
+// Every table is a series whose labels are the string columns of its group
+// key, other than the time bounds. The measurement and field are replaced by
+// the __name__ label of MetricName. Tables with the same labels are the same
+// series. The samples of a series are the _time and _value columns of its
+// rows.
+//
+// The results are encoded after all of them have been read. If reading the
+// results fails, nothing is written and the error is returned.
+type MultiResultEncoder struct {
+	Format ResultFormat
+	Label  string
+}
+
+// Response is the body of a successful response.
+type Response struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
+// QueryData is the data of the response to an instant or range query.
+type QueryData struct {
+	ResultType string        `json:"resultType"`
+	Result     []interface{} `json:"result"`
+}
+
+// VectorSample is the latest sample of a series in a vector.
+type VectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  Sample            `json:"value"`
+}
+
+// MatrixSeries is all of the samples of a series in a matrix.
+type MatrixSeries struct {
+	Metric map[string]string `json:"metric"`
+	Values []Sample          `json:"values"`
+}
+
+// Sample is the time and value of a series. It is encoded as
+// [<unix seconds>, "<value>"] with the time in millisecond precision.
+type Sample struct {
+	Time  int64 // Time is in nanoseconds.
+	Value float64
+}
+
+func (s Sample) MarshalJSON() ([]byte, error) {
+	var value string
+	switch {
+	case math.IsInf(s.Value, 1):
+		value = "+Inf"
+	case math.IsInf(s.Value, -1):
+		value = "-Inf"
+	default:
+		value = strconv.FormatFloat(s.Value, 'f', -1, 64)
+	}
+	return json.Marshal([]interface{}{
+		json.Number(strconv.FormatFloat(float64(s.Time/1e6)/1e3, 'f', -1, 64)),
+		value,
+	})
+}
+
+type series struct {
+	labels  map[string]string
+	samples []Sample
+}
+
+func (e *MultiResultEncoder) Encode(w io.Writer, results flux.ResultIterator) (int64, error) {
+	defer results.Release()
+
+	var (
+		keys []string
+		set  = make(map[string]*series)
+	)
+	for results.More() {
+		if err := results.Next().Tables().Do(func(tbl flux.Table) error {
+			var samples []Sample
+			if e.Format == Vector || e.Format == Matrix {
+				var err error
+				if samples, err = readSamples(tbl); err != nil || len(samples) == 0 {
+					return err
+				}
+			} else if empty, err := isEmpty(tbl); err != nil || empty {
+				return err
+			}
+
+			labels := seriesLabels(tbl.Key())
+
+			key := labelsKey(labels)
+			s, ok := set[key]
+			if !ok {
+				s = &series{labels: labels}
+				set[key] = s
+				keys = append(keys, key)
+			}
+			s.samples = append(s.samples, samples...)
+			return nil
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if err := results.Err(); err != nil {
+		return 0, err
+	}
+
+	sort.Strings(keys)
+	all := make([]*series, 0, len(keys))
+	for _, key := range keys {
+		all = append(all, set[key])
+	}
+
+	data, err := e.data(all)
+	if err != nil {
+		return 0, err
+	}
+	wc := &iocounter.Writer{Writer: w}
+	err = json.NewEncoder(wc).Encode(Response{Status: "success", Data: data})
+	return wc.Count(), err
+}
+
+// data returns the data of the response for the format of the encoder.
+func (e *MultiResultEncoder) data(all []*series) (interface{}, error) {
+	switch e.Format {
+	case Vector:
+		result := make([]interface{}, 0, len(all))
+		for _, s := range all {
+			result = append(result, VectorSample{
+				Metric: s.labels,
+				Value:  s.samples[len(s.samples)-1],
+			})
+		}
+		return QueryData{ResultType: "vector", Result: result}, nil
+	case Matrix:
+		result := make([]interface{}, 0, len(all))
+		for _, s := range all {
+			sort.SliceStable(s.samples, func(i, j int) bool {
+				return s.samples[i].Time < s.samples[j].Time
+			})
+			result = append(result, MatrixSeries{
+				Metric: s.labels,
+				Values: s.samples,
+			})
+		}
+		return QueryData{ResultType: "matrix", Result: result}, nil
+	case Series:
+		result := make([]map[string]string, 0, len(all))
+		for _, s := range all {
+			result = append(result, s.labels)
+		}
+		return result, nil
+	case Labels, LabelValues:
+		seen := make(map[string]bool)
+		result := []string{}
+		for _, s := range all {
+			for k, v := range s.labels {
+				if e.Format == LabelValues {
+					if k != e.Label {
+						continue
+					}
+					k = v
+				}
+				if !seen[k] {
+					seen[k] = true
+					result = append(result, k)
+				}
+			}
+		}
+		sort.Strings(result)
+		return result, nil
+	default:
+		return nil, fmt.Errorf("unknown result format %d", e.Format)
+	}
+}
+
+// seriesLabels returns the labels of the series with the group key.
+func seriesLabels(key flux.GroupKey) map[string]string {
+	var measurement, field string
+	labels := make(map[string]string)
+	for j, c := range key.Cols() {
+		if c.Type != flux.TString {
+			continue
+		}
+		switch c.Label {
+		case execute.DefaultStartColLabel, execute.DefaultStopColLabel:
+		case "_measurement":
+			measurement = key.ValueString(j)
+		case "_field":
+			field = key.ValueString(j)
+		default:
+			labels[c.Label] = key.ValueString(j)
+		}
+	}
+	if measurement != "" {
+		labels["__name__"] = measurement
+		if field != "" {
+			labels["__name__"] = MetricName(measurement, field)
+		}
+	}
+	return labels
+}
+
+// labelsKey returns a string that identifies the labels.
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, k := range names {
+		b.WriteString(strconv.Quote(k))
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// readSamples reads the time and value of every row of the table with a
+// value.
+func readSamples(tbl flux.Table) ([]Sample, error) {
+	timeIdx := execute.ColIdx(execute.DefaultTimeColLabel, tbl.Cols())
+	valueIdx := execute.ColIdx(execute.DefaultValueColLabel, tbl.Cols())
+	if timeIdx < 0 || valueIdx < 0 {
+		return nil, tbl.Do(func(flux.ColReader) error { return nil })
+	}
+
+	var samples []Sample
+	err := tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			if !cr.Times(timeIdx).IsValid(i) {
+				continue
+			}
+			s := Sample{Time: cr.Times(timeIdx).Value(i)}
+			switch typ := cr.Cols()[valueIdx].Type; typ {
+			case flux.TFloat:
+				vs := cr.Floats(valueIdx)
+				if !vs.IsValid(i) {
+					continue
+				}
+				s.Value = vs.Value(i)
+			case flux.TInt:
+				vs := cr.Ints(valueIdx)
+				if !vs.IsValid(i) {
+					continue
+				}
+				s.Value = float64(vs.Value(i))
+			case flux.TUInt:
+				vs := cr.UInts(valueIdx)
+				if !vs.IsValid(i) {
+					continue
+				}
+				s.Value = float64(vs.Value(i))
+			default:
+				return fmt.Errorf("unsupported value type %s", typ)
+			}
+			samples = append(samples, s)
+		}
+		return nil
+	})
+	return samples, err
+}
+
+// isEmpty reports whether the table has no rows.
+func isEmpty(tbl flux.Table) (bool, error) {
+	empty := true
+	err := tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() > 0 {
+			empty = false
+		}
+		return nil
+	})
+	return empty, err
+}
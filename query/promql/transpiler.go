@@ -0,0 +1,542 @@
+package promql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+)
+
+// DefaultLookbackDelta is how far back the latest sample of a series is
+// looked up for each evaluation time of a query.
+const DefaultLookbackDelta = 5 * time.Minute
+
+// valueFields are the fields written by the Prometheus scraper for the
+// value of counters, gauges and untyped metrics.
+var valueFields = []string{"counter", "gauge", "value"}
+
+// Config modifies the behavior of the transpiler.
+type Config struct {
+	// BucketID is the id of the bucket that the metrics are read from.
+	BucketID string
+	// Start and End are the first and last evaluation times of a range
+	// query. An instant query is only evaluated at End. For series
+	// queries, they are the time range of the series.
+	Start, End time.Time
+	// Step is the resolution of a range query. It is zero for an instant
+	// query.
+	Step time.Duration
+	// LookbackDelta defaults to DefaultLookbackDelta.
+	LookbackDelta time.Duration
+}
+
+// MetricName returns the name of the Prometheus metric that is stored as
+// the field of the measurement. The value fields of the scraper use the
+// measurement as the name and all other fields are appended to the
+// measurement with an underscore, as the summaries and histograms of the
+// scraper are stored.
+func MetricName(measurement, field string) string {
+	for _, f := range valueFields {
+		if field == f {
+			return measurement
+		}
+	}
+	return measurement + "_" + field
+}
+
+// Transpile converts a PromQL expression into a Flux query. The query
+// returns a table for each series of the result, with a row for every
+// sample of the series.
+func Transpile(query string, config Config) (*ast.Package, error) {
+	parsed, err := ParsePromQL(query)
+	if err != nil {
+		return nil, err
+	}
+
+	t := newTranspiler(config)
+	var expr ast.Expression
+	switch q := parsed.(type) {
+	case *Selector:
+		expr, err = t.selector(q)
+	case *AggregateExpr:
+		expr, err = t.aggregate(q)
+	default:
+		err = fmt.Errorf("unsupported expression type %T", parsed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pkg(expr), nil
+}
+
+// TranspileSeries converts series selectors into a Flux query that returns
+// a table for each series that matches any of the selectors between the
+// start and end of the config. All series match if there are no selectors.
+func TranspileSeries(matches []string, config Config) (*ast.Package, error) {
+	t := newTranspiler(config)
+	var cond ast.Expression
+	for _, m := range matches {
+		parsed, err := ParsePromQL(m)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := parsed.(*Selector)
+		if !ok {
+			return nil, fmt.Errorf("match[] must be a series selector, got %q", m)
+		}
+		expr, err := predicate(s)
+		if err != nil {
+			return nil, err
+		}
+		cond = or(cond, expr)
+	}
+
+	expr := t.from(t.config.Start, t.config.End.Add(time.Nanosecond))
+	if cond != nil {
+		expr = pipe(expr, "filter", property("fn", fn("r", cond)))
+	}
+	return pkg(pipe(expr, "last")), nil
+}
+
+type transpiler struct {
+	config Config
+}
+
+func newTranspiler(config Config) *transpiler {
+	if config.LookbackDelta == 0 {
+		config.LookbackDelta = DefaultLookbackDelta
+	}
+	if config.Start.IsZero() {
+		config.Start = config.End
+	}
+	return &transpiler{config: config}
+}
+
+// from reads the bucket between start and stop.
+func (t *transpiler) from(start, stop time.Time) ast.Expression {
+	return pipe(
+		&ast.CallExpression{
+			Callee: &ast.Identifier{Name: "from"},
+			Arguments: []ast.Expression{
+				&ast.ObjectExpression{
+					Properties: []*ast.Property{
+						property("bucketID", &ast.StringLiteral{Value: t.config.BucketID}),
+					},
+				},
+			},
+		},
+		"range",
+		property("start", &ast.DateTimeLiteral{Value: start.UTC()}),
+		property("stop", &ast.DateTimeLiteral{Value: stop.UTC()}),
+	)
+}
+
+// selector returns the samples of the series that match the selector. A
+// range vector returns every sample within the range before the evaluation
+// time. An instant vector returns the latest sample within the lookback
+// delta before each evaluation time, with the evaluation time as its time.
+func (t *transpiler) selector(s *Selector) (ast.Expression, error) {
+	cond, err := predicate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Range > 0 {
+		if t.config.Step > 0 {
+			return nil, errors.New("range vectors cannot be evaluated by a range query, they must be an instant vector")
+		}
+		end := t.config.End.Add(-s.Offset)
+		expr := t.from(end.Add(-s.Range+time.Nanosecond), end.Add(time.Nanosecond))
+		expr = pipe(expr, "filter", property("fn", fn("r", cond)))
+		if s.Offset > 0 {
+			expr = pipe(expr, "timeShift",
+				property("duration", duration(s.Offset)),
+				property("columns", stringArray("_time")),
+			)
+		}
+		return expr, nil
+	}
+
+	lookback := t.config.LookbackDelta
+	if t.config.Step == 0 {
+		end := t.config.End.Add(-s.Offset)
+		expr := t.from(end.Add(-lookback+time.Nanosecond), end.Add(time.Nanosecond))
+		expr = pipe(expr, "filter", property("fn", fn("r", cond)))
+		expr = pipe(expr, "last")
+		return pipe(expr, "map", property("fn", fn("r", &ast.ObjectExpression{
+			With: &ast.Identifier{Name: "r"},
+			Properties: []*ast.Property{
+				property("_time", &ast.DateTimeLiteral{Value: t.config.End.UTC()}),
+			},
+		}))), nil
+	}
+
+	// Each evaluation time t looks back at the samples in (t - lookback, t].
+	// The windows are shifted forward by a nanosecond so that they include
+	// the sample at t and their stop is used as the time of the sample after
+	// shifting it back. The range ends a nanosecond after the last window so
+	// that the windows clipped by the end of the range are filtered out.
+	start, end := t.config.Start.Add(-s.Offset), t.config.End.Add(-s.Offset)
+	expr := t.from(start.Add(-lookback+time.Nanosecond), end.Add(2*time.Nanosecond))
+	expr = pipe(expr, "filter", property("fn", fn("r", cond)))
+
+	window := []*ast.Property{
+		property("every", duration(t.config.Step)),
+		property("period", duration(lookback)),
+	}
+	step := int64(t.config.Step)
+	if offset := (start.UnixNano() + 1) % step; offset != 0 {
+		if offset < 0 {
+			offset += step
+		}
+		window = append(window, property("offset", duration(time.Duration(offset))))
+	}
+	expr = pipe(expr, "window", window...)
+	expr = pipe(expr, "last")
+	expr = pipe(expr, "duplicate",
+		property("column", &ast.StringLiteral{Value: "_stop"}),
+		property("as", &ast.StringLiteral{Value: "_time"}),
+	)
+	expr = pipe(expr, "window", property("every", &ast.Identifier{Name: "inf"}))
+	expr = pipe(expr, "filter", property("fn", fn("r", and(
+		&ast.BinaryExpression{
+			Operator: ast.GreaterThanEqualOperator,
+			Left:     column("_time"),
+			Right:    &ast.DateTimeLiteral{Value: start.Add(time.Nanosecond).UTC()},
+		},
+		&ast.BinaryExpression{
+			Operator: ast.LessThanEqualOperator,
+			Left:     column("_time"),
+			Right:    &ast.DateTimeLiteral{Value: end.Add(time.Nanosecond).UTC()},
+		},
+	))))
+	return pipe(expr, "timeShift",
+		property("duration", duration(s.Offset-time.Nanosecond)),
+		property("columns", stringArray("_time")),
+	), nil
+}
+
+// aggregate aggregates the series of the selector at each evaluation time.
+func (t *transpiler) aggregate(a *AggregateExpr) (ast.Expression, error) {
+	if a.Selector.Range > 0 {
+		return nil, errors.New("expected an instant vector in the aggregation, got a range vector")
+	}
+	expr, err := t.selector(a.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	// The series are grouped by their labels and the evaluation time. A
+	// without clause groups by all of the labels other than the ones listed
+	// and the name of the metric.
+	mode, labels := "by", []string{}
+	if a.Aggregate != nil {
+		for _, l := range a.Aggregate.Labels {
+			labels = append(labels, l.Name)
+		}
+		if a.Aggregate.Without {
+			mode = "except"
+			labels = append(labels, "_value", "_start", "_stop", "_measurement", "_field")
+		}
+	}
+	group := func(expr ast.Expression, columns []string) ast.Expression {
+		return pipe(expr, "group",
+			property("columns", stringArray(columns...)),
+			property("mode", &ast.StringLiteral{Value: mode}),
+		)
+	}
+	if mode == "by" {
+		expr = group(expr, append([]string{"_time"}, labels...))
+	} else {
+		expr = group(expr, labels)
+	}
+
+	switch a.Op.Kind {
+	case SumKind:
+		expr = pipe(expr, "sum")
+	case MinKind:
+		expr = pipe(expr, "min")
+	case MaxKind:
+		expr = pipe(expr, "max")
+	case AvgKind:
+		expr = pipe(expr, "mean")
+	case CountKind:
+		expr = pipe(expr, "count")
+	case StdevKind, StdVarKind:
+		expr = pipe(expr, "stddev", property("mode", &ast.StringLiteral{Value: "population"}))
+		if a.Op.Kind == StdVarKind {
+			expr = pipe(expr, "map", property("fn", fn("r", &ast.ObjectExpression{
+				With: &ast.Identifier{Name: "r"},
+				Properties: []*ast.Property{
+					property("_value", &ast.BinaryExpression{
+						Operator: ast.MultiplicationOperator,
+						Left:     column("_value"),
+						Right:    column("_value"),
+					}),
+				},
+			})))
+		}
+	case QuantileKind:
+		q, ok := a.Op.Arg.(*Number)
+		if !ok {
+			return nil, errors.New("quantile requires a number parameter")
+		}
+		expr = pipe(expr, "quantile",
+			property("q", &ast.FloatLiteral{Value: q.Val}),
+			property("method", &ast.StringLiteral{Value: "exact_mean"}),
+		)
+	case TopKind, BottomKind:
+		k, ok := a.Op.Arg.(*Number)
+		if !ok {
+			return nil, errors.New("topk and bottomk require a number parameter")
+		}
+		name := "top"
+		if a.Op.Kind == BottomKind {
+			name = "bottom"
+		}
+		expr = pipe(expr, name, property("n", &ast.IntegerLiteral{Value: int64(k.Val)}))
+
+		// The selected samples keep the labels of their series.
+		return pipe(expr, "group",
+			property("columns", stringArray("_time", "_value")),
+			property("mode", &ast.StringLiteral{Value: "except"}),
+		), nil
+	case CountValuesKind:
+		return nil, errors.New("count_values is not supported")
+	default:
+		return nil, fmt.Errorf("unknown aggregation operator %d", a.Op.Kind)
+	}
+
+	if mode == "by" {
+		return group(expr, labels), nil
+	}
+	return group(expr, append([]string{"_time"}, labels...)), nil
+}
+
+// predicate returns the condition on the rows of the series that match the
+// selector.
+func predicate(s *Selector) (ast.Expression, error) {
+	cond := metricPredicate(s.Name)
+	for _, m := range s.LabelMatchers {
+		if m.Name == "__name__" {
+			return nil, errors.New("the __name__ label cannot be matched, use the metric name instead")
+		}
+
+		var value string
+		switch v := m.Value.(type) {
+		case *StringLiteral:
+			value = v.String
+		case *Number:
+			value = strconv.FormatFloat(v.Val, 'f', -1, 64)
+		default:
+			return nil, fmt.Errorf("invalid value for label %q", m.Name)
+		}
+
+		var expr ast.Expression
+		switch m.Kind {
+		case Equal, NotEqual:
+			op := ast.EqualOperator
+			if m.Kind == NotEqual {
+				op = ast.NotEqualOperator
+			}
+			expr = &ast.BinaryExpression{
+				Operator: op,
+				Left:     column(m.Name),
+				Right:    &ast.StringLiteral{Value: value},
+			}
+		case RegexMatch, RegexNoMatch:
+			// Prometheus anchors the regular expressions of label matchers.
+			re, err := regexp.Compile("^(?:" + value + ")$")
+			if err != nil {
+				return nil, err
+			}
+			op := ast.RegexpMatchOperator
+			if m.Kind == RegexNoMatch {
+				op = ast.NotRegexpMatchOperator
+			}
+			expr = &ast.BinaryExpression{
+				Operator: op,
+				Left:     column(m.Name),
+				Right:    &ast.RegexpLiteral{Value: re},
+			}
+		default:
+			return nil, fmt.Errorf("unknown label match kind %d", m.Kind)
+		}
+		cond = and(cond, expr)
+	}
+	return cond, nil
+}
+
+// metricPredicate matches the measurements and fields that are named name
+// by MetricName.
+func metricPredicate(name string) ast.Expression {
+	var fields ast.Expression
+	for _, f := range valueFields {
+		fields = or(fields, &ast.BinaryExpression{
+			Operator: ast.EqualOperator,
+			Left:     column("_field"),
+			Right:    &ast.StringLiteral{Value: f},
+		})
+	}
+	cond := and(
+		&ast.BinaryExpression{
+			Operator: ast.EqualOperator,
+			Left:     column("_measurement"),
+			Right:    &ast.StringLiteral{Value: name},
+		},
+		fields,
+	)
+
+	for i := 1; i < len(name)-1; i++ {
+		if name[i] != '_' {
+			continue
+		}
+		cond = or(cond, and(
+			&ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     column("_measurement"),
+				Right:    &ast.StringLiteral{Value: name[:i]},
+			},
+			&ast.BinaryExpression{
+				Operator: ast.EqualOperator,
+				Left:     column("_field"),
+				Right:    &ast.StringLiteral{Value: name[i+1:]},
+			},
+		))
+	}
+	return cond
+}
+
+// pkg returns the package of a query with the single expression.
+func pkg(expr ast.Expression) *ast.Package {
+	return &ast.Package{
+		Package: "main",
+		Files: []*ast.File{{
+			Package: &ast.PackageClause{
+				Name: &ast.Identifier{Name: "main"},
+			},
+			Body: []ast.Statement{
+				&ast.ExpressionStatement{Expression: expr},
+			},
+		}},
+	}
+}
+
+// pipe pipes the expression into the function call with the properties as
+// its arguments.
+func pipe(expr ast.Expression, name string, properties ...*ast.Property) ast.Expression {
+	call := &ast.CallExpression{
+		Callee: &ast.Identifier{Name: name},
+	}
+	if len(properties) > 0 {
+		call.Arguments = []ast.Expression{
+			&ast.ObjectExpression{Properties: properties},
+		}
+	}
+	return &ast.PipeExpression{
+		Argument: expr,
+		Call:     call,
+	}
+}
+
+// property creates a property with the name and value.
+func property(name string, value ast.Expression) *ast.Property {
+	return &ast.Property{
+		Key:   &ast.Identifier{Name: name},
+		Value: value,
+	}
+}
+
+// fn creates a function with a single parameter and the body.
+func fn(param string, body ast.Node) *ast.FunctionExpression {
+	return &ast.FunctionExpression{
+		Params: []*ast.Property{{
+			Key: &ast.Identifier{Name: param},
+		}},
+		Body: body,
+	}
+}
+
+// column references the column of the row r.
+func column(name string) ast.Expression {
+	var property ast.PropertyKey = &ast.StringLiteral{Value: name}
+	if strings.HasPrefix(name, "_") {
+		property = &ast.Identifier{Name: name}
+	}
+	return &ast.MemberExpression{
+		Object:   &ast.Identifier{Name: "r"},
+		Property: property,
+	}
+}
+
+// stringArray creates an array of string literals.
+func stringArray(values ...string) *ast.ArrayExpression {
+	elements := make([]ast.Expression, 0, len(values))
+	for _, v := range values {
+		elements = append(elements, &ast.StringLiteral{Value: v})
+	}
+	return &ast.ArrayExpression{Elements: elements}
+}
+
+// duration creates a duration literal. Negative durations are negated
+// duration literals.
+func duration(d time.Duration) ast.Expression {
+	if d < 0 {
+		return &ast.UnaryExpression{
+			Operator: ast.SubtractionOperator,
+			Argument: duration(-d),
+		}
+	}
+
+	var values []ast.Duration
+	for _, u := range []struct {
+		unit string
+		d    time.Duration
+	}{
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+		{"ms", time.Millisecond},
+		{"us", time.Microsecond},
+		{"ns", time.Nanosecond},
+	} {
+		if n := d / u.d; n > 0 {
+			values = append(values, ast.Duration{Magnitude: int64(n), Unit: u.unit})
+			d -= n * u.d
+		}
+	}
+	if len(values) == 0 {
+		values = append(values, ast.Duration{Magnitude: 0, Unit: "s"})
+	}
+	return &ast.DurationLiteral{Values: values}
+}
+
+// and joins the conditions with the and operator. A nil left condition
+// returns the right condition.
+func and(left, right ast.Expression) ast.Expression {
+	if left == nil {
+		return right
+	}
+	return &ast.LogicalExpression{
+		Operator: ast.AndOperator,
+		Left:     left,
+		Right:    right,
+	}
+}
+
+// or joins the conditions with the or operator. A nil left condition
+// returns the right condition.
+func or(left, right ast.Expression) ast.Expression {
+	if left == nil {
+		return right
+	}
+	return &ast.LogicalExpression{
+		Operator: ast.OrOperator,
+		Left:     left,
+		Right:    right,
+	}
+}
@@ -0,0 +1,214 @@
+package promql
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	_ "github.com/influxdata/flux/stdlib"
+)
+
+// transpilerTestData is the data of the bucket of the transpiler tests in
+// the format of the scraper.
+const transpilerTestData = `#datatype,string,long,dateTime:RFC3339,double,string,string,string
+#group,false,false,false,false,true,true,true
+#default,_result,,,,,,
+,result,table,_time,_value,_field,_measurement,job
+,,0,2010-09-15T08:50:00Z,1,counter,up,a
+,,0,2010-09-15T08:55:00Z,2,counter,up,a
+,,0,2010-09-15T08:58:00Z,3,counter,up,a
+,,1,2010-09-15T08:52:00Z,10,counter,up,b
+,,1,2010-09-15T09:00:00Z,20,counter,up,b
+,,2,2010-09-15T08:59:00Z,7,sum,req_dur,a
+`
+
+func init() {
+	flux.FinalizeBuiltIns()
+}
+
+// runTranspiled runs the transpiled query against the test data and returns
+// the response in the format.
+func runTranspiled(t *testing.T, pkg *ast.Package, format ResultFormat) string {
+	t.Helper()
+
+	src := ast.Format(pkg.Files[0])
+	src = strings.Replace(src, "package main", "", 1)
+	src = strings.Replace(src, `from(bucketID: "")`, "csv.from(csv: data)", 1)
+	src = "import \"csv\"\ndata = " + strconv.Quote(transpilerTestData) + "\n" + src
+
+	prog, err := lang.Compile(src, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error compiling %s: %v", src, err)
+	}
+	q, err := prog.Start(context.Background(), &memory.Allocator{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Done()
+
+	var buf bytes.Buffer
+	enc := &MultiResultEncoder{Format: format, Label: "job"}
+	if _, err := enc.Encode(&buf, flux.NewResultIteratorFromQuery(q)); err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+func TestTranspile(t *testing.T) {
+	end := time.Date(2010, 9, 15, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		query  string
+		config Config
+		format ResultFormat
+		want   string
+	}{
+		{
+			name:   "instant vector",
+			query:  `up`,
+			config: Config{End: end},
+			want:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"a"},"value":[1284541200,"3"]},{"metric":{"__name__":"up","job":"b"},"value":[1284541200,"20"]}]}}`,
+		},
+		{
+			name:   "label matchers",
+			query:  `req_dur_sum{job=~"a|c", job!="b"}`,
+			config: Config{End: end},
+			want:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"req_dur_sum","job":"a"},"value":[1284541200,"7"]}]}}`,
+		},
+		{
+			name:   "range vector",
+			query:  `up[5m]`,
+			config: Config{End: end},
+			format: Matrix,
+			want:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1284541080,"3"]]},{"metric":{"__name__":"up","job":"b"},"values":[[1284541200,"20"]]}]}}`,
+		},
+		{
+			name:   "range query",
+			query:  `up`,
+			config: Config{Start: end.Add(-4 * time.Minute), End: end, Step: 2 * time.Minute},
+			format: Matrix,
+			want:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1284540960,"2"],[1284541080,"3"],[1284541200,"3"]]},{"metric":{"__name__":"up","job":"b"},"values":[[1284540960,"10"],[1284541200,"20"]]}]}}`,
+		},
+		{
+			name:   "range query with offset",
+			query:  `up offset 2m`,
+			config: Config{Start: end.Add(-2 * time.Minute), End: end, Step: time.Minute},
+			format: Matrix,
+			want:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"__name__":"up","job":"a"},"values":[[1284541080,"2"],[1284541140,"2"],[1284541200,"3"]]},{"metric":{"__name__":"up","job":"b"},"values":[[1284541080,"10"]]}]}}`,
+		},
+		{
+			name:   "sum",
+			query:  `sum(up)`,
+			config: Config{Start: end.Add(-4 * time.Minute), End: end, Step: 2 * time.Minute},
+			format: Matrix,
+			want:   `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1284540960,"12"],[1284541080,"3"],[1284541200,"23"]]}]}}`,
+		},
+		{
+			name:   "count without",
+			query:  `count without (job) (up)`,
+			config: Config{End: end},
+			want:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1284541200,"2"]}]}}`,
+		},
+		{
+			name:   "topk",
+			query:  `topk(1, up)`,
+			config: Config{End: end},
+			want:   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"__name__":"up","job":"b"},"value":[1284541200,"20"]}]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := Transpile(tt.query, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := runTranspiled(t, pkg, tt.format); got != tt.want {
+				t.Errorf("unexpected response:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranspileSeries(t *testing.T) {
+	end := time.Date(2010, 9, 15, 9, 0, 0, 0, time.UTC)
+	config := Config{Start: end.Add(-time.Hour), End: end}
+	tests := []struct {
+		name   string
+		match  []string
+		format ResultFormat
+		want   string
+	}{
+		{
+			name:   "series",
+			match:  []string{`up{job="a"}`, `req_dur_sum`},
+			format: Series,
+			want:   `{"status":"success","data":[{"__name__":"req_dur_sum","job":"a"},{"__name__":"up","job":"a"}]}`,
+		},
+		{
+			name:   "labels",
+			format: Labels,
+			want:   `{"status":"success","data":["__name__","job"]}`,
+		},
+		{
+			name:   "label values",
+			match:  []string{`up`},
+			format: LabelValues,
+			want:   `{"status":"success","data":["a","b"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkg, err := TranspileSeries(tt.match, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := runTranspiled(t, pkg, tt.format); got != tt.want {
+				t.Errorf("unexpected response:\ngot  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranspile_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		config Config
+		want   string
+	}{
+		{
+			name:   "range vector in a range query",
+			query:  `up[5m]`,
+			config: Config{End: time.Unix(0, 0), Step: time.Minute},
+		},
+		{
+			name:  "aggregate of a range vector",
+			query: `sum(up[5m])`,
+		},
+		{
+			name:  "metric name matcher",
+			query: `up{__name__="down"}`,
+		},
+		{
+			name:  "count_values",
+			query: `count_values("value", up)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Transpile(tt.query, tt.config); err == nil {
+				t.Fatalf("expected an error transpiling %s", tt.query)
+			}
+		})
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/lang"
 	platform "github.com/influxdata/influxdb"
 )
 
@@ -33,12 +35,44 @@ type Request struct {
 	// Source represents the ultimate source of the request.
 	Source string `json:"source"`
 
+	// Priority is the class the query is scheduled in. It defaults to
+	// PriorityInteractive.
+	Priority Priority `json:"priority,omitempty"`
+
 	// compilerMappings maps compiler types to creation methods
 	compilerMappings flux.CompilerMappings
 
 	options []RequestHeaderOption
 }
 
+// Priority is a class of queries that the query controller queues and
+// limits separately.
+type Priority string
+
+const (
+	// PriorityInteractive is the class of the queries run by users, such as
+	// dashboard cells. It is the default.
+	PriorityInteractive Priority = "interactive"
+	// PriorityTask is the class of the queries run by tasks.
+	PriorityTask Priority = "task"
+	// PriorityBackground is the class of the queries run by the server for
+	// itself.
+	PriorityBackground Priority = "background"
+)
+
+// Priorities lists the priority classes from the most to the least urgent.
+var Priorities = []Priority{PriorityInteractive, PriorityTask, PriorityBackground}
+
+// Valid reports whether p is a known priority class.
+func (p Priority) Valid() bool {
+	for _, v := range Priorities {
+		if p == v {
+			return true
+		}
+	}
+	return false
+}
+
 // SetReturnNoContent sets the header for a Request to return no content.
 func SetReturnNoContent(header http.Header, withError bool) {
 	if withError {
@@ -139,6 +173,41 @@ func RequestFromContext(ctx context.Context) *Request {
 	return v.(*Request)
 }
 
+// QueryText returns the text of the query of the compiler. Flux ASTs are
+// formatted, and compilers with a "query" field, like those of InfluxQL
+// and PromQL, return it. Other compilers are returned as JSON.
+func QueryText(c flux.Compiler) string {
+	switch c := c.(type) {
+	case lang.FluxCompiler:
+		return c.Query
+	case *lang.FluxCompiler:
+		return c.Query
+	case lang.ASTCompiler:
+		return formatAST(c.AST)
+	case *lang.ASTCompiler:
+		return formatAST(c.AST)
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	var raw struct {
+		Query *string `json:"query"`
+	}
+	if err := json.Unmarshal(b, &raw); err == nil && raw.Query != nil {
+		return *raw.Query
+	}
+	return string(b)
+}
+
+func formatAST(pkg *ast.Package) string {
+	if pkg == nil {
+		return ""
+	}
+	return ast.Format(pkg)
+}
+
 // ProxyRequest specifies a query request and the dialect for the results.
 type ProxyRequest struct {
 	// Request is the basic query request
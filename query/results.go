@@ -0,0 +1,75 @@
+package query
+
+import (
+	"github.com/influxdata/flux"
+)
+
+// BufferedResult is a result whose tables are held in memory, such as the
+// tables decoded from a query response.
+type BufferedResult struct {
+	name   string
+	tables []flux.Table
+}
+
+// NewBufferedResult returns a result named name with the given tables.
+func NewBufferedResult(name string, tables []flux.Table) *BufferedResult {
+	return &BufferedResult{name: name, tables: tables}
+}
+
+func (r *BufferedResult) Name() string {
+	return r.name
+}
+
+func (r *BufferedResult) Tables() flux.TableIterator {
+	return r
+}
+
+// Do calls f on each table. The tables can be read once.
+func (r *BufferedResult) Do(f func(flux.Table) error) error {
+	for len(r.tables) > 0 {
+		tbl := r.tables[0]
+		r.tables = r.tables[1:]
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bufferedResultIterator iterates over results held in memory and reports
+// the error that ended them.
+type bufferedResultIterator struct {
+	results []flux.Result
+	err     error
+}
+
+// NewBufferedResultIterator returns a ResultIterator over results which
+// reports err once the results have been iterated.
+func NewBufferedResultIterator(results []flux.Result, err error) flux.ResultIterator {
+	return &bufferedResultIterator{results: results, err: err}
+}
+
+func (r *bufferedResultIterator) More() bool {
+	return len(r.results) > 0
+}
+
+func (r *bufferedResultIterator) Next() flux.Result {
+	next := r.results[0]
+	r.results = r.results[1:]
+	return next
+}
+
+func (r *bufferedResultIterator) Release() {
+	r.results = nil
+}
+
+func (r *bufferedResultIterator) Err() error {
+	if len(r.results) > 0 {
+		return nil
+	}
+	return r.err
+}
+
+func (r *bufferedResultIterator) Statistics() flux.Statistics {
+	return flux.Statistics{}
+}
@@ -0,0 +1,170 @@
+// Package slowlog records the queries that take too long or allocate too
+// much memory in the _monitoring bucket of the organization that ran them,
+// so that they can be found with a query.
+package slowlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// Measurement is the measurement of the points written for slow queries.
+const Measurement = "slow_queries"
+
+const (
+	statusTag          = "status"
+	compilerTypeTag    = "compilerType"
+	authorizationIDTag = "authorizationID"
+
+	queryField           = "query"
+	userIDField          = "userID"
+	sourceField          = "source"
+	errorField           = "error"
+	traceIDField         = "traceID"
+	totalDurationField   = "totalDuration"
+	compileDurationField = "compileDuration"
+	queueDurationField   = "queueDuration"
+	planDurationField    = "planDuration"
+	requeueDurationField = "requeueDuration"
+	executeDurationField = "executeDuration"
+	concurrencyField     = "concurrency"
+	maxAllocatedField    = "maxAllocated"
+	totalAllocatedField  = "totalAllocated"
+	responseSizeField    = "responseSize"
+)
+
+// Config selects the queries that are logged. A query is logged when it
+// exceeds any non-zero threshold.
+type Config struct {
+	// Duration is the total duration of a slow query.
+	Duration time.Duration
+	// MemoryBytes is the maximum memory allocated by a slow query.
+	MemoryBytes int64
+}
+
+// Enabled reports whether any threshold is set.
+func (c Config) Enabled() bool {
+	return c.Duration > 0 || c.MemoryBytes > 0
+}
+
+// Logger is a query.Logger that writes slow queries to the _monitoring
+// bucket of their organization.
+type Logger struct {
+	config        Config
+	pw            storage.PointsWriter
+	bucketService influxdb.BucketService
+
+	log *zap.Logger
+}
+
+var _ query.Logger = (*Logger)(nil)
+
+// NewLogger returns a Logger that writes the queries exceeding the
+// thresholds of config with pw.
+func NewLogger(log *zap.Logger, config Config, pw storage.PointsWriter, bucketService influxdb.BucketService) *Logger {
+	return &Logger{
+		config:        config,
+		pw:            pw,
+		bucketService: bucketService,
+		log:           log,
+	}
+}
+
+// Slow reports whether the statistics exceed a threshold.
+func (l *Logger) Slow(q query.Log) bool {
+	if l.config.Duration > 0 && q.Statistics.TotalDuration >= l.config.Duration {
+		return true
+	}
+	return l.config.MemoryBytes > 0 && q.Statistics.MaxAllocated >= l.config.MemoryBytes
+}
+
+// Log writes q if it is slow. Errors are also logged because the
+// query.LoggingProxyQueryService ignores them.
+func (l *Logger) Log(q query.Log) error {
+	if !l.Slow(q) || !q.OrganizationID.Valid() {
+		return nil
+	}
+	if err := l.write(context.Background(), q); err != nil {
+		l.log.Info("Failed to log slow query", zap.String("orgID", q.OrganizationID.String()), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (l *Logger) write(ctx context.Context, q query.Log) error {
+	b, err := l.bucketService.FindBucketByName(ctx, q.OrganizationID, influxdb.MonitoringSystemBucketName)
+	if err != nil {
+		return err
+	}
+
+	point, err := NewPoint(q)
+	if err != nil {
+		return err
+	}
+
+	points, err := tsdb.ExplodePoints(q.OrganizationID, b.ID, models.Points{point})
+	if err != nil {
+		return err
+	}
+	return l.pw.WritePoints(ctx, points)
+}
+
+// NewPoint returns the point that records q. The authorization token is
+// never written.
+func NewPoint(q query.Log) (models.Point, error) {
+	status := "success"
+	if q.Error != nil {
+		status = "error"
+	}
+	tags := map[string]string{
+		statusTag: status,
+	}
+
+	stats := q.Statistics
+	fields := map[string]interface{}{
+		totalDurationField:   int64(stats.TotalDuration),
+		compileDurationField: int64(stats.CompileDuration),
+		queueDurationField:   int64(stats.QueueDuration),
+		planDurationField:    int64(stats.PlanDuration),
+		requeueDurationField: int64(stats.RequeueDuration),
+		executeDurationField: int64(stats.ExecuteDuration),
+		concurrencyField:     int64(stats.Concurrency),
+		maxAllocatedField:    stats.MaxAllocated,
+		totalAllocatedField:  stats.TotalAllocated,
+		responseSizeField:    q.ResponseSize,
+	}
+	if q.Error != nil {
+		fields[errorField] = q.Error.Error()
+	}
+	if q.TraceID != "" {
+		fields[traceIDField] = q.TraceID
+	}
+
+	if pr := q.ProxyRequest; pr != nil {
+		req := pr.Request
+		if req.Compiler != nil {
+			tags[compilerTypeTag] = string(req.Compiler.CompilerType())
+			fields[queryField] = query.QueryText(req.Compiler)
+		}
+		if req.Authorization != nil {
+			tags[authorizationIDTag] = req.Authorization.ID.String()
+			fields[userIDField] = req.Authorization.UserID.String()
+		}
+		if req.Source != "" {
+			fields[sourceField] = req.Source
+		}
+	}
+
+	t := q.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return models.NewPoint(Measurement, models.NewTags(tags), fields, t.UTC())
+}
@@ -0,0 +1,192 @@
+package slowlog_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/mock"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/query/slowlog"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	orgID    = influxdb.ID(0xba55)
+	bucketID = influxdb.ID(0xb0c4)
+)
+
+func newLog(d time.Duration, maxAllocated int64, err error) query.Log {
+	return query.Log{
+		Time:           time.Unix(10, 0),
+		OrganizationID: orgID,
+		Error:          err,
+		ProxyRequest: &query.ProxyRequest{
+			Request: query.Request{
+				Authorization: &influxdb.Authorization{
+					ID:     influxdb.ID(1),
+					UserID: influxdb.ID(2),
+					Token:  "secret",
+				},
+				OrganizationID: orgID,
+				Compiler:       lang.FluxCompiler{Query: `from(bucket: "b") |> range(start: -1h)`},
+				Source:         "dashboard",
+			},
+		},
+		ResponseSize: 64,
+		Statistics: flux.Statistics{
+			TotalDuration:   d,
+			ExecuteDuration: d / 2,
+			Concurrency:     1,
+			MaxAllocated:    maxAllocated,
+		},
+	}
+}
+
+func TestLogger_Log(t *testing.T) {
+	tests := []struct {
+		name   string
+		config slowlog.Config
+		log    query.Log
+		logged bool
+	}{
+		{
+			name:   "fast",
+			config: slowlog.Config{Duration: time.Second, MemoryBytes: 1024},
+			log:    newLog(time.Millisecond, 10, nil),
+		},
+		{
+			name:   "slow",
+			config: slowlog.Config{Duration: time.Second, MemoryBytes: 1024},
+			log:    newLog(2*time.Second, 10, nil),
+			logged: true,
+		},
+		{
+			name:   "memory",
+			config: slowlog.Config{Duration: time.Second, MemoryBytes: 1024},
+			log:    newLog(time.Millisecond, 4096, nil),
+			logged: true,
+		},
+		{
+			name:   "memory threshold disabled",
+			config: slowlog.Config{Duration: time.Second},
+			log:    newLog(time.Millisecond, 4096, nil),
+		},
+		{
+			name:   "failed",
+			config: slowlog.Config{Duration: time.Second},
+			log:    newLog(time.Minute, 0, errors.New("boom")),
+			logged: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pw := &mock.PointsWriter{}
+			bs := mock.NewBucketService()
+			bs.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+				if id != orgID || name != influxdb.MonitoringSystemBucketName {
+					t.Fatalf("unexpected bucket %v/%s", id, name)
+				}
+				return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+			}
+
+			l := slowlog.NewLogger(zaptest.NewLogger(t), tt.config, pw, bs)
+			if err := l.Log(tt.log); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.logged {
+				if len(pw.Points) != 0 {
+					t.Fatalf("expected no points, got %d", len(pw.Points))
+				}
+				return
+			}
+			if len(pw.Points) == 0 {
+				t.Fatal("expected points")
+			}
+			name := tsdb.EncodeName(orgID, bucketID)
+			for _, p := range pw.Points {
+				if got, want := string(p.Name()), string(name[:]); got != want {
+					t.Fatalf("unexpected name %q, want %q", got, want)
+				}
+				if got := string(p.Tags().Get(models.MeasurementTagKeyBytes)); got != slowlog.Measurement {
+					t.Fatalf("unexpected measurement %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestLogger_Log_BucketError(t *testing.T) {
+	pw := &mock.PointsWriter{}
+	bs := mock.NewBucketService()
+	bs.FindBucketByNameFn = func(ctx context.Context, id influxdb.ID, name string) (*influxdb.Bucket, error) {
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "bucket not found"}
+	}
+
+	l := slowlog.NewLogger(zaptest.NewLogger(t), slowlog.Config{Duration: time.Second}, pw, bs)
+	if err := l.Log(newLog(time.Minute, 0, nil)); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(pw.Points) != 0 {
+		t.Fatalf("expected no points, got %d", len(pw.Points))
+	}
+}
+
+func TestNewPoint(t *testing.T) {
+	p, err := slowlog.NewPoint(newLog(2*time.Second, 4096, errors.New("boom")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := string(p.Name()); got != slowlog.Measurement {
+		t.Errorf("unexpected measurement %q", got)
+	}
+	if got, want := p.Time(), time.Unix(10, 0).UTC(); !got.Equal(want) {
+		t.Errorf("unexpected time %v, want %v", got, want)
+	}
+
+	tags := map[string]string{}
+	for _, tag := range p.Tags() {
+		tags[string(tag.Key)] = string(tag.Value)
+	}
+	wantTags := map[string]string{
+		"status":          "error",
+		"compilerType":    "flux",
+		"authorizationID": influxdb.ID(1).String(),
+	}
+	if diff := cmp.Diff(wantTags, tags); diff != "" {
+		t.Errorf("unexpected tags -want/+got:\n%s", diff)
+	}
+
+	fields, err := p.Fields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantFields := models.Fields{
+		"query":           `from(bucket: "b") |> range(start: -1h)`,
+		"userID":          influxdb.ID(2).String(),
+		"source":          "dashboard",
+		"error":           "boom",
+		"totalDuration":   int64(2 * time.Second),
+		"compileDuration": int64(0),
+		"queueDuration":   int64(0),
+		"planDuration":    int64(0),
+		"requeueDuration": int64(0),
+		"executeDuration": int64(time.Second),
+		"concurrency":     int64(1),
+		"maxAllocated":    int64(4096),
+		"totalAllocated":  int64(0),
+		"responseSize":    int64(64),
+	}
+	if diff := cmp.Diff(wantFields, fields); diff != "" {
+		t.Errorf("unexpected fields -want/+got:\n%s", diff)
+	}
+}
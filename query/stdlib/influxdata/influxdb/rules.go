@@ -14,6 +14,7 @@ func init() {
 		PushDownRangeRule{},
 		PushDownFilterRule{},
 		PushDownGroupRule{},
+		PushDownLastRule{},
 		PushDownReadTagKeysRule{},
 		PushDownReadTagValuesRule{},
 		SortedPivotRule{},
@@ -58,6 +59,34 @@ func (rule PushDownGroupRule) Rewrite(node plan.Node) (plan.Node, bool, error) {
 	}), true, nil
 }
 
+// PushDownLastRule pushes down a last operation to storage, which may serve
+// the latest value of each series without reading the rest of the range.
+type PushDownLastRule struct{}
+
+func (rule PushDownLastRule) Name() string {
+	return "PushDownLastRule"
+}
+
+// Pattern matches 'ReadRange |> last'
+func (rule PushDownLastRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.LastKind, plan.Pat(ReadRangePhysKind))
+}
+
+// Rewrite converts 'ReadRange |> last' into 'ReadLast'
+func (rule PushDownLastRule) Rewrite(node plan.Node) (plan.Node, bool, error) {
+	src := node.Predecessors()[0].ProcedureSpec().(*ReadRangePhysSpec)
+	last := node.ProcedureSpec().(*universe.LastProcedureSpec)
+
+	// Storage selects the last row by the time of the value.
+	if last.Column != execute.DefaultValueColLabel {
+		return node, false, nil
+	}
+
+	return plan.CreatePhysicalNode("ReadLast", &ReadLastPhysSpec{
+		ReadRangePhysSpec: *src.Copy().(*ReadRangePhysSpec),
+	}), true, nil
+}
+
 // PushDownRangeRule pushes down a range filter to storage
 type PushDownRangeRule struct{}
 
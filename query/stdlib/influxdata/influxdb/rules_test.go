@@ -853,6 +853,121 @@ func TestPushDownGroupRule(t *testing.T) {
 	}
 }
 
+func TestPushDownLastRule(t *testing.T) {
+	readRange := influxdb.ReadRangePhysSpec{
+		Bucket: "my-bucket",
+		Bounds: flux.Bounds{
+			Start: fluxTime(5),
+			Stop:  fluxTime(10),
+		},
+	}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name: "simple",
+			// ReadRange -> last => ReadLast
+			Rules: []plan.Rule{
+				influxdb.PushDownLastRule{},
+			},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("last", &universe.LastProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadLast", &influxdb.ReadLastPhysSpec{
+						ReadRangePhysSpec: readRange,
+					}),
+				},
+			},
+		},
+		{
+			Name: "with successor",
+			// ReadRange -> last -> count => ReadLast -> count
+			Rules: []plan.Rule{
+				influxdb.PushDownLastRule{},
+			},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("last", &universe.LastProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{}),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+				},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadLast", &influxdb.ReadLastPhysSpec{
+						ReadRangePhysSpec: readRange,
+					}),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name: "other column",
+			// ReadRange -> last(column: "host") => ReadRange -> last(column: "host")
+			Rules: []plan.Rule{
+				influxdb.PushDownLastRule{},
+			},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("last", &universe.LastProcedureSpec{
+						SelectorConfig: execute.SelectorConfig{Column: "host"},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name: "with multiple successors",
+			//
+			// last     count       last     count
+			//     \    /       =>      \    /
+			//    ReadRange            ReadRange
+			//
+			Rules: []plan.Rule{
+				influxdb.PushDownLastRule{},
+			},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("ReadRange", &readRange),
+					plan.CreatePhysicalNode("last", &universe.LastProcedureSpec{
+						SelectorConfig: execute.DefaultSelectorConfig,
+					}),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{}),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{0, 2},
+				},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
+
 func TestReadTagKeysRule(t *testing.T) {
 	fromSpec := influxdb.FromProcedureSpec{
 		Bucket: "my-bucket",
@@ -40,6 +40,10 @@ func (mockReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec, al
 	return &mockTableIterator{}, nil
 }
 
+func (mockReader) ReadLast(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	return &mockTableIterator{}, nil
+}
+
 func (mockReader) ReadTagKeys(ctx context.Context, spec influxdb.ReadTagKeysSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
 	return &mockTableIterator{}, nil
 }
@@ -132,6 +132,9 @@ type Reader interface {
 	ReadFilter(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadGroup(ctx context.Context, spec ReadGroupSpec, alloc *memory.Allocator) (TableIterator, error)
 
+	// ReadLast is ReadFilter, producing only the latest value of each series.
+	ReadLast(ctx context.Context, spec ReadFilterSpec, alloc *memory.Allocator) (TableIterator, error)
+
 	ReadTagKeys(ctx context.Context, spec ReadTagKeysSpec, alloc *memory.Allocator) (TableIterator, error)
 	ReadTagValues(ctx context.Context, spec ReadTagValuesSpec, alloc *memory.Allocator) (TableIterator, error)
 
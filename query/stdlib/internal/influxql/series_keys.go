@@ -0,0 +1,139 @@
+// Package influxql implements the functions that are only used by the
+// transpiled InfluxQL queries and are not part of the public flux packages.
+package influxql
+
+import (
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/influxdb/models"
+)
+
+const SeriesKeysKind = "seriesKeys"
+
+type SeriesKeysOpSpec struct{}
+
+func init() {
+	seriesKeysSignature := flux.FunctionSignature(nil, nil)
+
+	flux.RegisterPackageValue("internal/influxql", SeriesKeysKind, flux.FunctionValue(SeriesKeysKind, createSeriesKeysOpSpec, seriesKeysSignature))
+	flux.RegisterOpSpec(SeriesKeysKind, newSeriesKeysOp)
+	plan.RegisterProcedureSpec(SeriesKeysKind, newSeriesKeysProcedure, SeriesKeysKind)
+	execute.RegisterTransformation(SeriesKeysKind, createSeriesKeysTransformation)
+}
+
+func createSeriesKeysOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	return new(SeriesKeysOpSpec), nil
+}
+
+func newSeriesKeysOp() flux.OperationSpec {
+	return new(SeriesKeysOpSpec)
+}
+
+func (s *SeriesKeysOpSpec) Kind() flux.OperationKind {
+	return SeriesKeysKind
+}
+
+type SeriesKeysProcedureSpec struct {
+	plan.DefaultCost
+}
+
+func newSeriesKeysProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	if _, ok := qs.(*SeriesKeysOpSpec); !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	return &SeriesKeysProcedureSpec{}, nil
+}
+
+func (s *SeriesKeysProcedureSpec) Kind() plan.ProcedureKind {
+	return SeriesKeysKind
+}
+
+func (s *SeriesKeysProcedureSpec) Copy() plan.ProcedureSpec {
+	return &SeriesKeysProcedureSpec{}
+}
+
+func createSeriesKeysTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	if _, ok := spec.(*SeriesKeysProcedureSpec); !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewSeriesKeysTransformation(d, cache)
+	return t, d, nil
+}
+
+// seriesKeysTransformation writes the series key of every non-empty table
+// into the value column of a single table without a group key. The series
+// key is built from the measurement and the string columns of the group key
+// other than the field.
+type seriesKeysTransformation struct {
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+}
+
+func NewSeriesKeysTransformation(d execute.Dataset, cache execute.TableBuilderCache) *seriesKeysTransformation {
+	return &seriesKeysTransformation{
+		d:     d,
+		cache: cache,
+	}
+}
+
+func (t *seriesKeysTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *seriesKeysTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	empty := true
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		if cr.Len() > 0 {
+			empty = false
+		}
+		return nil
+	}); err != nil {
+		return err
+	} else if empty {
+		return nil
+	}
+
+	var name string
+	tags := make(map[string]string)
+	key := tbl.Key()
+	for j, c := range key.Cols() {
+		if c.Type != flux.TString {
+			continue
+		}
+		switch c.Label {
+		case "_measurement":
+			name = key.ValueString(j)
+		case "_field":
+		default:
+			tags[c.Label] = key.ValueString(j)
+		}
+	}
+
+	builder, created := t.cache.TableBuilder(execute.NewGroupKey(nil, nil))
+	if created {
+		if _, err := builder.AddCol(flux.ColMeta{Label: execute.DefaultValueColLabel, Type: flux.TString}); err != nil {
+			return err
+		}
+	}
+	return builder.AppendString(0, string(models.MakeKey([]byte(name), models.NewTags(tags))))
+}
+
+func (t *seriesKeysTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *seriesKeysTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *seriesKeysTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
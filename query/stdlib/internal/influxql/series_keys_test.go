@@ -0,0 +1,72 @@
+package influxql_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/influxdb/query/stdlib/internal/influxql"
+)
+
+func TestSeriesKeys_Process(t *testing.T) {
+	data := []flux.Table{
+		&executetest.Table{
+			KeyCols: []string{"_measurement", "_field", "region", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "region", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "cpu", "usage", "west", "server01", 2.0},
+				{execute.Time(2), "cpu", "usage", "west", "server01", 3.0},
+			},
+		},
+		&executetest.Table{
+			KeyCols: []string{"_measurement", "_field"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), "mem used", "free", 1.0},
+			},
+		},
+		&executetest.Table{
+			KeyCols: []string{"_measurement", "_field", "host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_measurement", Type: flux.TString},
+				{Label: "_field", Type: flux.TString},
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TFloat},
+			},
+			KeyValues: []interface{}{"cpu", "usage", "server02"},
+		},
+	}
+	want := []*executetest.Table{{
+		ColMeta: []flux.ColMeta{
+			{Label: "_value", Type: flux.TString},
+		},
+		Data: [][]interface{}{
+			{"cpu,host=server01,region=west"},
+			{`mem\ used`},
+		},
+	}}
+
+	executetest.ProcessTestHelper(
+		t,
+		data,
+		want,
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			return influxql.NewSeriesKeysTransformation(d, c)
+		},
+	)
+}
@@ -5,5 +5,6 @@ import (
 	_ "github.com/influxdata/influxdb/query/stdlib/experimental"
 	_ "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb"
 	_ "github.com/influxdata/influxdb/query/stdlib/influxdata/influxdb/v1"
+	_ "github.com/influxdata/influxdb/query/stdlib/internal/influxql"
 	_ "github.com/influxdata/influxdb/query/stdlib/testing"
 )
@@ -0,0 +1,86 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Roles of an instance in metadata replication.
+const (
+	// ReplicationRolePrimary accepts metadata writes and serves its changes
+	// to followers.
+	ReplicationRolePrimary = "primary"
+	// ReplicationRoleFollower applies the changes of a primary and rejects
+	// metadata writes until promoted.
+	ReplicationRoleFollower = "follower"
+)
+
+// ErrReadOnlyReplica is returned when metadata is written on a follower.
+var ErrReadOnlyReplica = &Error{
+	Code: EForbidden,
+	Msg:  "metadata is read-only on a replication follower",
+}
+
+// ErrKVChangesTruncated is returned when changes are requested from a
+// position that is no longer kept by the primary. The follower must be
+// reinitialized from a snapshot.
+var ErrKVChangesTruncated = &Error{
+	Code: EConflict,
+	Msg:  "requested changes are no longer retained; a new snapshot is required",
+}
+
+// KVOp is a single write made to the metadata store.
+type KVOp struct {
+	Bucket []byte `json:"bucket"`
+	Key    []byte `json:"key"`
+	Value  []byte `json:"value,omitempty"`
+	Delete bool   `json:"delete,omitempty"`
+}
+
+// KVChange is a committed metadata store transaction. Changes are numbered
+// by consecutive sequence numbers starting at one.
+type KVChange struct {
+	Seq  uint64    `json:"seq"`
+	Time time.Time `json:"time"`
+	Ops  []KVOp    `json:"ops"`
+}
+
+// KVChangeFilter selects the changes returned by FindKVChanges.
+type KVChangeFilter struct {
+	// After is the sequence number of the last change already seen.
+	After uint64
+	// Limit caps the number of changes returned. Zero means the default.
+	Limit int
+	// Wait is how long to wait for a change when none are available.
+	Wait time.Duration
+}
+
+// ReplicationStatus describes the replication state of an instance.
+type ReplicationStatus struct {
+	Role string `json:"role"`
+	// Seq is the sequence number of the last change committed or applied.
+	Seq uint64 `json:"seq"`
+	// UpdatedAt is when the last change was committed on the primary.
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+	// Error is the last error a follower met while replicating.
+	Error string `json:"error,omitempty"`
+}
+
+// ReplicationService exposes the change feed of the metadata store and
+// manages the replication role of the instance.
+type ReplicationService interface {
+	// ReplicationStatus returns the replication state of the instance.
+	ReplicationStatus(ctx context.Context) (*ReplicationStatus, error)
+
+	// FindKVChanges returns the changes committed after filter.After.
+	FindKVChanges(ctx context.Context, filter KVChangeFilter) ([]*KVChange, error)
+
+	// KVSnapshot writes a consistent copy of the metadata store to w,
+	// including the changes it contains.
+	KVSnapshot(ctx context.Context, w io.Writer) error
+
+	// PromoteReplica stops a follower from replicating and makes its
+	// metadata writable.
+	PromoteReplica(ctx context.Context) error
+}
@@ -0,0 +1,222 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultPollWait is how long a follower asks the primary to wait for
+	// new changes.
+	DefaultPollWait = 30 * time.Second
+
+	// DefaultRetryInterval is how long a follower waits before retrying
+	// after failing to replicate.
+	DefaultRetryInterval = 5 * time.Second
+)
+
+// Source is the primary a Follower replicates from.
+type Source interface {
+	FindKVChanges(ctx context.Context, filter influxdb.KVChangeFilter) ([]*influxdb.KVChange, error)
+	KVSnapshot(ctx context.Context, w io.Writer) error
+}
+
+// Follower applies the changes of a primary to a Store.
+type Follower struct {
+	log    *zap.Logger
+	store  *Store
+	source Source
+
+	pollWait      time.Duration
+	retryInterval time.Duration
+}
+
+// FollowerOption configures a Follower.
+type FollowerOption func(*Follower)
+
+// WithPollWait sets how long the primary is asked to wait for new changes.
+func WithPollWait(d time.Duration) FollowerOption {
+	return func(f *Follower) {
+		f.pollWait = d
+	}
+}
+
+// WithRetryInterval sets how long to wait before retrying after an error.
+func WithRetryInterval(d time.Duration) FollowerOption {
+	return func(f *Follower) {
+		f.retryInterval = d
+	}
+}
+
+// NewFollower returns a Follower replicating source into store.
+func NewFollower(log *zap.Logger, store *Store, source Source, opts ...FollowerOption) *Follower {
+	f := &Follower{
+		log:           log,
+		store:         store,
+		source:        source,
+		pollWait:      DefaultPollWait,
+		retryInterval: DefaultRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// Run replicates until ctx is done or the store is promoted. A store
+// without any changes, or one that has fallen behind the changes kept by
+// the primary, is first replaced by a snapshot of the primary.
+func (f *Follower) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if !f.store.follow(cancel) {
+		return nil
+	}
+
+	seq, err := f.store.seq(ctx)
+	if err != nil {
+		return err
+	}
+	needSnapshot := seq == 0
+
+	for ctx.Err() == nil {
+		if needSnapshot {
+			if seq, err = f.restoreSnapshot(ctx); err != nil {
+				f.retry(ctx, "Failed to restore snapshot from primary", err)
+				continue
+			}
+			needSnapshot = false
+			f.log.Info("Restored metadata snapshot from primary", zap.Uint64("seq", seq))
+		}
+
+		changes, err := f.source.FindKVChanges(ctx, influxdb.KVChangeFilter{
+			After: seq,
+			Wait:  f.pollWait,
+		})
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.EConflict {
+				f.log.Warn("Follower has fallen behind the primary's changes", zap.Uint64("seq", seq))
+				needSnapshot = true
+			}
+			f.retry(ctx, "Failed to fetch changes from primary", err)
+			continue
+		}
+
+		for _, c := range changes {
+			if err = f.store.apply(ctx, c); err != nil {
+				break
+			}
+			seq = c.Seq
+		}
+		if err != nil {
+			f.retry(ctx, "Failed to apply change from primary", err)
+			continue
+		}
+		f.store.setError(nil)
+	}
+
+	if f.store.Role() == influxdb.ReplicationRolePrimary {
+		return nil
+	}
+	return ctx.Err()
+}
+
+func (f *Follower) retry(ctx context.Context, msg string, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	f.log.Error(msg, zap.Error(err))
+	f.store.setError(err)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(f.retryInterval):
+	}
+}
+
+// restoreSnapshot replaces the contents of the store with a snapshot of
+// the primary and returns the sequence number of its last change.
+func (f *Follower) restoreSnapshot(ctx context.Context) (uint64, error) {
+	tmp, err := ioutil.TempFile("", "influxd-replication-snapshot")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := f.source.KVSnapshot(ctx, tmp); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	db, err := bolt.Open(tmp.Name(), 0600, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if err != nil {
+		return 0, fmt.Errorf("unable to open snapshot: %v", err)
+	}
+	defer db.Close()
+
+	err = db.View(func(snap *bolt.Tx) error {
+		return f.store.store.Update(ctx, func(tx kv.Tx) error {
+			return snap.ForEach(func(name []byte, sb *bolt.Bucket) error {
+				return restoreBucket(tx, name, sb)
+			})
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	f.store.notify()
+
+	return f.store.seq(ctx)
+}
+
+// restoreBucket makes the bucket name of tx hold exactly the keys of sb.
+func restoreBucket(tx kv.Tx, name []byte, sb *bolt.Bucket) error {
+	b, err := tx.Bucket(name)
+	if err != nil {
+		return err
+	}
+
+	cur, err := b.ForwardCursor(nil)
+	if err != nil {
+		return err
+	}
+	var stale [][]byte
+	for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+		if sb.Get(k) == nil {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return err
+	}
+	if err := cur.Close(); err != nil {
+		return err
+	}
+
+	for _, k := range stale {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	return sb.ForEach(func(k, v []byte) error {
+		if v == nil {
+			// nested buckets are not used by the kv store.
+			return nil
+		}
+		return b.Put(k, v)
+	})
+}
@@ -0,0 +1,521 @@
+// Package replication replicates the metadata kv.Store of a primary influxd
+// to followers.
+//
+// A Store records every write transaction committed through it as a
+// numbered influxdb.KVChange, kept in the store itself and committed in the
+// same transaction as the writes. A Follower tails the changes of a primary
+// and applies them to its own Store, which rejects all other writes until
+// it is promoted.
+package replication
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kv"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultMaxChanges is the number of changes kept for followers.
+	DefaultMaxChanges = 100000
+
+	// DefaultChangesLimit is the number of changes returned by
+	// FindKVChanges when no limit is given.
+	DefaultChangesLimit = 1000
+)
+
+var changesBucket = []byte("replicationchangesv1")
+
+var (
+	_ kv.Store                    = (*Store)(nil)
+	_ influxdb.ReplicationService = (*Store)(nil)
+)
+
+// Store is a kv.Store that records the writes made through it as a feed of
+// changes.
+type Store struct {
+	store      kv.Store
+	log        *zap.Logger
+	maxChanges uint64
+	now        func() time.Time
+
+	mu            sync.Mutex
+	role          string
+	stopFollowing func()
+	lastErr       string
+	changed       chan struct{}
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithMaxChanges sets the number of changes kept for followers. Followers
+// that fall further behind must be reinitialized from a snapshot.
+func WithMaxChanges(n uint64) Option {
+	return func(s *Store) {
+		s.maxChanges = n
+	}
+}
+
+// WithFollower starts the Store as a follower, rejecting writes until it
+// is promoted.
+func WithFollower() Option {
+	return func(s *Store) {
+		s.role = influxdb.ReplicationRoleFollower
+	}
+}
+
+// NewStore returns a Store recording the changes made to store.
+func NewStore(log *zap.Logger, store kv.Store, opts ...Option) *Store {
+	s := &Store{
+		store:      store,
+		log:        log,
+		maxChanges: DefaultMaxChanges,
+		now:        time.Now,
+		role:       influxdb.ReplicationRolePrimary,
+		changed:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Initialize creates the bucket holding the changes.
+func (s *Store) Initialize(ctx context.Context) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		_, err := tx.Bucket(changesBucket)
+		return err
+	})
+}
+
+// View opens up a read-only transaction.
+func (s *Store) View(ctx context.Context, fn func(kv.Tx) error) error {
+	return s.store.View(ctx, fn)
+}
+
+// Update opens up a transaction that will mutate data. The writes made by
+// fn are recorded as a single change.
+//
+// On a follower, writes that would change the store fail with
+// influxdb.ErrReadOnlyReplica, so that idempotent initialization such as
+// kv.Service.Initialize still succeeds. A follower that has not yet
+// replicated anything accepts writes without recording them, as they are
+// replaced by the first snapshot of the primary.
+func (s *Store) Update(ctx context.Context, fn func(kv.Tx) error) error {
+	follower := s.Role() == influxdb.ReplicationRoleFollower
+
+	var recorded bool
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		rtx := &recordingTx{Tx: tx}
+		if follower {
+			seq, err := lastSeq(tx)
+			if err != nil {
+				return err
+			}
+			if seq == 0 {
+				return fn(tx)
+			}
+			rtx.readOnly = true
+		}
+
+		if err := fn(rtx); err != nil {
+			return err
+		}
+		if len(rtx.ops) == 0 {
+			return nil
+		}
+
+		recorded = true
+		return s.appendChange(tx, &influxdb.KVChange{
+			Time: s.now().UTC(),
+			Ops:  rtx.ops,
+		})
+	})
+	if err == nil && recorded {
+		s.notify()
+	}
+	return err
+}
+
+// Backup copies all K:Vs to a writer, in the format of the underlying store.
+func (s *Store) Backup(ctx context.Context, w io.Writer) error {
+	return s.store.Backup(ctx, w)
+}
+
+// Role returns the current replication role of the store.
+func (s *Store) Role() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.role
+}
+
+// ReplicationStatus returns the replication state of the store.
+func (s *Store) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	s.mu.Lock()
+	st := &influxdb.ReplicationStatus{
+		Role:  s.role,
+		Error: s.lastErr,
+	}
+	s.mu.Unlock()
+
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(changesBucket)
+		if err != nil {
+			return err
+		}
+		c, err := lastChange(b)
+		if err != nil || c == nil {
+			return err
+		}
+		st.Seq = c.Seq
+		st.UpdatedAt = c.Time
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// FindKVChanges returns up to filter.Limit changes committed after
+// filter.After, waiting up to filter.Wait for one to be committed if
+// there are none.
+func (s *Store) FindKVChanges(ctx context.Context, filter influxdb.KVChangeFilter) ([]*influxdb.KVChange, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultChangesLimit
+	}
+
+	var timeout <-chan time.Time
+	if filter.Wait > 0 {
+		timer := time.NewTimer(filter.Wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		// The channel is taken before reading so that a change committed
+		// in between is not missed.
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+
+		changes, err := s.findKVChanges(ctx, filter.After, limit)
+		if err != nil || len(changes) > 0 || timeout == nil {
+			return changes, err
+		}
+
+		select {
+		case <-changed:
+		case <-timeout:
+			return changes, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (s *Store) findKVChanges(ctx context.Context, after uint64, limit int) ([]*influxdb.KVChange, error) {
+	changes := []*influxdb.KVChange{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket(changesBucket)
+		if err != nil {
+			return err
+		}
+		cur, err := b.Cursor()
+		if err != nil {
+			return err
+		}
+
+		k, _ := cur.Last()
+		if k == nil {
+			if after > 0 {
+				return errAhead(after, 0)
+			}
+			return nil
+		}
+		if last := decodeSeq(k); after > last {
+			return errAhead(after, last)
+		}
+
+		if k, _ := cur.First(); decodeSeq(k) > after+1 {
+			return influxdb.ErrKVChangesTruncated
+		}
+
+		for k, v := cur.Seek(encodeSeq(after + 1)); k != nil && len(changes) < limit; k, v = cur.Next() {
+			c := &influxdb.KVChange{}
+			if err := json.Unmarshal(v, c); err != nil {
+				return &influxdb.Error{
+					Code: influxdb.EInternal,
+					Msg:  fmt.Sprintf("unable to decode change %d", decodeSeq(k)),
+					Err:  err,
+				}
+			}
+			changes = append(changes, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func errAhead(after, last uint64) error {
+	return &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  fmt.Sprintf("change %d is ahead of the last change %d", after, last),
+	}
+}
+
+// KVSnapshot writes a consistent copy of the store, including its changes.
+func (s *Store) KVSnapshot(ctx context.Context, w io.Writer) error {
+	return s.store.Backup(ctx, w)
+}
+
+// PromoteReplica stops the follower replicating into the store and makes
+// the store writable. Promoting a primary has no effect.
+func (s *Store) PromoteReplica(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.role == influxdb.ReplicationRolePrimary {
+		return nil
+	}
+	if s.stopFollowing != nil {
+		s.stopFollowing()
+		s.stopFollowing = nil
+	}
+	s.role = influxdb.ReplicationRolePrimary
+	s.lastErr = ""
+	s.log.Info("Promoted metadata replica to primary")
+	return nil
+}
+
+// follow registers the function stopping the follower replicating into
+// the store. It returns false if the store has already been promoted.
+func (s *Store) follow(stop func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.role != influxdb.ReplicationRoleFollower {
+		return false
+	}
+	s.stopFollowing = stop
+	return true
+}
+
+func (s *Store) setError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastErr = ""
+	if err != nil {
+		s.lastErr = err.Error()
+	}
+}
+
+// seq returns the sequence number of the last change in the store.
+func (s *Store) seq(ctx context.Context) (uint64, error) {
+	var seq uint64
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		seq, err = lastSeq(tx)
+		return err
+	})
+	return seq, err
+}
+
+// apply writes a change received from the primary, keeping its sequence
+// number.
+func (s *Store) apply(ctx context.Context, c *influxdb.KVChange) error {
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		for _, op := range c.Ops {
+			b, err := tx.Bucket(op.Bucket)
+			if err != nil {
+				return err
+			}
+			if op.Delete {
+				err = b.Delete(op.Key)
+			} else {
+				err = b.Put(op.Key, op.Value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return s.appendChange(tx, c)
+	})
+	if err == nil {
+		s.notify()
+	}
+	return err
+}
+
+// appendChange stores c, numbering it if it has no sequence number, and
+// removes the changes that are no longer retained.
+func (s *Store) appendChange(tx kv.Tx, c *influxdb.KVChange) error {
+	b, err := tx.Bucket(changesBucket)
+	if err != nil {
+		return err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return err
+	}
+
+	var last uint64
+	if k, _ := cur.Last(); k != nil {
+		last = decodeSeq(k)
+	}
+	if c.Seq == 0 {
+		c.Seq = last + 1
+	} else if c.Seq != last+1 {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("change %d does not follow the last change %d", c.Seq, last),
+		}
+	}
+
+	v, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(encodeSeq(c.Seq), v); err != nil {
+		return err
+	}
+
+	if s.maxChanges == 0 || c.Seq <= s.maxChanges {
+		return nil
+	}
+
+	// Keys are collected first as not every store allows deleting while
+	// iterating.
+	cutoff := c.Seq - s.maxChanges
+	var expired [][]byte
+	for k, _ := cur.First(); k != nil && decodeSeq(k) <= cutoff; k, _ = cur.Next() {
+		expired = append(expired, append([]byte(nil), k...))
+	}
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func lastSeq(tx kv.Tx) (uint64, error) {
+	b, err := tx.Bucket(changesBucket)
+	if err != nil {
+		return 0, err
+	}
+	cur, err := b.Cursor()
+	if err != nil {
+		return 0, err
+	}
+	k, _ := cur.Last()
+	return decodeSeq(k), nil
+}
+
+func lastChange(b kv.Bucket) (*influxdb.KVChange, error) {
+	cur, err := b.Cursor()
+	if err != nil {
+		return nil, err
+	}
+	k, v := cur.Last()
+	if k == nil {
+		return nil, nil
+	}
+	c := &influxdb.KVChange{}
+	if err := json.Unmarshal(v, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func encodeSeq(seq uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	return b
+}
+
+func decodeSeq(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+// recordingTx records the writes made to the buckets it returns.
+type recordingTx struct {
+	kv.Tx
+	readOnly bool
+	ops      []influxdb.KVOp
+}
+
+func (tx *recordingTx) Bucket(name []byte) (kv.Bucket, error) {
+	b, err := tx.Tx.Bucket(name)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingBucket{Bucket: b, tx: tx, name: append([]byte(nil), name...)}, nil
+}
+
+type recordingBucket struct {
+	kv.Bucket
+	tx   *recordingTx
+	name []byte
+}
+
+func (b *recordingBucket) Put(key, value []byte) error {
+	if b.tx.readOnly {
+		v, err := b.Bucket.Get(key)
+		if err == nil && bytes.Equal(v, value) {
+			return nil
+		}
+		return influxdb.ErrReadOnlyReplica
+	}
+	if err := b.Bucket.Put(key, value); err != nil {
+		return err
+	}
+	b.tx.ops = append(b.tx.ops, influxdb.KVOp{
+		Bucket: b.name,
+		Key:    append([]byte(nil), key...),
+		Value:  append([]byte(nil), value...),
+	})
+	return nil
+}
+
+func (b *recordingBucket) Delete(key []byte) error {
+	if b.tx.readOnly {
+		if _, err := b.Bucket.Get(key); kv.IsNotFound(err) {
+			return nil
+		}
+		return influxdb.ErrReadOnlyReplica
+	}
+	if err := b.Bucket.Delete(key); err != nil {
+		return err
+	}
+	b.tx.ops = append(b.tx.ops, influxdb.KVOp{
+		Bucket: b.name,
+		Key:    append([]byte(nil), key...),
+		Delete: true,
+	})
+	return nil
+}
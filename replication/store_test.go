@@ -0,0 +1,322 @@
+package replication_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/bolt"
+	"github.com/influxdata/influxdb/kv"
+	"github.com/influxdata/influxdb/replication"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestStore(t *testing.T, opts ...replication.Option) (*replication.Store, func()) {
+	t.Helper()
+
+	b, closeBolt := newTestBoltStore(t)
+	s := replication.NewStore(zaptest.NewLogger(t), b, opts...)
+	if err := s.Initialize(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s, closeBolt
+}
+
+func newTestBoltStore(t *testing.T) (*bolt.KVStore, func()) {
+	t.Helper()
+
+	f, err := ioutil.TempFile("", "influxdata-bolt-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	path := f.Name()
+	b := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	if err := b.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return b, func() {
+		b.Close()
+		os.Remove(path)
+	}
+}
+
+func put(ctx context.Context, s kv.Store, bucket, key, value string) error {
+	return s.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), []byte(value))
+	})
+}
+
+func get(ctx context.Context, s kv.Store, bucket, key string) (string, error) {
+	var v []byte
+	err := s.View(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		v, err = b.Get([]byte(key))
+		return err
+	})
+	return string(v), err
+}
+
+func TestStore_FindKVChanges(t *testing.T) {
+	ctx := context.Background()
+	s, closeStore := newTestStore(t)
+	defer closeStore()
+
+	if err := put(ctx, s, "b", "k1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	err := s.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("b"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte("k2"), []byte("v2")); err != nil {
+			return err
+		}
+		return b.Delete([]byte("k1"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither failed nor read-only transactions are recorded.
+	boom := errors.New("boom")
+	err = s.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("b"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put([]byte("k3"), []byte("v3")); err != nil {
+			return err
+		}
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.Update(ctx, func(tx kv.Tx) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := s.FindKVChanges(ctx, influxdb.KVChangeFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d", len(changes))
+	}
+	if c := changes[0]; c.Seq != 1 || len(c.Ops) != 1 || string(c.Ops[0].Key) != "k1" || string(c.Ops[0].Value) != "v1" {
+		t.Fatalf("unexpected first change: %+v", c)
+	}
+	if c := changes[1]; c.Seq != 2 || len(c.Ops) != 2 || !c.Ops[1].Delete || string(c.Ops[1].Key) != "k1" {
+		t.Fatalf("unexpected second change: %+v", c)
+	}
+
+	changes, err = s.FindKVChanges(ctx, influxdb.KVChangeFilter{After: 1, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Seq != 2 {
+		t.Fatalf("unexpected changes after 1: %+v", changes)
+	}
+
+	if _, err := s.FindKVChanges(ctx, influxdb.KVChangeFilter{After: 3}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected %q for a position ahead of the store, got: %v", influxdb.EInvalid, err)
+	}
+
+	st, err := s.ReplicationStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Role != influxdb.ReplicationRolePrimary || st.Seq != 2 {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+}
+
+func TestStore_MaxChanges(t *testing.T) {
+	ctx := context.Background()
+	s, closeStore := newTestStore(t, replication.WithMaxChanges(2))
+	defer closeStore()
+
+	for _, k := range []string{"k1", "k2", "k3"} {
+		if err := put(ctx, s, "b", k, "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.FindKVChanges(ctx, influxdb.KVChangeFilter{}); err != influxdb.ErrKVChangesTruncated {
+		t.Fatalf("expected truncated changes, got: %v", err)
+	}
+
+	changes, err := s.FindKVChanges(ctx, influxdb.KVChangeFilter{After: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].Seq != 2 {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+}
+
+func TestStore_FindKVChangesWait(t *testing.T) {
+	ctx := context.Background()
+	s, closeStore := newTestStore(t)
+	defer closeStore()
+
+	changes, err := s.FindKVChanges(ctx, influxdb.KVChangeFilter{Wait: 10 * time.Millisecond})
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes after waiting, got %d: %v", len(changes), err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		if err := put(ctx, s, "b", "k", "v"); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	changes, err = s.FindKVChanges(ctx, influxdb.KVChangeFilter{Wait: 10 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected the change committed while waiting, got %d", len(changes))
+	}
+}
+
+func TestFollower(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	primary, closePrimary := newTestStore(t)
+	defer closePrimary()
+	follower, closeFollower := newTestStore(t, replication.WithFollower())
+	defer closeFollower()
+
+	primarySvc := kv.NewService(zaptest.NewLogger(t), primary)
+	if err := primarySvc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+	followerSvc := kv.NewService(zaptest.NewLogger(t), follower)
+	if err := followerSvc.Initialize(ctx); err != nil {
+		t.Fatalf("unable to initialize a follower: %v", err)
+	}
+
+	// Written before the follower starts, so it is restored from a snapshot.
+	u1 := &influxdb.User{Name: "u1"}
+	if err := primarySvc.CreateUser(ctx, u1); err != nil {
+		t.Fatal(err)
+	}
+
+	f := replication.NewFollower(zaptest.NewLogger(t), follower, primary,
+		replication.WithPollWait(100*time.Millisecond),
+		replication.WithRetryInterval(10*time.Millisecond),
+	)
+	done := make(chan error, 1)
+	go func() { done <- f.Run(ctx) }()
+
+	u2 := &influxdb.User{Name: "u2"}
+	if err := primarySvc.CreateUser(ctx, u2); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, u := range []*influxdb.User{u1, u2} {
+		waitFor(t, ctx, func() bool {
+			_, err := followerSvc.FindUserByID(ctx, u.ID)
+			return err == nil
+		})
+	}
+
+	if err := followerSvc.CreateUser(ctx, &influxdb.User{Name: "u3"}); influxdb.ErrorCode(err) != influxdb.EForbidden {
+		t.Fatalf("expected writes to a follower to be forbidden, got: %v", err)
+	}
+
+	if err := follower.PromoteReplica(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error from a promoted follower: %v", err)
+	}
+
+	if err := followerSvc.CreateUser(ctx, &influxdb.User{Name: "u3"}); err != nil {
+		t.Fatalf("unexpected error writing to a promoted follower: %v", err)
+	}
+
+	pst, err := primary.ReplicationStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fst, err := follower.ReplicationStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fst.Role != influxdb.ReplicationRolePrimary || fst.Seq != pst.Seq+1 {
+		t.Fatalf("expected the promoted follower to continue the primary's changes: primary %+v, follower %+v", pst, fst)
+	}
+}
+
+func TestFollower_Snapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	primary, closePrimary := newTestStore(t, replication.WithMaxChanges(1))
+	defer closePrimary()
+	b, closeFollower := newTestBoltStore(t)
+	defer closeFollower()
+
+	// A key the primary doesn't have is removed by the snapshot.
+	if err := put(ctx, b, "b", "stale", "v"); err != nil {
+		t.Fatal(err)
+	}
+	follower := replication.NewStore(zaptest.NewLogger(t), b, replication.WithFollower())
+	if err := follower.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := put(ctx, primary, "b", "k1", "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := put(ctx, primary, "b", "k2", "v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	f := replication.NewFollower(zaptest.NewLogger(t), follower, primary,
+		replication.WithPollWait(100*time.Millisecond),
+		replication.WithRetryInterval(10*time.Millisecond),
+	)
+	go f.Run(ctx)
+
+	waitFor(t, ctx, func() bool {
+		v, err := get(ctx, follower, "b", "k2")
+		return err == nil && v == "v2"
+	})
+	if v, err := get(ctx, follower, "b", "k1"); err != nil || v != "v1" {
+		t.Fatalf("expected k1 from the snapshot, got %q: %v", v, err)
+	}
+	if _, err := get(ctx, follower, "b", "stale"); !kv.IsNotFound(err) {
+		t.Fatalf("expected the stale key to be removed, got: %v", err)
+	}
+}
+
+func waitFor(t *testing.T, ctx context.Context, fn func() bool) {
+	t.Helper()
+	for !fn() {
+		select {
+		case <-ctx.Done():
+			t.Fatal("timed out")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
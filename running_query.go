@@ -0,0 +1,43 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// RunningQuery is a query that is being compiled, queued or executed.
+type RunningQuery struct {
+	// ID identifies the query while it runs. IDs are not persisted and may
+	// be reused after a restart.
+	ID              ID        `json:"id"`
+	OrgID           ID        `json:"orgID,omitempty"`
+	UserID          ID        `json:"userID,omitempty"`
+	AuthorizationID ID        `json:"authorizationID,omitempty"`
+	State           string    `json:"state"`
+	CompilerType    string    `json:"compilerType"`
+	Query           string    `json:"query"`
+	Source          string    `json:"source,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Elapsed         Duration  `json:"elapsed"`
+	// MemoryBytes is the memory allocated by the query for its tables.
+	MemoryBytes int64 `json:"memoryBytes"`
+}
+
+// RunningQueryFilter selects running queries.
+type RunningQueryFilter struct {
+	OrgID *ID
+}
+
+// RunningQueryService lists and cancels the queries running in a server.
+type RunningQueryService interface {
+	// FindRunningQueryByID returns a single running query by ID.
+	FindRunningQueryByID(ctx context.Context, id ID) (*RunningQuery, error)
+
+	// FindRunningQueries returns the running queries matching filter,
+	// oldest first.
+	FindRunningQueries(ctx context.Context, filter RunningQueryFilter) ([]*RunningQuery, error)
+
+	// CancelRunningQuery stops a running query. The client that runs it
+	// receives an error.
+	CancelRunningQuery(ctx context.Context, id ID) error
+}
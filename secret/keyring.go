@@ -0,0 +1,218 @@
+// Package secret provides the encryption used to protect secrets stored
+// outside of a dedicated secret manager such as Vault.
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// KeySize is the size in bytes of the AES-256 keys held by a Keyring.
+	KeySize = 32
+
+	// ciphertextVersion identifies the layout of ciphertexts produced by a Keyring.
+	ciphertextVersion byte = 1
+
+	maxKeyIDLength = 255
+)
+
+var (
+	// ErrUnknownKey is returned when a ciphertext was encrypted with a key
+	// that is not in the keyring.
+	ErrUnknownKey = errors.New("secret was encrypted with a key that is not in the keyring")
+
+	// ErrMalformedCiphertext is returned when a ciphertext cannot be parsed.
+	ErrMalformedCiphertext = errors.New("malformed secret ciphertext")
+)
+
+// Keyring encrypts values with AES-GCM using its active key and decrypts
+// values encrypted with any of its keys. Keeping retired keys in the
+// keyring allows the active key to be rotated without losing access to
+// values encrypted before the rotation.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// Key is a named encryption key.
+type Key struct {
+	ID  string
+	Key []byte
+}
+
+// NewKeyring returns a Keyring holding keys. The first key is the active
+// key used for encryption.
+func NewKeyring(keys ...Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring requires at least one key")
+	}
+
+	kr := &Keyring{
+		active: keys[0].ID,
+		keys:   make(map[string]cipher.AEAD, len(keys)),
+	}
+	for _, k := range keys {
+		if k.ID == "" || len(k.ID) > maxKeyIDLength || strings.ContainsAny(k.ID, ":, \t\r\n") {
+			return nil, fmt.Errorf("invalid key id %q", k.ID)
+		}
+		if _, ok := kr.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		if len(k.Key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", k.ID, KeySize, len(k.Key))
+		}
+
+		block, err := aes.NewCipher(k.Key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		kr.keys[k.ID] = aead
+	}
+	return kr, nil
+}
+
+// ParseKeyring parses keys written as "id:base64-key" entries, separated by
+// newlines or commas. Blank lines and lines starting with '#' are ignored.
+// The first key is the active key.
+func ParseKeyring(r io.Reader) (*Keyring, error) {
+	var keys []Key
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, entry := range strings.Split(line, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			parts := strings.SplitN(entry, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("key entry must have the form id:base64-key")
+			}
+			key, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("key %q is not valid base64: %v", parts[0], err)
+			}
+			keys = append(keys, Key{ID: parts[0], Key: key})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewKeyring(keys...)
+}
+
+// LoadKeyringFile parses the keyring stored in the file at path.
+func LoadKeyringFile(path string) (*Keyring, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyring(bytes.NewReader(b))
+}
+
+// LoadKeyring returns the keyring in the file at path or, if path is
+// empty, the keyring parsed from keys. It returns nil if neither is set.
+func LoadKeyring(path, keys string) (*Keyring, error) {
+	switch {
+	case path != "" && keys != "":
+		return nil, errors.New("only one of a key file and keys may be given")
+	case path != "":
+		return LoadKeyringFile(path)
+	case keys != "":
+		return ParseKeyring(strings.NewReader(keys))
+	}
+	return nil, nil
+}
+
+// GenerateKey returns a random key with the given id, formatted as a
+// keyring entry.
+func GenerateKey(id string) (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	if _, err := NewKeyring(Key{ID: id, Key: key}); err != nil {
+		return "", err
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ActiveKeyID returns the id of the key used for encryption.
+func (kr *Keyring) ActiveKeyID() string {
+	return kr.active
+}
+
+// Encrypt seals plaintext with the active key. The additional data is
+// authenticated but not stored, so the same value must be given to Decrypt.
+func (kr *Keyring) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	aead := kr.keys[kr.active]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// version | key id length | key id | nonce | sealed plaintext
+	out := make([]byte, 0, 2+len(kr.active)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(out, ciphertextVersion, byte(len(kr.active)))
+	out = append(out, kr.active...)
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt with any key of the keyring.
+func (kr *Keyring) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	id, body, err := splitCiphertext(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, ok := kr.keys[id]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if len(body) < aead.NonceSize() {
+		return nil, ErrMalformedCiphertext
+	}
+
+	nonce, sealed := body[:aead.NonceSize()], body[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
+
+// KeyID returns the id of the key a ciphertext was encrypted with.
+func KeyID(ciphertext []byte) (string, error) {
+	id, _, err := splitCiphertext(ciphertext)
+	return id, err
+}
+
+func splitCiphertext(ciphertext []byte) (string, []byte, error) {
+	if len(ciphertext) < 2 || ciphertext[0] != ciphertextVersion {
+		return "", nil, ErrMalformedCiphertext
+	}
+	n := int(ciphertext[1])
+	if n == 0 || len(ciphertext) < 2+n {
+		return "", nil, ErrMalformedCiphertext
+	}
+	return string(ciphertext[2 : 2+n]), ciphertext[2+n:], nil
+}
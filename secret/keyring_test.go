@@ -0,0 +1,121 @@
+package secret_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/secret"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, secret.KeySize))
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	kr, err := secret.ParseKeyring(strings.NewReader("k1:" + testKey(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ct, err := kr.Encrypt([]byte("hunter2"), []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(ct, []byte("hunter2")) {
+		t.Fatal("ciphertext contains the plaintext")
+	}
+	if id, err := secret.KeyID(ct); err != nil || id != "k1" {
+		t.Fatalf("unexpected key id %q: %v", id, err)
+	}
+
+	pt, err := kr.Decrypt(ct, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(pt), "hunter2"; got != want {
+		t.Fatalf("unexpected plaintext: got %q, want %q", got, want)
+	}
+
+	if _, err := kr.Decrypt(ct, []byte("other key")); err == nil {
+		t.Fatal("expected an error decrypting with different additional data")
+	}
+
+	ct[len(ct)-1] ^= 0xff
+	if _, err := kr.Decrypt(ct, []byte("key")); err == nil {
+		t.Fatal("expected an error decrypting a modified ciphertext")
+	}
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	old, err := secret.ParseKeyring(strings.NewReader("k1:" + testKey(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := old.Encrypt([]byte("value"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, err := secret.ParseKeyring(strings.NewReader(`
+# the first key is the active key
+k2:` + testKey(2) + `
+k1:` + testKey(1) + `
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rotated.ActiveKeyID(); got != "k2" {
+		t.Fatalf("unexpected active key: %q", got)
+	}
+
+	pt, err := rotated.Decrypt(ct, nil)
+	if err != nil {
+		t.Fatalf("unable to decrypt with a retired key: %v", err)
+	}
+	if string(pt) != "value" {
+		t.Fatalf("unexpected plaintext: %q", pt)
+	}
+
+	ct, err = rotated.Encrypt(pt, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := secret.KeyID(ct); id != "k2" {
+		t.Fatalf("expected encryption with the active key, got %q", id)
+	}
+	if _, err := old.Decrypt(ct, nil); err != secret.ErrUnknownKey {
+		t.Fatalf("expected ErrUnknownKey, got: %v", err)
+	}
+}
+
+func TestParseKeyring_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "empty", in: "# no keys\n"},
+		{name: "missing id", in: testKey(1)},
+		{name: "bad base64", in: "k1:not base64"},
+		{name: "short key", in: "k1:" + base64.StdEncoding.EncodeToString([]byte("short"))},
+		{name: "duplicate id", in: "k1:" + testKey(1) + ",k1:" + testKey(2)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := secret.ParseKeyring(strings.NewReader(tt.in)); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	k, err := secret.GenerateKey("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := secret.ParseKeyring(strings.NewReader(k)); err != nil {
+		t.Fatalf("generated key does not parse: %v", err)
+	}
+}
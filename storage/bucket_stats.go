@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	platform "github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+var _ platform.BucketStatsService = (*Engine)(nil)
+
+// BucketStats returns the stats of the data stored for bucketID. The stats of
+// each TSM file are kept until the file changes and series are counted from
+// the series sets of the index, so no call scans all of the bucket's series.
+func (e *Engine) BucketStats(ctx context.Context, orgID, bucketID platform.ID) (*platform.BucketStats, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	name := encoded[:]
+
+	ts, err := e.engine.BucketStats(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the series of each measurement, which is the value of the
+	// measurement tag key of the series.
+	seriesN := make(map[string]int64)
+	itr, err := e.index.TagValueIterator(name, models.MeasurementTagKeyBytes)
+	if err != nil {
+		return nil, err
+	} else if itr != nil {
+		defer itr.Close()
+		for {
+			v, err := itr.Next()
+			if err != nil {
+				return nil, err
+			} else if v == nil {
+				break
+			}
+
+			n, err := e.index.TagValueCardinality(name, models.MeasurementTagKeyBytes, v)
+			if err != nil {
+				return nil, err
+			} else if n > 0 {
+				seriesN[string(v)] = n
+			}
+		}
+	}
+
+	// Measurements may have data without series until deleted data is
+	// compacted away, or series without data until the cache is flushed.
+	names := make([]string, 0, len(seriesN))
+	for m := range seriesN {
+		names = append(names, m)
+	}
+	for m := range ts.Measurements {
+		if _, ok := seriesN[m]; !ok {
+			names = append(names, m)
+		}
+	}
+	sort.Strings(names)
+
+	stats := &platform.BucketStats{
+		BucketID:     bucketID,
+		OrgID:        orgID,
+		Measurements: make([]platform.MeasurementStorageStats, 0, len(names)),
+	}
+	var total int64
+	for _, m := range names {
+		ks := ts.Measurements[m]
+		if ks == nil {
+			ks = &tsm1.KeyStats{}
+		}
+		stats.Measurements = append(stats.Measurements, platform.MeasurementStorageStats{
+			Name:         m,
+			StorageStats: newStorageStats(ks, seriesN[m]),
+		})
+		total += seriesN[m]
+	}
+	stats.StorageStats = newStorageStats(&ts.KeyStats, total)
+	return stats, nil
+}
+
+func newStorageStats(ks *tsm1.KeyStats, seriesN int64) platform.StorageStats {
+	s := platform.StorageStats{
+		SeriesCardinality: seriesN,
+		TSMBytes:          ks.TSMBytes,
+		WALBytes:          ks.CacheBytes,
+		Tombstones:        int64(ks.Tombstones),
+	}
+	if ks.HasData() {
+		oldest, newest := time.Unix(0, ks.MinTime).UTC(), time.Unix(0, ks.MaxTime).UTC()
+		s.OldestTime, s.NewestTime = &oldest, &newest
+	}
+	return s
+}
@@ -0,0 +1,67 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestEngine_BucketStats(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	point := func(m, host string, ts int64) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: m, "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, ts),
+		)
+	}
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("cpu", "a", 10),
+		point("cpu", "b", 20),
+		point("mem", "a", 30),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := engine.BucketStats(context.Background(), engine.org, engine.bucket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := stats.SeriesCardinality, int64(3); got != exp {
+		t.Fatalf("got %d series, exp %d", got, exp)
+	}
+	if stats.WALBytes == 0 || stats.TSMBytes != 0 {
+		t.Fatalf("got %d WAL bytes and %d TSM bytes, exp only WAL bytes", stats.WALBytes, stats.TSMBytes)
+	}
+	if stats.OldestTime == nil || stats.OldestTime.UnixNano() != 10 || stats.NewestTime.UnixNano() != 30 {
+		t.Fatalf("got time range %v - %v", stats.OldestTime, stats.NewestTime)
+	}
+
+	if got, exp := len(stats.Measurements), 2; got != exp {
+		t.Fatalf("got %d measurements, exp %d", got, exp)
+	}
+	cpu, mem := stats.Measurements[0], stats.Measurements[1]
+	if cpu.Name != "cpu" || cpu.SeriesCardinality != 2 || mem.Name != "mem" || mem.SeriesCardinality != 1 {
+		t.Fatalf("got measurements %+v", stats.Measurements)
+	}
+	if cpu.NewestTime.UnixNano() != 20 {
+		t.Fatalf("got cpu newest time %v, exp 20ns", cpu.NewestTime)
+	}
+
+	// Another bucket has no data.
+	stats, err = engine.BucketStats(context.Background(), engine.bucket, engine.org)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.SeriesCardinality != 0 || stats.WALBytes != 0 || stats.OldestTime != nil || len(stats.Measurements) != 0 {
+		t.Fatalf("got stats %+v for empty bucket", stats)
+	}
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/tsdb"
+	"go.uber.org/zap"
+)
+
+// An Offloader moves cold TSM files to an object store.
+type Offloader interface {
+	OffloadColdFiles(ctx context.Context, coldBefore func(name []byte) (int64, bool)) (int, error)
+}
+
+// The coldTierEnforcer periodically offloads TSM files whose data is older
+// than the cold tier age of the buckets it belongs to.
+type coldTierEnforcer struct {
+	// Engine offloads the files.
+	Engine Offloader
+
+	// BucketService provides the cold tier age of each bucket.
+	BucketService BucketFinder
+
+	logger *zap.Logger
+}
+
+// newColdTierEnforcer returns a new enforcer offloading the files of engine.
+func newColdTierEnforcer(engine Offloader, bucketService BucketFinder) *coldTierEnforcer {
+	return &coldTierEnforcer{
+		Engine:        engine,
+		BucketService: bucketService,
+		logger:        zap.NewNop(),
+	}
+}
+
+// WithLogger sets the logger l on the service. It must be called before any run calls.
+func (s *coldTierEnforcer) WithLogger(l *zap.Logger) {
+	s.logger = l.With(zap.String("component", "cold_tier_enforcer"))
+}
+
+// run offloads the files that have become cold.
+func (s *coldTierEnforcer) run(ctx context.Context) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	log, logEnd := logger.NewOperation(ctx, s.logger, "Cold tier check", "cold_tier_check")
+	defer logEnd()
+
+	findCtx, cancel := context.WithTimeout(ctx, bucketAPITimeout)
+	buckets, _, err := s.BucketService.FindBuckets(findCtx, influxdb.BucketFilter{})
+	cancel()
+	if err != nil {
+		log.Error("Unable to determine bucket information", zap.Error(err))
+		return
+	}
+
+	n, err := s.Engine.OffloadColdFiles(ctx, coldBefore(buckets, time.Now().UTC()))
+	if err != nil {
+		log.Error("Unable to offload files", zap.Int("offloaded", n), zap.Error(err))
+		return
+	}
+	log.Info("Offloaded files", zap.Int("offloaded", n))
+}
+
+// coldBefore returns a function returning the time before which the data of
+// a bucket is cold, given the bucket's encoded name.
+func coldBefore(buckets []*influxdb.Bucket, now time.Time) func(name []byte) (int64, bool) {
+	cutoffs := make(map[string]int64, len(buckets))
+	for _, b := range buckets {
+		if b.ColdAfter <= 0 || !b.OrgID.Valid() || !b.ID.Valid() {
+			continue
+		}
+		cutoffs[tsdb.EncodeNameString(b.OrgID, b.ID)] = now.Add(-b.ColdAfter).UnixNano()
+	}
+
+	return func(name []byte) (int64, bool) {
+		cutoff, ok := cutoffs[string(name)]
+		return cutoff, ok
+	}
+}
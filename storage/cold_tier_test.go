@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+type offloaderFunc func(ctx context.Context, coldBefore func(name []byte) (int64, bool)) (int, error)
+
+func (f offloaderFunc) OffloadColdFiles(ctx context.Context, coldBefore func(name []byte) (int64, bool)) (int, error) {
+	return f(ctx, coldBefore)
+}
+
+func TestColdTierEnforcer(t *testing.T) {
+	finder := NewTestBucketFinder()
+	finder.FindBucketsFn = func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return []*influxdb.Bucket{
+			{OrgID: 1, ID: 2, ColdAfter: time.Hour},
+			{OrgID: 1, ID: 3},
+		}, 2, nil
+	}
+
+	var called bool
+	engine := offloaderFunc(func(ctx context.Context, coldBefore func(name []byte) (int64, bool)) (int, error) {
+		called = true
+
+		cutoff, ok := coldBefore(tsdb.EncodeNameSlice(1, 2))
+		if !ok {
+			t.Fatal("expected bucket with a cold tier age to be offloaded")
+		}
+		if want := time.Now().Add(-time.Hour).UnixNano(); cutoff > want || cutoff < want-int64(time.Minute) {
+			t.Fatalf("cutoff %v, want about %v", time.Unix(0, cutoff), time.Unix(0, want))
+		}
+
+		if _, ok := coldBefore(tsdb.EncodeNameSlice(1, 3)); ok {
+			t.Fatal("expected bucket without a cold tier age to stay local")
+		}
+		if _, ok := coldBefore(tsdb.EncodeNameSlice(1, 4)); ok {
+			t.Fatal("expected unknown bucket to stay local")
+		}
+		return 1, nil
+	})
+
+	newColdTierEnforcer(engine, finder).run(context.Background())
+	if !called {
+		t.Fatal("engine was not asked to offload files")
+	}
+}
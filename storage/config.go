@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/objstore"
 	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
@@ -17,6 +18,9 @@ const (
 	DefaultIndexDirectoryName      = "index"
 	DefaultWALDirectoryName        = "wal"
 	DefaultEngineDirectoryName     = "data"
+	DefaultColdTierInterval        = time.Hour
+	DefaultColdTierCacheSize       = 256 * 1024 * 1024 // 256MB
+	DefaultColdTierTimeout         = 30 * time.Second
 )
 
 // Config holds the configuration for an Engine.
@@ -41,6 +45,64 @@ type Config struct {
 	// Index config.
 	Index     tsi1.Config `toml:"index"`
 	IndexPath string      `toml:"index-path"` // Overrides the default path.
+
+	// Cold tier config.
+	ColdTier ColdTierConfig `toml:"cold-tier"`
+
+	// Maximum size of the cache of the latest value of each series field,
+	// which serves queries for the last value of series. The cache is
+	// disabled when zero.
+	LastValueCacheSize toml.Size `toml:"last-value-cache-size"`
+
+	// Open the engine in read-only mode, rejecting writes and deletes until
+	// the mode is changed.
+	ReadOnly bool `toml:"read-only"`
+}
+
+// ColdTierConfig configures the object store that fully compacted TSM files
+// are offloaded to once all of their data is older than the cold tier age
+// of their buckets.
+type ColdTierConfig struct {
+	// URL of the object store, either file:///path/to/dir or
+	// s3://bucket/prefix?endpoint=...&region=... The cold tier is disabled
+	// when empty. A store must not be shared by several engines unless each
+	// uses its own prefix.
+	URL string `toml:"url"`
+
+	// Frequency of offload checks.
+	Interval toml.Duration `toml:"interval"`
+
+	// Maximum size of the cache of blocks read from the object store.
+	CacheSize toml.Size `toml:"cache-size"`
+
+	// Maximum time of each read of a block from, and delete of a file in,
+	// the object store. Requests are not limited when zero.
+	Timeout toml.Duration `toml:"timeout"`
+
+	// S3 credentials. The standard AWS environment variables are used when
+	// they are empty.
+	AccessKeyID     string `toml:"access-key-id"`
+	SecretAccessKey string `toml:"secret-access-key"`
+}
+
+// NewColdTierConfig initialises a new cold tier config.
+func NewColdTierConfig() ColdTierConfig {
+	return ColdTierConfig{
+		Interval:  toml.Duration(DefaultColdTierInterval),
+		CacheSize: toml.Size(DefaultColdTierCacheSize),
+		Timeout:   toml.Duration(DefaultColdTierTimeout),
+	}
+}
+
+// Credentials returns the configured S3 credentials.
+func (c ColdTierConfig) Credentials() objstore.Credentials {
+	if c.AccessKeyID == "" && c.SecretAccessKey == "" {
+		return objstore.EnvCredentials()
+	}
+	return objstore.Credentials{
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+	}
 }
 
 // NewConfig initialises a new config for an Engine.
@@ -51,6 +113,7 @@ func NewConfig() Config {
 		WAL:               tsm1.NewWALConfig(),
 		Engine:            tsm1.NewConfig(),
 		Index:             tsi1.NewConfig(),
+		ColdTier:          NewColdTierConfig(),
 	}
 }
 
@@ -0,0 +1,348 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/tracing"
+	"github.com/influxdata/influxdb/snowflake"
+	"go.uber.org/zap"
+)
+
+// DefaultDeleteJobHistory is the number of finished delete jobs that are kept.
+const DefaultDeleteJobHistory = 100
+
+// A ProgressDeleter deletes data while recording its progress.
+type ProgressDeleter interface {
+	DeleteBucketRangePredicateWithProgress(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error
+}
+
+var _ influxdb.DeleteJobService = (*DeleteJobService)(nil)
+
+// DeleteJobService runs deletes in the background, one at a time and in the
+// order they were created. Jobs are kept in memory; the deletes themselves
+// are written to the WAL by the engine, so a restart part way through a delete
+// finishes it when the WAL is replayed.
+//
+// A job can be canceled until the engine has written its delete to the WAL,
+// after which it always runs to completion.
+type DeleteJobService struct {
+	engine ProgressDeleter
+	log    *zap.Logger
+
+	IDGenerator influxdb.IDGenerator
+	MaxHistory  int
+	now         func() time.Time
+
+	mu      sync.Mutex
+	jobs    map[influxdb.ID]*deleteJob
+	order   []*deleteJob // every job, oldest first
+	queue   []*deleteJob
+	wake    chan struct{}
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+type deleteJob struct {
+	job      influxdb.DeleteJob
+	pred     influxdb.Predicate
+	progress influxdb.DeleteProgress
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDeleteJobService returns a DeleteJobService running deletes against
+// engine. Close must be called to stop it.
+func NewDeleteJobService(engine ProgressDeleter, log *zap.Logger) *DeleteJobService {
+	s := &DeleteJobService{
+		engine:      engine,
+		log:         log,
+		IDGenerator: snowflake.NewIDGenerator(),
+		MaxHistory:  DefaultDeleteJobHistory,
+		now:         time.Now,
+		jobs:        make(map[influxdb.ID]*deleteJob),
+		wake:        make(chan struct{}, 1),
+		closing:     make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run()
+	}()
+	return s
+}
+
+// Close cancels every unfinished job and waits for the running one to stop.
+// A running job that has started deleting data is not waited for: it stops
+// when the engine is closed, and the WAL replay finishes it.
+func (s *DeleteJobService) Close() error {
+	s.mu.Lock()
+	select {
+	case <-s.closing:
+		s.mu.Unlock()
+		return nil
+	default:
+	}
+	close(s.closing)
+	now := s.now().UTC()
+	var started bool
+	for _, j := range s.order {
+		j.cancel()
+		switch j.job.Status {
+		case influxdb.DeleteJobPending:
+			j.job.Status = influxdb.DeleteJobCanceled
+			j.job.FinishedAt = &now
+		case influxdb.DeleteJobRunning:
+			started = !j.progress.Cancel()
+		}
+	}
+	s.mu.Unlock()
+
+	if !started {
+		s.wg.Wait()
+	}
+	return nil
+}
+
+// CreateDeleteJob queues a delete.
+func (s *DeleteJobService) CreateDeleteJob(ctx context.Context, job *influxdb.DeleteJob, pred influxdb.Predicate) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !job.OrgID.Valid() || !job.BucketID.Valid() {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "delete job requires an organization and a bucket",
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.closing:
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "delete jobs are unavailable while the server shuts down",
+			Err:  ErrServiceClosed,
+		}
+	default:
+	}
+
+	job.ID = s.IDGenerator.ID()
+	job.Status = influxdb.DeleteJobPending
+	job.Progress = influxdb.DeleteProgress{}
+	job.Error = ""
+	job.CreatedAt = s.now().UTC()
+	job.StartedAt = nil
+	job.FinishedAt = nil
+
+	// Jobs are not tied to the request that created them.
+	jctx, cancel := context.WithCancel(context.Background())
+	j := &deleteJob{
+		job:    *job,
+		pred:   pred,
+		ctx:    jctx,
+		cancel: cancel,
+	}
+	s.jobs[job.ID] = j
+	s.order = append(s.order, j)
+	s.queue = append(s.queue, j)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// FindDeleteJobByID returns a single delete job by ID.
+func (s *DeleteJobService) FindDeleteJobByID(ctx context.Context, id influxdb.ID) (*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, errDeleteJobNotFound
+	}
+	return j.view(), nil
+}
+
+// FindDeleteJobs returns the delete jobs matching filter, oldest first.
+func (s *DeleteJobService) FindDeleteJobs(ctx context.Context, filter influxdb.DeleteJobFilter) ([]*influxdb.DeleteJob, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*influxdb.DeleteJob, 0, len(s.order))
+	for _, j := range s.order {
+		if filter.OrgID != nil && j.job.OrgID != *filter.OrgID {
+			continue
+		}
+		if filter.BucketID != nil && j.job.BucketID != *filter.BucketID {
+			continue
+		}
+		jobs = append(jobs, j.view())
+	}
+	return jobs, nil
+}
+
+// CancelDeleteJob stops a pending delete job, or a running one that has not
+// started deleting data yet. Once a job has started deleting data it can no
+// longer be canceled, since the delete would be applied again when the WAL is
+// replayed.
+func (s *DeleteJobService) CancelDeleteJob(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return errDeleteJobNotFound
+	}
+	if j.job.Status.Done() {
+		return &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  "delete job has already finished",
+		}
+	}
+
+	if !j.progress.Cancel() {
+		return errDeleteJobStarted
+	}
+
+	j.cancel()
+	if j.job.Status == influxdb.DeleteJobPending {
+		s.finish(j, influxdb.DeleteJobCanceled, nil)
+	}
+	return nil
+}
+
+var errDeleteJobNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "delete job not found",
+}
+
+var errDeleteJobStarted = &influxdb.Error{
+	Code: influxdb.EConflict,
+	Msg:  "delete job has started deleting data and can no longer be canceled",
+}
+
+// run executes queued jobs until the service is closed.
+func (s *DeleteJobService) run() {
+	for {
+		j := s.next()
+		if j == nil {
+			return
+		}
+
+		log := s.log.With(zap.String("job_id", j.job.ID.String()),
+			zap.String("bucket_id", j.job.BucketID.String()))
+		log.Info("Delete job started")
+
+		err := s.engine.DeleteBucketRangePredicateWithProgress(j.ctx,
+			j.job.OrgID, j.job.BucketID,
+			j.job.Start.UnixNano(), j.job.Stop.UnixNano(),
+			j.pred, &j.progress)
+
+		s.mu.Lock()
+		switch {
+		case err == nil:
+			s.finish(j, influxdb.DeleteJobSucceeded, nil)
+			log.Info("Delete job succeeded")
+		case j.ctx.Err() != nil && !j.progress.Started():
+			s.finish(j, influxdb.DeleteJobCanceled, nil)
+			log.Info("Delete job canceled")
+		default:
+			s.finish(j, influxdb.DeleteJobFailed, err)
+			log.Error("Delete job failed", zap.Error(err))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// next waits for a pending job, marks it running and returns it. It returns
+// nil once the service is closed.
+func (s *DeleteJobService) next() *deleteJob {
+	for {
+		s.mu.Lock()
+		select {
+		case <-s.closing:
+			s.mu.Unlock()
+			return nil
+		default:
+		}
+
+		for len(s.queue) > 0 {
+			j := s.queue[0]
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+			if j.job.Status != influxdb.DeleteJobPending {
+				continue // canceled while queued.
+			}
+
+			now := s.now().UTC()
+			j.job.Status = influxdb.DeleteJobRunning
+			j.job.StartedAt = &now
+			s.mu.Unlock()
+			return j
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-s.wake:
+		case <-s.closing:
+		}
+	}
+}
+
+// finish records the outcome of j and forgets the oldest finished jobs
+// beyond MaxHistory. It must be called with s.mu held.
+func (s *DeleteJobService) finish(j *deleteJob, status influxdb.DeleteJobStatus, err error) {
+	now := s.now().UTC()
+	j.job.Status = status
+	j.job.FinishedAt = &now
+	if err != nil {
+		j.job.Error = err.Error()
+	}
+	j.cancel()
+
+	var finished int
+	for _, o := range s.order {
+		if o.job.Status.Done() {
+			finished++
+		}
+	}
+
+	order := s.order[:0]
+	for _, o := range s.order {
+		if finished > s.MaxHistory && o.job.Status.Done() {
+			delete(s.jobs, o.job.ID)
+			finished--
+			continue
+		}
+		order = append(order, o)
+	}
+	for i := len(order); i < len(s.order); i++ {
+		s.order[i] = nil
+	}
+	s.order = order
+}
+
+// view returns a copy of the job with its current progress.
+func (j *deleteJob) view() *influxdb.DeleteJob {
+	job := j.job
+	job.Progress = j.progress.Snapshot()
+	return &job
+}
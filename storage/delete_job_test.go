@@ -0,0 +1,190 @@
+package storage_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/storage"
+	"go.uber.org/zap/zaptest"
+)
+
+type progressDeleterFunc func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error
+
+func (f progressDeleterFunc) DeleteBucketRangePredicateWithProgress(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+	return f(ctx, orgID, bucketID, min, max, pred, progress)
+}
+
+func newDeleteJob() *influxdb.DeleteJob {
+	return &influxdb.DeleteJob{
+		OrgID:    1,
+		BucketID: 2,
+		Start:    time.Unix(0, 10),
+		Stop:     time.Unix(0, 20),
+	}
+}
+
+// waitForStatus polls the job until it has status want.
+func waitForStatus(t *testing.T, s influxdb.DeleteJobService, id influxdb.ID, want influxdb.DeleteJobStatus) *influxdb.DeleteJob {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := s.FindDeleteJobByID(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status == want {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job status is %q, want %q", job.Status, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestDeleteJobService_Run(t *testing.T) {
+	var min, max int64
+	engine := progressDeleterFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, mn, mx int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+		min, max = mn, mx
+		atomic.StoreInt64(&progress.FilesTotal, 3)
+		atomic.StoreInt64(&progress.FilesScanned, 3)
+		atomic.StoreInt64(&progress.SeriesDeleted, 7)
+		return nil
+	})
+	s := storage.NewDeleteJobService(engine, zaptest.NewLogger(t))
+	defer s.Close()
+
+	job := newDeleteJob()
+	if err := s.CreateDeleteJob(context.Background(), job, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !job.ID.Valid() || job.Status != influxdb.DeleteJobPending {
+		t.Fatalf("unexpected created job: %+v", job)
+	}
+
+	got := waitForStatus(t, s, job.ID, influxdb.DeleteJobSucceeded)
+	if min != 10 || max != 20 {
+		t.Fatalf("deleted range [%d, %d], want [10, 20]", min, max)
+	}
+	if want := (influxdb.DeleteProgress{FilesTotal: 3, FilesScanned: 3, SeriesDeleted: 7}); got.Progress != want {
+		t.Fatalf("progress = %+v, want %+v", got.Progress, want)
+	}
+	if got.StartedAt == nil || got.FinishedAt == nil {
+		t.Fatalf("expected start and finish times: %+v", got)
+	}
+
+	if err := s.CancelDeleteJob(context.Background(), job.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("cancel finished job: got %v, want conflict", err)
+	}
+}
+
+func TestDeleteJobService_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	engine := progressDeleterFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	s := storage.NewDeleteJobService(engine, zaptest.NewLogger(t))
+	defer s.Close()
+
+	running, pending := newDeleteJob(), newDeleteJob()
+	for _, job := range []*influxdb.DeleteJob{running, pending} {
+		if err := s.CreateDeleteJob(context.Background(), job, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+
+	// The second job waits for the first.
+	waitForStatus(t, s, running.ID, influxdb.DeleteJobRunning)
+	waitForStatus(t, s, pending.ID, influxdb.DeleteJobPending)
+
+	if err := s.CancelDeleteJob(context.Background(), pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, s, pending.ID, influxdb.DeleteJobCanceled)
+
+	if err := s.CancelDeleteJob(context.Background(), running.ID); err != nil {
+		t.Fatal(err)
+	}
+	waitForStatus(t, s, running.ID, influxdb.DeleteJobCanceled)
+
+	if err := s.CancelDeleteJob(context.Background(), 99); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("cancel missing job: got %v, want not found", err)
+	}
+}
+
+func TestDeleteJobService_Cancel_Started(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	engine := progressDeleterFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+		if !progress.Start() {
+			return context.Canceled
+		}
+		close(started)
+		<-release
+		return nil
+	})
+	s := storage.NewDeleteJobService(engine, zaptest.NewLogger(t))
+	defer s.Close()
+
+	job := newDeleteJob()
+	if err := s.CreateDeleteJob(context.Background(), job, nil); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// The delete would be replayed from the WAL, so it must not be reported
+	// as canceled.
+	if err := s.CancelDeleteJob(context.Background(), job.ID); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("cancel started job: got %v, want conflict", err)
+	}
+	close(release)
+	waitForStatus(t, s, job.ID, influxdb.DeleteJobSucceeded)
+}
+
+func TestDeleteJobService_FindDeleteJobs(t *testing.T) {
+	engine := progressDeleterFunc(func(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64, pred influxdb.Predicate, progress *influxdb.DeleteProgress) error {
+		return nil
+	})
+	s := storage.NewDeleteJobService(engine, zaptest.NewLogger(t))
+	s.MaxHistory = 2
+	defer s.Close()
+
+	var ids []influxdb.ID
+	for _, bucketID := range []influxdb.ID{2, 3, 2} {
+		job := newDeleteJob()
+		job.BucketID = bucketID
+		if err := s.CreateDeleteJob(context.Background(), job, nil); err != nil {
+			t.Fatal(err)
+		}
+		waitForStatus(t, s, job.ID, influxdb.DeleteJobSucceeded)
+		ids = append(ids, job.ID)
+	}
+
+	// The oldest finished job is forgotten.
+	if _, err := s.FindDeleteJobByID(context.Background(), ids[0]); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("find forgotten job: got %v, want not found", err)
+	}
+
+	bucketID := influxdb.ID(2)
+	jobs, err := s.FindDeleteJobs(context.Background(), influxdb.DeleteJobFilter{BucketID: &bucketID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != ids[2] {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+
+	jobs, err = s.FindDeleteJobs(context.Background(), influxdb.DeleteJobFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != ids[1] || jobs[1].ID != ids[2] {
+		t.Fatalf("unexpected jobs: %+v", jobs)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/influxdb"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/influxdb/logger"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/pkg/limiter"
+	"github.com/influxdata/influxdb/pkg/objstore"
 	"github.com/influxdata/influxdb/storage/wal"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsi1"
@@ -37,6 +39,9 @@ var timeBytes = []byte("time")
 // it's closed.
 var ErrEngineClosed = errors.New("engine is closed")
 
+// errDeleteInterrupted is returned by a delete stopped by closing the engine.
+var errDeleteInterrupted = errors.New("delete interrupted by engine shutdown, it is finished when the WAL is replayed")
+
 // runner lets us mock out the retention enforcer in tests
 type runner interface{ run() }
 
@@ -61,6 +66,22 @@ type Engine struct {
 	retentionEnforcer        runner
 	retentionEnforcerLimiter runnable
 
+	coldTierEnforcer *coldTierEnforcer
+
+	seriesLimitTracker *seriesLimitTracker
+
+	lastCache *lastValueCache // nil when disabled.
+
+	// mode holds the current platform.StorageMode. modeMu serializes changes
+	// to it, as they snapshot the cache and toggle compactions.
+	mode   atomic.Value
+	modeMu sync.Mutex
+
+	// modifying is the number of writes and deletes in progress. Entering
+	// read-only mode waits for them without taking mu, which deletes hold
+	// for a long time.
+	modifying int32
+
 	defaultMetricLabels prometheus.Labels
 
 	// Tracks all goroutines started by the Engine.
@@ -110,7 +131,9 @@ func WithNodeID(id int) Option {
 // metrics are labelled correctly.
 func WithRetentionEnforcer(finder BucketFinder) Option {
 	return func(e *Engine) {
-		e.retentionEnforcer = newRetentionEnforcer(e, e.engine, finder)
+		r := newRetentionEnforcer(e, e.engine, finder)
+		r.Partitioner = e.engine
+		e.retentionEnforcer = r
 	}
 }
 
@@ -124,6 +147,14 @@ func WithRetentionEnforcerLimiter(f runnable) Option {
 	}
 }
 
+// WithColdTier initialises an enforcer offloading cold TSM files to the
+// object store in the engine's cold tier config.
+func WithColdTier(finder BucketFinder) Option {
+	return func(e *Engine) {
+		e.coldTierEnforcer = newColdTierEnforcer(e.engine, finder)
+	}
+}
+
 // WithFileStoreObserver makes the engine have the provided file store observer.
 func WithFileStoreObserver(obs tsm1.FileStoreObserver) Option {
 	return func(e *Engine) {
@@ -164,6 +195,7 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 		defaultMetricLabels: prometheus.Labels{},
 		logger:              zap.NewNop(),
 	}
+	e.mode.Store(platform.StorageMode{})
 
 	// Initialize series file.
 	e.sfile = tsdb.NewSeriesFile(c.GetSeriesFilePath(path))
@@ -181,6 +213,10 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	// Initialise Engine
 	e.engine = tsm1.NewEngine(c.GetEnginePath(path), e.index, c.Engine, tsm1.WithSnapshotter(e))
 
+	if c.LastValueCacheSize > 0 {
+		e.lastCache = newLastValueCache(uint64(c.LastValueCacheSize))
+	}
+
 	// Apply options.
 	for _, option := range options {
 		option(e)
@@ -195,6 +231,13 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 		r.SetDefaultMetricLabels(e.defaultMetricLabels)
 	}
 
+	mmu.Lock()
+	if sms == nil {
+		sms = newSeriesLimitMetrics(e.defaultMetricLabels)
+	}
+	mmu.Unlock()
+	e.seriesLimitTracker = newSeriesLimitTracker(sms, e.defaultMetricLabels)
+
 	return e
 }
 
@@ -218,6 +261,9 @@ func (e *Engine) WithLogger(log *zap.Logger) {
 	if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
 		r.WithLogger(e.logger)
 	}
+	if e.coldTierEnforcer != nil {
+		e.coldTierEnforcer.WithLogger(e.logger)
+	}
 }
 
 // PrometheusCollectors returns all the prometheus collectors associated with
@@ -229,6 +275,7 @@ func (e *Engine) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, tsm1.PrometheusCollectors()...)
 	metrics = append(metrics, wal.PrometheusCollectors()...)
 	metrics = append(metrics, RetentionPrometheusCollectors()...)
+	metrics = append(metrics, SeriesLimitPrometheusCollectors()...)
 	return metrics
 }
 
@@ -245,6 +292,16 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	// Offloaded TSM files can only be opened with their object store.
+	if url := e.config.ColdTier.URL; url != "" {
+		store, err := objstore.Open(url, e.config.ColdTier.Credentials())
+		if err != nil {
+			return err
+		}
+		tsm1.WithObjectStore(store, tsm1.NewBlockCache(int64(e.config.ColdTier.CacheSize)))(e.engine)
+		tsm1.WithObjectStoreTimeout(time.Duration(e.config.ColdTier.Timeout))(e.engine)
+	}
+
 	// Open the services in order and clean up if any fail.
 	var oh openHelper
 	oh.Open(ctx, e.sfile)
@@ -260,6 +317,7 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	}
 
 	e.closing = make(chan struct{})
+	e.mode.Store(platform.StorageMode{ReadOnly: e.config.ReadOnly})
 
 	// TODO(edd) background tasks will be run in priority order via a scheduler.
 	// For now we will just run on an interval as we only have the retention
@@ -267,6 +325,9 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 	if e.retentionEnforcer != nil {
 		e.runRetentionEnforcer()
 	}
+	if e.coldTierEnforcer != nil {
+		e.runColdTierEnforcer()
+	}
 
 	return nil
 }
@@ -313,7 +374,7 @@ func (e *Engine) replayWAL() error {
 				}
 			}
 
-			return e.deleteBucketRangeLocked(context.Background(), en.OrgID, en.BucketID, en.Min, en.Max, pred)
+			return e.deleteBucketRangeLocked(context.Background(), en.OrgID, en.BucketID, en.Min, en.Max, pred, nil)
 		}
 
 		return nil
@@ -350,6 +411,9 @@ func (e *Engine) runRetentionEnforcer() {
 	e.wg.Add(1)
 	go func() {
 		defer e.wg.Done()
+		if r, ok := e.retentionEnforcer.(*retentionEnforcer); ok {
+			r.refreshPartitions()
+		}
 		for {
 			// It's safe to read closing without a lock because it's never
 			// modified if this goroutine is active.
@@ -383,7 +447,12 @@ func (e *Engine) runRetentionEnforcer() {
 					l.Info("Stopping")
 					return
 				case done := <-canRun:
-					e.retentionEnforcer.run()
+					// Retention deletes data, which read-only mode prevents.
+					if e.readOnly() {
+						l.Info("Skipping, engine is read-only")
+					} else {
+						e.retentionEnforcer.run()
+					}
 					if done != nil {
 						done()
 					}
@@ -393,6 +462,51 @@ func (e *Engine) runRetentionEnforcer() {
 	}()
 }
 
+// runColdTierEnforcer runs the cold tier enforcer in a separate goroutine.
+func (e *Engine) runColdTierEnforcer() {
+	interval := time.Duration(e.config.ColdTier.Interval)
+
+	if e.config.ColdTier.URL == "" {
+		e.logger.Info("Cold tier disabled")
+		return
+	} else if interval <= 0 {
+		e.logger.Error("Invalid cold tier interval", logger.DurationLiteral("check_interval", interval))
+		return
+	}
+
+	l := e.logger.With(zap.String("component", "cold_tier_enforcer"), logger.DurationLiteral("check_interval", interval))
+	l.Info("Starting")
+
+	// Uploads are canceled when the engine closes.
+	closing := e.closing
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-closing
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		defer ticker.Stop()
+		for {
+			select {
+			case <-closing:
+				l.Info("Stopping")
+				return
+			case <-ticker.C:
+				// Offloading replaces TSM files, which may be being copied.
+				if e.readOnly() {
+					l.Info("Skipping, engine is read-only")
+					continue
+				}
+				e.coldTierEnforcer.run(ctx)
+			}
+		}
+	}()
+}
+
 // Close closes the store and all underlying resources. It returns an error if
 // any of the underlying systems fail to close.
 func (e *Engine) Close() error {
@@ -443,6 +557,94 @@ func (e *Engine) CreateCursorIterator(ctx context.Context) (tsdb.CursorIterator,
 	return e.engine.CreateCursorIterator(ctx)
 }
 
+// LastValue returns the value with the greatest timestamp within [start, end]
+// of the field of the series identified by name and tags, or nil if there is
+// none. The latest value of the series is served from the last value cache
+// when it is enabled, otherwise the series is scanned from its end.
+func (e *Engine) LastValue(ctx context.Context, name []byte, tags models.Tags, field string, start, end int64) (value.Value, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	if e.lastCache == nil {
+		return e.scanLastValue(ctx, name, tags, field, start, end)
+	}
+
+	key := tsm1.SeriesFieldKeyBytes(string(models.MakeKey(name, tags)), field)
+	v, ok := e.lastCache.Get(key)
+	if !ok {
+		gen := e.lastCache.Generation(key)
+		latest, err := e.scanLastValue(ctx, name, tags, field, models.MinNanoTime, models.MaxNanoTime)
+		if err != nil || latest == nil {
+			return nil, err
+		}
+		v = e.lastCache.Fill(key, latest, gen)
+	}
+
+	switch t := v.UnixNano(); {
+	case t < start:
+		// No value of the series is within the range.
+		return nil, nil
+	case t <= end:
+		return v, nil
+	}
+	return e.scanLastValue(ctx, name, tags, field, start, end)
+}
+
+// scanLastValue reads the value with the greatest timestamp within
+// [start, end] of a series field from the TSM engine.
+func (e *Engine) scanLastValue(ctx context.Context, name []byte, tags models.Tags, field string, start, end int64) (value.Value, error) {
+	itr, err := e.engine.CreateCursorIterator(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := itr.Next(ctx, &tsdb.CursorRequest{
+		Name:      name,
+		Tags:      tags,
+		Field:     field,
+		Ascending: false,
+		StartTime: start,
+		EndTime:   end,
+	})
+	if err != nil || cur == nil {
+		return nil, err
+	}
+	defer cur.Close()
+
+	// Values are read in descending order, so the first is the latest.
+	var v value.Value
+	switch c := cur.(type) {
+	case tsdb.FloatArrayCursor:
+		if a := c.Next(); a.Len() > 0 {
+			v = value.NewFloatValue(a.Timestamps[0], a.Values[0])
+		}
+	case tsdb.IntegerArrayCursor:
+		if a := c.Next(); a.Len() > 0 {
+			v = value.NewIntegerValue(a.Timestamps[0], a.Values[0])
+		}
+	case tsdb.UnsignedArrayCursor:
+		if a := c.Next(); a.Len() > 0 {
+			v = value.NewUnsignedValue(a.Timestamps[0], a.Values[0])
+		}
+	case tsdb.StringArrayCursor:
+		if a := c.Next(); a.Len() > 0 {
+			v = value.NewStringValue(a.Timestamps[0], a.Values[0])
+		}
+	case tsdb.BooleanArrayCursor:
+		if a := c.Next(); a.Len() > 0 {
+			v = value.NewBooleanValue(a.Timestamps[0], a.Values[0])
+		}
+	default:
+		return nil, fmt.Errorf("unexpected cursor type %T", cur)
+	}
+	return v, cur.Err()
+}
+
 // WritePoints writes the provided points to the engine.
 //
 // The Engine expects all points to have been correctly validated by the caller.
@@ -518,6 +720,15 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 
 	if e.closing == nil {
 		return ErrEngineClosed
+	} else if err := e.beginModify(); err != nil {
+		return err
+	}
+	defer e.endModify()
+
+	// Drop points that would create series beyond the configured limits
+	// before they are added to the WAL.
+	if err := e.applySeriesLimits(collection, dropPoint); err != nil {
+		return err
 	}
 
 	// Convert the collection to values for adding to the WAL/Cache.
@@ -562,6 +773,9 @@ func (e *Engine) writePointsLocked(ctx context.Context, collection *tsdb.SeriesC
 	if err := e.engine.WriteValues(values); err != nil {
 		return err
 	}
+	if e.lastCache != nil {
+		e.lastCache.Add(values)
+	}
 
 	return collection.PartialWriteError()
 }
@@ -616,6 +830,13 @@ func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID platform
 	defer e.mu.RUnlock()
 	if e.closing == nil {
 		return ErrEngineClosed
+	} else if err := e.beginModify(); err != nil {
+		return err
+	}
+	defer e.endModify()
+
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
 	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
@@ -623,12 +844,25 @@ func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID platform
 		return err
 	}
 
-	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, nil)
+	ctx, cancel := e.closingContext(ctx)
+	defer cancel()
+	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, nil, nil)
 }
 
 // DeleteBucketRangePredicate deletes data within a bucket from the storage engine. Any data
 // deleted must be in [min, max], and the key must match the predicate if provided.
 func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred platform.Predicate) error {
+	return e.DeleteBucketRangePredicateWithProgress(ctx, orgID, bucketID, min, max, pred, nil)
+}
+
+// DeleteBucketRangePredicateWithProgress is DeleteBucketRangePredicate, recording
+// its progress in progress, which may be nil.
+//
+// The delete can be cancelled with ctx or progress until it is written to the
+// WAL. From then on it runs to completion so that the TSM files, cache and
+// index stay consistent. Only closing the engine stops it early, and the WAL
+// replay finishes it when the engine is opened again.
+func (e *Engine) DeleteBucketRangePredicateWithProgress(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred platform.Predicate, progress *platform.DeleteProgress) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
@@ -636,7 +870,10 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 	defer e.mu.RUnlock()
 	if e.closing == nil {
 		return ErrEngineClosed
+	} else if err := e.beginModify(); err != nil {
+		return err
 	}
+	defer e.endModify()
 
 	var predData []byte
 	var err error
@@ -648,23 +885,207 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	} else if progress != nil && !progress.Start() {
+		return context.Canceled
+	}
+
 	// Add the delete to the WAL to be replayed if there is a crash or shutdown.
 	if _, err := e.wal.DeleteBucketRange(orgID, bucketID, min, max, predData); err != nil {
 		return err
 	}
 
-	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, pred)
+	ctx, cancel := e.closingContext(ctx)
+	defer cancel()
+	return e.deleteBucketRangeLocked(ctx, orgID, bucketID, min, max, pred, progress)
 }
 
+// PreviewBucketRangePredicate returns the number of series and points that
+// DeleteBucketRangePredicate would delete, without deleting anything.
+func (e *Engine) PreviewBucketRangePredicate(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred platform.Predicate) (*platform.DeleteStats, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	encoded := tsdb.EncodeName(orgID, bucketID)
+	name := models.EscapeMeasurement(encoded[:])
+
+	series, points, err := e.engine.CountPrefixRange(ctx, name, min, max, pred)
+	if err != nil {
+		return nil, err
+	}
+	return &platform.DeleteStats{Series: series, Points: points}, nil
+}
+
+// closingContext returns a context with the values of ctx that is cancelled
+// when the engine is closed rather than when ctx is. It must be called under
+// some sort of lock.
+func (e *Engine) closingContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(uncancelledContext{ctx})
+	closing := e.closing
+	go func() {
+		select {
+		case <-closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// uncancelledContext is a context with the values of its parent that is
+// never cancelled.
+type uncancelledContext struct {
+	context.Context
+}
+
+func (uncancelledContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (uncancelledContext) Done() <-chan struct{}       { return nil }
+func (uncancelledContext) Err() error                  { return nil }
+
 // deleteBucketRangeLocked does the work of deleting a bucket range and must be called under
 // some sort of lock.
-func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred tsm1.Predicate) error {
+func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID platform.ID, min, max int64, pred tsm1.Predicate, progress *platform.DeleteProgress) error {
 	// TODO(edd): we need to clean up how we're encoding the prefix so that we
 	// don't have to remember to get it right everywhere we need to touch TSM data.
 	encoded := tsdb.EncodeName(orgID, bucketID)
 	name := models.EscapeMeasurement(encoded[:])
 
-	return e.engine.DeletePrefixRange(ctx, name, min, max, pred)
+	err := e.engine.DeletePrefixRangeWithProgress(ctx, name, min, max, pred, progress)
+
+	// The latest values of the bucket may have been deleted, even if the
+	// delete failed part way through.
+	if e.lastCache != nil {
+		e.lastCache.DeletePrefix(name)
+	}
+	if err != nil && ctx.Err() != nil {
+		return errDeleteInterrupted
+	}
+	return err
+}
+
+// StorageMode returns the current mode of the engine.
+func (e *Engine) StorageMode(ctx context.Context) (*platform.StorageMode, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	m := e.storageMode()
+	return &m, nil
+}
+
+// SetStorageMode changes the mode of the engine.
+//
+// Entering read-only mode rejects new writes and deletes straight away, waits
+// for those in progress, then snapshots the cache to TSM files so the WAL
+// segments holding its data are removed. Reads are not blocked meanwhile. If
+// ctx is done first, the previous mode is restored. Compactions are paused
+// after the snapshot if requested, and resumed when leaving read-only mode.
+func (e *Engine) SetStorageMode(ctx context.Context, m platform.StorageMode) (*platform.StorageMode, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !m.ReadOnly {
+		m.PauseCompactions = false
+	}
+
+	e.modeMu.Lock()
+	defer e.modeMu.Unlock()
+
+	e.mu.RLock()
+	closed := e.closing == nil
+	e.mu.RUnlock()
+	if closed {
+		return nil, ErrEngineClosed
+	}
+	prev := e.storageMode()
+	e.mode.Store(m)
+
+	if m.ReadOnly && !prev.ReadOnly {
+		if err := e.waitModifying(ctx); err != nil {
+			e.mode.Store(prev)
+			return nil, err
+		}
+		if err := e.flushCache(ctx); err != nil {
+			e.mode.Store(prev)
+			return nil, err
+		}
+	}
+
+	if m.PauseCompactions && !prev.PauseCompactions {
+		e.engine.SetCompactionsEnabled(false)
+	} else if !m.PauseCompactions && prev.PauseCompactions {
+		e.engine.SetCompactionsEnabled(true)
+	}
+
+	e.logger.Info("Storage mode changed",
+		zap.Bool("read_only", m.ReadOnly),
+		zap.Bool("pause_compactions", m.PauseCompactions))
+	return &m, nil
+}
+
+// flushCache snapshots the cache, waiting for any snapshot already in
+// progress to finish first.
+func (e *Engine) flushCache(ctx context.Context) error {
+	for {
+		err := e.engine.WriteSnapshot(ctx, tsm1.CacheStatusReadOnly)
+		if err != tsm1.ErrSnapshotInProgress {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// waitModifying waits for the writes and deletes in progress to finish.
+func (e *Engine) waitModifying(ctx context.Context) error {
+	for atomic.LoadInt32(&e.modifying) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// beginModify records that a write or delete is in progress, unless the
+// engine is read-only. endModify must be called once it is done.
+func (e *Engine) beginModify() error {
+	// The count is raised before the mode is checked, so SetStorageMode
+	// either waits for this write or delete or it is rejected.
+	atomic.AddInt32(&e.modifying, 1)
+	if e.readOnly() {
+		atomic.AddInt32(&e.modifying, -1)
+		return platform.ErrStorageReadOnly
+	}
+	return nil
+}
+
+// endModify records that a write or delete started by beginModify is done.
+func (e *Engine) endModify() {
+	atomic.AddInt32(&e.modifying, -1)
+}
+
+// storageMode returns the current mode of the engine.
+func (e *Engine) storageMode() platform.StorageMode {
+	return e.mode.Load().(platform.StorageMode)
+}
+
+// readOnly returns true if the engine is in read-only mode.
+func (e *Engine) readOnly() bool {
+	return e.storageMode().ReadOnly
 }
 
 // CreateBackup creates a "snapshot" of all TSM data in the Engine.
@@ -806,6 +1227,26 @@ func (e *Engine) MeasurementCardinalityStats() (tsi1.MeasurementCardinalityStats
 	return e.index.MeasurementCardinalityStats()
 }
 
+// OrgSeriesCardinality returns the number of series stored across all of
+// the buckets of orgID.
+func (e *Engine) OrgSeriesCardinality(orgID platform.ID) (int64, error) {
+	stats, err := e.MeasurementCardinalityStats()
+	if err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for name, c := range stats {
+		if len(name) != 16 {
+			continue
+		}
+		if org, _ := tsdb.DecodeNameSlice([]byte(name)); org == orgID {
+			n += int64(c)
+		}
+	}
+	return n, nil
+}
+
 // MeasurementStats returns the current measurement stats for the engine.
 func (e *Engine) MeasurementStats() (tsm1.MeasurementStats, error) {
 	e.mu.RLock()
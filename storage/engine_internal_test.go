@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb"
+)
+
+func TestEngine_SetStorageMode_InFlight(t *testing.T) {
+	path := MustTempDir()
+	defer os.RemoveAll(path)
+
+	ctx := context.Background()
+	e := NewEngine(path, NewConfig(), WithEngineID(rand.Int()), WithNodeID(rand.Int()))
+	if err := e.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// Hold the read lock as a long running delete does.
+	e.mu.RLock()
+	if err := e.beginModify(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Giving up on the delete restores the previous mode.
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := e.SetStorageMode(tctx, influxdb.StorageMode{ReadOnly: true}); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, exp %v", err, context.DeadlineExceeded)
+	}
+	if e.readOnly() {
+		t.Fatal("expected the previous mode to be restored")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := e.SetStorageMode(ctx, influxdb.StorageMode{ReadOnly: true})
+		done <- err
+	}()
+	for !e.readOnly() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Reads are not blocked while the delete finishes, and new writes and
+	// deletes are rejected.
+	if _, err := e.StorageMode(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.beginModify(); err != influxdb.ErrStorageReadOnly {
+		t.Fatalf("got %v, exp %v", err, influxdb.ErrStorageReadOnly)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("read-only mode entered before the delete finished: %v", err)
+	default:
+	}
+
+	e.endModify()
+	e.mu.RUnlock()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -7,14 +7,18 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/kit/check"
 	"github.com/influxdata/influxdb/kit/prom/promtest"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/storage/wal"
+	"github.com/influxdata/influxdb/toml"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -352,6 +356,233 @@ func TestEngine_DeleteBucket_Predicate(t *testing.T) {
 
 }
 
+func TestEngine_DeleteBucket_Canceled(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	err := engine.Engine.WritePoints(context.TODO(), []models.Point{models.MustNewPoint(
+		tsdb.EncodeNameString(engine.org, engine.bucket),
+		models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A delete canceled before it starts is not applied.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := engine.DeleteBucketRangePredicateWithProgress(ctx, engine.org, engine.bucket,
+		math.MinInt64, math.MaxInt64, nil, nil); err != context.Canceled {
+		t.Fatalf("got error %v, exp %v", err, context.Canceled)
+	}
+
+	var progress influxdb.DeleteProgress
+	if !progress.Cancel() {
+		t.Fatal("expected to cancel the delete")
+	}
+	if err := engine.DeleteBucketRangePredicateWithProgress(context.Background(), engine.org, engine.bucket,
+		math.MinInt64, math.MaxInt64, nil, &progress); err != context.Canceled {
+		t.Fatalf("got error %v, exp %v", err, context.Canceled)
+	}
+
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+
+	// Neither delete is replayed from the WAL.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	engine.MustOpen()
+	if got, exp := engine.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got %d series, exp %d series in index after reopening", got, exp)
+	}
+
+	// A started delete can no longer be canceled.
+	progress = influxdb.DeleteProgress{}
+	if err := engine.DeleteBucketRangePredicateWithProgress(context.Background(), engine.org, engine.bucket,
+		math.MinInt64, math.MaxInt64, nil, &progress); err != nil {
+		t.Fatal(err)
+	}
+	if progress.Cancel() {
+		t.Fatal("expected not to cancel a started delete")
+	}
+	if got, exp := engine.SeriesCardinality(), int64(0); got != exp {
+		t.Fatalf("got %d series, exp %d series in index", got, exp)
+	}
+}
+
+func TestEngine_LastValue(t *testing.T) {
+	for _, size := range []int{0, 1 << 20} {
+		t.Run(fmt.Sprintf("cache size %d", size), func(t *testing.T) {
+			c := storage.NewConfig()
+			c.LastValueCacheSize = toml.Size(size)
+			engine := NewEngine(c, rand.Int(), rand.Int())
+			defer engine.Close()
+			engine.MustOpen()
+
+			name := tsdb.EncodeName(engine.org, engine.bucket)
+			tags := models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"})
+			write := func(v float64, ts int64) {
+				t.Helper()
+				err := engine.Engine.WritePoints(context.Background(), []models.Point{models.MustNewPoint(
+					string(name[:]), tags, map[string]interface{}{"value": v}, time.Unix(0, ts),
+				)})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			last := func(start, end int64) interface{} {
+				t.Helper()
+				v, err := engine.LastValue(context.Background(), name[:], tags, "value", start, end)
+				if err != nil {
+					t.Fatal(err)
+				} else if v == nil {
+					return nil
+				}
+				return v.Value()
+			}
+
+			if got := last(math.MinInt64, math.MaxInt64); got != nil {
+				t.Fatalf("got %v before any write", got)
+			}
+
+			write(20, 20)
+			if got, exp := last(0, 100), 20.0; got != exp {
+				t.Fatalf("got %v, exp %v", got, exp)
+			}
+
+			// Backfilled values are not the latest.
+			write(10, 10)
+			if got, exp := last(0, 100), 20.0; got != exp {
+				t.Fatalf("got %v after backfill, exp %v", got, exp)
+			}
+
+			write(30, 30)
+			for _, tc := range []struct {
+				start, end int64
+				exp        interface{}
+			}{
+				{0, 100, 30.0},
+				{0, 25, 20.0},
+				{0, 15, 10.0},
+				{31, 100, nil},
+				{0, 5, nil},
+			} {
+				if got := last(tc.start, tc.end); got != tc.exp {
+					t.Fatalf("got %v in [%d, %d], exp %v", got, tc.start, tc.end, tc.exp)
+				}
+			}
+
+			// Deleting the latest value reveals the previous one.
+			if err := engine.DeleteBucketRange(context.Background(), engine.org, engine.bucket, 25, 35); err != nil {
+				t.Fatal(err)
+			}
+			if got, exp := last(0, 100), 20.0; got != exp {
+				t.Fatalf("got %v after delete, exp %v", got, exp)
+			}
+		})
+	}
+}
+
+func TestEngine_StorageMode(t *testing.T) {
+	ctx := context.Background()
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeName(engine.org, engine.bucket)
+	tags := models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu"})
+	write := func(ts int64) error {
+		return engine.Engine.WritePoints(ctx, []models.Point{models.MustNewPoint(
+			string(name[:]), tags, map[string]interface{}{"value": 1.0}, time.Unix(0, ts),
+		)})
+	}
+	walSize := func() int64 {
+		t.Helper()
+		files, err := wal.SegmentFileNames(filepath.Join(engine.path, storage.DefaultWALDirectoryName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var size int64
+		for _, f := range files {
+			fi, err := os.Stat(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			size += fi.Size()
+		}
+		return size
+	}
+
+	if err := write(10); err != nil {
+		t.Fatal(err)
+	}
+	if walSize() == 0 {
+		t.Fatal("expected the write in the WAL")
+	}
+
+	m, err := engine.SetStorageMode(ctx, influxdb.StorageMode{ReadOnly: true, PauseCompactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.ReadOnly || !m.PauseCompactions {
+		t.Fatalf("unexpected mode: %+v", m)
+	}
+
+	// Entering read-only mode flushes the WAL to TSM files.
+	if size := walSize(); size != 0 {
+		t.Fatalf("got %d bytes in the WAL after entering read-only mode", size)
+	}
+	if v, err := engine.LastValue(ctx, name[:], tags, "value", 0, 100); err != nil {
+		t.Fatal(err)
+	} else if v == nil || v.UnixNano() != 10 {
+		t.Fatalf("got %v after entering read-only mode", v)
+	}
+
+	if err := write(20); err != influxdb.ErrStorageReadOnly {
+		t.Fatalf("got %v writing in read-only mode", err)
+	}
+	if err := engine.DeleteBucketRange(ctx, engine.org, engine.bucket, 0, 100); err != influxdb.ErrStorageReadOnly {
+		t.Fatalf("got %v deleting in read-only mode", err)
+	}
+	if err := engine.DeleteBucketRangePredicate(ctx, engine.org, engine.bucket, 0, 100, nil); err != influxdb.ErrStorageReadOnly {
+		t.Fatalf("got %v deleting by predicate in read-only mode", err)
+	}
+
+	// Compactions are only paused while read-only.
+	m, err = engine.SetStorageMode(ctx, influxdb.StorageMode{PauseCompactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ReadOnly || m.PauseCompactions {
+		t.Fatalf("unexpected mode: %+v", m)
+	}
+	if err := write(20); err != nil {
+		t.Fatal(err)
+	}
+
+	// The configured mode applies when the engine is opened.
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	c := storage.NewConfig()
+	c.ReadOnly = true
+	engine.Engine = storage.NewEngine(engine.path, c, storage.WithEngineID(engine.engineID), storage.WithNodeID(engine.nodeID))
+	engine.MustOpen()
+	if m, err := engine.StorageMode(ctx); err != nil {
+		t.Fatal(err)
+	} else if !m.ReadOnly {
+		t.Fatalf("unexpected mode after open: %+v", m)
+	}
+	if err := write(30); err != influxdb.ErrStorageReadOnly {
+		t.Fatalf("got %v writing after opening read-only", err)
+	}
+}
+
 func TestEngine_OpenClose(t *testing.T) {
 	engine := NewDefaultEngine()
 	engine.MustOpen()
@@ -369,6 +600,33 @@ func TestEngine_OpenClose(t *testing.T) {
 	}
 }
 
+func TestEngine_Check(t *testing.T) {
+	engine := NewDefaultEngine()
+	defer engine.Close()
+	engine.MustOpen()
+
+	if res := engine.Check(context.Background()); res.Status != check.StatusPass || res.Message != "" {
+		t.Fatalf("unexpected check: %+v", res)
+	}
+
+	// Quarantined files are reported without failing the check.
+	bad := filepath.Join(engine.path, storage.DefaultEngineDirectoryName, "000000002-000000001.tsm.bad")
+	if err := ioutil.WriteFile(bad, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	res := engine.Check(context.Background())
+	if exp := "1 corrupt TSM file(s) quarantined: 000000002-000000001.tsm.bad"; res.Status != check.StatusPass || res.Message != exp {
+		t.Fatalf("got %+v, exp a passing check with message %q", res, exp)
+	}
+
+	if err := engine.Engine.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if res := engine.Check(context.Background()); res.Status != check.StatusFail {
+		t.Fatalf("got %+v after closing, exp a failing check", res)
+	}
+}
+
 func TestEngine_InitializeMetrics(t *testing.T) {
 	engine := NewDefaultEngine()
 
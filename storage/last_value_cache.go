@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"container/list"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/influxdb/tsdb/value"
+)
+
+// lastValueEntryOverhead approximates the memory used by an entry beyond its
+// key and value: the list element, the entry and the map bucket.
+const lastValueEntryOverhead = 128
+
+// lastValueCacheStripes is the number of generations tracked by the cache.
+const lastValueCacheStripes = 256
+
+// lastValueCache holds the latest value written to each series field key,
+// bounded by a maximum size and evicting the least recently read keys first.
+//
+// Writes only know the latest value of their own batch, not whether the TSM
+// files hold a later one, so entries created by writes are incomplete until
+// a scan of the series fills them. Only complete entries are returned by Get.
+// A fill is discarded if the key may have been evicted or invalidated since
+// the scan started, as a write between the two could otherwise be lost.
+type lastValueCache struct {
+	mu      sync.Mutex
+	maxSize uint64
+	size    uint64
+	entries map[string]*list.Element
+	lru     *list.List // of *lastValueEntry, most recently read first
+
+	// gens are incremented when keys hashing to them are evicted or
+	// invalidated.
+	gens [lastValueCacheStripes]uint64
+}
+
+type lastValueEntry struct {
+	key      string
+	value    value.Value
+	complete bool
+}
+
+func (e *lastValueEntry) size() uint64 {
+	return uint64(len(e.key) + e.value.Size() + lastValueEntryOverhead)
+}
+
+// newLastValueCache returns a cache using at most maxSize bytes.
+func newLastValueCache(maxSize uint64) *lastValueCache {
+	return &lastValueCache{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// Get returns the latest value of key, if it is known.
+func (c *lastValueCache) Get(key []byte) (value.Value, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[string(key)]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lastValueEntry)
+	if !entry.complete {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return entry.value, true
+}
+
+// Generation returns the generation of key, which must be read before
+// scanning the series for its latest value and passed to Fill.
+func (c *lastValueCache) Generation(key []byte) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gens[stripe(key)]
+}
+
+// Fill records v, read from the series by a scan started at generation gen,
+// as the latest value of key. It returns the latest value known, which is
+// later than v if it was written during the scan.
+func (c *lastValueCache) Fill(key []byte, v value.Value, gen uint64) value.Value {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gens[stripe(key)] != gen {
+		return v
+	}
+
+	if el, ok := c.entries[string(key)]; ok {
+		entry := el.Value.(*lastValueEntry)
+		if v.UnixNano() > entry.value.UnixNano() {
+			c.size -= entry.size()
+			entry.value = v
+			c.size += entry.size()
+		}
+		entry.complete = true
+		c.lru.MoveToFront(el)
+		v = entry.value
+	} else {
+		c.insert(string(key), v, true)
+	}
+	c.evict()
+	return v
+}
+
+// Add updates the cache with the values of a write, keyed by series field
+// key.
+func (c *lastValueCache) Add(values map[string][]value.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, vals := range values {
+		if len(vals) == 0 {
+			continue
+		}
+		latest := vals[0]
+		for _, v := range vals[1:] {
+			// A later value with the same timestamp overwrites an earlier one.
+			if v.UnixNano() >= latest.UnixNano() {
+				latest = v
+			}
+		}
+
+		el, ok := c.entries[key]
+		if !ok {
+			c.insert(key, latest, false)
+			continue
+		}
+		entry := el.Value.(*lastValueEntry)
+		if latest.UnixNano() >= entry.value.UnixNano() {
+			c.size -= entry.size()
+			entry.value = latest
+			c.size += entry.size()
+		}
+	}
+	c.evict()
+}
+
+// DeletePrefix removes the keys starting with prefix, whose latest values may
+// have been deleted.
+func (c *lastValueCache) DeletePrefix(prefix []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p := string(prefix)
+	for el := c.lru.Front(); el != nil; {
+		next := el.Next()
+		if entry := el.Value.(*lastValueEntry); strings.HasPrefix(entry.key, p) {
+			c.remove(el)
+		}
+		el = next
+	}
+
+	// Scans in progress may have read values deleted since.
+	for i := range c.gens {
+		c.gens[i]++
+	}
+}
+
+// Len returns the number of keys in the cache.
+func (c *lastValueCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Size returns the approximate number of bytes used by the cache.
+func (c *lastValueCache) Size() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// insert adds a new entry to the cache. Incomplete entries are added as the
+// least recently read, as they have not been read yet.
+func (c *lastValueCache) insert(key string, v value.Value, complete bool) {
+	entry := &lastValueEntry{key: key, value: v, complete: complete}
+	if complete {
+		c.entries[key] = c.lru.PushFront(entry)
+	} else {
+		c.entries[key] = c.lru.PushBack(entry)
+	}
+	c.size += entry.size()
+}
+
+// remove removes the entry of el and increments its generation.
+func (c *lastValueCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*lastValueEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size()
+	c.gens[stripe([]byte(entry.key))]++
+}
+
+// evict removes the least recently read entries until the cache fits in its
+// maximum size.
+func (c *lastValueCache) evict() {
+	for c.size > c.maxSize {
+		el := c.lru.Back()
+		if el == nil {
+			return
+		}
+		c.remove(el)
+	}
+}
+
+// stripe returns the index of the generation of key.
+func stripe(key []byte) int {
+	h := fnv.New32a()
+	h.Write(key)
+	return int(h.Sum32() % lastValueCacheStripes)
+}
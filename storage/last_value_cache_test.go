@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/value"
+)
+
+func TestLastValueCache(t *testing.T) {
+	c := newLastValueCache(1 << 20)
+	key := []byte("cpu,host=a#!~#value")
+
+	// A write alone does not know whether a later value was written before.
+	c.Add(map[string][]value.Value{string(key): {value.NewFloatValue(2, 2), value.NewFloatValue(1, 1)}})
+	if _, ok := c.Get(key); ok {
+		t.Fatal("got value of incomplete entry")
+	}
+
+	// A scan fills the entry, keeping the later value of the write.
+	gen := c.Generation(key)
+	if got := c.Fill(key, value.NewFloatValue(1, 1), gen); got.UnixNano() != 2 {
+		t.Fatalf("filled %v, exp time 2", got)
+	}
+	if v, ok := c.Get(key); !ok || v.UnixNano() != 2 {
+		t.Fatalf("got %v, %v, exp time 2", v, ok)
+	}
+
+	// Earlier writes are ignored and later ones replace the value.
+	c.Add(map[string][]value.Value{string(key): {value.NewFloatValue(0, 0)}})
+	c.Add(map[string][]value.Value{string(key): {value.NewFloatValue(3, 3)}})
+	if v, ok := c.Get(key); !ok || v.UnixNano() != 3 || v.Value() != 3.0 {
+		t.Fatalf("got %v, %v, exp 3", v, ok)
+	}
+
+	// Deletes invalidate the entries of the prefix and any scan in progress.
+	other := []byte("mem,host=a#!~#value")
+	gen = c.Generation(other)
+	c.DeletePrefix([]byte("cpu"))
+	if _, ok := c.Get(key); ok {
+		t.Fatal("got value of deleted prefix")
+	}
+	c.Fill(other, value.NewFloatValue(1, 1), gen)
+	if _, ok := c.Get(other); ok {
+		t.Fatal("got value filled by scan started before delete")
+	}
+	if got, exp := c.Len(), 0; got != exp {
+		t.Fatalf("got %d entries, exp %d", got, exp)
+	}
+}
+
+func TestLastValueCache_Evict(t *testing.T) {
+	v := value.NewFloatValue(1, 1)
+	keys := [][]byte{[]byte("a#!~#value"), []byte("b#!~#value"), []byte("c#!~#value")}
+	entrySize := uint64(len(keys[0]) + v.Size() + lastValueEntryOverhead)
+
+	c := newLastValueCache(2 * entrySize)
+	c.Fill(keys[0], v, c.Generation(keys[0]))
+	c.Fill(keys[1], v, c.Generation(keys[1]))
+
+	// Reading a makes b the least recently read.
+	if _, ok := c.Get(keys[0]); !ok {
+		t.Fatal("expected a")
+	}
+	c.Fill(keys[2], v, c.Generation(keys[2]))
+
+	if _, ok := c.Get(keys[1]); ok {
+		t.Fatal("b not evicted")
+	}
+	for _, key := range [][]byte{keys[0], keys[2]} {
+		if _, ok := c.Get(key); !ok {
+			t.Fatalf("%s evicted", key)
+		}
+	}
+	if got, exp := c.Size(), 2*entrySize; got != exp {
+		t.Fatalf("got size %d, exp %d", got, exp)
+	}
+
+	// Entries created by writes are evicted before those read.
+	c.Add(map[string][]value.Value{"d#!~#value": {v}})
+	if got, exp := c.Len(), 2; got != exp {
+		t.Fatalf("got %d entries, exp %d", got, exp)
+	}
+	if _, ok := c.Get(keys[0]); !ok {
+		t.Fatal("a evicted by write")
+	}
+}
@@ -12,6 +12,7 @@ import (
 // monitored within the same process.
 var (
 	rms *retentionMetrics
+	sms *seriesLimitMetrics
 	mmu sync.RWMutex
 )
 
@@ -27,10 +28,23 @@ func RetentionPrometheusCollectors() []prometheus.Collector {
 	return collectors
 }
 
+// SeriesLimitPrometheusCollectors returns all prometheus metrics for series limits.
+func SeriesLimitPrometheusCollectors() []prometheus.Collector {
+	mmu.RLock()
+	defer mmu.RUnlock()
+
+	var collectors []prometheus.Collector
+	if sms != nil {
+		collectors = append(collectors, sms.PrometheusCollectors()...)
+	}
+	return collectors
+}
+
 // namespace is the leading part of all published metrics for the Storage service.
 const namespace = "storage"
 
-const retentionSubsystem = "retention" // sub-system associated with metrics for writing points.
+const retentionSubsystem = "retention"      // sub-system associated with metrics for writing points.
+const seriesLimitSubsystem = "series_limit" // sub-system associated with metrics for series limits.
 
 // retentionMetrics is a set of metrics concerned with tracking data about retention policies.
 type retentionMetrics struct {
@@ -88,3 +102,36 @@ func (rm *retentionMetrics) PrometheusCollectors() []prometheus.Collector {
 		rm.CheckDuration,
 	}
 }
+
+// seriesLimitMetrics is a set of metrics concerned with points rejected by
+// series limits.
+type seriesLimitMetrics struct {
+	labels         prometheus.Labels
+	RejectedPoints *prometheus.CounterVec
+}
+
+func newSeriesLimitMetrics(labels prometheus.Labels) *seriesLimitMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	names = append(names, "limit")
+	sort.Strings(names)
+
+	return &seriesLimitMetrics{
+		labels: labels,
+		RejectedPoints: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: seriesLimitSubsystem,
+			Name:      "rejected_points_total",
+			Help:      "Number of points rejected because they would create a series beyond a bucket or measurement limit.",
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *seriesLimitMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.RejectedPoints,
+	}
+}
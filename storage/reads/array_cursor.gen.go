@@ -237,6 +237,63 @@ func (c *integerFloatCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type floatArrayLastCursor struct {
+	cursors.FloatArrayCursor
+	ts  [1]int64
+	vs  [1]float64
+	res cursors.FloatArray
+}
+
+func (c *floatArrayLastCursor) Stats() cursors.CursorStats {
+	return c.FloatArrayCursor.Stats()
+}
+
+func (c *floatArrayLastCursor) Next() *cursors.FloatArray {
+	a := c.FloatArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		n := len(a.Timestamps)
+		c.ts[0] = a.Timestamps[n-1]
+		c.vs[0] = a.Values[n-1]
+		a = c.FloatArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return &c.res
+		}
+	}
+}
+
+type floatValueArrayCursor struct {
+	ts   [1]int64
+	vs   [1]float64
+	res  cursors.FloatArray
+	done bool
+}
+
+func newFloatValueArrayCursor(t int64, v float64) *floatValueArrayCursor {
+	c := &floatValueArrayCursor{}
+	c.ts[0], c.vs[0] = t, v
+	return c
+}
+
+func (c *floatValueArrayCursor) Err() error                 { return nil }
+func (c *floatValueArrayCursor) Close()                     {}
+func (c *floatValueArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func (c *floatValueArrayCursor) Next() *cursors.FloatArray {
+	if c.done {
+		c.res.Timestamps, c.res.Values = nil, nil
+	} else {
+		c.res.Timestamps, c.res.Values = c.ts[:], c.vs[:]
+		c.done = true
+	}
+	return &c.res
+}
+
 type floatEmptyArrayCursor struct {
 	res cursors.FloatArray
 }
@@ -466,6 +523,63 @@ func (c *integerIntegerCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type integerArrayLastCursor struct {
+	cursors.IntegerArrayCursor
+	ts  [1]int64
+	vs  [1]int64
+	res cursors.IntegerArray
+}
+
+func (c *integerArrayLastCursor) Stats() cursors.CursorStats {
+	return c.IntegerArrayCursor.Stats()
+}
+
+func (c *integerArrayLastCursor) Next() *cursors.IntegerArray {
+	a := c.IntegerArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		n := len(a.Timestamps)
+		c.ts[0] = a.Timestamps[n-1]
+		c.vs[0] = a.Values[n-1]
+		a = c.IntegerArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return &c.res
+		}
+	}
+}
+
+type integerValueArrayCursor struct {
+	ts   [1]int64
+	vs   [1]int64
+	res  cursors.IntegerArray
+	done bool
+}
+
+func newIntegerValueArrayCursor(t int64, v int64) *integerValueArrayCursor {
+	c := &integerValueArrayCursor{}
+	c.ts[0], c.vs[0] = t, v
+	return c
+}
+
+func (c *integerValueArrayCursor) Err() error                 { return nil }
+func (c *integerValueArrayCursor) Close()                     {}
+func (c *integerValueArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func (c *integerValueArrayCursor) Next() *cursors.IntegerArray {
+	if c.done {
+		c.res.Timestamps, c.res.Values = nil, nil
+	} else {
+		c.res.Timestamps, c.res.Values = c.ts[:], c.vs[:]
+		c.done = true
+	}
+	return &c.res
+}
+
 type integerEmptyArrayCursor struct {
 	res cursors.IntegerArray
 }
@@ -695,6 +809,63 @@ func (c *integerUnsignedCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type unsignedArrayLastCursor struct {
+	cursors.UnsignedArrayCursor
+	ts  [1]int64
+	vs  [1]uint64
+	res cursors.UnsignedArray
+}
+
+func (c *unsignedArrayLastCursor) Stats() cursors.CursorStats {
+	return c.UnsignedArrayCursor.Stats()
+}
+
+func (c *unsignedArrayLastCursor) Next() *cursors.UnsignedArray {
+	a := c.UnsignedArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		n := len(a.Timestamps)
+		c.ts[0] = a.Timestamps[n-1]
+		c.vs[0] = a.Values[n-1]
+		a = c.UnsignedArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return &c.res
+		}
+	}
+}
+
+type unsignedValueArrayCursor struct {
+	ts   [1]int64
+	vs   [1]uint64
+	res  cursors.UnsignedArray
+	done bool
+}
+
+func newUnsignedValueArrayCursor(t int64, v uint64) *unsignedValueArrayCursor {
+	c := &unsignedValueArrayCursor{}
+	c.ts[0], c.vs[0] = t, v
+	return c
+}
+
+func (c *unsignedValueArrayCursor) Err() error                 { return nil }
+func (c *unsignedValueArrayCursor) Close()                     {}
+func (c *unsignedValueArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func (c *unsignedValueArrayCursor) Next() *cursors.UnsignedArray {
+	if c.done {
+		c.res.Timestamps, c.res.Values = nil, nil
+	} else {
+		c.res.Timestamps, c.res.Values = c.ts[:], c.vs[:]
+		c.done = true
+	}
+	return &c.res
+}
+
 type unsignedEmptyArrayCursor struct {
 	res cursors.UnsignedArray
 }
@@ -884,6 +1055,63 @@ func (c *integerStringCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type stringArrayLastCursor struct {
+	cursors.StringArrayCursor
+	ts  [1]int64
+	vs  [1]string
+	res cursors.StringArray
+}
+
+func (c *stringArrayLastCursor) Stats() cursors.CursorStats {
+	return c.StringArrayCursor.Stats()
+}
+
+func (c *stringArrayLastCursor) Next() *cursors.StringArray {
+	a := c.StringArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		n := len(a.Timestamps)
+		c.ts[0] = a.Timestamps[n-1]
+		c.vs[0] = a.Values[n-1]
+		a = c.StringArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return &c.res
+		}
+	}
+}
+
+type stringValueArrayCursor struct {
+	ts   [1]int64
+	vs   [1]string
+	res  cursors.StringArray
+	done bool
+}
+
+func newStringValueArrayCursor(t int64, v string) *stringValueArrayCursor {
+	c := &stringValueArrayCursor{}
+	c.ts[0], c.vs[0] = t, v
+	return c
+}
+
+func (c *stringValueArrayCursor) Err() error                 { return nil }
+func (c *stringValueArrayCursor) Close()                     {}
+func (c *stringValueArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func (c *stringValueArrayCursor) Next() *cursors.StringArray {
+	if c.done {
+		c.res.Timestamps, c.res.Values = nil, nil
+	} else {
+		c.res.Timestamps, c.res.Values = c.ts[:], c.vs[:]
+		c.done = true
+	}
+	return &c.res
+}
+
 type stringEmptyArrayCursor struct {
 	res cursors.StringArray
 }
@@ -1073,6 +1301,63 @@ func (c *integerBooleanCountArrayCursor) Next() *cursors.IntegerArray {
 	}
 }
 
+type booleanArrayLastCursor struct {
+	cursors.BooleanArrayCursor
+	ts  [1]int64
+	vs  [1]bool
+	res cursors.BooleanArray
+}
+
+func (c *booleanArrayLastCursor) Stats() cursors.CursorStats {
+	return c.BooleanArrayCursor.Stats()
+}
+
+func (c *booleanArrayLastCursor) Next() *cursors.BooleanArray {
+	a := c.BooleanArrayCursor.Next()
+	if len(a.Timestamps) == 0 {
+		return a
+	}
+
+	for {
+		n := len(a.Timestamps)
+		c.ts[0] = a.Timestamps[n-1]
+		c.vs[0] = a.Values[n-1]
+		a = c.BooleanArrayCursor.Next()
+		if len(a.Timestamps) == 0 {
+			c.res.Timestamps = c.ts[:]
+			c.res.Values = c.vs[:]
+			return &c.res
+		}
+	}
+}
+
+type booleanValueArrayCursor struct {
+	ts   [1]int64
+	vs   [1]bool
+	res  cursors.BooleanArray
+	done bool
+}
+
+func newBooleanValueArrayCursor(t int64, v bool) *booleanValueArrayCursor {
+	c := &booleanValueArrayCursor{}
+	c.ts[0], c.vs[0] = t, v
+	return c
+}
+
+func (c *booleanValueArrayCursor) Err() error                 { return nil }
+func (c *booleanValueArrayCursor) Close()                     {}
+func (c *booleanValueArrayCursor) Stats() cursors.CursorStats { return cursors.CursorStats{} }
+
+func (c *booleanValueArrayCursor) Next() *cursors.BooleanArray {
+	if c.done {
+		c.res.Timestamps, c.res.Values = nil, nil
+	} else {
+		c.res.Timestamps, c.res.Values = c.ts[:], c.vs[:]
+		c.done = true
+	}
+	return &c.res
+}
+
 type booleanEmptyArrayCursor struct {
 	res cursors.BooleanArray
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxdb/tsdb/value"
 )
 
 type singleValue struct {
@@ -63,6 +64,41 @@ func newCountArrayCursor(cur cursors.Cursor) cursors.Cursor {
 	}
 }
 
+func newLastArrayCursor(cur cursors.Cursor) cursors.Cursor {
+	switch cur := cur.(type) {
+	case cursors.FloatArrayCursor:
+		return &floatArrayLastCursor{FloatArrayCursor: cur}
+	case cursors.IntegerArrayCursor:
+		return &integerArrayLastCursor{IntegerArrayCursor: cur}
+	case cursors.UnsignedArrayCursor:
+		return &unsignedArrayLastCursor{UnsignedArrayCursor: cur}
+	case cursors.StringArrayCursor:
+		return &stringArrayLastCursor{StringArrayCursor: cur}
+	case cursors.BooleanArrayCursor:
+		return &booleanArrayLastCursor{BooleanArrayCursor: cur}
+	default:
+		panic(fmt.Sprintf("unreachable: %T", cur))
+	}
+}
+
+// newValueArrayCursor returns a cursor producing the single value v.
+func newValueArrayCursor(v value.Value) cursors.Cursor {
+	switch v := v.(type) {
+	case value.FloatValue:
+		return newFloatValueArrayCursor(v.UnixNano(), v.RawValue())
+	case value.IntegerValue:
+		return newIntegerValueArrayCursor(v.UnixNano(), v.RawValue())
+	case value.UnsignedValue:
+		return newUnsignedValueArrayCursor(v.UnixNano(), v.RawValue())
+	case value.StringValue:
+		return newStringValueArrayCursor(v.UnixNano(), v.RawValue())
+	case value.BooleanValue:
+		return newBooleanValueArrayCursor(v.UnixNano(), v.RawValue())
+	default:
+		panic(fmt.Sprintf("unreachable: %T", v))
+	}
+}
+
 type cursorContext struct {
 	ctx   context.Context
 	req   *cursors.CursorRequest
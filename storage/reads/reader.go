@@ -41,6 +41,17 @@ func (r *storeReader) ReadFilter(ctx context.Context, spec influxdb.ReadFilterSp
 	}, nil
 }
 
+func (r *storeReader) ReadLast(ctx context.Context, spec influxdb.ReadFilterSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
+	return &filterIterator{
+		ctx:   ctx,
+		s:     r.s,
+		spec:  spec,
+		last:  true,
+		cache: newTagsCache(0),
+		alloc: alloc,
+	}, nil
+}
+
 func (r *storeReader) ReadGroup(ctx context.Context, spec influxdb.ReadGroupSpec, alloc *memory.Allocator) (influxdb.TableIterator, error) {
 	return &groupIterator{
 		ctx:   ctx,
@@ -97,6 +108,7 @@ type filterIterator struct {
 	ctx   context.Context
 	s     Store
 	spec  influxdb.ReadFilterSpec
+	last  bool // read only the latest value of each series
 	stats cursors.CursorStats
 	cache *tagsCache
 	alloc *memory.Allocator
@@ -131,7 +143,12 @@ func (fi *filterIterator) Do(f func(flux.Table) error) error {
 	req.Range.Start = int64(fi.spec.Bounds.Start)
 	req.Range.End = int64(fi.spec.Bounds.Stop)
 
-	rs, err := fi.s.ReadFilter(fi.ctx, &req)
+	var rs ResultSet
+	if fi.last {
+		rs, err = fi.s.ReadLast(fi.ctx, &req)
+	} else {
+		rs, err = fi.s.ReadFilter(fi.ctx, &req)
+	}
 	if err != nil {
 		return err
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/influxdata/influxdb/tsdb/value"
 )
 
 type multiShardCursors interface {
@@ -72,3 +73,64 @@ func (r *resultSet) Tags() models.Tags {
 // Stats returns the stats for the underlying cursors.
 // Available after resultset has been scanned.
 func (r *resultSet) Stats() cursors.CursorStats { return r.row.Query.Stats() }
+
+// LastValuer reads the latest value of series fields.
+type LastValuer interface {
+	// LastValue returns the value with the greatest timestamp within
+	// [start, end] of the field of the series identified by name and tags,
+	// or nil if there is none.
+	LastValue(ctx context.Context, name []byte, tags models.Tags, field string, start, end int64) (value.Value, error)
+}
+
+type lastResultSet struct {
+	resultSet
+	last       LastValuer
+	start, end int64
+	err        error
+}
+
+// NewLastResultSet returns a ResultSet whose cursors produce only the latest
+// value of each series within the range of req, read from last.
+func NewLastResultSet(ctx context.Context, req *datatypes.ReadFilterRequest, cur SeriesCursor, last LastValuer) ResultSet {
+	return &lastResultSet{
+		resultSet: resultSet{
+			ctx: ctx,
+			cur: cur,
+			mb:  newMultiShardArrayCursors(ctx, req.Range.Start, req.Range.End, true, math.MaxInt64),
+		},
+		last:  last,
+		start: req.Range.Start,
+		end:   req.Range.End,
+	}
+}
+
+func (r *lastResultSet) Err() error { return r.err }
+
+func (r *lastResultSet) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	return r.resultSet.Next()
+}
+
+func (r *lastResultSet) Cursor() cursors.Cursor {
+	if r.row.ValueCond != nil {
+		// The latest value may not match the condition, so every value
+		// must be read.
+		cur := r.mb.createCursor(r.row)
+		if cur == nil {
+			return nil
+		}
+		return newLastArrayCursor(cur)
+	}
+
+	v, err := r.last.LastValue(r.ctx, r.row.Name, r.row.SeriesTags, r.row.Field, r.start, r.end)
+	if err != nil {
+		r.err = err
+		return nil
+	}
+	if v == nil {
+		return nil
+	}
+	return newValueArrayCursor(v)
+}
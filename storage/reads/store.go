@@ -77,6 +77,10 @@ type Store interface {
 	ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest) (ResultSet, error)
 	ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest) (GroupResultSet, error)
 
+	// ReadLast is ReadFilter, with cursors producing only the latest value
+	// of each series.
+	ReadLast(ctx context.Context, req *datatypes.ReadFilterRequest) (ResultSet, error)
+
 	TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cursors.StringIterator, error)
 	TagValues(ctx context.Context, req *datatypes.TagValuesRequest) (cursors.StringIterator, error)
 
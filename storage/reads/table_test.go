@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -22,9 +23,75 @@ import (
 	"github.com/influxdata/influxdb/storage"
 	"github.com/influxdata/influxdb/storage/reads"
 	"github.com/influxdata/influxdb/storage/readservice"
+	"github.com/influxdata/influxdb/tsdb"
 	"go.uber.org/zap/zaptest"
 )
 
+func TestStoreReader_ReadLast(t *testing.T) {
+	rootDir, err := ioutil.TempDir("", "storage-reads-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(rootDir) }()
+
+	config := storage.NewConfig()
+	config.LastValueCacheSize = 1 << 20
+	engine := storage.NewEngine(rootDir, config)
+	engine.WithLogger(zaptest.NewLogger(t))
+	if err := engine.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	idgen := mock.NewMockIDGenerator()
+	orgID, bucketID := idgen.ID(), idgen.ID()
+	name := tsdb.EncodeNameString(orgID, bucketID)
+	var points []models.Point
+	for _, host := range []string{"a", "b"} {
+		for i := int64(1); i <= 3; i++ {
+			tags := models.NewTags(map[string]string{models.MeasurementTagKey: "cpu", models.FieldKeyTagKey: "f0", "host": host})
+			points = append(points, models.MustNewPoint(name, tags, map[string]interface{}{"f0": float64(i)}, time.Unix(0, i)))
+		}
+	}
+	if err := engine.WritePoints(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := reads.NewReader(readservice.NewStore(engine))
+	for _, tc := range []struct {
+		stop values.Time
+		exp  []float64
+	}{
+		{stop: 10, exp: []float64{3, 3}},
+		{stop: 2, exp: []float64{2, 2}},
+	} {
+		tables, err := reader.ReadLast(context.Background(), influxdb.ReadFilterSpec{
+			OrganizationID: orgID,
+			BucketID:       bucketID,
+			Bounds:         execute.Bounds{Start: 0, Stop: tc.stop},
+		}, &memory.Allocator{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []float64
+		if err := tables.Do(func(table flux.Table) error {
+			return table.Do(func(cr flux.ColReader) error {
+				idx := execute.ColIdx(execute.DefaultValueColLabel, cr.Cols())
+				for i := 0; i < cr.Len(); i++ {
+					got = append(got, cr.Floats(idx).Value(i))
+				}
+				return nil
+			})
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tc.exp) {
+			t.Fatalf("got %v with stop %d, exp %v", got, tc.stop, tc.exp)
+		}
+	}
+}
+
 func BenchmarkReadFilter(b *testing.B) {
 	idgen := mock.NewMockIDGenerator()
 	tagsSpec := &gen.TagsSpec{
@@ -9,6 +9,7 @@ import (
 	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -29,6 +30,12 @@ type Snapshotter interface {
 	WriteSnapshot(ctx context.Context, status tsm1.CacheStatus) error
 }
 
+// A Partitioner splits the data of buckets into time partitions, keyed by the
+// encoded bucket name, so that expired data can be removed in whole files.
+type Partitioner interface {
+	SetPartitionDurations(durations map[string]time.Duration)
+}
+
 // A BucketFinder is responsible for providing access to buckets via a filter.
 type BucketFinder interface {
 	FindBuckets(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error)
@@ -45,6 +52,10 @@ type retentionEnforcer struct {
 
 	Snapshotter Snapshotter
 
+	// Partitioner is given the partition durations of the buckets on every
+	// check, if set.
+	Partitioner Partitioner
+
 	// BucketService provides an API for retrieving buckets associated with
 	// organisations.
 	BucketService BucketFinder
@@ -108,11 +119,43 @@ func (s *retentionEnforcer) run() {
 	if err != nil {
 		log.Error("Unable to determine bucket information", zap.Error(err))
 	} else {
+		s.setPartitions(buckets)
 		s.expireData(ctx, buckets, now)
 	}
 	s.tracker.CheckDuration(time.Since(now), err == nil)
 }
 
+// refreshPartitions sets the partition durations of all buckets on the
+// Partitioner, so that data written before the first check is partitioned.
+func (s *retentionEnforcer) refreshPartitions() {
+	if s == nil || s.Partitioner == nil {
+		return
+	}
+
+	buckets, err := s.getBucketInformation(context.Background())
+	if err != nil {
+		s.logger.Warn("Unable to determine bucket partitions", zap.Error(err))
+		return
+	}
+	s.setPartitions(buckets)
+}
+
+// setPartitions sets the partition durations of buckets on the Partitioner.
+func (s *retentionEnforcer) setPartitions(buckets []*influxdb.Bucket) {
+	if s.Partitioner == nil {
+		return
+	}
+
+	durations := make(map[string]time.Duration)
+	for _, b := range buckets {
+		if b.PartitionDuration <= 0 || !b.OrgID.Valid() || !b.ID.Valid() {
+			continue
+		}
+		durations[tsdb.EncodeNameString(b.OrgID, b.ID)] = b.PartitionDuration
+	}
+	s.Partitioner.SetPartitionDurations(durations)
+}
+
 // expireData runs a delete operation on the storage engine.
 //
 // Any series data that (1) belongs to a bucket in the provided list and
@@ -240,6 +240,28 @@ func TestRetentionService(t *testing.T) {
 	})
 }
 
+func TestRetentionService_Partitions(t *testing.T) {
+	t.Parallel()
+	finder := NewTestBucketFinder()
+	finder.FindBucketsFn = func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+		return []*influxdb.Bucket{
+			{OrgID: 1, ID: 2, RetentionPeriod: 24 * time.Hour, PartitionDuration: time.Hour},
+			{OrgID: 1, ID: 3, RetentionPeriod: 24 * time.Hour},
+			{OrgID: 1, ID: 4},
+		}, 3, nil
+	}
+
+	var partitioner TestPartitioner
+	service := newRetentionEnforcer(NewTestEngine(), &TestSnapshotter{}, finder)
+	service.Partitioner = &partitioner
+	service.run()
+
+	exp := map[string]time.Duration{tsdb.EncodeNameString(1, 2): time.Hour}
+	if !reflect.DeepEqual(partitioner.durations, exp) {
+		t.Fatalf("got partitions %v, expected %v", partitioner.durations, exp)
+	}
+}
+
 func TestMetrics_Retention(t *testing.T) {
 	t.Parallel()
 	// metrics to be shared by multiple file stores.
@@ -333,6 +355,14 @@ func (s *TestSnapshotter) WriteSnapshot(ctx context.Context, status tsm1.CacheSt
 	return nil
 }
 
+type TestPartitioner struct {
+	durations map[string]time.Duration
+}
+
+func (p *TestPartitioner) SetPartitionDurations(durations map[string]time.Duration) {
+	p.durations = durations
+}
+
 type TestBucketFinder struct {
 	FindBucketsFn func(context.Context, influxdb.BucketFilter, ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error)
 }
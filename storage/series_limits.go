@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// applySeriesLimits drops the points of collection that would create a new
+// series in a bucket or measurement that has reached its series limit.
+// Points written to existing series are always kept. Writes are not
+// serialized, so concurrent writes may together exceed a limit by the new
+// series they are creating.
+func (e *Engine) applySeriesLimits(collection *tsdb.SeriesCollection, dropPoint func(key []byte, reason string)) error {
+	maxBucket := int64(e.config.TSDB.MaxSeriesPerBucket)
+	maxMeasurement := int64(e.config.TSDB.MaxSeriesPerMeasurement)
+	if maxBucket <= 0 && maxMeasurement <= 0 {
+		return nil
+	}
+
+	type measurementKey struct{ name, measurement string }
+	var (
+		bucketN      = make(map[string]int64)
+		measurementN = make(map[measurementKey]int64)
+		created      = make(map[string]struct{}) // new series accepted from this batch
+		buf          []byte
+		j            int
+	)
+
+	for iter := collection.Iterator(); iter.Next(); {
+		name, tags := iter.Name(), iter.Tags()
+
+		if _, ok := created[string(iter.Key())]; ok {
+			collection.Copy(j, iter.Index())
+			j++
+			continue
+		}
+		buf = tsdb.AppendSeriesKey(buf[:0], name, tags)
+		if !e.sfile.SeriesIDTypedBySeriesKey(buf).SeriesID().IsZero() {
+			collection.Copy(j, iter.Index())
+			j++
+			continue
+		}
+
+		// The measurement tag is always the first tag of a valid point.
+		mkey := measurementKey{name: string(name), measurement: string(tags[0].Value)}
+
+		if maxBucket > 0 {
+			n, ok := bucketN[mkey.name]
+			if !ok {
+				var err error
+				if n, err = e.index.MeasurementCardinality(name); err != nil {
+					return err
+				}
+			}
+			if n >= maxBucket {
+				bucketN[mkey.name] = n
+				e.seriesLimitTracker.IncRejected("bucket")
+				dropPoint(iter.Key(), fmt.Sprintf("max-series-per-bucket limit exceeded: bucket %s has %d series, the limit is %d",
+					bucketIDString(name), n, maxBucket))
+				continue
+			}
+			bucketN[mkey.name] = n
+		}
+
+		if maxMeasurement > 0 {
+			n, ok := measurementN[mkey]
+			if !ok {
+				var err error
+				if n, err = e.index.TagValueCardinality(name, models.MeasurementTagKeyBytes, tags[0].Value); err != nil {
+					return err
+				}
+			}
+			measurementN[mkey] = n
+			if n >= maxMeasurement {
+				e.seriesLimitTracker.IncRejected("measurement")
+				dropPoint(iter.Key(), fmt.Sprintf("max-series-per-measurement limit exceeded: measurement %q in bucket %s has %d series, the limit is %d",
+					mkey.measurement, bucketIDString(name), n, maxMeasurement))
+				continue
+			}
+		}
+
+		bucketN[mkey.name]++
+		measurementN[mkey]++
+		created[string(iter.Key())] = struct{}{}
+		collection.Copy(j, iter.Index())
+		j++
+	}
+	collection.Truncate(j)
+	return nil
+}
+
+// bucketIDString returns the bucket ID encoded in the name of a series.
+func bucketIDString(name []byte) string {
+	if len(name) != 16 {
+		return fmt.Sprintf("%q", name)
+	}
+	_, bucketID := tsdb.DecodeNameSlice(name)
+	return bucketID.String()
+}
+
+// seriesLimitTracker tracks the points rejected by series limits.
+type seriesLimitTracker struct {
+	metrics *seriesLimitMetrics
+	labels  prometheus.Labels
+}
+
+func newSeriesLimitTracker(metrics *seriesLimitMetrics, defaultLabels prometheus.Labels) *seriesLimitTracker {
+	return &seriesLimitTracker{metrics: metrics, labels: defaultLabels}
+}
+
+// Labels returns a copy of labels for use with series limit metrics.
+func (t *seriesLimitTracker) Labels() prometheus.Labels {
+	l := make(map[string]string, len(t.labels))
+	for k, v := range t.labels {
+		l[k] = v
+	}
+	return l
+}
+
+// IncRejected signals that a point was rejected by the bucket or measurement
+// limit.
+func (t *seriesLimitTracker) IncRejected(limit string) {
+	labels := t.Labels()
+	labels["limit"] = limit
+	t.metrics.RejectedPoints.With(labels).Inc()
+}
@@ -0,0 +1,88 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func TestEngine_WritePoints_SeriesLimits(t *testing.T) {
+	c := storage.NewConfig()
+	c.TSDB.MaxSeriesPerBucket = 3
+	c.TSDB.MaxSeriesPerMeasurement = 2
+	engine := NewEngine(c, rand.Int(), rand.Int())
+	defer engine.Close()
+	engine.MustOpen()
+
+	name := tsdb.EncodeNameString(engine.org, engine.bucket)
+	point := func(m, host string, ts int64) models.Point {
+		return models.MustNewPoint(
+			name,
+			models.NewTags(map[string]string{models.MeasurementTagKey: m, "host": host, models.FieldKeyTagKey: "value"}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, ts),
+		)
+	}
+	cardinality := func(m string) int64 {
+		t.Helper()
+		stats, err := engine.BucketStats(context.Background(), engine.org, engine.bucket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m == "" {
+			return stats.SeriesCardinality
+		}
+		for _, ms := range stats.Measurements {
+			if ms.Name == m {
+				return ms.SeriesCardinality
+			}
+		}
+		return 0
+	}
+
+	// The third cpu series exceeds the measurement limit, while the points
+	// for series created earlier in the batch are accepted.
+	err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("cpu", "a", 1),
+		point("cpu", "b", 1),
+		point("cpu", "a", 2),
+		point("cpu", "c", 1),
+	})
+	var pwe tsdb.PartialWriteError
+	if !errors.As(err, &pwe) {
+		t.Fatalf("got error %v, exp partial write error", err)
+	}
+	if pwe.Dropped != 1 || string(pwe.DroppedKeys[0]) != string(point("cpu", "c", 1).Key()) {
+		t.Fatalf("got %d dropped points %q, exp cpu,host=c", pwe.Dropped, pwe.DroppedKeys)
+	}
+	if got, exp := cardinality("cpu"), int64(2); got != exp {
+		t.Fatalf("got %d cpu series, exp %d", got, exp)
+	}
+
+	// Writes to existing series are still accepted.
+	if err := engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("cpu", "a", 3),
+		point("cpu", "b", 3),
+		point("mem", "a", 3),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The bucket limit rejects new series of any measurement.
+	err = engine.Engine.WritePoints(context.Background(), []models.Point{
+		point("mem", "a", 4),
+		point("disk", "a", 4),
+	})
+	if !errors.As(err, &pwe) || pwe.Dropped != 1 {
+		t.Fatalf("got error %v, exp one dropped point", err)
+	}
+	if got, exp := cardinality(""), int64(3); got != exp {
+		t.Fatalf("got %d series, exp %d", got, exp)
+	}
+}
@@ -0,0 +1,30 @@
+package influxdb
+
+import "context"
+
+// ErrStorageReadOnly is returned when data is written to or deleted from a
+// storage engine in read-only mode.
+var ErrStorageReadOnly = &Error{
+	Code: EUnavailable,
+	Msg:  "storage engine is read-only",
+}
+
+// StorageMode is the mode of the storage engine.
+type StorageMode struct {
+	// ReadOnly rejects writes and deletes while still serving queries.
+	ReadOnly bool `json:"readOnly"`
+	// PauseCompactions stops compactions while read-only, so TSM files are
+	// not changed while they are copied. It is ignored when not read-only.
+	PauseCompactions bool `json:"pauseCompactions,omitempty"`
+}
+
+// StorageModeService reads and changes the mode of the storage engine.
+type StorageModeService interface {
+	// StorageMode returns the current mode of the storage engine.
+	StorageMode(ctx context.Context) (*StorageMode, error)
+
+	// SetStorageMode changes the mode of the storage engine. Entering
+	// read-only mode snapshots the cache, so that the WAL holds no data
+	// not also held by TSM files.
+	SetStorageMode(ctx context.Context, m StorageMode) (*StorageMode, error)
+}
@@ -378,6 +378,7 @@ func (w *worker) executeQuery(p *promise) {
 			AST: pkg,
 			Now: sf,
 		},
+		Priority: query.PriorityTask,
 	}
 	req.WithReturnNoContent(true)
 	ctx = icontext.SetAuthorizer(ctx, p.task.Authorization)
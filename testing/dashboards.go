@@ -1928,6 +1928,116 @@ func UpdateDashboardCellView(
 				},
 			},
 		},
+		{
+			name: "update view query params",
+			fields: DashboardFields{
+				Dashboards: []*platform.Dashboard{
+					{
+						ID:             1,
+						OrganizationID: 1,
+						Name:           "dashboard1",
+						Cells: []*platform.Cell{
+							{
+								ID: 100,
+							},
+						},
+					},
+				},
+			},
+			args: args{
+				dashboardID: 1,
+				cellID:      100,
+				properties: platform.TableViewProperties{
+					Type: "table",
+					Queries: []platform.DashboardQuery{{
+						Text:   "from(bucket: params.bucket)",
+						Params: map[string]interface{}{"bucket": "b"},
+					}},
+				},
+			},
+			wants: wants{
+				view: &platform.View{
+					ViewContents: platform.ViewContents{
+						ID: 100,
+					},
+					Properties: platform.TableViewProperties{
+						Type: "table",
+						Queries: []platform.DashboardQuery{{
+							Text:   "from(bucket: params.bucket)",
+							Params: map[string]interface{}{"bucket": "b"},
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "update view with a query param that is not an identifier",
+			fields: DashboardFields{
+				Dashboards: []*platform.Dashboard{
+					{
+						ID:             1,
+						OrganizationID: 1,
+						Name:           "dashboard1",
+						Cells: []*platform.Cell{
+							{
+								ID: 100,
+							},
+						},
+					},
+				},
+			},
+			args: args{
+				dashboardID: 1,
+				cellID:      100,
+				properties: platform.TableViewProperties{
+					Type: "table",
+					Queries: []platform.DashboardQuery{{
+						Text:   "from(bucket: params.bucket)",
+						Params: map[string]interface{}{"not-an-identifier": "b"},
+					}},
+				},
+			},
+			wants: wants{
+				err: &platform.Error{
+					Code: platform.EInvalid,
+					Msg:  `parameter name "not-an-identifier" is not an identifier`,
+				},
+			},
+		},
+		{
+			name: "update view with a null query param",
+			fields: DashboardFields{
+				Dashboards: []*platform.Dashboard{
+					{
+						ID:             1,
+						OrganizationID: 1,
+						Name:           "dashboard1",
+						Cells: []*platform.Cell{
+							{
+								ID: 100,
+							},
+						},
+					},
+				},
+			},
+			args: args{
+				dashboardID: 1,
+				cellID:      100,
+				properties: platform.TableViewProperties{
+					Type: "table",
+					Queries: []platform.DashboardQuery{{
+						Text:   "from(bucket: params.bucket)",
+						Params: map[string]interface{}{"bucket": nil},
+					}},
+				},
+			},
+			wants: wants{
+				err: &platform.Error{
+					Code: platform.EInvalid,
+					Msg:  "parameter bucket: null values are not supported",
+				},
+			},
+		},
 		{
 			name: "update view for cell that does not exist",
 			fields: DashboardFields{
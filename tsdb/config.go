@@ -18,6 +18,15 @@ type Config struct {
 	// LargeSeriesWriteThreshold is the threshold before a write requires
 	// preallocation to improve throughput. Currently used in the series file.
 	LargeSeriesWriteThreshold int `toml:"large-series-write-threshold"`
+
+	// MaxSeriesPerBucket is the maximum number of series in a bucket. Points
+	// that would create a new series in a bucket at the limit are rejected.
+	// Zero disables the limit.
+	MaxSeriesPerBucket int `toml:"max-series-per-bucket"`
+
+	// MaxSeriesPerMeasurement is the maximum number of series in each
+	// measurement of a bucket. Zero disables the limit.
+	MaxSeriesPerMeasurement int `toml:"max-series-per-measurement"`
 }
 
 // NewConfig return a new instance of config with default settings.
@@ -956,6 +956,42 @@ func (i *Index) TagValueSeriesIDIterator(name, key, value []byte) (tsdb.SeriesID
 	return tsdb.FilterUndeletedSeriesIDIterator(i.sfile, itr)
 }
 
+// MeasurementCardinality returns the number of series in measurement name.
+// It is computed from the series id sets of the index rather than by
+// iterating over the series.
+func (i *Index) MeasurementCardinality(name []byte) (int64, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var n int64
+	for _, p := range i.partitions {
+		pn, err := p.MeasurementCardinality(name)
+		if err != nil {
+			return 0, err
+		}
+		n += pn
+	}
+	return n, nil
+}
+
+// TagValueCardinality returns the number of series in measurement name with
+// the tag key=value. It is computed from the series id sets of the index
+// rather than by iterating over the series.
+func (i *Index) TagValueCardinality(name, key, value []byte) (int64, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var n int64
+	for _, p := range i.partitions {
+		pn, err := p.TagValueCardinality(name, key, value)
+		if err != nil {
+			return 0, err
+		}
+		n += pn
+	}
+	return n, nil
+}
+
 // tagValueSeriesIDIterator returns a series iterator for a single tag value.
 func (i *Index) tagValueSeriesIDIterator(name, key, value []byte) (tsdb.SeriesIDIterator, error) {
 	// Check series ID set cache...
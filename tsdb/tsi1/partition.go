@@ -843,6 +843,59 @@ func (p *Partition) TagValueSeriesIDIterator(name, key, value []byte) (tsdb.Seri
 	return newFileSetSeriesIDIterator(fs, itr), nil
 }
 
+// MeasurementCardinality returns the number of series in measurement name.
+func (p *Partition) MeasurementCardinality(name []byte) (int64, error) {
+	fs, err := p.FileSet()
+	if err != nil {
+		return 0, err
+	}
+	defer fs.Release()
+
+	return p.seriesCardinality(fs.MeasurementSeriesIDIterator(name))
+}
+
+// TagValueCardinality returns the number of series in measurement name with
+// the tag key=value.
+func (p *Partition) TagValueCardinality(name, key, value []byte) (int64, error) {
+	fs, err := p.FileSet()
+	if err != nil {
+		return 0, err
+	}
+	defer fs.Release()
+
+	itr, err := fs.TagValueSeriesIDIterator(name, key, value)
+	if err != nil {
+		return 0, err
+	}
+	return p.seriesCardinality(itr)
+}
+
+// seriesCardinality returns the number of undeleted series in itr and closes it.
+func (p *Partition) seriesCardinality(itr tsdb.SeriesIDIterator) (int64, error) {
+	if itr == nil {
+		return 0, nil
+	}
+	defer itr.Close()
+
+	// Intersect with partition set to ensure deleted series are not counted.
+	if ssitr, ok := itr.(tsdb.SeriesIDSetIterator); ok {
+		return int64(p.seriesIDSet.And(ssitr.SeriesIDSet()).Cardinality()), nil
+	}
+
+	var n int64
+	for {
+		e, err := itr.Next()
+		if err != nil {
+			return 0, err
+		} else if e.SeriesID.IsZero() {
+			return n, nil
+		}
+		if p.seriesIDSet.Contains(e.SeriesID) {
+			n++
+		}
+	}
+}
+
 // MeasurementTagKeysByExpr extracts the tag keys wanted by the expression.
 func (p *Partition) MeasurementTagKeysByExpr(name []byte, expr influxql.Expr) (map[string]struct{}, error) {
 	fs, err := p.FileSet()
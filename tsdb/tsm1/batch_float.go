@@ -276,6 +276,10 @@ func init() {
 }
 
 func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
+	if len(b) > 0 && b[0]>>4 == floatCompressedZstd {
+		return floatArrayDecodeZstd(b, buf)
+	}
+
 	if len(b) < 9 {
 		return []float64{}, nil
 	}
@@ -286,7 +290,7 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 		meaningfulN uint8  = 64 // meaningful bit count
 	)
 
-	// first byte is the compression type; always Gorilla
+	// first byte is the compression type; Gorilla from here on
 	b = b[1:]
 
 	val = binary.BigEndian.Uint64(b)
@@ -512,3 +516,49 @@ func FloatArrayDecodeAll(b []byte, buf []float64) ([]float64, error) {
 ERROR:
 	return (*(*[]float64)(unsafe.Pointer(&dst)))[:0], io.EOF
 }
+
+// floatArrayEncodeZstd encodes src into b using the zstd float encoding at
+// the given compression level, returning b and any error encountered.
+func floatArrayEncodeZstd(src []float64, b []byte, level int) ([]byte, error) {
+	// Store the bytes of the values by significance. The exponent and high
+	// order mantissa bytes of related values tend to repeat, even when the
+	// low order bytes are noise.
+	n := len(src)
+	split := make([]byte, 8*n)
+	for i, v := range src {
+		u := math.Float64bits(v)
+		for j := 0; j < 8; j++ {
+			split[j*n+i] = byte(u >> uint(56-8*j))
+		}
+	}
+
+	b = append(b[:0], floatCompressedZstd<<4)
+	return zstdEncode(b, split, level)
+}
+
+// floatArrayDecodeZstd decodes the zstd encoded floats of b into buf,
+// returning buf and any error encountered.
+func floatArrayDecodeZstd(b []byte, buf []float64) ([]float64, error) {
+	split, err := zstdDecode(nil, b[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode float block: %v", err)
+	}
+	if len(split)%8 != 0 {
+		return nil, fmt.Errorf("failed to decode float block: invalid length %d", len(split))
+	}
+
+	n := len(split) / 8
+	if cap(buf) < n {
+		buf = make([]float64, n)
+	} else {
+		buf = buf[:n]
+	}
+	for i := range buf {
+		var u uint64
+		for j := 0; j < 8; j++ {
+			u = u<<8 | uint64(split[j*n+i])
+		}
+		buf[i] = math.Float64frombits(u)
+	}
+	return buf, nil
+}
@@ -69,15 +69,33 @@ func StringArrayEncodeAll(src []string, b []byte) ([]byte, error) {
 	return dst[:len(res)+1], nil
 }
 
+// stringArrayEncodeZstd encodes src into b using zstd compression at the
+// given level, returning b and any error encountered.
+func stringArrayEncodeZstd(src []string, b []byte, level int) ([]byte, error) {
+	sz := len(src) * binary.MaxVarintLen64
+	for i := range src {
+		sz += len(src[i])
+	}
+
+	dta := make([]byte, sz)
+	n := 0
+	for i := range src {
+		n += binary.PutUvarint(dta[n:], uint64(len(src[i])))
+		n += copy(dta[n:], src[i])
+	}
+
+	b = append(b[:0], stringCompressedZstd<<4)
+	return zstdEncode(b, dta[:n], level)
+}
+
 func StringArrayDecodeAll(b []byte, dst []string) ([]string, error) {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	// First byte stores the encoding type.
 	if len(b) > 0 {
 		var err error
-		// it is important that to note that `snappy.Decode` always returns
+		// it is important that to note that decompressStrings always returns
 		// a newly allocated slice as the final strings reference this slice
 		// directly.
-		b, err = snappy.Decode(nil, b[1:])
+		b, err = decompressStrings(b)
 		if err != nil {
 			return []string{}, fmt.Errorf("failed to decode string block: %v", err.Error())
 		}
@@ -0,0 +1,97 @@
+package tsm1
+
+import (
+	"container/list"
+	"sync"
+)
+
+// BlockCache is an LRU cache of raw TSM blocks read from an object store,
+// bounded by the total size of the cached blocks. It is shared by every
+// remote TSM file of a FileStore.
+type BlockCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	lru     *list.List // most recently used first
+	blocks  map[blockCacheKey]*list.Element
+}
+
+type blockCacheKey struct {
+	object string
+	offset int64
+}
+
+type blockCacheEntry struct {
+	key   blockCacheKey
+	block []byte
+}
+
+// NewBlockCache returns a BlockCache holding at most maxSize bytes of blocks.
+// A cache with a maxSize of zero caches nothing.
+func NewBlockCache(maxSize int64) *BlockCache {
+	return &BlockCache{
+		maxSize: maxSize,
+		lru:     list.New(),
+		blocks:  make(map[blockCacheKey]*list.Element),
+	}
+}
+
+// Size returns the number of bytes of cached blocks.
+func (c *BlockCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *BlockCache) get(key blockCacheKey) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.blocks[key]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*blockCacheEntry).block
+}
+
+// add caches block, evicting the least recently used blocks to make room.
+// Cached blocks must not be modified.
+func (c *BlockCache) add(key blockCacheKey, block []byte) {
+	if int64(len(block)) > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.blocks[key]; ok {
+		return
+	}
+	c.blocks[key] = c.lru.PushFront(&blockCacheEntry{key: key, block: block})
+	c.size += int64(len(block))
+
+	for c.size > c.maxSize {
+		e := c.lru.Back()
+		entry := e.Value.(*blockCacheEntry)
+		c.lru.Remove(e)
+		delete(c.blocks, entry.key)
+		c.size -= int64(len(entry.block))
+	}
+}
+
+// evict removes every cached block of object.
+func (c *BlockCache) evict(object string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if entry := e.Value.(*blockCacheEntry); entry.key.object == object {
+			c.lru.Remove(e)
+			delete(c.blocks, entry.key)
+			c.size -= int64(len(entry.block))
+		}
+		e = next
+	}
+}
@@ -0,0 +1,240 @@
+package tsm1
+
+import (
+	"strings"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// KeyStats summarizes the data stored under a set of keys.
+type KeyStats struct {
+	TSMBytes   int64 // size of the blocks in TSM files
+	CacheBytes int64 // size of the values in the cache, which are also in the WAL
+	MinTime    int64
+	MaxTime    int64
+	Tombstones int // tombstones written for TSM files
+
+	n int // number of blocks and cache entries; MinTime and MaxTime are unset if zero
+}
+
+// HasData returns true if any blocks or cache entries were counted, in which
+// case MinTime and MaxTime are set.
+func (s *KeyStats) HasData() bool { return s.n > 0 }
+
+func (s *KeyStats) addTimeRange(min, max int64) {
+	if s.n == 0 || min < s.MinTime {
+		s.MinTime = min
+	}
+	if s.n == 0 || max > s.MaxTime {
+		s.MaxTime = max
+	}
+	s.n++
+}
+
+func (s *KeyStats) add(other *KeyStats) {
+	if other.n > 0 {
+		s.addTimeRange(other.MinTime, other.MaxTime)
+		s.n += other.n - 1
+	}
+	s.TSMBytes += other.TSMBytes
+	s.CacheBytes += other.CacheBytes
+	s.Tombstones += other.Tombstones
+}
+
+// BucketStats summarizes the data stored for a bucket, in total and by
+// measurement.
+type BucketStats struct {
+	KeyStats
+	Measurements map[string]*KeyStats
+}
+
+func newBucketStats() *BucketStats {
+	return &BucketStats{Measurements: make(map[string]*KeyStats)}
+}
+
+func (s *BucketStats) measurement(name []byte) *KeyStats {
+	m := s.Measurements[string(name)]
+	if m == nil {
+		m = &KeyStats{}
+		s.Measurements[string(name)] = m
+	}
+	return m
+}
+
+func (s *BucketStats) add(other *BucketStats) {
+	s.KeyStats.add(&other.KeyStats)
+	for name, m := range other.Measurements {
+		s.measurement([]byte(name)).add(m)
+	}
+}
+
+// fileBucketStats holds the BucketStats of every bucket in a TSM file, as of
+// the last modification of the file.
+type fileBucketStats struct {
+	lastModified int64
+	size         uint32
+	buckets      map[string]*BucketStats
+}
+
+func (fs *fileBucketStats) bucket(name []byte) *BucketStats {
+	s := fs.buckets[string(name)]
+	if s == nil {
+		s = newBucketStats()
+		fs.buckets[string(name)] = s
+	}
+	return s
+}
+
+// newFileBucketStats computes the stats of every bucket in f with a single
+// pass over its index and tombstones. st is the FileStat of f.
+func newFileBucketStats(f TSMFile, st FileStat) (*fileBucketStats, error) {
+	fs := &fileBucketStats{
+		lastModified: st.LastModified,
+		size:         st.Size,
+		buckets:      make(map[string]*BucketStats),
+	}
+
+	var tags models.Tags
+	itr := f.Iterator(nil)
+	for itr.Next() {
+		var name []byte
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(itr.Key())
+		name, tags = models.ParseKeyBytesWithTags(seriesKey, tags[:0])
+
+		bs := fs.bucket(name)
+		ms := bs.measurement(tags.Get(models.MeasurementTagKeyBytes))
+		for _, e := range itr.Entries() {
+			bs.TSMBytes += int64(e.Size)
+			bs.addTimeRange(e.MinTime, e.MaxTime)
+			ms.TSMBytes += int64(e.Size)
+			ms.addTimeRange(e.MinTime, e.MaxTime)
+		}
+	}
+	if err := itr.Err(); err != nil {
+		return nil, err
+	}
+
+	if !st.HasTombstone {
+		return fs, nil
+	}
+	if err := f.WalkTombstones(func(ts Tombstone) error {
+		// Prefix tombstones delete from a whole bucket.
+		if ts.Prefix {
+			fs.bucket(models.ParseName(ts.Key)).Tombstones++
+			return nil
+		}
+
+		var name []byte
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(ts.Key)
+		name, tags = models.ParseKeyBytesWithTags(seriesKey, tags[:0])
+		bs := fs.bucket(name)
+		bs.Tombstones++
+		bs.measurement(tags.Get(models.MeasurementTagKeyBytes)).Tombstones++
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// BucketStats returns the stats of the TSM files for the bucket name. The
+// stats of a file are computed the first time they are needed and kept until
+// the file is removed or modified, so only new files are read by later calls.
+func (f *FileStore) BucketStats(name []byte) (*BucketStats, error) {
+	f.mu.RLock()
+	files := make([]TSMFile, len(f.files))
+	copy(files, f.files)
+	for _, file := range files {
+		file.Ref()
+	}
+	f.mu.RUnlock()
+
+	defer func() {
+		for _, file := range files {
+			file.Unref()
+		}
+	}()
+
+	f.statsMu.Lock()
+	defer f.statsMu.Unlock()
+
+	stats := newBucketStats()
+	cached := make(map[string]*fileBucketStats, len(files))
+	for _, file := range files {
+		st := file.Stats()
+		fs := f.bucketStats[st.Path]
+		if fs == nil || fs.lastModified != st.LastModified || fs.size != st.Size {
+			var err error
+			if fs, err = newFileBucketStats(file, st); err != nil {
+				return nil, err
+			}
+		}
+		cached[st.Path] = fs
+
+		if bs := fs.buckets[string(name)]; bs != nil {
+			stats.add(bs)
+		}
+	}
+	f.bucketStats = cached
+	return stats, nil
+}
+
+// addBucketStats adds the size and time range of the values cached for the
+// bucket name, including those of a snapshot being written, to stats.
+func (c *Cache) addBucketStats(name []byte, stats *BucketStats) {
+	prefix := string(models.EscapeMeasurement(name)) + ","
+
+	c.mu.RLock()
+	stores := []*ring{c.store}
+	if c.snapshot != nil {
+		stores = append(stores, c.snapshot.store)
+	}
+	c.mu.RUnlock()
+
+	var tags models.Tags
+	for _, store := range stores {
+		// applySerial only errors if the closure returns an error.
+		_ = store.applySerial(func(k string, e *entry) error {
+			if !strings.HasPrefix(k, prefix) {
+				return nil
+			}
+
+			e.mu.RLock()
+			if len(e.values) == 0 {
+				e.mu.RUnlock()
+				return nil
+			}
+			min, max := e.values[0].UnixNano(), e.values[0].UnixNano()
+			for _, v := range e.values[1:] {
+				if t := v.UnixNano(); t < min {
+					min = t
+				} else if t > max {
+					max = t
+				}
+			}
+			size := int64(e.values.Size())
+			e.mu.RUnlock()
+
+			seriesKey, _ := SeriesAndFieldFromCompositeKey([]byte(k))
+			_, tags = models.ParseKeyBytesWithTags(seriesKey, tags[:0])
+			ms := stats.measurement(tags.Get(models.MeasurementTagKeyBytes))
+
+			stats.CacheBytes += size
+			stats.addTimeRange(min, max)
+			ms.CacheBytes += size
+			ms.addTimeRange(min, max)
+			return nil
+		})
+	}
+}
+
+// BucketStats returns the stats of the data stored for the bucket name,
+// including the values in the cache that are not yet in TSM files.
+func (e *Engine) BucketStats(name []byte) (*BucketStats, error) {
+	stats, err := e.FileStore.BucketStats(name)
+	if err != nil {
+		return nil, err
+	}
+	e.Cache.addBucketStats(name, stats)
+	return stats, nil
+}
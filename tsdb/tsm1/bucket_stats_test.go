@@ -0,0 +1,66 @@
+package tsm1_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestFileStore_BucketStats(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	cpuKey := "b0,\x00=cpu,host=A,\xff=value#!~#value"
+	MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		cpuKey:                                   {tsm1.NewValue(1, 1.0), tsm1.NewValue(3, 2.0)},
+		"b0,\x00=mem,host=A,\xff=value#!~#value": {tsm1.NewValue(10, 1.0)},
+	})
+	MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"b1,\x00=cpu,host=A,\xff=value#!~#value": {tsm1.NewValue(100, 1.0)},
+	})
+
+	fs := tsm1.NewFileStore(dir)
+	if err := fs.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	stats, err := fs.BucketStats([]byte("b0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.HasData() || stats.MinTime != 1 || stats.MaxTime != 10 {
+		t.Fatalf("got time range [%d, %d], want [1, 10]", stats.MinTime, stats.MaxTime)
+	}
+	if len(stats.Measurements) != 2 {
+		t.Fatalf("got %d measurements, want 2", len(stats.Measurements))
+	}
+	cpu, mem := stats.Measurements["cpu"], stats.Measurements["mem"]
+	if cpu.TSMBytes == 0 || mem.TSMBytes == 0 || cpu.TSMBytes+mem.TSMBytes != stats.TSMBytes {
+		t.Fatalf("got cpu %d + mem %d bytes, bucket %d bytes", cpu.TSMBytes, mem.TSMBytes, stats.TSMBytes)
+	}
+	if cpu.MaxTime != 3 {
+		t.Fatalf("got cpu max time %d, want 3", cpu.MaxTime)
+	}
+
+	// Tombstones modify the file, so its stats are computed again.
+	if err := fs.DeleteRange([][]byte{[]byte(cpuKey)}, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	stats, err = fs.BucketStats([]byte("b0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Tombstones != 1 || stats.Measurements["cpu"].Tombstones != 1 || stats.Measurements["mem"].Tombstones != 0 {
+		t.Fatalf("got tombstones %d, cpu %d, mem %d, want 1, 1, 0",
+			stats.Tombstones, stats.Measurements["cpu"].Tombstones, stats.Measurements["mem"].Tombstones)
+	}
+
+	if stats, err := fs.BucketStats([]byte("missing")); err != nil {
+		t.Fatal(err)
+	} else if stats.HasData() || stats.TSMBytes != 0 {
+		t.Fatalf("got stats %+v for missing bucket", stats.KeyStats)
+	}
+}
@@ -99,6 +99,11 @@ type CompactionPlanner interface {
 type DefaultPlanner struct {
 	FileStore fileStore
 
+	// Partitions identifies the files holding a single time partition of a
+	// bucket. Level 4 generations of such files are only compacted again
+	// once another generation holds data of their partitions.
+	Partitions *Partitions
+
 	// compactFullWriteColdDuration specifies the length of time after
 	// which if no writes have been committed to the WAL, the engine will
 	// do a full compaction of the TSM files in this shard. This duration
@@ -204,7 +209,7 @@ func (c *DefaultPlanner) ParseFileName(path string) (int, int, error) {
 
 // FullyCompacted returns true if the shard is fully compacted.
 func (c *DefaultPlanner) FullyCompacted() bool {
-	gens := c.findGenerations(false)
+	gens := c.unsettled(c.findGenerations(false))
 	return len(gens) <= 1 && !gens.hasTombstones()
 }
 
@@ -327,7 +332,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	// Determine the generations from all files on disk.  We need to treat
 	// a generation conceptually as a single file even though it may be
 	// split across several files in sequence.
-	generations := c.findGenerations(true)
+	generations := c.unsettled(c.findGenerations(true))
 
 	// If there is only one generation and no tombstones, then there's nothing to
 	// do.
@@ -406,7 +411,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 // Plan returns a set of TSM files to rewrite for level 4 or higher.  The planning returns
 // multiple groups if possible to allow compactions to run concurrently.
 func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
-	generations := c.findGenerations(true)
+	generations := c.unsettled(c.findGenerations(true))
 
 	c.mu.RLock()
 	forceFull := c.forceFull
@@ -627,6 +632,12 @@ func (c *DefaultPlanner) findGenerations(skipInUse bool) tsmGenerations {
 			continue
 		}
 
+		// Offloaded files are never compacted again; doing so would bring
+		// their blocks back to local disk.
+		if f.Remote {
+			continue
+		}
+
 		group := generations[gen]
 		if group == nil {
 			group = newTsmGeneration(gen, c.ParseFileName)
@@ -649,6 +660,57 @@ func (c *DefaultPlanner) findGenerations(skipInUse bool) tsmGenerations {
 	return orderedGenerations
 }
 
+// unsettled returns generations without the level 4 generations that hold
+// only whole time partitions of partitioned buckets. Compacting them with
+// other generations would rewrite their files without merging any data, and
+// retention removes them once their partitions expire.
+func (c *DefaultPlanner) unsettled(generations tsmGenerations) tsmGenerations {
+	durations := c.Partitions.snapshot()
+	if len(durations) == 0 {
+		return generations
+	}
+
+	var stats []FileStat
+	for _, g := range generations {
+		stats = append(stats, g.files...)
+	}
+	parts := filePartitions(durations, stats)
+
+	// Count the generations holding data of each partition.
+	owners := make(map[timePartition]int)
+	for _, g := range generations {
+		seen := make(map[timePartition]struct{}, len(g.files))
+		for _, f := range g.files {
+			if p, ok := parts[f.Path]; ok {
+				if _, ok := seen[p]; !ok {
+					seen[p] = struct{}{}
+					owners[p]++
+				}
+			}
+		}
+	}
+
+	settled := func(g *tsmGeneration) bool {
+		if g.level() < 4 || g.hasTombstones() {
+			return false
+		}
+		for _, f := range g.files {
+			if p, ok := parts[f.Path]; !ok || owners[p] > 1 {
+				return false
+			}
+		}
+		return true
+	}
+
+	unsettled := make(tsmGenerations, 0, len(generations))
+	for _, g := range generations {
+		if !settled(g) {
+			unsettled = append(unsettled, g)
+		}
+	}
+	return unsettled
+}
+
 func (c *DefaultPlanner) acquire(groups []CompactionGroup) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -698,6 +760,13 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// Partitions splits the files written for partitioned buckets at the
+	// boundaries of their time partitions.
+	Partitions *Partitions
+
+	// Compression rewrites string and float blocks with the configured codecs.
+	Compression *Compression
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -1028,6 +1097,10 @@ func (c *Compactor) removeTmpFiles(files []string) error {
 // writeNewFiles writes from the iterator into new TSM files, rotating
 // to a new file once it has reached the max TSM file size.
 func (c *Compactor) writeNewFiles(generation, sequence int, src []string, iter KeyIterator, throttle bool) ([]string, error) {
+	if c.Partitions.Enabled() {
+		return c.writePartitionedFiles(generation, sequence, src, iter, throttle)
+	}
+
 	// These are the new TSM files written
 	var files []string
 
@@ -1084,10 +1157,11 @@ func (c *Compactor) writeNewFiles(generation, sequence int, src []string, iter K
 	return files, nil
 }
 
-func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err error) {
+// newTSMWriter creates the file at path and returns a writer for it.
+func (c *Compactor) newTSMWriter(path string, iter KeyIterator, throttle bool) (TSMWriter, error) {
 	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
 	if err != nil {
-		return errCompactionInProgress{err: err}
+		return nil, errCompactionInProgress{err: err}
 	}
 
 	// syncingWriter ensures that whatever we wrap the above file descriptor in
@@ -1098,28 +1172,31 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 		Sync() error
 	}
 
-	// Create the write for the new TSM file.
-	var (
-		w           TSMWriter
-		limitWriter syncingWriter = fd
-	)
-
+	var limitWriter syncingWriter = fd
 	if c.RateLimit != nil && throttle {
 		limitWriter = limiter.NewWriterWithRate(fd, c.RateLimit)
 	}
 
 	// Use a disk based TSM buffer if it looks like we might create a big index
 	// in memory.
+	var w TSMWriter
 	if iter.EstimatedIndexSize() > 64*1024*1024 {
 		w, err = NewTSMWriterWithDiskBuffer(limitWriter)
-		if err != nil {
-			return err
-		}
 	} else {
 		w, err = NewTSMWriter(limitWriter)
-		if err != nil {
-			return err
-		}
+	}
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err error) {
+	// Create the write for the new TSM file.
+	w, err := c.newTSMWriter(path, iter, throttle)
+	if err != nil {
+		return err
 	}
 
 	defer func() {
@@ -1161,6 +1238,10 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 			return fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
 		}
 
+		if block, err = c.Compression.Recompress(block); err != nil {
+			return err
+		}
+
 		// Write the key and value
 		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
@@ -0,0 +1,207 @@
+package tsm1
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression rewrites the values of string and float blocks written to TSM
+// files with the configured codecs. A nil Compression leaves blocks as they
+// are encoded.
+type Compression struct {
+	stringCodec byte
+	floatCodec  byte
+	level       int
+}
+
+// NewCompression returns a new Compression for the codecs of c.
+func NewCompression(c CompressionConfig) (*Compression, error) {
+	cmp := &Compression{level: c.Level}
+
+	switch c.String {
+	case "", CompressionSnappy:
+		cmp.stringCodec = stringCompressedSnappy
+	case CompressionZstd:
+		cmp.stringCodec = stringCompressedZstd
+	default:
+		return nil, fmt.Errorf("unknown string compression %q", c.String)
+	}
+
+	switch c.Float {
+	case "", CompressionGorilla:
+		cmp.floatCodec = floatCompressedGorilla
+	case CompressionZstd:
+		cmp.floatCodec = floatCompressedZstd
+	default:
+		return nil, fmt.Errorf("unknown float compression %q", c.Float)
+	}
+
+	if cmp.level == 0 {
+		cmp.level = DefaultCompressionLevel
+	} else if cmp.level < 1 || cmp.level > 22 {
+		return nil, fmt.Errorf("invalid compression level %d, must be between 1 and 22", c.Level)
+	}
+	return cmp, nil
+}
+
+// Recompress returns block with its values encoded with the configured
+// codec. Blocks already using it, and blocks of other types, are returned
+// as they are.
+func (c *Compression) Recompress(block []byte) ([]byte, error) {
+	if c == nil || len(block) <= encodedBlockHeaderSize {
+		return block, nil
+	}
+
+	var codec byte
+	switch block[0] {
+	case BlockString:
+		codec = c.stringCodec
+	case BlockFloat64:
+		codec = c.floatCodec
+	default:
+		return block, nil
+	}
+
+	tb, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(vb) == 0 || vb[0]>>4 == codec {
+		return block, nil
+	}
+
+	switch block[0] {
+	case BlockString:
+		values, err := StringArrayDecodeAll(vb, nil)
+		if err != nil {
+			return nil, err
+		}
+		if codec == stringCompressedZstd {
+			vb, err = stringArrayEncodeZstd(values, nil, c.level)
+		} else {
+			vb, err = StringArrayEncodeAll(values, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+	case BlockFloat64:
+		values, err := FloatArrayDecodeAll(vb, nil)
+		if err != nil {
+			return nil, err
+		}
+		if codec == floatCompressedZstd {
+			vb, err = floatArrayEncodeZstd(values, nil, c.level)
+		} else {
+			vb, err = FloatArrayEncodeAll(values, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return packBlock(nil, block[0], tb, vb), nil
+}
+
+// BlockCodec returns the name of the codec that the values of block are
+// encoded with.
+func BlockCodec(block []byte) (string, error) {
+	if len(block) <= encodedBlockHeaderSize {
+		return "", fmt.Errorf("codec of short block: got %v, exp %v", len(block), encodedBlockHeaderSize)
+	}
+	_, vb, err := unpackBlock(block[encodedBlockHeaderSize:])
+	if err != nil {
+		return "", err
+	}
+	return codecName(block[0], vb), nil
+}
+
+// codecName returns the name of the codec that the values of a block of type
+// typ are encoded with, given the encoded values.
+func codecName(typ byte, values []byte) string {
+	if len(values) == 0 {
+		return "none"
+	}
+
+	codec := values[0] >> 4
+	switch typ {
+	case BlockFloat64:
+		switch codec {
+		case floatCompressedGorilla:
+			return CompressionGorilla
+		case floatCompressedZstd:
+			return CompressionZstd
+		}
+	case BlockInteger, BlockUnsigned:
+		switch codec {
+		case intUncompressed:
+			return "uncompressed"
+		case intCompressedSimple:
+			return "simple8b"
+		case intCompressedRLE:
+			return "rle"
+		}
+	case BlockBoolean:
+		if codec == booleanCompressedBitPacked {
+			return "bitpacked"
+		}
+	case BlockString:
+		switch codec {
+		case stringCompressedSnappy:
+			return CompressionSnappy
+		case stringCompressedZstd:
+			return CompressionZstd
+		}
+	}
+	return fmt.Sprintf("unknown(%d)", codec)
+}
+
+// zstdDecoder decodes the zstd blocks of all files. Its DecodeAll method
+// may be called concurrently.
+var zstdDecoder struct {
+	once sync.Once
+	dec  *zstd.Decoder
+	err  error
+}
+
+// zstdDecode appends the decompressed zstd frames of src to dst.
+func zstdDecode(dst, src []byte) ([]byte, error) {
+	zstdDecoder.once.Do(func() {
+		zstdDecoder.dec, zstdDecoder.err = zstd.NewReader(nil)
+	})
+	if zstdDecoder.err != nil {
+		return nil, zstdDecoder.err
+	}
+	return zstdDecoder.dec.DecodeAll(src, dst)
+}
+
+// zstdEncoders are the encoders of each level, created on first use. Their
+// EncodeAll method may be called concurrently.
+var zstdEncoders struct {
+	mu   sync.Mutex
+	encs map[zstd.EncoderLevel]*zstd.Encoder
+}
+
+// zstdEncode appends src, compressed as a zstd frame at the encoder level
+// closest to the zstd level, to dst.
+func zstdEncode(dst, src []byte, level int) ([]byte, error) {
+	l := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncoders.mu.Lock()
+	enc, ok := zstdEncoders.encs[l]
+	if !ok {
+		var err error
+		if enc, err = zstd.NewWriter(nil, zstd.WithEncoderLevel(l)); err != nil {
+			zstdEncoders.mu.Unlock()
+			return nil, err
+		}
+		if zstdEncoders.encs == nil {
+			zstdEncoders.encs = make(map[zstd.EncoderLevel]*zstd.Encoder)
+		}
+		zstdEncoders.encs[l] = enc
+	}
+	zstdEncoders.mu.Unlock()
+
+	return enc.EncodeAll(src, dst), nil
+}
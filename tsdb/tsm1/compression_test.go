@@ -0,0 +1,287 @@
+package tsm1_test
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+var cmpValue = cmp.Comparer(func(a, b tsm1.Value) bool {
+	return a.UnixNano() == b.UnixNano() && a.Value() == b.Value()
+})
+
+// blockCodec returns the codec name of the values of block.
+func blockCodec(t *testing.T, block []byte) string {
+	t.Helper()
+	codec, err := tsm1.BlockCodec(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return codec
+}
+
+// blockValues returns the encoded values of block.
+func blockValues(block []byte) []byte {
+	n, i := binary.Uvarint(block[1:])
+	return block[1+i+int(n):]
+}
+
+func TestCompression_Recompress_String(t *testing.T) {
+	var values tsm1.Values
+	for i := 0; i < 1000; i++ {
+		values = append(values, tsm1.NewValue(int64(i), fmt.Sprintf("host-%d.example.com", i%17)))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := blockCodec(t, block), tsm1.CompressionSnappy; got != exp {
+		t.Fatalf("got codec %s, exp %s", got, exp)
+	}
+
+	zstd, err := tsm1.NewCompression(tsm1.CompressionConfig{String: tsm1.CompressionZstd, Level: 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zblock, err := zstd.Recompress(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := blockCodec(t, zblock), tsm1.CompressionZstd; got != exp {
+		t.Fatalf("got codec %s, exp %s", got, exp)
+	}
+
+	got, err := tsm1.DecodeBlock(zblock, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]tsm1.Value(values), got, cmpValue); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// The typed decoder reads zstd blocks as well.
+	var a []tsm1.StringValue
+	if a, err = tsm1.DecodeStringBlock(zblock, &a); err != nil {
+		t.Fatal(err)
+	} else if len(a) != len(values) {
+		t.Fatalf("got %d values, exp %d", len(a), len(values))
+	}
+	for i := range a {
+		assertValueEqual(t, &a[i], values[i])
+	}
+
+	var dec tsm1.StringDecoder
+	if err := dec.SetBytes(blockValues(zblock)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; dec.Next(); i++ {
+		if got, exp := dec.Read(), values[i].Value().(string); got != exp {
+			t.Fatalf("value %d mismatch: got %s, exp %s", i, got, exp)
+		}
+	}
+	if err := dec.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocks are transcoded back to the default codec.
+	def, err := tsm1.NewCompression(tsm1.CompressionConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sblock, err := def.Recompress(zblock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := blockCodec(t, sblock), tsm1.CompressionSnappy; got != exp {
+		t.Fatalf("got codec %s, exp %s", got, exp)
+	}
+	if got, err := tsm1.DecodeBlock(sblock, nil); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff([]tsm1.Value(values), got, cmpValue); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCompression_Recompress_Float(t *testing.T) {
+	rng := rand.New(rand.NewSource(0))
+	var values tsm1.Values
+	for i := 0; i < 1000; i++ {
+		values = append(values, tsm1.NewValue(int64(i), 20+rng.NormFloat64()))
+	}
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zstd, err := tsm1.NewCompression(tsm1.CompressionConfig{Float: tsm1.CompressionZstd})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zblock, err := zstd.Recompress(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := blockCodec(t, zblock), tsm1.CompressionZstd; got != exp {
+		t.Fatalf("got codec %s, exp %s", got, exp)
+	}
+
+	got, err := tsm1.DecodeBlock(zblock, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]tsm1.Value(values), got, cmpValue); diff != "" {
+		t.Fatal(diff)
+	}
+
+	var a []tsm1.FloatValue
+	if a, err = tsm1.DecodeFloatBlock(zblock, &a); err != nil {
+		t.Fatal(err)
+	} else if len(a) != len(values) {
+		t.Fatalf("got %d values, exp %d", len(a), len(values))
+	}
+	for i := range a {
+		assertValueEqual(t, &a[i], values[i])
+	}
+
+	var dec tsm1.FloatDecoder
+	if err := dec.SetBytes(blockValues(zblock)); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	for ; dec.Next(); n++ {
+		if got, exp := dec.Values(), values[n].Value().(float64); got != exp {
+			t.Fatalf("value %d mismatch: got %v, exp %v", n, got, exp)
+		}
+	}
+	if err := dec.Error(); err != nil {
+		t.Fatal(err)
+	} else if n != len(values) {
+		t.Fatalf("got %d values, exp %d", n, len(values))
+	}
+
+	// Blocks of other types are left alone.
+	iblock, err := tsm1.Values{tsm1.NewValue(0, int64(1))}.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := zstd.Recompress(iblock); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(iblock, got); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestNewCompression_Invalid(t *testing.T) {
+	for _, c := range []tsm1.CompressionConfig{
+		{String: "lz4"},
+		{Float: tsm1.CompressionSnappy},
+		{Level: 23},
+		{Level: -1},
+	} {
+		if _, err := tsm1.NewCompression(c); err == nil {
+			t.Fatalf("expected error for config %+v", c)
+		}
+	}
+}
+
+// Tests that zstd values written by the reference zstd library decode.
+func TestZstd_Compatibility(t *testing.T) {
+	strs, err := hex.DecodeString("2028b52ffd200e7100000161026262036363630262620161")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotStrs, err := tsm1.StringArrayDecodeAll(strs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"a", "bb", "ccc", "bb", "a"}, gotStrs); diff != "" {
+		t.Fatal(diff)
+	}
+
+	floats, err := hex.DecodeString("2028b52ffd2028dd0000803fc0404200f802080200000000a05f2004008001d80163611525")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotFloats, err := tsm1.FloatArrayDecodeAll(floats, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]float64{1.5, -2.25, 3, 1e10, 0}, gotFloats); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+// Tests that compactions write blocks with the configured codecs.
+func TestCompactor_CompactFull_Compression(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	f1 := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.1), tsm1.NewValue(2, 1.2)},
+		"cpu,host=A#!~#state": {tsm1.NewValue(1, "ok")},
+	})
+	f2 := MustWriteTSM(dir, 2, map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": {tsm1.NewValue(3, 1.3)},
+		"cpu,host=A#!~#state": {tsm1.NewValue(2, "failed")},
+	})
+
+	compression, err := tsm1.NewCompression(tsm1.CompressionConfig{
+		String: tsm1.CompressionZstd,
+		Float:  tsm1.CompressionZstd,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = fs
+	compactor.Compression = compression
+	compactor.Open()
+
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	iter := r.BlockIterator()
+	var blocks int
+	for iter.Next() {
+		_, _, _, _, _, block, err := iter.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, exp := blockCodec(t, block), tsm1.CompressionZstd; got != exp {
+			t.Fatalf("got codec %s, exp %s", got, exp)
+		}
+		blocks++
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatal(err)
+	} else if blocks != 2 {
+		t.Fatalf("got %d blocks, exp 2", blocks)
+	}
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []tsm1.Value{tsm1.NewValue(1, 1.1), tsm1.NewValue(2, 1.2), tsm1.NewValue(3, 1.3)}
+	if diff := cmp.Diff(exp, values, cmpValue); diff != "" {
+		t.Fatal(diff)
+	}
+}
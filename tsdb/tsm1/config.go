@@ -33,8 +33,10 @@ type Config struct {
 	// preallocation to improve throughput. Currently used in the series file.
 	LargeSeriesWriteThreshold int `toml:"large-series-write-threshold"`
 
-	Compaction CompactionConfig `toml:"compaction"`
-	Cache      CacheConfig      `toml:"cache"`
+	Compaction  CompactionConfig  `toml:"compaction"`
+	Cache       CacheConfig       `toml:"cache"`
+	Compression CompressionConfig `toml:"compression"`
+	Scrub       ScrubConfig       `toml:"scrub"`
 }
 
 // NewConfig constructs a Config with the default values.
@@ -44,7 +46,9 @@ func NewConfig() Config {
 		MADVWillNeed:              DefaultMADVWillNeed,
 		LargeSeriesWriteThreshold: DefaultLargeSeriesWriteThreshold,
 
-		Cache: NewCacheConfig(),
+		Cache:       NewCacheConfig(),
+		Compression: NewCompressionConfig(),
+		Scrub:       NewScrubConfig(),
 		Compaction: CompactionConfig{
 			FullWriteColdDuration: toml.Duration(DefaultCompactFullWriteColdDuration),
 			Throughput:            toml.Size(DefaultCompactThroughput),
@@ -84,6 +88,74 @@ type CompactionConfig struct {
 	MaxConcurrent int `toml:"max-concurrent"`
 }
 
+// Compression codecs of string and float blocks.
+const (
+	CompressionSnappy  = "snappy"
+	CompressionGorilla = "gorilla"
+	CompressionZstd    = "zstd"
+)
+
+// Default compression configuration values.
+const (
+	DefaultStringCompression = CompressionSnappy
+	DefaultFloatCompression  = CompressionGorilla
+	DefaultCompressionLevel  = 3
+)
+
+// CompressionConfig holds the codecs that string and float blocks are written
+// with. Blocks record their codec, so files written with different codecs
+// can be read together, and compactions rewrite existing blocks with the
+// configured codecs.
+type CompressionConfig struct {
+	// String is the codec of string blocks, either snappy or zstd.
+	String string `toml:"string"`
+
+	// Float is the codec of float blocks, either gorilla or zstd. The zstd
+	// codec compresses noisy values better than gorilla.
+	Float string `toml:"float"`
+
+	// Level is the zstd compression level, from 1 (fastest) to 22 (smallest).
+	// Levels 1 and 2 use the fastest encoder, 3 to 5 the default encoder,
+	// and higher levels the encoder with better compression.
+	Level int `toml:"level"`
+}
+
+// NewCompressionConfig initialises a new CompressionConfig with default values.
+func NewCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		String: DefaultStringCompression,
+		Float:  DefaultFloatCompression,
+		Level:  DefaultCompressionLevel,
+	}
+}
+
+// Default scrub configuration values.
+const (
+	DefaultScrubInterval   = 24 * time.Hour
+	DefaultScrubThroughput = 8 * 1024 * 1024
+)
+
+// ScrubConfig holds the configuration of the scrubber, which verifies the
+// checksums and index of TSM files in the background and quarantines the
+// files it finds corrupt.
+type ScrubConfig struct {
+	// Interval is the time between scrubs of all TSM files. A value of 0
+	// disables scrubbing.
+	Interval toml.Duration `toml:"interval"`
+
+	// Throughput is the rate limit in bytes per second at which the scrubber
+	// reads TSM files, keeping it from competing with queries and compactions.
+	Throughput toml.Size `toml:"throughput"`
+}
+
+// NewScrubConfig initialises a new ScrubConfig with default values.
+func NewScrubConfig() ScrubConfig {
+	return ScrubConfig{
+		Interval:   toml.Duration(DefaultScrubInterval),
+		Throughput: toml.Size(DefaultScrubThroughput),
+	}
+}
+
 // Default Cache configuration values.
 const (
 	DefaultCacheMaxMemorySize             = toml.Size(1024 << 20)           // 1GB
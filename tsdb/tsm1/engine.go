@@ -77,8 +77,7 @@ type EngineOption func(i *Engine)
 // WithCompactionPlanner sets the compaction planner for the engine.
 func WithCompactionPlanner(planner CompactionPlanner) EngineOption {
 	return func(e *Engine) {
-		planner.SetFileStore(e.FileStore)
-		e.CompactionPlan = planner
+		e.WithCompactionPlanner(planner)
 	}
 }
 
@@ -134,6 +133,22 @@ type Engine struct {
 	CompactionPlan CompactionPlanner
 	FileStore      *FileStore
 
+	// Partitions holds the time partition durations of buckets, shared with
+	// the compactor and the default planner.
+	partitions *Partitions
+
+	// compression holds the codecs the compactor writes blocks with.
+	compression CompressionConfig
+
+	// scrubInterval is the time between scrubs of the TSM files, which read
+	// no faster than scrubLimiter allows.
+	scrubInterval time.Duration
+	scrubLimiter  limiter.Rate
+
+	scrubMu   sync.Mutex
+	scrubbed  map[string]struct{} // files verified by the current scrub
+	lastScrub time.Time           // time the last scrub completed
+
 	MaxPointsPerBlock int
 
 	// CacheFlushMemorySizeThreshold specifies the minimum size threshold for
@@ -157,6 +172,7 @@ type Engine struct {
 
 	compactionTracker   *compactionTracker // Used to track state of compactions.
 	readTracker         *readTracker       // Used to track number of reads.
+	scrubTracker        *scrubTracker      // Used to track scrubbing of TSM files.
 	defaultMetricLabels prometheus.Labels  // N.B this must not be mutated after Open is called.
 
 	// Limiter for concurrent compactions.
@@ -178,9 +194,12 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 
 	cache := NewCache(uint64(config.Cache.MaxMemorySize))
 
+	partitions := NewPartitions()
+
 	c := NewCompactor()
 	c.Dir = path
 	c.FileStore = fs
+	c.Partitions = partitions
 	c.RateLimit = limiter.NewRate(
 		int(config.Compaction.Throughput),
 		int(config.Compaction.ThroughputBurst))
@@ -205,6 +224,14 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		maxCompactions = runtime.GOMAXPROCS(0)
 	}
 
+	planner := NewDefaultPlanner(fs, time.Duration(config.Compaction.FullWriteColdDuration))
+	planner.Partitions = partitions
+
+	var scrubLimiter limiter.Rate
+	if config.Scrub.Throughput > 0 {
+		scrubLimiter = limiter.NewRate(int(config.Scrub.Throughput), int(config.Scrub.Throughput))
+	}
+
 	logger := zap.NewNop()
 	e := &Engine{
 		path:   path,
@@ -214,10 +241,13 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 
 		Cache: cache,
 
-		FileStore: fs,
-		Compactor: c,
-		CompactionPlan: NewDefaultPlanner(fs,
-			time.Duration(config.Compaction.FullWriteColdDuration)),
+		FileStore:      fs,
+		Compactor:      c,
+		CompactionPlan: planner,
+		partitions:     partitions,
+		compression:    config.Compression,
+		scrubInterval:  time.Duration(config.Scrub.Interval),
+		scrubLimiter:   scrubLimiter,
 
 		CacheFlushMemorySizeThreshold:  uint64(config.Cache.SnapshotMemorySize),
 		CacheFlushWriteColdDuration:    time.Duration(config.Cache.SnapshotWriteColdDuration),
@@ -243,6 +273,14 @@ func (e *Engine) SetSemaphore(s influxdb.Semaphore) {
 	e.fullCompactionSemaphore = s
 }
 
+// SetPartitionDurations sets the duration of the time partitions that the
+// data of each bucket, keyed by encoded bucket name, is split into when it is
+// written to TSM files. Buckets without a duration are not partitioned. Files
+// already written are split as they are compacted.
+func (e *Engine) SetPartitionDurations(durations map[string]time.Duration) {
+	e.partitions.SetDurations(durations)
+}
+
 // WithCompactionLimiter sets the compaction limiter, which is used to limit the
 // number of concurrent compactions.
 func (e *Engine) WithCompactionLimiter(limiter limiter.Fixed) {
@@ -269,6 +307,9 @@ func (e *Engine) WithFileStoreObserver(obs FileStoreObserver) {
 
 func (e *Engine) WithCompactionPlanner(planner CompactionPlanner) {
 	planner.SetFileStore(e.FileStore)
+	if p, ok := planner.(*DefaultPlanner); ok && p.Partitions == nil {
+		p.Partitions = e.partitions
+	}
 	e.CompactionPlan = planner
 }
 
@@ -323,13 +364,15 @@ func (e *Engine) enableLevelCompactions(wait bool) {
 
 	// last one to enable, start things back up
 	e.Compactor.EnableCompactions()
-	e.done = make(chan struct{})
+	quit := make(chan struct{})
+	e.done = quit
 	wg := new(sync.WaitGroup)
-	wg.Add(1)
+	wg.Add(2)
 	e.wg = wg
 	e.mu.Unlock()
 
 	go func() { defer wg.Done(); e.compact(wg) }()
+	go func() { defer wg.Done(); e.scrub(quit) }()
 }
 
 // disableLevelCompactions will stop level compactions before returning.
@@ -514,6 +557,7 @@ func (e *Engine) initTrackers() {
 	e.FileStore.tracker = newFileTracker(bms.fileMetrics, e.defaultMetricLabels)
 	e.Cache.tracker = newCacheTracker(bms.cacheMetrics, e.defaultMetricLabels)
 	e.readTracker = newReadTracker(bms.readMetrics, e.defaultMetricLabels)
+	e.scrubTracker = newScrubTracker(bms.scrubMetrics, e.defaultMetricLabels)
 
 	e.scheduler.setCompactionTracker(e.compactionTracker)
 }
@@ -541,6 +585,10 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 
 	e.initTrackers()
 
+	if e.Compactor.Compression, err = NewCompression(e.compression); err != nil {
+		return err
+	}
+
 	if err := os.MkdirAll(e.path, 0777); err != nil {
 		return err
 	}
@@ -553,6 +601,11 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
+	if err := e.updateQuarantined(); err != nil {
+		return err
+	}
+	e.lastScrub = time.Now()
+
 	e.Compactor.Open()
 
 	if e.enableCompactionsOnOpen {
@@ -934,6 +987,7 @@ const (
 	CacheStatusRetention                         // The cache was snapshotted before running retention.
 	CacheStatusFullCompaction                    // The cache was snapshotted as part of a full compaction.
 	CacheStatusBackup                            // The cache was snapshotted before running backup.
+	CacheStatusReadOnly                          // The cache was snapshotted when entering read-only mode.
 )
 
 // ShouldCompactCache returns a status indicating if the Cache should be
@@ -7,8 +7,10 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/kit/tracing"
 	"github.com/influxdata/influxdb/models"
 	"github.com/influxdata/influxdb/tsdb"
@@ -19,6 +21,22 @@ import (
 // and series file data associated with the bucket. The provided time range ensures
 // that only bucket data for that range is removed.
 func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, max int64, pred Predicate) error {
+	return e.DeletePrefixRangeWithProgress(rootCtx, name, min, max, pred, nil)
+}
+
+// DeletePrefixRangeWithProgress is DeletePrefixRange, recording its progress
+// in progress as it goes. The counters are updated atomically so that progress
+// can be read while the delete runs; it may be nil.
+//
+// Cancelling ctx stops the delete between TSM files. Tombstones already
+// written are kept, so a cancelled delete may have removed part of the data.
+// Once every file has been tombstoned the delete runs to completion so that
+// the cache and index stay consistent with the files.
+func (e *Engine) DeletePrefixRangeWithProgress(rootCtx context.Context, name []byte, min, max int64, pred Predicate, progress *influxdb.DeleteProgress) error {
+	if progress == nil {
+		progress = new(influxdb.DeleteProgress)
+	}
+
 	span, ctx := tracing.StartSpanFromContext(rootCtx)
 	span.LogKV("name_prefix", fmt.Sprintf("%x", name),
 		"min", time.Unix(0, min), "max", time.Unix(0, max),
@@ -79,7 +97,30 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 	}
 	possiblyDead.keys = make(map[string]struct{})
 
+	atomic.StoreInt64(&progress.FilesTotal, int64(e.FileStore.Count()))
+
+	// Files holding only data of the prefix within the range, such as the
+	// expired partitions of a partitioned bucket, are removed whole rather
+	// than tombstoned and rewritten by compactions.
+	if pred == nil {
+		if err := rootCtx.Err(); err != nil {
+			return err
+		}
+		n, err := e.FileStore.RemovePrefixRange(name, min, max, func(key []byte) {
+			possiblyDead.keys[string(key)] = struct{}{}
+		})
+		if err != nil {
+			return err
+		}
+		atomic.AddInt64(&progress.FilesRemoved, int64(n))
+	}
+
 	if err := e.FileStore.Apply(func(r TSMFile) error {
+		if err := rootCtx.Err(); err != nil {
+			return err
+		}
+		defer atomic.AddInt64(&progress.FilesScanned, 1)
+
 		var predClone Predicate // Apply executes concurrently across files.
 		if pred != nil {
 			predClone = pred.Clone()
@@ -91,14 +132,24 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 		span.LogKV("file_path", r.Path())
 		defer span.Finish()
 
-		return r.DeletePrefix(name, min, max, predClone, func(key []byte) {
+		size := tombstoneSize(r)
+		if err := r.DeletePrefix(name, min, max, predClone, func(key []byte) {
 			possiblyDead.Lock()
 			possiblyDead.keys[string(key)] = struct{}{}
 			possiblyDead.Unlock()
-		})
+		}); err != nil {
+			return err
+		}
+		if tombstoneSize(r) != size {
+			atomic.AddInt64(&progress.TombstonesWritten, 1)
+		}
+		return nil
 	}); err != nil {
 		return err
 	}
+	if err := rootCtx.Err(); err != nil {
+		return err
+	}
 
 	span, _ = tracing.StartSpanFromContextWithOperationName(rootCtx, "Cache find delete keys")
 	span.LogKV("cache_size", e.Cache.Size())
@@ -155,6 +206,7 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 			if predClone != nil && !predClone.Matches(key) {
 				continue
 			}
+			atomic.AddInt64(&progress.SeriesScanned, 1)
 
 			// TODO(jeff): benchmark the locking here.
 			if i%1024 == 0 { // allow writes to proceed.
@@ -190,6 +242,7 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 		if pred != nil && !pred.Matches([]byte(k)) {
 			return nil
 		}
+		atomic.AddInt64(&progress.SeriesScanned, 1)
 
 		delete(possiblyDead.keys, k)
 		return nil
@@ -251,6 +304,7 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 				}
 			})
 			span.Finish()
+			atomic.AddInt64(&progress.SeriesDeleted, int64(set.Cardinality()))
 			return err
 		}
 
@@ -275,9 +329,151 @@ func (e *Engine) DeletePrefixRange(rootCtx context.Context, name []byte, min, ma
 			if err := e.sfile.DeleteSeriesID(sid); err != nil {
 				return err
 			}
+			atomic.AddInt64(&progress.SeriesDeleted, 1)
 		}
 		span.Finish()
 	}
 
 	return nil
 }
+
+// tombstoneSize returns the size of the tombstone files of r, which grows
+// whenever a tombstone is written.
+func tombstoneSize(r TSMFile) int64 {
+	var n int64
+	for _, ts := range r.TombstoneFiles() {
+		n += int64(ts.Size)
+	}
+	return n
+}
+
+// CountPrefixRange returns the number of series keys and points that
+// DeletePrefixRange would remove when called with the same arguments. Nothing
+// is removed.
+func (e *Engine) CountPrefixRange(ctx context.Context, name []byte, min, max int64, pred Predicate) (series, points int64, err error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	span.LogKV("name_prefix", fmt.Sprintf("%x", name),
+		"min", time.Unix(0, min), "max", time.Unix(0, max),
+		"has_pred", pred != nil,
+	)
+	defer span.Finish()
+
+	if min == influxql.MinTime {
+		min = math.MinInt64
+	}
+	if max == influxql.MaxTime {
+		max = math.MaxInt64
+	}
+
+	// Hold on to the files so that they are not removed by a compaction while
+	// they are read.
+	var files unrefs
+	e.FileStore.ForEachFile(func(r TSMFile) bool {
+		r.Ref()
+		files = append(files, r)
+		return true
+	})
+	defer files.Unref()
+
+	// Find every key that may have data in the range, on disk or in the cache.
+	keys := make(map[string]struct{})
+	for _, r := range files {
+		if !r.OverlapsTimeRange(min, max) {
+			continue
+		}
+		iter := r.Iterator(name)
+		for iter.Next() {
+			key := iter.Key()
+			if !bytes.HasPrefix(key, name) {
+				break
+			}
+			if pred != nil && !pred.Matches(key) {
+				continue
+			}
+			keys[string(key)] = struct{}{}
+		}
+		if err := iter.Err(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	nameStr := string(name)
+	_ = e.Cache.ApplyEntryFn(func(k string, _ *entry) error {
+		if !strings.HasPrefix(k, nameStr) {
+			return nil
+		}
+		if pred != nil && !pred.Matches([]byte(k)) {
+			return nil
+		}
+		keys[k] = struct{}{}
+		return nil
+	})
+
+	// Points are counted by timestamp, as the same point may be stored in
+	// several files and in the cache.
+	var (
+		entries    []IndexEntry
+		values     []Value
+		tombstones []TimeRange
+	)
+	timestamps := make(map[int64]struct{})
+	// Keys are counted by measurement and tag set, as each field of a
+	// series has its own key and field tag.
+	seriesKeys := make(map[string]struct{})
+	var seriesKey []byte
+	for k := range keys {
+		key := []byte(k)
+		for t := range timestamps {
+			delete(timestamps, t)
+		}
+
+		for _, r := range files {
+			if !r.Contains(key) {
+				continue
+			}
+			tombstones = r.TombstoneRange(key, tombstones[:0])
+			if entries, err = r.ReadEntries(key, entries[:0]); err != nil {
+				return 0, 0, err
+			}
+			for i := range entries {
+				if !entries[i].OverlapsTimeRange(min, max) {
+					continue
+				}
+				if values, err = r.ReadAt(&entries[i], values[:0]); err != nil {
+					return 0, 0, err
+				}
+				for _, v := range values {
+					if t := v.UnixNano(); t >= min && t <= max && !inTimeRanges(tombstones, t) {
+						timestamps[t] = struct{}{}
+					}
+				}
+			}
+		}
+
+		for _, v := range e.Cache.Values(key) {
+			if t := v.UnixNano(); t >= min && t <= max {
+				timestamps[t] = struct{}{}
+			}
+		}
+
+		if len(timestamps) > 0 {
+			sk, _ := SeriesAndFieldFromCompositeKey(key)
+			mm, tags := models.ParseKeyBytes(sk)
+			tags.Delete(models.FieldKeyTagKeyBytes)
+			seriesKey = models.AppendMakeKey(seriesKey[:0], mm, tags)
+			seriesKeys[string(seriesKey)] = struct{}{}
+			points += int64(len(timestamps))
+		}
+	}
+	return int64(len(seriesKeys)), points, nil
+}
+
+// inTimeRanges returns true if t is within any of the ranges.
+func inTimeRanges(ranges []TimeRange, t int64) bool {
+	for _, r := range ranges {
+		if r.Min <= t && t <= r.Max {
+			return true
+		}
+	}
+	return false
+}
@@ -3,10 +3,14 @@ package tsm1_test
 import (
 	"bytes"
 	"context"
+	"math"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/influxdata/influxdb"
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/storage/reads/datatypes"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
 
@@ -119,3 +123,188 @@ func TestEngine_DeletePrefix(t *testing.T) {
 		}
 	}
 }
+
+func TestEngine_CountPrefixRange(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.1 1", "mm0"),
+		MustParsePointString("cpu,host=A value=1.2 2", "mm0"),
+		MustParsePointString("cpu,host=B value=1.3 3", "mm0"),
+		MustParsePointString("cpu,host=C value=1.4 4", "mm0"),
+		MustParsePointString("cpu,host=D value=1.6 8", "mm0"),
+		MustParsePointString("cpu,host=D idle=9 8", "mm0"),
+		MustParsePointString("cpu,host=A value=1.5 5", "mm1"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background(), tsm1.CacheStatusColdNoWrites); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+
+	// Overwrite a point on disk and add one that is only in the cache.
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=2.2 2", "mm0"),
+		MustParsePointString("cpu,host=B value=2.3 6", "mm0"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	pred, err := tsm1.NewProtobufPredicate(&datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeComparisonExpression,
+			Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonRegex},
+			Children: []*datatypes.Node{
+				{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: "host"}},
+				{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_RegexValue{RegexValue: "^[AB]$"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		min, max       int64
+		pred           tsm1.Predicate
+		series, points int64
+	}{
+		{name: "all", min: math.MinInt64, max: math.MaxInt64, series: 4, points: 7},
+		{name: "range", min: 2, max: 4, series: 3, points: 3},
+		{name: "predicate", min: math.MinInt64, max: math.MaxInt64, pred: pred, series: 2, points: 4},
+		{name: "multiple fields", min: 8, max: 8, series: 1, points: 2},
+		{name: "empty", min: 9, max: 10, series: 0, points: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, points, err := e.CountPrefixRange(context.Background(), []byte("mm0"), tt.min, tt.max, tt.pred)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if series != tt.series || points != tt.points {
+				t.Fatalf("got %d series and %d points, exp %d series and %d points", series, points, tt.series, tt.points)
+			}
+		})
+	}
+
+	// Counting must not remove anything.
+	if exp, got := 6, len(e.FileStore.Keys()); exp != got {
+		t.Fatalf("series count mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestEngine_DeletePrefixRangeWithProgress(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// Two TSM files with data for the prefix, and one without.
+	for _, p := range []string{
+		"cpu,host=A value=1.1 1",
+		"cpu,host=B value=1.2 2",
+	} {
+		if err := e.writePoints(MustParsePointString(p, "mm0")); err != nil {
+			t.Fatalf("failed to write points: %s", err.Error())
+		}
+		if err := e.WriteSnapshot(context.Background(), tsm1.CacheStatusColdNoWrites); err != nil {
+			t.Fatalf("failed to snapshot: %s", err.Error())
+		}
+	}
+	if err := e.writePoints(MustParsePointString("cpu,host=C value=1.3 3", "mm1")); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background(), tsm1.CacheStatusColdNoWrites); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+
+	// A cancelled delete stops before tombstoning anything.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var progress influxdb.DeleteProgress
+	if err := e.DeletePrefixRangeWithProgress(ctx, []byte("mm0"), 0, 1, nil, &progress); err != context.Canceled {
+		t.Fatalf("got error %v, exp %v", err, context.Canceled)
+	}
+	if exp := (influxdb.DeleteProgress{FilesTotal: 3}); progress != exp {
+		t.Fatalf("got progress %+v, exp %+v", progress, exp)
+	}
+
+	progress = influxdb.DeleteProgress{}
+	if err := e.DeletePrefixRangeWithProgress(context.Background(), []byte("mm0"), 0, 1, nil, &progress); err != nil {
+		t.Fatal(err)
+	}
+	// The file holding only the deleted data is removed rather than
+	// tombstoned.
+	exp := influxdb.DeleteProgress{
+		FilesTotal:    3,
+		FilesScanned:  2,
+		FilesRemoved:  1,
+		SeriesScanned: 1,
+		SeriesDeleted: 1,
+	}
+	if progress != exp {
+		t.Fatalf("got progress %+v, exp %+v", progress, exp)
+	}
+}
+
+func TestEngine_DeletePrefixRange_Partitioned(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	e.SetPartitionDurations(map[string]time.Duration{"mm0": 10})
+
+	// A single snapshot writes a file for each partition of mm0.
+	if err := e.writePoints(
+		MustParsePointString("cpu,host=A value=1.1 1", "mm0"),
+		MustParsePointString("cpu,host=A value=1.2 12", "mm0"),
+		MustParsePointString("cpu,host=B value=1.3 5", "mm0"),
+		MustParsePointString("cpu,host=B value=1.4 25", "mm0"),
+		MustParsePointString("cpu,host=C value=1.5 5", "mm1"),
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	if err := e.WriteSnapshot(context.Background(), tsm1.CacheStatusColdNoWrites); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+	if got, exp := e.FileStore.Count(), 4; got != exp {
+		t.Fatalf("got %d files, exp %d", got, exp)
+	}
+
+	// Deleting the first two partitions removes their files whole.
+	var progress influxdb.DeleteProgress
+	if err := e.DeletePrefixRangeWithProgress(context.Background(), []byte("mm0"), math.MinInt64, 19, nil, &progress); err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := progress.FilesRemoved, int64(2); got != exp {
+		t.Fatalf("got %d files removed, exp %d", got, exp)
+	}
+	if got, exp := progress.TombstonesWritten, int64(0); got != exp {
+		t.Fatalf("got %d tombstones written, exp %d", got, exp)
+	}
+
+	exp := map[string]byte{
+		"mm0,\x00=cpu,host=B,\xff=value#!~#value": 0,
+		"mm1,\x00=cpu,host=C,\xff=value#!~#value": 0,
+	}
+	if keys := e.FileStore.Keys(); !reflect.DeepEqual(keys, exp) {
+		t.Fatalf("unexpected series in file store: %v != %v", keys, exp)
+	}
+}
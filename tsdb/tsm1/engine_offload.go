@@ -0,0 +1,166 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/objstore"
+	"go.uber.org/zap"
+)
+
+// WithObjectStore sets the object store that cold TSM files are offloaded
+// to. Blocks read back from it are cached in cache, which may be nil.
+func WithObjectStore(store objstore.Store, cache *BlockCache) EngineOption {
+	return func(e *Engine) {
+		e.FileStore.WithObjectStore(store, cache)
+	}
+}
+
+// WithObjectStoreTimeout limits each read from and delete in the object store
+// to timeout. Requests are not limited when it is zero.
+func WithObjectStoreTimeout(timeout time.Duration) EngineOption {
+	return func(e *Engine) {
+		e.FileStore.WithObjectStoreTimeout(timeout)
+	}
+}
+
+// OffloadColdFiles uploads fully compacted TSM files to the object store and
+// replaces each with a stub, after which its blocks are read lazily from the
+// object store. A file is offloaded when it has no tombstones and all of its
+// data is older than the cutoff coldBefore returns for every measurement
+// name in it; coldBefore returns false for names that stay on local disk.
+//
+// Offloading stops while level compactions are disabled, such as during a
+// delete. It returns the number of files offloaded.
+func (e *Engine) OffloadColdFiles(ctx context.Context, coldBefore func(name []byte) (int64, bool)) (int, error) {
+	store := e.FileStore.objectStore
+	if store == nil {
+		return 0, ErrNoObjectStore
+	}
+
+	e.mu.RLock()
+	quit, wg := e.done, e.wg
+	if quit == nil {
+		e.mu.RUnlock()
+		return 0, nil
+	}
+	wg.Add(1)
+	e.mu.RUnlock()
+	defer wg.Done()
+
+	var n int
+	for _, f := range e.FileStore.Files() {
+		select {
+		case <-quit:
+			return n, nil
+		case <-ctx.Done():
+			return n, ctx.Err()
+		default:
+		}
+
+		if ok, err := e.isCold(f, coldBefore); err != nil {
+			return n, err
+		} else if !ok {
+			continue
+		}
+
+		start := time.Now()
+		if ok, err := e.offloadFile(ctx, f); err != nil {
+			return n, fmt.Errorf("offloading %s: %v", f.Path(), err)
+		} else if ok {
+			e.logger.Info("Offloaded TSM file",
+				zap.String("path", f.Path()),
+				zap.Uint32("size", f.Size()),
+				zap.Duration("duration", time.Since(start)))
+			n++
+		}
+	}
+	return n, nil
+}
+
+// isCold returns true if f can be offloaded.
+func (e *Engine) isCold(f TSMFile, coldBefore func(name []byte) (int64, bool)) (bool, error) {
+	stat := f.Stats()
+	if stat.Remote || stat.HasTombstone {
+		return false, nil
+	}
+
+	// Only fully compacted files are offloaded.
+	if _, seq, err := e.FileStore.ParseFileName(f.Path()); err != nil {
+		return false, err
+	} else if seq < 4 {
+		return false, nil
+	}
+
+	var name []byte
+	iter := f.Iterator(nil)
+	for iter.Next() {
+		if n := models.ParseName(iter.Key()); !bytes.Equal(n, name) {
+			name = append(name[:0], n...)
+			cutoff, ok := coldBefore(name)
+			if !ok || stat.MaxTime >= cutoff {
+				return false, nil
+			}
+		}
+	}
+	return name != nil, iter.Err()
+}
+
+// offloadFile uploads f and replaces it with a stub. It returns false if f
+// was removed from the file store while it was uploaded.
+func (e *Engine) offloadFile(ctx context.Context, f TSMFile) (bool, error) {
+	store := e.FileStore.objectStore
+	path := f.Path()
+	key := filepath.Base(path)
+
+	fd, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer fd.Close()
+
+	stat, err := fd.Stat()
+	if err != nil {
+		return false, err
+	}
+	size := stat.Size()
+
+	if err := store.Put(ctx, key, io.NewSectionReader(fd, 0, size), size); err != nil {
+		return false, err
+	}
+
+	stubPath := path + "." + TmpTSMFileExtension
+	ok, err := e.writeStubAndReplace(f, fd, key, size, stubPath)
+	if !ok {
+		os.Remove(stubPath)
+		if derr := store.Delete(context.Background(), key); derr != nil && err == nil {
+			err = derr
+		}
+	}
+	return ok, err
+}
+
+func (e *Engine) writeStubAndReplace(f TSMFile, fd *os.File, key string, size int64, stubPath string) (bool, error) {
+	stub, err := os.OpenFile(stubPath, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return false, err
+	}
+	if err := WriteRemoteStub(stub, key, fd, size); err != nil {
+		stub.Close()
+		return false, err
+	}
+	if err := stub.Sync(); err != nil {
+		stub.Close()
+		return false, err
+	}
+	if err := stub.Close(); err != nil {
+		return false, err
+	}
+	return e.FileStore.replaceWithStub(f, stubPath)
+}
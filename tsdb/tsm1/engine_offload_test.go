@@ -0,0 +1,246 @@
+package tsm1_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/objstore"
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestEngine_OffloadColdFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsm1-objstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store := objstore.NewDir(dir)
+	cache := tsm1.NewBlockCache(1 << 20)
+
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tsm1.WithObjectStore(store, cache)(e.Engine)
+
+	// Write two fully compacted files, one with old data and one with new.
+	dataDir := filepath.Join(e.root, "data")
+	if err := os.MkdirAll(dataDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	oldKey := "mm0,\x00=cpu,host=A,\xff=value#!~#value"
+	oldValues := []tsm1.Value{tsm1.NewValue(1, 1.0), tsm1.NewValue(2, 2.0), tsm1.NewValue(3, 3.0)}
+	newKey := "mm1,\x00=cpu,host=A,\xff=value#!~#value"
+	for gen, values := range map[int]map[string][]tsm1.Value{
+		1: {oldKey: oldValues},
+		2: {newKey: {tsm1.NewValue(100, 1.0)}},
+	} {
+		name := MustWriteTSM(dataDir, gen, values)
+		if err := os.Rename(name, filepath.Join(dataDir, tsm1.DefaultFormatFileName(gen, 4)+".tsm")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+	e.SetCompactionsEnabled(true)
+
+	coldBefore := func(name []byte) (int64, bool) {
+		return 10, string(name) == "mm0" || string(name) == "mm1"
+	}
+	if n, err := e.OffloadColdFiles(context.Background(), coldBefore); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("offloaded %d files, want 1", n)
+	}
+
+	oldPath := filepath.Join(dataDir, tsm1.DefaultFormatFileName(1, 4)+".tsm")
+	for _, stat := range e.FileStore.Stats() {
+		if got, want := stat.Remote, stat.Path == oldPath; got != want {
+			t.Fatalf("%s: remote = %v, want %v", stat.Path, got, want)
+		}
+	}
+	object := filepath.Join(dir, filepath.Base(oldPath))
+	if _, err := os.Stat(object); err != nil {
+		t.Fatalf("object not uploaded: %v", err)
+	}
+
+	// Blocks are read back from the object store and cached.
+	values, err := e.FileStore.Read([]byte(oldKey), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, oldValues) {
+		t.Fatalf("read %v, want %v", values, oldValues)
+	}
+	if cache.Size() == 0 {
+		t.Fatal("expected block to be cached")
+	}
+
+	// Offloaded files are not offloaded again.
+	if n, err := e.OffloadColdFiles(context.Background(), coldBefore); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("offloaded %d files, want 0", n)
+	}
+
+	// Removing the file removes its object.
+	if err := e.FileStore.Replace([]string{oldPath}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(object); !os.IsNotExist(err) {
+		t.Fatalf("object not removed: %v", err)
+	}
+	if cache.Size() != 0 {
+		t.Fatalf("cache size = %d, want 0", cache.Size())
+	}
+}
+
+func TestTSMReader_ObjectStore(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	store := objstore.NewDir(filepath.Join(dir, "objects"))
+
+	values := map[string][]tsm1.Value{
+		"cpu": {tsm1.NewValue(1, 1.0), tsm1.NewValue(2, 2.0)},
+		"mem": {tsm1.NewValue(1, int64(1))},
+	}
+	path := MustWriteTSM(dir, 1, values)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(context.Background(), "obj", f, stat.Size()); err != nil {
+		t.Fatal(err)
+	}
+
+	stubPath := filepath.Join(dir, "stub.tsm")
+	stub, err := os.Create(stubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tsm1.WriteRemoteStub(stub, "obj", f, stat.Size()); err != nil {
+		t.Fatal(err)
+	}
+	stub.Close()
+	f.Close()
+
+	// A stub cannot be opened without its object store.
+	fd, err := os.Open(stubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tsm1.NewTSMReader(fd); err != tsm1.ErrNoObjectStore {
+		t.Fatalf("got %v, want %v", err, tsm1.ErrNoObjectStore)
+	}
+	fd.Close()
+
+	if fd, err = os.Open(stubPath); err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(fd, tsm1.WithTSMReaderObjectStore(store, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, exp := range values {
+		got, err := r.ReadAll([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("%s: read %v, want %v", key, got, exp)
+		}
+	}
+	if got := r.KeyCount(); got != 2 {
+		t.Fatalf("key count = %d, want 2", got)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	p := make([]byte, 1)
+	if err := store.ReadAt(context.Background(), "obj", p, 0); err != objstore.ErrNotFound {
+		t.Fatalf("object not removed: %v", err)
+	}
+}
+
+// stalledStore is an object store that never answers reads or deletes.
+type stalledStore struct {
+	objstore.Store
+}
+
+func (stalledStore) ReadAt(ctx context.Context, key string, p []byte, off int64) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (stalledStore) Delete(ctx context.Context, key string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestTSMReader_ObjectStore_Timeout(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	path := MustWriteTSM(dir, 1, map[string][]tsm1.Value{
+		"cpu": {tsm1.NewValue(1, 1.0)},
+	})
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stubPath := filepath.Join(dir, "stub.tsm")
+	stub, err := os.Create(stubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tsm1.WriteRemoteStub(stub, "obj", f, stat.Size()); err != nil {
+		t.Fatal(err)
+	}
+	stub.Close()
+
+	fd, err := os.Open(stubPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := tsm1.NewTSMReader(fd,
+		tsm1.WithTSMReaderObjectStore(stalledStore{}, nil),
+		tsm1.WithTSMReaderObjectStoreTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Neither reads nor removing the file hang on a stalled store.
+	if _, err := r.ReadAll([]byte("cpu")); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Fatalf("got %v, want deadline exceeded", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
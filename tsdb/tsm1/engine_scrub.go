@@ -0,0 +1,293 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"hash/crc32"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/limiter"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ScrubFiles verifies the block checksums and index of every local TSM file,
+// reading no faster than the scrub throughput. Corrupt files are quarantined:
+// they are removed from the file store and renamed with the
+// BadTSMFileExtension, so that queries and compactions no longer read them.
+//
+// Scrubbing stops while level compactions are disabled, such as during a
+// delete. The next call resumes with the files not yet verified, so that a
+// scrub completes even if it is interrupted regularly. It returns the paths
+// of the files quarantined.
+func (e *Engine) ScrubFiles(ctx context.Context) ([]string, error) {
+	e.mu.RLock()
+	quit, wg := e.done, e.wg
+	if quit == nil {
+		e.mu.RUnlock()
+		return nil, nil
+	}
+	wg.Add(1)
+	e.mu.RUnlock()
+	defer wg.Done()
+
+	// Stop verifying as soon as level compactions are disabled.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	e.scrubMu.Lock()
+	defer e.scrubMu.Unlock()
+	if e.scrubbed == nil {
+		e.scrubbed = make(map[string]struct{})
+	}
+
+	var files unrefs
+	e.FileStore.ForEachFile(func(f TSMFile) bool {
+		if _, ok := e.scrubbed[f.Path()]; !ok {
+			f.Ref()
+			files = append(files, f)
+		}
+		return true
+	})
+	defer func() { files.Unref() }()
+
+	var quarantined []string
+	for len(files) > 0 {
+		f := files[0]
+		path := f.Path()
+
+		var verr error
+		if !f.Stats().Remote {
+			var blocks int
+			blocks, verr = e.verifyFile(ctx, f)
+			e.scrubTracker.AddBlocks(blocks)
+		}
+		if err := ctx.Err(); err != nil {
+			select {
+			case <-quit:
+				return quarantined, nil
+			default:
+				return quarantined, err
+			}
+		}
+
+		f.Unref()
+		files = files[1:]
+		e.scrubbed[path] = struct{}{}
+
+		if verr == nil {
+			e.scrubTracker.IncFiles(true)
+			continue
+		}
+		e.scrubTracker.IncFiles(false)
+
+		e.logger.Error("Corrupt TSM file found by scrub", zap.String("path", path), zap.Error(verr))
+		if ok, err := e.FileStore.Quarantine(f); err != nil {
+			return quarantined, fmt.Errorf("quarantining %s: %v", path, err)
+		} else if ok {
+			e.logger.Warn("Quarantined corrupt TSM file", zap.String("path", path+"."+BadTSMFileExtension))
+			quarantined = append(quarantined, path)
+		}
+	}
+
+	// Every file has been verified, so the next scrub starts over.
+	e.scrubbed = nil
+	e.lastScrub = time.Now()
+	e.scrubTracker.SetLastScrub(e.lastScrub)
+	if err := e.updateQuarantined(); err != nil {
+		return quarantined, err
+	}
+	return quarantined, nil
+}
+
+// QuarantinedFiles returns the paths of the corrupt TSM files moved aside by
+// scrubbing or when the engine was opened. They are reported until they are
+// removed or restored by an operator.
+func (e *Engine) QuarantinedFiles() ([]string, error) {
+	return e.FileStore.QuarantinedFiles()
+}
+
+// updateQuarantined updates the metric of the number of quarantined files.
+func (e *Engine) updateQuarantined() error {
+	paths, err := e.QuarantinedFiles()
+	if err != nil {
+		return err
+	}
+	e.scrubTracker.SetQuarantined(len(paths))
+	return nil
+}
+
+// verifyFile checks the checksum of every block of f, and that the index
+// holds the keys in order and the time range of each block. It returns the
+// number of blocks verified, and an error describing the first inconsistency
+// found. If ctx is cancelled, it returns the context's error before reading
+// the next block, even if the scrub is not throttled.
+func (e *Engine) verifyFile(ctx context.Context, f TSMFile) (int, error) {
+	var (
+		blocks int
+		prev   []byte
+		ts     cursors.TimestampArray
+	)
+
+	iter := f.BlockIterator()
+	for iter.Next() {
+		if err := ctx.Err(); err != nil {
+			return blocks, err
+		}
+		key, minTime, maxTime, typ, checksum, buf, err := iter.Read()
+		if err != nil {
+			return blocks, fmt.Errorf("reading block %d: %v", blocks, err)
+		}
+		if err := waitN(ctx, e.scrubLimiter, len(buf)); err != nil {
+			return blocks, err
+		}
+
+		if bytes.Compare(key, prev) < 0 {
+			return blocks, fmt.Errorf("index key %q out of order after %q", key, prev)
+		}
+		prev = append(prev[:0], key...)
+
+		if exp := crc32.ChecksumIEEE(buf); checksum != exp {
+			return blocks, fmt.Errorf("unexpected checksum %d, expected %d for key %q, block %d", checksum, exp, key, blocks)
+		}
+		if len(buf) == 0 || buf[0] != typ {
+			return blocks, fmt.Errorf("block %d of key %q does not match the index type %s", blocks, key, BlockTypeName(typ))
+		}
+		if err := DecodeTimestampArrayBlock(buf, &ts); err != nil {
+			return blocks, fmt.Errorf("unable to decode timestamps for key %q, block %d: %v", key, blocks, err)
+		}
+		if ts.Len() == 0 || ts.MinTime() != minTime || ts.MaxTime() != maxTime {
+			return blocks, fmt.Errorf("index time range %d-%d does not match block %d of key %q", minTime, maxTime, blocks, key)
+		}
+		blocks++
+	}
+	if err := iter.Err(); err != nil {
+		return blocks, fmt.Errorf("reading index: %v", err)
+	}
+	return blocks, nil
+}
+
+// waitN waits until the rate limiter allows n bytes to be read. A nil
+// limiter allows everything.
+func waitN(ctx context.Context, l limiter.Rate, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		m := n
+		if burst := l.Burst(); m > burst {
+			m = burst
+		}
+		if err := l.WaitN(ctx, m); err != nil {
+			return err
+		}
+		n -= m
+	}
+	return nil
+}
+
+// scrub scrubs the TSM files every scrub interval until quit is closed. A
+// scrub interrupted by quit resumes when level compactions are re-enabled.
+func (e *Engine) scrub(quit <-chan struct{}) {
+	if e.scrubInterval <= 0 {
+		return
+	}
+
+	check := time.Minute
+	if e.scrubInterval < check {
+		check = e.scrubInterval
+	}
+	t := time.NewTicker(check)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-quit:
+			return
+		case <-t.C:
+			e.scrubMu.Lock()
+			due := time.Since(e.lastScrub) >= e.scrubInterval
+			e.scrubMu.Unlock()
+			if !due {
+				continue
+			}
+
+			start := time.Now()
+			paths, err := e.ScrubFiles(context.Background())
+			if err != nil {
+				e.logger.Error("Error scrubbing TSM files", zap.Error(err))
+				continue
+			}
+			e.logger.Info("Scrubbed TSM files",
+				zap.Int("quarantined", len(paths)),
+				zap.Duration("duration", time.Since(start)))
+		}
+	}
+}
+
+// scrubTracker tracks the files and blocks verified by scrubbing.
+type scrubTracker struct {
+	metrics *scrubMetrics
+	labels  prometheus.Labels
+
+	files       uint64
+	corrupt     uint64
+	blocks      uint64
+	quarantined uint64
+}
+
+func newScrubTracker(metrics *scrubMetrics, defaultLabels prometheus.Labels) *scrubTracker {
+	return &scrubTracker{metrics: metrics, labels: defaultLabels}
+}
+
+// Labels returns a copy of the default labels used by the tracker's metrics.
+// The returned map is safe for modification.
+func (t *scrubTracker) Labels() prometheus.Labels {
+	labels := make(prometheus.Labels, len(t.labels))
+	for k, v := range t.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// IncFiles increments the number of files scrubbed, either intact or corrupt.
+func (t *scrubTracker) IncFiles(ok bool) {
+	status := "ok"
+	if ok {
+		atomic.AddUint64(&t.files, 1)
+	} else {
+		atomic.AddUint64(&t.corrupt, 1)
+		status = "corrupt"
+	}
+
+	labels := t.Labels()
+	labels["status"] = status
+	t.metrics.Files.With(labels).Inc()
+}
+
+// AddBlocks increases the number of blocks verified.
+func (t *scrubTracker) AddBlocks(n int) {
+	atomic.AddUint64(&t.blocks, uint64(n))
+	t.metrics.Blocks.With(t.labels).Add(float64(n))
+}
+
+// SetQuarantined sets the number of quarantined files.
+func (t *scrubTracker) SetQuarantined(n int) {
+	atomic.StoreUint64(&t.quarantined, uint64(n))
+	t.metrics.Quarantined.With(t.labels).Set(float64(n))
+}
+
+// SetLastScrub sets the time the last scrub completed.
+func (t *scrubTracker) SetLastScrub(at time.Time) {
+	t.metrics.LastScrub.With(t.labels).Set(float64(at.UnixNano()) / float64(time.Second))
+}
@@ -0,0 +1,70 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+)
+
+// cancelAfter is a context that is cancelled once its error has been
+// checked n times.
+type cancelAfter struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestEngine_verifyFile_Cancel(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		key := []byte(fmt.Sprintf("cpu%d", i))
+		if err := w.Write(key, []Value{NewValue(int64(i), float64(i))}); err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	fd, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error opening: %v", err)
+	}
+	r, err := NewTSMReader(fd)
+	if err != nil {
+		t.Fatalf("unexpected error creating reader: %v", err)
+	}
+	defer r.Close()
+
+	// Without a scrub limiter, nothing waits on the context.
+	e := &Engine{}
+	if blocks, err := e.verifyFile(context.Background(), r); err != nil || blocks != 5 {
+		t.Fatalf("got %d blocks and error %v, exp 5 blocks", blocks, err)
+	}
+
+	ctx := &cancelAfter{Context: context.Background(), n: 2}
+	blocks, err := e.verifyFile(ctx, r)
+	if err != context.Canceled {
+		t.Fatalf("got error %v, exp %v", err, context.Canceled)
+	}
+	if blocks != 2 {
+		t.Fatalf("verified %d blocks after cancellation, exp 2", blocks)
+	}
+}
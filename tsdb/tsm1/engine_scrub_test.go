@@ -0,0 +1,101 @@
+package tsm1_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+func TestEngine_ScrubFiles(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataDir := filepath.Join(e.root, "data")
+	if err := os.MkdirAll(dataDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+	goodKey := "mm0,\x00=cpu,host=A,\xff=value#!~#value"
+	goodValues := []tsm1.Value{tsm1.NewValue(1, 1.0), tsm1.NewValue(2, 2.0)}
+	good := MustWriteTSM(dataDir, 1, map[string][]tsm1.Value{goodKey: goodValues})
+	bad := MustWriteTSM(dataDir, 2, map[string][]tsm1.Value{
+		"mm1,\x00=cpu,host=A,\xff=value#!~#value": {tsm1.NewValue(3, 3.0)},
+	})
+
+	// Flip a bit in the first block of the second file, past its header
+	// and the block checksum.
+	f, err := os.OpenFile(bad, os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, 12); err != nil {
+		t.Fatal(err)
+	}
+	b[0] ^= 0x01
+	if _, err := f.WriteAt(b, 12); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer e.Close()
+
+	// Nothing is scrubbed while compactions are disabled.
+	e.SetCompactionsEnabled(false)
+	if paths, err := e.ScrubFiles(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 0 {
+		t.Fatalf("quarantined %v with compactions disabled", paths)
+	}
+	e.SetCompactionsEnabled(true)
+
+	paths, err := e.ScrubFiles(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{bad}; !reflect.DeepEqual(paths, exp) {
+		t.Fatalf("quarantined %v, exp %v", paths, exp)
+	}
+
+	if got, exp := e.FileStore.Count(), 1; got != exp {
+		t.Fatalf("got %d files, exp %d", got, exp)
+	}
+	if got := e.FileStore.Files()[0].Path(); got != good {
+		t.Fatalf("got file %s, exp %s", got, good)
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Fatalf("corrupt file not moved aside: %v", err)
+	}
+
+	quarantined, err := e.QuarantinedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{bad + "." + tsm1.BadTSMFileExtension}; !reflect.DeepEqual(quarantined, exp) {
+		t.Fatalf("got quarantined files %v, exp %v", quarantined, exp)
+	}
+
+	// The intact file is still read, and the next scrub finds nothing.
+	values, err := e.FileStore.Read([]byte(goodKey), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(values, goodValues) {
+		t.Fatalf("read %v, exp %v", values, goodValues)
+	}
+	if paths, err := e.ScrubFiles(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if len(paths) != 0 {
+		t.Fatalf("quarantined %v, exp none", paths)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/influxdata/influxdb/pkg/fs"
 	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/pkg/metrics"
+	"github.com/influxdata/influxdb/pkg/objstore"
 	"github.com/influxdata/influxdb/query"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/prometheus/client_golang/prometheus"
@@ -126,6 +127,9 @@ type TSMFile interface {
 	// written for this file.
 	TombstoneFiles() []FileStat
 
+	// WalkTombstones calls fn for every tombstone written for this file.
+	WalkTombstones(fn func(t Tombstone) error) error
+
 	// Close closes the underlying file resources.
 	Close() error
 
@@ -217,12 +221,20 @@ type FileStore struct {
 	parseFileName ParseFileNameFunc
 
 	obs FileStoreObserver
+
+	objectStore        objstore.Store // store of offloaded files, if any
+	blockCache         *BlockCache
+	objectStoreTimeout time.Duration // of each request to objectStore
+
+	statsMu     sync.Mutex
+	bucketStats map[string]*fileBucketStats // by file path
 }
 
 // FileStat holds information about a TSM file on disk.
 type FileStat struct {
 	Path             string
 	HasTombstone     bool
+	Remote           bool // blocks are stored in an object store
 	Size             uint32
 	LastModified     int64
 	MinTime, MaxTime int64
@@ -272,6 +284,29 @@ func (f *FileStore) WithObserver(obs FileStoreObserver) {
 	f.obs = obs
 }
 
+// WithObjectStore sets the object store that TSM files may be offloaded to.
+// Blocks read from offloaded files are cached in cache, which may be nil.
+func (f *FileStore) WithObjectStore(store objstore.Store, cache *BlockCache) {
+	f.objectStore = store
+	f.blockCache = cache
+}
+
+// WithObjectStoreTimeout limits each read from and delete in the object store
+// to timeout. Requests are not limited when it is zero.
+func (f *FileStore) WithObjectStoreTimeout(timeout time.Duration) {
+	f.objectStoreTimeout = timeout
+}
+
+// readerOptions returns the options for opening the TSM files of the store.
+func (f *FileStore) readerOptions() []tsmReaderOption {
+	return []tsmReaderOption{
+		WithMadviseWillNeed(f.tsmMMAPWillNeed),
+		WithTSMReaderLogger(f.logger),
+		WithTSMReaderObjectStore(f.objectStore, f.blockCache),
+		WithTSMReaderObjectStoreTimeout(f.objectStoreTimeout),
+	}
+}
+
 func (f *FileStore) WithParseFileNameFunc(parseFileNameFunc ParseFileNameFunc) {
 	f.parseFileName = parseFileNameFunc
 }
@@ -589,6 +624,46 @@ func (f *FileStore) DeleteRange(keys [][]byte, min, max int64) error {
 	return nil
 }
 
+// RemovePrefixRange removes the files holding only values of keys with the
+// given prefix between timestamps min and max, such as the expired
+// partitions of a partitioned bucket, and returns how many were removed. fn
+// is called with each key of the removed files.
+func (f *FileStore) RemovePrefixRange(prefix []byte, min, max int64, fn func(key []byte)) (int, error) {
+	var files []TSMFile
+	f.mu.RLock()
+	for _, r := range f.files {
+		minKey, maxKey := r.KeyRange()
+		minTime, maxTime := r.TimeRange()
+		if bytes.HasPrefix(minKey, prefix) && bytes.HasPrefix(maxKey, prefix) && min <= minTime && maxTime <= max {
+			r.Ref()
+			files = append(files, r)
+		}
+	}
+	f.mu.RUnlock()
+
+	paths := make([]string, 0, len(files))
+	for _, r := range files {
+		iter := r.Iterator(nil)
+		for iter.Next() {
+			fn(iter.Key())
+		}
+		err := iter.Err()
+		paths = append(paths, r.Path())
+		r.Unref()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(paths) == 0 {
+		return 0, nil
+	}
+	if err := f.Replace(paths, nil); err != nil {
+		return 0, err
+	}
+	return len(paths), nil
+}
+
 // Open loads all the TSM files in the configured directory.
 func (f *FileStore) Open(ctx context.Context) error {
 	f.mu.Lock()
@@ -661,14 +736,20 @@ func (f *FileStore) Open(ctx context.Context) error {
 			defer f.openLimiter.Release()
 
 			start := time.Now()
-			df, err := NewTSMReader(file,
-				WithMadviseWillNeed(f.tsmMMAPWillNeed),
-				WithTSMReaderLogger(f.logger))
+			df, err := NewTSMReader(file, f.readerOptions()...)
 			f.logger.Info("Opened file",
 				zap.String("path", file.Name()),
 				zap.Int("id", idx),
 				zap.Duration("duration", time.Since(start)))
 
+			// An offloaded file is not corrupt; its object store is missing
+			// from the configuration.
+			if err == ErrNoObjectStore {
+				file.Close()
+				readerC <- &res{err: fmt.Errorf("cannot open %s: %v", file.Name(), err)}
+				return
+			}
+
 			// If we are unable to read a TSM file then log the error, rename
 			// the file, and continue loading the shard without it.
 			if err != nil {
@@ -902,9 +983,7 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 			}
 		}
 
-		tsm, err := NewTSMReader(fd,
-			WithMadviseWillNeed(f.tsmMMAPWillNeed),
-			WithTSMReaderLogger(f.logger))
+		tsm, err := NewTSMReader(fd, f.readerOptions()...)
 		if err != nil {
 			return err
 		}
@@ -1024,10 +1103,15 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	f.lastFileStats = nil
 	f.files = active
 	sort.Sort(tsmReaders(f.files))
+	return f.recalculateTracker()
+}
+
+// recalculateTracker recalculates the disk size and file count stats. It
+// must be called with f.mu held.
+func (f *FileStore) recalculateTracker() error {
 	f.tracker.ClearFileCounts()
 	f.tracker.ClearDiskSizes()
 
-	// Recalculate the disk size stat
 	sizes := make(map[int]uint64, 4)
 	counts := make(map[int]uint64, 4)
 	for _, file := range f.files {
@@ -1044,10 +1128,111 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	}
 	f.tracker.SetBytes(sizes)
 	f.tracker.SetFileCount(counts)
-
 	return nil
 }
 
+// replaceWithStub replaces the local TSM file old with the stub at stubPath,
+// whose object has already been uploaded. The stub is renamed over the
+// file and queries still using old finish against the local copy. It
+// returns false without renaming the stub if old is no longer in the
+// store, such as when a compaction has replaced it.
+func (f *FileStore) replaceWithStub(old TSMFile, stubPath string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := -1
+	for i, file := range f.files {
+		if file == old {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false, nil
+	}
+
+	path := old.Path()
+	if err := f.obs.FileFinishing(stubPath); err != nil {
+		return false, err
+	}
+	if err := fs.RenameFileWithReplacement(stubPath, path); err != nil {
+		return false, err
+	}
+	if err := fs.SyncDir(f.dir); err != nil {
+		return true, err
+	}
+
+	// The old reader keeps serving from its mapping of the unlinked file
+	// if the stub cannot be opened; the stub is loaded on restart.
+	fd, err := os.Open(path)
+	if err != nil {
+		return true, err
+	}
+	tsm, err := NewTSMReader(fd, f.readerOptions()...)
+	if err != nil {
+		fd.Close()
+		return true, err
+	}
+	tsm.WithObserver(f.obs)
+
+	f.files[idx] = tsm
+	f.lastFileStats = nil
+	// Let the planner see that the file has been offloaded.
+	f.lastModified = f.lastModified.Add(1)
+
+	go func() {
+		if err := old.Close(); err != nil {
+			f.logger.Info("Error closing offloaded file", zap.String("path", path), zap.Error(err))
+		}
+	}()
+	return true, f.recalculateTracker()
+}
+
+// Quarantine removes the corrupt file from the store and renames it with the
+// BadTSMFileExtension, so that it is not loaded again. Queries already reading
+// the file finish against it. It returns false if the file is no longer in
+// the store.
+func (f *FileStore) Quarantine(file TSMFile) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	idx := -1
+	for i, tsm := range f.files {
+		if tsm == file {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return false, nil
+	}
+
+	path := file.Path()
+	if err := file.Rename(path + "." + BadTSMFileExtension); err != nil {
+		return false, err
+	}
+	if err := fs.SyncDir(f.dir); err != nil {
+		return true, err
+	}
+
+	f.files = append(f.files[:idx:idx], f.files[idx+1:]...)
+	f.lastFileStats = nil
+	f.lastModified = f.lastModified.Add(1)
+
+	go func() {
+		if err := file.Close(); err != nil {
+			f.logger.Info("Error closing quarantined file", zap.String("path", path), zap.Error(err))
+		}
+	}()
+	return true, f.recalculateTracker()
+}
+
+// QuarantinedFiles returns the paths of the corrupt TSM files that were moved
+// aside, either when the store was opened or by Quarantine.
+func (f *FileStore) QuarantinedFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(f.dir, "*."+TSMFileExtension+"."+BadTSMFileExtension))
+}
+
 // LastModified returns the last time the file store was updated with new
 // TSM files or a delete.
 func (f *FileStore) LastModified() time.Time {
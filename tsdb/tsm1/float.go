@@ -19,8 +19,17 @@ import (
 )
 
 // Note: an uncompressed format is not yet implemented.
-// floatCompressedGorilla is a compressed format using the gorilla paper encoding
-const floatCompressedGorilla = 1
+const (
+	// floatCompressedGorilla is a compressed format using the gorilla paper encoding
+	floatCompressedGorilla = 1
+
+	// floatCompressedZstd is a compressed format storing the bytes of all
+	// values by significance, the first bytes of every value followed by the
+	// second bytes and so on, compressed using zstd. It suits noisy values
+	// that have little in common with their predecessors better than the
+	// gorilla encoding.
+	floatCompressedZstd = 2
+)
 
 // uvnan is the constant returned from math.NaN().
 const uvnan = 0x7FF8000000000001
@@ -151,11 +160,32 @@ type FloatDecoder struct {
 	first    bool
 	finished bool
 
+	// Values of zstd encoded blocks are decoded by SetBytes.
+	unpacked bool
+	vals     []float64
+	i        int
+
 	err error
 }
 
 // SetBytes initializes the decoder with b. Must call before calling Next().
 func (it *FloatDecoder) SetBytes(b []byte) error {
+	if len(b) > 0 && b[0]>>4 == floatCompressedZstd {
+		vals, err := floatArrayDecodeZstd(b, it.vals)
+		if err != nil {
+			return err
+		}
+
+		it.b = b
+		it.unpacked = true
+		it.vals = vals
+		it.i = 0
+		it.finished = false
+		it.err = nil
+		return nil
+	}
+	it.unpacked = false
+
 	var v uint64
 	if len(b) == 0 {
 		v = uvnan
@@ -189,6 +219,16 @@ func (it *FloatDecoder) Next() bool {
 		return false
 	}
 
+	if it.unpacked {
+		if it.i == len(it.vals) {
+			it.finished = true
+			return false
+		}
+		it.val = math.Float64bits(it.vals[it.i])
+		it.i++
+		return true
+	}
+
 	if it.first {
 		it.first = false
 
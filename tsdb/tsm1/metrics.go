@@ -26,6 +26,7 @@ func PrometheusCollectors() []prometheus.Collector {
 		collectors = append(collectors, bms.fileMetrics.PrometheusCollectors()...)
 		collectors = append(collectors, bms.cacheMetrics.PrometheusCollectors()...)
 		collectors = append(collectors, bms.readMetrics.PrometheusCollectors()...)
+		collectors = append(collectors, bms.scrubMetrics.PrometheusCollectors()...)
 	}
 	return collectors
 }
@@ -37,6 +38,7 @@ const compactionSubsystem = "compactions" // sub-system associated with metrics
 const fileStoreSubsystem = "tsm_files"    // sub-system associated with metrics for TSM files.
 const cacheSubsystem = "cache"            // sub-system associated with metrics for the cache.
 const readSubsystem = "reads"             // sub-system associated with metrics for reads.
+const scrubSubsystem = "scrub"            // sub-system associated with metrics for scrubbing.
 
 // blockMetrics are a set of metrics concerned with tracking data about block storage.
 type blockMetrics struct {
@@ -45,6 +47,7 @@ type blockMetrics struct {
 	*fileMetrics
 	*cacheMetrics
 	*readMetrics
+	*scrubMetrics
 }
 
 // newBlockMetrics initialises the prometheus metrics for the block subsystem.
@@ -55,6 +58,7 @@ func newBlockMetrics(labels prometheus.Labels) *blockMetrics {
 		fileMetrics:       newFileMetrics(labels),
 		cacheMetrics:      newCacheMetrics(labels),
 		readMetrics:       newReadMetrics(labels),
+		scrubMetrics:      newScrubMetrics(labels),
 	}
 }
 
@@ -65,6 +69,7 @@ func (m *blockMetrics) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, m.fileMetrics.PrometheusCollectors()...)
 	metrics = append(metrics, m.cacheMetrics.PrometheusCollectors()...)
 	metrics = append(metrics, m.readMetrics.PrometheusCollectors()...)
+	metrics = append(metrics, m.scrubMetrics.PrometheusCollectors()...)
 	return metrics
 }
 
@@ -288,3 +293,61 @@ func (m *readMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.Seeks,
 	}
 }
+
+// scrubMetrics are a set of metrics concerned with tracking the scrubbing of
+// TSM files.
+type scrubMetrics struct {
+	Files       *prometheus.CounterVec
+	Blocks      *prometheus.CounterVec
+	Quarantined *prometheus.GaugeVec
+	LastScrub   *prometheus.GaugeVec
+}
+
+// newScrubMetrics initialises the prometheus metrics for tracking scrubbing.
+func newScrubMetrics(labels prometheus.Labels) *scrubMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	filesNames := append(append([]string(nil), names...), "status")
+	sort.Strings(filesNames)
+
+	return &scrubMetrics{
+		Files: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: scrubSubsystem,
+			Name:      "files_total",
+			Help:      "Number of TSM files scrubbed.",
+		}, filesNames),
+		Blocks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: scrubSubsystem,
+			Name:      "blocks_total",
+			Help:      "Number of TSM blocks verified.",
+		}, names),
+		Quarantined: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: scrubSubsystem,
+			Name:      "quarantined_files",
+			Help:      "Number of corrupt TSM files moved aside.",
+		}, names),
+		LastScrub: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: scrubSubsystem,
+			Name:      "last_completed_timestamp_seconds",
+			Help:      "Time the last scrub of all TSM files completed.",
+		}, names),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *scrubMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.Files,
+		m.Blocks,
+		m.Quarantined,
+		m.LastScrub,
+	}
+}
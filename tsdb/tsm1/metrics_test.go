@@ -216,3 +216,48 @@ func TestMetrics_Compactions(t *testing.T) {
 		}
 	}
 }
+
+func TestMetrics_Scrub(t *testing.T) {
+	// metrics to be shared by multiple engines.
+	metrics := newScrubMetrics(prometheus.Labels{"engine_id": "", "node_id": ""})
+	t1 := newScrubTracker(metrics, prometheus.Labels{"engine_id": "0", "node_id": "0"})
+	t2 := newScrubTracker(metrics, prometheus.Labels{"engine_id": "1", "node_id": "0"})
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics.PrometheusCollectors()...)
+
+	// Generate some measurements.
+	t1.IncFiles(true)
+	t1.IncFiles(true)
+	t1.IncFiles(false)
+	t1.AddBlocks(10)
+	t2.SetQuarantined(3)
+
+	// Test that all the correct metrics are present.
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := namespace + "_" + scrubSubsystem + "_"
+	m1Ok := promtest.MustFindMetric(t, mfs, base+"files_total", prometheus.Labels{"engine_id": "0", "node_id": "0", "status": "ok"})
+	m1Corrupt := promtest.MustFindMetric(t, mfs, base+"files_total", prometheus.Labels{"engine_id": "0", "node_id": "0", "status": "corrupt"})
+	m1Blocks := promtest.MustFindMetric(t, mfs, base+"blocks_total", prometheus.Labels{"engine_id": "0", "node_id": "0"})
+	m2Quarantined := promtest.MustFindMetric(t, mfs, base+"quarantined_files", prometheus.Labels{"engine_id": "1", "node_id": "0"})
+
+	if m, got, exp := m1Ok, m1Ok.GetCounter().GetValue(), 2.0; got != exp {
+		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	}
+
+	if m, got, exp := m1Corrupt, m1Corrupt.GetCounter().GetValue(), 1.0; got != exp {
+		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	}
+
+	if m, got, exp := m1Blocks, m1Blocks.GetCounter().GetValue(), 10.0; got != exp {
+		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	}
+
+	if m, got, exp := m2Quarantined, m2Quarantined.GetGauge().GetValue(), 3.0; got != exp {
+		t.Errorf("[%s] got %v, expected %v", m, got, exp)
+	}
+}
@@ -0,0 +1,300 @@
+package tsm1
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+)
+
+// Partitions holds the duration of the time partitions that the data of each
+// bucket is split into when it is written to TSM files. Every file written
+// for a partitioned bucket holds data of that bucket only, within a single
+// partition, so that data past a retention period can be removed by
+// unlinking whole files rather than by tombstoning and rewriting them.
+//
+// A nil Partitions partitions no buckets.
+type Partitions struct {
+	mu        sync.RWMutex
+	durations map[string]int64 // partition duration by bucket name
+}
+
+// NewPartitions returns a new Partitions that partitions no buckets.
+func NewPartitions() *Partitions {
+	return &Partitions{durations: make(map[string]int64)}
+}
+
+// SetDurations replaces the partition durations of all buckets, keyed by the
+// encoded bucket name. Buckets without a positive duration are not
+// partitioned.
+func (p *Partitions) SetDurations(durations map[string]time.Duration) {
+	m := make(map[string]int64, len(durations))
+	for name, d := range durations {
+		if d > 0 {
+			m[name] = int64(d)
+		}
+	}
+
+	p.mu.Lock()
+	p.durations = m
+	p.mu.Unlock()
+}
+
+// Duration returns the partition duration of the bucket name, or 0 if the
+// bucket is not partitioned.
+func (p *Partitions) Duration(name []byte) time.Duration {
+	return time.Duration(p.snapshot()[string(name)])
+}
+
+// Enabled returns true if any bucket is partitioned.
+func (p *Partitions) Enabled() bool {
+	return len(p.snapshot()) > 0
+}
+
+// snapshot returns the current durations. The map is replaced rather than
+// modified, so it may be read without holding the lock.
+func (p *Partitions) snapshot() map[string]int64 {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.durations
+}
+
+// timePartition identifies a time partition of a bucket. The zero value holds
+// the data of buckets that are not partitioned.
+type timePartition struct {
+	name  string
+	start int64
+}
+
+// partitionStart returns the start of the partition of duration d holding t.
+func partitionStart(t, d int64) int64 {
+	start := t - t%d
+	if t%d < 0 {
+		if start < math.MinInt64+d {
+			return math.MinInt64
+		}
+		start -= d
+	}
+	return start
+}
+
+// filePartitions returns the partition holding all of the data of each file
+// in stats. Files holding data of an unpartitioned bucket, or of more than one
+// partition, are not in the returned map.
+func filePartitions(durations map[string]int64, stats []FileStat) map[string]timePartition {
+	parts := make(map[string]timePartition, len(stats))
+	for _, st := range stats {
+		name := models.ParseName(st.MinKey)
+		if !bytes.Equal(name, models.ParseName(st.MaxKey)) {
+			continue
+		}
+		d := durations[string(name)]
+		if d == 0 {
+			continue
+		}
+		if start := partitionStart(st.MinTime, d); start == partitionStart(st.MaxTime, d) {
+			parts[st.Path] = timePartition{name: string(name), start: start}
+		}
+	}
+	return parts
+}
+
+// writePartitionedFiles writes from the iterator into new TSM files such that
+// each file holds the data of a single partition of a partitioned bucket. The
+// data of unpartitioned buckets is written to shared files as by
+// writeNewFiles. Blocks spanning partitions are split.
+//
+// The level of a generation is the sequence number of its first file, so
+// while the output is below level 4 its files are numbered from the level in
+// steps of 4. That keeps the level of the generation and leaves the numbers
+// of the next level free.
+func (c *Compactor) writePartitionedFiles(generation, sequence int, src []string, iter KeyIterator, throttle bool) (files []string, err error) {
+	durations := c.Partitions.snapshot()
+
+	// Compactions write the level above that of their newest files, and
+	// snapshots write level 1.
+	var minSequence int
+	for _, path := range src {
+		gen, seq, err := c.parseFileName(path)
+		if err != nil {
+			return nil, err
+		}
+		if gen == generation && (minSequence == 0 || seq < minSequence) {
+			minSequence = seq
+		}
+	}
+	level, step := minSequence+1, 4
+	if level >= 4 {
+		step = 1
+	} else {
+		sequence = level - step
+	}
+
+	writers := make(map[timePartition]TSMWriter)
+	paths := make(map[timePartition]string)
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		for _, w := range writers {
+			w.Remove()
+		}
+		for _, f := range files {
+			os.RemoveAll(f)
+			os.RemoveAll(StatsFilename(f))
+		}
+		files = nil
+	}()
+
+	// finish completes the file of partition p.
+	finish := func(p timePartition) error {
+		w, path := writers[p], paths[p]
+		delete(writers, p)
+		delete(paths, p)
+
+		if err := w.WriteIndex(); err == ErrNoValues {
+			return w.Remove()
+		} else if err != nil {
+			w.Remove()
+			return err
+		}
+		if err := w.Close(); err != nil {
+			w.Remove()
+			return err
+		}
+		files = append(files, path)
+		return nil
+	}
+
+	write := func(p timePartition, key []byte, minTime, maxTime int64, block []byte) error {
+		block, err := c.Compression.Recompress(block)
+		if err != nil {
+			return err
+		}
+
+		w := writers[p]
+		if w == nil {
+			// New TSM files are written to a temp file and renamed when fully
+			// completed. Skip the numbers of any files already in the way.
+			var path string
+			for {
+				sequence += step
+				path = filepath.Join(c.Dir, c.formatFileName(generation, sequence)+"."+TSMFileExtension)
+				if _, err := os.Stat(path); os.IsNotExist(err) {
+					break
+				}
+			}
+			path += "." + TmpTSMFileExtension
+
+			if w, err = c.newTSMWriter(path, iter, throttle); err != nil {
+				return err
+			}
+			writers[p], paths[p] = w, path
+		}
+
+		// Start a new file once the max file size or block count is reached.
+		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
+			return finish(p)
+		} else if err != nil {
+			return err
+		}
+		if w.Size() > maxTSMFileSize {
+			return finish(p)
+		}
+		return nil
+	}
+
+	var (
+		name []byte
+		d    int64
+	)
+	for iter.Next() {
+		c.mu.RLock()
+		enabled := c.snapshotsEnabled || c.compactionsEnabled
+		c.mu.RUnlock()
+
+		if !enabled {
+			return nil, errCompactionAborted{}
+		}
+
+		key, minTime, maxTime, block, err := iter.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		if minTime > maxTime {
+			return nil, fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
+		}
+
+		// Keys are sorted, so the partitions of the previous bucket are
+		// complete once the bucket changes.
+		if n := models.ParseName(key); !bytes.Equal(n, name) {
+			for p := range writers {
+				if p.name == "" {
+					continue
+				}
+				if err := finish(p); err != nil {
+					return nil, err
+				}
+			}
+			name = append(name[:0], n...)
+			d = durations[string(name)]
+		}
+
+		if d == 0 {
+			if err := write(timePartition{}, key, minTime, maxTime, block); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if start := partitionStart(minTime, d); start == partitionStart(maxTime, d) {
+			if err := write(timePartition{name: string(name), start: start}, key, minTime, maxTime, block); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		values, err := DecodeBlock(block, nil)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(values); {
+			start := partitionStart(values[i].UnixNano(), d)
+			j := i + 1
+			for j < len(values) && partitionStart(values[j].UnixNano(), d) == start {
+				j++
+			}
+
+			b, err := Values(values[i:j]).Encode(nil)
+			if err != nil {
+				return nil, err
+			}
+			if err := write(timePartition{name: string(name), start: start}, key, values[i].UnixNano(), values[j-1].UnixNano(), b); err != nil {
+				return nil, err
+			}
+			i = j
+		}
+	}
+
+	// Were there any errors encountered during iteration?
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+
+	for p := range writers {
+		if err := finish(p); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
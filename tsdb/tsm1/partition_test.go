@@ -0,0 +1,154 @@
+package tsm1_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/tsm1"
+)
+
+// Tests that snapshots of partitioned buckets never write files spanning
+// partitions, and split blocks that do.
+func TestCompactor_Snapshot_Partitioned(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	var cpu []tsm1.Value
+	for ts := int64(0); ts < 30; ts += 3 {
+		cpu = append(cpu, tsm1.NewValue(ts, float64(ts)))
+	}
+	mem := []tsm1.Value{tsm1.NewValue(1, 1.0), tsm1.NewValue(25, 2.0)}
+
+	c := tsm1.NewCache(0)
+	if err := c.Write([]byte("cpu,host=A#!~#value"), cpu); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write([]byte("mem,host=A#!~#value"), mem); err != nil {
+		t.Fatal(err)
+	}
+
+	partitions := tsm1.NewPartitions()
+	partitions.SetDurations(map[string]time.Duration{"cpu": 10})
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = &fakeFileStore{}
+	compactor.Partitions = partitions
+	compactor.Open()
+
+	files, err := compactor.WriteSnapshot(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error writing snapshot: %v", err)
+	}
+	sort.Strings(files)
+
+	// Three files for the partitions of cpu, and one for mem.
+	if got, exp := len(files), 4; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	var got []tsm1.Value
+	for _, f := range files {
+		r := MustOpenTSMReader(f)
+		if r.Contains([]byte("mem,host=A#!~#value")) {
+			if r.Contains([]byte("cpu,host=A#!~#value")) {
+				t.Fatalf("file %s holds data of partitioned and unpartitioned buckets", f)
+			}
+			r.Close()
+			continue
+		}
+
+		min, max := r.TimeRange()
+		if min/10 != max/10 {
+			t.Fatalf("file %s spans partitions: min=%d, max=%d", f, min, max)
+		}
+		values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, values...)
+		r.Close()
+	}
+
+	sort.Slice(got, func(i, j int) bool { return got[i].UnixNano() < got[j].UnixNano() })
+	if len(got) != len(cpu) {
+		t.Fatalf("values length mismatch: got %v, exp %v", len(got), len(cpu))
+	}
+	for i := range cpu {
+		assertValueEqual(t, got[i], cpu[i])
+	}
+
+	// The files of the snapshot all belong to a level 1 generation.
+	for _, f := range files {
+		_, seq, err := tsm1.DefaultParseFileName(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seq%4 != 1 {
+			t.Fatalf("file %s has sequence %d, exp level 1", filepath.Base(f), seq)
+		}
+	}
+}
+
+// Tests that fully compacted generations holding the only data of their
+// partitions are not compacted again.
+func TestDefaultPlanner_Plan_PartitionsSettled(t *testing.T) {
+	data := []tsm1.FileStat{
+		{
+			Path:    "01-05.tsm1",
+			Size:    1 * 1024 * 1024,
+			MinKey:  []byte("cpu,host=A#!~#value"),
+			MaxKey:  []byte("cpu,host=B#!~#value"),
+			MinTime: 0,
+			MaxTime: 9,
+		},
+	}
+	for gen := 2; gen <= 5; gen++ {
+		data = append(data, tsm1.FileStat{
+			Path:    tsm1.DefaultFormatFileName(gen, 5) + ".tsm1",
+			Size:    1 * 1024 * 1024,
+			MinKey:  []byte("cpu,host=A#!~#value"),
+			MaxKey:  []byte("cpu,host=B#!~#value"),
+			MinTime: int64(10 + gen),
+			MaxTime: 19,
+		})
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsm1.DefaultCompactFullWriteColdDuration,
+	)
+
+	// Unpartitioned, the generations are compacted together.
+	tsm := cp.PlanOptimize()
+	if len(tsm) != 1 || len(tsm[0]) != len(data) {
+		t.Fatalf("got plan %v, exp all files", tsm)
+	}
+	cp.Release(tsm)
+
+	cp.Partitions = tsm1.NewPartitions()
+	cp.Partitions.SetDurations(map[string]time.Duration{"cpu": 10})
+
+	// The first generation holds the only data of its partition, so only
+	// the others are compacted.
+	tsm = cp.PlanOptimize()
+	if exp, got := 1, len(tsm); exp != got {
+		t.Fatalf("group length mismatch: got %v, exp %v", got, exp)
+	}
+	expFiles := []string{data[1].Path, data[2].Path, data[3].Path, data[4].Path}
+	if exp, got := len(expFiles), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"os"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/influxdata/influxdb/pkg/objstore"
 	"go.uber.org/zap"
 )
 
@@ -23,6 +25,12 @@ type TSMReader struct {
 	madviseWillNeed bool // Hint to the kernel with MADV_WILLNEED.
 	mu              sync.RWMutex
 
+	// objectStore and blockCache are used to read files offloaded to an
+	// object store. Each request to it is limited to objectStoreTimeout.
+	objectStore        objstore.Store
+	blockCache         *BlockCache
+	objectStoreTimeout time.Duration
+
 	// accessor provides access and decoding of blocks for the reader.
 	accessor blockAccessor
 
@@ -72,10 +80,23 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	}
 	t.size = stat.Size()
 	t.lastModified = stat.ModTime().UnixNano()
-	t.accessor = &mmapAccessor{
-		logger:       t.logger,
-		f:            f,
-		mmapWillNeed: t.madviseWillNeed,
+	if isRemoteFile(f) {
+		if t.objectStore == nil {
+			return nil, ErrNoObjectStore
+		}
+		t.accessor = &remoteAccessor{
+			logger:  t.logger,
+			f:       f,
+			store:   t.objectStore,
+			cache:   t.blockCache,
+			timeout: t.objectStoreTimeout,
+		}
+	} else {
+		t.accessor = &mmapAccessor{
+			logger:       t.logger,
+			f:            f,
+			mmapWillNeed: t.madviseWillNeed,
+		}
 	}
 
 	index, err := t.accessor.init()
@@ -286,9 +307,22 @@ func (t *TSMReader) remove() error {
 	if err := t.tombstoner.Delete(); err != nil {
 		return err
 	}
+
+	// The stub is gone, so nothing refers to the object any more.
+	if r, ok := t.accessor.(*remoteAccessor); ok {
+		if err := r.deleteObject(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// IsRemote returns true if the file's blocks are stored in an object store.
+func (t *TSMReader) IsRemote() bool {
+	_, ok := t.accessor.(*remoteAccessor)
+	return ok
+}
+
 // Contains returns whether the given key is present in the index.
 func (t *TSMReader) Contains(key []byte) bool {
 	return t.index.Contains(key)
@@ -442,6 +476,11 @@ func (t *TSMReader) TombstoneFiles() []FileStat {
 	return fs
 }
 
+// WalkTombstones calls fn for every tombstone written for this file.
+func (t *TSMReader) WalkTombstones(fn func(ts Tombstone) error) error {
+	return t.tombstoner.WalkAll(fn)
+}
+
 // TombstoneRange returns ranges of time that are deleted for the given key.
 func (t *TSMReader) TombstoneRange(key []byte, buf []TimeRange) []TimeRange {
 	t.mu.RLock()
@@ -463,6 +502,7 @@ func (t *TSMReader) Stats() FileStat {
 		MinKey:       minKey,
 		MaxKey:       maxKey,
 		HasTombstone: t.tombstoner.HasTombstones(),
+		Remote:       t.IsRemote(),
 	}
 }
 
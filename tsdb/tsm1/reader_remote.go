@@ -0,0 +1,328 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/fs"
+	"github.com/influxdata/influxdb/pkg/objstore"
+	"go.uber.org/zap"
+)
+
+// A TSM file offloaded to an object store is replaced on disk by a stub file
+// with the same name. The stub keeps a copy of the file's index so that the
+// file can be opened and planned against without touching the object store,
+// and blocks are read from the object store as they are needed.
+//
+// The stub is laid out as:
+//
+//	┌──────────────────────────────────────────────────────┬─────────┬────────┐
+//	│                       Header                         │  Index  │ Footer │
+//	├─────────┬─────────┬─────────┬──────────┬────────────┼─────────┼────────┤
+//	│  Magic  │ Version │ Key Len │   Key    │ Object Len │         │ Index  │
+//	│ 4 bytes │ 1 byte  │ 2 bytes │ N bytes  │  8 bytes   │         │ Offset │
+//	└─────────┴─────────┴─────────┴──────────┴────────────┴─────────┴────────┘
+//
+// The index is copied unchanged from the original file, so the block offsets
+// in its entries are offsets into the object.
+const (
+	// RemoteMagicNumber is written as the first 4 bytes of a stub file.
+	RemoteMagicNumber uint32 = 0x16D116D2
+
+	// RemoteVersion indicates the version of the stub file format.
+	RemoteVersion byte = 1
+)
+
+// ErrNoObjectStore is returned when opening a stub file without an object store.
+var ErrNoObjectStore = errors.New("tsm file is stored in an object store but none is configured")
+
+// WithTSMReaderObjectStore is an option for reading stub files from store, caching
+// blocks in cache. The cache may be nil.
+var WithTSMReaderObjectStore = func(store objstore.Store, cache *BlockCache) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.objectStore = store
+		r.blockCache = cache
+	}
+}
+
+// WithTSMReaderObjectStoreTimeout is an option for limiting each read of a
+// block from the object store, and the delete of the object, to timeout.
+// Requests are not limited when it is zero.
+var WithTSMReaderObjectStoreTimeout = func(timeout time.Duration) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.objectStoreTimeout = timeout
+	}
+}
+
+// isRemoteFile returns true if f is a stub file.
+func isRemoteFile(f *os.File) bool {
+	var magic [4]byte
+	if _, err := f.ReadAt(magic[:], 0); err != nil {
+		return false
+	}
+	return binary.BigEndian.Uint32(magic[:]) == RemoteMagicNumber
+}
+
+// WriteRemoteStub writes a stub for the TSM file r of length size, stored as
+// the object key.
+func WriteRemoteStub(w io.Writer, key string, r io.ReaderAt, size int64) error {
+	if len(key) > 1<<16-1 {
+		return fmt.Errorf("object key too long: %d bytes", len(key))
+	}
+	if size < 13 {
+		return fmt.Errorf("tsm file too small: %d bytes", size)
+	}
+
+	var footer [8]byte
+	if _, err := r.ReadAt(footer[:], size-8); err != nil {
+		return err
+	}
+	indexOfsPos := size - 8
+	indexStart := int64(binary.BigEndian.Uint64(footer[:]))
+	if indexStart < 5 || indexStart >= indexOfsPos {
+		return fmt.Errorf("invalid indexStart")
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, RemoteMagicNumber)
+	buf.WriteByte(RemoteVersion)
+	binary.Write(&buf, binary.BigEndian, uint16(len(key)))
+	buf.WriteString(key)
+	binary.Write(&buf, binary.BigEndian, uint64(size))
+	stubIndexStart := uint64(buf.Len())
+	if _, err := io.Copy(&buf, io.NewSectionReader(r, indexStart, indexOfsPos-indexStart)); err != nil {
+		return err
+	}
+	binary.Write(&buf, binary.BigEndian, stubIndexStart)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// remoteAccessor is a block accessor for a stub file. Blocks are read from
+// the object store and kept in a shared BlockCache.
+type remoteAccessor struct {
+	logger  *zap.Logger
+	store   objstore.Store
+	cache   *BlockCache
+	timeout time.Duration // of each request to the store, if not zero
+
+	mu     sync.RWMutex
+	f      *os.File
+	key    string
+	size   int64 // size of the object
+	closed bool
+	_path  string // If the underlying file is renamed then this gets updated
+
+	index *indirectIndex
+}
+
+func (m *remoteAccessor) init() (*indirectIndex, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m._path = m.f.Name()
+
+	// The index keeps references to the bytes it is loaded from, so the
+	// whole stub is read into memory. Stubs are no larger than the index.
+	b, err := ioutil.ReadAll(m.f)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.f.Close(); err != nil {
+		return nil, err
+	}
+
+	if len(b) < 15 || binary.BigEndian.Uint32(b) != RemoteMagicNumber {
+		return nil, fmt.Errorf("remoteAccessor: not a remote tsm file")
+	}
+	if b[4] != RemoteVersion {
+		return nil, fmt.Errorf("remoteAccessor: unknown version %d", b[4])
+	}
+	keyLen := int(binary.BigEndian.Uint16(b[5:7]))
+	if len(b) < 7+keyLen+16 {
+		return nil, fmt.Errorf("remoteAccessor: byte slice too small for header")
+	}
+	m.key = string(b[7 : 7+keyLen])
+	m.size = int64(binary.BigEndian.Uint64(b[7+keyLen:]))
+
+	headerLen := uint64(7 + keyLen + 8)
+	indexOfsPos := len(b) - 8
+	indexStart := binary.BigEndian.Uint64(b[indexOfsPos:])
+	if indexStart != headerLen || indexStart > uint64(indexOfsPos) {
+		return nil, fmt.Errorf("remoteAccessor: invalid indexStart")
+	}
+
+	m.index = NewIndirectIndex()
+	if err := m.index.UnmarshalBinary(b[indexStart:indexOfsPos]); err != nil {
+		return nil, err
+	}
+	m.index.logger = m.logger
+
+	return m.index, nil
+}
+
+// readRaw returns the block of entry, including its checksum.
+func (m *remoteAccessor) readRaw(entry *IndexEntry) ([]byte, error) {
+	m.mu.RLock()
+	closed, key := m.closed, m.key
+	m.mu.RUnlock()
+
+	if closed {
+		return nil, ErrTSMClosed
+	} else if entry.Offset+int64(entry.Size) > m.size || entry.Size < 4 {
+		return nil, fmt.Errorf("remoteAccessor: block out of range")
+	}
+
+	ck := blockCacheKey{object: key, offset: entry.Offset}
+	if m.cache != nil {
+		if b := m.cache.get(ck); b != nil {
+			return b, nil
+		}
+	}
+
+	ctx, cancel := m.requestContext()
+	defer cancel()
+
+	b := make([]byte, entry.Size)
+	if err := m.store.ReadAt(ctx, key, b, entry.Offset); err != nil {
+		return nil, fmt.Errorf("reading block of %s: %v", key, err)
+	}
+	if crc32.ChecksumIEEE(b[4:]) != binary.BigEndian.Uint32(b[:4]) {
+		return nil, fmt.Errorf("reading block of %s: checksum mismatch", key)
+	}
+
+	if m.cache != nil {
+		m.cache.add(ck, b)
+	}
+	return b, nil
+}
+
+func (m *remoteAccessor) read(key []byte, timestamp int64) ([]Value, error) {
+	entry := m.index.Entry(key, timestamp)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return m.readBlock(entry, nil)
+}
+
+func (m *remoteAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, error) {
+	b, err := m.readRaw(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(b[4:], values)
+}
+
+func (m *remoteAccessor) readBytes(entry *IndexEntry, _ []byte) (uint32, []byte, error) {
+	b, err := m.readRaw(entry)
+	if err != nil {
+		return 0, nil, err
+	}
+	return binary.BigEndian.Uint32(b[:4]), b[4:], nil
+}
+
+// readAll returns all values for a key in all blocks.
+func (m *remoteAccessor) readAll(key []byte) ([]Value, error) {
+	blocks, err := m.index.ReadEntries(key, nil)
+	if len(blocks) == 0 || err != nil {
+		return nil, err
+	}
+
+	tombstones := m.index.TombstoneRange(key, nil)
+
+	var temp []Value
+	var values []Value
+	for i := range blocks {
+		block := &blocks[i]
+
+		var skip bool
+		for _, t := range tombstones {
+			// Should we skip this block because it contains points that have been deleted
+			if t.Min <= block.MinTime && t.Max >= block.MaxTime {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		temp, err = m.readBlock(block, temp[:0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out any values that were deleted
+		for _, t := range tombstones {
+			temp = Values(temp).Exclude(t.Min, t.Max)
+		}
+
+		values = append(values, temp...)
+	}
+
+	return values, nil
+}
+
+func (m *remoteAccessor) rename(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := fs.RenameFileWithReplacement(m._path, path); err != nil {
+		return err
+	}
+	m._path = path
+	return nil
+}
+
+func (m *remoteAccessor) path() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m._path
+}
+
+// free releases nothing; cached blocks are evicted by the BlockCache.
+func (m *remoteAccessor) free() error { return nil }
+
+func (m *remoteAccessor) close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	return nil
+}
+
+// deleteObject removes the object the stub refers to.
+func (m *remoteAccessor) deleteObject() error {
+	m.mu.RLock()
+	key := m.key
+	m.mu.RUnlock()
+
+	if m.cache != nil {
+		m.cache.evict(key)
+	}
+
+	ctx, cancel := m.requestContext()
+	defer cancel()
+	return m.store.Delete(ctx, key)
+}
+
+// requestContext returns the context of a request to the object store, so
+// that a stalled store cannot hang readers, closing the file or replacing it.
+func (m *remoteAccessor) requestContext() (context.Context, context.CancelFunc) {
+	if m.timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), m.timeout)
+}
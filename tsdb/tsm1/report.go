@@ -31,6 +31,7 @@ type Report struct {
 	Pattern         string       // Providing "01.tsm" for example would filter for level 1 files.
 	Detailed        bool         // Detailed will segment cardinality by tag keys.
 	Exact           bool         // Exact determines if estimation or exact methods are used to determine cardinality.
+	Compression     bool         // Compression reads every block to determine the compression ratio of each codec.
 }
 
 // ReportSummary provides a summary of the cardinalities in the processed fileset.
@@ -44,6 +45,25 @@ type ReportSummary struct {
 	Measurements map[string]uint64 // The exact or estimated unique set of series keys segmented by the measurement tag.
 	FieldKeys    map[string]uint64 // The exact or estimated unique set of series keys segmented by the field tag.
 	TagKeys      map[string]uint64 // The exact or estimated unique set of series keys segmented by tag keys.
+
+	// These are calculated when the compression flag is in use.
+	Codecs map[string]CodecStats // Block statistics segmented by block type and codec, e.g. "string/zstd".
+}
+
+// CodecStats holds the sizes of the blocks encoded with a codec.
+type CodecStats struct {
+	Blocks       int // Number of blocks.
+	Values       int // Number of values in the blocks.
+	RawBytes     int // Size of the values before encoding.
+	EncodedBytes int // Size of the encoded values.
+}
+
+// Ratio returns the compression ratio of the codec.
+func (s CodecStats) Ratio() float64 {
+	if s.EncodedBytes == 0 {
+		return 0
+	}
+	return float64(s.RawBytes) / float64(s.EncodedBytes)
 }
 
 func newReportSummary() *ReportSummary {
@@ -53,6 +73,7 @@ func newReportSummary() *ReportSummary {
 		Measurements:  map[string]uint64{},
 		FieldKeys:     map[string]uint64{},
 		TagKeys:       map[string]uint64{},
+		Codecs:        map[string]CodecStats{},
 	}
 }
 
@@ -95,6 +116,9 @@ func (r *Report) Run(print bool) (*ReportSummary, error) {
 	fCardinalities := map[string]counter{} // The exact or estimated unique set of series keys segmented by the field tag.
 	tCardinalities := map[string]counter{} // The exact or estimated unique set of series keys segmented by tag keys.
 
+	// These are calculated when the compression flag is in use.
+	codecs := map[string]CodecStats{} // Block statistics segmented by block type and codec.
+
 	start := time.Now()
 
 	tw := tabwriter.NewWriter(r.Stdout, 8, 2, 1, ' ', 0)
@@ -208,6 +232,13 @@ func (r *Report) Run(print bool) (*ReportSummary, error) {
 			}
 		}
 
+		if r.Compression {
+			if err := r.addCodecStats(reader, codecs); err != nil {
+				reader.Close()
+				return nil, fmt.Errorf("error: %s: %v. Exiting", path, err)
+			}
+		}
+
 		minT, maxT := reader.TimeRange()
 		if minT < minTime {
 			minTime = minT
@@ -296,10 +327,81 @@ func (r *Report) Run(print bool) (*ReportSummary, error) {
 		}
 	}
 
+	if r.Compression {
+		names := make([]string, 0, len(codecs))
+		for name := range codecs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Printf("\n  Compression By Codec (%d):\n", len(codecs))
+		for _, name := range names {
+			st := codecs[name]
+			summary.Codecs[name] = st
+			fmt.Printf("    - %v: %d blocks, %d values, %d raw bytes, %d encoded bytes (%.2fx)\n", name, st.Blocks, st.Values, st.RawBytes, st.EncodedBytes, st.Ratio())
+		}
+	}
+
 	fmt.Printf("\nCompleted in %s\n", time.Since(start))
 	return summary, nil
 }
 
+// matches returns true if key belongs to the org and bucket being reported on.
+func (r *Report) matches(key []byte) bool {
+	if r.OrgID == nil && r.BucketID == nil {
+		return true
+	}
+	var a [16]byte
+	copy(a[:], key)
+	org, bucket := tsdb.DecodeName(a)
+	return (r.OrgID == nil || *r.OrgID == org) && (r.BucketID == nil || *r.BucketID == bucket)
+}
+
+// addCodecStats reads every block of reader and adds its sizes to the stats
+// of its block type and codec.
+func (r *Report) addCodecStats(reader *TSMReader, stats map[string]CodecStats) error {
+	iter := reader.BlockIterator()
+	for iter.Next() {
+		key, _, _, typ, _, block, err := iter.Read()
+		if err != nil {
+			return err
+		}
+		if !r.matches(key) || len(block) <= encodedBlockHeaderSize {
+			continue
+		}
+
+		tb, vb, err := unpackBlock(block[encodedBlockHeaderSize:])
+		if err != nil {
+			return err
+		}
+
+		n := CountTimestamps(tb)
+		raw := 8 * n
+		switch typ {
+		case BlockBoolean:
+			raw = n
+		case BlockString:
+			values, err := StringArrayDecodeAll(vb, nil)
+			if err != nil {
+				return err
+			}
+			raw = 0
+			for _, v := range values {
+				raw += len(v)
+			}
+		}
+
+		name := BlockTypeName(typ) + "/" + codecName(typ, vb)
+		st := stats[name]
+		st.Blocks++
+		st.Values += n
+		st.RawBytes += raw
+		st.EncodedBytes += len(vb)
+		stats[name] = st
+	}
+	return iter.Err()
+}
+
 // sortKeys is a quick helper to return the sorted set of a map's keys
 func sortKeys(vals map[string]counter) (keys []string) {
 	for k := range vals {
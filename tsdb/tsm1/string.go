@@ -14,8 +14,13 @@ import (
 
 // Note: an uncompressed format is not yet implemented.
 
-// stringCompressedSnappy is a compressed encoding using Snappy compression
-const stringCompressedSnappy = 1
+const (
+	// stringCompressedSnappy is a compressed encoding using Snappy compression
+	stringCompressedSnappy = 1
+
+	// stringCompressedZstd is a compressed encoding using zstd compression
+	stringCompressedZstd = 2
+)
 
 // StringEncoder encodes multiple strings into a byte slice.
 type StringEncoder struct {
@@ -68,12 +73,11 @@ type StringDecoder struct {
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	// First byte stores the encoding type.
 	var data []byte
 	if len(b) > 0 {
 		var err error
-		data, err = snappy.Decode(nil, b[1:])
+		data, err = decompressStrings(b)
 		if err != nil {
 			return fmt.Errorf("failed to decode string block: %v", err.Error())
 		}
@@ -127,3 +131,17 @@ func (e *StringDecoder) Read() string {
 func (e *StringDecoder) Error() error {
 	return e.err
 }
+
+// decompressStrings returns the length-prefixed strings of the encoded block
+// b, decompressed with the codec given by its header. The returned slice is
+// always newly allocated.
+func decompressStrings(b []byte) ([]byte, error) {
+	switch b[0] >> 4 {
+	case stringCompressedSnappy:
+		return snappy.Decode(nil, b[1:])
+	case stringCompressedZstd:
+		return zstdDecode(nil, b[1:])
+	default:
+		return nil, fmt.Errorf("unknown string encoding %d", b[0]>>4)
+	}
+}
@@ -286,33 +286,58 @@ func (t *Tombstoner) TombstoneFiles() []FileStat {
 	return stats
 }
 
-// Walk calls fn for every Tombstone under the Tombstoner.
+// Walk calls fn for every Tombstone under the Tombstoner. Tombstones read
+// from the file by an earlier call to Walk are skipped.
 func (t *Tombstoner) Walk(fn func(t Tombstone) error) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	pos, err := t.walk(t.lastAppliedOffset, fn)
+	if err != nil {
+		return err
+	} else if pos > 0 {
+		// Save the position of tombstone file so we don't re-apply the same set again if there are
+		// more deletes.
+		t.lastAppliedOffset = pos
+	}
+	return nil
+}
+
+// WalkAll calls fn for every Tombstone under the Tombstoner, including those
+// already read by Walk.
+func (t *Tombstoner) WalkAll(fn func(t Tombstone) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, err := t.walk(0, fn)
+	return err
+}
+
+// walk calls fn for the tombstones in the file from offset on and returns the
+// offset of the end of the file, or zero if there is no file.
+func (t *Tombstoner) walk(offset int64, fn func(t Tombstone) error) (int64, error) {
 	f, err := os.Open(t.tombstonePath())
 	if os.IsNotExist(err) {
-		return nil
+		return 0, nil
 	} else if err != nil {
-		return err
+		return 0, err
 	}
 	defer f.Close()
 
 	var b [4]byte
 	if _, err := f.Read(b[:]); err != nil {
-		return errors.New("unable to read header")
+		return 0, errors.New("unable to read header")
 	}
 
 	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return err
+		return 0, err
 	}
 
 	header := binary.BigEndian.Uint32(b[:])
 	if header == v4header {
-		return t.readTombstoneV4(f, fn)
+		return t.readTombstoneV4(f, offset, fn)
 	}
-	return errors.New("invalid tombstone file")
+	return 0, errors.New("invalid tombstone file")
 }
 
 func (t *Tombstoner) prepareLatest() error {
@@ -444,15 +469,15 @@ func (t *Tombstoner) rollback() error {
 
 // readTombstoneV4 reads the fourth version of tombstone files that are capable
 // of storing multiple v3 files appended together.
-func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) error {
+func (t *Tombstoner) readTombstoneV4(f *os.File, offset int64, fn func(t Tombstone) error) (int64, error) {
 	// Skip header, already checked earlier
-	if t.lastAppliedOffset != 0 {
-		if _, err := f.Seek(t.lastAppliedOffset, io.SeekStart); err != nil {
-			return err
+	if offset != 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return 0, err
 		}
 	} else {
 		if _, err := f.Seek(headerSize, io.SeekStart); err != nil {
-			return err
+			return 0, err
 		}
 	}
 
@@ -461,9 +486,9 @@ func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) err
 	br := bufio.NewReaderSize(f, 64*1024)
 	gr, err := gzip.NewReader(br)
 	if err == io.EOF {
-		return nil
+		return offset, nil
 	} else if err != nil {
-		return err
+		return 0, err
 	}
 	defer gr.Close()
 
@@ -540,12 +565,12 @@ func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) err
 				}
 			}
 		}(); err != nil {
-			return err
+			return 0, err
 		}
 
 		for _, t := range t.tombstones {
 			if err := fn(t); err != nil {
-				return err
+				return 0, err
 			}
 		}
 
@@ -555,14 +580,7 @@ func (t *Tombstoner) readTombstoneV4(f *os.File, fn func(t Tombstone) error) err
 		}
 	}
 
-	// Save the position of tombstone file so we don't re-apply the same set again if there are
-	// more deletes.
-	pos, err := f.Seek(0, io.SeekCurrent)
-	if err != nil {
-		return err
-	}
-	t.lastAppliedOffset = pos
-	return nil
+	return f.Seek(0, io.SeekCurrent)
 }
 
 func (t *Tombstoner) tombstonePath() string {
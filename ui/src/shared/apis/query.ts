@@ -35,7 +35,8 @@ export interface RunQueryErrorResult {
 export const runQuery = (
   orgID: string,
   query: string,
-  extern?: File
+  extern?: File,
+  params?: Query['params']
 ): CancelBox<RunQueryResult> => {
   const url = `${API_BASE_PATH}api/v2/query?${new URLSearchParams({orgID})}`
 
@@ -47,6 +48,7 @@ export const runQuery = (
   const body: Query = {
     query,
     extern,
+    params,
     dialect: {annotations: ['group', 'datatype', 'default']},
   }
 
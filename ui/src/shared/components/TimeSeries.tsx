@@ -176,11 +176,11 @@ class TimeSeries extends Component<Props & WithRouterProps, State> {
       this.pendingResults.forEach(({cancel}) => cancel())
 
       // Issue new queries
-      this.pendingResults = queries.map(({text}) => {
+      this.pendingResults = queries.map(({text, params}) => {
         const windowVars = getWindowVars(text, variables)
         const extern = buildVarsOption([...variables, ...windowVars])
 
-        return runQuery(orgID, text, extern)
+        return runQuery(orgID, text, extern, params)
       })
 
       // Wait for new queries to complete
@@ -139,11 +139,11 @@ export const executeQueries = (dashboardID?: string) => async (
 
     pendingResults.forEach(({cancel}) => cancel())
 
-    pendingResults = queries.map(({text}) => {
+    pendingResults = queries.map(({text, params}) => {
       const windowVars = getWindowVars(text, variableAssignments)
       const extern = buildVarsOption([...variableAssignments, ...windowVars])
 
-      return runQuery(orgID, text, extern)
+      return runQuery(orgID, text, extern, params)
     })
 
     const results = await Promise.all(pendingResults.map(r => r.promise))
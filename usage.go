@@ -23,6 +23,13 @@ const (
 	UsageQueryRequestCount UsageMetric = "usage_query_request_count"
 	// UsageQueryRequestBytes is the name of the metrics for tracking the number of query bytes.
 	UsageQueryRequestBytes UsageMetric = "usage_query_request_bytes"
+	// UsageConcurrentQueries is the name of the metrics for tracking the number of queries executing.
+	UsageConcurrentQueries UsageMetric = "usage_concurrent_queries"
+
+	// UsageBuckets is the name of the metrics for tracking the number of buckets.
+	UsageBuckets UsageMetric = "usage_buckets"
+	// UsageTasks is the name of the metrics for tracking the number of tasks.
+	UsageTasks UsageMetric = "usage_tasks"
 )
 
 // Usage is a metric associated with the utilization of a particular resource.